@@ -25,7 +25,7 @@ func main() {
 					config.GetEnv("APP_MODE", config.ModeAIO),
 				),
 				config.WithServices(
-					strings.Split(",", config.GetEnv("APP_SERVICES", "")),
+					strings.Split(config.GetEnv("APP_SERVICES", ""), ","),
 				),
 			),
 		),
@@ -7,11 +7,20 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/tonica-go/tonica/pkg/tonica/grpc/serviceconfig"
+	"github.com/tonica-go/tonica/pkg/tonica/service"
 )
 
 const ServiceName = "paymentservice-service"
 const ServiceAddrEnvName = "PAYMENTSERVICE_SERVICE_GRPC_ADDR"
 
+// PaymentServiceGateway registers the HTTP gateway handlers of PaymentService.
+// Pass it to service.WithGateway.
+var PaymentServiceGateway service.GatewayRegistrar = RegisterPaymentServiceHandlerFromEndpoint
+
+func init() {
+	service.RegisterGatewayHandler(PaymentService_ServiceDesc.ServiceName, PaymentServiceGateway)
+}
+
 type PaymentServiceAddressConfig struct {
 	PaymentServiceAddress  string `env:"PAYMENTSERVICE_SERVICE_GRPC_ADDR,required"`
 	PaymentServiceGrpcPort string `env:"PAYMENTSERVICE_SERVICE_GRPC_PORT,required"`
@@ -25,6 +34,7 @@ func (cfg *PaymentServiceAddressConfig) GetPaymentServiceConfig() serviceconfig.
 	return serviceconfig.ServiceConfig{
 		Address: net.JoinHostPort(cfg.PaymentServiceAddress, cfg.PaymentServiceGrpcPort),
 		Name:    ServiceName,
+		TLS:     serviceconfig.TLSFromEnv("PAYMENTSERVICE_SERVICE"),
 	}
 }
 
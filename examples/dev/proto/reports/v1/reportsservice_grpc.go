@@ -7,11 +7,20 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/tonica-go/tonica/pkg/tonica/grpc/serviceconfig"
+	"github.com/tonica-go/tonica/pkg/tonica/service"
 )
 
 const ServiceName = "reportsservice-service"
 const ServiceAddrEnvName = "REPORTSSERVICE_SERVICE_GRPC_ADDR"
 
+// ReportsServiceGateway registers the HTTP gateway handlers of ReportsService.
+// Pass it to service.WithGateway.
+var ReportsServiceGateway service.GatewayRegistrar = RegisterReportsServiceHandlerFromEndpoint
+
+func init() {
+	service.RegisterGatewayHandler(ReportsService_ServiceDesc.ServiceName, ReportsServiceGateway)
+}
+
 type ReportsServiceAddressConfig struct {
 	ReportsServiceAddress  string `env:"REPORTSSERVICE_SERVICE_GRPC_ADDR,required"`
 	ReportsServiceGrpcPort string `env:"REPORTSSERVICE_SERVICE_GRPC_PORT,required"`
@@ -25,6 +34,7 @@ func (cfg *ReportsServiceAddressConfig) GetReportsServiceConfig() serviceconfig.
 	return serviceconfig.ServiceConfig{
 		Address: net.JoinHostPort(cfg.ReportsServiceAddress, cfg.ReportsServiceGrpcPort),
 		Name:    ServiceName,
+		TLS:     serviceconfig.TLSFromEnv("REPORTSSERVICE_SERVICE"),
 	}
 }
 
@@ -3,14 +3,18 @@ package tonica
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
 	"net"
 	"net/http"
 	"os"
+	"path"
 	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/MarceloPetrucio/go-scalar-api-reference"
@@ -18,6 +22,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/tonica-go/tonica/pkg/tonica/config"
+	"github.com/tonica-go/tonica/pkg/tonica/consumer"
+	"github.com/tonica-go/tonica/pkg/tonica/grpc/serviceconfig"
+	"github.com/tonica-go/tonica/pkg/tonica/identity"
 	"github.com/tonica-go/tonica/pkg/tonica/logger"
 	"github.com/tonica-go/tonica/pkg/tonica/metrics"
 	"github.com/tonica-go/tonica/pkg/tonica/metrics/exporters"
@@ -25,9 +32,15 @@ import (
 	"github.com/tonica-go/tonica/pkg/tonica/modules/workflows"
 	obs "github.com/tonica-go/tonica/pkg/tonica/observabillity"
 	"github.com/tonica-go/tonica/pkg/tonica/registry"
+	"github.com/tonica-go/tonica/pkg/tonica/service"
+	"github.com/tonica-go/tonica/pkg/tonica/storage/blob"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
@@ -40,11 +53,14 @@ type App struct {
 	spec                    string
 	specUrl                 string
 	customRoutes            []RouteMetadata
+	securitySchemes         map[string]SecurityScheme
 	apiPrefix               string
+	publicURL               string
 	useGatewayProtoMessages bool
 
-	isWorkflowService bool
-	workflowNamespace string
+	isWorkflowService    bool
+	workflowNamespace    string
+	workflowInputSchemas *workflows.InputSchemas
 
 	isEntityService   bool
 	entityDefinitions string
@@ -54,13 +70,77 @@ type App struct {
 	router       *gin.Engine
 	metricRouter *gin.Engine
 
-	metricsManager metrics.Manager
-	shutdown       *Shutdown
+	// listen addresses of the API and metrics servers, see WithHTTPAddr
+	httpAddr    string
+	metricsAddr string
+	// addresses the servers are bound to, see HTTPAddr
+	httpListenAddr    atomic.Value
+	metricsListenAddr atomic.Value
+
+	// metrics endpoints mounted on the API router, see WithMetricsOnAPI
+	metricsOnAPI  bool
+	metricsPrefix string
+	metricsGuards []gin.HandlerFunc
+	// guards of the consumer admin endpoints, which are only served when
+	// set, see WithConsumerAdmin
+	consumerAdminGuards []gin.HandlerFunc
+
+	metricsManager    metrics.Manager
+	metricExporters   []sdkmetric.Exporter
+	disablePrometheus bool
+	shutdown          *Shutdown
+
+	// gateway routes of some services served below a prefix
+	gatewayMounts []GatewayMount
 
 	// routeMiddlewares defines middleware for specific route patterns
 	routeMiddlewares []RouteMiddleware
+	// methodMiddlewares guard gateway calls by gRPC method
+	methodMiddlewares []methodMiddlewareRule
+	// body size and timeout of gateway calls, overridden by gRPC method
+	defaultGatewayLimits GatewayLimits
+	methodLimits         []methodLimitsRule
 
 	customGrpcHeaders []string
+
+	// httpServerHooks adjust the API http.Server before it listens
+	httpServerHooks []func(*http.Server)
+
+	// gatewayTLS, when set, makes the gateway dial services over TLS
+	gatewayTLS *serviceconfig.TLSConfig
+
+	// shared gRPC server for raw registrations
+	grpcAddr    string
+	rawGRPCRegs []func(*grpc.Server)
+
+	// default deadline of gRPC calls arriving without one
+	grpcTimeout time.Duration
+
+	// grpcReflection overrides the debug mode default, see WithGRPCReflection
+	grpcReflection *bool
+
+	// request validation against the merged OpenAPI spec
+	requestValidator   *requestValidator
+	validateGateway    bool
+	validationPrefixes []string
+
+	uploadStore UploadStore
+	blobStore   blob.Store
+
+	requestIDHeader string
+	requestIDFormat RequestIDFormat
+	requestIDs      *obs.RequestIDs
+	logConfig       LogConfig
+	requestLogger   *obs.RequestLogger
+
+	// proto services registered per service name, filled by registerGRPC
+	protoServices map[string][]string
+	autoGateway   bool
+
+	serviceMetricLabels bool
+
+	// checks reported by /readyz
+	readiness *readiness
 }
 
 // RouteMiddleware defines middleware for specific route patterns
@@ -75,23 +155,89 @@ func NewApp(options ...AppOption) *App {
 		Name:              config.DefaultAppName,
 		registry:          registry.NewRegistry(),
 		logger:            l,
-		metricsManager:    metrics.NewMetricsManager(exporters.Prometheus(config.DefaultAppName, "0.0.0")),
 		router:            gin.New(),
 		metricRouter:      gin.New(),
 		customGrpcHeaders: make([]string, 0),
 		shutdown:          NewShutdown(),
 		apiPrefix:         "/v1", // default prefix for backward compatibility
+		grpcAddr:          DefaultGRPCAddr,
+		protoServices:     map[string][]string{},
+		readiness:         newReadiness(),
 	}
 
 	for _, option := range options {
 		option(app)
 	}
+	app.requestIDs = obs.NewRequestIDs(app.requestIDHeader, app.requestIDFormat)
+	app.requestLogger = obs.NewRequestLogger(app.logConfig)
 
+	app.metricsManager = metrics.NewMetricsManager(app.frameworkMeter())
 	app.registerFrameworkMetrics()
 
 	return app
 }
 
+// frameworkMeter returns the meter for the framework metrics. With push
+// exporters configured it is the global meter, which obs.Init backs with the
+// single provider reading every exporter; otherwise a Prometheus meter.
+func (a *App) frameworkMeter() metric.Meter {
+	if len(a.metricExporters) > 0 {
+		return otel.Meter(config.DefaultAppName, metric.WithInstrumentationVersion("0.0.0"))
+	}
+	return exporters.New(config.DefaultAppName, "0.0.0", !a.disablePrometheus)
+}
+
+// getPublicURL returns the externally visible base URL of the API.
+func (a *App) getPublicURL() string {
+	if a.publicURL != "" {
+		return a.publicURL
+	}
+	return config.GetEnv("APP_PUBLIC_URL", "")
+}
+
+// getHTTPAddr returns the configured listen address of the API server.
+func (a *App) getHTTPAddr() string {
+	if a.httpAddr != "" {
+		return a.httpAddr
+	}
+	return config.GetEnv("APP_HTTP_ADDR", ":8080")
+}
+
+// getMetricsAddr returns the configured listen address of the metrics server.
+func (a *App) getMetricsAddr() string {
+	if a.metricsAddr != "" {
+		return a.metricsAddr
+	}
+	return config.GetEnv("APP_METRIC_ADDR", ":2121")
+}
+
+// HTTPAddr returns the address the API server listens on, e.g.
+// "127.0.0.1:41235" when it was configured with port 0. It is empty until the
+// server listens.
+func (a *App) HTTPAddr() string {
+	addr, _ := a.httpListenAddr.Load().(string)
+	return addr
+}
+
+// MetricsAddr returns the address the metrics server listens on, see
+// HTTPAddr.
+func (a *App) MetricsAddr() string {
+	addr, _ := a.metricsListenAddr.Load().(string)
+	return addr
+}
+
+// RegisterRawGRPC adds a registration onto the app's shared gRPC server, e.g.
+// a health service or a third-party gRPC service, without wrapping it in a
+// service.Service. The shared server listens on WithGRPCAddr (default
+// DefaultGRPCAddr) and is started in aio and service mode only if something
+// was registered on it. Must be called before Run.
+func (a *App) RegisterRawGRPC(register func(*grpc.Server)) {
+	if register == nil {
+		return
+	}
+	a.rawGRPCRegs = append(a.rawGRPCRegs, register)
+}
+
 func (a *App) GetLogger() *log.Logger {
 	return a.logger
 }
@@ -112,24 +258,58 @@ func (a *App) GetRouter() *gin.Engine {
 	return a.router
 }
 
+// GetBlobStore returns the store set with WithBlobStore, or nil.
+func (a *App) GetBlobStore() blob.Store {
+	return a.blobStore
+}
+
 // initObs initializes OpenTelemetry + Prometheus for a given service name.
-func initObs(ctx context.Context, service string) (*obs.Observability, error) {
+func (a *App) initObs(ctx context.Context, service string) (*obs.Observability, error) {
 	slog.Info(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"))
 	return obs.Init(ctx, obs.Config{
-		ServiceName:    service,
-		ServiceVersion: "v0.1.0",
-		OTLPEndpoint:   os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
-		LogLevel:       config.GetEnv("LOG_LEVEL", "info"),
+		ServiceName:       service,
+		ServiceVersion:    "v0.1.0",
+		OTLPEndpoint:      os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		LogLevel:          config.GetEnv("LOG_LEVEL", "info"),
+		OTLPStrict:        config.GetEnvBool("OTEL_EXPORTER_OTLP_STRICT", false),
+		MetricExporters:   a.metricExporters,
+		DisablePrometheus: a.disablePrometheus,
 	})
 }
 
-func (a *App) registerGateway(ctx context.Context) *runtime.ServeMux {
+// registerGateway builds the gateway handler: one ServeMux per gateway mount
+// and one for the services outside any mount.
+func (a *App) registerGateway(ctx context.Context) http.Handler {
+	services, err := a.GetRegistry().GetAllServices()
+	if err != nil {
+		a.GetLogger().Fatal(err)
+	}
+	mounts, unmounted, err := a.splitGatewayMounts(services)
+	if err != nil {
+		a.GetLogger().Fatal(err)
+	}
+
+	gw := &gatewayMux{fallback: a.newGatewayMux(ctx, unmounted)}
+	for _, m := range mounts {
+		gw.mounts = append(gw.mounts, mountedGateway{
+			prefix:  m.prefix,
+			handler: a.newGatewayMux(ctx, m.services),
+		})
+	}
+	gw.sortMounts()
+	return gw
+}
+
+// newGatewayMux creates a gateway ServeMux proxying the HTTP routes of
+// services.
+func (a *App) newGatewayMux(ctx context.Context, services []*service.Service) *runtime.ServeMux {
+	owners := a.protoServiceOwners()
 	options := []runtime.ServeMuxOption{
 		runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
 			keyLower := strings.ToLower(key)
 
 			switch keyLower {
-			case "authorization", "traceparent", "tracestate", "x-request-id":
+			case "authorization", "traceparent", "tracestate", strings.ToLower(a.requestIDs.Header()):
 				return keyLower, true
 			}
 
@@ -142,9 +322,13 @@ func (a *App) registerGateway(ctx context.Context) *runtime.ServeMux {
 		runtime.WithMetadata(func(ctx context.Context, r *http.Request) metadata.MD {
 			md := metadata.MD{}
 
+			if a.serviceMetricLabels {
+				obs.SetMetricService(ctx, gatewayMetricService(ctx, owners))
+			}
+
 			// Извлекаем identity из контекста и добавляем в metadata
-			if identity, ok := ctx.Value("identity").(map[string]interface{}); ok && identity != nil {
-				ib, err := json.Marshal(identity)
+			if id := identity.FromContext(ctx); id != nil {
+				ib, err := json.Marshal(id)
 				if err != nil {
 					slog.Error("Failed to marshal identity", "error", err)
 					return metadata.MD{}
@@ -154,6 +338,7 @@ func (a *App) registerGateway(ctx context.Context) *runtime.ServeMux {
 
 			return md
 		}),
+		runtime.WithForwardResponseOption(entities.RecordLocation),
 	}
 
 	if a.useGatewayProtoMessages {
@@ -168,106 +353,137 @@ func (a *App) registerGateway(ctx context.Context) *runtime.ServeMux {
 		}))
 	}
 
+	if a.hasGatewayLimits() {
+		options = append(options, runtime.WithMiddlewares(a.gatewayLimitsMiddleware(services)))
+	}
+
 	gwmux := runtime.NewServeMux(options...)
+	creds, err := a.gatewayCredentials()
+	if err != nil {
+		a.GetLogger().Fatal(fmt.Errorf("gateway TLS: %w", err))
+	}
 	dialOpts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(creds),
 		obs.GRPCClientStats(),
 		//grpc.WithUnaryInterceptor(ClientContextInterceptor()),
 	}
-
-	services, err := a.GetRegistry().GetAllServices()
-	if err != nil {
-		a.GetLogger().Fatal(err)
+	if len(a.methodMiddlewares) > 0 {
+		dialOpts = append(dialOpts,
+			grpc.WithChainUnaryInterceptor(a.methodMiddlewareUnary()),
+			grpc.WithChainStreamInterceptor(a.methodMiddlewareStream()),
+		)
 	}
+
 	for _, service := range services {
 		if service.GetIsGatewayEnabled() {
 			registerGw := service.GetGateway()
-			if err := registerGw(ctx, gwmux, service.GetGRPCAddr(), dialOpts); err != nil {
+			if err := registerGw(ctx, gwmux, a.serviceDialAddr(service), dialOpts); err != nil {
 				a.GetLogger().Fatal(err)
 			}
+			continue
+		}
+		if err := a.registerMissingGateway(ctx, gwmux, service, dialOpts); err != nil {
+			a.GetLogger().Fatal(err)
 		}
 	}
 
 	return gwmux
 }
 
-func (a *App) registerMetrics(ctx context.Context, o *obs.Observability) {
+// registerMetrics serves the metrics endpoints on their own port until the
+// server is shut down. Serve errors are sent to errCh.
+func (a *App) registerMetrics(ctx context.Context, o *obs.Observability, errCh chan error) {
 	router := a.metricRouter
 
-	router.Use(gin.Recovery())
-	router.Use(obs.HTTPLogger())
+	router.Use(obs.HTTPRecovery())
+	router.Use(a.requestLogger.HTTP())
 
-	// Use OpenTelemetry metrics handler instead of old metrics.Manager
-	if o != nil && o.MetricsHandler != nil {
-		router.GET("/metrics", gin.WrapH(o.MetricsHandler))
-	} else {
+	if (o == nil || o.MetricsHandler == nil) && !a.disablePrometheus {
 		// Fallback to old handler if obs not available
 		metrics.GetHandler(a.GetMetricManager(), router)
 	}
+	a.mountMetrics(&router.RouterGroup, o)
+
+	addr := a.getMetricsAddr()
+	a.GetLogger().Println("metrics server running, listening addr", addr)
+	if err := a.serveHTTP(a.newHTTPServer(addr, router), &a.metricsListenAddr); err != nil {
+		errCh <- fmt.Errorf("metrics server: %w", err)
+	}
+}
 
-	router.GET("/healthz", func(c *gin.Context) {
+// registerMetricsWithAPI mounts the metrics endpoints on the API router when
+// WithMetricsOnAPI is set and serves them on their own port otherwise. It
+// must run before registerAPI starts serving.
+func (a *App) registerMetricsWithAPI(ctx context.Context, o *obs.Observability, errCh chan error) {
+	if !a.metricsOnAPI {
+		go a.registerMetrics(ctx, o, errCh)
+		return
+	}
+	handlers := append([]gin.HandlerFunc{obs.HTTPRecovery(), a.requestLogger.HTTP()}, a.metricsGuards...)
+	group := a.router.Group(a.metricsPrefix, handlers...)
+	if (o == nil || o.MetricsHandler == nil) && !a.disablePrometheus {
+		// Fallback to old handler if obs not available; pprof stays off the API.
+		group.GET("/metrics", gin.WrapH(metrics.Handler(a.GetMetricManager())))
+	}
+	a.mountMetrics(group, o)
+	a.GetLogger().Println("metrics served by the http server at", path.Join("/", a.metricsPrefix))
+}
+
+// mountMetrics registers the metrics, health and readiness endpoints on group,
+// and the consumer admin endpoints behind their guards with
+// WithConsumerAdmin.
+func (a *App) mountMetrics(group *gin.RouterGroup, o *obs.Observability) {
+	// Use OpenTelemetry metrics handler instead of old metrics.Manager
+	if o != nil && o.MetricsHandler != nil {
+		group.GET("/metrics", gin.WrapH(o.MetricsHandler))
+	}
+
+	group.GET("/healthz", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
 			"status": "ok",
 			"now":    time.Now().UTC().Format(time.RFC3339),
 		})
 	})
-	router.GET("/readyz", func(c *gin.Context) {
-		//results, overall := ReadinessChecks(c.Request.Context(), opts.Infra)
-		code := http.StatusOK
-		//if !overall {
-		//	code = http.StatusServiceUnavailable
-		//}
-		//todo need to add ready checks for services, consumers, workers, gateway
-		c.JSON(code, gin.H{
-			"status": func() string {
-				//if overall {
-				//	return "ok"
-				//}
-				return "ok"
-			}(),
-			"now":    time.Now().UTC().Format(time.RFC3339),
-			"checks": "results",
-		})
-	})
-
-	addr := config.GetEnv("APP_METRIC_ADDR", ":2121")
-	a.GetLogger().Println("metrics server running, listening addr", addr)
-	router.Run(addr)
+	group.GET("/readyz", a.readyz)
+	if len(a.consumerAdminGuards) > 0 {
+		admin := group.Group("/admin/consumers", a.consumerAdminGuards...)
+		admin.POST("/:name/pause", a.setConsumerPaused(true))
+		admin.POST("/:name/resume", a.setConsumerPaused(false))
+	}
 }
 
-func (a *App) registerAPI(ctx context.Context) {
+// registerAPI serves the gateway, custom routes and docs until the server is
+// shut down. Serve errors are sent to errCh.
+func (a *App) registerAPI(ctx context.Context, errCh chan error) {
 	router := a.router
-	router.Use(gin.Recovery())
+	router.Use(obs.HTTPRecovery())
 	router.Use(obs.HTTPTracing(a.Name + "-http"))
-	router.Use(obs.RequestID())
-	router.Use(obs.HTTPLogger())
-	router.Use(cors.New(buildCORSConfig()))
+	router.Use(a.requestIDs.Middleware())
+	router.Use(a.requestLogger.HTTP())
+	router.Use(cors.New(buildCORSConfig(a.requestIDs.Header())))
 
 	if a.spec != "" {
-		specContent := func() ([]byte, error) {
-			specBytes, err := os.ReadFile(a.spec)
+		// Serve merged OpenAPI spec with custom routes at /openapi.json
+		router.GET("/openapi.json", func(c *gin.Context) {
+			specBytes, err := a.specContent()
 			if err != nil {
 				a.GetLogger().Printf("failed to read spec file %s: %v", a.spec, err)
-				//c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read spec file"})
-				return nil, err
-			}
-
-			// Merge custom routes into the spec
-			mergedSpec, err := mergeCustomRoutesIntoSpec(specBytes, a.customRoutes)
-			if err != nil {
-				a.GetLogger().Printf("failed to merge custom routes: %v", err)
-				//c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to merge custom routes"})
-				return nil, err
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read spec file"})
+				return
 			}
 
-			return mergedSpec, nil
+			c.Data(http.StatusOK, "application/json", specBytes)
+		})
+	}
+	for _, m := range a.gatewayMounts {
+		if m.Spec == "" {
+			continue
 		}
-
-		// Serve merged OpenAPI spec with custom routes at /openapi.json
-		router.GET("/openapi.json", func(c *gin.Context) {
-			specBytes, err := specContent()
+		// Serve the spec of each gateway mount at <prefix>/openapi.json
+		router.GET(path.Join(cleanMountPrefix(m.Prefix), "openapi.json"), func(c *gin.Context) {
+			specBytes, err := a.mountSpecContent(m)
 			if err != nil {
-				a.GetLogger().Printf("failed to read spec file %s: %v", a.spec, err)
+				a.GetLogger().Printf("failed to read spec file %s: %v", m.Spec, err)
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read spec file"})
 				return
 			}
@@ -296,8 +512,27 @@ func (a *App) registerAPI(ctx context.Context) {
 		router.GET("/docs", gin.WrapF(docs))
 	}
 
+	// Build request validator from the merged spec (or custom routes only)
+	validatorSpec := []byte("{}")
+	if a.spec != "" {
+		if specBytes, err := a.specContent(); err == nil {
+			validatorSpec = specBytes
+		}
+	} else if merged, err := mergeCustomRoutesIntoSpec(validatorSpec, a.customRoutes, a.securitySchemes); err == nil {
+		validatorSpec = merged
+	}
+	if v, err := newRequestValidator(validatorSpec); err != nil {
+		a.GetLogger().Printf("request validation disabled: %v", err)
+	} else {
+		a.requestValidator = v
+	}
+
 	// Register gateway routes with middleware
 	gwHandler := a.registerGateway(ctx)
+	gwHandlers := []gin.HandlerFunc{WrapH(gwHandler)}
+	if mw := a.gatewayValidation(); mw != nil {
+		gwHandlers = append([]gin.HandlerFunc{mw}, gwHandlers...)
+	}
 
 	if len(a.routeMiddlewares) > 0 {
 		// Register specific route patterns with their middleware
@@ -307,7 +542,7 @@ func (a *App) registerAPI(ctx context.Context) {
 				group := router.Group(prefix)
 				group.Use(rm.Middlewares...)
 				// Register all HTTP methods for the group
-				group.Any("/*any", WrapH(gwHandler))
+				group.Any("/*any", gwHandlers...)
 			}
 		}
 	}
@@ -315,19 +550,85 @@ func (a *App) registerAPI(ctx context.Context) {
 	// Use NoRoute as fallback for all unmatched routes
 	// This avoids conflicts with specific routes like /openapi.json, /docs
 	// NoRoute is called only when no other route matches
-	router.NoRoute(WrapH(gwHandler))
+	router.NoRoute(gwHandlers...)
 
-	addr := config.GetEnv("APP_HTTP_ADDR", ":8080")
-	a.GetLogger().Println("http server running, listening addr", addr)
-	router.Run(addr)
+	srv := a.newHTTPServer(a.getHTTPAddr(), router)
+	for _, hook := range a.httpServerHooks {
+		hook(srv)
+	}
+	a.GetLogger().Println("http server running, listening addr", srv.Addr)
+	state := a.trackRunning("gateway")
+	state.set(true)
+	if err := a.serveHTTP(srv, &a.httpListenAddr); err != nil {
+		errCh <- fmt.Errorf("http server: %w", err)
+	}
+	state.set(false)
+}
+
+// newHTTPServer creates an HTTP server with the default timeouts and
+// registers it for graceful shutdown.
+func (a *App) newHTTPServer(addr string, handler http.Handler) *http.Server {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: DefaultReadHeaderTimeout,
+		IdleTimeout:       DefaultIdleTimeout,
+	}
+	a.shutdown.RegisterHTTPServer(srv)
+	return srv
+}
+
+// serveHTTP serves srv until it is shut down, over TLS when a hook gave it
+// certificates. The address it listens on is stored in bound. It returns nil
+// once the server is shut down.
+func (a *App) serveHTTP(srv *http.Server, bound *atomic.Value) error {
+	lis, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	bound.Store(lis.Addr().String())
+
+	if srv.TLSConfig != nil && (len(srv.TLSConfig.Certificates) > 0 || srv.TLSConfig.GetCertificate != nil) {
+		err = srv.ServeTLS(lis, "", "")
+	} else {
+		err = srv.Serve(lis)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// specContent reads the OpenAPI spec, merges custom routes into it and
+// points it at the public URL.
+func (a *App) specContent() ([]byte, error) {
+	specBytes, err := os.ReadFile(a.spec)
+	if err != nil {
+		a.GetLogger().Printf("failed to read spec file %s: %v", a.spec, err)
+		return nil, err
+	}
+
+	// Merge custom routes into the spec
+	mergedSpec, err := mergeCustomRoutesIntoSpec(specBytes, a.customRoutes, a.securitySchemes)
+	if err != nil {
+		a.GetLogger().Printf("failed to merge custom routes: %v", err)
+		return nil, err
+	}
+
+	// Point the spec at the URL the API is served from
+	mergedSpec, err = applySpecServer(mergedSpec, a.getPublicURL())
+	if err != nil {
+		a.GetLogger().Printf("failed to set spec server: %v", err)
+		return nil, err
+	}
+
+	return mergedSpec, nil
 }
 
 func WrapH(h http.Handler) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id, exist := c.Get("identity")
-		if exist {
-
-			r := c.Request.WithContext(context.WithValue(c.Request.Context(), "identity", id))
+		if id := identity.FromGinContext(c); id != nil {
+			r := c.Request.WithContext(identity.NewContext(c.Request.Context(), id))
 			h.ServeHTTP(c.Writer, r)
 			return
 		}
@@ -341,14 +642,63 @@ func (a *App) registerConsumers(ctx context.Context) {
 	if err != nil {
 		a.GetLogger().Fatal(err)
 	}
-	for _, consumer := range consumers {
+	// Consumers stop pulling when ctx is cancelled; the drain phase waits
+	// for in-flight messages before stores and connections are closed.
+	// Handlers keep a live context for up to the shutdown timeout meanwhile.
+	var wg sync.WaitGroup
+	for _, c := range consumers {
+		if c.GetMetrics() == nil {
+			consumer.WithMetrics(a.GetMetricManager())(c)
+		}
+		if c.GetDrainTimeout() == 0 {
+			consumer.WithDrainTimeout(shutdownTimeout)(c)
+		}
+	}
+	for _, c := range consumers {
+		state := &runState{}
+		a.RegisterReadinessCheck("consumer:"+c.GetName(), consumerCheck(state, c))
+		wg.Add(1)
 		go func() {
-			err := consumer.Start(ctx)
-			if err != nil {
+			defer wg.Done()
+			state.set(true)
+			defer state.set(false)
+			err := c.Start(ctx)
+			if err != nil && !errors.Is(err, context.Canceled) {
 				a.GetLogger().Fatal(err)
 			}
 		}()
 	}
+	a.shutdown.RegisterCleanupInPhase(PhaseDrain, func(ctx context.Context) error {
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// setConsumerPaused pauses or resumes the registered consumer named in the
+// path and reports its state.
+func (a *App) setConsumerPaused(paused bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		consumer, err := a.GetRegistry().GetConsumer(c.Param("name"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if paused {
+			consumer.Pause()
+		} else {
+			consumer.Resume()
+		}
+		c.JSON(http.StatusOK, gin.H{"name": consumer.GetName(), "paused": consumer.Paused()})
+	}
 }
 
 func (a *App) registerWorkers(_ context.Context) {
@@ -357,8 +707,15 @@ func (a *App) registerWorkers(_ context.Context) {
 		a.GetLogger().Fatal(err)
 	}
 	for _, w := range workers {
+		state := a.trackRunning("worker:" + w.Name())
+		a.shutdown.RegisterCleanupInPhase(PhaseStopIntake, func(context.Context) error {
+			w.Stop()
+			return nil
+		})
 		go func() {
+			state.set(true)
 			err := w.Start()
+			state.set(false)
 			if err != nil {
 				a.GetLogger().Fatal(err)
 			}
@@ -377,12 +734,12 @@ func UnaryInterceptor() grpc.UnaryServerInterceptor {
 
 		// Извлекаем identity из metadata
 		if identityJSON := md.Get("x-identity"); len(identityJSON) > 0 {
-			var identity map[string]interface{}
-			if err := json.Unmarshal([]byte(identityJSON[0]), &identity); err != nil {
+			var id identity.Identity
+			if err := json.Unmarshal([]byte(identityJSON[0]), &id); err != nil {
 				slog.Error("Failed to unmarshal identity", "error", err)
 			} else {
 				// Добавляем identity в контекст
-				ctx = context.WithValue(ctx, "identity", identity)
+				ctx = identity.NewContext(ctx, id)
 			}
 		}
 
@@ -390,10 +747,15 @@ func UnaryInterceptor() grpc.UnaryServerInterceptor {
 	}
 }
 
-func (a *App) registerServices(_ context.Context, errCh chan error) {
+func (a *App) registerServices(ctx context.Context, errCh chan error) {
 	if a.isEntityService {
-		// Register Entities service
-		entitiesService := entities.NewTonicaService(a.entityDriver, a.entityDSN)
+		// Register Entities service; its scheduled jobs stop with the app.
+		jobCtx, stopJobs := context.WithCancel(ctx)
+		a.shutdown.RegisterCleanupInPhase(PhaseStopIntake, func(context.Context) error {
+			stopJobs()
+			return nil
+		})
+		entitiesService := entities.NewTonicaService(a.entityDSN, a.entityDriver, entities.WithJobContext(jobCtx))
 		a.GetRegistry().MustRegisterService(entitiesService)
 		slog.Info("registered entities service")
 	}
@@ -405,7 +767,7 @@ func (a *App) registerServices(_ context.Context, errCh chan error) {
 			a.GetLogger().Fatal(err)
 		}
 		// Register Workflows service
-		workflowsService := workflows.NewTonicaService(temporalClient)
+		workflowsService := workflows.NewTonicaService(temporalClient, a.workflowInputSchemas)
 		a.GetRegistry().MustRegisterService(workflowsService)
 		slog.Info("Registered workflows")
 	}
@@ -414,98 +776,301 @@ func (a *App) registerServices(_ context.Context, errCh chan error) {
 	if err != nil {
 		a.GetLogger().Fatal(err)
 	}
-	for _, service := range services {
-		var grpcSrv *grpc.Server
-		var grpcLis net.Listener
-		srvGrpc := service.GetGRPC()
+	var shared []*service.Service
+	for _, svc := range services {
+		if a.usesSharedGRPC(svc) {
+			shared = append(shared, svc)
+			continue
+		}
 
-		grpcLis, err = net.Listen("tcp", service.GetGRPCAddr())
+		grpcLis, err := net.Listen("tcp", svc.GetGRPCAddr())
 		if err != nil {
 			a.GetLogger().Fatal(err)
 		}
-		grpcSrv = grpc.NewServer(
-			obs.GRPCServerStats(),
-			grpc.ChainUnaryInterceptor(
-				UnaryInterceptor(),
-				obs.GRPCRecoverUnary(),
-				obs.GRPCLoggingUnary(),
-			),
-			grpc.ChainStreamInterceptor(
-				obs.GRPCRecoverStream(),
-				obs.GRPCLoggingStream(),
-			),
-		)
-
-		// Register gRPC server for graceful shutdown
-		a.shutdown.RegisterGRPCServer(grpcSrv)
+		routes := serviceRoutes{}
+		grpcSrv, err := a.newGRPCServer(svc.GetTLS(), routes)
+		if err != nil {
+			a.GetLogger().Fatal(fmt.Errorf("service %s: %w", svc.GetName(), err))
+		}
 
-		srvGrpc(grpcSrv, service)
+		a.registerGRPC(grpcSrv, svc)
+		routes.add(svc, a.protoServices[svc.GetName()])
 
+		state := a.trackRunning("service:" + svc.GetName())
 		go func(srv *grpc.Server, addr string) {
 			a.GetLogger().Println("gRPC listening", "addr", addr)
+			state.set(true)
+			defer state.set(false)
 			if err := srv.Serve(grpcLis); err != nil {
 				errCh <- err
 			}
-		}(grpcSrv, service.GetGRPCAddr())
+		}(grpcSrv, svc.GetGRPCAddr())
+	}
+
+	a.registerSharedGRPC(errCh, shared)
+}
+
+// registerSharedGRPC starts the shared gRPC server with the given services and
+// all raw registrations. Nothing is started if there is nothing to serve.
+func (a *App) registerSharedGRPC(errCh chan error, services []*service.Service) {
+	if len(services) == 0 && len(a.rawGRPCRegs) == 0 {
+		return
+	}
+
+	lis, err := net.Listen("tcp", a.grpcAddr)
+	if err != nil {
+		a.GetLogger().Fatal(err)
+	}
+	tlsCfg, err := sharedTLS(services)
+	if err != nil {
+		a.GetLogger().Fatal(err)
+	}
+	routes := serviceRoutes{}
+	srv, err := a.newGRPCServer(tlsCfg, routes)
+	if err != nil {
+		a.GetLogger().Fatal(fmt.Errorf("shared gRPC server: %w", err))
+	}
+	for _, svc := range services {
+		a.registerGRPC(srv, svc)
+		routes.add(svc, a.protoServices[svc.GetName()])
+	}
+	for _, register := range a.rawGRPCRegs {
+		register(srv)
+	}
+
+	// Every service on the shared server is ready while it serves.
+	state := &runState{}
+	for _, svc := range services {
+		a.RegisterReadinessCheck("service:"+svc.GetName(), state.check)
+	}
+	if len(services) == 0 {
+		a.RegisterReadinessCheck("grpc", state.check)
+	}
+
+	go func() {
+		a.GetLogger().Println("shared gRPC listening", "addr", a.grpcAddr, "services", len(services))
+		state.set(true)
+		defer state.set(false)
+		if err := srv.Serve(lis); err != nil {
+			errCh <- err
+		}
+	}()
+}
+
+// usesSharedGRPC reports whether a service is served by the shared gRPC
+// server: it has no address of its own or the same address as the shared one.
+func (a *App) usesSharedGRPC(svc *service.Service) bool {
+	addr := svc.GetGRPCAddr()
+	return addr == "" || addr == a.grpcAddr
+}
+
+// serviceDialAddr returns the address the gateway dials for a service.
+func (a *App) serviceDialAddr(svc *service.Service) string {
+	if !a.usesSharedGRPC(svc) {
+		return svc.GetGRPCAddr()
+	}
+	_, port, err := net.SplitHostPort(a.grpcAddr)
+	if err != nil {
+		return a.grpcAddr
+	}
+	return net.JoinHostPort("localhost", port)
+}
+
+// serviceMetricAttrs returns the gRPC metric attributes of calls served by svc
+// when service metric labels are enabled.
+func (a *App) serviceMetricAttrs(svc *service.Service) []attribute.KeyValue {
+	if !a.serviceMetricLabels || svc == nil {
+		return nil
+	}
+	return []attribute.KeyValue{attribute.String("service", svc.GetName())}
+}
+
+// sharedTLS returns the TLS configuration declared by the services of the
+// shared gRPC server. They must all declare the same one, or none: a service
+// without TLS is not served over TLS because it shares the server.
+func sharedTLS(services []*service.Service) (*service.TLSConfig, error) {
+	if len(services) == 0 {
+		return nil, nil
+	}
+	tlsCfg := services[0].GetTLS()
+	for _, svc := range services[1:] {
+		cfg := svc.GetTLS()
+		if (tlsCfg == nil) != (cfg == nil) || (cfg != nil && *tlsCfg != *cfg) {
+			return nil, fmt.Errorf("services %s and %s on the shared gRPC server declare different TLS configurations", services[0].GetName(), svc.GetName())
+		}
+	}
+	return tlsCfg, nil
+}
+
+// newGRPCServer creates a gRPC server with the framework interceptors and
+// registers it for graceful shutdown. It serves TLS when tlsCfg is set.
+// The interceptors of the services in routes run after the framework ones, and
+// its metrics carry the attributes of the service owning each called method.
+func (a *App) newGRPCServer(tlsCfg *service.TLSConfig, routes serviceRoutes) (*grpc.Server, error) {
+	unary := []grpc.UnaryServerInterceptor{a.requestIDs.GRPCUnary(), UnaryInterceptor()}
+	stream := []grpc.StreamServerInterceptor{a.requestIDs.GRPCStream()}
+	if a.grpcTimeout > 0 {
+		unary = append(unary, a.timeoutUnary())
+	}
+	opts := []grpc.ServerOption{
+		obs.GRPCServerStatsByMethod(func(fullMethod string) []attribute.KeyValue {
+			return a.serviceMetricAttrs(routes.lookup(fullMethod))
+		}),
+		grpc.ChainUnaryInterceptor(append(unary,
+			obs.GRPCRecoverUnary(),
+			a.requestLogger.GRPCUnary(),
+			routes.unary(),
+		)...),
+		grpc.ChainStreamInterceptor(append(stream,
+			obs.GRPCRecoverStream(),
+			a.requestLogger.GRPCStream(),
+			routes.stream(),
+		)...),
+	}
+	if tlsCfg != nil {
+		creds, err := tlsCfg.ServerCredentials()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	srv := grpc.NewServer(opts...)
+	if a.reflectionEnabled() {
+		reflection.Register(srv)
+	}
+
+	// Register gRPC server for graceful shutdown
+	a.shutdown.RegisterGRPCServer(srv)
+	return srv, nil
+}
+
+// reflectionEnabled reports whether the gRPC servers serve reflection.
+func (a *App) reflectionEnabled() bool {
+	if a.grpcReflection != nil {
+		return *a.grpcReflection
+	}
+	return a.cfg != nil && a.cfg.DebugMode()
+}
+
+// serviceRoutes maps proto services to the service that registered them. It
+// is filled before the server serves and read by the server's interceptors.
+type serviceRoutes map[string]*service.Service
+
+func (r serviceRoutes) add(svc *service.Service, protoServices []string) {
+	for _, name := range protoServices {
+		r[name] = svc
+	}
+}
+
+func (r serviceRoutes) lookup(fullMethod string) *service.Service {
+	protoService, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return r[protoService]
+}
+
+// unary runs the unary interceptors of the service owning the called method.
+func (r serviceRoutes) unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		svc := r.lookup(info.FullMethod)
+		if svc == nil {
+			return handler(ctx, req)
+		}
+		interceptors := svc.GetUnaryInterceptors()
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], handler
+			handler = func(ctx context.Context, req any) (any, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+		return handler(ctx, req)
 	}
 }
 
-func (a *App) run(ctx context.Context, errCh chan error) {
+// stream runs the stream interceptors of the service owning the called method.
+func (r serviceRoutes) stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		svc := r.lookup(info.FullMethod)
+		if svc == nil {
+			return handler(srv, ss)
+		}
+		interceptors := svc.GetStreamInterceptors()
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], handler
+			handler = func(srv any, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, next)
+			}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// run blocks until ctx is done or a server fails, then cancels ctx through
+// cancel and shuts everything down gracefully. A server error is returned.
+func (a *App) run(ctx context.Context, cancel context.CancelFunc, errCh chan error) error {
+	var runErr error
 	select {
 	case <-ctx.Done():
 		a.GetLogger().Println("shutdown signal received, starting graceful shutdown...")
+	case runErr = <-errCh:
+		a.GetLogger().Printf("server failed, starting graceful shutdown: %v", runErr)
+	}
+	// Stop consumers and workers started with ctx so the drain phase does
+	// not wait for them until the timeout.
+	cancel()
 
-		// Graceful shutdown with 30 second timeout
-		if err := a.shutdown.Execute(30 * time.Second); err != nil {
-			a.GetLogger().Printf("graceful shutdown error: %v", err)
-		}
-
-		a.GetLogger().Println("shutdown complete")
-		return
-	case err := <-errCh:
-		a.GetLogger().Fatal(err)
+	if err := a.shutdown.Execute(shutdownTimeout); err != nil {
+		a.GetLogger().Printf("graceful shutdown error: %v", err)
 	}
+
+	a.GetLogger().Println("shutdown complete")
+	return runErr
 }
 
-const gatewayCount = 1
+// shutdownTimeout bounds the graceful shutdown of the app.
+const shutdownTimeout = 30 * time.Second
 
-func (a *App) runAio(ctx context.Context, o *obs.Observability) {
-	go a.registerMetrics(ctx, o)
-	errCh := make(chan error, gatewayCount+a.GetRegistry().GetCountWorkers()+a.GetRegistry().GetCountConsumers()+a.GetRegistry().GetCountServices()+a.GetRegistry().GetCountServices())
+const (
+	gatewayCount    = 1
+	metricsCount    = 1
+	sharedGRPCCount = 1
+)
+
+func (a *App) runAio(ctx context.Context, cancel context.CancelFunc, o *obs.Observability) error {
+	errCh := make(chan error, gatewayCount+metricsCount+a.GetRegistry().GetCountWorkers()+a.GetRegistry().GetCountConsumers()+a.GetRegistry().GetCountServices()+a.GetRegistry().GetCountServices()+sharedGRPCCount)
+	a.registerMetricsWithAPI(ctx, o, errCh)
 	a.registerServices(ctx, errCh)
-	go a.registerAPI(ctx)
+	go a.registerAPI(ctx, errCh)
 	go a.registerWorkers(ctx)
 	go a.registerConsumers(ctx)
 
-	a.run(ctx, errCh)
+	return a.run(ctx, cancel, errCh)
 }
 
-func (a *App) runService(ctx context.Context, o *obs.Observability) {
-	go a.registerMetrics(ctx, o)
-	errCh := make(chan error, a.GetRegistry().GetCountServices())
+func (a *App) runService(ctx context.Context, cancel context.CancelFunc, o *obs.Observability) error {
+	errCh := make(chan error, metricsCount+a.GetRegistry().GetCountServices()+sharedGRPCCount)
+	go a.registerMetrics(ctx, o, errCh)
 	a.registerServices(ctx, errCh)
-	a.run(ctx, errCh)
+	return a.run(ctx, cancel, errCh)
 }
 
-func (a *App) runWorker(ctx context.Context, o *obs.Observability) {
-	go a.registerMetrics(ctx, o)
+func (a *App) runWorker(ctx context.Context, cancel context.CancelFunc, o *obs.Observability) error {
+	errCh := make(chan error, metricsCount+a.GetRegistry().GetCountWorkers())
+	go a.registerMetrics(ctx, o, errCh)
 	go a.registerWorkers(ctx)
-	errCh := make(chan error, a.GetRegistry().GetCountWorkers())
-	a.run(ctx, errCh)
+	return a.run(ctx, cancel, errCh)
 }
 
-func (a *App) runConsumer(ctx context.Context, o *obs.Observability) {
-	go a.registerMetrics(ctx, o)
+func (a *App) runConsumer(ctx context.Context, cancel context.CancelFunc, o *obs.Observability) error {
+	errCh := make(chan error, metricsCount+a.GetRegistry().GetCountConsumers())
+	go a.registerMetrics(ctx, o, errCh)
 	go a.registerConsumers(ctx)
-	errCh := make(chan error, a.GetRegistry().GetCountConsumers())
-	a.run(ctx, errCh)
+	return a.run(ctx, cancel, errCh)
 }
 
-func (a *App) runGateway(ctx context.Context, o *obs.Observability) {
-	go a.registerMetrics(ctx, o)
-	go a.registerAPI(ctx)
-	errCh := make(chan error, gatewayCount)
-	a.run(ctx, errCh)
+func (a *App) runGateway(ctx context.Context, cancel context.CancelFunc, o *obs.Observability) error {
+	errCh := make(chan error, gatewayCount+metricsCount)
+	a.registerMetricsWithAPI(ctx, o, errCh)
+	go a.registerAPI(ctx, errCh)
+	return a.run(ctx, cancel, errCh)
 }
 
 func (a *App) registerFrameworkMetrics() {
@@ -540,32 +1105,24 @@ func (a *App) registerFrameworkMetrics() {
 	a.GetMetricManager().NewCounter("app_pubsub_publish_success_count", "Number of successful publish operations.")
 	a.GetMetricManager().NewCounter("app_pubsub_subscribe_total_count", "Number of total subscribe operations.")
 	a.GetMetricManager().NewCounter("app_pubsub_subscribe_success_count", "Number of successful subscribe operations.")
+	a.GetMetricManager().NewCounter("app_pubsub_consume_failed_count", "Number of failed message handler calls.")
+
+	// gRPC metrics
+	a.GetMetricManager().NewCounter(grpcTimeoutMetric, "Number of gRPC calls cancelled by the default deadline.")
 }
 
-func buildCORSConfig() cors.Config {
+func buildCORSConfig(requestIDHeader string) cors.Config {
 	cfg := cors.Config{
 		AllowAllOrigins:  true,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Authorization", "Content-Type", "X-Request-ID"},
-		ExposeHeaders:    []string{"Content-Length", "X-Request-ID"},
+		AllowHeaders:     []string{"Authorization", "Content-Type", requestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", requestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}
 	if v := strings.TrimSpace(os.Getenv("APP_CORS_ORIGINS")); v != "" {
 		cfg.AllowAllOrigins = false
-		cfg.AllowOrigins = splitAndTrim(v)
+		cfg.AllowOrigins = config.SplitAndTrim(v)
 	}
 	return cfg
 }
-
-func splitAndTrim(s string) []string {
-	parts := strings.Split(s, ",")
-	out := make([]string, 0, len(parts))
-	for _, p := range parts {
-		p = strings.TrimSpace(p)
-		if p != "" {
-			out = append(out, p)
-		}
-	}
-	return out
-}
@@ -1,13 +1,37 @@
 package tonica
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tonica-go/tonica/pkg/tonica/config"
+	"github.com/tonica-go/tonica/pkg/tonica/consumer"
+	"github.com/tonica-go/tonica/pkg/tonica/identity"
+	obs "github.com/tonica-go/tonica/pkg/tonica/observabillity"
+	entitiespb "github.com/tonica-go/tonica/pkg/tonica/proto/entities"
+	"github.com/tonica-go/tonica/pkg/tonica/service"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 func TestNewApp(t *testing.T) {
@@ -124,6 +148,12 @@ func TestOptions(t *testing.T) {
 		assert.NotNil(t, app.logger)
 	})
 
+	t.Run("WithoutPrometheus", func(t *testing.T) {
+		app := NewApp(WithoutPrometheus())
+		assert.True(t, app.disablePrometheus)
+		assert.NotNil(t, app.metricsManager)
+	})
+
 	t.Run("WithRegistry", func(t *testing.T) {
 		// Can't easily test custom registry, but verify option works
 		tempApp := NewApp()
@@ -134,7 +164,7 @@ func TestOptions(t *testing.T) {
 
 func TestBuildCORSConfig(t *testing.T) {
 	t.Run("should allow all origins by default", func(t *testing.T) {
-		cfg := buildCORSConfig()
+		cfg := buildCORSConfig(obs.DefaultRequestIDHeader)
 
 		assert.True(t, cfg.AllowAllOrigins)
 		assert.Contains(t, cfg.AllowMethods, "GET")
@@ -145,7 +175,7 @@ func TestBuildCORSConfig(t *testing.T) {
 	t.Run("should parse custom origins from env", func(t *testing.T) {
 		t.Setenv("APP_CORS_ORIGINS", "http://localhost:3000, https://example.com")
 
-		cfg := buildCORSConfig()
+		cfg := buildCORSConfig(obs.DefaultRequestIDHeader)
 
 		assert.False(t, cfg.AllowAllOrigins)
 		assert.Len(t, cfg.AllowOrigins, 2)
@@ -154,6 +184,191 @@ func TestBuildCORSConfig(t *testing.T) {
 	})
 }
 
+func TestApp_RequestIDHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	app := NewApp(WithRequestIDHeader("X-Correlation-ID"), WithRequestIDFormat(RequestIDUUID))
+	// Another app in the same process keeps its own configuration.
+	NewApp()
+
+	router := gin.New()
+	router.Use(app.requestIDs.Middleware())
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, c.Request.Header.Get("X-Correlation-ID"))
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	generated := rec.Header().Get("X-Correlation-ID")
+	_, err := uuid.Parse(generated)
+	assert.NoError(t, err)
+	assert.Equal(t, generated, rec.Body.String(), "generated id is forwarded to the gateway")
+	assert.Empty(t, rec.Header().Get("X-Request-ID"))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Correlation-ID", "inbound-1")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, "inbound-1", rec.Body.String())
+
+	assert.Contains(t, buildCORSConfig(app.requestIDs.Header()).ExposeHeaders, "X-Correlation-ID")
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-correlation-id", "grpc-1"))
+	_, err = app.requestIDs.GRPCUnary()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		assert.Equal(t, "grpc-1", obs.RequestIDFromContext(ctx))
+		return nil, nil
+	})
+	require.NoError(t, err)
+}
+
+func TestApp_LogConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	app := NewApp(WithLogConfig(LogConfig{
+		FieldNames:    map[string]string{"trace_id": "traceId"},
+		Headers:       []string{"Authorization", "X-Tenant", "X-Secret"},
+		RedactHeaders: []string{"X-Secret"},
+		HTTPMessage:   "request served",
+	}))
+	// another app in the process keeps its own configuration
+	NewApp()
+
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	router := gin.New()
+	router.Use(app.requestLogger.HTTP())
+	router.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("X-Tenant", "acme")
+	req.Header.Set("X-Secret", "hunter2")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "request served", line["msg"])
+	assert.Contains(t, line, "traceId")
+	assert.NotContains(t, line, "trace_id")
+	assert.Equal(t, map[string]any{
+		"Authorization": obs.RedactedValue,
+		"X-Tenant":      "acme",
+		"X-Secret":      obs.RedactedValue,
+	}, line["headers"])
+	assert.NotContains(t, buf.String(), "Bearer token")
+	assert.NotContains(t, buf.String(), "hunter2")
+}
+
+func TestRequestLogLines(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	decode := func() map[string]any {
+		t.Helper()
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+		buf.Reset()
+		return line
+	}
+
+	t.Run("http", func(t *testing.T) {
+		router := gin.New()
+		router.Use(obs.RequestID(), obs.HTTPLogger())
+		router.GET("/items", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+		req := httptest.NewRequest(http.MethodGet, "/items", nil)
+		req.Header.Set("X-Request-ID", "req-1")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		line := decode()
+		assert.Equal(t, "http request", line["msg"])
+		assert.Equal(t, "WARN", line["level"])
+		assert.Equal(t, "http", line["protocol"])
+		assert.Equal(t, "GET", line["method"])
+		assert.Equal(t, "/items", line["path"])
+		assert.Equal(t, float64(http.StatusNotFound), line["status"])
+		assert.Equal(t, "req-1", line["request_id"])
+		assert.Len(t, line["span_id"], 16)
+		assert.NotContains(t, line, "!BADKEY")
+	})
+
+	t.Run("grpc", func(t *testing.T) {
+		interceptor := obs.GRPCLoggingUnary()
+		info := &grpc.UnaryServerInfo{FullMethod: "/entities.v1.EntityService/ListRecords"}
+		_, _ = interceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+			return nil, status.Error(codes.NotFound, "missing")
+		})
+
+		line := decode()
+		assert.Equal(t, "grpc call", line["msg"])
+		assert.Equal(t, "WARN", line["level"])
+		assert.Equal(t, "grpc", line["protocol"])
+		assert.Equal(t, info.FullMethod, line["method"])
+		assert.Equal(t, codes.NotFound.String(), line["code"])
+		assert.Contains(t, line["error"], "missing")
+		assert.Len(t, line["span_id"], 16)
+		assert.NotContains(t, line, "!BADKEY")
+	})
+}
+
+func TestGRPCPayloadLog(t *testing.T) {
+	app := NewApp(WithLogConfig(LogConfig{GRPCPayloads: true, RedactFields: []string{"cardNumber"}, PayloadLimit: 128}))
+
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	req, err := structpb.NewStruct(map[string]any{
+		"name":        "Acme",
+		"password":    "hunter2",
+		"card_number": "4242",
+		"nested":      map[string]any{"token": "abc"},
+	})
+	require.NoError(t, err)
+	resp, err := structpb.NewStruct(map[string]any{"notes": strings.Repeat("x", 200)})
+	require.NoError(t, err)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/billing.v1.BillingService/Charge"}
+	_, err = app.requestLogger.GRPCUnary()(context.Background(), req, info, func(context.Context, any) (any, error) {
+		return resp, nil
+	})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var line map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &line))
+	assert.Equal(t, obs.DefaultGRPCPayloadMessage, line["msg"])
+	assert.Equal(t, "DEBUG", line["level"])
+	assert.Equal(t, info.FullMethod, line["method"])
+
+	var logged map[string]any
+	require.NoError(t, json.Unmarshal([]byte(line["request"].(string)), &logged))
+	assert.Equal(t, map[string]any{
+		"name":        "Acme",
+		"password":    obs.RedactedValue,
+		"card_number": obs.RedactedValue,
+		"nested":      map[string]any{"token": obs.RedactedValue},
+	}, logged)
+	assert.True(t, strings.HasSuffix(line["response"].(string), "...(truncated)"))
+	assert.NotContains(t, buf.String(), "hunter2")
+
+	t.Run("should stay silent above debug level", func(t *testing.T) {
+		buf.Reset()
+		slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+		_, _ = app.requestLogger.GRPCUnary()(context.Background(), req, info, func(context.Context, any) (any, error) {
+			return resp, nil
+		})
+		assert.NotContains(t, buf.String(), obs.DefaultGRPCPayloadMessage)
+	})
+}
+
 func TestSplitAndTrim(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -194,7 +409,7 @@ func TestSplitAndTrim(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := splitAndTrim(tt.input)
+			result := config.SplitAndTrim(tt.input)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -235,3 +450,584 @@ func TestApp_Shutdown_Integration(t *testing.T) {
 		assert.True(t, called)
 	})
 }
+
+func TestApp_RegisterRawGRPC(t *testing.T) {
+	t.Run("should ignore nil registrations", func(t *testing.T) {
+		app := NewApp()
+		app.RegisterRawGRPC(nil)
+		assert.Empty(t, app.rawGRPCRegs)
+	})
+
+	t.Run("should register onto the shared server", func(t *testing.T) {
+		app := NewApp(WithGRPCAddr("127.0.0.1:0"))
+		assert.Equal(t, "127.0.0.1:0", app.grpcAddr)
+
+		var registered []*grpc.Server
+		register := func(srv *grpc.Server) { registered = append(registered, srv) }
+		app.RegisterRawGRPC(register)
+		app.RegisterRawGRPC(register)
+
+		errCh := make(chan error, sharedGRPCCount)
+		app.registerSharedGRPC(errCh, nil)
+
+		assert.Len(t, registered, 2)
+		assert.Same(t, registered[0], registered[1])
+		assert.NoError(t, app.shutdown.Execute(time.Second))
+	})
+}
+
+func TestApp_SharedGRPC(t *testing.T) {
+	app := NewApp(WithGRPCAddr(":9100"))
+
+	shared := service.NewService(service.WithName("shared"))
+	sameAddr := service.NewService(service.WithName("same"), service.WithGRPCAddr(":9100"))
+	isolated := service.NewService(service.WithName("isolated"), service.WithGRPCAddr(":9200"))
+
+	assert.True(t, app.usesSharedGRPC(shared))
+	assert.True(t, app.usesSharedGRPC(sameAddr))
+	assert.False(t, app.usesSharedGRPC(isolated))
+
+	assert.Equal(t, "localhost:9100", app.serviceDialAddr(shared))
+	assert.Equal(t, "localhost:9100", app.serviceDialAddr(sameAddr))
+	assert.Equal(t, ":9200", app.serviceDialAddr(isolated))
+}
+
+func TestApp_GRPCReflection(t *testing.T) {
+	reflected := func(app *App) bool {
+		srv, err := app.newGRPCServer(nil, nil)
+		require.NoError(t, err)
+		_, ok := srv.GetServiceInfo()["grpc.reflection.v1.ServerReflection"]
+		return ok
+	}
+
+	debug := config.NewConfig(config.WithDebugMode(true))
+	assert.False(t, reflected(NewApp()))
+	assert.True(t, reflected(NewApp(WithConfig(debug))))
+	assert.False(t, reflected(NewApp(WithConfig(debug), WithGRPCReflection(false))))
+	assert.True(t, reflected(NewApp(WithGRPCReflection(true))))
+}
+
+func TestApp_DefaultGRPCTimeout(t *testing.T) {
+	app := NewApp(WithDefaultGRPCTimeout(20 * time.Millisecond))
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.Service/Slow"}
+	slow := func(ctx context.Context, _ any) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	t.Run("should cancel calls without a deadline", func(t *testing.T) {
+		_, err := app.timeoutUnary()(context.Background(), nil, info, slow)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("should keep the deadline of the caller", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		want, _ := ctx.Deadline()
+
+		_, err := app.timeoutUnary()(ctx, nil, info, func(ctx context.Context, _ any) (any, error) {
+			got, _ := ctx.Deadline()
+			assert.Equal(t, want, got)
+			return nil, nil
+		})
+		assert.NoError(t, err)
+	})
+}
+
+func TestApp_MissingGateway(t *testing.T) {
+	newEntities := func(name string) *service.Service {
+		return service.NewService(
+			service.WithName(name),
+			service.WithGRPC(func(srv *grpc.Server, _ *service.Service) {
+				entitiespb.RegisterEntityServiceServer(srv, entitiespb.UnimplementedEntityServiceServer{})
+			}),
+		)
+	}
+
+	assert.True(t, hasHTTPRules("entities.v1.EntityService"))
+	assert.False(t, hasHTTPRules("grpc.health.v1.Health"))
+	assert.False(t, hasHTTPRules("missing.v1.Service"))
+
+	var registered []string
+	service.RegisterGatewayHandler("entities.v1.EntityService",
+		func(_ context.Context, _ *runtime.ServeMux, target string, _ []grpc.DialOption) error {
+			registered = append(registered, target)
+			return nil
+		})
+
+	t.Run("should only warn without auto gateway", func(t *testing.T) {
+		app := NewApp()
+		svc := newEntities("plain")
+		app.registerGRPC(grpc.NewServer(), svc)
+		assert.Equal(t, []string{"entities.v1.EntityService"}, app.protoServices["plain"])
+
+		registered = nil
+		assert.NoError(t, app.registerMissingGateway(context.Background(), runtime.NewServeMux(), svc, nil))
+		assert.Empty(t, registered)
+	})
+
+	t.Run("should register the generated handler", func(t *testing.T) {
+		app := NewApp(WithAutoGateway(), WithGRPCAddr(":9300"))
+		svc := newEntities("auto")
+		app.registerGRPC(grpc.NewServer(), svc)
+
+		registered = nil
+		assert.NoError(t, app.registerMissingGateway(context.Background(), runtime.NewServeMux(), svc, nil))
+		assert.Equal(t, []string{"localhost:9300"}, registered)
+	})
+}
+
+func TestApp_ServiceMetricLabels(t *testing.T) {
+	app := NewApp(WithServiceMetricLabels())
+	assert.True(t, app.serviceMetricLabels)
+
+	svc := service.NewService(service.WithName("entities"))
+	assert.Equal(t, []attribute.KeyValue{attribute.String("service", "entities")}, app.serviceMetricAttrs(svc))
+	assert.Nil(t, NewApp().serviceMetricAttrs(svc))
+	assert.Nil(t, app.serviceMetricAttrs(nil))
+
+	app.protoServices["entities"] = []string{"entities.v1.EntityService"}
+	owners := app.protoServiceOwners()
+
+	annotate := func(method string) context.Context {
+		req := httptest.NewRequest(http.MethodGet, "/v1/entities", nil)
+		ctx, err := runtime.AnnotateContext(context.Background(), runtime.NewServeMux(), req, method)
+		require.NoError(t, err)
+		return ctx
+	}
+	assert.Equal(t, "entities", gatewayMetricService(annotate("/entities.v1.EntityService/ListRecords"), owners))
+	assert.Equal(t, "reports.v1.ReportsService", gatewayMetricService(annotate("/reports.v1.ReportsService/GetReport"), owners))
+	assert.Empty(t, gatewayMetricService(context.Background(), owners))
+}
+
+func TestApp_MethodMiddleware(t *testing.T) {
+	var calls []string
+	requireAdmin := func(ctx context.Context, method string) error {
+		calls = append(calls, method)
+		if identity.FromContext(ctx) == nil {
+			return status.Error(codes.PermissionDenied, "admin required")
+		}
+		return nil
+	}
+	app := NewApp(
+		WithMethodMiddleware([]string{"/pkg.UserService/DeleteUser"}, requireAdmin),
+		WithMethodMiddleware([]string{"/pkg.AdminService/*"}, requireAdmin),
+	)
+
+	invoked := 0
+	invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		invoked++
+		return nil
+	}
+	interceptor := app.methodMiddlewareUnary()
+
+	err := interceptor(context.Background(), "/pkg.UserService/DeleteUser", nil, nil, nil, invoker)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	err = interceptor(context.Background(), "/pkg.AdminService/Purge", nil, nil, nil, invoker)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, 0, invoked)
+
+	admin := identity.NewContext(context.Background(), identity.NewIdentity("admin-1"))
+	assert.NoError(t, interceptor(admin, "/pkg.UserService/DeleteUser", nil, nil, nil, invoker))
+	assert.NoError(t, interceptor(context.Background(), "/pkg.UserService/GetUser", nil, nil, nil, invoker))
+	assert.Equal(t, 2, invoked)
+	assert.Equal(t, []string{"/pkg.UserService/DeleteUser", "/pkg.AdminService/Purge", "/pkg.UserService/DeleteUser"}, calls)
+}
+
+func TestApp_GatewayLimits(t *testing.T) {
+	routes := httpRuleMethods(nil)
+	assert.Equal(t, "/entities.v1.EntityService/GetEntity", routes["GET /api/v1/entities/{id=*}"])
+	assert.Equal(t, "/entities.v1.EntityService/ListRecords", routes["POST /api/v1/entities/{entity=*}/search"])
+	assert.Equal(t, "/entities.v1.EntityService/ListRecords", routes["GET /api/v1/entities/{entity=*}"])
+	assert.Contains(t, routes, "POST /api/v1/workflows/trigger")
+
+	app := NewApp(
+		WithGatewayLimits(GatewayLimits{MaxBodyBytes: 8, Timeout: time.Hour}),
+		WithMethodLimits([]string{"/entities.v1.EntityService/ListRecords"}, GatewayLimits{MaxBodyBytes: 64}),
+	)
+	entitiesSvc := service.NewService(service.WithName("entities"))
+	app.protoServices["entities"] = []string{"entities.v1.EntityService"}
+	scoped := httpRuleMethods(app.gatewayProtoServices([]*service.Service{entitiesSvc}))
+	assert.Equal(t, "/entities.v1.EntityService/GetEntity", scoped["GET /api/v1/entities/{id=*}"])
+	assert.NotContains(t, scoped, "POST /api/v1/workflows/trigger", "routes of services outside the mux should not be looked up")
+	assert.Nil(t, app.gatewayProtoServices([]*service.Service{entitiesSvc, service.NewService(service.WithName("remote"))}))
+
+	assert.Equal(t, GatewayLimits{MaxBodyBytes: 64, Timeout: time.Hour}, app.gatewayLimits("/entities.v1.EntityService/ListRecords"))
+	assert.Equal(t, GatewayLimits{MaxBodyBytes: 8, Timeout: time.Hour}, app.gatewayLimits("/entities.v1.EntityService/GetEntity"))
+
+	mux := runtime.NewServeMux(runtime.WithMiddlewares(app.gatewayLimitsMiddleware(nil)))
+	var deadline time.Time
+	handler := func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		deadline, _ = r.Context().Deadline()
+		if _, err := io.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	require.NoError(t, mux.HandlePath(http.MethodPost, "/api/v1/entities/{entity}/search", handler))
+	require.NoError(t, mux.HandlePath(http.MethodPost, "/other", handler))
+
+	post := func(path string, size int) int {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(strings.Repeat("x", size))))
+		return w.Code
+	}
+	assert.Equal(t, http.StatusOK, post("/api/v1/entities/account/search", 32))
+	assert.WithinDuration(t, time.Now().Add(time.Hour), deadline, time.Minute)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post("/api/v1/entities/account/search", 100))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post("/other", 32))
+	assert.Equal(t, http.StatusOK, post("/other", 8))
+}
+
+func TestIdentityPropagation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// gin -> gateway request context
+	var gatewayID identity.Identity
+	router := gin.New()
+	router.Use(identity.Middleware(identity.HeaderExtractor("X-User-ID", "", "")))
+	router.Any("/*any", WrapH(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gatewayID = identity.FromContext(r.Context())
+	})))
+	req := httptest.NewRequest(http.MethodGet, "/v1/items", nil)
+	req.Header.Set("X-User-ID", "user-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "user-1", gatewayID.GetID())
+
+	// gateway metadata -> gRPC handler context
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-identity", `{"id":"user-1","role":"admin"}`))
+	_, err := UnaryInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+		id := identity.FromContext(ctx)
+		assert.Equal(t, "user-1", id.GetID())
+		assert.Equal(t, "admin", id.GetRole())
+		return nil, nil
+	})
+	require.NoError(t, err)
+}
+
+func TestApp_HTTPServer(t *testing.T) {
+	app := NewApp(WithHTTPServer(func(srv *http.Server) {
+		srv.MaxHeaderBytes = 1 << 10
+	}))
+	require.Len(t, app.httpServerHooks, 1)
+
+	srv := app.newHTTPServer("127.0.0.1:0", app.router)
+	assert.Equal(t, DefaultReadHeaderTimeout, srv.ReadHeaderTimeout)
+	assert.Equal(t, DefaultIdleTimeout, srv.IdleTimeout)
+	app.httpServerHooks[0](srv)
+	assert.Equal(t, 1<<10, srv.MaxHeaderBytes)
+
+	done := make(chan struct{})
+	go func() {
+		assert.NoError(t, app.serveHTTP(srv, &app.httpListenAddr))
+		close(done)
+	}()
+	require.Eventually(t, func() bool { return app.HTTPAddr() != "" }, time.Second, 10*time.Millisecond)
+	assert.NotEqual(t, "127.0.0.1:0", app.HTTPAddr())
+	require.NoError(t, app.shutdown.Execute(time.Second))
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("http server was not shut down")
+	}
+}
+
+func TestApp_RunServerError(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+
+	app := NewApp(WithMetricsAddr(lis.Addr().String()))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errCh := make(chan error, metricsCount)
+	go app.registerMetrics(ctx, nil, errCh)
+
+	// A consumer stands in here: it stops once ctx is cancelled and the
+	// drain phase waits for it.
+	stopped := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		close(stopped)
+	}()
+	app.shutdown.RegisterCleanupInPhase(PhaseDrain, func(ctx context.Context) error {
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	var closed atomic.Bool
+	app.shutdown.RegisterCleanup(func(context.Context) error {
+		closed.Store(true)
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- app.run(ctx, cancel, errCh) }()
+
+	select {
+	case err := <-done:
+		assert.ErrorContains(t, err, "metrics server")
+	case <-time.After(2 * time.Second):
+		t.Fatal("listen error was not returned")
+	}
+	assert.True(t, closed.Load(), "resources are closed after a server fails")
+}
+
+func TestApp_ListenAddrs(t *testing.T) {
+	t.Setenv("APP_HTTP_ADDR", ":8081")
+	t.Setenv("APP_METRIC_ADDR", ":2122")
+
+	app := NewApp()
+	assert.Equal(t, ":8081", app.getHTTPAddr())
+	assert.Equal(t, ":2122", app.getMetricsAddr())
+	assert.Empty(t, app.HTTPAddr())
+
+	app = NewApp(WithHTTPAddr("127.0.0.1:0"), WithMetricsAddr("127.0.0.1:0"))
+	assert.Equal(t, "127.0.0.1:0", app.getHTTPAddr())
+	assert.Equal(t, "127.0.0.1:0", app.getMetricsAddr())
+}
+
+func TestServiceRoutes(t *testing.T) {
+	var calls []string
+	tag := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			calls = append(calls, name)
+			return handler(ctx, req)
+		}
+	}
+	billing := service.NewService(service.WithName("billing"), service.WithUnaryInterceptors(tag("first"), tag("second")))
+	users := service.NewService(service.WithName("users"))
+
+	routes := serviceRoutes{}
+	routes.add(billing, []string{"billing.v1.BillingService"})
+	routes.add(users, []string{"users.v1.UserService"})
+	interceptor := routes.unary()
+	handler := func(context.Context, any) (any, error) {
+		calls = append(calls, "handler")
+		return nil, nil
+	}
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/billing.v1.BillingService/Charge"}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second", "handler"}, calls)
+
+	calls = nil
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/users.v1.UserService/Get"}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"handler"}, calls)
+}
+
+func TestApp_Readiness(t *testing.T) {
+	app := NewApp()
+	router := gin.New()
+	router.GET("/readyz", app.readyz)
+
+	readyz := func() (int, map[string]ReadinessResult) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var body struct {
+			Checks map[string]ReadinessResult `json:"checks"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return w.Code, body.Checks
+	}
+
+	code, checks := readyz()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, checks)
+
+	state := app.trackRunning("worker:billing")
+	app.RegisterReadinessCheck("db", func(ctx context.Context) error { return nil })
+
+	code, checks = readyz()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, ReadinessResult{Status: ReadinessDown, Error: "not running"}, checks["worker:billing"])
+	assert.Equal(t, ReadinessResult{Status: ReadinessOK}, checks["db"])
+
+	state.set(true)
+	code, _ = readyz()
+	assert.Equal(t, http.StatusOK, code)
+
+	app.RegisterReadinessCheck("db", func(ctx context.Context) error { return errors.New("connection refused") })
+	code, checks = readyz()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, ReadinessResult{Status: ReadinessDown, Error: "connection refused"}, checks["db"])
+}
+
+func TestApp_ConsumerAdmin(t *testing.T) {
+	app := NewApp()
+	orders := consumer.NewConsumer(consumer.WithName("orders"), consumer.WithTopic("orders.created"))
+	app.GetRegistry().MustRegisterConsumer(orders)
+
+	router := gin.New()
+	router.POST("/admin/consumers/:name/pause", app.setConsumerPaused(true))
+	router.POST("/admin/consumers/:name/resume", app.setConsumerPaused(false))
+	post := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		return w
+	}
+
+	w := post("/admin/consumers/orders/pause")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"name":"orders","paused":true}`, w.Body.String())
+	assert.True(t, orders.Paused())
+
+	w = post("/admin/consumers/orders/resume")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, orders.Paused())
+
+	w = post("/admin/consumers/missing/pause")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestApp_ConsumerAdminRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	pause := func(app *App, header string) int {
+		orders := consumer.NewConsumer(consumer.WithName("orders"), consumer.WithTopic("orders.created"))
+		app.GetRegistry().MustRegisterConsumer(orders)
+		router := gin.New()
+		app.mountMetrics(&router.RouterGroup, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/consumers/orders/pause", nil)
+		if header != "" {
+			req.Header.Set("X-Admin", header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	adminOnly := func(c *gin.Context) {
+		if c.GetHeader("X-Admin") == "" {
+			c.AbortWithStatus(http.StatusForbidden)
+		}
+	}
+
+	assert.Equal(t, http.StatusNotFound, pause(NewApp(), "1"), "admin endpoints should be off by default")
+	assert.Equal(t, http.StatusForbidden, pause(NewApp(WithConsumerAdmin(adminOnly)), ""))
+	assert.Equal(t, http.StatusOK, pause(NewApp(WithConsumerAdmin(adminOnly)), "1"))
+	assert.Panics(t, func() { WithConsumerAdmin(nil)(NewApp()) })
+}
+
+func TestApp_MetricsOnAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	internalOnly := func(c *gin.Context) {
+		if c.GetHeader("X-Internal") == "" {
+			c.AbortWithStatus(http.StatusForbidden)
+		}
+	}
+	app := NewApp(WithMetricsOnAPI("/internal", internalOnly))
+	metricsHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("app_up 1"))
+	})
+	app.registerMetricsWithAPI(context.Background(), &obs.Observability{MetricsHandler: metricsHandler}, make(chan error, metricsCount))
+
+	for _, path := range []string{"/internal/metrics", "/internal/healthz", "/internal/readyz"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Internal", "1")
+		rec := httptest.NewRecorder()
+		app.GetRouter().ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+
+		rec = httptest.NewRecorder()
+		app.GetRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusForbidden, rec.Code, "guard should apply to %s", path)
+	}
+	assert.Empty(t, app.GetMetricRouter().Routes(), "metrics port should not be used")
+
+	fallback := NewApp(WithMetricsOnAPI(""))
+	fallback.registerMetricsWithAPI(context.Background(), nil, make(chan error, metricsCount))
+	// The Prometheus handler reads the process-wide registry that every App in
+	// this package registers with, so check the route instead of scraping it.
+	var served bool
+	for _, route := range fallback.GetRouter().Routes() {
+		served = served || (route.Method == http.MethodGet && route.Path == "/metrics")
+	}
+	assert.True(t, served, "prometheus handler should serve /metrics without obs")
+	rec := httptest.NewRecorder()
+	fallback.GetRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code, "pprof should not be served on the API")
+}
+
+func TestApp_GatewayMounts(t *testing.T) {
+	v1 := service.NewService(service.WithName("orders-v1"))
+	v2 := service.NewService(service.WithName("orders-v2"))
+	users := service.NewService(service.WithName("users"))
+
+	app := NewApp(
+		WithGatewayMount(GatewayMount{Prefix: "v1", Services: []string{"orders-v1", "users"}}),
+		WithGatewayMount(GatewayMount{Prefix: "/v2/", Services: []string{"orders-v2", "users"}}),
+	)
+	mounts, unmounted, err := app.splitGatewayMounts([]*service.Service{v1, v2, users, service.NewService(service.WithName("health"))})
+	require.NoError(t, err)
+	require.Len(t, mounts, 2)
+	assert.Equal(t, "/v1", mounts[0].prefix)
+	assert.Equal(t, []*service.Service{v1, users}, mounts[0].services)
+	assert.Equal(t, "/v2", mounts[1].prefix)
+	assert.Equal(t, []*service.Service{v2, users}, mounts[1].services)
+	require.Len(t, unmounted, 1)
+	assert.Equal(t, "health", unmounted[0].GetName())
+
+	bad := NewApp(
+		WithGatewayMount(GatewayMount{Prefix: "/v1", Services: []string{"missing"}}),
+		WithGatewayMount(GatewayMount{Prefix: "/v1/"}),
+		WithGatewayMount(GatewayMount{Prefix: "/"}),
+	)
+	_, _, err = bad.splitGatewayMounts(nil)
+	var validationErr *config.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []string{
+		`gateway mount /v1: unknown service "missing"`,
+		`gateway mount /v1: prefix is used twice`,
+		`gateway mount "/": prefix must not be empty`,
+	}, validationErr.Problems)
+
+	echo := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(name + " " + r.URL.Path))
+		})
+	}
+	gw := &gatewayMux{
+		mounts: []mountedGateway{
+			{prefix: "/v2", handler: echo("v2")},
+			{prefix: "/v2/beta", handler: echo("beta")},
+		},
+		fallback: echo("default"),
+	}
+	gw.sortMounts()
+	for path, want := range map[string]string{
+		"/v2/orders/1":   "v2 /orders/1",
+		"/v2/beta/items": "beta /items",
+		"/v2beta/orders": "default /v2beta/orders",
+		"/healthz":       "default /healthz",
+	} {
+		rec := httptest.NewRecorder()
+		gw.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, want, rec.Body.String(), path)
+	}
+}
+
+func TestPrefixSpecServer(t *testing.T) {
+	spec, err := applySpecServer([]byte(`{"openapi":"3.0.0","paths":{}}`), "https://api.example.com/billing")
+	require.NoError(t, err)
+	spec, err = prefixSpecServer(spec, "/v2")
+	require.NoError(t, err)
+	assert.Contains(t, string(spec), `"url": "https://api.example.com/billing/v2"`)
+
+	spec, err = applySpecServer([]byte(`{"swagger":"2.0","paths":{}}`), "")
+	require.NoError(t, err)
+	spec, err = prefixSpecServer(spec, "/v2")
+	require.NoError(t, err)
+	assert.Contains(t, string(spec), `"basePath": "/v2"`)
+
+	spec, err = prefixSpecServer([]byte(`{"openapi":"3.0.0","paths":{}}`), "/v2")
+	require.NoError(t, err)
+	assert.Contains(t, string(spec), `"url": "/v2"`)
+}
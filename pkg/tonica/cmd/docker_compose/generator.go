@@ -2,65 +2,104 @@ package docker_compose
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"text/template"
 )
 
+const composeFileName = "docker-compose.yml"
+
+var (
+	// ErrComposeFileExists is returned when the target file exists and Force is not set.
+	ErrComposeFileExists = errors.New("docker-compose.yml already exists, use --force to overwrite")
+	// ErrComposeFileOutdated is returned in check mode when the file on disk differs.
+	ErrComposeFileOutdated = errors.New("docker-compose.yml is out of date")
+)
+
+// Options controls where the generated compose file goes.
+type Options struct {
+	// Stdout prints the generated file instead of writing it.
+	Stdout bool
+	// Check compares the generated file with the one on disk without writing.
+	Check bool
+	// Force overwrites an existing file.
+	Force bool
+}
+
 type ServiceConfig struct {
-	AppDB       bool
-	Temporal    bool
-	Redpanda    bool
-	Dragonfly   bool
-	Mailhog     bool
-	Jaeger      bool
+	AppDB     bool
+	Temporal  bool
+	Redpanda  bool
+	Dragonfly bool
+	Mailhog   bool
+	Jaeger    bool
 }
 
 type TemplateData struct {
-	AppDB       bool
-	Temporal    bool
-	Redpanda    bool
-	Dragonfly   bool
-	Mailhog     bool
-	Jaeger      bool
-	HasVolumes  bool
+	AppDB      bool
+	Temporal   bool
+	Redpanda   bool
+	Dragonfly  bool
+	Mailhog    bool
+	Jaeger     bool
+	HasVolumes bool
 }
 
-func GenerateDockerCompose() error {
+func GenerateDockerCompose(opts Options) error {
 	config := ServiceConfig{}
 	reader := bufio.NewReader(os.Stdin)
 
+	// Keep stdout clean for the generated file in stdout mode
+	var out io.Writer = os.Stdout
+	if opts.Stdout {
+		out = os.Stderr
+	}
+
 	// Ask about each service
-	fmt.Println("Docker Compose Generator")
-	fmt.Println("=========================")
-	fmt.Println()
-
-	config.AppDB = askYesNo(reader, "Enable App Database (PostgreSQL)?")
-	config.Temporal = askYesNo(reader, "Enable Temporal (workflow engine)?")
-	config.Redpanda = askYesNo(reader, "Enable Redpanda (Kafka)?")
-	config.Dragonfly = askYesNo(reader, "Enable Dragonfly (Redis)?")
-	config.Mailhog = askYesNo(reader, "Enable Mailhog (email testing)?")
-	config.Jaeger = askYesNo(reader, "Enable Jaeger (tracing)?")
-
-	// Generate docker-compose.yml
-	err := generateComposeFile(config)
+	fmt.Fprintln(out, "Docker Compose Generator")
+	fmt.Fprintln(out, "=========================")
+	fmt.Fprintln(out)
+
+	config.AppDB = askYesNo(reader, out, "Enable App Database (PostgreSQL)?")
+	config.Temporal = askYesNo(reader, out, "Enable Temporal (workflow engine)?")
+	config.Redpanda = askYesNo(reader, out, "Enable Redpanda (Kafka)?")
+	config.Dragonfly = askYesNo(reader, out, "Enable Dragonfly (Redis)?")
+	config.Mailhog = askYesNo(reader, out, "Enable Mailhog (email testing)?")
+	config.Jaeger = askYesNo(reader, out, "Enable Jaeger (tracing)?")
+
+	content, err := renderComposeFile(config)
 	if err != nil {
-		return fmt.Errorf("failed to generate docker-compose.yml: %w", err)
+		return fmt.Errorf("failed to generate %s: %w", composeFileName, err)
+	}
+
+	switch {
+	case opts.Stdout:
+		_, err := os.Stdout.Write(content)
+		return err
+	case opts.Check:
+		return checkComposeFile(out, content)
+	}
+
+	if err := writeComposeFile(content, opts.Force); err != nil {
+		return fmt.Errorf("failed to generate %s: %w", composeFileName, err)
 	}
 
-	fmt.Println()
-	fmt.Println("✓ docker-compose.yml generated successfully!")
-	fmt.Println()
-	fmt.Println("To start services run:")
-	fmt.Println("  docker-compose up -d")
-	fmt.Println()
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "✓ %s generated successfully!\n", composeFileName)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "To start services run:")
+	fmt.Fprintln(out, "  docker-compose up -d")
+	fmt.Fprintln(out)
 
 	return nil
 }
 
-func askYesNo(reader *bufio.Reader, question string) bool {
-	fmt.Printf("%s [y/N]: ", question)
+func askYesNo(reader *bufio.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [y/N]: ", question)
 	response, err := reader.ReadString('\n')
 	if err != nil {
 		return false
@@ -69,7 +108,7 @@ func askYesNo(reader *bufio.Reader, question string) bool {
 	return response == "y" || response == "yes"
 }
 
-func generateComposeFile(config ServiceConfig) error {
+func renderComposeFile(config ServiceConfig) ([]byte, error) {
 	data := TemplateData{
 		AppDB:      config.AppDB,
 		Temporal:   config.Temporal,
@@ -82,11 +121,44 @@ func generateComposeFile(config ServiceConfig) error {
 
 	tmpl := template.Must(template.New("docker-compose").Parse(dockerComposeTemplate))
 
-	file, err := os.Create("docker-compose.yml")
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func checkComposeFile(out io.Writer, content []byte) error {
+	existing, err := os.ReadFile(composeFileName)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: file does not exist", ErrComposeFileOutdated)
+		}
+		return err
+	}
+	if !bytes.Equal(existing, content) {
+		return ErrComposeFileOutdated
+	}
+
+	fmt.Fprintf(out, "✓ %s is up to date\n", composeFileName)
+	return nil
+}
+
+func writeComposeFile(content []byte, force bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !force {
+		flags |= os.O_EXCL
+	}
+
+	file, err := os.OpenFile(composeFileName, flags, 0o644)
 	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return ErrComposeFileExists
+		}
 		return err
 	}
 	defer file.Close()
 
-	return tmpl.Execute(file, data)
+	_, err = file.Write(content)
+	return err
 }
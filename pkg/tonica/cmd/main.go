@@ -2,13 +2,21 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/tonica-go/tonica/pkg/tonica/cmd/docker_compose"
 	"github.com/tonica-go/tonica/pkg/tonica/cmd/project"
 	"github.com/tonica-go/tonica/pkg/tonica/cmd/proto_init"
 	"github.com/tonica-go/tonica/pkg/tonica/cmd/wrap"
+	"github.com/tonica-go/tonica/pkg/tonica/modules/entities"
+	"github.com/tonica-go/tonica/pkg/tonica/modules/eventstore"
 	"github.com/urfave/cli/v3"
 )
 
@@ -56,20 +64,28 @@ func Run() {
 			{
 				Name: "wrap",
 				Flags: []cli.Flag{
-					&cli.StringFlag{
+					&cli.StringSliceFlag{
 						Name:     "proto",
-						Value:    "",
-						Usage:    "path to proto file",
+						Usage:    "path to proto file, repeat for several files",
 						Required: true,
 					},
+					&cli.BoolFlag{
+						Name:  "verify",
+						Usage: "report generated files that are out of date instead of writing them, and fail if there are any",
+					},
 				},
 				Action: func(ctx context.Context, cmd *cli.Command) error {
-					path := cmd.String("proto")
-					_, err := wrap.BuildGRPCGoFrServer(path)
-					if err != nil {
-						return err
+					if cmd.Bool("verify") {
+						return verifyWrappers(cmd.StringSlice("proto"))
 					}
-					return nil
+					// Keep going so one broken file does not hide problems in the others.
+					var errs []error
+					for _, path := range cmd.StringSlice("proto") {
+						if _, err := wrap.BuildGRPCGoFrServer(path); err != nil {
+							errs = append(errs, err)
+						}
+					}
+					return errors.Join(errs...)
 				},
 			},
 			{
@@ -85,14 +101,130 @@ func Run() {
 			{
 				Name:  "compose",
 				Usage: "Generate docker-compose.yml with selected services",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "stdout",
+						Usage: "print the generated file instead of writing it",
+					},
+					&cli.BoolFlag{
+						Name:  "check",
+						Usage: "exit with an error if docker-compose.yml differs from the generated file",
+					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "overwrite an existing docker-compose.yml",
+					},
+				},
 				Action: func(ctx context.Context, cmd *cli.Command) error {
-					err := docker_compose.GenerateDockerCompose()
+					err := docker_compose.GenerateDockerCompose(docker_compose.Options{
+						Stdout: cmd.Bool("stdout"),
+						Check:  cmd.Bool("check"),
+						Force:  cmd.Bool("force"),
+					})
 					if err != nil {
 						return err
 					}
 					return nil
 				},
 			},
+			{
+				Name:  "events",
+				Usage: "Inspect the entity event store",
+				Commands: []*cli.Command{
+					{
+						Name:  "tail",
+						Usage: "Print the events of an entity or record as they are appended",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "entity",
+								Usage:    "entity id",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:  "record",
+								Usage: "record id, follows a single record stream",
+							},
+							&cli.Int64Flag{
+								Name:  "from-version",
+								Usage: "record version (with --record) or feed position to replay from",
+							},
+							&cli.StringFlag{
+								Name:    "driver",
+								Value:   "postgres",
+								Usage:   "event store driver: postgres, mysql or sqlite",
+								Sources: cli.EnvVars("ENTITIES_DB_DRIVER"),
+							},
+							&cli.StringFlag{
+								Name:     "dsn",
+								Usage:    "event store DSN",
+								Required: true,
+								Sources:  cli.EnvVars("ENTITIES_DB_DSN"),
+							},
+							&cli.StringFlag{
+								Name:    "codec",
+								Value:   "number",
+								Usage:   "payload codec: number or json",
+								Sources: cli.EnvVars("ENTITIES_PAYLOAD_CODEC"),
+							},
+							&cli.DurationFlag{
+								Name:  "interval",
+								Value: time.Second,
+								Usage: "poll interval",
+							},
+						},
+						Action: func(ctx context.Context, cmd *cli.Command) error {
+							codec, err := entities.CodecByName(cmd.String("codec"))
+							if err != nil {
+								return err
+							}
+							ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+							defer stop()
+
+							store, err := eventstore.New(ctx, cmd.String("driver"), cmd.String("dsn"))
+							if err != nil {
+								return err
+							}
+							defer store.Close(context.Background())
+
+							return entities.TailEvents(ctx, store, entities.TailOptions{
+								Entity:      cmd.String("entity"),
+								Record:      cmd.String("record"),
+								FromVersion: cmd.Int64("from-version"),
+								Interval:    cmd.Duration("interval"),
+								Codec:       codec,
+							}, printEvent)
+						},
+					},
+				},
+			},
+			{
+				Name:  "lint",
+				Usage: "Check project files for problems",
+				Commands: []*cli.Command{
+					{
+						Name:      "definitions",
+						Usage:     "Validate entity definitions and the references between them",
+						ArgsUsage: "[dir]",
+						Action: func(ctx context.Context, cmd *cli.Command) error {
+							dir := cmd.Args().First()
+							if dir == "" {
+								dir = "definitions"
+							}
+							issues, err := entities.LintDefinitions(dir)
+							if err != nil {
+								return err
+							}
+							for _, issue := range issues {
+								fmt.Println(issue)
+							}
+							if len(issues) > 0 {
+								return fmt.Errorf("found %d problem(s) in %s", len(issues), dir)
+							}
+							return nil
+						},
+					},
+				},
+			},
 		},
 	}
 
@@ -100,3 +232,43 @@ func Run() {
 		log.Fatal(err)
 	}
 }
+
+// verifyWrappers prints every wrapper generated from protoPaths that
+// differs from its proto and fails if there is one.
+func verifyWrappers(protoPaths []string) error {
+	var (
+		stale int
+		errs  []error
+	)
+	for _, path := range protoPaths {
+		files, err := wrap.VerifyGRPCGoFrServer(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, file := range files {
+			fmt.Println(file)
+		}
+		stale += len(files)
+	}
+	if stale > 0 {
+		errs = append(errs, fmt.Errorf("%d generated file(s) out of date, run tonica wrap to regenerate", stale))
+	}
+	return errors.Join(errs...)
+}
+
+// printEvent writes one tailed event: a header line followed by the indented
+// payload.
+func printEvent(evt entities.TailedEvent) error {
+	fmt.Printf("%s  %-24s v%-4d %s  actor=%s  id=%d\n",
+		evt.Timestamp.Format(time.RFC3339), evt.Type, evt.Version, evt.StreamID, evt.ActorID, evt.ID)
+	if evt.Payload == nil {
+		return nil
+	}
+	payload, err := json.MarshalIndent(evt.Payload, "    ", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("    %s\n", payload)
+	return nil
+}
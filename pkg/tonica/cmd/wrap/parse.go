@@ -0,0 +1,145 @@
+package wrap
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/emicklei/proto"
+)
+
+// maxSkippedRPCs bounds how many broken RPCs are dropped from one file before
+// parsing gives up.
+const maxSkippedRPCs = 50
+
+// positionPattern matches the line:column the parser reports, with or
+// without a leading filename.
+var positionPattern = regexp.MustCompile(`(?:^|:)(\d+):(\d+):`)
+
+// parseProtoSource parses src, skipping RPC statements the parser rejects. The
+// skipped RPCs are blanked out line by line so later errors keep their line
+// numbers. Errors outside an RPC are returned with the parser's file, line and
+// column.
+func parseProtoSource(filename string, src []byte) (*proto.Proto, error) {
+	lines := strings.Split(string(src), "\n")
+
+	for skipped := 0; ; skipped++ {
+		parser := proto.NewParser(bytes.NewReader([]byte(strings.Join(lines, "\n"))))
+		parser.Filename(filename)
+
+		definition, err := parser.Parse()
+		if err == nil {
+			return definition, nil
+		}
+
+		line, _, ok := errorPosition(err)
+		if !ok || skipped >= maxSkippedRPCs {
+			return nil, fmt.Errorf("%w: %v", ErrFailedToParseProto, err)
+		}
+		// An unterminated rpc is reported on the statement that follows it,
+		// so blame the one before.
+		idx := line - 1
+		if prev, ok := unterminatedBefore(lines, idx); ok {
+			idx = prev
+		}
+		start, end, ok := enclosingRPC(lines, idx)
+		if !ok {
+			return nil, fmt.Errorf("%w: %v", ErrFailedToParseProto, err)
+		}
+
+		slog.Warn("skipping rpc that failed to parse",
+			"file", filename,
+			"line", start+1,
+			"rpc", strings.TrimSpace(lines[start]),
+			"err", err,
+		)
+		for i := start; i <= end; i++ {
+			lines[i] = ""
+		}
+	}
+}
+
+func errorPosition(err error) (int, int, bool) {
+	m := positionPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0, 0, false
+	}
+	line, lineErr := strconv.Atoi(m[1])
+	column, columnErr := strconv.Atoi(m[2])
+	return line, column, lineErr == nil && columnErr == nil && line > 0
+}
+
+// unterminatedBefore returns the previous non-blank line when line starts an
+// rpc and that line does not end its statement.
+func unterminatedBefore(lines []string, line int) (int, bool) {
+	if line <= 0 || line >= len(lines) || !strings.HasPrefix(strings.TrimSpace(lines[line]), "rpc ") {
+		return 0, false
+	}
+	for i := line - 1; i >= 0; i-- {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case trimmed == "":
+			continue
+		case strings.HasSuffix(trimmed, ";"), strings.HasSuffix(trimmed, "}"),
+			strings.HasPrefix(trimmed, "service "), strings.HasPrefix(trimmed, "//"):
+			return 0, false
+		default:
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// enclosingRPC returns the line range of the rpc statement containing line.
+// An rpc ends at its semicolon, at the brace closing its option block, or
+// right before the next rpc or the end of the service.
+func enclosingRPC(lines []string, line int) (int, int, bool) {
+	if line < 0 || line >= len(lines) {
+		return 0, 0, false
+	}
+
+	start := -1
+	for i := line; i >= 0; i-- {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, "rpc ") || trimmed == "rpc" {
+			start = i
+			break
+		}
+		if strings.HasPrefix(trimmed, "service ") || strings.HasPrefix(trimmed, "message ") {
+			return 0, 0, false
+		}
+	}
+	if start < 0 {
+		return 0, 0, false
+	}
+
+	depth := 0
+	for i := start; i < len(lines); i++ {
+		if i > start && depth == 0 && strings.HasPrefix(strings.TrimSpace(lines[i]), "rpc ") {
+			return start, i - 1, line < i
+		}
+		for _, r := range lines[i] {
+			switch r {
+			case '{':
+				depth++
+			case '}':
+				if depth == 0 {
+					end := max(start, i-1)
+					return start, end, line <= end || line == i
+				}
+				depth--
+				if depth == 0 {
+					return start, i, line <= i
+				}
+			case ';':
+				if depth == 0 {
+					return start, i, line <= i
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
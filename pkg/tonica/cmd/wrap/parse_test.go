@@ -0,0 +1,100 @@
+package wrap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func methodNames(t *testing.T, src string) []string {
+	t.Helper()
+	definition, err := parseProtoSource("test.proto", []byte(src))
+	require.NoError(t, err)
+
+	var names []string
+	for _, service := range getServices(context.Background(), definition) {
+		for _, method := range service.Methods {
+			names = append(names, method.Name)
+		}
+	}
+	return names
+}
+
+func TestParseProtoSource(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		names := methodNames(t, `syntax = "proto3";
+service Users {
+  rpc Get(GetRequest) returns (User);
+  rpc List(ListRequest) returns (ListResponse) {}
+}
+`)
+		assert.Equal(t, []string{"Get", "List"}, names)
+	})
+
+	t.Run("skips broken rpc", func(t *testing.T) {
+		names := methodNames(t, `syntax = "proto3";
+service Users {
+  rpc Get(GetRequest) returns (User);
+  rpc Broken(BrokenRequest) (User);
+  rpc List(ListRequest) returns (ListResponse);
+}
+`)
+		assert.Equal(t, []string{"Get", "List"}, names)
+	})
+
+	t.Run("skips unterminated rpc", func(t *testing.T) {
+		names := methodNames(t, `syntax = "proto3";
+service Users {
+  rpc Broken(BrokenRequest) returns (User)
+  rpc List(ListRequest) returns (ListResponse);
+}
+`)
+		assert.Equal(t, []string{"List"}, names)
+	})
+
+	t.Run("skips rpc with malformed return type", func(t *testing.T) {
+		names := methodNames(t, `syntax = "proto3";
+service Users {
+  rpc Broken(BrokenRequest) returns User;
+  rpc List(ListRequest) returns (ListResponse);
+}
+`)
+		assert.Equal(t, []string{"List"}, names)
+	})
+
+	t.Run("reports errors outside rpcs with their position", func(t *testing.T) {
+		_, err := parseProtoSource("test.proto", []byte(`syntax = "proto3";
+message User {
+  string name = ;
+}
+`))
+		require.ErrorIs(t, err, ErrFailedToParseProto)
+		assert.Contains(t, err.Error(), "test.proto:3:")
+	})
+}
+
+func TestEnclosingRPC(t *testing.T) {
+	lines := []string{
+		"service Users {",
+		"  rpc Get(GetRequest) returns (User) {",
+		"    option deprecated = true;",
+		"  }",
+		"  rpc List(ListRequest) returns (ListResponse);",
+		"}",
+	}
+
+	start, end, ok := enclosingRPC(lines, 2)
+	require.True(t, ok)
+	assert.Equal(t, 1, start)
+	assert.Equal(t, 3, end)
+
+	start, end, ok = enclosingRPC(lines, 4)
+	require.True(t, ok)
+	assert.Equal(t, 4, start)
+	assert.Equal(t, 4, end)
+
+	_, _, ok = enclosingRPC(lines, 0)
+	assert.False(t, ok)
+}
@@ -639,12 +639,13 @@ package {{ .Package }}
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	//"time"
 
 	"github.com/tonica-go/tonica/pkg/tonica"
+	"github.com/tonica-go/tonica/pkg/tonica/grpc/serviceconfig"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
 
@@ -675,9 +676,13 @@ func NewHealthClient(conn *grpc.ClientConn) HealthClient {
 func createGRPCConn(host string, serviceName string, dialOptions ...grpc.DialOption) (*grpc.ClientConn, error) {
 	serviceConfig := ` + "`{\"loadBalancingPolicy\": \"round_robin\"}`" + `
 
+	creds, err := serviceconfig.TLSFromEnv(strings.ToUpper(serviceName) + "_SERVICE").Credentials()
+	if err != nil {
+		return nil, fmt.Errorf("%s client credentials: %w", serviceName, err)
+	}
 	defaultOpts := []grpc.DialOption{
 		grpc.WithDefaultServiceConfig(serviceConfig),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(creds),
 	}
 
 	// Developer Note: If the user provides custom DialOptions, they will override the default options due to 
@@ -746,10 +751,23 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/tonica-go/tonica/pkg/tonica/grpc/serviceconfig"
+	{{- if $.HasHTTP }}
+	"github.com/tonica-go/tonica/pkg/tonica/service"
+	{{- end }}
 )
 
 const ServiceName = "{{ $.ServiceLower }}-service"
 const ServiceAddrEnvName = "{{ $.ServiceUpper }}_SERVICE_GRPC_ADDR"
+{{- if $.HasHTTP }}
+
+// {{ $.Service }}Gateway registers the HTTP gateway handlers of {{ $.Service }}.
+// Pass it to service.WithGateway.
+var {{ $.Service }}Gateway service.GatewayRegistrar = Register{{ $.Service }}HandlerFromEndpoint
+
+func init() {
+	service.RegisterGatewayHandler({{ $.Service }}_ServiceDesc.ServiceName, {{ $.Service }}Gateway)
+}
+{{- end }}
 
 type {{ $.Service }}AddressConfig struct {
 	{{ $.Service }}Address  string ` + "`" + `env:"{{ $.ServiceUpper }}_SERVICE_GRPC_ADDR,required"` + "`" + `
@@ -764,6 +782,7 @@ func (cfg *{{ $.Service }}AddressConfig) Get{{ $.Service }}Config() serviceconfi
 	return serviceconfig.ServiceConfig{
 		Address: net.JoinHostPort(cfg.{{ $.Service }}Address, cfg.{{ $.Service }}GrpcPort),
 		Name:    ServiceName,
+		TLS:     serviceconfig.TLSFromEnv("{{ $.ServiceUpper }}_SERVICE"),
 	}
 }
 
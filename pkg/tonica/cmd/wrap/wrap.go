@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -45,6 +48,8 @@ type ServiceMethod struct {
 type ProtoService struct {
 	Name    string
 	Methods []ServiceMethod
+	// HasHTTP is set when any method has a google.api.http option.
+	HasHTTP bool
 }
 
 // WrapperData is the template data structure.
@@ -56,6 +61,7 @@ type WrapperData struct {
 	Methods      []ServiceMethod
 	Requests     []string
 	Source       string
+	HasHTTP      bool
 }
 
 type FileType struct {
@@ -73,17 +79,57 @@ func BuildGRPCGoFrClient(protoPath string) (any, error) {
 	return generateWrapper(context.Background(), protoPath, gRPCClient...)
 }
 
+// gRPCServerFiles are the files generated by BuildGRPCGoFrServer.
+var gRPCServerFiles = []FileType{
+	//{FileSuffix: serverWrapperFileSuffix, CodeGenerator: generateGoFrServerWrapper},
+	//{FileSuffix: serverHealthFile, CodeGenerator: generateGoFrServerHealthWrapper},
+	//{FileSuffix: serverRequestFile, CodeGenerator: generateGoFrRequestWrapper},
+	//{FileSuffix: serverFileSuffix, CodeGenerator: generateGoFrServer},
+	{FileSuffix: serverGetpFileSuffix, CodeGenerator: generateGRPCTemplate},
+}
+
 // BuildGRPCGoFrServer generates gRPC client and server code based on a proto definition.
 func BuildGRPCGoFrServer(protoPath string) (any, error) {
-	gRPCServer := []FileType{
-		//{FileSuffix: serverWrapperFileSuffix, CodeGenerator: generateGoFrServerWrapper},
-		//{FileSuffix: serverHealthFile, CodeGenerator: generateGoFrServerHealthWrapper},
-		//{FileSuffix: serverRequestFile, CodeGenerator: generateGoFrRequestWrapper},
-		//{FileSuffix: serverFileSuffix, CodeGenerator: generateGoFrServer},
-		{FileSuffix: serverGetpFileSuffix, CodeGenerator: generateGRPCTemplate},
+	return generateWrapper(context.Background(), protoPath, gRPCServerFiles...)
+}
+
+// StaleFile is a generated file that does not match its proto definition.
+type StaleFile struct {
+	Path    string
+	Service string
+	// Missing is set when the file has not been generated at all.
+	Missing bool
+}
+
+func (f StaleFile) String() string {
+	if f.Missing {
+		return fmt.Sprintf("%s: missing (service %s)", f.Path, f.Service)
 	}
+	return fmt.Sprintf("%s: out of date (service %s)", f.Path, f.Service)
+}
 
-	return generateWrapper(context.Background(), protoPath, gRPCServer...)
+// VerifyGRPCGoFrServer regenerates the files of BuildGRPCGoFrServer in memory
+// and returns those that differ from the files on disk. Nothing is written.
+func VerifyGRPCGoFrServer(protoPath string) ([]StaleFile, error) {
+	files, err := renderWrapper(context.Background(), protoPath, gRPCServerFiles...)
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []StaleFile
+	for _, file := range files {
+		current, err := os.ReadFile(file.Path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			stale = append(stale, StaleFile{Path: file.Path, Service: file.Service, Missing: true})
+		case err != nil:
+			return nil, fmt.Errorf("%s: service %s: %w", protoPath, file.Service, err)
+		case !bytes.Equal(current, file.Code):
+			stale = append(stale, StaleFile{Path: file.Path, Service: file.Service})
+		}
+	}
+
+	return stale, nil
 }
 
 // BuildGRPCServer generates gRPC client and server code based on a proto definition.
@@ -95,10 +141,43 @@ func BuildGRPCServer(protoPath string) (any, error) {
 	return generateWrapper(context.Background(), protoPath, gRPCServer...)
 }
 
+// generatedFile is the code generated for a service and file suffix.
+type generatedFile struct {
+	Path    string
+	Service string
+	Code    []byte
+}
+
 // generateWrapper executes the function for specified FileType to create GoFr integrated
 // gRPC server/client files with the required services in proto file and
 // specified suffix for every service specified in the proto file.
 func generateWrapper(ctx context.Context, protoPath string, options ...FileType) (any, error) {
+	files, err := renderWrapper(ctx, protoPath, options...)
+
+	// Files of the services that did generate are still written.
+	errs := []error{err}
+	for _, file := range files {
+		if err := os.WriteFile(file.Path, file.Code, filePerm); err != nil {
+			slog.Error("Failed to write file", "path", file.Path, "err", err)
+			errs = append(errs, fmt.Errorf("%s: service %s: %w", protoPath, file.Service, ErrWritingFile))
+			continue
+		}
+
+		slog.Info("Generated file for service %s at", "service", file.Service, "path", file.Path)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Successfully generated all files for GoFr integrated gRPC servers/clients")
+
+	return "Successfully generated all files for GoFr integrated gRPC servers/clients", nil
+}
+
+// renderWrapper generates the files for every service in the proto file
+// without writing them. Files of the services that generated are returned
+// along with the errors of the others.
+func renderWrapper(ctx context.Context, protoPath string, options ...FileType) ([]generatedFile, error) {
 	if protoPath == "" {
 		slog.Error("No proto file", "err", ErrNoProtoFile)
 		return nil, ErrNoProtoFile
@@ -114,6 +193,11 @@ func generateWrapper(ctx context.Context, protoPath string, options ...FileType)
 	services := getServices(ctx, definition)
 	requests := getRequests(ctx, services)
 
+	// A service that fails to generate does not stop the others.
+	var (
+		files []generatedFile
+		errs  []error
+	)
 	for _, service := range services {
 		wrapperData := WrapperData{
 			Package:      packageName,
@@ -123,41 +207,36 @@ func generateWrapper(ctx context.Context, protoPath string, options ...FileType)
 			Methods:      service.Methods,
 			Requests:     uniqueRequestTypes(ctx, service.Methods),
 			Source:       path.Base(protoPath),
+			HasHTTP:      service.HasHTTP,
 		}
 
-		if err := generateFiles(ctx, projectPath, service.Name, &wrapperData, requests, options...); err != nil {
-			return nil, err
+		generated, err := generateFiles(ctx, projectPath, service.Name, &wrapperData, requests, options...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: service %s: %w", protoPath, service.Name, err))
+			continue
 		}
+		files = append(files, generated...)
 	}
 
-	slog.Info("Successfully generated all files for GoFr integrated gRPC servers/clients")
-
-	return "Successfully generated all files for GoFr integrated gRPC servers/clients", nil
+	return files, errors.Join(errs...)
 }
 
-// parseProtoFile opens and parses the proto file.
+// parseProtoFile reads and parses the proto file. RPCs that fail to parse are
+// skipped with a warning; other parse errors carry the file, line and column.
 func parseProtoFile(_ context.Context, protoPath string) (*proto.Proto, error) {
-	file, err := os.Open(protoPath)
+	src, err := os.ReadFile(protoPath)
 	if err != nil {
 		slog.Error("Failed to open proto file", "err", err)
-		return nil, ErrOpeningProtoFile
+		return nil, fmt.Errorf("%w: %v", ErrOpeningProtoFile, err)
 	}
-	defer file.Close()
 
-	parser := proto.NewParser(file)
-
-	definition, err := parser.Parse()
-	if err != nil {
-		slog.Error("Failed to parse proto file", "err", err)
-		return nil, ErrFailedToParseProto
-	}
-
-	return definition, nil
+	return parseProtoSource(protoPath, src)
 }
 
 // generateFiles generates files for a given service.
 func generateFiles(ctx context.Context, projectPath, serviceName string, wrapperData *WrapperData,
-	requests []string, options ...FileType) error {
+	requests []string, options ...FileType) ([]generatedFile, error) {
+	files := make([]generatedFile, 0, len(options))
 	for _, option := range options {
 		if option.FileSuffix == serverRequestFile {
 			wrapperData.Requests = requests
@@ -166,19 +245,17 @@ func generateFiles(ctx context.Context, projectPath, serviceName string, wrapper
 		generatedCode := option.CodeGenerator(ctx, wrapperData)
 		if generatedCode == "" {
 			slog.Error("Failed to generate code for service %s with file suffix", "service", serviceName, "suffix", option.FileSuffix)
-			return ErrGeneratingWrapper
+			return nil, ErrGeneratingWrapper
 		}
 
-		outputFilePath := getOutputFilePath(projectPath, serviceName, option.FileSuffix)
-		if err := os.WriteFile(outputFilePath, []byte(generatedCode), filePerm); err != nil {
-			slog.Error("Failed to write file", "path", outputFilePath, "err", err)
-			return ErrWritingFile
-		}
-
-		slog.Info("Generated file for service %s at", "service", serviceName, "path", outputFilePath)
+		files = append(files, generatedFile{
+			Path:    getOutputFilePath(projectPath, serviceName, option.FileSuffix),
+			Service: serviceName,
+			Code:    []byte(generatedCode),
+		})
 	}
 
-	return nil
+	return files, nil
 }
 
 // getOutputFilePath generates the output file path based on the file suffix.
@@ -223,12 +300,14 @@ func uniqueRequestTypes(_ context.Context, methods []ServiceMethod) []string {
 	return mapKeysToSlice(requests)
 }
 
-// mapKeysToSlice converts a map's keys to a slice.
+// mapKeysToSlice converts a map's keys to a sorted slice, so generated code
+// does not change between runs.
 func mapKeysToSlice(m map[string]bool) []string {
 	keys := make([]string, 0, len(m))
 	for key := range m {
 		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
 	return keys
 }
@@ -303,6 +382,16 @@ func getPackageAndProject(_ context.Context, definition *proto.Proto, protoPath
 	return projectPath, packageName
 }
 
+// hasHTTPOption reports whether the rpc declares a google.api.http route.
+func hasHTTPOption(rpc *proto.RPC) bool {
+	for _, element := range rpc.Elements {
+		if opt, ok := element.(*proto.Option); ok && opt.Name == "(google.api.http)" {
+			return true
+		}
+	}
+	return false
+}
+
 // getServices extracts services from the proto definition.
 func getServices(_ context.Context, definition *proto.Proto) []ProtoService {
 	var services []ProtoService
@@ -320,6 +409,7 @@ func getServices(_ context.Context, definition *proto.Proto) []ProtoService {
 						StreamsRequest:  rpc.StreamsRequest,
 						StreamsResponse: rpc.StreamsReturns,
 					})
+					service.HasHTTP = service.HasHTTP || hasHTTPOption(rpc)
 				}
 			}
 
@@ -0,0 +1,104 @@
+package wrap
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testProto = `syntax = "proto3";
+package shop.v1;
+option go_package = "example.com/shop/gen;shopv1";
+
+service Orders {
+  rpc Get(GetRequest) returns (Order);
+  rpc List(ListRequest) returns (ListResponse);
+  rpc Watch(GetRequest) returns (stream Order);
+}
+
+service Carts {
+  rpc Add(AddRequest) returns (Cart);
+  rpc Clear(ClearRequest) returns (Cart);
+}
+`
+
+func writeProto(t *testing.T) string {
+	t.Helper()
+	protoPath := filepath.Join(t.TempDir(), "shop.proto")
+	require.NoError(t, os.WriteFile(protoPath, []byte(testProto), filePerm))
+	return protoPath
+}
+
+func TestVerifyGRPCGoFrServer(t *testing.T) {
+	protoPath := writeProto(t)
+	dir := filepath.Dir(protoPath)
+	orders := filepath.Join(dir, "orders"+serverGetpFileSuffix)
+	carts := filepath.Join(dir, "carts"+serverGetpFileSuffix)
+
+	t.Run("missing", func(t *testing.T) {
+		stale, err := VerifyGRPCGoFrServer(protoPath)
+		require.NoError(t, err)
+		assert.Equal(t, []StaleFile{
+			{Path: orders, Service: "Orders", Missing: true},
+			{Path: carts, Service: "Carts", Missing: true},
+		}, stale)
+		assert.Equal(t, orders+": missing (service Orders)", stale[0].String())
+	})
+
+	_, err := BuildGRPCGoFrServer(protoPath)
+	require.NoError(t, err)
+
+	t.Run("up to date", func(t *testing.T) {
+		stale, err := VerifyGRPCGoFrServer(protoPath)
+		require.NoError(t, err)
+		assert.Empty(t, stale)
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(carts, []byte("package shopv1\n"), filePerm))
+
+		stale, err := VerifyGRPCGoFrServer(protoPath)
+		require.NoError(t, err)
+		assert.Equal(t, []StaleFile{{Path: carts, Service: "Carts"}}, stale)
+		assert.Equal(t, carts+": out of date (service Carts)", stale[0].String())
+
+		code, err := os.ReadFile(carts)
+		require.NoError(t, err)
+		assert.Equal(t, "package shopv1\n", string(code), "verify should not write files")
+	})
+
+	t.Run("no proto file", func(t *testing.T) {
+		_, err := VerifyGRPCGoFrServer("")
+		assert.ErrorIs(t, err, ErrNoProtoFile)
+
+		_, err = VerifyGRPCGoFrServer(filepath.Join(dir, "missing.proto"))
+		assert.ErrorIs(t, err, ErrOpeningProtoFile)
+	})
+}
+
+func TestRenderWrapper_Deterministic(t *testing.T) {
+	protoPath := writeProto(t)
+	files := []FileType{
+		{FileSuffix: serverGetpFileSuffix, CodeGenerator: generateGRPCTemplate},
+		{FileSuffix: serverRequestFile, CodeGenerator: generateGoFrRequestWrapper},
+	}
+
+	first, err := renderWrapper(context.Background(), protoPath, files...)
+	require.NoError(t, err)
+	require.NotEmpty(t, first)
+	for range 20 {
+		again, err := renderWrapper(context.Background(), protoPath, files...)
+		require.NoError(t, err)
+		assert.Equal(t, first, again)
+	}
+
+	assert.Equal(t, []string{"AddRequest", "ClearRequest", "GetRequest", "ListRequest"},
+		getRequests(context.Background(), []ProtoService{
+			{Methods: []ServiceMethod{{Request: "ListRequest"}, {Request: "GetRequest"}, {Request: "GetRequest"}}},
+			{Methods: []ServiceMethod{{Request: "ClearRequest"}, {Request: "AddRequest"}}},
+		}))
+}
@@ -1,6 +1,7 @@
 package tonica
 
 import (
+	"path"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -12,9 +13,28 @@ type ConditionalMiddleware struct {
 	rules []MiddlewareRule
 }
 
+// MatchMode selects how a rule's patterns are compared with request paths.
+type MatchMode int
+
+const (
+	// MatchPrefix matches paths starting with the pattern, so /api also
+	// matches /apidocs. It is the default.
+	MatchPrefix MatchMode = iota
+	// MatchExact matches the pattern only.
+	MatchExact
+	// MatchSegment matches the pattern and the paths below it: /api matches
+	// /api, /api/ and /api/users but not /apidocs.
+	MatchSegment
+	// MatchGlob matches path.Match patterns, where * stands for a single
+	// segment: /users/*/admin matches /users/42/admin.
+	MatchGlob
+)
+
 // MiddlewareRule defines when to apply specific middleware
 type MiddlewareRule struct {
 	PathPrefixes []string          // paths that match these prefixes
+	Excludes     []string          // paths carved out of PathPrefixes, with the ones below them
+	Match        MatchMode         // how PathPrefixes and Excludes match
 	Middlewares  []gin.HandlerFunc // middleware to apply
 }
 
@@ -34,6 +54,36 @@ func (cm *ConditionalMiddleware) AddRule(pathPrefixes []string, middlewares ...g
 	return cm
 }
 
+// AddRuleWithExclusions adds a middleware rule for pathPrefixes that skips
+// paths matching excludes, e.g. all of /api except /api/public. Excluded
+// paths are checked against the following rules.
+func (cm *ConditionalMiddleware) AddRuleWithExclusions(pathPrefixes, excludes []string, middlewares ...gin.HandlerFunc) *ConditionalMiddleware {
+	cm.rules = append(cm.rules, MiddlewareRule{
+		PathPrefixes: pathPrefixes,
+		Excludes:     excludes,
+		Middlewares:  middlewares,
+	})
+	return cm
+}
+
+// AddMatchRule adds a middleware rule whose patterns match using mode.
+func (cm *ConditionalMiddleware) AddMatchRule(mode MatchMode, patterns []string, middlewares ...gin.HandlerFunc) *ConditionalMiddleware {
+	return cm.AddMatchRuleWithExclusions(mode, patterns, nil, middlewares...)
+}
+
+// AddMatchRuleWithExclusions adds a middleware rule whose patterns and
+// excludes match using mode, e.g. MatchGlob /users/* except /users/*/public.
+// Excluded paths are checked against the following rules.
+func (cm *ConditionalMiddleware) AddMatchRuleWithExclusions(mode MatchMode, patterns, excludes []string, middlewares ...gin.HandlerFunc) *ConditionalMiddleware {
+	cm.rules = append(cm.rules, MiddlewareRule{
+		PathPrefixes: patterns,
+		Excludes:     excludes,
+		Match:        mode,
+		Middlewares:  middlewares,
+	})
+	return cm
+}
+
 // Handler returns a gin middleware that conditionally applies middleware based on path
 func (cm *ConditionalMiddleware) Handler() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -41,7 +91,7 @@ func (cm *ConditionalMiddleware) Handler() gin.HandlerFunc {
 
 		// Find matching rules and apply their middleware
 		for _, rule := range cm.rules {
-			if cm.pathMatches(path, rule.PathPrefixes) {
+			if matchPath(rule.Match, path, rule.PathPrefixes) && !rule.excluded(path) {
 				// Create a chain of middleware for this rule
 				handlers := append(rule.Middlewares, func(c *gin.Context) {
 					c.Next()
@@ -63,10 +113,38 @@ func (cm *ConditionalMiddleware) Handler() gin.HandlerFunc {
 	}
 }
 
+// excluded reports whether urlPath is carved out of the rule. Prefix
+// exclusions match on a segment boundary, so excluding /api/public keeps
+// /api/publicadmin under the rule.
+func (r MiddlewareRule) excluded(urlPath string) bool {
+	mode := r.Match
+	if mode == MatchPrefix {
+		mode = MatchSegment
+	}
+	return matchPath(mode, urlPath, r.Excludes)
+}
+
 // pathMatches checks if path matches any of the prefixes
 func (cm *ConditionalMiddleware) pathMatches(path string, prefixes []string) bool {
-	for _, prefix := range prefixes {
-		if strings.HasPrefix(path, prefix) {
+	return matchPath(MatchPrefix, path, prefixes)
+}
+
+// matchPath checks if urlPath matches any of the patterns using mode
+func matchPath(mode MatchMode, urlPath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		var ok bool
+		switch mode {
+		case MatchExact:
+			ok = urlPath == pattern
+		case MatchSegment:
+			base := strings.TrimSuffix(pattern, "/")
+			ok = urlPath == base || strings.HasPrefix(urlPath, base+"/")
+		case MatchGlob:
+			ok, _ = path.Match(pattern, urlPath)
+		default:
+			ok = strings.HasPrefix(urlPath, pattern)
+		}
+		if ok {
 			return true
 		}
 	}
@@ -91,6 +169,13 @@ func setupConditionalMiddleware() gin.HandlerFunc {
 		identityMiddleware(),
 	)
 
+	// Account API - JWT auth, except the public signup endpoints
+	cm.AddRuleWithExclusions(
+		[]string{"/api/accounts"},
+		[]string{"/api/accounts/signup"},
+		jwtAuthMiddleware(),
+	)
+
 	// Internal - API key auth
 	cm.AddRule(
 		[]string{"/internal"},
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/tonica-go/tonica/pkg/tonica/identity"
 )
 
 func TestConditionalMiddleware(t *testing.T) {
@@ -168,6 +169,85 @@ func TestConditionalMiddleware(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w2.Code)
 		assert.Equal(t, "true", w2.Header().Get("X-Public"))
 	})
+
+	t.Run("exclusions carve paths out of a rule", func(t *testing.T) {
+		cm := NewConditionalMiddleware()
+
+		cm.AddRuleWithExclusions([]string{"/api"}, []string{"/api/public"}, func(c *gin.Context) {
+			c.AbortWithStatus(http.StatusUnauthorized)
+		})
+		cm.AddRule([]string{"/api/public"}, func(c *gin.Context) {
+			c.Header("X-Public", "true")
+			c.Next()
+		})
+
+		router := gin.New()
+		router.Use(cm.Handler())
+		router.GET("/api/users", func(c *gin.Context) {
+			c.String(http.StatusOK, "users")
+		})
+		router.GET("/api/public/info", func(c *gin.Context) {
+			c.String(http.StatusOK, "public")
+		})
+		router.GET("/api/publicadmin", func(c *gin.Context) {
+			c.String(http.StatusOK, "admin")
+		})
+
+		w1 := httptest.NewRecorder()
+		router.ServeHTTP(w1, httptest.NewRequest("GET", "/api/users", nil))
+		assert.Equal(t, http.StatusUnauthorized, w1.Code)
+
+		// The excluded path falls through to the next rule
+		w2 := httptest.NewRecorder()
+		router.ServeHTTP(w2, httptest.NewRequest("GET", "/api/public/info", nil))
+		assert.Equal(t, http.StatusOK, w2.Code)
+		assert.Equal(t, "true", w2.Header().Get("X-Public"))
+
+		// Exclusions stop at a segment boundary
+		w3 := httptest.NewRecorder()
+		router.ServeHTTP(w3, httptest.NewRequest("GET", "/api/publicadmin", nil))
+		assert.Equal(t, http.StatusUnauthorized, w3.Code)
+	})
+}
+
+func TestConditionalMiddleware_Identity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := NewConditionalMiddleware()
+	cm.AddRule([]string{"/api"}, identity.Middleware(identity.HeaderExtractor("X-User-ID", "X-User-Email", "X-User-Role")))
+
+	router := gin.New()
+	router.Use(cm.Handler())
+	handler := func(c *gin.Context) {
+		id, ok := Identity(c)
+		if !ok {
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+		c.String(http.StatusOK, id.GetID()+":"+id.GetRole())
+	}
+	router.GET("/api/me", handler)
+	router.GET("/public/me", handler)
+
+	t.Run("identity is available to custom routes", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/me", nil)
+		req.Header.Set("X-User-ID", "user-1")
+		req.Header.Set("X-User-Role", "admin")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "user-1:admin", w.Body.String())
+	})
+
+	t.Run("missing identity", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/public/me", nil)
+		req.Header.Set("X-User-ID", "user-1")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
 }
 
 func TestPathMatches(t *testing.T) {
@@ -224,3 +304,72 @@ func TestPathMatches(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     MatchMode
+		path     string
+		pattern  string
+		expected bool
+	}{
+		{name: "prefix over-matches", mode: MatchPrefix, path: "/apidocs", pattern: "/api", expected: true},
+		{name: "exact match", mode: MatchExact, path: "/api", pattern: "/api", expected: true},
+		{name: "exact rejects sub-path", mode: MatchExact, path: "/api/users", pattern: "/api", expected: false},
+		{name: "segment matches itself", mode: MatchSegment, path: "/api", pattern: "/api", expected: true},
+		{name: "segment matches sub-path", mode: MatchSegment, path: "/api/users", pattern: "/api/", expected: true},
+		{name: "segment rejects sibling", mode: MatchSegment, path: "/apidocs", pattern: "/api", expected: false},
+		{name: "glob matches one segment", mode: MatchGlob, path: "/users/42/admin", pattern: "/users/*/admin", expected: true},
+		{name: "glob rejects extra segments", mode: MatchGlob, path: "/users/42/x/admin", pattern: "/users/*/admin", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchPath(tt.mode, tt.path, []string{tt.pattern}))
+		})
+	}
+}
+
+func TestConditionalMiddleware_MatchRule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := NewConditionalMiddleware()
+	cm.AddMatchRule(MatchSegment, []string{"/api"}, func(c *gin.Context) {
+		c.Header("X-Matched", "true")
+		c.Next()
+	})
+
+	router := gin.New()
+	router.Use(cm.Handler())
+	router.GET("/api/users", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/apidocs", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w1 := httptest.NewRecorder()
+	router.ServeHTTP(w1, httptest.NewRequest("GET", "/api/users", nil))
+	assert.Equal(t, "true", w1.Header().Get("X-Matched"))
+
+	w2 := httptest.NewRecorder()
+	router.ServeHTTP(w2, httptest.NewRequest("GET", "/apidocs", nil))
+	assert.Empty(t, w2.Header().Get("X-Matched"))
+}
+
+func TestConditionalMiddleware_MatchRuleWithExclusions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := NewConditionalMiddleware()
+	cm.AddMatchRuleWithExclusions(MatchGlob, []string{"/users/*"}, []string{"/users/public"}, func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+	})
+
+	router := gin.New()
+	router.Use(cm.Handler())
+	router.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w1 := httptest.NewRecorder()
+	router.ServeHTTP(w1, httptest.NewRequest("GET", "/users/42", nil))
+	assert.Equal(t, http.StatusUnauthorized, w1.Code)
+
+	w2 := httptest.NewRecorder()
+	router.ServeHTTP(w2, httptest.NewRequest("GET", "/users/public", nil))
+	assert.Equal(t, http.StatusOK, w2.Code)
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithServices(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		expected []string
+	}{
+		{
+			name:     "unset",
+			env:      "",
+			expected: []string{},
+		},
+		{
+			name:     "single service",
+			env:      "payments",
+			expected: []string{"payments"},
+		},
+		{
+			name:     "multiple services with blanks",
+			env:      " payments, ,reports,",
+			expected: []string{"payments", "reports"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_SERVICES", tt.env)
+
+			cfg := NewConfig(WithServices(strings.Split(GetEnv("APP_SERVICES", ""), ",")))
+			assert.Equal(t, tt.expected, cfg.Services())
+			assert.Equal(t, tt.expected, GetEnvStringSlice("APP_SERVICES", ""))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := Settings{
+		RunMode:     ModeAIO,
+		HTTPAddr:    ":8080",
+		MetricsAddr: ":2121",
+		GRPCAddrs:   map[string]string{"shared grpc": ":50051"},
+	}
+	assert.NoError(t, Validate(valid))
+
+	conflicting := valid
+	conflicting.MetricsAddr = "0.0.0.0:8080"
+	conflicting.GRPCAddrs = map[string]string{"service payments": "localhost:2121", "service reports": "9000"}
+	conflicting.Workflows = true
+	conflicting.TemporalAddr = "temporal"
+	conflicting.Entities = true
+	conflicting.EntityDriver = "oracle"
+
+	err := Validate(conflicting)
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.ElementsMatch(t, []string{
+		`http and metrics both listen on 0.0.0.0:8080`,
+		`service reports: listen address "9000" is invalid`,
+		`workflows: temporal address "temporal" must be host:port`,
+		`workflows: temporal namespace is not set`,
+		`entities: database driver "oracle" is not one of sqlite, mysql, postgres`,
+		`entities: database dsn is not set`,
+	}, validationErr.Problems)
+
+	// Only the metrics server listens in worker mode, and workers need
+	// Temporal whether or not the workflows service is enabled.
+	worker := conflicting
+	worker.RunMode = ModeWorker
+	worker.MetricsAddr = ":2121"
+	worker.Workflows = false
+	err = Validate(worker)
+	require.ErrorAs(t, err, &validationErr)
+	assert.ElementsMatch(t, []string{
+		`workflows: temporal address "temporal" must be host:port`,
+		`workflows: temporal namespace is not set`,
+	}, validationErr.Problems)
+	worker.TemporalAddr = "temporal:7233"
+	worker.TemporalNamespace = "default"
+	assert.NoError(t, Validate(worker))
+
+	// Metrics mounted on the API do not listen on their own port, except in
+	// modes without the API.
+	shared := valid
+	shared.MetricsAddr = ":8080"
+	shared.MetricsOnAPI = true
+	assert.NoError(t, Validate(shared))
+	shared.RunMode = ModeService
+	shared.GRPCAddrs = map[string]string{"shared grpc": ":8080"}
+	assert.Error(t, Validate(shared))
+
+	// Generated gateway handlers are found through the local gRPC servers.
+	auto := valid
+	auto.AutoGateway = true
+	assert.NoError(t, Validate(auto))
+	auto.RunMode = ModeGateway
+	err = Validate(auto)
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []string{"gateway: auto gateway is not supported in gateway mode, register handlers with service.WithGateway"}, validationErr.Problems)
+}
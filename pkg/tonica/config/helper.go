@@ -14,11 +14,28 @@ func GetEnv(key, fallback string) string {
 	return fallback
 }
 
+// GetEnvStringSlice returns the comma separated list in key, or in fallback
+// when key is unset. Entries are trimmed and empty entries dropped.
 func GetEnvStringSlice(key, fallback string) []string {
-	if value, ok := os.LookupEnv(key); ok {
-		return strings.Split(",", value)
+	return SplitAndTrim(GetEnv(key, fallback))
+}
+
+// SplitAndTrim splits a comma separated list, trimming each entry and
+// dropping empty ones. An empty string yields an empty list.
+func SplitAndTrim(s string) []string {
+	return trimList(strings.Split(s, ","))
+}
+
+// trimList trims every value and drops the empty ones.
+func trimList(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			out = append(out, v)
+		}
 	}
-	return strings.Split(",", fallback)
+	return out
 }
 
 func GetEnvInt(key string, fallback int) int {
@@ -32,20 +32,26 @@ func WithRunMode(mode string) Option {
 	}
 }
 
+// WithServices selects the services to run in service mode. Names are
+// trimmed and empty names, such as those split from an unset APP_SERVICES,
+// are dropped.
 func WithServices(services []string) Option {
 	return func(cfg *Config) {
-		cfg.services = services
+		cfg.services = trimList(services)
 	}
 }
 
+// WithWorkers selects the workers to run in worker mode, see WithServices.
 func WithWorkers(workers []string) Option {
 	return func(cfg *Config) {
-		cfg.workers = workers
+		cfg.workers = trimList(workers)
 	}
 }
 
+// WithConsumers selects the consumers to run in consumer mode, see
+// WithServices.
 func WithConsumers(consumers []string) Option {
 	return func(cfg *Config) {
-		cfg.consumers = consumers
+		cfg.consumers = trimList(consumers)
 	}
 }
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Settings are the parts of an app setup that Validate checks before any
+// listener is started.
+type Settings struct {
+	RunMode string
+	// HTTPAddr is the API listen address, used in aio and gateway mode.
+	HTTPAddr string
+	// MetricsAddr is the metrics listen address, used in every mode.
+	MetricsAddr string
+	// MetricsOnAPI serves the metrics endpoints on HTTPAddr instead of
+	// MetricsAddr in aio and gateway mode.
+	MetricsOnAPI bool
+	// GRPCAddrs are the gRPC listen addresses, used in aio and service mode,
+	// keyed by the name of the listener.
+	GRPCAddrs map[string]string
+	// AutoGateway wires generated gateway handlers from the proto services
+	// registered on the local gRPC servers, so it needs aio mode.
+	AutoGateway bool
+
+	Workflows         bool
+	TemporalAddr      string
+	TemporalNamespace string
+
+	Entities     bool
+	EntityDriver string
+	EntityDSN    string
+}
+
+// ValidationError aggregates every problem found by Validate.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
+
+// entityDrivers are the database drivers supported by the entities module.
+var entityDrivers = []string{"sqlite", "mysql", "postgres"}
+
+// Validate checks s for listen addresses that conflict in its run mode and
+// for settings required by the enabled modules. All problems are reported in
+// one *ValidationError.
+func Validate(s Settings) error {
+	var problems []string
+
+	mode := s.RunMode
+	if mode == "" {
+		mode = ModeAIO
+	}
+
+	listeners := make(map[string]string)
+	servesAPI := mode == ModeAIO || mode == ModeGateway
+	if servesAPI {
+		listeners["http"] = s.HTTPAddr
+	}
+	if !servesAPI || !s.MetricsOnAPI {
+		listeners["metrics"] = s.MetricsAddr
+	}
+	if mode == ModeAIO || mode == ModeService {
+		for name, addr := range s.GRPCAddrs {
+			listeners[name] = addr
+		}
+	}
+	problems = append(problems, addressConflicts(listeners)...)
+
+	// No gRPC server is started in gateway mode, so there are no registered
+	// proto services to find generated handlers for.
+	if s.AutoGateway && mode == ModeGateway {
+		problems = append(problems, "gateway: auto gateway is not supported in gateway mode, register handlers with service.WithGateway")
+	}
+
+	// Modules are started along with the services. Workers are Temporal
+	// workers, so worker mode needs Temporal as well.
+	if (s.Workflows && (mode == ModeAIO || mode == ModeService)) || mode == ModeWorker {
+		if _, port, err := net.SplitHostPort(s.TemporalAddr); err != nil || port == "" {
+			problems = append(problems, fmt.Sprintf("workflows: temporal address %q must be host:port", s.TemporalAddr))
+		}
+		if s.TemporalNamespace == "" {
+			problems = append(problems, "workflows: temporal namespace is not set")
+		}
+	}
+	if mode == ModeAIO || mode == ModeService {
+		if s.Entities {
+			if !slices.Contains(entityDrivers, s.EntityDriver) {
+				problems = append(problems, fmt.Sprintf("entities: database driver %q is not one of %s", s.EntityDriver, strings.Join(entityDrivers, ", ")))
+			}
+			if s.EntityDSN == "" {
+				problems = append(problems, "entities: database dsn is not set")
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// addressConflicts reports invalid addresses and listeners sharing a port.
+// Wildcard hosts conflict with every host; port 0 never conflicts.
+func addressConflicts(listeners map[string]string) []string {
+	names := make([]string, 0, len(listeners))
+	for name := range listeners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	type endpoint struct{ name, host, port string }
+	var endpoints []endpoint
+	for _, name := range names {
+		host, port, err := net.SplitHostPort(listeners[name])
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: listen address %q is invalid", name, listeners[name]))
+			continue
+		}
+		if port == "0" {
+			continue
+		}
+		for _, other := range endpoints {
+			if other.port == port && (isWildcardHost(host) || isWildcardHost(other.host) || other.host == host) {
+				problems = append(problems, fmt.Sprintf("%s and %s both listen on %s", other.name, name, listeners[name]))
+			}
+		}
+		endpoints = append(endpoints, endpoint{name: name, host: host, port: port})
+	}
+	return problems
+}
+
+func isWildcardHost(host string) bool {
+	return host == "" || host == "0.0.0.0" || host == "::"
+}
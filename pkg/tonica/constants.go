@@ -1,6 +1,16 @@
 package tonica
 
+import "time"
+
 const (
 	DefaultAppName  = "Tonica"
 	DefaultGRPCAddr = ":9000"
+
+	// DefaultReadHeaderTimeout and DefaultIdleTimeout are set on the HTTP
+	// servers; change them for the API server with WithHTTPServer.
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultIdleTimeout       = 120 * time.Second
+
+	// DefaultReadinessTimeout bounds the checks run by /readyz.
+	DefaultReadinessTimeout = 2 * time.Second
 )
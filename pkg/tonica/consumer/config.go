@@ -0,0 +1,125 @@
+package consumer
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrInvalidConfig is returned when a consumer declaration is incomplete.
+	ErrInvalidConfig = errors.New("invalid consumer config")
+	// ErrUnknownHandler is returned when a declaration references a handler
+	// that was not provided.
+	ErrUnknownHandler = errors.New("unknown consumer handler")
+)
+
+// ConsumerConfig declares a consumer in config files.
+type ConsumerConfig struct {
+	Name        string      `yaml:"name"`
+	Topic       string      `yaml:"topic"`
+	Group       string      `yaml:"group"`
+	Handler     string      `yaml:"handler"`
+	Concurrency int         `yaml:"concurrency"`
+	Retry       RetryConfig `yaml:"retry"`
+	DLQ         string      `yaml:"dlq"`
+}
+
+// RetryConfig declares how failed messages are retried.
+type RetryConfig struct {
+	MaxAttempts int           `yaml:"max_attempts"`
+	Backoff     time.Duration `yaml:"backoff"`
+}
+
+// ParseConfig reads consumer declarations from a YAML document of the form:
+//
+//	consumers:
+//	  - name: orders
+//	    topic: orders.created
+//	    group: billing
+//	    handler: handleOrderCreated
+//	    retry:
+//	      max_attempts: 3
+//	      backoff: 1s
+//	    dlq: orders.created.dlq
+func ParseConfig(data []byte) ([]ConsumerConfig, error) {
+	var doc struct {
+		Consumers []ConsumerConfig `yaml:"consumers"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse consumers config: %w", err)
+	}
+	return doc.Consumers, nil
+}
+
+// FromConfig builds consumers from declarations, binding each handler name to
+// a function from handlers. Options are applied to every consumer before its
+// declaration, so shared settings such as WithClient can be passed once.
+// Settings left out of a declaration keep the value of the shared options.
+func FromConfig(configs []ConsumerConfig, handlers map[string]Handler, options ...Option) ([]*Consumer, error) {
+	consumers := make([]*Consumer, 0, len(configs))
+	names := make(map[string]struct{}, len(configs))
+
+	for i, cfg := range configs {
+		if err := cfg.validate(); err != nil {
+			return nil, fmt.Errorf("consumer #%d: %w", i, err)
+		}
+		if _, ok := names[cfg.Name]; ok {
+			return nil, fmt.Errorf("%w: duplicate consumer name %q", ErrInvalidConfig, cfg.Name)
+		}
+		names[cfg.Name] = struct{}{}
+
+		handler, ok := handlers[cfg.Handler]
+		if !ok || handler == nil {
+			return nil, fmt.Errorf("%w: %q for consumer %q", ErrUnknownHandler, cfg.Handler, cfg.Name)
+		}
+
+		opts := append([]Option{}, options...)
+		opts = append(opts,
+			WithName(cfg.Name),
+			WithTopic(cfg.Topic),
+			WithConsumerGroup(cfg.Group),
+			WithHandler(handler),
+			cfg.Retry.option(),
+		)
+		if cfg.Concurrency > 0 {
+			opts = append(opts, WithConcurrency(cfg.Concurrency))
+		}
+		if cfg.DLQ != "" {
+			opts = append(opts, WithDLQ(cfg.DLQ))
+		}
+		consumers = append(consumers, NewConsumer(opts...))
+	}
+
+	return consumers, nil
+}
+
+// option sets the declared retry settings and keeps the others.
+func (r RetryConfig) option() Option {
+	return func(a *Consumer) {
+		if r.MaxAttempts > 0 {
+			a.maxAttempts = r.MaxAttempts
+		}
+		if r.Backoff > 0 {
+			a.backoff = r.Backoff
+		}
+	}
+}
+
+func (cfg ConsumerConfig) validate() error {
+	switch {
+	case cfg.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidConfig)
+	case cfg.Topic == "":
+		return fmt.Errorf("%w: topic is required for %q", ErrInvalidConfig, cfg.Name)
+	case cfg.Handler == "":
+		return fmt.Errorf("%w: handler is required for %q", ErrInvalidConfig, cfg.Name)
+	case cfg.Concurrency < 0:
+		return fmt.Errorf("%w: concurrency must not be negative for %q", ErrInvalidConfig, cfg.Name)
+	case cfg.Retry.MaxAttempts < 0 || cfg.Retry.Backoff < 0:
+		return fmt.Errorf("%w: retry must not be negative for %q", ErrInvalidConfig, cfg.Name)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package consumer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonica-go/tonica/pkg/tonica/storage/pubsub"
+)
+
+func TestParseConfig(t *testing.T) {
+	data := []byte(`
+consumers:
+  - name: orders
+    topic: orders.created
+    group: billing
+    handler: handleOrder
+  - name: payments
+    topic: payments.settled
+    handler: handlePayment
+    retry:
+      max_attempts: 3
+      backoff: 2s
+`)
+
+	configs, err := ParseConfig(data)
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+	assert.Equal(t, "orders", configs[0].Name)
+	assert.Equal(t, "billing", configs[0].Group)
+	assert.Equal(t, 3, configs[1].Retry.MaxAttempts)
+	assert.Equal(t, "2s", configs[1].Retry.Backoff.String())
+}
+
+func TestFromConfig(t *testing.T) {
+	handler := func(ctx context.Context, msg *pubsub.Message) error { return nil }
+	handlers := map[string]Handler{"handleOrder": handler}
+
+	t.Run("should build consumers", func(t *testing.T) {
+		client := &mockPubSubClient{}
+		consumers, err := FromConfig([]ConsumerConfig{
+			{Name: "orders", Topic: "orders.created", Group: "billing", Handler: "handleOrder"},
+		}, handlers, WithClient(client))
+		require.NoError(t, err)
+		require.Len(t, consumers, 1)
+
+		c := consumers[0]
+		assert.Equal(t, "orders", c.GetName())
+		assert.Equal(t, "orders.created", c.GetTopic())
+		assert.Equal(t, "billing", c.GetConsumerGroup())
+		assert.Same(t, client, c.GetClient())
+		assert.NotNil(t, c.GetHandler())
+	})
+
+	t.Run("should apply concurrency", func(t *testing.T) {
+		consumers, err := FromConfig([]ConsumerConfig{
+			{Name: "orders", Topic: "orders.created", Handler: "handleOrder", Concurrency: 4},
+		}, handlers)
+		require.NoError(t, err)
+		assert.Equal(t, 4, consumers[0].concurrency)
+
+		consumers, err = FromConfig([]ConsumerConfig{
+			{Name: "orders", Topic: "orders.created", Handler: "handleOrder"},
+		}, handlers, WithConcurrency(8))
+		require.NoError(t, err)
+		assert.Equal(t, 8, consumers[0].concurrency, "a shared concurrency is kept")
+	})
+
+	t.Run("should apply retry and dead-letter topic", func(t *testing.T) {
+		consumers, err := FromConfig([]ConsumerConfig{{
+			Name: "orders", Topic: "orders.created", Handler: "handleOrder",
+			Retry: RetryConfig{MaxAttempts: 3, Backoff: time.Second},
+			DLQ:   "orders.dlq",
+		}}, handlers)
+		require.NoError(t, err)
+		assert.Equal(t, 3, consumers[0].maxAttempts)
+		assert.Equal(t, time.Second, consumers[0].backoff)
+		assert.Equal(t, "orders.dlq", consumers[0].dlq)
+	})
+
+	t.Run("should keep shared options for settings left out", func(t *testing.T) {
+		consumers, err := FromConfig([]ConsumerConfig{
+			{Name: "orders", Topic: "orders.created", Handler: "handleOrder"},
+			{Name: "payments", Topic: "payments.settled", Handler: "handleOrder", Retry: RetryConfig{MaxAttempts: 5}},
+		}, handlers, WithRetry(3, time.Second), WithDLQ("dead"))
+		require.NoError(t, err)
+		assert.Equal(t, 3, consumers[0].maxAttempts)
+		assert.Equal(t, time.Second, consumers[0].backoff)
+		assert.Equal(t, "dead", consumers[0].dlq)
+		assert.Equal(t, 5, consumers[1].maxAttempts)
+		assert.Equal(t, time.Second, consumers[1].backoff)
+	})
+
+	t.Run("should reject unknown handler", func(t *testing.T) {
+		_, err := FromConfig([]ConsumerConfig{
+			{Name: "orders", Topic: "orders.created", Handler: "missing"},
+		}, handlers)
+		assert.ErrorIs(t, err, ErrUnknownHandler)
+	})
+
+	t.Run("should reject incomplete and duplicate declarations", func(t *testing.T) {
+		_, err := FromConfig([]ConsumerConfig{{Name: "orders", Handler: "handleOrder"}}, handlers)
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+
+		_, err = FromConfig([]ConsumerConfig{
+			{Name: "orders", Topic: "a", Handler: "handleOrder"},
+			{Name: "orders", Topic: "b", Handler: "handleOrder"},
+		}, handlers)
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+	})
+}
@@ -2,19 +2,61 @@ package consumer
 
 import (
 	"context"
+	"hash/fnv"
 	"log/slog"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/tonica-go/tonica/pkg/tonica/storage/pubsub"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	minSubscribeBackoff = 10 * time.Millisecond
+	maxSubscribeBackoff = 5 * time.Second
+)
+
+// Headers added to messages moved to the dead-letter topic.
+const (
+	// HeaderDLQTopic is the topic the message failed on.
+	HeaderDLQTopic = "x-dlq-topic"
+	// HeaderDLQAttempts is the number of times the message was handled.
+	HeaderDLQAttempts = "x-dlq-attempts"
+	// HeaderDLQError is the error of the last attempt.
+	HeaderDLQError = "x-dlq-error"
 )
 
 type Handler func(ctx context.Context, msg *pubsub.Message) error
 
+// Metrics records consumer counters. It is satisfied by metrics.Manager.
+type Metrics interface {
+	IncrementCounter(ctx context.Context, name string, labels ...string)
+}
+
 type Consumer struct {
 	client        pubsub.Client
 	name          string
 	consumerGroup string
 	topic         string
 	handler       func(ctx context.Context, msg *pubsub.Message) error
+	concurrency   int
+	maxAttempts   int
+	backoff       time.Duration
+	dlq           string
+	drainTimeout  time.Duration
+	metrics       Metrics
+
+	// subscribeBackoff is the wait after the last failed pull, reset by a
+	// successful one.
+	subscribeBackoff time.Duration
+
+	// resumed is non-nil while paused and closed by Resume.
+	pauseMu sync.Mutex
+	resumed chan struct{}
 }
 
 func (c *Consumer) GetName() string {
@@ -37,6 +79,14 @@ func (c *Consumer) GetHandler() func(ctx context.Context, msg *pubsub.Message) e
 	return c.handler
 }
 
+func (c *Consumer) GetMetrics() Metrics {
+	return c.metrics
+}
+
+func (c *Consumer) GetDrainTimeout() time.Duration {
+	return c.drainTimeout
+}
+
 type Option func(*Consumer)
 
 func WithName(name string) Option {
@@ -69,6 +119,56 @@ func WithHandler(c func(ctx context.Context, msg *pubsub.Message) error) Option
 	}
 }
 
+// WithConcurrency handles up to n messages at once. Messages with the same
+// key are always handled by the same goroutine, in the order received, so a
+// slow keyed message holds back later messages with a key of the same
+// goroutine. Unkeyed messages are spread round-robin and may complete in any
+// order. On shutdown Start waits for the messages in flight.
+func WithConcurrency(n int) Option {
+	return func(a *Consumer) {
+		a.concurrency = n
+	}
+}
+
+// WithRetry handles a failing message up to maxAttempts times in total,
+// waiting backoff before the first retry and doubling the wait after each
+// further one. Messages with the same key are held back while one of them is
+// retried.
+func WithRetry(maxAttempts int, backoff time.Duration) Option {
+	return func(a *Consumer) {
+		a.maxAttempts = maxAttempts
+		a.backoff = backoff
+	}
+}
+
+// WithDLQ publishes a message that failed every attempt to the dead-letter
+// topic and commits it, instead of leaving it uncommitted. The key and headers
+// are kept and the HeaderDLQ headers are added, see Consumer.process.
+func WithDLQ(topic string) Option {
+	return func(a *Consumer) {
+		a.dlq = topic
+	}
+}
+
+// WithDrainTimeout lets handlers in flight when the context of Start is done
+// keep running for up to d: their context is only cancelled once d has
+// passed, so their database and RPC calls can complete. Zero cancels them
+// together with Start. Consumers registered with an App default to its
+// shutdown timeout.
+func WithDrainTimeout(d time.Duration) Option {
+	return func(a *Consumer) {
+		a.drainTimeout = d
+	}
+}
+
+// WithMetrics records failed handler calls in app_pubsub_consume_failed_count.
+// Consumers registered with an App use its metrics manager by default.
+func WithMetrics(m Metrics) Option {
+	return func(a *Consumer) {
+		a.metrics = m
+	}
+}
+
 func NewConsumer(options ...Option) *Consumer {
 	app := &Consumer{}
 	for _, option := range options {
@@ -78,22 +178,259 @@ func NewConsumer(options ...Option) *Consumer {
 	return app
 }
 
+// Pause stops Start from pulling new messages. A message already received is
+// still handled. Pausing a paused consumer does nothing.
+func (c *Consumer) Pause() {
+	c.pauseMu.Lock()
+	defer c.pauseMu.Unlock()
+	if c.resumed == nil {
+		c.resumed = make(chan struct{})
+		slog.Info("consumer paused", "name", c.name, "topic", c.topic)
+	}
+}
+
+// Resume lets a paused consumer pull messages again.
+func (c *Consumer) Resume() {
+	c.pauseMu.Lock()
+	defer c.pauseMu.Unlock()
+	if c.resumed != nil {
+		close(c.resumed)
+		c.resumed = nil
+		slog.Info("consumer resumed", "name", c.name, "topic", c.topic)
+	}
+}
+
+// Paused reports whether the consumer is paused.
+func (c *Consumer) Paused() bool {
+	c.pauseMu.Lock()
+	defer c.pauseMu.Unlock()
+	return c.resumed != nil
+}
+
+// waitResumed blocks while the consumer is paused.
+func (c *Consumer) waitResumed(ctx context.Context) error {
+	c.pauseMu.Lock()
+	resumed := c.resumed
+	c.pauseMu.Unlock()
+	if resumed == nil {
+		return nil
+	}
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Start pulls and handles messages until ctx is done. Handlers run with a
+// context that outlives ctx by the drain timeout, see WithDrainTimeout.
 func (c *Consumer) Start(ctx context.Context) error {
+	handlerCtx, cancel := c.handlerContext(ctx)
+	defer cancel()
+
+	if c.concurrency > 1 {
+		return c.startPartitioned(ctx, handlerCtx)
+	}
+	for {
+		msg, err := c.receive(ctx)
+		if err != nil {
+			return err
+		}
+		if msg == nil {
+			continue
+		}
+		if err := c.process(handlerCtx, msg); err != nil {
+			slog.Error("handling consumer message failed", "topic", c.topic, "err", err.Error())
+		}
+	}
+}
+
+// handlerContext returns the context handlers run with: it keeps the values
+// of ctx and is cancelled once the drain timeout has passed after ctx is done.
+func (c *Consumer) handlerContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.drainTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	handlerCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		time.AfterFunc(c.drainTimeout, cancel)
+	})
+	return handlerCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// startPartitioned fans messages out to c.concurrency goroutines and waits
+// for them to finish their current message before returning. Messages are
+// pulled with ctx and handled with handlerCtx.
+func (c *Consumer) startPartitioned(ctx, handlerCtx context.Context) error {
+	partitions := make([]chan *pubsub.Message, c.concurrency)
+	var wg sync.WaitGroup
+	for i := range partitions {
+		partitions[i] = make(chan *pubsub.Message)
+		wg.Add(1)
+		go func(msgs <-chan *pubsub.Message) {
+			defer wg.Done()
+			for msg := range msgs {
+				if err := c.process(handlerCtx, msg); err != nil {
+					slog.Error("handling consumer message failed", "topic", c.topic, "err", err.Error())
+				}
+			}
+		}(partitions[i])
+	}
+	defer func() {
+		for _, msgs := range partitions {
+			close(msgs)
+		}
+		wg.Wait()
+	}()
+
+	var next int
 	for {
+		msg, err := c.receive(ctx)
+		if err != nil {
+			return err
+		}
+		if msg == nil {
+			continue
+		}
 		select {
+		case partitions[partition(msg, len(partitions), &next)] <- msg:
 		case <-ctx.Done():
-			slog.Info("consumer stopping", "name", c.name, "topic", c.topic)
-			return ctx.Err()
-		default:
-			msg, err := c.client.Subscribe(ctx, c.topic)
-			if err != nil {
-				slog.Error("subscribe failed", "topic", c.topic, "err", err.Error())
-				continue
-			}
+		}
+	}
+}
 
-			if err := c.handler(ctx, msg); err != nil {
-				slog.Error("handling consumer message failed", "topic", c.topic, "err", err.Error())
-			}
+// receive pulls the next message. It returns a nil message when the pull
+// failed or was interrupted, and an error once ctx is done. Failed pulls are
+// retried after a backoff that doubles up to maxSubscribeBackoff.
+func (c *Consumer) receive(ctx context.Context) (*pubsub.Message, error) {
+	select {
+	case <-ctx.Done():
+		slog.Info("consumer stopping", "name", c.name, "topic", c.topic)
+		return nil, ctx.Err()
+	default:
+	}
+	if err := c.waitResumed(ctx); err != nil {
+		return nil, nil
+	}
+	msg, err := c.client.Subscribe(ctx, c.topic)
+	if err != nil {
+		slog.Error("subscribe failed", "topic", c.topic, "err", err.Error())
+		c.subscribeBackoff = min(max(c.subscribeBackoff*2, minSubscribeBackoff), maxSubscribeBackoff)
+		select {
+		case <-time.After(c.subscribeBackoff):
+		case <-ctx.Done():
 		}
+		return nil, nil
+	}
+	c.subscribeBackoff = 0
+	return msg, nil
+}
+
+// partition picks the goroutine for msg. Keyed messages hash to a fixed
+// goroutine so their order is kept; unkeyed messages go round-robin.
+func partition(msg *pubsub.Message, n int, next *int) int {
+	if len(msg.Key) == 0 {
+		i := *next % n
+		*next++
+		return i
+	}
+	h := fnv.New32a()
+	h.Write(msg.Key)
+	return int(h.Sum32() % uint32(n))
+}
+
+// process handles msg, retrying it as configured by WithRetry. A message that
+// fails every attempt is moved to the dead-letter topic, if any, and the last
+// handler error is returned only when it could not be moved.
+func (c *Consumer) process(ctx context.Context, msg *pubsub.Message) error {
+	attempts := max(c.maxAttempts, 1)
+	wait := c.backoff
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		msg.Attempt = attempt
+		if err = c.handle(ctx, msg); err == nil {
+			return nil
+		}
+		if c.metrics != nil {
+			c.metrics.IncrementCounter(ctx, "app_pubsub_consume_failed_count", "topic", c.topic, "consumer_group", c.consumerGroup)
+		}
+		if attempt == attempts {
+			break
+		}
+		slog.Warn("retrying consumer message", "topic", c.topic, "attempt", attempt, "err", err.Error())
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return err
+		}
+		wait *= 2
+	}
+
+	if c.dlq == "" {
+		return err
+	}
+	if pubErr := c.deadLetter(ctx, msg, attempts, err); pubErr != nil {
+		slog.Error("publishing to dead-letter topic failed", "topic", c.topic, "dlq", c.dlq, "err", pubErr.Error())
+		return err
+	}
+	slog.Warn("consumer message moved to dead-letter topic", "topic", c.topic, "dlq", c.dlq, "attempts", attempts, "err", err.Error())
+	if msg.Committer != nil {
+		msg.Commit()
+	}
+	return nil
+}
+
+// deadLetter publishes msg to the dead-letter topic with its key and headers,
+// adding the topic, the number of attempts and the last error. The publish
+// continues the trace of msg. Clients that cannot publish headers only send
+// the value.
+func (c *Consumer) deadLetter(ctx context.Context, msg *pubsub.Message, attempts int, cause error) error {
+	publisher, ok := c.client.(pubsub.MessagePublisher)
+	if !ok {
+		return c.client.Publish(ctx, c.dlq, msg.Value)
+	}
+
+	dead := &pubsub.Message{
+		Topic:   c.dlq,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: make(map[string][]byte, len(msg.Headers)+3),
+	}
+	for name, value := range msg.Headers {
+		dead.Headers[name] = value
+	}
+	dead.SetHeader(HeaderDLQTopic, c.topic)
+	dead.SetHeader(HeaderDLQAttempts, strconv.Itoa(attempts))
+	dead.SetHeader(HeaderDLQError, cause.Error())
+	return publisher.PublishMessage(pubsub.ExtractTraceContext(ctx, msg), c.dlq, dead)
+}
+
+// handle runs the handler inside a consumer span linked to the producer span
+// propagated through the message headers.
+func (c *Consumer) handle(ctx context.Context, msg *pubsub.Message) error {
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.destination.name", c.topic),
+			attribute.String("messaging.consumer.group.name", c.consumerGroup),
+		),
+	}
+	if remote := trace.SpanContextFromContext(pubsub.ExtractTraceContext(context.Background(), msg)); remote.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: remote}))
+	}
+
+	ctx, span := otel.Tracer("tonica/consumer").Start(ctx, c.topic+" process", opts...)
+	defer span.End()
+
+	err := c.handler(ctx, msg)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 	}
+	return err
 }
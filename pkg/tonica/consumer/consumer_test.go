@@ -3,6 +3,8 @@ package consumer
 import (
 	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -10,6 +12,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tonica-go/tonica/pkg/tonica/storage"
 	"github.com/tonica-go/tonica/pkg/tonica/storage/pubsub"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // Mock pubsub client for testing
@@ -18,6 +24,26 @@ type mockPubSubClient struct {
 	messageIndex   int
 	subscribeErr   error
 	subscribeCalls int
+
+	mu        sync.Mutex
+	published map[string][][]byte
+	// messagesTo records PublishMessage calls of messageClient.
+	messagesTo map[string][]*pubsub.Message
+}
+
+// messageClient is a mockPubSubClient that also publishes keys and headers.
+type messageClient struct {
+	*mockPubSubClient
+}
+
+func (m messageClient) PublishMessage(ctx context.Context, topic string, msg *pubsub.Message) error {
+	m.mu.Lock()
+	if m.messagesTo == nil {
+		m.messagesTo = make(map[string][]*pubsub.Message)
+	}
+	m.messagesTo[topic] = append(m.messagesTo[topic], msg)
+	m.mu.Unlock()
+	return m.Publish(ctx, topic, msg.Value)
 }
 
 func (m *mockPubSubClient) Subscribe(ctx context.Context, topic string) (*pubsub.Message, error) {
@@ -39,9 +65,21 @@ func (m *mockPubSubClient) Subscribe(ctx context.Context, topic string) (*pubsub
 }
 
 func (m *mockPubSubClient) Publish(ctx context.Context, topic string, message []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.published == nil {
+		m.published = make(map[string][][]byte)
+	}
+	m.published[topic] = append(m.published[topic], message)
 	return nil
 }
 
+func (m *mockPubSubClient) publishedTo(topic string) [][]byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.published[topic]
+}
+
 func (m *mockPubSubClient) Health() storage.Health {
 	return storage.Health{Status: storage.StatusUp}
 }
@@ -286,3 +324,395 @@ func TestConsumer_Getters(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestConsumer_TracePropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	})
+
+	producerCtx, producerSpan := tp.Tracer("test").Start(context.Background(), "produce")
+	msg := &pubsub.Message{Value: []byte("message1")}
+	pubsub.InjectTraceContext(producerCtx, msg)
+	producerSpan.End()
+
+	require.NotEmpty(t, msg.Header("traceparent"))
+
+	mockClient := &mockPubSubClient{messages: []*pubsub.Message{msg}}
+	consumer := NewConsumer(
+		WithName("test-consumer"),
+		WithClient(mockClient),
+		WithTopic("test-topic"),
+		WithHandler(func(ctx context.Context, msg *pubsub.Message) error { return nil }),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_ = consumer.Start(ctx)
+
+	var consumerSpan sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "test-topic process" {
+			consumerSpan = span
+		}
+	}
+	require.NotNil(t, consumerSpan, "consumer span should be recorded")
+	require.Len(t, consumerSpan.Links(), 1)
+	assert.Equal(t, producerSpan.SpanContext().TraceID(), consumerSpan.Links()[0].SpanContext.TraceID())
+}
+
+func TestConsumer_PauseResume(t *testing.T) {
+	mockClient := &mockPubSubClient{
+		messages: []*pubsub.Message{
+			{Value: []byte("message1")},
+			{Value: []byte("message2")},
+		},
+	}
+
+	var processed atomic.Int32
+	consumer := NewConsumer(
+		WithName("test-consumer"),
+		WithClient(mockClient),
+		WithTopic("test-topic"),
+		WithHandler(func(ctx context.Context, msg *pubsub.Message) error {
+			processed.Add(1)
+			return nil
+		}),
+	)
+
+	consumer.Pause()
+	assert.True(t, consumer.Paused())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- consumer.Start(ctx)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Zero(t, processed.Load(), "paused consumer should not pull messages")
+
+	consumer.Resume()
+	assert.False(t, consumer.Paused())
+	assert.Eventually(t, func() bool { return processed.Load() == 2 }, time.Second, 10*time.Millisecond)
+
+	consumer.Pause()
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("paused consumer did not stop on cancellation")
+	}
+}
+
+func TestConsumer_OrderedConcurrency(t *testing.T) {
+	var messages []*pubsub.Message
+	for i := 0; i < 30; i++ {
+		key := []byte{byte('a' + i%3)}
+		messages = append(messages, &pubsub.Message{Key: key, Value: []byte{byte(i)}})
+	}
+	messages = append(messages, &pubsub.Message{Value: []byte("unkeyed")})
+
+	var mu sync.Mutex
+	seen := map[string][]byte{}
+	consumer := NewConsumer(
+		WithName("test-consumer"),
+		WithClient(&mockPubSubClient{messages: messages}),
+		WithTopic("test-topic"),
+		WithConcurrency(4),
+		WithHandler(func(ctx context.Context, msg *pubsub.Message) error {
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			seen[string(msg.Key)] = append(seen[string(msg.Key)], msg.Value...)
+			return nil
+		}),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- consumer.Start(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(seen) == 4 && len(seen["a"])+len(seen["b"])+len(seen["c"]) == 30
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	for k, key := range []string{"a", "b", "c"} {
+		var want []byte
+		for i := k; i < 30; i += 3 {
+			want = append(want, byte(i))
+		}
+		assert.Equal(t, want, seen[key], "messages for key %q out of order", key)
+	}
+	assert.Equal(t, []byte("unkeyed"), seen[""])
+}
+
+func TestPartition(t *testing.T) {
+	var next int
+	keyed := &pubsub.Message{Key: []byte("order-42")}
+	first := partition(keyed, 8, &next)
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, first, partition(keyed, 8, &next))
+	}
+	assert.Zero(t, next, "keyed messages should not advance round-robin")
+
+	unkeyed := &pubsub.Message{}
+	assert.Equal(t, []int{0, 1, 2, 0}, []int{
+		partition(unkeyed, 3, &next),
+		partition(unkeyed, 3, &next),
+		partition(unkeyed, 3, &next),
+		partition(unkeyed, 3, &next),
+	})
+}
+
+func TestConsumer_BoundedConcurrency(t *testing.T) {
+	var messages []*pubsub.Message
+	for i := 0; i < 12; i++ {
+		messages = append(messages, &pubsub.Message{Value: []byte{byte(i)}})
+	}
+
+	var inFlight, peak, handled atomic.Int32
+	consumer := NewConsumer(
+		WithName("test-consumer"),
+		WithClient(&mockPubSubClient{messages: messages}),
+		WithTopic("test-topic"),
+		WithConcurrency(3),
+		WithHandler(func(ctx context.Context, msg *pubsub.Message) error {
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			inFlight.Add(-1)
+			handled.Add(1)
+			return nil
+		}),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- consumer.Start(ctx)
+	}()
+
+	assert.Eventually(t, func() bool { return handled.Load() == 12 }, time.Second, 5*time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.EqualValues(t, 3, peak.Load(), "should run up to 3 handlers at once")
+}
+
+func TestConsumer_DrainsOnShutdown(t *testing.T) {
+	started := make(chan struct{})
+	var finished atomic.Bool
+	consumer := NewConsumer(
+		WithName("test-consumer"),
+		WithClient(&mockPubSubClient{messages: []*pubsub.Message{{Value: []byte("message1")}}}),
+		WithTopic("test-topic"),
+		WithConcurrency(2),
+		WithHandler(func(ctx context.Context, msg *pubsub.Message) error {
+			close(started)
+			time.Sleep(30 * time.Millisecond)
+			finished.Store(true)
+			return nil
+		}),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- consumer.Start(ctx)
+	}()
+
+	<-started
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.True(t, finished.Load(), "Start should wait for in-flight handlers")
+}
+
+func TestConsumer_DrainTimeout(t *testing.T) {
+	run := func(t *testing.T, drain, work time.Duration) error {
+		t.Helper()
+		started := make(chan struct{})
+		handlerErr := make(chan error, 1)
+		consumer := NewConsumer(
+			WithClient(&mockPubSubClient{messages: []*pubsub.Message{{Value: []byte("message1")}}}),
+			WithTopic("test-topic"),
+			WithConcurrency(2),
+			WithDrainTimeout(drain),
+			WithHandler(func(ctx context.Context, msg *pubsub.Message) error {
+				close(started)
+				select {
+				case <-time.After(work):
+					handlerErr <- ctx.Err()
+				case <-ctx.Done():
+					handlerErr <- ctx.Err()
+				}
+				return nil
+			}),
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- consumer.Start(ctx)
+		}()
+
+		<-started
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+		return <-handlerErr
+	}
+
+	t.Run("should keep the handler context alive while draining", func(t *testing.T) {
+		assert.NoError(t, run(t, time.Second, 30*time.Millisecond))
+	})
+
+	t.Run("should cancel handlers after the drain timeout", func(t *testing.T) {
+		assert.ErrorIs(t, run(t, 10*time.Millisecond, time.Second), context.Canceled)
+	})
+}
+
+type commitFunc func()
+
+func (f commitFunc) Commit() { f() }
+
+type countingMetrics struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *countingMetrics) IncrementCounter(_ context.Context, name string, _ ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.counts == nil {
+		m.counts = make(map[string]int)
+	}
+	m.counts[name]++
+}
+
+func TestConsumer_Retry(t *testing.T) {
+	t.Run("should retry until the handler succeeds", func(t *testing.T) {
+		mockClient := &mockPubSubClient{messages: []*pubsub.Message{{Value: []byte("message1")}}}
+
+		var attempts []int
+		consumer := NewConsumer(
+			WithClient(mockClient),
+			WithTopic("orders"),
+			WithRetry(3, time.Millisecond),
+			WithDLQ("orders.dlq"),
+			WithHandler(func(ctx context.Context, msg *pubsub.Message) error {
+				attempts = append(attempts, msg.Attempt)
+				if msg.Attempt < 3 {
+					return errors.New("handler error")
+				}
+				return nil
+			}),
+		)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_ = consumer.Start(ctx)
+
+		assert.Equal(t, []int{1, 2, 3}, attempts)
+		assert.Empty(t, mockClient.publishedTo("orders.dlq"))
+	})
+
+	t.Run("should move exhausted messages to the dead-letter topic", func(t *testing.T) {
+		var committed atomic.Bool
+		msg := &pubsub.Message{Value: []byte("message1"), Committer: commitFunc(func() { committed.Store(true) })}
+		mockClient := &mockPubSubClient{messages: []*pubsub.Message{msg}}
+		metrics := &countingMetrics{}
+
+		var calls atomic.Int32
+		consumer := NewConsumer(
+			WithClient(mockClient),
+			WithTopic("orders"),
+			WithRetry(2, time.Millisecond),
+			WithDLQ("orders.dlq"),
+			WithMetrics(metrics),
+			WithHandler(func(ctx context.Context, msg *pubsub.Message) error {
+				calls.Add(1)
+				return errors.New("handler error")
+			}),
+		)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_ = consumer.Start(ctx)
+
+		assert.EqualValues(t, 2, calls.Load())
+		assert.Equal(t, [][]byte{[]byte("message1")}, mockClient.publishedTo("orders.dlq"))
+		assert.True(t, committed.Load(), "dead-lettered message should be committed")
+		assert.Equal(t, 2, metrics.counts["app_pubsub_consume_failed_count"])
+	})
+
+	t.Run("should keep the key and headers of dead-lettered messages", func(t *testing.T) {
+		msg := &pubsub.Message{
+			Key:     []byte("order-1"),
+			Value:   []byte("message1"),
+			Headers: map[string][]byte{"schema": []byte("v2")},
+		}
+		mockClient := &mockPubSubClient{messages: []*pubsub.Message{msg}}
+
+		consumer := NewConsumer(
+			WithClient(messageClient{mockClient}),
+			WithTopic("orders"),
+			WithRetry(2, time.Millisecond),
+			WithDLQ("orders.dlq"),
+			WithHandler(func(ctx context.Context, msg *pubsub.Message) error {
+				return errors.New("handler error")
+			}),
+		)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_ = consumer.Start(ctx)
+
+		mockClient.mu.Lock()
+		defer mockClient.mu.Unlock()
+		require.Len(t, mockClient.messagesTo["orders.dlq"], 1)
+		dead := mockClient.messagesTo["orders.dlq"][0]
+		assert.Equal(t, []byte("order-1"), dead.Key)
+		assert.Equal(t, []byte("message1"), dead.Value)
+		assert.Equal(t, "v2", dead.Header("schema"))
+		assert.Equal(t, "orders", dead.Header(HeaderDLQTopic))
+		assert.Equal(t, "2", dead.Header(HeaderDLQAttempts))
+		assert.Equal(t, "handler error", dead.Header(HeaderDLQError))
+		assert.Len(t, msg.Headers, 1, "the consumed message is not modified")
+	})
+
+	t.Run("should handle once without retry", func(t *testing.T) {
+		mockClient := &mockPubSubClient{messages: []*pubsub.Message{{Value: []byte("message1")}}}
+
+		var attempts []int
+		consumer := NewConsumer(
+			WithClient(mockClient),
+			WithTopic("orders"),
+			WithHandler(func(ctx context.Context, msg *pubsub.Message) error {
+				attempts = append(attempts, msg.Attempt)
+				return errors.New("handler error")
+			}),
+		)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_ = consumer.Start(ctx)
+
+		assert.Equal(t, []int{1}, attempts)
+	})
+}
@@ -0,0 +1,168 @@
+package tonica
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/tonica-go/tonica/pkg/tonica/service"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// registerGRPC registers svc on srv and remembers which proto services it
+// added, so the gateway can check them for HTTP annotations later.
+func (a *App) registerGRPC(srv *grpc.Server, svc *service.Service) {
+	before := srv.GetServiceInfo()
+	svc.GetGRPC()(srv, svc)
+
+	for name := range srv.GetServiceInfo() {
+		if _, ok := before[name]; !ok {
+			a.protoServices[svc.GetName()] = append(a.protoServices[svc.GetName()], name)
+		}
+	}
+}
+
+// gatewayCredentials returns the transport credentials the gateway dials
+// services with: TLS with WithGatewayTLS, insecure otherwise.
+func (a *App) gatewayCredentials() (credentials.TransportCredentials, error) {
+	return a.gatewayTLS.Credentials()
+}
+
+// registerMissingGateway handles a service registered without WithGateway
+// whose proto declares HTTP routes. With WithAutoGateway the generated
+// handler is registered; otherwise a warning names the service.
+func (a *App) registerMissingGateway(ctx context.Context, mux *runtime.ServeMux, svc *service.Service, dialOpts []grpc.DialOption) error {
+	for _, name := range a.protoServices[svc.GetName()] {
+		if !hasHTTPRules(name) {
+			continue
+		}
+
+		registrar, ok := service.GatewayHandler(name)
+		if !a.autoGateway || !ok {
+			slog.Warn("service has HTTP annotations but no gateway registered, its HTTP API is not exposed",
+				"service", svc.GetName(),
+				"proto_service", name,
+				"generated_handler", ok,
+			)
+			continue
+		}
+
+		if err := registrar(ctx, mux, a.serviceDialAddr(svc), dialOpts); err != nil {
+			return err
+		}
+		slog.Info("registered generated gateway", "service", svc.GetName(), "proto_service", name)
+	}
+	return nil
+}
+
+// protoServiceOwners maps each registered proto service to the name of the
+// service that registered it.
+func (a *App) protoServiceOwners() map[string]string {
+	owners := make(map[string]string)
+	for name, protoServices := range a.protoServices {
+		for _, protoService := range protoServices {
+			owners[protoService] = name
+		}
+	}
+	return owners
+}
+
+// gatewayMetricService returns the service a gateway call is attributed to in
+// HTTP metrics: the owner of the called proto service, or the proto service
+// itself when its owner is unknown (e.g. in gateway mode).
+func gatewayMetricService(ctx context.Context, owners map[string]string) string {
+	method, ok := runtime.RPCMethod(ctx)
+	if !ok {
+		return ""
+	}
+	protoService, _, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	if owner, ok := owners[protoService]; ok {
+		return owner
+	}
+	return protoService
+}
+
+// MethodMiddleware runs before a gateway call to a gRPC method is forwarded.
+// ctx carries the HTTP request values, such as the identity. Returning an
+// error rejects the call; gRPC status errors map to their HTTP status.
+type MethodMiddleware func(ctx context.Context, method string) error
+
+type methodMiddlewareRule struct {
+	methods     []string
+	middlewares []MethodMiddleware
+}
+
+// runMethodMiddlewares runs the middlewares of every rule matching method.
+func (a *App) runMethodMiddlewares(ctx context.Context, method string) error {
+	for _, rule := range a.methodMiddlewares {
+		if !matchMethod(method, rule.methods) {
+			continue
+		}
+		for _, mw := range rule.middlewares {
+			if err := mw(ctx, method); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (a *App) methodMiddlewareUnary() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := a.runMethodMiddlewares(ctx, method); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func (a *App) methodMiddlewareStream() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if err := a.runMethodMiddlewares(ctx, method); err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+// matchMethod reports whether method is listed in methods, either by full
+// name or through a "/pkg.Service/*" wildcard.
+func matchMethod(method string, methods []string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+		if service, ok := strings.CutSuffix(m, "/*"); ok && strings.HasPrefix(method, service+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasHTTPRules reports whether any method of the named proto service carries a
+// google.api.http option. Services missing from the registry report false.
+func hasHTTPRules(name string) bool {
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return false
+	}
+	sd, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return false
+	}
+
+	methods := sd.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		opts := methods.Get(i).Options()
+		if opts != nil && proto.HasExtension(opts, annotations.E_Http) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package tonica
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/tonica-go/tonica/pkg/tonica/service"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// GatewayLimits bound a gateway request before it is forwarded to gRPC.
+type GatewayLimits struct {
+	// MaxBodyBytes limits the request body. Larger bodies are rejected with
+	// 413 Request Entity Too Large when their length is known, and fail to
+	// decode otherwise. Zero is unlimited.
+	MaxBodyBytes int64
+	// Timeout bounds the gRPC call. A shorter deadline sent by the client
+	// still applies. Zero leaves it to the client and the server.
+	Timeout time.Duration
+}
+
+type methodLimitsRule struct {
+	methods []string
+	limits  GatewayLimits
+}
+
+// gatewayLimits returns the limits of method: those of the last matching
+// WithMethodLimits rule, with unset fields taken from WithGatewayLimits.
+func (a *App) gatewayLimits(method string) GatewayLimits {
+	limits := a.defaultGatewayLimits
+	for i := len(a.methodLimits) - 1; i >= 0; i-- {
+		rule := a.methodLimits[i]
+		if !matchMethod(method, rule.methods) {
+			continue
+		}
+		if rule.limits.MaxBodyBytes > 0 {
+			limits.MaxBodyBytes = rule.limits.MaxBodyBytes
+		}
+		if rule.limits.Timeout > 0 {
+			limits.Timeout = rule.limits.Timeout
+		}
+		break
+	}
+	return limits
+}
+
+// hasGatewayLimits reports whether any gateway limit is configured.
+func (a *App) hasGatewayLimits() bool {
+	return a.defaultGatewayLimits != (GatewayLimits{}) || len(a.methodLimits) > 0
+}
+
+// gatewayLimitsMiddleware enforces the limits of the gRPC method a gateway
+// route of services is mapped to. It runs once the gateway matched the route,
+// before the body is decoded.
+func (a *App) gatewayLimitsMiddleware(services []*service.Service) runtime.Middleware {
+	routes := httpRuleMethods(a.gatewayProtoServices(services))
+	return func(next runtime.HandlerFunc) runtime.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			var method string
+			if pattern, ok := runtime.HTTPPattern(r.Context()); ok {
+				method = routes[r.Method+" "+pattern.String()]
+			}
+			limits := a.gatewayLimits(method)
+
+			if limits.MaxBodyBytes > 0 && r.Body != nil {
+				if r.ContentLength > limits.MaxBodyBytes {
+					http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+					return
+				}
+				r.Body = http.MaxBytesReader(w, r.Body, limits.MaxBodyBytes)
+			}
+			if limits.Timeout > 0 {
+				ctx, cancel := context.WithTimeout(r.Context(), limits.Timeout)
+				defer cancel()
+				r = r.WithContext(ctx)
+			}
+			next(w, r, pathParams)
+		}
+	}
+}
+
+// gatewayProtoServices returns the proto services registered by services, or
+// nil when they are not known for every service, e.g. in gateway mode where
+// the services run elsewhere.
+func (a *App) gatewayProtoServices(services []*service.Service) []string {
+	var names []string
+	for _, svc := range services {
+		protoServices := a.protoServices[svc.GetName()]
+		if len(protoServices) == 0 {
+			return nil
+		}
+		names = append(names, protoServices...)
+	}
+	return names
+}
+
+// httpRuleMethods maps "<HTTP method> <path template>" of the google.api.http
+// bindings of protoServices, or of every service in the proto registry when
+// none are given, to their full gRPC method name. Templates are normalized to
+// the form the gateway reports for matched routes. A route bound by several
+// methods maps to "", so only the default limits apply to it.
+func httpRuleMethods(protoServices []string) map[string]string {
+	routes := make(map[string]string)
+	protoregistry.GlobalFiles.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		services := fd.Services()
+		for i := 0; i < services.Len(); i++ {
+			if len(protoServices) > 0 && !slices.Contains(protoServices, string(services.Get(i).FullName())) {
+				continue
+			}
+			methods := services.Get(i).Methods()
+			for j := 0; j < methods.Len(); j++ {
+				md := methods.Get(j)
+				opts := md.Options()
+				if opts == nil || !proto.HasExtension(opts, annotations.E_Http) {
+					continue
+				}
+				rule, ok := proto.GetExtension(opts, annotations.E_Http).(*annotations.HttpRule)
+				if !ok {
+					continue
+				}
+				name := "/" + string(md.Parent().FullName()) + "/" + string(md.Name())
+				for _, binding := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+					verb, template := httpRuleBinding(binding)
+					if template == "" {
+						continue
+					}
+					key := verb + " " + normalizeHTTPTemplate(template)
+					if bound, ok := routes[key]; ok && bound != name {
+						routes[key] = ""
+						continue
+					}
+					routes[key] = name
+				}
+			}
+		}
+		return true
+	})
+	return routes
+}
+
+func httpRuleBinding(rule *annotations.HttpRule) (string, string) {
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return http.MethodGet, p.Get
+	case *annotations.HttpRule_Put:
+		return http.MethodPut, p.Put
+	case *annotations.HttpRule_Post:
+		return http.MethodPost, p.Post
+	case *annotations.HttpRule_Delete:
+		return http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		return http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Custom:
+		return p.Custom.GetKind(), p.Custom.GetPath()
+	}
+	return "", ""
+}
+
+// bareVariable matches path variables without a segment pattern, "{name}".
+var bareVariable = regexp.MustCompile(`\{([^}=]+)\}`)
+
+// normalizeHTTPTemplate spells out single segment variables, so "/v1/{id}"
+// becomes "/v1/{id=*}".
+func normalizeHTTPTemplate(template string) string {
+	return bareVariable.ReplaceAllString(template, "{$1=*}")
+}
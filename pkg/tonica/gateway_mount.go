@@ -0,0 +1,164 @@
+package tonica
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/tonica-go/tonica/pkg/tonica/config"
+	"github.com/tonica-go/tonica/pkg/tonica/service"
+)
+
+// GatewayMount serves the gateway routes of a set of services below a path
+// prefix, e.g. "/v2" next to "/v1" while clients migrate between API
+// versions. Services in a mount are not served by the default gateway.
+type GatewayMount struct {
+	// Prefix is stripped from the request path before it is matched against
+	// the HTTP annotations of the services, so "/v2/orders" reaches the
+	// "/orders" route of a v2 service.
+	Prefix string
+	// Services are the names of registered services. A service may be listed
+	// by several mounts.
+	Services []string
+	// Spec is the path of the OpenAPI spec of the mount, served at
+	// <Prefix>/openapi.json. Optional.
+	Spec string
+}
+
+// resolvedMount is a gateway mount with its services looked up.
+type resolvedMount struct {
+	prefix   string
+	services []*service.Service
+}
+
+// splitGatewayMounts resolves the gateway mounts against the registered
+// services and returns the services served by the default gateway. Problems
+// are reported in one *config.ValidationError.
+func (a *App) splitGatewayMounts(services []*service.Service) ([]resolvedMount, []*service.Service, error) {
+	byName := make(map[string]*service.Service, len(services))
+	for _, svc := range services {
+		byName[svc.GetName()] = svc
+	}
+
+	var problems []string
+	mounts := make([]resolvedMount, 0, len(a.gatewayMounts))
+	prefixes := make(map[string]bool)
+	mounted := make(map[string]bool)
+	for _, m := range a.gatewayMounts {
+		prefix := cleanMountPrefix(m.Prefix)
+		if prefix == "/" {
+			problems = append(problems, fmt.Sprintf("gateway mount %q: prefix must not be empty", m.Prefix))
+			continue
+		}
+		if prefixes[prefix] {
+			problems = append(problems, fmt.Sprintf("gateway mount %s: prefix is used twice", prefix))
+			continue
+		}
+		prefixes[prefix] = true
+
+		mount := resolvedMount{prefix: prefix}
+		for _, name := range m.Services {
+			svc, ok := byName[name]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("gateway mount %s: unknown service %q", prefix, name))
+				continue
+			}
+			mount.services = append(mount.services, svc)
+			mounted[name] = true
+		}
+		mounts = append(mounts, mount)
+	}
+	if len(problems) > 0 {
+		return nil, nil, &config.ValidationError{Problems: problems}
+	}
+
+	var unmounted []*service.Service
+	for _, svc := range services {
+		if !mounted[svc.GetName()] {
+			unmounted = append(unmounted, svc)
+		}
+	}
+	return mounts, unmounted, nil
+}
+
+func cleanMountPrefix(prefix string) string {
+	return path.Clean("/" + prefix)
+}
+
+// mountedGateway is the gateway handler of one mount.
+type mountedGateway struct {
+	prefix  string
+	handler http.Handler
+}
+
+// gatewayMux dispatches a gateway request to the mount whose prefix matches
+// its path, or to the default gateway.
+type gatewayMux struct {
+	mounts   []mountedGateway
+	fallback http.Handler
+}
+
+// sortMounts orders the mounts longest prefix first, so "/v2/beta" wins over
+// "/v2".
+func (g *gatewayMux) sortMounts() {
+	sort.SliceStable(g.mounts, func(i, j int) bool {
+		return len(g.mounts[i].prefix) > len(g.mounts[j].prefix)
+	})
+}
+
+func (g *gatewayMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, m := range g.mounts {
+		if r.URL.Path == m.prefix || strings.HasPrefix(r.URL.Path, m.prefix+"/") {
+			http.StripPrefix(m.prefix, m.handler).ServeHTTP(w, r)
+			return
+		}
+	}
+	g.fallback.ServeHTTP(w, r)
+}
+
+// mountSpecContent reads the OpenAPI spec of a mount and points it at the
+// URL the mount is served from.
+func (a *App) mountSpecContent(m GatewayMount) ([]byte, error) {
+	specBytes, err := os.ReadFile(m.Spec)
+	if err != nil {
+		return nil, err
+	}
+	specBytes, err = applySpecServer(specBytes, a.getPublicURL())
+	if err != nil {
+		return nil, err
+	}
+	return prefixSpecServer(specBytes, cleanMountPrefix(m.Prefix))
+}
+
+// prefixSpecServer appends prefix to the server URLs (OpenAPI 3.x) or the
+// base path (Swagger 2.0) of the spec. OpenAPI 3.x specs without servers are
+// served from prefix on the same origin.
+func prefixSpecServer(specBytes []byte, prefix string) ([]byte, error) {
+	var spec map[string]interface{}
+	if err := json.Unmarshal(specBytes, &spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal spec: %w", err)
+	}
+
+	_, openapi := spec["openapi"]
+	servers, _ := spec["servers"].([]interface{})
+	switch {
+	case openapi && len(servers) == 0:
+		spec["servers"] = []map[string]string{{"url": prefix}}
+	case openapi:
+		for _, s := range servers {
+			if server, ok := s.(map[string]interface{}); ok {
+				serverURL, _ := server["url"].(string)
+				server["url"] = strings.TrimSuffix(serverURL, "/") + prefix
+			}
+		}
+	default:
+		basePath, _ := spec["basePath"].(string)
+		spec["basePath"] = path.Join("/", basePath, prefix)
+	}
+
+	return json.MarshalIndent(spec, "", "  ")
+}
@@ -7,12 +7,13 @@ import (
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/retry"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 type ServiceConfig struct {
 	Address string
 	Name    string
+	// TLS, when set, makes the connection dial the service over TLS.
+	TLS *TLSConfig
 }
 
 const maxDelay = 10 * time.Second
@@ -23,9 +24,18 @@ func CreateDefaultConnectionParams() grpc.ConnectParams {
 	return grpc.ConnectParams{Backoff: bc}
 }
 
+// MustCreateNewNonBlockingServiceConnection dials the service with the
+// credentials of config.TLS. opts are applied after the defaults and may
+// override them.
 func MustCreateNewNonBlockingServiceConnection(config ServiceConfig, opts ...grpc.DialOption) *grpc.ClientConn {
-	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	opts = append(opts, grpc.WithConnectParams(CreateDefaultConnectionParams()))
+	creds, err := config.TLS.Credentials()
+	if err != nil {
+		log.Panicf("unrecoverable error occurred while loading grpc credentials - %v", err)
+	}
+	opts = append([]grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithConnectParams(CreateDefaultConnectionParams()),
+	}, opts...)
 	opts = append(opts, grpc.WithChainUnaryInterceptor(
 		retry.UnaryClientInterceptor(),
 	))
@@ -42,7 +52,11 @@ type ClientConnections struct {
 }
 
 func (c *ClientConnections) CreateNewConnection(serviceConfig ServiceConfig) *grpc.ClientConn {
-	serviceConn, err := grpc.NewClient(serviceConfig.Address, grpc.WithConnectParams(CreateDefaultConnectionParams()), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	creds, err := serviceConfig.TLS.Credentials()
+	if err != nil {
+		panic(err)
+	}
+	serviceConn, err := grpc.NewClient(serviceConfig.Address, grpc.WithConnectParams(CreateDefaultConnectionParams()), grpc.WithTransportCredentials(creds))
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,76 @@
+package serviceconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// TLSConfig holds the files a client dials a TLS gRPC server with.
+type TLSConfig struct {
+	// CAFile verifies the server certificate. The system roots are used
+	// when empty.
+	CAFile string
+	// CertFile and KeyFile are the client certificate presented to servers
+	// requiring mTLS and may be empty.
+	CertFile string
+	KeyFile  string
+}
+
+// TLSFromEnv reads <prefix>_GRPC_TLS_CA, <prefix>_GRPC_TLS_CERT and
+// <prefix>_GRPC_TLS_KEY, e.g. PAYMENTSERVICE_SERVICE_GRPC_TLS_CA. It returns
+// nil when none is set.
+func TLSFromEnv(prefix string) *TLSConfig {
+	cfg := &TLSConfig{
+		CAFile:   strings.TrimSpace(os.Getenv(prefix + "_GRPC_TLS_CA")),
+		CertFile: strings.TrimSpace(os.Getenv(prefix + "_GRPC_TLS_CERT")),
+		KeyFile:  strings.TrimSpace(os.Getenv(prefix + "_GRPC_TLS_KEY")),
+	}
+	if *cfg == (TLSConfig{}) {
+		return nil
+	}
+	return cfg
+}
+
+// Credentials loads the files into gRPC transport credentials. A nil config
+// dials without TLS.
+func (c *TLSConfig) Credentials() (credentials.TransportCredentials, error) {
+	if c == nil {
+		return insecure.NewCredentials(), nil
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.CAFile != "" {
+		pool, err := LoadCertPool(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load TLS key pair: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
+// LoadCertPool reads a PEM encoded CA bundle.
+func LoadCertPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("read CA file: no certificates found in " + caFile)
+	}
+	return pool, nil
+}
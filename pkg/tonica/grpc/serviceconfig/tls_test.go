@@ -0,0 +1,28 @@
+package serviceconfig //nolint:testpackage // tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSFromEnv(t *testing.T) {
+	require.Nil(t, TLSFromEnv("BILLING_SERVICE"))
+
+	t.Setenv("BILLING_SERVICE_GRPC_TLS_CA", "ca.crt")
+	require.Equal(t, &TLSConfig{CAFile: "ca.crt"}, TLSFromEnv("BILLING_SERVICE"))
+}
+
+func TestTLSConfig_Credentials(t *testing.T) {
+	var cfg *TLSConfig
+	creds, err := cfg.Credentials()
+	require.NoError(t, err)
+	require.Equal(t, "insecure", creds.Info().SecurityProtocol)
+
+	_, err = (&TLSConfig{CAFile: "missing.crt"}).Credentials()
+	require.Error(t, err)
+
+	creds, err = (&TLSConfig{}).Credentials()
+	require.NoError(t, err)
+	require.Equal(t, "tls", creds.Info().SecurityProtocol)
+}
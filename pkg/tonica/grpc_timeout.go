@@ -0,0 +1,36 @@
+package tonica
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+)
+
+// grpcTimeoutMetric counts calls cancelled by the default gRPC deadline.
+const grpcTimeoutMetric = "app_grpc_default_timeout_count"
+
+// timeoutUnary applies the default deadline to unary calls that arrive
+// without one. Streams are exempt: change feeds, tails and reflection stay
+// open for as long as the client wants.
+func (a *App) timeoutUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := ctx.Deadline(); ok {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, a.grpcTimeout)
+		defer cancel()
+
+		resp, err := handler(ctx, req)
+		a.recordDefaultTimeout(ctx, info.FullMethod)
+		return resp, err
+	}
+}
+
+// recordDefaultTimeout counts the call when ctx, carrying the default
+// deadline, has expired.
+func (a *App) recordDefaultTimeout(ctx context.Context, method string) {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		a.GetMetricManager().IncrementCounter(ctx, grpcTimeoutMetric, "method", method)
+	}
+}
@@ -0,0 +1,14 @@
+package tonica
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/tonica-go/tonica/pkg/tonica/identity"
+)
+
+// Identity returns the authenticated identity of the request, as stored by
+// UseBetterAuthMiddleware or identity.Middleware. Use it in custom route
+// handlers instead of reading the "identity" gin key directly.
+func Identity(c *gin.Context) (identity.Identity, bool) {
+	id := identity.FromGinContext(c)
+	return id, id != nil
+}
@@ -8,7 +8,8 @@ import (
 )
 
 const (
-	// IdentityContextKey is the key for identity in context
+	// IdentityContextKey is the Temporal header and gin key identity is
+	// stored under. Go contexts use NewContext and FromContext instead.
 	IdentityContextKey = "identity"
 )
 
@@ -25,7 +26,7 @@ func NewIdentityContextPropagator() *IdentityContextPropagator {
 // Inject extracts identity from Go context and adds it to Temporal headers
 // This is called when starting a workflow or activity from regular Go code
 func (p *IdentityContextPropagator) Inject(ctx context.Context, writer workflow.HeaderWriter) error {
-	identity := ctx.Value(IdentityContextKey)
+	identity := FromContext(ctx)
 	if identity == nil {
 		return nil
 	}
@@ -43,7 +44,7 @@ func (p *IdentityContextPropagator) Inject(ctx context.Context, writer workflow.
 // InjectFromWorkflow extracts identity from workflow context and adds it to headers
 // This is called when starting a child workflow or activity from within a workflow
 func (p *IdentityContextPropagator) InjectFromWorkflow(ctx workflow.Context, writer workflow.HeaderWriter) error {
-	identity := ctx.Value(IdentityContextKey)
+	identity := FromWorkflowContext(ctx)
 	if identity == nil {
 		return nil
 	}
@@ -71,7 +72,7 @@ func (p *IdentityContextPropagator) Extract(ctx context.Context, reader workflow
 		return ctx, err
 	}
 
-	return context.WithValue(ctx, IdentityContextKey, identity), nil
+	return NewContext(ctx, FromValue(identity)), nil
 }
 
 // ExtractToWorkflow retrieves identity from headers and adds it to workflow context
@@ -87,5 +88,5 @@ func (p *IdentityContextPropagator) ExtractToWorkflow(ctx workflow.Context, read
 		return ctx, err
 	}
 
-	return workflow.WithValue(ctx, IdentityContextKey, identity), nil
+	return ToWorkflowContext(ctx, FromValue(identity)), nil
 }
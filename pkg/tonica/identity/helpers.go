@@ -41,44 +41,46 @@ func (i Identity) GetName() string {
 	return ""
 }
 
-// FromContext extracts identity from Go context
-// Returns nil if identity is not found
-func FromContext(ctx context.Context) Identity {
-	identity := ctx.Value(IdentityContextKey)
-	if identity == nil {
+// FromValue converts a stored identity value to Identity
+// Returns nil if the value is not an identity map
+func FromValue(value interface{}) Identity {
+	switch identity := value.(type) {
+	case Identity:
+		return identity
+	case map[string]interface{}:
+		return Identity(identity)
+	default:
 		return nil
 	}
+}
 
-	// Try to convert to Identity map
-	if identityMap, ok := identity.(map[string]interface{}); ok {
-		return Identity(identityMap)
-	}
+// contextKey is the context key identities are stored under, in both Go and
+// Temporal workflow contexts.
+type contextKey struct{}
 
-	return nil
+// FromContext extracts identity from Go context
+// Returns nil if identity is not found
+func FromContext(ctx context.Context) Identity {
+	return FromValue(ctx.Value(contextKey{}))
 }
 
 // FromWorkflowContext extracts identity from Temporal workflow context
 // Returns nil if identity is not found
 func FromWorkflowContext(ctx workflow.Context) Identity {
-	identity := ctx.Value(IdentityContextKey)
-	if identity == nil {
-		return nil
-	}
-
-	// Try to convert to Identity map
-	if identityMap, ok := identity.(map[string]interface{}); ok {
-		return Identity(identityMap)
-	}
-
-	return nil
+	return FromValue(ctx.Value(contextKey{}))
 }
 
-// ToContext adds identity to Go context
-func ToContext(ctx context.Context, identity Identity) context.Context {
+// NewContext returns a copy of ctx carrying identity
+func NewContext(ctx context.Context, identity Identity) context.Context {
 	if identity == nil {
 		return ctx
 	}
-	return context.WithValue(ctx, IdentityContextKey, map[string]interface{}(identity))
+	return context.WithValue(ctx, contextKey{}, identity)
+}
+
+// ToContext adds identity to Go context. It is the same as NewContext.
+func ToContext(ctx context.Context, identity Identity) context.Context {
+	return NewContext(ctx, identity)
 }
 
 // ToWorkflowContext adds identity to Temporal workflow context
@@ -86,7 +88,7 @@ func ToWorkflowContext(ctx workflow.Context, identity Identity) workflow.Context
 	if identity == nil {
 		return ctx
 	}
-	return workflow.WithValue(ctx, IdentityContextKey, map[string]interface{}(identity))
+	return workflow.WithValue(ctx, contextKey{}, identity)
 }
 
 // NewIdentity creates a new Identity with minimal required information
@@ -16,8 +16,9 @@ func Middleware(extractor IdentityExtractor) gin.HandlerFunc {
 		// Extract identity using the provided extractor
 		identity := extractor(c)
 
-		// If identity was extracted, add it to the request context
+		// If identity was extracted, add it to the gin and request contexts
 		if identity != nil {
+			ToGinContext(c, identity)
 			ctx := ToContext(c.Request.Context(), identity)
 			c.Request = c.Request.WithContext(ctx)
 		}
@@ -30,16 +31,33 @@ func Middleware(extractor IdentityExtractor) gin.HandlerFunc {
 // This is useful when you already have identity stored in gin.Context by your auth middleware
 func DefaultExtractor(c *gin.Context) Identity {
 	if identity, exists := c.Get(IdentityContextKey); exists {
-		if identityMap, ok := identity.(map[string]interface{}); ok {
-			return Identity(identityMap)
-		}
-		if identityMap, ok := identity.(Identity); ok {
-			return identityMap
-		}
+		return FromValue(identity)
 	}
 	return nil
 }
 
+// FromGinContext extracts identity stored in gin.Context by an auth middleware
+// or by Middleware, falling back to the request context.
+// Returns nil if identity is not found
+func FromGinContext(c *gin.Context) Identity {
+	if identity := DefaultExtractor(c); identity != nil {
+		return identity
+	}
+	if c.Request == nil {
+		return nil
+	}
+	return FromContext(c.Request.Context())
+}
+
+// ToGinContext stores identity in gin.Context. The value is always stored as a
+// plain map[string]interface{} so every reader can rely on the same type.
+func ToGinContext(c *gin.Context, identity Identity) {
+	if identity == nil {
+		return
+	}
+	c.Set(IdentityContextKey, map[string]interface{}(identity))
+}
+
 // HeaderExtractor creates an extractor that reads identity from request headers
 // This is useful for simple authentication where user info is passed in headers
 func HeaderExtractor(userIDHeader, emailHeader, roleHeader string) IdentityExtractor {
@@ -14,11 +14,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/tonica-go/tonica/pkg/tonica/config"
+	"github.com/tonica-go/tonica/pkg/tonica/identity"
 )
 
-func newIdentityMapFromClaims(claims jwt.Claims) map[string]any {
+func newIdentityMapFromClaims(claims jwt.Claims) identity.Identity {
 	mapClaims := claims.(jwt.MapClaims)
-	return mapClaims
+	return identity.Identity(mapClaims)
 }
 
 func UseBetterAuthMiddleware(ctx *gin.Context) {
@@ -67,8 +68,7 @@ func UseBetterAuthMiddleware(ctx *gin.Context) {
 		ctx.AbortWithStatus(401)
 		return
 	}
-	identityVal := newIdentityMapFromClaims(token.Claims)
-	ctx.Set("identity", identityVal)
+	identity.ToGinContext(ctx, newIdentityMapFromClaims(token.Claims))
 	ctx.Next()
 }
 
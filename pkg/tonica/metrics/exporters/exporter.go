@@ -11,22 +11,31 @@ import (
 )
 
 func Prometheus(appName, appVersion string) metric.Meter {
-	exporter, err := prometheus.New(
-		prometheus.WithoutTargetInfo(),
-		prometheus.WithTranslationStrategy(otlptranslator.NoTranslation))
-	if err != nil {
-		return nil
-	}
+	return New(appName, appVersion, true)
+}
 
-	meter := metricSdk.NewMeterProvider(
-		metricSdk.WithReader(exporter),
+// New returns a meter backed by the Prometheus exporter when withPrometheus is
+// set, and a meter that records into nothing otherwise.
+func New(appName, appVersion string, withPrometheus bool) metric.Meter {
+	opts := []metricSdk.Option{
 		metricSdk.WithResource(resource.NewWithAttributes(
 			semconv.SchemaURL,
 			semconv.ServiceNameKey.String(appName),
 			attribute.String("framework_version", "version.Framework"),
-		))).Meter(appName, metric.WithInstrumentationVersion(appVersion))
+		)),
+	}
+
+	if withPrometheus {
+		exporter, err := prometheus.New(
+			prometheus.WithoutTargetInfo(),
+			prometheus.WithTranslationStrategy(otlptranslator.NoTranslation))
+		if err != nil {
+			return nil
+		}
+		opts = append(opts, metricSdk.WithReader(exporter))
+	}
 
-	return meter
+	return metricSdk.NewMeterProvider(opts...).Meter(appName, metric.WithInstrumentationVersion(appVersion))
 }
 
 // TODO : OTLPStdOut and OTLPMetricHTTP are not being used but has to be modified such that user can decide the exporter.
@@ -0,0 +1,217 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tonica-go/tonica/pkg/tonica/modules/eventstore"
+)
+
+// BatchOptions control how CreateRecords and UpdateRecords apply a batch.
+type BatchOptions struct {
+	// Atomic applies the batch all-or-nothing: every item is validated up
+	// front and the writes share one transaction, so the first failure rolls
+	// back the whole batch. It needs a transactional event store, and a
+	// TxProvider joining its transactions for entities with a provider; it
+	// fails with ErrAtomicUnsupported otherwise. Without Atomic items are written
+	// independently and failed items are reported in a BatchError next to
+	// the records that were written.
+	Atomic bool
+}
+
+// ErrAtomicUnsupported is returned for atomic batches when the event store or
+// the provider of the entity cannot roll back a partially written batch.
+var ErrAtomicUnsupported = errors.New("atomic batches need a transactional event store and provider")
+
+// ErrBatchAborted is returned, wrapping the BatchError of the failed item,
+// when a write of an atomic batch failed and the batch was rolled back.
+var ErrBatchAborted = errors.New("batch aborted")
+
+// RecordUpdate is a single item of an UpdateRecords batch.
+type RecordUpdate struct {
+	ID   string
+	Data map[string]any
+}
+
+// BatchItemError is the failure of one item of a batch.
+type BatchItemError struct {
+	// Index is the position of the item in the batch.
+	Index int
+	// ID is the record id of the item, when known.
+	ID  string
+	Err error
+}
+
+func (e BatchItemError) Error() string {
+	if e.ID == "" {
+		return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+	}
+	return fmt.Sprintf("item %d (%s): %v", e.Index, e.ID, e.Err)
+}
+
+func (e BatchItemError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError aggregates the failed items of a batch, ordered by index.
+type BatchError []BatchItemError
+
+func (e BatchError) Error() string {
+	parts := make([]string, 0, len(e))
+	for _, item := range e {
+		parts = append(parts, item.Error())
+	}
+	return fmt.Sprintf("batch failed for %d item(s): %s", len(e), strings.Join(parts, "; "))
+}
+
+func (e BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, item := range e {
+		errs = append(errs, item)
+	}
+	return errs
+}
+
+// batchItem is a validated batch entry ready to be written. Items that failed
+// validation carry the error instead.
+type batchItem struct {
+	id   string
+	data map[string]any
+	err  error
+}
+
+// CreateRecords creates a record per payload. Records are returned in payload
+// order. Without opts.Atomic a partial failure returns the created records,
+// with zero values for the failed items, along with a BatchError.
+func (s *Service) CreateRecords(ctx context.Context, entityID string, payloads []map[string]any, opts BatchOptions) ([]Record, error) {
+	def, err := s.Definition(entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]batchItem, len(payloads))
+	seen := make(map[string]struct{}, len(payloads))
+	for i, payload := range payloads {
+		data, err := s.prepareCreate(ctx, def, payload)
+		id := asString(payload[def.PrimaryKey])
+		if err == nil && id != "" {
+			if _, dup := seen[id]; dup {
+				err = fmt.Errorf("record %s already exists", id)
+			}
+			seen[id] = struct{}{}
+		}
+		items[i] = batchItem{id: id, data: data, err: err}
+	}
+
+	return s.applyBatch(ctx, def, items, opts, func(ctx context.Context, item batchItem) (Record, error) {
+		return s.createRecord(ctx, def, item.data)
+	})
+}
+
+// UpdateRecords applies a partial update per item, like UpdateRecord. An id
+// may appear only once per batch. Results follow CreateRecords.
+func (s *Service) UpdateRecords(ctx context.Context, entityID string, updates []RecordUpdate, opts BatchOptions) ([]Record, error) {
+	def, err := s.Definition(entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]batchItem, len(updates))
+	seen := make(map[string]struct{}, len(updates))
+	for i, update := range updates {
+		data, err := s.prepareUpdate(ctx, def, update.Data)
+		if err == nil {
+			if update.ID == "" {
+				err = fmt.Errorf("%w: missing record id", ErrInvalidPayload)
+			} else if _, dup := seen[update.ID]; dup {
+				err = fmt.Errorf("%w: duplicate record id %s", ErrInvalidPayload, update.ID)
+			}
+			seen[update.ID] = struct{}{}
+		}
+		items[i] = batchItem{id: update.ID, data: data, err: err}
+	}
+
+	return s.applyBatch(ctx, def, items, opts, func(ctx context.Context, item batchItem) (Record, error) {
+		return s.updateRecord(ctx, def, item.id, item.data)
+	})
+}
+
+func (s *Service) applyBatch(ctx context.Context, def Definition, items []batchItem, opts BatchOptions, write func(ctx context.Context, item batchItem) (Record, error)) ([]Record, error) {
+	records := make([]Record, len(items))
+	var failed BatchError
+
+	if opts.Atomic {
+		if !s.transactional(ctx, def) {
+			return nil, ErrAtomicUnsupported
+		}
+		for i, item := range items {
+			if item.err != nil {
+				failed = append(failed, BatchItemError{Index: i, ID: item.id, Err: item.err})
+			}
+		}
+		if len(failed) > 0 {
+			return nil, failed
+		}
+		err := s.inUnitOfWork(ctx, func(ctx context.Context) error {
+			for i, item := range items {
+				record, err := write(ctx, item)
+				if err != nil {
+					return fmt.Errorf("%w: %w", ErrBatchAborted, BatchError{{Index: i, ID: item.id, Err: err}})
+				}
+				records[i] = record
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return records, nil
+	}
+
+	for i, item := range items {
+		err := item.err
+		if err == nil {
+			records[i], err = write(ctx, item)
+		}
+		if err != nil {
+			failed = append(failed, BatchItemError{Index: i, ID: item.id, Err: err})
+		}
+	}
+	if len(failed) > 0 {
+		return records, failed
+	}
+	return records, nil
+}
+
+// transactional reports whether the writes of ctx to def can be rolled back:
+// they join a unit of work with a transaction, or a new one can begin one,
+// and the provider of def, if any, joins that transaction.
+func (s *Service) transactional(ctx context.Context, def Definition) bool {
+	if provider, ok := s.providerFor(def.ID); ok {
+		txProvider, ok := provider.(TxProvider)
+		if !ok || !txProvider.JoinsUnitOfWork() {
+			return false
+		}
+	}
+	if uow, ok := UnitOfWorkFromContext(ctx); ok {
+		return uow.tx != nil
+	}
+	_, ok := s.store.(eventstore.Transactional)
+	return ok
+}
+
+// batchItemErrors returns the per-item errors of err keyed by index, or nil
+// when err is not a BatchError.
+func batchItemErrors(err error) map[int]error {
+	var batchErr BatchError
+	if !errors.As(err, &batchErr) {
+		return nil
+	}
+	byIndex := make(map[int]error, len(batchErr))
+	for _, item := range batchErr {
+		byIndex[item.Index] = item.Err
+	}
+	return byIndex
+}
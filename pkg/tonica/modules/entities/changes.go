@@ -0,0 +1,236 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tonica-go/tonica/pkg/tonica/modules/eventstore"
+)
+
+// ChangeType is the kind of record change reported by StreamChanges.
+type ChangeType string
+
+const (
+	ChangeCreated  ChangeType = "created"
+	ChangeUpdated  ChangeType = "updated"
+	ChangeDeleted  ChangeType = "deleted"
+	ChangeRestored ChangeType = "restored"
+)
+
+// changeStateCacheSize bounds the record states kept by StreamChanges to
+// compute the before image of a change. States evicted from the cache are
+// loaded from the record stream again.
+const changeStateCacheSize = 1024
+
+// Change is a normalized change of one record of an entity.
+type Change struct {
+	// Position is the feed position of the change. Changes whose write
+	// committed late arrive late and out of position order, see
+	// eventstore.Follow, so a consumer checkpoints the highest position it
+	// processed and resumes after a restart with that position plus one.
+	Position  int64
+	Entity    string
+	RecordID  string
+	Type      ChangeType
+	Version   int64
+	Timestamp time.Time
+	Actor     string
+	// Before is the record data before the change. It is nil for created
+	// and restored records.
+	Before map[string]any
+	// After is the record data after the change. It is nil for deleted
+	// records.
+	After map[string]any
+}
+
+// ChangeStreamOptions control StreamChanges.
+type ChangeStreamOptions struct {
+	// FromPosition is the first feed position to return. Zero replays every
+	// change of the entity.
+	FromPosition int64
+	// Interval is the poll interval once the stream caught up. Defaults to
+	// one second.
+	Interval time.Duration
+}
+
+// StreamChanges replays the changes of every record of entityID starting at
+// opts.FromPosition and then follows new ones, calling fn for each until ctx
+// is done or fn fails. Unlike the pubsub notifications it is read from the
+// event store, so it is durable and can be resumed from any position. Change
+// data goes through the read transforms of the entity. The store must
+// implement the aggregate-type feed, otherwise ErrFeedUnsupported is
+// returned.
+func (s *Service) StreamChanges(ctx context.Context, entityID string, opts ChangeStreamOptions, fn func(Change) error) error {
+	def, err := s.Definition(entityID)
+	if err != nil {
+		return err
+	}
+	if _, ok := s.providerFor(def.ID); ok {
+		return fmt.Errorf("%w: %s is served by a provider", ErrFeedUnsupported, def.ID)
+	}
+	feed, ok := s.store.(eventstore.TypeFeed)
+	if !ok {
+		return ErrFeedUnsupported
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultTailInterval
+	}
+
+	load := func(ctx context.Context, fromID int64, limit int) ([]eventstore.Event, error) {
+		return feed.LoadByType(ctx, aggregateType(def.ID), fromID, limit)
+	}
+	states := &changeStates{records: make(map[string]Record), primary: make(map[string]bool)}
+	return eventstore.Follow(ctx, load, opts.FromPosition, opts.Interval, func(evt eventstore.Event) error {
+		change, ok, err := s.applyChange(ctx, def, states, evt)
+		if err != nil {
+			return fmt.Errorf("event %d: %w", evt.ID, err)
+		}
+		if !ok {
+			return nil
+		}
+		return fn(change)
+	})
+}
+
+// changeStates caches what StreamChanges learned about the records of an
+// entity, both bounded by changeStateCacheSize.
+type changeStates struct {
+	// records are the states of records after their last streamed change.
+	records map[string]Record
+	// primary reports whether a record is written to its own stream, so
+	// the copies of its events on the legacy stream are skipped.
+	primary map[string]bool
+}
+
+// recordID returns the record an event of def belongs to, and false for the
+// copy of an event that is also on the record's own stream.
+func (s *Service) recordID(ctx context.Context, def Definition, states *changeStates, evt eventstore.Event) (string, bool, error) {
+	recordID, legacy := strings.CutPrefix(evt.AggregateID, legacyRecordStreamID(def.ID, ""))
+	if !legacy {
+		return evt.AggregateID, true, nil
+	}
+	primary, ok := states.primary[recordID]
+	if !ok {
+		events, err := s.store.Load(ctx, recordStreamID(def.ID, recordID), 0)
+		if err != nil {
+			return "", false, err
+		}
+		primary = len(events) > 0
+		if len(states.primary) >= changeStateCacheSize {
+			clear(states.primary)
+		}
+		states.primary[recordID] = primary
+	}
+	return recordID, !primary, nil
+}
+
+// applyChange applies a feed event to the cached state of its record and
+// returns the resulting change. Events that do not change a record, such as
+// index events, report false.
+func (s *Service) applyChange(ctx context.Context, def Definition, states *changeStates, evt eventstore.Event) (Change, bool, error) {
+	var changeType ChangeType
+	switch evt.Type {
+	case eventTypeRecordCreated:
+		changeType = ChangeCreated
+	case eventTypeRecordUpdated:
+		changeType = ChangeUpdated
+	case eventTypeRecordDeleted:
+		changeType = ChangeDeleted
+	case eventTypeRecordRestored:
+		changeType = ChangeRestored
+	default:
+		return Change{}, false, nil
+	}
+
+	recordID, ok, err := s.recordID(ctx, def, states, evt)
+	if err != nil || !ok {
+		return Change{}, false, err
+	}
+	// The cached state is stale when changes committed late or were evicted.
+	before, ok := states.records[recordID]
+	if !ok || before.Version != evt.Version-1 {
+		before, err = s.recordStateBefore(ctx, def, recordID, evt.Version)
+		if err != nil {
+			return Change{}, false, err
+		}
+	}
+	// replayRecordFrom updates the data in place.
+	state := before
+	state.Data = cloneMap(before.Data)
+	after, err := replayRecordFrom(s.payloadCodec(), s.metadataCodec(), def, state, []eventstore.Event{evt})
+	if err != nil {
+		return Change{}, false, err
+	}
+	if len(states.records) >= changeStateCacheSize {
+		clear(states.records)
+	}
+	states.records[recordID] = after
+
+	meta, err := decodeEventMetadata(s.metadataCodec(), evt.Metadata)
+	if err != nil {
+		return Change{}, false, err
+	}
+	change := Change{
+		Position:  evt.ID,
+		Entity:    def.ID,
+		RecordID:  recordID,
+		Type:      changeType,
+		Version:   evt.Version,
+		Timestamp: meta.Timestamp,
+		Actor:     meta.ActorID,
+	}
+	if changeType == ChangeUpdated || changeType == ChangeDeleted {
+		if change.Before, err = s.changeData(ctx, def, before); err != nil {
+			return Change{}, false, err
+		}
+	}
+	if changeType != ChangeDeleted {
+		if change.After, err = s.changeData(ctx, def, after); err != nil {
+			return Change{}, false, err
+		}
+	}
+	return change, true, nil
+}
+
+// recordStateBefore replays the stream of a record up to, excluding, version.
+// Versions folded by compaction are gone, so their changes start from the
+// compacted state.
+func (s *Service) recordStateBefore(ctx context.Context, def Definition, recordID string, version int64) (Record, error) {
+	state := Record{Entity: def.ID, ID: recordID, Data: make(map[string]any)}
+	if version <= 1 {
+		return state, nil
+	}
+	streamID, events, err := s.loadRecordEvents(ctx, def, recordID)
+	if errors.Is(err, ErrRecordNotFound) {
+		return state, nil
+	}
+	if err != nil {
+		return Record{}, err
+	}
+	base, err := s.replayBase(ctx, def, recordID, streamID, events)
+	if err != nil {
+		return Record{}, err
+	}
+	events = eventsAfter(events, base.Version)
+	for i, evt := range events {
+		if evt.Version >= version {
+			events = events[:i]
+			break
+		}
+	}
+	return replayRecordFrom(s.payloadCodec(), s.metadataCodec(), def, base, events)
+}
+
+// changeData presents the data of record in a change, as for history
+// entries.
+func (s *Service) changeData(ctx context.Context, def Definition, record Record) (map[string]any, error) {
+	records := []Record{record}
+	records[0].Data = snapshotForHistory(def, record.Data)
+	if err := s.transformRecords(ctx, def.ID, records); err != nil {
+		return nil, err
+	}
+	return records[0].Data, nil
+}
@@ -0,0 +1,84 @@
+package entities
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// PayloadCodec encodes record payloads written to the event store.
+type PayloadCodec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec encodes payloads with encoding/json. Numbers decode as float64,
+// so integers beyond 2^53 lose precision.
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// NumberJSONCodec encodes payloads with encoding/json but decodes numbers as
+// json.Number, preserving the exact value of large integers.
+type NumberJSONCodec struct{}
+
+func (NumberJSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (NumberJSONCodec) Unmarshal(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return fmt.Errorf("unexpected data after payload")
+	}
+	return nil
+}
+
+// CodecByName returns the built-in codec for name: "json" or "number".
+func CodecByName(name string) (PayloadCodec, error) {
+	switch name {
+	case "json":
+		return JSONCodec{}, nil
+	case "number":
+		return NumberJSONCodec{}, nil
+	default:
+		return nil, fmt.Errorf("unknown payload codec %q", name)
+	}
+}
+
+// SetPayloadCodec configures the codec used for record payloads. Defaults to
+// NumberJSONCodec. Both built-in codecs write plain JSON, so they can be
+// switched on existing streams.
+func (s *Service) SetPayloadCodec(codec PayloadCodec) {
+	s.codec = codec
+}
+
+// SetMetadataCodec configures the codec used for event metadata. Defaults to
+// JSONCodec. Metadata already written must be readable by the new codec.
+func (s *Service) SetMetadataCodec(codec PayloadCodec) {
+	s.metaCodec = codec
+}
+
+func (s *Service) metadataCodec() PayloadCodec {
+	if s.metaCodec == nil {
+		return JSONCodec{}
+	}
+	return s.metaCodec
+}
+
+func (s *Service) payloadCodec() PayloadCodec {
+	if s.codec == nil {
+		return NumberJSONCodec{}
+	}
+	return s.codec
+}
@@ -0,0 +1,205 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/tonica-go/tonica/pkg/tonica/modules/eventstore"
+)
+
+// ErrCompactionUnsupported is returned when the event store cannot compact
+// streams, see eventstore.Compactor and eventstore.Snapshotter.
+var ErrCompactionUnsupported = errors.New("event store does not support compaction")
+
+// CompactionResult summarizes a compaction run for a single entity.
+type CompactionResult struct {
+	Entity        string
+	Records       int
+	RemovedEvents int64
+}
+
+// CompactRecord folds the events of a record that fall outside the entity
+// retention policy into a snapshot and removes them from the event store.
+// It returns the number of removed events.
+func (s *Service) CompactRecord(ctx context.Context, entityID, recordID string) (int64, error) {
+	def, err := s.retentionDefinition(entityID)
+	if err != nil {
+		return 0, err
+	}
+	return s.compactRecord(ctx, def, recordID, time.Now().UTC())
+}
+
+// CompactRecords applies the retention policy to every record of an entity,
+// including deleted ones.
+func (s *Service) CompactRecords(ctx context.Context, entityID string) (CompactionResult, error) {
+	def, err := s.retentionDefinition(entityID)
+	if err != nil {
+		return CompactionResult{}, err
+	}
+	if _, _, ok := s.compactor(); !ok {
+		return CompactionResult{}, ErrCompactionUnsupported
+	}
+
+	entries, err := s.loadIndexEntries(ctx, def.ID, true)
+	if err != nil {
+		return CompactionResult{}, err
+	}
+
+	result := CompactionResult{Entity: def.ID}
+	now := time.Now().UTC()
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		removed, err := s.compactRecord(ctx, def, entry.RecordID, now)
+		if errors.Is(err, ErrRecordNotFound) || errors.Is(err, eventstore.ErrVersionNotFound) {
+			// Record vanished or was compacted concurrently.
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("compact %s/%s: %w", def.ID, entry.RecordID, err)
+		}
+		if removed > 0 {
+			result.Records++
+			result.RemovedEvents += removed
+		}
+	}
+	return result, nil
+}
+
+// RunCompaction compacts every entity that declares a retention policy and
+// repeats on the given interval until ctx is cancelled.
+func (s *Service) RunCompaction(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("compaction interval must be positive, got %s", interval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.compactAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) compactAll(ctx context.Context) {
+	for _, def := range s.ListEntities() {
+		if !def.Retention.Enabled() {
+			continue
+		}
+		if _, ok := s.providerFor(def.ID); ok {
+			continue
+		}
+		result, err := s.CompactRecords(ctx, def.ID)
+		if err != nil {
+			slog.Warn("entity compaction failed", "entity", def.ID, "error", err.Error())
+			continue
+		}
+		if result.RemovedEvents > 0 {
+			slog.Info("entity compaction finished",
+				"entity", def.ID,
+				"records", result.Records,
+				"removed_events", result.RemovedEvents,
+			)
+		}
+	}
+}
+
+func (s *Service) retentionDefinition(entityID string) (Definition, error) {
+	def, err := s.Definition(entityID)
+	if err != nil {
+		return Definition{}, err
+	}
+	if !def.Retention.Enabled() {
+		return Definition{}, fmt.Errorf("%w: %s", ErrNoRetention, def.ID)
+	}
+	return def, nil
+}
+
+func (s *Service) compactRecord(ctx context.Context, def Definition, recordID string, now time.Time) (int64, error) {
+	compactor, snapshotter, ok := s.compactor()
+	if !ok {
+		return 0, ErrCompactionUnsupported
+	}
+	streamID, events, err := s.loadRecordEvents(ctx, def, recordID)
+	if err != nil {
+		return 0, err
+	}
+
+	cut, err := retentionCutoff(s.metadataCodec(), def.Retention, events, now)
+	if err != nil {
+		return 0, err
+	}
+	if cut <= 0 {
+		return 0, nil
+	}
+
+	codec := s.payloadCodec()
+	base, err := s.replayBase(ctx, def, recordID, streamID, events)
+	if err != nil {
+		return 0, err
+	}
+	state, err := replayRecordFrom(codec, s.metadataCodec(), def, base, eventsAfter(events[:cut+1], base.Version))
+	if err != nil {
+		return 0, err
+	}
+	snapshot, err := encodeSnapshot(codec, state)
+	if err != nil {
+		return 0, err
+	}
+
+	// The snapshot is saved before any event is removed, so a failure in
+	// between leaves a stream that still replays from its first event.
+	if err := snapshotter.SaveSnapshot(ctx, compactedSnapshotID(streamID), events[cut].Version, snapshot); err != nil {
+		return 0, fmt.Errorf("save compacted state: %w", err)
+	}
+	return compactor.Compact(ctx, streamID, events[cut].Version)
+}
+
+// compactor returns the event store when it can both remove events and keep
+// the snapshot they are folded into.
+func (s *Service) compactor() (eventstore.Compactor, eventstore.Snapshotter, bool) {
+	compactor, ok := s.store.(eventstore.Compactor)
+	if !ok {
+		return nil, nil, false
+	}
+	snapshotter, ok := s.store.(eventstore.Snapshotter)
+	return compactor, snapshotter, ok
+}
+
+// retentionCutoff returns the index of the newest event that may be folded
+// into a snapshot. Events are kept when they satisfy any part of the policy.
+func retentionCutoff(metaCodec PayloadCodec, policy RetentionPolicy, events []eventstore.Event, now time.Time) (int, error) {
+	keepFrom := len(events)
+
+	if policy.KeepVersions > 0 {
+		keepFrom = min(keepFrom, max(len(events)-policy.KeepVersions, 0))
+	}
+
+	if policy.MaxAge > 0 {
+		threshold := now.Add(-policy.MaxAge)
+		newest := len(events)
+		for i := len(events) - 1; i >= 0; i-- {
+			meta, err := decodeEventMetadata(metaCodec, events[i].Metadata)
+			if err != nil {
+				return -1, err
+			}
+			if meta.Timestamp.Before(threshold) {
+				break
+			}
+			newest = i
+		}
+		keepFrom = min(keepFrom, newest)
+	}
+
+	return keepFrom - 1, nil
+}
@@ -0,0 +1,219 @@
+package entities
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	entityPb "github.com/tonica-go/tonica/pkg/tonica/proto/entities"
+)
+
+// referenceLoader batches reference lookups for a page of records. Ids are
+// collected per target entity and field, then each batch is resolved with a
+// single IN query instead of one load per record.
+type referenceLoader struct {
+	svc     *Service
+	batches map[referenceKey]*referenceBatch
+	fields  map[string]referenceKey
+}
+
+type referenceKey struct {
+	entity string
+	field  string
+}
+
+type referenceBatch struct {
+	target  Definition
+	ids     stringSet
+	records map[string]Record
+}
+
+func newReferenceLoader(svc *Service) *referenceLoader {
+	return &referenceLoader{
+		svc:     svc,
+		batches: make(map[referenceKey]*referenceBatch),
+		fields:  make(map[string]referenceKey),
+	}
+}
+
+// add queues the values of a reference field across records.
+func (l *referenceLoader) add(field FieldDefinition, records []Record) error {
+	target, err := l.svc.Definition(field.Reference.Entity)
+	if err != nil {
+		return err
+	}
+
+	key := referenceKey{entity: target.ID, field: referenceTargetField(field.Reference, target)}
+	batch, ok := l.batches[key]
+	if !ok {
+		batch = &referenceBatch{target: target, ids: make(stringSet)}
+		l.batches[key] = batch
+	}
+	l.fields[field.ID] = key
+	for id := range extractFieldValues(records, field.ID, "") {
+		batch.ids.add(id)
+	}
+	return nil
+}
+
+// load resolves every queued batch.
+func (l *referenceLoader) load(ctx context.Context) error {
+	for key, batch := range l.batches {
+		if len(batch.ids) == 0 {
+			continue
+		}
+		ids := batch.ids.toSlice()
+		sort.Strings(ids)
+
+		records, err := l.svc.collectAllRecords(ctx, key.entity, []Filter{{
+			FieldID:  key.field,
+			Operator: entityPb.FilterOperator_FILTER_OPERATOR_IN,
+			Value:    ids,
+		}})
+		if err != nil {
+			return err
+		}
+
+		// Keys are taken before the read transforms of the referenced entity
+		// can mask the field they match on.
+		values := make([]string, len(records))
+		for i, record := range records {
+			values[i] = canonicalID(record.ID)
+			if !strings.EqualFold(key.field, batch.target.PrimaryKey) {
+				values[i] = canonicalID(record.Data[key.field])
+			}
+		}
+		if err := l.svc.transformRecords(ctx, key.entity, records); err != nil {
+			return err
+		}
+
+		batch.records = make(map[string]Record, len(records))
+		for i, record := range records {
+			if values[i] != "" {
+				batch.records[values[i]] = record
+			}
+		}
+	}
+	return nil
+}
+
+// label returns the label of the record referenced by value.
+func (l *referenceLoader) label(field FieldDefinition, value any) (string, bool) {
+	key, ok := l.fields[field.ID]
+	if !ok {
+		return "", false
+	}
+	batch := l.batches[key]
+	record, ok := batch.records[canonicalID(value)]
+	if !ok {
+		return "", false
+	}
+	label := asString(record.Data[field.Reference.Label])
+	return label, label != ""
+}
+
+// resolveReferenceLabels attaches the labels of referenced records to each
+// record, keyed by reference field id. Only references that declare a label
+// field are resolved.
+func (s *Service) resolveReferenceLabels(ctx context.Context, def Definition, records []Record) error {
+	var fields []FieldDefinition
+	for _, field := range def.Fields {
+		if field.Reference != nil && field.Reference.Label != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	labels, err := s.loadReferenceLabels(ctx, records, fields)
+	if err != nil {
+		return err
+	}
+	for i := range records {
+		if len(labels[i]) > 0 {
+			records[i].Labels = labels[i]
+		}
+	}
+	return nil
+}
+
+// loadReferenceLabels resolves the labels of the given reference fields for
+// every record. The result is indexed like records and keyed by field id.
+func (s *Service) loadReferenceLabels(ctx context.Context, records []Record, fields []FieldDefinition) ([]map[string]string, error) {
+	if len(records) == 0 || len(fields) == 0 {
+		return nil, nil
+	}
+
+	loader := newReferenceLoader(s)
+	for _, field := range fields {
+		if err := loader.add(field, records); err != nil {
+			return nil, err
+		}
+	}
+	if err := loader.load(ctx); err != nil {
+		return nil, err
+	}
+
+	labels := make([]map[string]string, len(records))
+	for i, record := range records {
+		for _, field := range fields {
+			label, ok := loader.label(field, record.Data[field.ID])
+			if !ok {
+				continue
+			}
+			if labels[i] == nil {
+				labels[i] = make(map[string]string, len(fields))
+			}
+			labels[i][field.ID] = label
+		}
+	}
+	return labels, nil
+}
+
+// resolveExpand validates that every expand target is a reference field that
+// declares a label. It returns nil when nothing was requested.
+func resolveExpand(def Definition, expand []string) ([]FieldDefinition, error) {
+	var fields []FieldDefinition
+	seen := make(map[string]struct{}, len(expand))
+	for _, fieldID := range expand {
+		fieldID = strings.TrimSpace(fieldID)
+		if fieldID == "" {
+			continue
+		}
+		if _, ok := seen[fieldID]; ok {
+			continue
+		}
+		seen[fieldID] = struct{}{}
+
+		field, ok := def.Field(fieldID)
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("%w: unknown field %s", ErrInvalidExpand, fieldID)
+		case field.Reference == nil:
+			return nil, fmt.Errorf("%w: %s is not a reference", ErrInvalidExpand, fieldID)
+		case field.Reference.Label == "":
+			return nil, fmt.Errorf("%w: reference %s declares no label", ErrInvalidExpand, fieldID)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// attachExpandedLabels inlines resolved labels into record data as
+// <field>_label.
+func attachExpandedLabels(records []Record, labels []map[string]string) {
+	for i := range labels {
+		for fieldID, label := range labels[i] {
+			if records[i].Data == nil {
+				records[i].Data = make(map[string]any)
+			}
+			records[i].Data[fieldID+"_label"] = label
+		}
+	}
+}
+
+func referenceTargetField(ref *ReferenceDefinition, target Definition) string {
+	if field := strings.TrimSpace(ref.Field); field != "" {
+		return field
+	}
+	return target.PrimaryKey
+}
@@ -4,8 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/tonica-go/tonica/pkg/tonica/proto/entities"
 
@@ -21,6 +26,119 @@ type Definition struct {
 	Proto       string
 	Fields      []FieldDefinition
 	Metadata    map[string]string
+	Retention   RetentionPolicy
+	Audit       AuditFields
+	// IDFormat restricts client-supplied primary keys. Nil accepts any id.
+	IDFormat *IDFormat
+	// Pagination limits how deep offset page tokens may reach.
+	Pagination PaginationPolicy
+}
+
+// Default audit field names, used unless the definition overrides them.
+const (
+	DefaultCreatedByField = "createdBy"
+	DefaultUpdatedByField = "updatedBy"
+)
+
+// AuditFields names the payload fields that hold the creating and last
+// updating actor. They are set by the service and never taken from payloads.
+type AuditFields struct {
+	CreatedBy string
+	UpdatedBy string
+}
+
+// Built-in id formats accepted by the id_format definition key. Any other
+// value is used as a regular expression matched against the whole id.
+const (
+	IDFormatUUID = "uuid"
+	IDFormatSlug = "slug"
+)
+
+var builtinIDFormats = map[string]string{
+	IDFormatUUID: `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+	IDFormatSlug: `[a-z0-9]+(?:-[a-z0-9]+)*`,
+}
+
+// IDFormat validates client-supplied record ids before they become part of
+// a stream id.
+type IDFormat struct {
+	// Name is the built-in format name or the configured expression.
+	Name    string
+	pattern *regexp.Regexp
+}
+
+// Validate returns ErrInvalidID when id does not match the format.
+func (f *IDFormat) Validate(id string) error {
+	if f == nil || f.pattern.MatchString(id) {
+		return nil
+	}
+	return fmt.Errorf("%w: %q does not match format %s", ErrInvalidID, id, f.Name)
+}
+
+func buildIDFormat(raw string) (*IDFormat, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	expr, ok := builtinIDFormats[strings.ToLower(raw)]
+	if !ok {
+		expr = raw
+	}
+	pattern, err := regexp.Compile(`^(?:` + expr + `)$`)
+	if err != nil {
+		return nil, err
+	}
+	return &IDFormat{Name: raw, pattern: pattern}, nil
+}
+
+// PaginationPolicy guards against deep offset pagination, where every page
+// loads and skips all the records before it. Keyset page tokens are not
+// limited.
+type PaginationPolicy struct {
+	// MaxOffset is the deepest offset served without action. Zero disables
+	// the check.
+	MaxOffset int
+	// Reject fails lists beyond MaxOffset with ErrDeepPagination. Otherwise
+	// they are served and logged.
+	Reject bool
+}
+
+// Deep offset actions accepted by the pagination.on_deep_offset key.
+const (
+	DeepOffsetWarn   = "warn"
+	DeepOffsetReject = "reject"
+)
+
+func buildPaginationPolicy(raw *rawPaginationDefinition) (PaginationPolicy, error) {
+	if raw == nil {
+		return PaginationPolicy{}, nil
+	}
+	if raw.MaxOffset < 0 {
+		return PaginationPolicy{}, fmt.Errorf("max_offset must not be negative")
+	}
+	policy := PaginationPolicy{MaxOffset: raw.MaxOffset}
+	switch strings.ToLower(strings.TrimSpace(raw.OnDeepOffset)) {
+	case "", DeepOffsetWarn:
+	case DeepOffsetReject:
+		policy.Reject = true
+	default:
+		return PaginationPolicy{}, fmt.Errorf("on_deep_offset must be %s or %s", DeepOffsetWarn, DeepOffsetReject)
+	}
+	return policy, nil
+}
+
+// RetentionPolicy controls how much record history is kept in the event store.
+// Events outside the policy are folded into a snapshot by compaction.
+type RetentionPolicy struct {
+	// KeepVersions keeps at least the last N events of every record.
+	KeepVersions int
+	// MaxAge keeps at least the events newer than the given duration.
+	MaxAge time.Duration
+}
+
+// Enabled reports whether the policy allows compaction.
+func (r RetentionPolicy) Enabled() bool {
+	return r.KeepVersions > 0 || r.MaxAge > 0
 }
 
 // Field returns a field definition by id.
@@ -43,7 +161,123 @@ type FieldDefinition struct {
 	Sortable        bool
 	FilterOperators []entities.FilterOperator
 	Reference       *ReferenceDefinition
-	Metadata        map[string]string
+	Default         *FieldDefault
+	// Validation restricts accepted values. Nil accepts any value of the
+	// field type.
+	Validation *FieldValidation
+	Metadata   map[string]string
+}
+
+// defaultNow is the default expression resolving to the creation time.
+const defaultNow = "now()"
+
+// FieldDefault is applied on create when the payload omits the field.
+type FieldDefault struct {
+	// Value is the literal default, already coerced to the field type.
+	Value any
+	// Now sets datetime fields to the creation time.
+	Now bool
+}
+
+func (d FieldDefault) value() any {
+	if d.Now {
+		return time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	return d.Value
+}
+
+// FieldValidation restricts the values accepted for a field on create and
+// update. Rules apply to every item of repeated fields.
+type FieldValidation struct {
+	// Pattern must match the whole string value.
+	Pattern *regexp.Regexp
+	// Min and Max bound numeric values, both inclusive.
+	Min *float64
+	Max *float64
+	// MaxLength limits string values to this many characters. Zero means no
+	// limit.
+	MaxLength int
+	// Enum lists the accepted string values.
+	Enum []string
+}
+
+// Validate checks a coerced field value against the rules and describes the
+// first violation. Nil values are not checked.
+func (v *FieldValidation) Validate(value any) error {
+	if v == nil || value == nil {
+		return nil
+	}
+	if items, ok := value.([]any); ok {
+		for _, item := range items {
+			if err := v.Validate(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if number, ok := toFloat64(value); ok && (v.Min != nil || v.Max != nil) {
+		if v.Min != nil && number < *v.Min {
+			return fmt.Errorf("must be at least %v", *v.Min)
+		}
+		if v.Max != nil && number > *v.Max {
+			return fmt.Errorf("must be at most %v", *v.Max)
+		}
+		return nil
+	}
+	str, ok := value.(string)
+	if !ok {
+		return nil
+	}
+	if v.MaxLength > 0 && utf8.RuneCountInString(str) > v.MaxLength {
+		return fmt.Errorf("must be at most %d characters", v.MaxLength)
+	}
+	if v.Pattern != nil && !v.Pattern.MatchString(str) {
+		return fmt.Errorf("does not match the required pattern")
+	}
+	if len(v.Enum) > 0 && !slices.Contains(v.Enum, str) {
+		return fmt.Errorf("must be one of %s", strings.Join(v.Enum, ", "))
+	}
+	return nil
+}
+
+// buildFieldValidation checks that the declared rules fit the field type:
+// min and max need a number field, the other rules a string-valued one.
+func buildFieldValidation(fieldType entities.FieldType, raw *rawValidationDefinition) (*FieldValidation, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	stringValued := fieldType == entities.FieldType_FIELD_TYPE_STRING ||
+		fieldType == entities.FieldType_FIELD_TYPE_ENUM ||
+		fieldType == entities.FieldType_FIELD_TYPE_UUID
+	validation := &FieldValidation{Min: raw.Min, Max: raw.Max, MaxLength: raw.MaxLength}
+
+	if raw.Min != nil || raw.Max != nil {
+		if fieldType != entities.FieldType_FIELD_TYPE_NUMBER {
+			return nil, fmt.Errorf("min and max are only supported for number fields")
+		}
+		if raw.Min != nil && raw.Max != nil && *raw.Min > *raw.Max {
+			return nil, fmt.Errorf("min %v is greater than max %v", *raw.Min, *raw.Max)
+		}
+	}
+	if raw.MaxLength < 0 {
+		return nil, fmt.Errorf("max_length must not be negative")
+	}
+	if (raw.MaxLength > 0 || raw.Pattern != "" || len(raw.Enum) > 0) && !stringValued {
+		return nil, fmt.Errorf("pattern, max_length and enum are only supported for string, enum and uuid fields")
+	}
+	if pattern := strings.TrimSpace(raw.Pattern); pattern != "" {
+		compiled, err := regexp.Compile(`^(?:` + pattern + `)$`)
+		if err != nil {
+			return nil, fmt.Errorf("pattern: %w", err)
+		}
+		validation.Pattern = compiled
+	}
+	for _, value := range raw.Enum {
+		if value = strings.TrimSpace(value); value != "" && !slices.Contains(validation.Enum, value) {
+			validation.Enum = append(validation.Enum, value)
+		}
+	}
+	return validation, nil
 }
 
 // ReferenceDefinition describes a relationship to another entity.
@@ -154,6 +388,20 @@ func parseDefinition(data []byte) (Definition, error) {
 
 	metadata := cloneStringMap(raw.Metadata)
 
+	retention, err := buildRetentionPolicy(raw.Retention)
+	if err != nil {
+		return Definition{}, fmt.Errorf("retention: %w", err)
+	}
+	audit := buildAuditFields(raw.Audit, fields)
+	idFormat, err := buildIDFormat(raw.IDFormat)
+	if err != nil {
+		return Definition{}, fmt.Errorf("id_format: %w", err)
+	}
+	pagination, err := buildPaginationPolicy(raw.Pagination)
+	if err != nil {
+		return Definition{}, fmt.Errorf("pagination: %w", err)
+	}
+
 	return Definition{
 		ID:          id,
 		DisplayName: fallback(strings.TrimSpace(raw.DisplayName), humanizeIdentifier(id)),
@@ -162,9 +410,118 @@ func parseDefinition(data []byte) (Definition, error) {
 		Proto:       strings.TrimSpace(raw.Proto),
 		Fields:      fields,
 		Metadata:    metadata,
+		Retention:   retention,
+		Audit:       audit,
+		IDFormat:    idFormat,
+		Pagination:  pagination,
+	}, nil
+}
+
+// buildAuditFields resolves the audit field names. Names missing from the
+// audit section fall back to a declared snake_case field (created_by,
+// updated_by) and then to the camelCase defaults.
+func buildAuditFields(raw *rawAuditDefinition, fields []FieldDefinition) AuditFields {
+	var audit AuditFields
+	if raw != nil {
+		audit.CreatedBy = strings.TrimSpace(raw.CreatedBy)
+		audit.UpdatedBy = strings.TrimSpace(raw.UpdatedBy)
+	}
+	declared := func(id string) bool {
+		for _, field := range fields {
+			if field.ID == id {
+				return true
+			}
+		}
+		return false
+	}
+	if audit.CreatedBy == "" {
+		audit.CreatedBy = DefaultCreatedByField
+		if declared("created_by") {
+			audit.CreatedBy = "created_by"
+		}
+	}
+	if audit.UpdatedBy == "" {
+		audit.UpdatedBy = DefaultUpdatedByField
+		if declared("updated_by") {
+			audit.UpdatedBy = "updated_by"
+		}
+	}
+	return audit
+}
+
+// auditFields returns the audit field names, with defaults for definitions
+// built in code.
+func (d Definition) auditFields() AuditFields {
+	audit := d.Audit
+	if audit.CreatedBy == "" {
+		audit.CreatedBy = DefaultCreatedByField
+	}
+	if audit.UpdatedBy == "" {
+		audit.UpdatedBy = DefaultUpdatedByField
+	}
+	return audit
+}
+
+// isAuditField reports whether fieldID is an audit field of the definition.
+// Both camelCase and snake_case spellings of createdBy/updatedBy are always
+// treated as audit fields so clients cannot set them under either name.
+func (d Definition) isAuditField(fieldID string) bool {
+	fieldID = strings.TrimSpace(fieldID)
+	audit := d.auditFields()
+	if strings.EqualFold(fieldID, audit.CreatedBy) || strings.EqualFold(fieldID, audit.UpdatedBy) {
+		return true
+	}
+	switch strings.ToLower(strings.ReplaceAll(fieldID, "_", "")) {
+	case "createdby", "updatedby":
+		return true
+	default:
+		return false
+	}
+}
+
+func buildRetentionPolicy(raw *rawRetentionDefinition) (RetentionPolicy, error) {
+	if raw == nil {
+		return RetentionPolicy{}, nil
+	}
+	if raw.KeepVersions < 0 {
+		return RetentionPolicy{}, fmt.Errorf("keep_versions must not be negative")
+	}
+	maxAge, err := parseRetentionAge(raw.MaxAge)
+	if err != nil {
+		return RetentionPolicy{}, fmt.Errorf("max_age: %w", err)
+	}
+	return RetentionPolicy{
+		KeepVersions: raw.KeepVersions,
+		MaxAge:       maxAge,
 	}, nil
 }
 
+// parseRetentionAge accepts Go durations ("720h") and whole days ("30d").
+func parseRetentionAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	var age time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		age = time.Duration(n) * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		age = parsed
+	}
+	if age < 0 {
+		return 0, fmt.Errorf("duration must not be negative")
+	}
+	return age, nil
+}
+
 func buildFieldDefinition(raw rawFieldDefinition) (FieldDefinition, error) {
 	if strings.TrimSpace(raw.ID) == "" {
 		return FieldDefinition{}, fmt.Errorf("id is required")
@@ -186,6 +543,16 @@ func buildFieldDefinition(raw rawFieldDefinition) (FieldDefinition, error) {
 		filterOps = append(filterOps, parsed)
 	}
 
+	fieldDefault, err := buildFieldDefault(fieldType, raw.Default)
+	if err != nil {
+		return FieldDefinition{}, fmt.Errorf("default: %w", err)
+	}
+
+	validation, err := buildFieldValidation(fieldType, raw.Validation)
+	if err != nil {
+		return FieldDefinition{}, fmt.Errorf("validation: %w", err)
+	}
+
 	reference, err := buildReferenceDefinition(raw.Reference)
 	if err != nil {
 		return FieldDefinition{}, fmt.Errorf("reference: %w", err)
@@ -217,10 +584,31 @@ func buildFieldDefinition(raw rawFieldDefinition) (FieldDefinition, error) {
 		Sortable:        raw.Sortable,
 		FilterOperators: filterOps,
 		Reference:       reference,
+		Default:         fieldDefault,
+		Validation:      validation,
 		Metadata:        metadata,
 	}, nil
 }
 
+// buildFieldDefault type-checks a declared default. now() is only valid for
+// datetime fields.
+func buildFieldDefault(fieldType entities.FieldType, raw any) (*FieldDefault, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	if expr, ok := raw.(string); ok && strings.EqualFold(strings.TrimSpace(expr), defaultNow) {
+		if fieldType != entities.FieldType_FIELD_TYPE_DATETIME {
+			return nil, fmt.Errorf("%s is only supported for datetime fields", defaultNow)
+		}
+		return &FieldDefault{Now: true}, nil
+	}
+	value, err := coerceValue(fieldType, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &FieldDefault{Value: value}, nil
+}
+
 func fallback(value, defaultValue string) string {
 	if strings.TrimSpace(value) != "" {
 		return value
@@ -289,6 +677,12 @@ func parseFilterOperator(value string) (entities.FilterOperator, bool) {
 		return entities.FilterOperator_FILTER_OPERATOR_LT, true
 	case "lte", "<=":
 		return entities.FilterOperator_FILTER_OPERATOR_LTE, true
+	case "between", "..":
+		return entities.FilterOperator_FILTER_OPERATOR_BETWEEN, true
+	case "is_null", "null":
+		return entities.FilterOperator_FILTER_OPERATOR_IS_NULL, true
+	case "is_not_null", "not_null":
+		return entities.FilterOperator_FILTER_OPERATOR_IS_NOT_NULL, true
 	case "":
 		return entities.FilterOperator_FILTER_OPERATOR_UNSPECIFIED, true
 	default:
@@ -297,25 +691,54 @@ func parseFilterOperator(value string) (entities.FilterOperator, bool) {
 }
 
 type rawDefinition struct {
-	ID          string               `yaml:"id"`
-	DisplayName string               `yaml:"display_name"`
-	Description string               `yaml:"description"`
-	PrimaryKey  string               `yaml:"primary_key"`
-	Proto       string               `yaml:"proto"`
-	Fields      []rawFieldDefinition `yaml:"fields"`
-	Metadata    map[string]string    `yaml:"metadata"`
+	ID          string                   `yaml:"id"`
+	DisplayName string                   `yaml:"display_name"`
+	Description string                   `yaml:"description"`
+	PrimaryKey  string                   `yaml:"primary_key"`
+	Proto       string                   `yaml:"proto"`
+	Fields      []rawFieldDefinition     `yaml:"fields"`
+	Metadata    map[string]string        `yaml:"metadata"`
+	Retention   *rawRetentionDefinition  `yaml:"retention"`
+	Audit       *rawAuditDefinition      `yaml:"audit"`
+	IDFormat    string                   `yaml:"id_format"`
+	Pagination  *rawPaginationDefinition `yaml:"pagination"`
+}
+
+type rawPaginationDefinition struct {
+	MaxOffset    int    `yaml:"max_offset"`
+	OnDeepOffset string `yaml:"on_deep_offset"`
+}
+
+type rawAuditDefinition struct {
+	CreatedBy string `yaml:"created_by"`
+	UpdatedBy string `yaml:"updated_by"`
+}
+
+type rawRetentionDefinition struct {
+	KeepVersions int    `yaml:"keep_versions"`
+	MaxAge       string `yaml:"max_age"`
 }
 
 type rawFieldDefinition struct {
-	ID          string                  `yaml:"id"`
-	DisplayName string                  `yaml:"display_name"`
-	Type        string                  `yaml:"type"`
-	Required    bool                    `yaml:"required"`
-	Repeated    bool                    `yaml:"repeated"`
-	Sortable    bool                    `yaml:"sortable"`
-	Filter      rawFilterDefinition     `yaml:"filter"`
-	Reference   *rawReferenceDefinition `yaml:"reference"`
-	Metadata    map[string]string       `yaml:"metadata"`
+	ID          string                   `yaml:"id"`
+	DisplayName string                   `yaml:"display_name"`
+	Type        string                   `yaml:"type"`
+	Required    bool                     `yaml:"required"`
+	Repeated    bool                     `yaml:"repeated"`
+	Sortable    bool                     `yaml:"sortable"`
+	Filter      rawFilterDefinition      `yaml:"filter"`
+	Reference   *rawReferenceDefinition  `yaml:"reference"`
+	Default     any                      `yaml:"default"`
+	Validation  *rawValidationDefinition `yaml:"validation"`
+	Metadata    map[string]string        `yaml:"metadata"`
+}
+
+type rawValidationDefinition struct {
+	Pattern   string   `yaml:"pattern"`
+	Min       *float64 `yaml:"min"`
+	Max       *float64 `yaml:"max"`
+	MaxLength int      `yaml:"max_length"`
+	Enum      []string `yaml:"enum"`
 }
 
 type rawFilterDefinition struct {
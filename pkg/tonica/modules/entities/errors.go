@@ -11,11 +11,20 @@ var (
 	ErrUnknownEntity   = errors.New("unknown entity")
 	ErrRecordNotFound  = errors.New("record not found")
 	ErrRecordDeleted   = errors.New("record deleted")
+	ErrNotDeleted      = errors.New("record not deleted")
 	ErrInvalidFilter   = errors.New("invalid filter")
 	ErrInvalidSort     = errors.New("invalid sort")
+	ErrInvalidFields   = errors.New("invalid fields")
+	ErrInvalidExpand   = errors.New("invalid expand")
 	ErrInvalidPayload  = errors.New("invalid payload")
 	ErrValidation      = errors.New("validation failed")
 	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrNoRetention     = errors.New("no retention policy")
+	ErrInvalidID       = errors.New("invalid record id")
+	ErrDeepPagination  = errors.New("page offset too deep")
+	// ErrStalePageToken is returned for a page token written for a list
+	// whose sort, count or mode has changed since.
+	ErrStalePageToken = errors.New("page token does not apply to this list")
 )
 
 // ValidationErrors aggregates field-level validation failures.
@@ -1,12 +1,16 @@
 package entities
 
 import (
+	"context"
 	"crypto/sha1"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"time"
 
 	"github.com/google/uuid"
+	obs "github.com/tonica-go/tonica/pkg/tonica/observabillity"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -14,6 +18,8 @@ const (
 	eventTypeRecordUpdated = "entity.record.updated"
 	eventTypeRecordDeleted = "entity.record.deleted"
 	eventTypeRecordIndexed = "entity.record.indexed"
+	// eventTypeRecordRestored undoes a preceding deletion.
+	eventTypeRecordRestored = "entity.record.restored"
 )
 
 func legacyRecordStreamID(entityID, recordID string) string {
@@ -24,17 +30,76 @@ func legacyIndexStreamID(entityID string) string {
 	return fmt.Sprintf("entity:%s:index", entityID)
 }
 
-type eventMetadata struct {
+// EventMetadata is the audit metadata stored with every entity event.
+type EventMetadata struct {
 	Entity    string    `json:"entity"`
 	RecordID  string    `json:"record_id,omitempty"`
 	ActorID   string    `json:"actor_id,omitempty"`
 	Timestamp time.Time `json:"timestamp"`
+	// TraceID is the trace the write was made in, if any.
+	TraceID string `json:"trace_id,omitempty"`
+	// RequestID is the request id forwarded by the gateway, if any.
+	RequestID string `json:"request_id,omitempty"`
+	// Annotations carries caller supplied key/values, see WithEventAnnotations.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Annotation returns the annotation stored under key, or "".
+func (m EventMetadata) Annotation(key string) string {
+	return m.Annotations[key]
+}
+
+type eventAnnotationsKey struct{}
+
+// WithEventAnnotations returns a context whose entity writes record
+// annotations in their event metadata. Annotations already on ctx are kept
+// unless overridden.
+func WithEventAnnotations(ctx context.Context, annotations map[string]string) context.Context {
+	merged := maps.Clone(eventAnnotations(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(annotations))
+	}
+	maps.Copy(merged, annotations)
+	return context.WithValue(ctx, eventAnnotationsKey{}, merged)
+}
+
+func eventAnnotations(ctx context.Context) map[string]string {
+	annotations, _ := ctx.Value(eventAnnotationsKey{}).(map[string]string)
+	return annotations
+}
+
+// newEventMetadata describes a write made in ctx, picking up its trace,
+// request id and annotations.
+func newEventMetadata(ctx context.Context, entityID, recordID, actorID string, ts time.Time) EventMetadata {
+	meta := EventMetadata{
+		Entity:      entityID,
+		RecordID:    recordID,
+		ActorID:     actorID,
+		Timestamp:   ts,
+		RequestID:   obs.RequestIDFromContext(ctx),
+		Annotations: maps.Clone(eventAnnotations(ctx)),
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		meta.TraceID = sc.TraceID().String()
+	}
+	return meta
 }
 
 type recordPayload struct {
 	Data map[string]any `json:"data"`
 }
 
+// snapshotPayload carries the full record state, including creation details
+// that would otherwise be lost together with the compacted created event.
+type snapshotPayload struct {
+	Data      map[string]any `json:"data"`
+	CreatedAt time.Time      `json:"created_at"`
+	CreatedBy string         `json:"created_by,omitempty"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	UpdatedBy string         `json:"updated_by,omitempty"`
+	Deleted   bool           `json:"deleted,omitempty"`
+}
+
 type indexPayload struct {
 	RecordID string `json:"record_id"`
 	Deleted  bool   `json:"deleted"`
@@ -59,13 +124,13 @@ func aggregateType(entityID string) string {
 	return fmt.Sprintf("entity:%s", entityID)
 }
 
-func decodeEventMetadata(data []byte) (eventMetadata, error) {
+func decodeEventMetadata(codec PayloadCodec, data []byte) (EventMetadata, error) {
 	if len(data) == 0 {
-		return eventMetadata{}, fmt.Errorf("missing metadata")
+		return EventMetadata{}, fmt.Errorf("missing metadata")
 	}
-	var meta eventMetadata
-	if err := json.Unmarshal(data, &meta); err != nil {
-		return eventMetadata{}, fmt.Errorf("decode metadata: %w", err)
+	var meta EventMetadata
+	if err := codec.Unmarshal(data, &meta); err != nil {
+		return EventMetadata{}, fmt.Errorf("decode metadata: %w", err)
 	}
 	if meta.Timestamp.IsZero() {
 		meta.Timestamp = time.Now().UTC()
@@ -73,12 +138,12 @@ func decodeEventMetadata(data []byte) (eventMetadata, error) {
 	return meta, nil
 }
 
-func decodeRecordPayload(data []byte) (recordPayload, error) {
+func decodeRecordPayload(codec PayloadCodec, data []byte) (recordPayload, error) {
 	if len(data) == 0 {
 		return recordPayload{}, nil
 	}
 	var payload recordPayload
-	if err := json.Unmarshal(data, &payload); err != nil {
+	if err := codec.Unmarshal(data, &payload); err != nil {
 		return recordPayload{}, fmt.Errorf("decode record payload: %w", err)
 	}
 	if payload.Data == nil {
@@ -87,6 +152,20 @@ func decodeRecordPayload(data []byte) (recordPayload, error) {
 	return payload, nil
 }
 
+func decodeSnapshotPayload(codec PayloadCodec, data []byte) (snapshotPayload, error) {
+	if len(data) == 0 {
+		return snapshotPayload{}, fmt.Errorf("missing snapshot payload")
+	}
+	var payload snapshotPayload
+	if err := codec.Unmarshal(data, &payload); err != nil {
+		return snapshotPayload{}, fmt.Errorf("decode snapshot payload: %w", err)
+	}
+	if payload.Data == nil {
+		payload.Data = make(map[string]any)
+	}
+	return payload, nil
+}
+
 func decodeIndexPayload(data []byte) (indexPayload, error) {
 	if len(data) == 0 {
 		return indexPayload{}, fmt.Errorf("missing index payload")
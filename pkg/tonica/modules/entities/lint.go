@@ -0,0 +1,147 @@
+package entities
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	entityPb "github.com/tonica-go/tonica/pkg/tonica/proto/entities"
+)
+
+// LintIssue is a problem found in an entity definition file.
+type LintIssue struct {
+	File    string
+	Entity  string
+	Field   string
+	Message string
+}
+
+func (i LintIssue) String() string {
+	location := i.File
+	switch {
+	case i.Entity != "" && i.Field != "":
+		location += ": " + i.Entity + "." + i.Field
+	case i.Entity != "":
+		location += ": " + i.Entity
+	}
+	return location + ": " + i.Message
+}
+
+// filterOperatorsByType lists the operators the filter engine can evaluate
+// for each field type. Comparison operators only compare numbers; between
+// also compares datetimes. Presence operators apply to every type.
+var filterOperatorsByType = map[entityPb.FieldType][]entityPb.FilterOperator{
+	entityPb.FieldType_FIELD_TYPE_STRING:   {entityPb.FilterOperator_FILTER_OPERATOR_EQ, entityPb.FilterOperator_FILTER_OPERATOR_NE, entityPb.FilterOperator_FILTER_OPERATOR_IN, entityPb.FilterOperator_FILTER_OPERATOR_CONTAINS},
+	entityPb.FieldType_FIELD_TYPE_UUID:     {entityPb.FilterOperator_FILTER_OPERATOR_EQ, entityPb.FilterOperator_FILTER_OPERATOR_NE, entityPb.FilterOperator_FILTER_OPERATOR_IN, entityPb.FilterOperator_FILTER_OPERATOR_CONTAINS},
+	entityPb.FieldType_FIELD_TYPE_ENUM:     {entityPb.FilterOperator_FILTER_OPERATOR_EQ, entityPb.FilterOperator_FILTER_OPERATOR_NE, entityPb.FilterOperator_FILTER_OPERATOR_IN, entityPb.FilterOperator_FILTER_OPERATOR_CONTAINS},
+	entityPb.FieldType_FIELD_TYPE_DATETIME: {entityPb.FilterOperator_FILTER_OPERATOR_EQ, entityPb.FilterOperator_FILTER_OPERATOR_NE, entityPb.FilterOperator_FILTER_OPERATOR_IN, entityPb.FilterOperator_FILTER_OPERATOR_BETWEEN},
+	entityPb.FieldType_FIELD_TYPE_BOOLEAN:  {entityPb.FilterOperator_FILTER_OPERATOR_EQ, entityPb.FilterOperator_FILTER_OPERATOR_NE},
+	entityPb.FieldType_FIELD_TYPE_NUMBER: {
+		entityPb.FilterOperator_FILTER_OPERATOR_EQ, entityPb.FilterOperator_FILTER_OPERATOR_NE, entityPb.FilterOperator_FILTER_OPERATOR_IN,
+		entityPb.FilterOperator_FILTER_OPERATOR_GT, entityPb.FilterOperator_FILTER_OPERATOR_GTE,
+		entityPb.FilterOperator_FILTER_OPERATOR_LT, entityPb.FilterOperator_FILTER_OPERATOR_LTE,
+		entityPb.FilterOperator_FILTER_OPERATOR_BETWEEN,
+	},
+	entityPb.FieldType_FIELD_TYPE_ARRAY:  {entityPb.FilterOperator_FILTER_OPERATOR_CONTAINS},
+	entityPb.FieldType_FIELD_TYPE_OBJECT: {},
+}
+
+// isPresenceOperator reports whether op only checks whether a field is set,
+// which every field type supports.
+func isPresenceOperator(op entityPb.FilterOperator) bool {
+	return op == entityPb.FilterOperator_FILTER_OPERATOR_IS_NULL || op == entityPb.FilterOperator_FILTER_OPERATOR_IS_NOT_NULL
+}
+
+// LintDefinitions loads every definition in dir and reports problems that
+// would otherwise only surface at request time: unparsable files, duplicate
+// ids, references to unknown entities or fields, filter operators the field
+// type cannot evaluate and sortable fields that cannot be ordered.
+func LintDefinitions(dir string) ([]LintIssue, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read definitions directory: %w", err)
+	}
+
+	var issues []LintIssue
+	defs := make(map[string]Definition, len(entries))
+	files := make(map[string]string, len(entries))
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+
+		def, err := parseDefinition(data)
+		if err != nil {
+			issues = append(issues, LintIssue{File: path, Message: err.Error()})
+			continue
+		}
+		if other, exists := files[def.ID]; exists {
+			issues = append(issues, LintIssue{File: path, Entity: def.ID, Message: fmt.Sprintf("duplicate entity id, also defined in %s", other)})
+			continue
+		}
+		defs[def.ID] = def
+		files[def.ID] = path
+	}
+
+	ids := make([]string, 0, len(defs))
+	for id := range defs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		def := defs[id]
+		for _, field := range def.Fields {
+			for _, msg := range lintField(defs, field) {
+				issues = append(issues, LintIssue{File: files[id], Entity: id, Field: field.ID, Message: msg})
+			}
+		}
+	}
+	return issues, nil
+}
+
+func lintField(defs map[string]Definition, field FieldDefinition) []string {
+	var problems []string
+
+	if allowed, ok := filterOperatorsByType[field.Type]; ok {
+		for _, op := range field.FilterOperators {
+			if !containsOperator(allowed, op) && !isPresenceOperator(op) {
+				problems = append(problems, fmt.Sprintf("filter operator %s is not supported for type %s", op, field.Type))
+			}
+		}
+	}
+
+	if field.Sortable && (field.Type == entityPb.FieldType_FIELD_TYPE_OBJECT || field.Type == entityPb.FieldType_FIELD_TYPE_ARRAY) {
+		problems = append(problems, fmt.Sprintf("type %s cannot be sortable", field.Type))
+	}
+
+	if ref := field.Reference; ref != nil {
+		target, ok := defs[ref.Entity]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("reference to unknown entity %q", ref.Entity))
+		case ref.Field != target.PrimaryKey:
+			if _, ok := target.Field(ref.Field); !ok {
+				problems = append(problems, fmt.Sprintf("reference field %q does not exist on %s", ref.Field, target.ID))
+			}
+		}
+	}
+	return problems
+}
+
+func containsOperator(ops []entityPb.FilterOperator, op entityPb.FilterOperator) bool {
+	for _, existing := range ops {
+		if existing == op {
+			return true
+		}
+	}
+	return false
+}
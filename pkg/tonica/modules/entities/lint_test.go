@@ -0,0 +1,71 @@
+package entities
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintDefinitions(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	write("customer.yaml", `
+id: customer
+primary_key: id
+fields:
+  - id: id
+    type: string
+  - id: name
+    type: string
+    filter:
+      operators: [eq, gt]
+`)
+	write("order.yaml", `
+id: order
+primary_key: id
+fields:
+  - id: id
+    type: string
+  - id: customer_id
+    type: string
+    reference:
+      entity: customer
+      field: id
+  - id: account_id
+    type: string
+    reference:
+      entity: account
+      field: id
+  - id: owner_id
+    type: string
+    reference:
+      entity: customer
+      field: email
+  - id: lines
+    type: array
+    sortable: true
+`)
+	write("broken.yaml", "id: broken\n")
+	write("notes.txt", "ignored")
+
+	issues, err := LintDefinitions(dir)
+	require.NoError(t, err)
+
+	messages := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		messages = append(messages, issue.String())
+	}
+	assert.ElementsMatch(t, []string{
+		filepath.Join(dir, "broken.yaml") + ": primary_key is required",
+		filepath.Join(dir, "customer.yaml") + ": customer.name: filter operator FILTER_OPERATOR_GT is not supported for type FIELD_TYPE_STRING",
+		filepath.Join(dir, "order.yaml") + `: order.account_id: reference to unknown entity "account"`,
+		filepath.Join(dir, "order.yaml") + `: order.owner_id: reference field "email" does not exist on customer`,
+		filepath.Join(dir, "order.yaml") + ": order.lines: type FIELD_TYPE_ARRAY cannot be sortable",
+	}, messages)
+}
@@ -0,0 +1,140 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	entityPb "github.com/tonica-go/tonica/pkg/tonica/proto/entities"
+)
+
+// keysetTokenPrefix marks page tokens that resume after a record id instead
+// of at an offset.
+const keysetTokenPrefix = "after:"
+
+// SetStreamingList makes ListRecords materialize event-sourced records lazily
+// and stop as soon as a page is filled. It only applies to lists sorted by
+// the primary key without a total count; other lists keep loading every
+// record. Pages are returned in record id order and their next page token
+// resumes after the last returned id, so deep pages cost no more than the
+// first one. Disabled by default.
+func (s *Service) SetStreamingList(enabled bool) {
+	s.streamingList = enabled
+}
+
+// canStreamList reports whether opts can be served by listRecordsStreaming.
+// The index is kept in record id order, so only primary key sorts avoid
+// materializing every record, and a total count needs all matches anyway.
+func (s *Service) canStreamList(def Definition, opts ListOptions) bool {
+	return s.streamingList && opts.SortField == def.PrimaryKey && !opts.IncludeTotalCount
+}
+
+// checkKeysetToken rejects keyset tokens for lists that cannot be streamed,
+// such as a list whose sort changed or that asks for a total count after
+// the first page, so clients know to start over.
+func (s *Service) checkKeysetToken(def Definition, opts ListOptions) error {
+	if !strings.HasPrefix(opts.PageToken, keysetTokenPrefix) || s.canStreamList(def, opts) {
+		return nil
+	}
+	if !s.streamingList {
+		return fmt.Errorf("%w: keyset tokens are only served by streaming lists; start again from the first page", ErrStalePageToken)
+	}
+	return fmt.Errorf("%w: keyset tokens only page lists sorted on %s without a total count; start again from the first page",
+		ErrStalePageToken, def.PrimaryKey)
+}
+
+// listRecordsStreaming returns a page of event-sourced records, evaluating
+// search and filters while records are loaded. Only the index is read in
+// full; records past the page are never loaded. The page token is either a
+// keyset token written by a previous page or a plain offset, which still
+// has to load and match every skipped record.
+func (s *Service) listRecordsStreaming(ctx context.Context, def Definition, filters []normalizedFilter, orGroups [][]normalizedFilter, opts ListOptions) ([]Record, string, error) {
+	indexEntries, err := s.loadIndex(ctx, def.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	if opts.SortDir == entityPb.SortDirection_SORT_DIRECTION_DESC {
+		for i, j := 0, len(indexEntries)-1; i < j; i, j = i+1, j-1 {
+			indexEntries[i], indexEntries[j] = indexEntries[j], indexEntries[i]
+		}
+	}
+
+	after, offset, err := parseStreamToken(opts.PageToken)
+	if err != nil {
+		return nil, "", err
+	}
+	start := 0
+	if after != "" {
+		start = keysetStart(indexEntries, after, opts.SortDir)
+	}
+
+	searchFields := searchableFieldIDs(def)
+	query := strings.ToLower(opts.Search)
+
+	page := make([]Record, 0, opts.PageSize)
+	scanned := 0
+	for i := start; i < len(indexEntries); i++ {
+		if len(page) == opts.PageSize {
+			// More entries remain, though they may all be filtered out, in
+			// which case the next page comes back empty.
+			recordListMetrics(ctx, def.ID, scanned, len(page))
+			return page, keysetTokenPrefix + page[len(page)-1].ID, nil
+		}
+
+		record, err := s.loadRecord(ctx, def, indexEntries[i].RecordID)
+		if errors.Is(err, ErrRecordNotFound) || errors.Is(err, ErrRecordDeleted) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		scanned++
+
+		if record.Deleted {
+			continue
+		}
+		if query != "" && len(searchFields) > 0 && !matchesSearchFields(record.Data, searchFields, query) {
+			continue
+		}
+		if !matchesFilters(record.Data, filters) || !matchesOrGroups(record.Data, orGroups) {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		page = append(page, record)
+	}
+
+	recordListMetrics(ctx, def.ID, scanned, len(page))
+	return page, "", nil
+}
+
+// parseStreamToken splits a streaming page token into the record id to
+// resume after or, for plain tokens, the offset to skip.
+func parseStreamToken(token string) (string, int, error) {
+	if after, ok := strings.CutPrefix(token, keysetTokenPrefix); ok {
+		if after == "" {
+			return "", 0, fmt.Errorf("invalid page token")
+		}
+		return after, 0, nil
+	}
+	offset, err := parsePageToken(token)
+	return "", offset, err
+}
+
+// keysetStart returns the position of the first index entry after the given
+// record id, in the compareText order of the index. The id need not exist
+// anymore.
+func keysetStart(entries []indexState, after string, direction entityPb.SortDirection) int {
+	if direction == entityPb.SortDirection_SORT_DIRECTION_DESC {
+		return sort.Search(len(entries), func(i int) bool {
+			return compareText(entries[i].RecordID, after) < 0
+		})
+	}
+	return sort.Search(len(entries), func(i int) bool {
+		return compareText(entries[i].RecordID, after) > 0
+	})
+}
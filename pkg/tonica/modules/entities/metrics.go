@@ -0,0 +1,63 @@
+package entities
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	listMetricsOnce     sync.Once
+	listScannedRecords  metric.Int64Histogram
+	listReturnedRecords metric.Int64Histogram
+	listDeepOffsets     metric.Int64Counter
+)
+
+// listSizeBuckets covers single pages up to full scans of large entities.
+var listSizeBuckets = []float64{0, 1, 10, 50, 100, 200, 500, 1000, 5000, 10000, 50000}
+
+// initListInstruments registers the list histograms on first use so the
+// global meter provider configured at startup is picked up.
+func initListInstruments() {
+	meter := otel.Meter("tonica/entities")
+	listScannedRecords, _ = meter.Int64Histogram(
+		"entity_list_scanned_records",
+		metric.WithDescription("Number of index entries scanned by a list call"),
+		metric.WithExplicitBucketBoundaries(listSizeBuckets...),
+	)
+	listReturnedRecords, _ = meter.Int64Histogram(
+		"entity_list_returned_records",
+		metric.WithDescription("Number of records returned by a list call"),
+		metric.WithExplicitBucketBoundaries(listSizeBuckets...),
+	)
+	listDeepOffsets, _ = meter.Int64Counter(
+		"entity_list_deep_offsets",
+		metric.WithDescription("Number of list calls whose offset exceeded the entity pagination limit"),
+	)
+}
+
+func recordListMetrics(ctx context.Context, entityID string, scanned, returned int) {
+	listMetricsOnce.Do(initListInstruments)
+	attrs := metric.WithAttributes(attribute.String("entity", entityID))
+	if listScannedRecords != nil {
+		listScannedRecords.Record(ctx, int64(scanned), attrs)
+	}
+	if listReturnedRecords != nil {
+		listReturnedRecords.Record(ctx, int64(returned), attrs)
+	}
+}
+
+// recordDeepOffset counts a list beyond the pagination limit of an entity.
+// action is the configured reaction, warn or reject.
+func recordDeepOffset(ctx context.Context, entityID, action string) {
+	listMetricsOnce.Do(initListInstruments)
+	if listDeepOffsets != nil {
+		listDeepOffsets.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("entity", entityID),
+			attribute.String("action", action),
+		))
+	}
+}
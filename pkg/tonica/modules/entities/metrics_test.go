@@ -0,0 +1,72 @@
+package entities
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	entityPb "github.com/tonica-go/tonica/pkg/tonica/proto/entities"
+)
+
+func TestListMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	previous := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	// Instruments are created once; recreate them on the provider above.
+	listMetricsOnce = sync.Once{}
+	t.Cleanup(func() {
+		otel.SetMeterProvider(previous)
+		listMetricsOnce = sync.Once{}
+	})
+
+	svc := newTestService(t)
+	ctx := testContext()
+	for i := 1; i <= 6; i++ {
+		_, err := svc.CreateRecord(ctx, "account", map[string]any{
+			"id":          fmt.Sprintf("acc-%d", i),
+			"external_id": json.Number(strconv.Itoa(i)),
+		})
+		require.NoError(t, err)
+	}
+
+	result, err := svc.ListRecordsPage(ctx, "account", ListOptions{Filters: []Filter{{
+		FieldID:  "external_id",
+		Operator: entityPb.FilterOperator_FILTER_OPERATOR_GT,
+		Value:    "4",
+	}}})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 2)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	sums := make(map[string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			hist, ok := m.Data.(metricdata.Histogram[int64])
+			if !ok {
+				continue
+			}
+			for _, dp := range hist.DataPoints {
+				entity, _ := dp.Attributes.Value(attribute.Key("entity"))
+				assert.Equal(t, "account", entity.AsString(), m.Name)
+				assert.EqualValues(t, 1, dp.Count, m.Name)
+				sums[m.Name] += dp.Sum
+			}
+		}
+	}
+	assert.Equal(t, map[string]int64{
+		"entity_list_scanned_records":  6,
+		"entity_list_returned_records": 2,
+	}, sums)
+}
@@ -1,12 +1,68 @@
 package entities
 
 import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
 	pb "github.com/tonica-go/tonica/pkg/tonica/proto/entities"
 
 	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// filterValueFromProto converts a filter value using the declared type of the
+// filtered field. structpb's AsInterface turns every number into float64, so
+// ids, integers and timestamps are typed here instead: integral numbers become
+// int64, numbers sent to string fields keep their integer spelling, numbers
+// sent to datetime fields are read as Unix seconds, and numeric strings sent
+// to number fields are parsed exactly. Lists (IN filters) convert per element.
+func filterValueFromProto(fieldType pb.FieldType, value *structpb.Value) any {
+	switch kind := value.GetKind().(type) {
+	case nil, *structpb.Value_NullValue:
+		return nil
+	case *structpb.Value_ListValue:
+		items := make([]any, 0, len(kind.ListValue.GetValues()))
+		for _, item := range kind.ListValue.GetValues() {
+			items = append(items, filterValueFromProto(fieldType, item))
+		}
+		return items
+	case *structpb.Value_NumberValue:
+		number := kind.NumberValue
+		switch fieldType {
+		case pb.FieldType_FIELD_TYPE_STRING, pb.FieldType_FIELD_TYPE_UUID, pb.FieldType_FIELD_TYPE_ENUM:
+			return canonicalFloat(number)
+		case pb.FieldType_FIELD_TYPE_DATETIME:
+			sec, frac := math.Modf(number)
+			return time.Unix(int64(sec), int64(frac*float64(time.Second))).UTC().Format(time.RFC3339Nano)
+		}
+		if integer, ok := toInt64(number); ok {
+			return integer
+		}
+		return number
+	case *structpb.Value_StringValue:
+		if fieldType == pb.FieldType_FIELD_TYPE_NUMBER {
+			raw := strings.TrimSpace(kind.StringValue)
+			if integer, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				return integer
+			}
+			if number, err := strconv.ParseFloat(raw, 64); err == nil {
+				return number
+			}
+		}
+		return kind.StringValue
+	case *structpb.Value_BoolValue:
+		switch fieldType {
+		case pb.FieldType_FIELD_TYPE_STRING, pb.FieldType_FIELD_TYPE_ENUM:
+			return strconv.FormatBool(kind.BoolValue)
+		}
+		return kind.BoolValue
+	default:
+		return value.AsInterface()
+	}
+}
+
 // recordToProto converts a domain Record to protobuf Record.
 func recordToProto(r Record) *pb.Record {
 	data, _ := structpb.NewStruct(r.Data)
@@ -22,6 +78,7 @@ func recordToProto(r Record) *pb.Record {
 			CreatedBy: r.CreatedBy,
 			UpdatedBy: r.UpdatedBy,
 		},
+		Labels: r.Labels,
 	}
 }
 
@@ -40,6 +97,26 @@ func historyToProto(h HistoryEntry) *pb.RecordHistoryEntry {
 	}
 }
 
+// changeToProto converts a domain Change to protobuf EntityChange.
+func changeToProto(c Change) *pb.EntityChange {
+	change := &pb.EntityChange{
+		Position:   c.Position,
+		Entity:     c.Entity,
+		RecordId:   c.RecordID,
+		ChangeType: string(c.Type),
+		Version:    c.Version,
+		OccurredAt: timestamppb.New(c.Timestamp),
+		Actor:      c.Actor,
+	}
+	if c.Before != nil {
+		change.Before, _ = structpb.NewStruct(c.Before)
+	}
+	if c.After != nil {
+		change.After, _ = structpb.NewStruct(c.After)
+	}
+	return change
+}
+
 // pivotToProto converts a domain PivotResult to protobuf PivotResponse.
 func pivotToProto(r PivotResult) *pb.PivotResponse {
 	entries := make([]*pb.PivotEntry, 0, len(r.Entries))
@@ -63,10 +140,38 @@ func pivotToProto(r PivotResult) *pb.PivotResponse {
 		columnField = r.ColumnField.ID
 	}
 
+	valueField := ""
+	if r.ValueField != nil {
+		valueField = r.ValueField.ID
+	}
+
 	return &pb.PivotResponse{
 		RowField:    rowField,
 		ColumnField: columnField,
 		Entries:     entries,
 		Totals:      totals,
+		ValueField:  valueField,
+		Aggregation: r.Aggregation,
+	}
+}
+
+// statsToProto converts a domain StatsResult to protobuf StatsResponse.
+func statsToProto(r StatsResult) *pb.StatsResponse {
+	groups := make([]*pb.StatsCount, 0, len(r.Groups))
+	for _, g := range r.Groups {
+		groups = append(groups, &pb.StatsCount{Key: g.Key, Count: g.Count})
+	}
+	buckets := make([]*pb.StatsBucket, 0, len(r.Buckets))
+	for _, b := range r.Buckets {
+		buckets = append(buckets, &pb.StatsBucket{Start: timestamppb.New(b.Start), Count: b.Count})
+	}
+
+	return &pb.StatsResponse{
+		Total:      r.Total,
+		GroupField: r.GroupField,
+		Groups:     groups,
+		TimeField:  r.TimeField,
+		Interval:   r.Interval,
+		Buckets:    buckets,
 	}
 }
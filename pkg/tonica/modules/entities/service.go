@@ -16,6 +16,7 @@ import (
 
 	entityPb "github.com/tonica-go/tonica/pkg/tonica/proto/entities"
 
+	"github.com/tonica-go/tonica/pkg/tonica/identity"
 	"github.com/tonica-go/tonica/pkg/tonica/modules/eventstore"
 )
 
@@ -31,6 +32,21 @@ type Service struct {
 	store     eventstore.Store
 	providers map[string]Provider
 	indexer   SearchIndexer
+	codec     PayloadCodec
+	metaCodec PayloadCodec
+	maxDepth  int
+	// allowAnonymous records writes without an identity as AnonymousActor.
+	allowAnonymous bool
+	// streamingList serves primary key sorted lists lazily, see
+	// SetStreamingList.
+	streamingList bool
+	// snapshotEvery is the record version interval between snapshots, see
+	// SetSnapshotFrequency.
+	snapshotEvery int64
+	// readTransforms holds the RegisterReadTransform hooks by entity id.
+	readTransforms map[string][]ReadTransform
+	// writeHooks holds the RegisterWriteHook hooks by entity id.
+	writeHooks map[string][]WriteHook
 }
 
 // Record represents a materialized entity instance.
@@ -44,22 +60,63 @@ type Record struct {
 	UpdatedBy string
 	Version   int64
 	Deleted   bool
+	// Labels holds the labels of referenced records keyed by reference field
+	// id. It is only set when ListOptions.ResolveLabels is requested.
+	Labels map[string]string
 }
 
 // ListOptions contains optional filters for ListRecords.
 type ListOptions struct {
-	Filters   []Filter
+	Filters []Filter
+	// OrGroups holds groups of filters of which at least one must match, in
+	// addition to every filter of Filters.
+	OrGroups  [][]Filter
 	SortField string
 	SortDir   entityPb.SortDirection
 	PageSize  int
 	PageToken string
 	Search    string
+	// Fields limits returned data to these field ids. Empty returns all
+	// fields; the primary key is always included.
+	Fields []string
+	// IncludeTotalCount requests the number of matching records across all
+	// pages. Providers only report it when they implement RecordCounter.
+	IncludeTotalCount bool
+	// ResolveLabels attaches the labels of referenced records to each record
+	// of the page. References are loaded in one batch per target entity.
+	ResolveLabels bool
+	// Expand lists reference fields whose label is inlined into the record
+	// data as <field>_label.
+	Expand []string
+}
+
+// GetOptions shape a single record returned by GetRecordWith.
+type GetOptions struct {
+	// Fields limits returned data to these field ids. Empty returns all
+	// fields; the primary key is always included.
+	Fields []string
+	// Expand lists reference fields whose label is inlined into the record
+	// data as <field>_label.
+	Expand []string
+}
+
+// ListResult is a page of records.
+type ListResult struct {
+	Records       []Record
+	NextPageToken string
+	// TotalCount is the number of records matching the filters across all
+	// pages. It is nil unless ListOptions.IncludeTotalCount was set.
+	TotalCount *int64
 }
 
 // HistoryOptions control pagination for record history.
 type HistoryOptions struct {
 	PageSize  int
 	PageToken string
+	// SinceVersion and UntilVersion bound the returned versions, both
+	// inclusive. Zero leaves the bound open.
+	SinceVersion int64
+	UntilVersion int64
 }
 
 // Filter captures a declarative filter expression.
@@ -85,6 +142,12 @@ type PivotOptions struct {
 	RowField    string
 	ColumnField string
 	Filters     []Filter
+	// ValueField is the numeric field aggregated per cell. Records are
+	// counted when it is empty; non-numeric values are skipped otherwise.
+	ValueField string
+	// Aggregation applied to ValueField. Defaults to SUM when ValueField is
+	// set and to COUNT otherwise.
+	Aggregation entityPb.PivotAggregation
 }
 
 // PivotEntry captures a single aggregated cell.
@@ -98,13 +161,17 @@ type PivotEntry struct {
 type PivotResult struct {
 	RowField     FieldDefinition
 	ColumnField  *FieldDefinition
+	ValueField   *FieldDefinition
+	Aggregation  entityPb.PivotAggregation
 	Entries      []PivotEntry
 	RowTotals    map[string]float64
 	ColumnTotals map[string]float64
 	GrandTotal   float64
 }
 
-// Provider allows overriding CRUD behaviour for a specific entity.
+// Provider allows overriding CRUD behaviour for a specific entity. Create,
+// Update and Delete run inside a UnitOfWork available through
+// UnitOfWorkFromContext; returning an error rolls it back.
 type Provider interface {
 	List(ctx context.Context, def Definition, opts ListOptions) ([]Record, string, error)
 	Get(ctx context.Context, def Definition, id string) (Record, error)
@@ -113,6 +180,25 @@ type Provider interface {
 	Delete(ctx context.Context, def Definition, id string) error
 }
 
+// RecordCounter is implemented by providers that can count the records
+// matching a list query. It is used when ListOptions.IncludeTotalCount is set.
+type RecordCounter interface {
+	Count(ctx context.Context, def Definition, opts ListOptions) (int64, error)
+}
+
+// RecordRestorer is implemented by providers that keep deleted records and
+// can restore them. It is used by RestoreRecord.
+type RecordRestorer interface {
+	Restore(ctx context.Context, def Definition, recordID string) (Record, error)
+}
+
+// TxProvider is implemented by providers whose writes can join the
+// transaction of the active unit of work, see UnitOfWork.Tx. Atomic batches
+// on entities with a provider need it.
+type TxProvider interface {
+	JoinsUnitOfWork() bool
+}
+
 // NewService constructs Service from embedded definitions.
 func NewService(store eventstore.Store) (*Service, error) {
 	defs, err := LoadDefinitions()
@@ -171,77 +257,116 @@ func (s *Service) providerFor(entityID string) (Provider, bool) {
 	return provider, ok
 }
 
+const (
+	// SystemActorPrefix prefixes actors set with WithSystemActor.
+	SystemActorPrefix = "system:"
+	// AnonymousActor records writes without an identity when anonymous
+	// writes are allowed.
+	AnonymousActor = "anonymous"
+)
+
+type systemActorKey struct{}
+
+// actorKey carries the actor resolved by the service, anonymous included, to
+// the providers.
+type actorKey struct{}
+
+// WithSystemActor marks ctx as a non-interactive write (worker, import,
+// migration) performed by the named system actor, e.g. "import" records
+// "system:import". It is only used when ctx carries no identity.
+func WithSystemActor(ctx context.Context, name string) context.Context {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ctx
+	}
+	if !strings.HasPrefix(name, SystemActorPrefix) {
+		name = SystemActorPrefix + name
+	}
+	return context.WithValue(ctx, systemActorKey{}, name)
+}
+
+// SetAllowAnonymous lets writes without an identity through, recorded as
+// AnonymousActor, for services behind an authenticating gateway. Writes are
+// rejected with ErrUnauthenticated by default.
+func (s *Service) SetAllowAnonymous(allow bool) {
+	s.allowAnonymous = allow
+}
+
+// actorID returns the actor recorded for a write made with ctx.
+func (s *Service) actorID(ctx context.Context) (string, error) {
+	actorID, err := actorIDFromContext(ctx)
+	if errors.Is(err, ErrUnauthenticated) && s.allowAnonymous {
+		return AnonymousActor, nil
+	}
+	return actorID, err
+}
+
+// withActor resolves the actor of a write before it is handed to a provider,
+// so providers record the same actor as the service.
+func (s *Service) withActor(ctx context.Context) (context.Context, error) {
+	actorID, err := s.actorID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, actorKey{}, actorID), nil
+}
+
 func actorIDFromContext(ctx context.Context) (string, error) {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok {
+		return actor, nil
+	}
 	// Try to get identity from context
-	value := ctx.Value("identity")
-	if value == nil {
-		return "", ErrUnauthenticated
+	if id := identity.FromContext(ctx).GetID(); strings.TrimSpace(id) != "" {
+		return id, nil
 	}
 
-	ident, ok := value.(map[string]interface{})
-	if !ok || ident == nil || strings.TrimSpace(ident["id"].(string)) == "" {
-		return "", ErrUnauthenticated
+	if actor, ok := ctx.Value(systemActorKey{}).(string); ok {
+		return actor, nil
 	}
-
-	return ident["id"].(string), nil
+	return "", ErrUnauthenticated
 }
 
 // ListRecords materializes records for an entity using metadata filters.
 func (s *Service) ListRecords(ctx context.Context, entityID string, opts ListOptions) ([]Record, string, error) {
-	def, err := s.Definition(entityID)
+	result, err := s.ListRecordsPage(ctx, entityID, opts)
 	if err != nil {
 		return nil, "", err
 	}
+	return result.Records, result.NextPageToken, nil
+}
 
-	normFilters, err := s.normalizeFilters(def, opts.Filters)
+// ListRecordsPage is ListRecords returning the page as a ListResult, which
+// also carries the total count when opts.IncludeTotalCount is set.
+func (s *Service) ListRecordsPage(ctx context.Context, entityID string, opts ListOptions) (ListResult, error) {
+	def, err := s.Definition(entityID)
 	if err != nil {
-		return nil, "", err
+		return ListResult{}, err
 	}
 
-	localFilters := make([]normalizedFilter, 0, len(normFilters))
-	nestedFilters := make([]normalizedFilter, 0)
-	for _, nf := range normFilters {
-		if nf.isNested() {
-			nestedFilters = append(nestedFilters, nf)
-			continue
-		}
-		localFilters = append(localFilters, nf)
-	}
-
-	generatedFilters, emptyResult, err := s.buildNestedFilters(ctx, nestedFilters)
+	filters, normFilters, emptyResult, err := s.resolveFilters(ctx, def, opts.Filters)
 	if err != nil {
-		return nil, "", err
+		return ListResult{}, err
 	}
-	if len(nestedFilters) > 0 && emptyResult {
-		return []Record{}, "", nil
+	if emptyResult {
+		return emptyListResult(opts), nil
 	}
+	opts.Filters = filters
 
-	sanitizedFilters := make([]Filter, 0, len(localFilters)+len(generatedFilters))
-	for _, nf := range localFilters {
-		sanitizedFilters = append(sanitizedFilters, Filter{
-			FieldID:  nf.targetField().ID,
-			Operator: nf.Operator,
-			Value:    nf.Value,
-		})
+	orGroups, sanitizedGroups, emptyResult, err := s.resolveOrGroups(ctx, def, opts.OrGroups)
+	if err != nil {
+		return ListResult{}, err
 	}
-	sanitizedFilters = append(sanitizedFilters, generatedFilters...)
-	opts.Filters = sanitizedFilters
-
-	if len(generatedFilters) > 0 {
-		normFilters, err = s.normalizeFilters(def, sanitizedFilters)
-		if err != nil {
-			return nil, "", err
-		}
-	} else {
-		normFilters = localFilters
+	if emptyResult {
+		return emptyListResult(opts), nil
 	}
+	opts.OrGroups = sanitizedGroups
 
 	sortField := strings.TrimSpace(opts.SortField)
 	if sortField == "" {
 		sortField = def.PrimaryKey
 	}
 	if _, ok := def.Field(sortField); !ok && sortField != def.PrimaryKey {
-		return nil, "", fmt.Errorf("%w: unknown sort field %s", ErrInvalidSort, sortField)
+		return ListResult{}, fmt.Errorf("%w: unknown sort field %s", ErrInvalidSort, sortField)
 	}
 	opts.SortField = sortField
 
@@ -253,17 +378,92 @@ func (s *Service) ListRecords(ctx context.Context, entityID string, opts ListOpt
 	opts.PageSize = clampPageSize(opts.PageSize)
 	opts.Search = strings.TrimSpace(opts.Search)
 
-	if provider, ok := s.providerFor(entityID); ok {
-		return provider.List(ctx, def, opts)
+	fields, err := resolveFields(def, opts.Fields)
+	if err != nil {
+		return ListResult{}, err
 	}
+	opts.Fields = fields
 
-	return s.listRecordsDefault(ctx, def, normFilters, opts)
+	expand, err := resolveExpand(def, opts.Expand)
+	if err != nil {
+		return ListResult{}, err
+	}
+
+	var result ListResult
+	provider, hasProvider := s.providerFor(entityID)
+	if !hasProvider {
+		// Provider page tokens are opaque, so only event-sourced lists are
+		// checked.
+		if err := s.checkPageOffset(ctx, def, opts.PageToken); err != nil {
+			return ListResult{}, err
+		}
+		if err := s.checkKeysetToken(def, opts); err != nil {
+			return ListResult{}, err
+		}
+	}
+	if hasProvider {
+		result.Records, result.NextPageToken, err = provider.List(ctx, def, opts)
+		if err == nil && opts.IncludeTotalCount {
+			if counter, ok := provider.(RecordCounter); ok {
+				var total int64
+				total, err = counter.Count(ctx, def, opts)
+				result.TotalCount = &total
+			}
+		}
+	} else if s.canStreamList(def, opts) {
+		result.Records, result.NextPageToken, err = s.listRecordsStreaming(ctx, def, normFilters, orGroups, opts)
+	} else {
+		var total int
+		result.Records, result.NextPageToken, total, err = s.listRecordsDefault(ctx, def, normFilters, orGroups, opts)
+		if opts.IncludeTotalCount {
+			count := int64(total)
+			result.TotalCount = &count
+		}
+	}
+	if err != nil {
+		return ListResult{}, err
+	}
+	if err := s.transformRecords(ctx, def.ID, result.Records); err != nil {
+		return ListResult{}, err
+	}
+
+	if opts.ResolveLabels {
+		if err := s.resolveReferenceLabels(ctx, def, result.Records); err != nil {
+			return ListResult{}, err
+		}
+	}
+
+	// Expanded labels are loaded before projection, which may drop the
+	// reference field, and attached after it.
+	expanded, err := s.loadReferenceLabels(ctx, result.Records, expand)
+	if err != nil {
+		return ListResult{}, err
+	}
+
+	// Projection runs last so filters, search and sorting see full records.
+	for i := range result.Records {
+		result.Records[i].Data = projectFields(def, result.Records[i].Data, fields)
+	}
+	attachExpandedLabels(result.Records, expanded)
+	return result, nil
 }
 
-func (s *Service) listRecordsDefault(ctx context.Context, def Definition, filters []normalizedFilter, opts ListOptions) ([]Record, string, error) {
+// emptyListResult is the result of a list whose filters cannot match.
+func emptyListResult(opts ListOptions) ListResult {
+	result := ListResult{Records: []Record{}}
+	if opts.IncludeTotalCount {
+		result.TotalCount = new(int64)
+	}
+	return result
+}
+
+// listRecordsDefault returns a page of event-sourced records along with the
+// number of records matching the filters. Every record is loaded and filtered
+// in memory, so the count comes for free.
+func (s *Service) listRecordsDefault(ctx context.Context, def Definition, filters []normalizedFilter, orGroups [][]normalizedFilter, opts ListOptions) ([]Record, string, int, error) {
 	indexEntries, err := s.loadIndex(ctx, def.ID)
 	if err != nil {
-		return nil, "", err
+		return nil, "", 0, err
 	}
 
 	records := make([]Record, 0, len(indexEntries))
@@ -273,21 +473,23 @@ func (s *Service) listRecordsDefault(ctx context.Context, def Definition, filter
 			continue
 		}
 		if err != nil {
-			return nil, "", err
+			return nil, "", 0, err
 		}
 		records = append(records, record)
 	}
 
 	records = applySearch(def, records, opts.Search)
 	records = applyFilters(records, filters)
+	records = applyOrGroups(records, orGroups)
 	sortRecords(records, opts.SortField, opts.SortDir)
 
 	offset, err := parsePageToken(opts.PageToken)
 	if err != nil {
-		return nil, "", err
+		return nil, "", 0, err
 	}
 	if offset >= len(records) {
-		return []Record{}, "", nil
+		recordListMetrics(ctx, def.ID, len(indexEntries), 0)
+		return []Record{}, "", len(records), nil
 	}
 
 	end := offset + opts.PageSize
@@ -295,12 +497,13 @@ func (s *Service) listRecordsDefault(ctx context.Context, def Definition, filter
 		end = len(records)
 	}
 	page := records[offset:end]
+	recordListMetrics(ctx, def.ID, len(indexEntries), len(page))
 
 	var nextToken string
 	if end < len(records) {
 		nextToken = strconv.Itoa(end)
 	}
-	return page, nextToken, nil
+	return page, nextToken, len(records), nil
 }
 
 // GetRecord returns a single record by id.
@@ -310,66 +513,221 @@ func (s *Service) GetRecord(ctx context.Context, entityID, recordID string) (Rec
 		return Record{}, err
 	}
 
+	var record Record
 	if provider, ok := s.providerFor(entityID); ok {
-		return provider.Get(ctx, def, recordID)
+		record, err = provider.Get(ctx, def, recordID)
+	} else {
+		record, err = s.getRecordDefault(ctx, def, recordID)
+	}
+	if err != nil {
+		return Record{}, err
+	}
+
+	records := []Record{record}
+	if err := s.transformRecords(ctx, def.ID, records); err != nil {
+		return Record{}, err
+	}
+	return records[0], nil
+}
+
+// GetRecordFields returns a single record by id with its data limited to the
+// given field ids. The primary key is always included.
+func (s *Service) GetRecordFields(ctx context.Context, entityID, recordID string, fields []string) (Record, error) {
+	return s.GetRecordWith(ctx, entityID, recordID, GetOptions{Fields: fields})
+}
+
+// GetRecordWith returns a single record by id shaped by opts.
+func (s *Service) GetRecordWith(ctx context.Context, entityID, recordID string, opts GetOptions) (Record, error) {
+	def, err := s.Definition(entityID)
+	if err != nil {
+		return Record{}, err
+	}
+
+	fields, err := resolveFields(def, opts.Fields)
+	if err != nil {
+		return Record{}, err
+	}
+	expand, err := resolveExpand(def, opts.Expand)
+	if err != nil {
+		return Record{}, err
 	}
 
-	return s.getRecordDefault(ctx, def, recordID)
+	record, err := s.GetRecord(ctx, entityID, recordID)
+	if err != nil {
+		return Record{}, err
+	}
+
+	records := []Record{record}
+	expanded, err := s.loadReferenceLabels(ctx, records, expand)
+	if err != nil {
+		return Record{}, err
+	}
+	records[0].Data = projectFields(def, records[0].Data, fields)
+	attachExpandedLabels(records, expanded)
+	return records[0], nil
 }
 
-// RecordHistory returns the timeline of changes for a record.
+// RecordHistory returns the timeline of changes for a record, newest first.
+// Page tokens resume below the oldest version of the previous page. When the
+// store keeps snapshots and the page lies after the latest one, only the
+// events after that snapshot are loaded.
 func (s *Service) RecordHistory(ctx context.Context, entityID, recordID string, opts HistoryOptions) ([]HistoryEntry, string, error) {
 	def, err := s.Definition(entityID)
 	if err != nil {
 		return nil, "", err
 	}
 
-	streamID := recordStreamID(def.ID, recordID)
-	events, err := s.store.Load(ctx, streamID, 0)
+	page, nextToken, err := s.recordHistory(ctx, def, recordID, opts)
 	if err != nil {
 		return nil, "", err
 	}
-	if len(events) == 0 {
-		legacyID := legacyRecordStreamID(def.ID, recordID)
-		legacyEvents, legacyErr := s.store.Load(ctx, legacyID, 0)
-		if legacyErr != nil {
-			return nil, "", legacyErr
-		}
-		events = legacyEvents
-		if len(events) == 0 {
-			return nil, "", ErrRecordNotFound
+	if err := s.transformHistory(ctx, def, recordID, page); err != nil {
+		return nil, "", err
+	}
+	return page, nextToken, nil
+}
+
+func (s *Service) recordHistory(ctx context.Context, def Definition, recordID string, opts HistoryOptions) ([]HistoryEntry, string, error) {
+	window, offset, err := historyWindow(opts)
+	if err != nil {
+		return nil, "", err
+	}
+	pageSize := clampPageSize(opts.PageSize)
+
+	if offset == 0 {
+		page, nextToken, ok, err := s.recordHistoryFromSnapshot(ctx, def, recordID, window, pageSize)
+		if err != nil || ok {
+			return page, nextToken, err
 		}
 	}
 
-	state := make(map[string]any)
+	streamID, events, err := s.loadRecordEvents(ctx, def, recordID)
+	if err != nil {
+		return nil, "", err
+	}
+	base, err := s.replayBase(ctx, def, recordID, streamID, events)
+	if err != nil {
+		return nil, "", err
+	}
+	entries, err := replayHistory(s.payloadCodec(), s.metadataCodec(), def, base, events, window)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if offset >= len(entries) {
+		return []HistoryEntry{}, "", nil
+	}
+	end := min(offset+pageSize, len(entries))
+	page := entries[offset:end]
+
+	var nextToken string
+	if end < len(entries) {
+		nextToken = historyTokenPrefix + strconv.FormatInt(page[len(page)-1].Version, 10)
+	}
+	return page, nextToken, nil
+}
+
+// recordHistoryFromSnapshot serves a history page from the latest record
+// snapshot and the events after it. It reports false when the page reaches
+// back to the snapshot version or earlier, or when compaction removed the
+// snapshot version, which needs a full replay.
+func (s *Service) recordHistoryFromSnapshot(ctx context.Context, def Definition, recordID string, window versionWindow, pageSize int) ([]HistoryEntry, string, bool, error) {
+	snapshotter, ok := s.snapshotter()
+	if !ok {
+		return nil, "", false, nil
+	}
+	streamID := recordStreamID(def.ID, recordID)
+	snapshot, err := snapshotter.LoadSnapshot(ctx, streamID)
+	if errors.Is(err, eventstore.ErrSnapshotNotFound) {
+		return nil, "", false, nil
+	}
+	if err != nil {
+		return nil, "", false, err
+	}
+	if window.until > 0 && window.until <= snapshot.Version {
+		return nil, "", false, nil
+	}
+
+	codec := s.payloadCodec()
+	base, err := recordFromSnapshot(codec, def, recordID, snapshot)
+	if err != nil {
+		return nil, "", false, err
+	}
+	// The event at the snapshot version is loaded too: it is gone once
+	// compaction cut past the snapshot, and only the full replay still knows
+	// the versions in between.
+	events, err := s.store.Load(ctx, streamID, snapshot.Version)
+	if err != nil {
+		return nil, "", false, err
+	}
+	if len(events) == 0 || events[0].Version != snapshot.Version {
+		return nil, "", false, nil
+	}
+	entries, err := replayHistory(codec, s.metadataCodec(), def, base, events[1:], window)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	// Versions up to the snapshot still exist in the stream, so a window that
+	// reaches them has more entries than were replayed here.
+	olderExist := window.since <= snapshot.Version
+	if len(entries) < pageSize && olderExist {
+		return nil, "", false, nil
+	}
+	page := entries[:min(pageSize, len(entries))]
+
+	var nextToken string
+	if len(entries) > pageSize || olderExist {
+		nextToken = historyTokenPrefix + strconv.FormatInt(page[len(page)-1].Version, 10)
+	}
+	return page, nextToken, true, nil
+}
+
+// replayHistory applies events on top of base and returns the entries whose
+// version falls inside window, newest first. Events up to the version of
+// base are already folded into it; the one at that version reports its state.
+func replayHistory(codec, metaCodec PayloadCodec, def Definition, base Record, events []eventstore.Event, window versionWindow) ([]HistoryEntry, error) {
 	entries := make([]HistoryEntry, 0, len(events))
+	state := cloneMap(base.Data)
 
 	for _, evt := range events {
-		meta, err := decodeEventMetadata(evt.Metadata)
-		if err != nil {
-			return nil, "", err
+		if evt.Version < base.Version {
+			continue
+		}
+		if window.until > 0 && evt.Version > window.until {
+			break
+		}
+		inWindow := evt.Version >= window.since
+
+		var meta EventMetadata
+		if inWindow {
+			var err error
+			meta, err = decodeEventMetadata(metaCodec, evt.Metadata)
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		entry := HistoryEntry{
-			Version:    evt.Version,
-			EventType:  evt.Type,
-			Timestamp:  meta.Timestamp,
-			Actor:      meta.ActorID,
-			RawPayload: append([]byte(nil), evt.Payload...),
+			Version:   evt.Version,
+			EventType: evt.Type,
+			Timestamp: meta.Timestamp,
+			Actor:     meta.ActorID,
 		}
 
-		switch evt.Type {
-		case eventTypeRecordCreated:
-			payload, err := decodeRecordPayload(evt.Payload)
+		switch {
+		case evt.Version == base.Version:
+			entry.Deleted = base.Deleted
+		case evt.Type == eventTypeRecordCreated:
+			payload, err := decodeRecordPayload(codec, evt.Payload)
 			if err != nil {
-				return nil, "", err
+				return nil, err
 			}
 			state = cloneMap(payload.Data)
-			entry.Data = snapshotForHistory(def, state)
-		case eventTypeRecordUpdated:
-			payload, err := decodeRecordPayload(evt.Payload)
+		case evt.Type == eventTypeRecordUpdated:
+			payload, err := decodeRecordPayload(codec, evt.Payload)
 			if err != nil {
-				return nil, "", err
+				return nil, err
 			}
 			if state == nil {
 				state = make(map[string]any)
@@ -381,14 +739,19 @@ func (s *Service) RecordHistory(ctx context.Context, entityID, recordID string,
 				}
 				state[key] = value
 			}
-			entry.Data = snapshotForHistory(def, state)
-		case eventTypeRecordDeleted:
-			entry.Data = snapshotForHistory(def, state)
+		case evt.Type == eventTypeRecordDeleted:
 			entry.Deleted = true
+		case evt.Type == eventTypeRecordRestored:
+			// The state is unchanged and the entry is no longer deleted.
 		default:
 			continue
 		}
 
+		if !inWindow {
+			continue
+		}
+		entry.Data = snapshotForHistory(def, state)
+		entry.RawPayload = append([]byte(nil), evt.Payload...)
 		entries = append(entries, entry)
 	}
 
@@ -396,28 +759,42 @@ func (s *Service) RecordHistory(ctx context.Context, entityID, recordID string,
 	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
 		entries[i], entries[j] = entries[j], entries[i]
 	}
+	return entries, nil
+}
 
-	pageSize := clampPageSize(opts.PageSize)
-	offset, err := parsePageToken(opts.PageToken)
-	if err != nil {
-		return nil, "", err
-	}
-	if offset >= len(entries) {
-		return []HistoryEntry{}, "", nil
-	}
+// historyTokenPrefix marks history page tokens that resume below a version
+// instead of at an offset.
+const historyTokenPrefix = "before:"
 
-	end := offset + pageSize
-	if end > len(entries) {
-		end = len(entries)
-	}
-	page := entries[offset:end]
+// versionWindow is an inclusive range of record versions. Zero bounds are
+// open.
+type versionWindow struct {
+	since int64
+	until int64
+}
 
-	var nextToken string
-	if end < len(entries) {
-		nextToken = strconv.Itoa(end)
+// historyWindow combines the version bounds and page token of opts. Plain
+// offset tokens are still accepted and returned as offset.
+func historyWindow(opts HistoryOptions) (versionWindow, int, error) {
+	if opts.SinceVersion < 0 || opts.UntilVersion < 0 {
+		return versionWindow{}, 0, fmt.Errorf("%w: versions must not be negative", ErrInvalidFilter)
 	}
+	window := versionWindow{since: opts.SinceVersion, until: opts.UntilVersion}
 
-	return page, nextToken, nil
+	before, ok := strings.CutPrefix(opts.PageToken, historyTokenPrefix)
+	if !ok {
+		offset, err := parsePageToken(opts.PageToken)
+		return window, offset, err
+	}
+	// Tokens are only issued when older versions exist, so they are above 1.
+	version, err := strconv.ParseInt(before, 10, 64)
+	if err != nil || version <= 1 {
+		return versionWindow{}, 0, fmt.Errorf("invalid page token")
+	}
+	if window.until == 0 || version-1 < window.until {
+		window.until = version - 1
+	}
+	return window, 0, nil
 }
 
 // PivotRecords aggregates records for simple pivot reporting.
@@ -446,6 +823,29 @@ func (s *Service) PivotRecords(ctx context.Context, entityID string, opts PivotO
 		columnFieldPtr = &columnField
 	}
 
+	var valueFieldPtr *FieldDefinition
+	aggregation := opts.Aggregation
+	if valueFieldID := strings.TrimSpace(opts.ValueField); valueFieldID != "" {
+		valueField, ok := def.Field(valueFieldID)
+		if !ok {
+			return PivotResult{}, fmt.Errorf("%w: value field %s", ErrInvalidFilter, valueFieldID)
+		}
+		if valueField.Type != entityPb.FieldType_FIELD_TYPE_NUMBER {
+			return PivotResult{}, fmt.Errorf("%w: value field %s is not a number", ErrInvalidFilter, valueField.ID)
+		}
+		valueFieldPtr = &valueField
+		if aggregation == entityPb.PivotAggregation_PIVOT_AGGREGATION_UNSPECIFIED {
+			aggregation = entityPb.PivotAggregation_PIVOT_AGGREGATION_SUM
+		}
+	} else {
+		switch aggregation {
+		case entityPb.PivotAggregation_PIVOT_AGGREGATION_UNSPECIFIED, entityPb.PivotAggregation_PIVOT_AGGREGATION_COUNT:
+			aggregation = entityPb.PivotAggregation_PIVOT_AGGREGATION_COUNT
+		default:
+			return PivotResult{}, fmt.Errorf("%w: aggregation %s requires a value field", ErrInvalidFilter, aggregation)
+		}
+	}
+
 	listOpts := ListOptions{
 		Filters:   opts.Filters,
 		SortField: def.PrimaryKey,
@@ -454,14 +854,15 @@ func (s *Service) PivotRecords(ctx context.Context, entityID string, opts PivotO
 	}
 
 	pageToken := ""
-	counts := make(map[string]map[string]float64)
-	rowTotals := make(map[string]float64)
-	columnTotals := make(map[string]float64)
-	var grandTotal float64
+	cells := make(map[string]map[string]*pivotAccumulator)
+	rowAccs := make(map[string]*pivotAccumulator)
+	columnAccs := make(map[string]*pivotAccumulator)
+	var grandAcc pivotAccumulator
 
+	scanCtx := withFullScan(ctx)
 	for {
 		listOpts.PageToken = pageToken
-		records, next, err := s.ListRecords(ctx, entityID, listOpts)
+		records, next, err := s.ListRecords(scanCtx, entityID, listOpts)
 		if err != nil {
 			return PivotResult{}, err
 		}
@@ -473,13 +874,21 @@ func (s *Service) PivotRecords(ctx context.Context, entityID string, opts PivotO
 				columnKey = normalizePivotKey(asString(record.Data[columnFieldPtr.ID]))
 			}
 
-			if _, exists := counts[rowKey]; !exists {
-				counts[rowKey] = make(map[string]float64)
+			value := 1.0
+			if valueFieldPtr != nil {
+				var ok bool
+				if value, ok = toFloat64(record.Data[valueFieldPtr.ID]); !ok {
+					continue
+				}
+			}
+
+			if _, exists := cells[rowKey]; !exists {
+				cells[rowKey] = make(map[string]*pivotAccumulator)
 			}
-			counts[rowKey][columnKey]++
-			rowTotals[rowKey]++
-			columnTotals[columnKey]++
-			grandTotal++
+			pivotAccumulatorFor(cells[rowKey], columnKey).add(value)
+			pivotAccumulatorFor(rowAccs, rowKey).add(value)
+			pivotAccumulatorFor(columnAccs, columnKey).add(value)
+			grandAcc.add(value)
 		}
 
 		if next == "" {
@@ -492,62 +901,105 @@ func (s *Service) PivotRecords(ctx context.Context, entityID string, opts PivotO
 	}
 
 	entries := make([]PivotEntry, 0)
-	rowKeys := make([]string, 0, len(counts))
-	for key := range counts {
+	rowKeys := make([]string, 0, len(cells))
+	for key := range cells {
 		rowKeys = append(rowKeys, key)
 	}
 	sort.Strings(rowKeys)
 
+	rowTotals := make(map[string]float64, len(rowAccs))
+	for key, acc := range rowAccs {
+		rowTotals[key] = acc.result(aggregation)
+	}
+	columnTotals := make(map[string]float64, len(columnAccs))
+
 	if columnFieldPtr != nil {
-		columnKeySet := make(map[string]struct{})
-		for _, columnMap := range counts {
-			for key := range columnMap {
-				columnKeySet[key] = struct{}{}
-			}
-		}
-		columnKeys := make([]string, 0, len(columnKeySet))
-		for key := range columnKeySet {
+		columnKeys := make([]string, 0, len(columnAccs))
+		for key, acc := range columnAccs {
 			columnKeys = append(columnKeys, key)
+			columnTotals[key] = acc.result(aggregation)
 		}
 		sort.Strings(columnKeys)
 
 		for _, rowKey := range rowKeys {
 			for _, columnKey := range columnKeys {
-				value := counts[rowKey][columnKey]
-				if value == 0 {
+				acc, ok := cells[rowKey][columnKey]
+				if !ok {
 					continue
 				}
 				entries = append(entries, PivotEntry{
 					RowKey:    rowKey,
 					ColumnKey: columnKey,
-					Value:     value,
+					Value:     acc.result(aggregation),
 				})
 			}
 		}
 	} else {
 		for _, rowKey := range rowKeys {
-			value := rowTotals[rowKey]
-			if value == 0 {
-				continue
-			}
 			entries = append(entries, PivotEntry{
 				RowKey: rowKey,
-				Value:  value,
+				Value:  rowTotals[rowKey],
 			})
 		}
-		columnTotals = map[string]float64{}
 	}
 
 	return PivotResult{
 		RowField:     rowField,
 		ColumnField:  columnFieldPtr,
+		ValueField:   valueFieldPtr,
+		Aggregation:  aggregation,
 		Entries:      entries,
 		RowTotals:    rowTotals,
 		ColumnTotals: columnTotals,
-		GrandTotal:   grandTotal,
+		GrandTotal:   grandAcc.result(aggregation),
 	}, nil
 }
 
+// pivotAccumulator folds the values of one pivot cell or total.
+type pivotAccumulator struct {
+	count    int
+	sum      float64
+	min, max float64
+}
+
+func pivotAccumulatorFor(accs map[string]*pivotAccumulator, key string) *pivotAccumulator {
+	acc, ok := accs[key]
+	if !ok {
+		acc = &pivotAccumulator{}
+		accs[key] = acc
+	}
+	return acc
+}
+
+func (a *pivotAccumulator) add(value float64) {
+	if a.count == 0 || value < a.min {
+		a.min = value
+	}
+	if a.count == 0 || value > a.max {
+		a.max = value
+	}
+	a.count++
+	a.sum += value
+}
+
+func (a *pivotAccumulator) result(aggregation entityPb.PivotAggregation) float64 {
+	switch aggregation {
+	case entityPb.PivotAggregation_PIVOT_AGGREGATION_SUM:
+		return a.sum
+	case entityPb.PivotAggregation_PIVOT_AGGREGATION_AVG:
+		if a.count == 0 {
+			return 0
+		}
+		return a.sum / float64(a.count)
+	case entityPb.PivotAggregation_PIVOT_AGGREGATION_MIN:
+		return a.min
+	case entityPb.PivotAggregation_PIVOT_AGGREGATION_MAX:
+		return a.max
+	default:
+		return float64(a.count)
+	}
+}
+
 func (s *Service) getRecordDefault(ctx context.Context, def Definition, recordID string) (Record, error) {
 	record, err := s.loadRecord(ctx, def, recordID)
 	if err != nil {
@@ -566,20 +1018,55 @@ func (s *Service) CreateRecord(ctx context.Context, entityID string, payload map
 		return Record{}, err
 	}
 
-	data, err := sanitizePayload(def, payload, false)
+	data, err := s.prepareCreate(ctx, def, payload)
 	if err != nil {
 		return Record{}, err
 	}
+	return s.createRecord(ctx, def, data)
+}
 
-	if provider, ok := s.providerFor(entityID); ok {
-		return provider.Create(ctx, def, data)
+// prepareCreate sanitizes a create payload, runs the write hooks and
+// validates its client-supplied id, if any.
+func (s *Service) prepareCreate(ctx context.Context, def Definition, payload map[string]any) (map[string]any, error) {
+	data, err := sanitizePayload(def, payload, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.runWriteHooks(ctx, def.ID, WriteActionCreate, data); err != nil {
+		return nil, err
+	}
+	// Generated ids are always valid; only client-supplied ones are checked.
+	if recordID := asString(data[def.PrimaryKey]); recordID != "" {
+		if err := def.IDFormat.Validate(recordID); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func (s *Service) createRecord(ctx context.Context, def Definition, data map[string]any) (Record, error) {
+	if provider, ok := s.providerFor(def.ID); ok {
+		ctx, err := s.withActor(ctx)
+		if err != nil {
+			return Record{}, err
+		}
+		var record Record
+		err = s.inUnitOfWork(ctx, func(ctx context.Context) error {
+			var err error
+			record, err = provider.Create(ctx, def, data)
+			return err
+		})
+		if err != nil {
+			return Record{}, err
+		}
+		return record, nil
 	}
 
 	return s.createRecordDefault(ctx, def, data)
 }
 
 func (s *Service) createRecordDefault(ctx context.Context, def Definition, data map[string]any) (Record, error) {
-	actorID, err := actorIDFromContext(ctx)
+	actorID, err := s.actorID(ctx)
 	if err != nil {
 		return Record{}, err
 	}
@@ -597,21 +1084,19 @@ func (s *Service) createRecordDefault(ctx context.Context, def Definition, data
 	}
 
 	now := time.Now().UTC()
-	data["createdBy"] = actorID
-	data["updatedBy"] = actorID
+	audit := def.auditFields()
+	data[audit.CreatedBy] = actorID
+	data[audit.UpdatedBy] = actorID
 
-	meta := eventMetadata{
-		Entity:    def.ID,
-		RecordID:  recordID,
-		ActorID:   actorID,
-		Timestamp: now,
-	}
-
-	if err := s.appendRecordEvent(ctx, def.ID, recordID, -1, eventTypeRecordCreated, data, meta); err != nil {
-		return Record{}, err
-	}
+	meta := newEventMetadata(ctx, def.ID, recordID, actorID, now)
 
-	if err := s.appendIndexEvent(ctx, def.ID, recordID, false, meta); err != nil {
+	err = s.inUnitOfWork(ctx, func(ctx context.Context) error {
+		if err := s.appendRecordEvent(ctx, def.ID, recordID, -1, eventTypeRecordCreated, data, meta); err != nil {
+			return err
+		}
+		return s.appendIndexEvent(ctx, def.ID, recordID, false, meta)
+	})
+	if err != nil {
 		return Record{}, err
 	}
 
@@ -638,27 +1123,58 @@ func (s *Service) UpdateRecord(ctx context.Context, entityID, recordID string, p
 		return Record{}, err
 	}
 
-	data, err := sanitizePayload(def, payload, true)
+	data, err := s.prepareUpdate(ctx, def, payload)
 	if err != nil {
 		return Record{}, err
 	}
+	return s.updateRecord(ctx, def, recordID, data)
+}
+
+// prepareUpdate sanitizes an update payload and runs the write hooks. The
+// primary key cannot be changed and is dropped.
+func (s *Service) prepareUpdate(ctx context.Context, def Definition, payload map[string]any) (map[string]any, error) {
+	data, err := sanitizePayload(def, payload, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.runWriteHooks(ctx, def.ID, WriteActionUpdate, data); err != nil {
+		return nil, err
+	}
 	delete(data, def.PrimaryKey)
+	return data, nil
+}
+
+func (s *Service) updateRecord(ctx context.Context, def Definition, recordID string, data map[string]any) (Record, error) {
+	provider, hasProvider := s.providerFor(def.ID)
 	if len(data) == 0 {
-		if provider, ok := s.providerFor(entityID); ok {
+		if hasProvider {
 			return provider.Get(ctx, def, recordID)
 		}
 		return s.getRecordDefault(ctx, def, recordID)
 	}
 
-	if provider, ok := s.providerFor(entityID); ok {
-		return provider.Update(ctx, def, recordID, data)
+	if hasProvider {
+		ctx, err := s.withActor(ctx)
+		if err != nil {
+			return Record{}, err
+		}
+		var record Record
+		err = s.inUnitOfWork(ctx, func(ctx context.Context) error {
+			var err error
+			record, err = provider.Update(ctx, def, recordID, data)
+			return err
+		})
+		if err != nil {
+			return Record{}, err
+		}
+		return record, nil
 	}
 
 	return s.updateRecordDefault(ctx, def, recordID, data)
 }
 
 func (s *Service) updateRecordDefault(ctx context.Context, def Definition, recordID string, data map[string]any) (Record, error) {
-	actorID, err := actorIDFromContext(ctx)
+	actorID, err := s.actorID(ctx)
 	if err != nil {
 		return Record{}, err
 	}
@@ -672,20 +1188,17 @@ func (s *Service) updateRecordDefault(ctx context.Context, def Definition, recor
 	}
 
 	now := time.Now().UTC()
-	data["updatedBy"] = actorID
+	data[def.auditFields().UpdatedBy] = actorID
 
-	meta := eventMetadata{
-		Entity:    def.ID,
-		RecordID:  recordID,
-		ActorID:   actorID,
-		Timestamp: now,
-	}
+	meta := newEventMetadata(ctx, def.ID, recordID, actorID, now)
 
-	if err := s.appendRecordEvent(ctx, def.ID, recordID, current.Version, eventTypeRecordUpdated, data, meta); err != nil {
-		return Record{}, err
-	}
-
-	if err := s.appendIndexEvent(ctx, def.ID, recordID, false, meta); err != nil {
+	err = s.inUnitOfWork(ctx, func(ctx context.Context) error {
+		if err := s.appendRecordEvent(ctx, def.ID, recordID, current.Version, eventTypeRecordUpdated, data, meta); err != nil {
+			return err
+		}
+		return s.appendIndexEvent(ctx, def.ID, recordID, false, meta)
+	})
+	if err != nil {
 		return Record{}, err
 	}
 
@@ -715,14 +1228,20 @@ func (s *Service) DeleteRecord(ctx context.Context, entityID, recordID string) e
 	}
 
 	if provider, ok := s.providerFor(entityID); ok {
-		return provider.Delete(ctx, def, recordID)
+		ctx, err := s.withActor(ctx)
+		if err != nil {
+			return err
+		}
+		return s.inUnitOfWork(ctx, func(ctx context.Context) error {
+			return provider.Delete(ctx, def, recordID)
+		})
 	}
 
 	return s.deleteRecordDefault(ctx, def, recordID)
 }
 
 func (s *Service) deleteRecordDefault(ctx context.Context, def Definition, recordID string) error {
-	actorID, err := actorIDFromContext(ctx)
+	actorID, err := s.actorID(ctx)
 	if err != nil {
 		return err
 	}
@@ -736,17 +1255,15 @@ func (s *Service) deleteRecordDefault(ctx context.Context, def Definition, recor
 	}
 
 	now := time.Now().UTC()
-	meta := eventMetadata{
-		Entity:    def.ID,
-		RecordID:  recordID,
-		ActorID:   actorID,
-		Timestamp: now,
-	}
+	meta := newEventMetadata(ctx, def.ID, recordID, actorID, now)
 
-	if err := s.appendRecordEvent(ctx, def.ID, recordID, current.Version, eventTypeRecordDeleted, nil, meta); err != nil {
-		return err
-	}
-	if err := s.appendIndexEvent(ctx, def.ID, recordID, true, meta); err != nil {
+	err = s.inUnitOfWork(ctx, func(ctx context.Context) error {
+		if err := s.appendRecordEvent(ctx, def.ID, recordID, current.Version, eventTypeRecordDeleted, nil, meta); err != nil {
+			return err
+		}
+		return s.appendIndexEvent(ctx, def.ID, recordID, true, meta)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -755,39 +1272,130 @@ func (s *Service) deleteRecordDefault(ctx context.Context, def Definition, recor
 	return nil
 }
 
-func (s *Service) loadRecord(ctx context.Context, def Definition, recordID string) (Record, error) {
-	streamID := recordStreamID(def.ID, recordID)
-	events, err := s.store.Load(ctx, streamID, 0)
+// RestoreRecord undoes the deletion of a record. Restoring a record that is
+// not deleted fails with ErrNotDeleted. Provider backed entities can only be
+// restored when the provider implements RecordRestorer.
+func (s *Service) RestoreRecord(ctx context.Context, entityID, recordID string) (Record, error) {
+	def, err := s.Definition(entityID)
 	if err != nil {
 		return Record{}, err
 	}
-	if len(events) == 0 {
-		legacyID := legacyRecordStreamID(def.ID, recordID)
-		legacyEvents, legacyErr := s.store.Load(ctx, legacyID, 0)
-		if legacyErr != nil {
-			return Record{}, legacyErr
+
+	if provider, ok := s.providerFor(entityID); ok {
+		restorer, ok := provider.(RecordRestorer)
+		if !ok {
+			return Record{}, fmt.Errorf("provider for %s does not support restoring records", def.ID)
 		}
-		events = legacyEvents
-		if len(events) == 0 {
-			return Record{}, ErrRecordNotFound
+		ctx, err := s.withActor(ctx)
+		if err != nil {
+			return Record{}, err
+		}
+		var record Record
+		err = s.inUnitOfWork(ctx, func(ctx context.Context) error {
+			var err error
+			record, err = restorer.Restore(ctx, def, recordID)
+			return err
+		})
+		if err != nil {
+			return Record{}, err
+		}
+		return record, nil
+	}
+
+	return s.restoreRecordDefault(ctx, def, recordID)
+}
+
+func (s *Service) restoreRecordDefault(ctx context.Context, def Definition, recordID string) (Record, error) {
+	actorID, err := s.actorID(ctx)
+	if err != nil {
+		return Record{}, err
+	}
+
+	current, err := s.loadRecord(ctx, def, recordID)
+	if err != nil {
+		return Record{}, err
+	}
+	if !current.Deleted {
+		return Record{}, fmt.Errorf("%w: %s/%s", ErrNotDeleted, def.ID, recordID)
+	}
+
+	now := time.Now().UTC()
+	meta := newEventMetadata(ctx, def.ID, recordID, actorID, now)
+
+	err = s.inUnitOfWork(ctx, func(ctx context.Context) error {
+		if err := s.appendRecordEvent(ctx, def.ID, recordID, current.Version, eventTypeRecordRestored, nil, meta); err != nil {
+			return err
 		}
+		return s.appendIndexEvent(ctx, def.ID, recordID, false, meta)
+	})
+	if err != nil {
+		return Record{}, err
 	}
 
-	state := Record{
-		Entity: def.ID,
-		ID:     recordID,
-		Data:   make(map[string]any),
+	current.Deleted = false
+	current.UpdatedAt = now
+	if actorID != "" {
+		current.UpdatedBy = actorID
 	}
+	current.Version++
+
+	s.indexRecord(ctx, current)
+	return current, nil
+}
+
+func (s *Service) loadRecord(ctx context.Context, def Definition, recordID string) (Record, error) {
+	if record, ok, err := s.loadRecordFromSnapshot(ctx, def, recordID); err != nil || ok {
+		return record, err
+	}
+
+	streamID, events, err := s.loadRecordEvents(ctx, def, recordID)
+	if err != nil {
+		return Record{}, err
+	}
+	base, err := s.replayBase(ctx, def, recordID, streamID, events)
+	if err != nil {
+		return Record{}, err
+	}
+	return replayRecordFrom(s.payloadCodec(), s.metadataCodec(), def, base, eventsAfter(events, base.Version))
+}
+
+// loadRecordEvents returns the events of a record together with the stream
+// they were read from, falling back to the legacy stream layout.
+func (s *Service) loadRecordEvents(ctx context.Context, def Definition, recordID string) (string, []eventstore.Event, error) {
+	streamID := recordStreamID(def.ID, recordID)
+	events, err := s.store.Load(ctx, streamID, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(events) > 0 {
+		return streamID, events, nil
+	}
+
+	legacyID := legacyRecordStreamID(def.ID, recordID)
+	events, err = s.store.Load(ctx, legacyID, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(events) == 0 {
+		return "", nil, ErrRecordNotFound
+	}
+	return legacyID, events, nil
+}
+
+// replayRecordFrom applies events on top of state, which is either empty or
+// restored from a snapshot.
+func replayRecordFrom(codec, metaCodec PayloadCodec, def Definition, state Record, events []eventstore.Event) (Record, error) {
+	audit := def.auditFields()
 
 	for _, evt := range events {
-		meta, err := decodeEventMetadata(evt.Metadata)
+		meta, err := decodeEventMetadata(metaCodec, evt.Metadata)
 		if err != nil {
 			return Record{}, err
 		}
 
 		switch evt.Type {
 		case eventTypeRecordCreated:
-			payload, err := decodeRecordPayload(evt.Payload)
+			payload, err := decodeRecordPayload(codec, evt.Payload)
 			if err != nil {
 				return Record{}, err
 			}
@@ -797,17 +1405,17 @@ func (s *Service) loadRecord(ctx context.Context, def Definition, recordID strin
 			state.CreatedBy = meta.ActorID
 			state.UpdatedBy = meta.ActorID
 			if meta.ActorID != "" {
-				if _, exists := state.Data["createdBy"]; !exists {
-					state.Data["createdBy"] = meta.ActorID
+				if _, exists := state.Data[audit.CreatedBy]; !exists {
+					state.Data[audit.CreatedBy] = meta.ActorID
 				}
-				if _, exists := state.Data["updatedBy"]; !exists {
-					state.Data["updatedBy"] = meta.ActorID
+				if _, exists := state.Data[audit.UpdatedBy]; !exists {
+					state.Data[audit.UpdatedBy] = meta.ActorID
 				}
 			}
 			state.Version = evt.Version
 			state.Deleted = false
 		case eventTypeRecordUpdated:
-			payload, err := decodeRecordPayload(evt.Payload)
+			payload, err := decodeRecordPayload(codec, evt.Payload)
 			if err != nil {
 				return Record{}, err
 			}
@@ -821,7 +1429,7 @@ func (s *Service) loadRecord(ctx context.Context, def Definition, recordID strin
 			state.UpdatedAt = meta.Timestamp
 			if meta.ActorID != "" {
 				state.UpdatedBy = meta.ActorID
-				state.Data["updatedBy"] = meta.ActorID
+				state.Data[audit.UpdatedBy] = meta.ActorID
 			}
 			state.Version = evt.Version
 		case eventTypeRecordDeleted:
@@ -831,6 +1439,13 @@ func (s *Service) loadRecord(ctx context.Context, def Definition, recordID strin
 				state.UpdatedBy = meta.ActorID
 			}
 			state.Version = evt.Version
+		case eventTypeRecordRestored:
+			state.Deleted = false
+			state.UpdatedAt = meta.Timestamp
+			if meta.ActorID != "" {
+				state.UpdatedBy = meta.ActorID
+			}
+			state.Version = evt.Version
 		default:
 			continue
 		}
@@ -845,6 +1460,10 @@ type indexState struct {
 }
 
 func (s *Service) loadIndex(ctx context.Context, entityID string) ([]indexState, error) {
+	return s.loadIndexEntries(ctx, entityID, false)
+}
+
+func (s *Service) loadIndexEntries(ctx context.Context, entityID string, includeDeleted bool) ([]indexState, error) {
 	streamID := indexStreamID(entityID)
 	events, err := s.store.Load(ctx, streamID, 0)
 	if err != nil {
@@ -879,25 +1498,25 @@ func (s *Service) loadIndex(ctx context.Context, entityID string) ([]indexState,
 
 	result := make([]indexState, 0, len(state))
 	for _, entry := range state {
-		if entry.Deleted {
+		if entry.Deleted && !includeDeleted {
 			continue
 		}
 		result = append(result, entry)
 	}
 
 	sort.Slice(result, func(i, j int) bool {
-		return result[i].RecordID < result[j].RecordID
+		return compareText(result[i].RecordID, result[j].RecordID) < 0
 	})
 	return result, nil
 }
 
-func (s *Service) appendRecordEvent(ctx context.Context, entityID, recordID string, expectedVersion int64, eventType string, data map[string]any, meta eventMetadata) error {
+func (s *Service) appendRecordEvent(ctx context.Context, entityID, recordID string, expectedVersion int64, eventType string, data map[string]any, meta EventMetadata) error {
 	payload := recordPayload{Data: data}
-	payloadBytes, err := json.Marshal(payload)
+	payloadBytes, err := s.payloadCodec().Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("marshal payload: %w", err)
 	}
-	metaBytes, err := json.Marshal(meta)
+	metaBytes, err := s.metadataCodec().Marshal(meta)
 	if err != nil {
 		return fmt.Errorf("marshal metadata: %w", err)
 	}
@@ -909,7 +1528,7 @@ func (s *Service) appendRecordEvent(ctx context.Context, entityID, recordID stri
 		Metadata:      metaBytes,
 	}
 	streamID := recordStreamID(entityID, recordID)
-	if err := s.store.Append(ctx, streamID, expectedVersion, []eventstore.Event{event}); err != nil {
+	if err := s.appendEvents(ctx, streamID, expectedVersion, []eventstore.Event{event}); err != nil {
 		// Preserve concurrency conflict errors for retry logic
 		if errors.Is(err, eventstore.ErrConcurrencyConflict) {
 			return fmt.Errorf("%w: %s/%s at version %d", eventstore.ErrConcurrencyConflict, entityID, recordID, expectedVersion)
@@ -918,12 +1537,15 @@ func (s *Service) appendRecordEvent(ctx context.Context, entityID, recordID stri
 	}
 	legacyID := legacyRecordStreamID(entityID, recordID)
 	if streamID != legacyID {
-		_ = s.store.Append(ctx, legacyID, -1, []eventstore.Event{event})
+		if err := s.appendEvents(ctx, legacyID, -1, []eventstore.Event{event}); err != nil {
+			return fmt.Errorf("append legacy record event: %w", err)
+		}
 	}
+	s.scheduleSnapshot(ctx, entityID, recordID, max(expectedVersion+1, 1))
 	return nil
 }
 
-func (s *Service) appendIndexEvent(ctx context.Context, entityID, recordID string, deleted bool, meta eventMetadata) error {
+func (s *Service) appendIndexEvent(ctx context.Context, entityID, recordID string, deleted bool, meta EventMetadata) error {
 	payload := indexPayload{
 		RecordID: recordID,
 		Deleted:  deleted,
@@ -932,7 +1554,7 @@ func (s *Service) appendIndexEvent(ctx context.Context, entityID, recordID strin
 	if err != nil {
 		return fmt.Errorf("marshal index payload: %w", err)
 	}
-	metaBytes, err := json.Marshal(meta)
+	metaBytes, err := s.metadataCodec().Marshal(meta)
 	if err != nil {
 		return fmt.Errorf("marshal metadata: %w", err)
 	}
@@ -942,17 +1564,19 @@ func (s *Service) appendIndexEvent(ctx context.Context, entityID, recordID strin
 		Payload:       payloadBytes,
 		Metadata:      metaBytes,
 	}
-	if err := s.store.Append(ctx, indexStreamID(entityID), -1, []eventstore.Event{event}); err != nil {
+	if err := s.appendEvents(ctx, indexStreamID(entityID), -1, []eventstore.Event{event}); err != nil {
 		return err
 	}
 	legacyID := legacyIndexStreamID(entityID)
 	if legacyID != indexStreamID(entityID) {
-		_ = s.store.Append(ctx, legacyID, -1, []eventstore.Event{event})
+		if err := s.appendEvents(ctx, legacyID, -1, []eventstore.Event{event}); err != nil {
+			return fmt.Errorf("append legacy index event: %w", err)
+		}
 	}
 	return nil
 }
 
-//func (s *Service) emitEntityNotification(ctx context.Context, eventType string, def Definition, record Record, meta eventMetadata) {
+//func (s *Service) emitEntityNotification(ctx context.Context, eventType string, def Definition, record Record, meta EventMetadata) {
 //	if s.notifier == nil {
 //		return
 //	}
@@ -972,6 +1596,20 @@ func (s *Service) appendIndexEvent(ctx context.Context, entityID, recordID strin
 //
 //}
 
+// filterFieldType returns the declared type of the field a filter path on
+// entityID points at, following references.
+func (s *Service) filterFieldType(entityID, fieldPath string) (entityPb.FieldType, bool) {
+	def, err := s.Definition(entityID)
+	if err != nil {
+		return entityPb.FieldType_FIELD_TYPE_UNSPECIFIED, false
+	}
+	steps, err := s.resolveRelationshipPath(def, strings.Split(strings.TrimSpace(fieldPath), "."))
+	if err != nil || len(steps) == 0 {
+		return entityPb.FieldType_FIELD_TYPE_UNSPECIFIED, false
+	}
+	return steps[len(steps)-1].Field.Type, true
+}
+
 func (s *Service) normalizeFilters(def Definition, filters []Filter) ([]normalizedFilter, error) {
 	out := make([]normalizedFilter, 0, len(filters))
 	for _, filter := range filters {
@@ -1000,9 +1638,15 @@ func (s *Service) normalizeFilters(def Definition, filters []Filter) ([]normaliz
 			value   any
 			convErr error
 		)
-		if operator == entityPb.FilterOperator_FILTER_OPERATOR_IN {
+		switch operator {
+		case entityPb.FilterOperator_FILTER_OPERATOR_IN:
 			value, convErr = coerceInValue(targetField.Type, filter.Value)
-		} else {
+		case entityPb.FilterOperator_FILTER_OPERATOR_BETWEEN:
+			value, convErr = coerceRangeValue(targetField.Type, filter.Value)
+		case entityPb.FilterOperator_FILTER_OPERATOR_IS_NULL,
+			entityPb.FilterOperator_FILTER_OPERATOR_IS_NOT_NULL:
+			// Presence checks take no value.
+		default:
 			value, convErr = coerceValue(targetField.Type, filter.Value)
 		}
 		if convErr != nil {
@@ -1029,6 +1673,13 @@ func (nf normalizedFilter) targetField() FieldDefinition {
 	return nf.Steps[len(nf.Steps)-1].Field
 }
 
+// isIDField reports whether the filter targets a primary key or a reference,
+// whose values are compared in canonical id form.
+func (nf normalizedFilter) isIDField() bool {
+	step := nf.Steps[len(nf.Steps)-1]
+	return step.Field.Reference != nil || step.Field.ID == step.Entity.PrimaryKey
+}
+
 func (nf normalizedFilter) isNested() bool {
 	return len(nf.Steps) > 1
 }
@@ -1054,29 +1705,146 @@ func coerceInValue(fieldType entityPb.FieldType, value any) (any, error) {
 		for i, item := range v {
 			items[i] = item
 		}
-	case string:
-		raw := strings.TrimSpace(v)
-		if raw == "" {
-			return []any{}, nil
+	case string:
+		raw := strings.TrimSpace(v)
+		if raw == "" {
+			return []any{}, nil
+		}
+		parts := strings.Split(raw, ",")
+		items = make([]any, 0, len(parts))
+		for _, part := range parts {
+			items = append(items, strings.TrimSpace(part))
+		}
+	default:
+		items = []any{v}
+	}
+
+	normalized := make([]any, 0, len(items))
+	for _, item := range items {
+		coerced, err := coerceValue(fieldType, item)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, coerced)
+	}
+	return normalized, nil
+}
+
+// coerceRangeValue coerces a BETWEEN value, given as a two element list or
+// "min,max", into its lower and upper bound.
+func coerceRangeValue(fieldType entityPb.FieldType, value any) (any, error) {
+	bounds, err := coerceInValue(fieldType, value)
+	if err != nil {
+		return nil, err
+	}
+	items := bounds.([]any)
+	if len(items) != 2 {
+		return nil, fmt.Errorf("expected two bounds, got %d", len(items))
+	}
+	if fieldType == entityPb.FieldType_FIELD_TYPE_DATETIME {
+		for _, item := range items {
+			if _, err := time.Parse(time.RFC3339Nano, asString(item)); err != nil {
+				return nil, fmt.Errorf("expected RFC 3339 bounds, got %q", asString(item))
+			}
+		}
+	}
+	return bounds, nil
+}
+
+// resolveFilters normalizes filters and replaces filters on related
+// entities by filters on the local reference fields. It reports true when a
+// related filter matches no record, so nothing can match.
+func (s *Service) resolveFilters(ctx context.Context, def Definition, filters []Filter) ([]Filter, []normalizedFilter, bool, error) {
+	normFilters, err := s.normalizeFilters(def, filters)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	localFilters := make([]normalizedFilter, 0, len(normFilters))
+	nestedFilters := make([]normalizedFilter, 0)
+	for _, nf := range normFilters {
+		if nf.isNested() {
+			nestedFilters = append(nestedFilters, nf)
+			continue
+		}
+		localFilters = append(localFilters, nf)
+	}
+
+	generatedFilters, emptyResult, err := s.buildNestedFilters(ctx, nestedFilters)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if len(nestedFilters) > 0 && emptyResult {
+		return nil, nil, true, nil
+	}
+
+	sanitizedFilters := make([]Filter, 0, len(localFilters)+len(generatedFilters))
+	for _, nf := range localFilters {
+		sanitizedFilters = append(sanitizedFilters, Filter{
+			FieldID:  nf.targetField().ID,
+			Operator: nf.Operator,
+			Value:    nf.Value,
+		})
+	}
+	sanitizedFilters = append(sanitizedFilters, generatedFilters...)
+	if len(generatedFilters) == 0 {
+		return sanitizedFilters, localFilters, false, nil
+	}
+	normFilters, err = s.normalizeFilters(def, sanitizedFilters)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return sanitizedFilters, normFilters, false, nil
+}
+
+// resolveOrGroups normalizes OR groups. Nested filters are replaced by a
+// filter on the local reference field, and dropped from their group when no
+// referenced record matches. The returned flag reports a group left without
+// filters, which matches nothing. Empty input groups are ignored.
+func (s *Service) resolveOrGroups(ctx context.Context, def Definition, groups [][]Filter) ([][]normalizedFilter, [][]Filter, bool, error) {
+	var (
+		normalized [][]normalizedFilter
+		sanitized  [][]Filter
+	)
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		normGroup, err := s.normalizeFilters(def, group)
+		if err != nil {
+			return nil, nil, false, err
 		}
-		parts := strings.Split(raw, ",")
-		items = make([]any, 0, len(parts))
-		for _, part := range parts {
-			items = append(items, strings.TrimSpace(part))
+
+		filters := make([]Filter, 0, len(normGroup))
+		for _, nf := range normGroup {
+			if !nf.isNested() {
+				filters = append(filters, Filter{
+					FieldID:  nf.targetField().ID,
+					Operator: nf.Operator,
+					Value:    nf.Value,
+				})
+				continue
+			}
+			generated, empty, err := s.buildNestedFilters(ctx, []normalizedFilter{nf})
+			if err != nil {
+				return nil, nil, false, err
+			}
+			if !empty {
+				filters = append(filters, generated...)
+			}
+		}
+		if len(filters) == 0 {
+			return nil, nil, true, nil
 		}
-	default:
-		items = []any{v}
-	}
 
-	normalized := make([]any, 0, len(items))
-	for _, item := range items {
-		coerced, err := coerceValue(fieldType, item)
+		normGroup, err = s.normalizeFilters(def, filters)
 		if err != nil {
-			return nil, err
+			return nil, nil, false, err
 		}
-		normalized = append(normalized, coerced)
+		normalized = append(normalized, normGroup)
+		sanitized = append(sanitized, filters)
 	}
-	return normalized, nil
+	return normalized, sanitized, false, nil
 }
 
 func (s *Service) buildNestedFilters(ctx context.Context, filters []normalizedFilter) ([]Filter, bool, error) {
@@ -1233,7 +2001,7 @@ func (s *Service) collectAllRecords(ctx context.Context, entityID string, filter
 		if provider, ok := s.providerFor(entityID); ok {
 			batch, nextToken, listErr = provider.List(ctx, def, opts)
 		} else {
-			batch, nextToken, listErr = s.listRecordsDefault(ctx, def, normFilters, opts)
+			batch, nextToken, _, listErr = s.listRecordsDefault(ctx, def, normFilters, nil, opts)
 		}
 		if listErr != nil {
 			return nil, listErr
@@ -1254,6 +2022,10 @@ func (s *Service) resolveRelationshipPath(def Definition, segments []string) ([]
 		return nil, fmt.Errorf("invalid empty field path for entity %s", def.ID)
 	}
 
+	if maxDepth := s.relationshipDepth(); len(segments)-1 > maxDepth {
+		return nil, fmt.Errorf("path %s exceeds max relationship depth %d", strings.Join(segments, "."), maxDepth)
+	}
+
 	current := def
 	steps := make([]relationshipStep, 0, len(segments))
 
@@ -1293,6 +2065,22 @@ func (s *Service) resolveRelationshipPath(def Definition, segments []string) ([]
 	return steps, nil
 }
 
+// defaultMaxRelationshipDepth bounds nested filter paths unless configured.
+const defaultMaxRelationshipDepth = 3
+
+// SetMaxRelationshipDepth limits how many references a nested filter path such
+// as "order.customer.country" may follow. Defaults to 3.
+func (s *Service) SetMaxRelationshipDepth(depth int) {
+	s.maxDepth = depth
+}
+
+func (s *Service) relationshipDepth() int {
+	if s.maxDepth <= 0 {
+		return defaultMaxRelationshipDepth
+	}
+	return s.maxDepth
+}
+
 func findFieldForSegment(def Definition, segment string) (FieldDefinition, error) {
 	for _, field := range def.Fields {
 		if matchesFieldSegment(field, segment) {
@@ -1340,7 +2128,7 @@ func extractFieldValues(records []Record, fieldID string, primaryKey string) str
 	for _, record := range records {
 		if normalized != "" {
 			if value, ok := record.Data[normalized]; ok {
-				if str := asString(value); str != "" {
+				if str := canonicalID(value); str != "" {
 					result.add(str)
 					continue
 				}
@@ -1490,13 +2278,62 @@ func applyFilters(records []Record, filters []normalizedFilter) []Record {
 	return result
 }
 
+func applyOrGroups(records []Record, groups [][]normalizedFilter) []Record {
+	if len(groups) == 0 {
+		return records
+	}
+	result := make([]Record, 0, len(records))
+	for _, record := range records {
+		if matchesOrGroups(record.Data, groups) {
+			result = append(result, record)
+		}
+	}
+	return result
+}
+
+// matchesOrGroups reports whether data matches at least one filter of every
+// group.
+func matchesOrGroups(data map[string]any, groups [][]normalizedFilter) bool {
+	for _, group := range groups {
+		matched := false
+		for _, filter := range group {
+			if matchesFilters(data, []normalizedFilter{filter}) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
 func matchesFilters(data map[string]any, filters []normalizedFilter) bool {
 	for _, filter := range filters {
 		field := filter.targetField()
 		value, ok := data[field.ID]
+		switch filter.Operator {
+		case entityPb.FilterOperator_FILTER_OPERATOR_IS_NULL:
+			if ok && !isNullValue(value) {
+				return false
+			}
+			continue
+		case entityPb.FilterOperator_FILTER_OPERATOR_IS_NOT_NULL:
+			if !ok || isNullValue(value) {
+				return false
+			}
+			continue
+		}
 		if !ok {
 			return false
 		}
+		if filter.isIDField() {
+			if !evaluateIDFilter(value, filter.Operator, filter.Value) {
+				return false
+			}
+			continue
+		}
 		if !evaluateFilter(value, filter.Operator, filter.Value) {
 			return false
 		}
@@ -1504,6 +2341,56 @@ func matchesFilters(data map[string]any, filters []normalizedFilter) bool {
 	return true
 }
 
+// isNullValue reports whether a present field value counts as null for the
+// IS_NULL and IS_NOT_NULL operators.
+func isNullValue(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	default:
+		return false
+	}
+}
+
+// evaluateIDFilter is evaluateFilter for id fields: equality and membership
+// compare canonical ids, so 42 (float64), "42" and "42.0" match each other.
+func evaluateIDFilter(recordValue any, operator entityPb.FilterOperator, filterValue any) bool {
+	switch operator {
+	case entityPb.FilterOperator_FILTER_OPERATOR_EQ:
+		return compareIDs(recordValue, filterValue)
+	case entityPb.FilterOperator_FILTER_OPERATOR_NE:
+		return !compareIDs(recordValue, filterValue)
+	case entityPb.FilterOperator_FILTER_OPERATOR_IN:
+		switch candidates := filterValue.(type) {
+		case []any:
+			for _, candidate := range candidates {
+				if compareIDs(recordValue, candidate) {
+					return true
+				}
+			}
+			return false
+		case []string:
+			for _, candidate := range candidates {
+				if compareIDs(recordValue, candidate) {
+					return true
+				}
+			}
+			return false
+		default:
+			return compareIDs(recordValue, filterValue)
+		}
+	default:
+		return evaluateFilter(recordValue, operator, filterValue)
+	}
+}
+
+func compareIDs(a, b any) bool {
+	ca, cb := canonicalID(a), canonicalID(b)
+	return ca != "" && strings.EqualFold(ca, cb)
+}
+
 func evaluateFilter(recordValue any, operator entityPb.FilterOperator, filterValue any) bool {
 	switch operator {
 	case entityPb.FilterOperator_FILTER_OPERATOR_EQ:
@@ -1519,22 +2406,22 @@ func evaluateFilter(recordValue any, operator entityPb.FilterOperator, filterVal
 		entityPb.FilterOperator_FILTER_OPERATOR_LT,
 		entityPb.FilterOperator_FILTER_OPERATOR_LTE:
 		return compareNumbers(recordValue, filterValue, operator)
+	case entityPb.FilterOperator_FILTER_OPERATOR_BETWEEN:
+		return compareBetween(recordValue, filterValue)
 	default:
 		return true
 	}
 }
 
 func compareEquality(a, b any) bool {
+	if isNumber(a) && isNumber(b) {
+		cmp, ok := compareNumeric(a, b)
+		return ok && cmp == 0
+	}
 	switch va := a.(type) {
 	case string:
 		vb, ok := b.(string)
 		return ok && strings.EqualFold(va, vb)
-	case float64:
-		vb, ok := b.(float64)
-		return ok && va == vb
-	case int:
-		vb, ok := b.(int)
-		return ok && va == vb
 	case bool:
 		vb, ok := b.(bool)
 		return ok && va == vb
@@ -1587,28 +2474,92 @@ func compareIn(a, b any) bool {
 }
 
 func compareNumbers(a, b any, op entityPb.FilterOperator) bool {
-	av, ok := toFloat64(a)
-	if !ok {
-		return false
-	}
-	bv, ok := toFloat64(b)
+	cmp, ok := compareNumeric(a, b)
 	if !ok {
 		return false
 	}
 	switch op {
 	case entityPb.FilterOperator_FILTER_OPERATOR_GT:
-		return av > bv
+		return cmp > 0
 	case entityPb.FilterOperator_FILTER_OPERATOR_GTE:
-		return av >= bv
+		return cmp >= 0
 	case entityPb.FilterOperator_FILTER_OPERATOR_LT:
-		return av < bv
+		return cmp < 0
 	case entityPb.FilterOperator_FILTER_OPERATOR_LTE:
-		return av <= bv
+		return cmp <= 0
 	default:
 		return false
 	}
 }
 
+// compareBetween reports whether a lies within the inclusive bounds held by
+// b. Numbers are compared numerically and datetimes chronologically.
+func compareBetween(a, b any) bool {
+	bounds, ok := b.([]any)
+	if !ok || len(bounds) != 2 {
+		return false
+	}
+	lower, ok := compareRange(a, bounds[0])
+	if !ok || lower < 0 {
+		return false
+	}
+	upper, ok := compareRange(a, bounds[1])
+	return ok && upper <= 0
+}
+
+func compareRange(a, b any) (int, bool) {
+	if isNumber(a) || isNumber(b) {
+		return compareNumeric(a, b)
+	}
+	as, aok := a.(string)
+	bs, bok := b.(string)
+	if !aok || !bok {
+		return 0, false
+	}
+	at, err := time.Parse(time.RFC3339Nano, as)
+	if err != nil {
+		return 0, false
+	}
+	bt, err := time.Parse(time.RFC3339Nano, bs)
+	if err != nil {
+		return 0, false
+	}
+	return at.Compare(bt), true
+}
+
+// compareNumeric compares two numeric values. Integers are compared exactly so
+// values beyond 2^53 are not conflated by float64 rounding.
+func compareNumeric(a, b any) (int, bool) {
+	if ai, ok := toInt64(a); ok {
+		if bi, ok := toInt64(b); ok {
+			switch {
+			case ai < bi:
+				return -1, true
+			case ai > bi:
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+	}
+	av, ok := toFloat64(a)
+	if !ok {
+		return 0, false
+	}
+	bv, ok := toFloat64(b)
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case av < bv:
+		return -1, true
+	case av > bv:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
 func sortRecords(records []Record, field string, direction entityPb.SortDirection) {
 	if len(records) <= 1 {
 		return
@@ -1637,21 +2588,13 @@ func fetchSortable(record Record, field string) any {
 func compareSortable(a, b any) int {
 	switch va := a.(type) {
 	case string:
-		vb := fmt.Sprintf("%v", b)
-		return strings.Compare(strings.ToLower(va), strings.ToLower(vb))
-	case float64:
-		vb, ok := toFloat64(b)
+		return compareText(va, fmt.Sprintf("%v", b))
+	case float64, json.Number:
+		cmp, ok := compareNumeric(va, b)
 		if !ok {
 			return 0
 		}
-		switch {
-		case va < vb:
-			return -1
-		case va > vb:
-			return 1
-		default:
-			return 0
-		}
+		return cmp
 	case time.Time:
 		vb, ok := b.(time.Time)
 		if !ok {
@@ -1668,6 +2611,16 @@ func compareSortable(a, b any) int {
 	return strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
 }
 
+// compareText orders strings case-insensitively. Strings differing only in
+// case fall back to their exact spelling, so record ids keep one order in the
+// index, in sorted lists and across page tokens.
+func compareText(a, b string) int {
+	if c := strings.Compare(strings.ToLower(a), strings.ToLower(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
 func clampPageSize(size int) int {
 	switch {
 	case size <= 0:
@@ -1679,6 +2632,51 @@ func clampPageSize(size int) int {
 	}
 }
 
+// checkPageOffset applies the pagination policy of def to an offset page
+// token. Keyset tokens and internal full scans are always accepted.
+func (s *Service) checkPageOffset(ctx context.Context, def Definition, token string) error {
+	policy := def.Pagination
+	if policy.MaxOffset <= 0 || strings.HasPrefix(token, keysetTokenPrefix) || isFullScan(ctx) {
+		return nil
+	}
+	offset, err := parsePageToken(token)
+	if err != nil || offset <= policy.MaxOffset {
+		// Malformed tokens are rejected by the list itself.
+		return nil
+	}
+	if policy.Reject {
+		recordDeepOffset(ctx, def.ID, DeepOffsetReject)
+		return fmt.Errorf("%w: offset %d exceeds the limit of %d for %s; %s",
+			ErrDeepPagination, offset, policy.MaxOffset, def.ID, s.deepOffsetHint(def))
+	}
+	recordDeepOffset(ctx, def.ID, DeepOffsetWarn)
+	slog.Warn("deep entity list offset", "entity", def.ID, "offset", offset, "max_offset", policy.MaxOffset)
+	return nil
+}
+
+// deepOffsetHint tells clients how to reach records past the offset limit.
+// Keyset tokens are only issued by streaming lists, see SetStreamingList.
+func (s *Service) deepOffsetHint(def Definition) string {
+	if !s.streamingList {
+		return "narrow the filters to page through fewer records"
+	}
+	return fmt.Sprintf("sort on %s without a total count and follow next_page_token to page by key", def.PrimaryKey)
+}
+
+type fullScanKey struct{}
+
+// withFullScan marks ctx as an internal scan that pages through every record
+// of an entity, such as pivot and stats aggregation. Full scans are exempt
+// from the deep offset policy.
+func withFullScan(ctx context.Context) context.Context {
+	return context.WithValue(ctx, fullScanKey{}, true)
+}
+
+func isFullScan(ctx context.Context) bool {
+	scan, _ := ctx.Value(fullScanKey{}).(bool)
+	return scan
+}
+
 func parsePageToken(token string) (int, error) {
 	if token == "" {
 		return 0, nil
@@ -1700,6 +2698,10 @@ func sanitizePayload(def Definition, payload map[string]any, allowMissing bool)
 
 	for _, field := range def.Fields {
 		value, exists := payload[field.ID]
+		// Defaults only apply on create so updates never clobber stored values.
+		if !exists && !allowMissing && field.Default != nil {
+			value, exists = field.Default.value(), true
+		}
 		if !exists {
 			if field.Required && !allowMissing {
 				errs = append(errs, ValidationError{Field: field.ID, Message: "is required"})
@@ -1707,7 +2709,7 @@ func sanitizePayload(def Definition, payload map[string]any, allowMissing bool)
 			continue
 		}
 
-		if isAuditField(field.ID) {
+		if def.isAuditField(field.ID) {
 			continue
 		}
 
@@ -1716,7 +2718,10 @@ func sanitizePayload(def Definition, payload map[string]any, allowMissing bool)
 			continue
 		}
 
-		normalized, err := coerceValue(field.Type, value)
+		normalized, err := coerceFieldValue(field, value)
+		if err == nil {
+			err = field.Validation.Validate(normalized)
+		}
 		if err != nil {
 			errs = append(errs, ValidationError{Field: field.ID, Message: err.Error()})
 			continue
@@ -1730,7 +2735,7 @@ func sanitizePayload(def Definition, payload map[string]any, allowMissing bool)
 
 	// Preserve unknown fields for forward compatibility but copy as-is.
 	for key, value := range payload {
-		if _, exists := result[key]; !exists && !isAuditField(key) {
+		if _, exists := result[key]; !exists && !def.isAuditField(key) {
 			result[key] = value
 		}
 	}
@@ -1738,31 +2743,78 @@ func sanitizePayload(def Definition, payload map[string]any, allowMissing bool)
 	return result, nil
 }
 
-func isAuditField(fieldID string) bool {
-	switch strings.ToLower(strings.TrimSpace(fieldID)) {
-	case "createdby", "updatedby":
-		return true
-	default:
-		return false
+func snapshotForHistory(def Definition, state map[string]any) map[string]any {
+	fields := make([]string, 0, len(def.Fields))
+	for _, field := range def.Fields {
+		fields = append(fields, field.ID)
 	}
+	return projectFields(def, state, fields)
 }
 
-func snapshotForHistory(def Definition, state map[string]any) map[string]any {
+// projectFields copies the primary key and the given fields from state. A nil
+// fields slice returns state unchanged.
+func projectFields(def Definition, state map[string]any, fields []string) map[string]any {
+	if fields == nil {
+		return state
+	}
 	if len(state) == 0 {
 		return map[string]any{}
 	}
-	snapshot := make(map[string]any, len(def.Fields)+1)
+	projected := make(map[string]any, len(fields)+1)
 	if def.PrimaryKey != "" {
 		if value, ok := state[def.PrimaryKey]; ok {
-			snapshot[def.PrimaryKey] = value
+			projected[def.PrimaryKey] = value
 		}
 	}
-	for _, field := range def.Fields {
-		if value, ok := state[field.ID]; ok {
-			snapshot[field.ID] = value
+	for _, fieldID := range fields {
+		if value, ok := state[fieldID]; ok {
+			projected[fieldID] = value
+		}
+	}
+	return projected
+}
+
+// resolveFields validates requested field ids against the definition. It
+// returns nil when no fields were requested.
+func resolveFields(def Definition, fields []string) ([]string, error) {
+	resolved := make([]string, 0, len(fields))
+	seen := make(map[string]struct{}, len(fields))
+	for _, fieldID := range fields {
+		fieldID = strings.TrimSpace(fieldID)
+		if fieldID == "" {
+			continue
+		}
+		if _, ok := def.Field(fieldID); !ok && fieldID != def.PrimaryKey {
+			return nil, fmt.Errorf("%w: unknown field %s", ErrInvalidFields, fieldID)
 		}
+		if _, ok := seen[fieldID]; ok {
+			continue
+		}
+		seen[fieldID] = struct{}{}
+		resolved = append(resolved, fieldID)
+	}
+	if len(resolved) == 0 {
+		return nil, nil
+	}
+	return resolved, nil
+}
+
+// ParseFields splits a comma-separated field list such as "name,email".
+func ParseFields(raw string) []string {
+	if strings.TrimSpace(raw) == "" {
+		return nil
 	}
-	return snapshot
+	return strings.Split(raw, ",")
+}
+
+// ParseFieldList flattens repeated values that may themselves be
+// comma-separated, so both ?expand=a&expand=b and ?expand=a,b work.
+func ParseFieldList(values []string) []string {
+	var out []string
+	for _, value := range values {
+		out = append(out, ParseFields(value)...)
+	}
+	return out
 }
 
 func normalizePivotKey(value string) string {
@@ -1773,6 +2825,34 @@ func normalizePivotKey(value string) string {
 	return trimmed
 }
 
+// coerceFieldValue coerces a payload value for field. Lists given for
+// repeated fields are coerced item by item.
+func coerceFieldValue(field FieldDefinition, value any) (any, error) {
+	var items []any
+	switch v := value.(type) {
+	case []any:
+		items = v
+	case []string:
+		items = make([]any, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+	}
+	if !field.Repeated || items == nil {
+		return coerceValue(field.Type, value)
+	}
+
+	normalized := make([]any, 0, len(items))
+	for _, item := range items {
+		coerced, err := coerceValue(field.Type, item)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, coerced)
+	}
+	return normalized, nil
+}
+
 func coerceValue(fieldType entityPb.FieldType, value any) (any, error) {
 	switch fieldType {
 	case entityPb.FieldType_FIELD_TYPE_STRING,
@@ -1799,6 +2879,10 @@ func coerceValue(fieldType entityPb.FieldType, value any) (any, error) {
 		}
 		return nil, fmt.Errorf("expected boolean value")
 	case entityPb.FieldType_FIELD_TYPE_NUMBER:
+		// Integers are kept as json.Number so 64-bit ids survive storage.
+		if number, ok := toInt64(value); ok {
+			return json.Number(strconv.FormatInt(number, 10)), nil
+		}
 		if number, ok := toFloat64(value); ok {
 			return number, nil
 		}
@@ -1844,6 +2928,98 @@ func asString(value any) string {
 	}
 }
 
+// canonicalID renders an id in one string form regardless of how it was
+// decoded: whole numbers are written without a fraction or exponent, so
+// float64(1234567), json.Number("1234567") and "1234567.0" all become
+// "1234567". Other values fall back to asString.
+func canonicalID(value any) string {
+	switch v := value.(type) {
+	case float64:
+		return canonicalFloat(v)
+	case float32:
+		return canonicalFloat(float64(v))
+	case json.Number:
+		return canonicalNumericString(string(v))
+	case string:
+		return canonicalNumericString(strings.TrimSpace(v))
+	}
+	if number, ok := toInt64(value); ok {
+		return strconv.FormatInt(number, 10)
+	}
+	return asString(value)
+}
+
+func canonicalFloat(v float64) string {
+	if number, ok := toInt64(v); ok {
+		return strconv.FormatInt(number, 10)
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// canonicalNumericString drops an all-zero fraction from an integer string.
+// Exponent forms such as "1.234567e+06", which %v writes for float64 ids, are
+// turned into integers when they are exact.
+func canonicalNumericString(s string) string {
+	if strings.ContainsAny(s, "eE") {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			if number, ok := toInt64(f); ok {
+				return strconv.FormatInt(number, 10)
+			}
+		}
+		return s
+	}
+	whole, frac, ok := strings.Cut(s, ".")
+	if !ok || frac == "" || strings.Trim(frac, "0") != "" {
+		return s
+	}
+	if _, err := strconv.ParseInt(whole, 10, 64); err != nil {
+		return s
+	}
+	return whole
+}
+
+func isNumber(value any) bool {
+	switch value.(type) {
+	case float64, float32, int, int64, int32, uint64, uint32, json.Number:
+		return true
+	default:
+		return false
+	}
+}
+
+// toInt64 returns value as an exact integer. Floats qualify only when they are
+// integral and within the range float64 represents exactly.
+func toInt64(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case int32:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	case float64:
+		if v != math.Trunc(v) || math.Abs(v) > 1<<53 {
+			return 0, false
+		}
+		return int64(v), true
+	case json.Number:
+		parsed, err := strconv.ParseInt(string(v), 10, 64)
+		return parsed, err == nil
+	case string:
+		parsed, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return parsed, err == nil
+	default:
+		return 0, false
+	}
+}
+
 func toFloat64(value any) (float64, bool) {
 	switch v := value.(type) {
 	case float64:
@@ -1863,6 +3039,12 @@ func toFloat64(value any) (float64, bool) {
 		return float64(v), true
 	case uint32:
 		return float64(v), true
+	case json.Number:
+		parsed, err := v.Float64()
+		if err != nil {
+			return 0, false
+		}
+		return parsed, true
 	case string:
 		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
 		if err != nil {
@@ -0,0 +1,2478 @@
+package entities
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	entityPb "github.com/tonica-go/tonica/pkg/tonica/proto/entities"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/tonica-go/tonica/pkg/tonica/identity"
+	"github.com/tonica-go/tonica/pkg/tonica/modules/eventstore"
+)
+
+// memoryStore is an in-memory eventstore.Store for tests.
+type memoryStore struct {
+	mu      sync.Mutex
+	streams map[string][]eventstore.Event
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{streams: make(map[string][]eventstore.Event)}
+}
+
+func (m *memoryStore) Append(_ context.Context, streamID string, expectedVersion int64, events []eventstore.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stream := m.streams[streamID]
+	var version int64
+	if len(stream) > 0 {
+		version = stream[len(stream)-1].Version
+	}
+	if expectedVersion >= 0 && version > 0 && version != expectedVersion {
+		return eventstore.ErrConcurrencyConflict
+	}
+	for _, evt := range events {
+		version++
+		evt.AggregateID = streamID
+		evt.Version = version
+		stream = append(stream, evt)
+	}
+	m.streams[streamID] = stream
+	return nil
+}
+
+func (m *memoryStore) Load(_ context.Context, streamID string, fromVersion int64) ([]eventstore.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var out []eventstore.Event
+	for _, evt := range m.streams[streamID] {
+		if evt.Version >= fromVersion {
+			out = append(out, evt)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryStore) Compact(_ context.Context, streamID string, version int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stream := m.streams[streamID]
+	for i, evt := range stream {
+		if evt.Version == version {
+			m.streams[streamID] = stream[i:]
+			return int64(i), nil
+		}
+	}
+	return 0, eventstore.ErrVersionNotFound
+}
+
+func (m *memoryStore) Close(context.Context) error {
+	return nil
+}
+
+const testDefinition = `
+id: account
+primary_key: id
+fields:
+  - id: id
+    type: string
+  - id: external_id
+    type: number
+    filter:
+      operators: [eq, gt]
+  - id: name
+    type: string
+`
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+
+	def, err := parseDefinition([]byte(testDefinition))
+	require.NoError(t, err)
+
+	return &Service{
+		defs:      map[string]Definition{def.ID: def},
+		store:     newMemoryStore(),
+		providers: make(map[string]Provider),
+	}
+}
+
+func testContext() context.Context {
+	return identity.NewContext(context.Background(), identity.NewIdentity("user-1"))
+}
+
+func TestService_LargeIntegerPrecision(t *testing.T) {
+	const largeID = "9007199254740993" // 2^53 + 1, not representable as float64
+
+	svc := newTestService(t)
+	ctx := testContext()
+
+	_, err := svc.CreateRecord(ctx, "account", map[string]any{
+		"id":          "acc-1",
+		"external_id": json.Number(largeID),
+		"name":        "first",
+	})
+	require.NoError(t, err)
+
+	// 2^53 collides with 2^53 + 1 when compared as float64
+	_, err = svc.CreateRecord(ctx, "account", map[string]any{
+		"id":          "acc-2",
+		"external_id": json.Number("9007199254740992"),
+		"name":        "second",
+	})
+	require.NoError(t, err)
+
+	record, err := svc.GetRecord(ctx, "account", "acc-1")
+	require.NoError(t, err)
+	assert.Equal(t, json.Number(largeID), record.Data["external_id"])
+
+	records, _, err := svc.ListRecords(ctx, "account", ListOptions{
+		Filters: []Filter{{
+			FieldID:  "external_id",
+			Operator: entityPb.FilterOperator_FILTER_OPERATOR_EQ,
+			Value:    largeID,
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "acc-1", records[0].ID)
+
+	records, _, err = svc.ListRecords(ctx, "account", ListOptions{
+		Filters: []Filter{{
+			FieldID:  "external_id",
+			Operator: entityPb.FilterOperator_FILTER_OPERATOR_GT,
+			Value:    "9007199254740992",
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "acc-1", records[0].ID)
+}
+
+func TestService_SparseFieldsets(t *testing.T) {
+	svc := newTestService(t)
+	ctx := testContext()
+
+	_, err := svc.CreateRecord(ctx, "account", map[string]any{
+		"id":          "acc-1",
+		"external_id": json.Number("42"),
+		"name":        "first",
+	})
+	require.NoError(t, err)
+
+	records, _, err := svc.ListRecords(ctx, "account", ListOptions{Fields: ParseFields("name")})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, map[string]any{"id": "acc-1", "name": "first"}, records[0].Data)
+
+	// Filters still apply to fields left out of the projection.
+	records, _, err = svc.ListRecords(ctx, "account", ListOptions{
+		Filters: []Filter{{
+			FieldID:  "external_id",
+			Operator: entityPb.FilterOperator_FILTER_OPERATOR_EQ,
+			Value:    "42",
+		}},
+		Fields: []string{"name"},
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.NotContains(t, records[0].Data, "external_id")
+
+	record, err := svc.GetRecordFields(ctx, "account", "acc-1", ParseFields(" external_id ,external_id"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": "acc-1", "external_id": json.Number("42")}, record.Data)
+
+	_, err = svc.GetRecordFields(ctx, "account", "acc-1", []string{"missing"})
+	assert.ErrorIs(t, err, ErrInvalidFields)
+
+	_, err = (&grpcHandler{svc: svc}).GetRecord(ctx, &entityPb.GetRecordRequest{Entity: "account", Id: "acc-1", Fields: "missing"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = (&grpcHandler{svc: svc}).ListRecords(ctx, &entityPb.ListRecordsRequest{Entity: "account", Fields: "missing"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestService_ListTotalCount(t *testing.T) {
+	svc := newTestService(t)
+	ctx := testContext()
+
+	for _, id := range []string{"acc-1", "acc-2", "acc-3"} {
+		_, err := svc.CreateRecord(ctx, "account", map[string]any{"id": id, "name": "n-" + id})
+		require.NoError(t, err)
+	}
+
+	result, err := svc.ListRecordsPage(ctx, "account", ListOptions{PageSize: 2})
+	require.NoError(t, err)
+	assert.Len(t, result.Records, 2)
+	assert.Nil(t, result.TotalCount)
+
+	result, err = svc.ListRecordsPage(ctx, "account", ListOptions{PageSize: 2, IncludeTotalCount: true})
+	require.NoError(t, err)
+	assert.Len(t, result.Records, 2)
+	require.NotNil(t, result.TotalCount)
+	assert.EqualValues(t, 3, *result.TotalCount)
+
+	result, err = svc.ListRecordsPage(ctx, "account", ListOptions{
+		Filters: []Filter{{
+			FieldID:  "name",
+			Operator: entityPb.FilterOperator_FILTER_OPERATOR_NE,
+			Value:    "n-acc-1",
+		}},
+		PageSize:          1,
+		PageToken:         "1",
+		IncludeTotalCount: true,
+	})
+	require.NoError(t, err)
+	assert.Len(t, result.Records, 1)
+	require.NotNil(t, result.TotalCount)
+	assert.EqualValues(t, 2, *result.TotalCount)
+}
+
+// isRecordStream reports whether streamID holds the events of one record
+// rather than an entity index.
+func isRecordStream(streamID string) bool {
+	return !strings.HasPrefix(streamID, "idx:") && !strings.HasSuffix(streamID, ":index")
+}
+
+// countingStore counts the record streams loaded through it.
+type countingStore struct {
+	eventstore.Store
+	loads int
+}
+
+func (c *countingStore) Load(ctx context.Context, streamID string, fromVersion int64) ([]eventstore.Event, error) {
+	if isRecordStream(streamID) {
+		c.loads++
+	}
+	return c.Store.Load(ctx, streamID, fromVersion)
+}
+
+func TestService_StreamingList(t *testing.T) {
+	svc := newTestService(t)
+	ctx := testContext()
+
+	for i := 1; i <= 6; i++ {
+		_, err := svc.CreateRecord(ctx, "account", map[string]any{
+			"id":          fmt.Sprintf("acc-%d", i),
+			"external_id": json.Number(strconv.Itoa(i)),
+		})
+		require.NoError(t, err)
+	}
+	store := &countingStore{Store: svc.store}
+	svc.store = store
+	svc.SetStreamingList(true)
+
+	filters := []Filter{{
+		FieldID:  "external_id",
+		Operator: entityPb.FilterOperator_FILTER_OPERATOR_GT,
+		Value:    "1",
+	}}
+	result, err := svc.ListRecordsPage(ctx, "account", ListOptions{Filters: filters, PageSize: 2})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 2)
+	assert.Equal(t, "acc-2", result.Records[0].ID)
+	assert.Equal(t, "acc-3", result.Records[1].ID)
+	assert.Equal(t, "after:acc-3", result.NextPageToken)
+	assert.Equal(t, 3, store.loads, "records past the page are not loaded")
+
+	store.loads = 0
+	result, err = svc.ListRecordsPage(ctx, "account", ListOptions{Filters: filters, PageSize: 2, PageToken: result.NextPageToken})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 2)
+	assert.Equal(t, "acc-4", result.Records[0].ID)
+	assert.Equal(t, 2, store.loads, "keyset tokens skip earlier records")
+
+	result, err = svc.ListRecordsPage(ctx, "account", ListOptions{Filters: filters, PageSize: 2, PageToken: result.NextPageToken})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 1)
+	assert.Equal(t, "acc-6", result.Records[0].ID)
+	assert.Empty(t, result.NextPageToken)
+
+	result, err = svc.ListRecordsPage(ctx, "account", ListOptions{
+		SortDir:   entityPb.SortDirection_SORT_DIRECTION_DESC,
+		PageSize:  2,
+		PageToken: "after:acc-5",
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 2)
+	assert.Equal(t, "acc-4", result.Records[0].ID)
+	assert.Equal(t, "acc-3", result.Records[1].ID)
+
+	// Other sorts and total counts keep the default path and offset tokens.
+	result, err = svc.ListRecordsPage(ctx, "account", ListOptions{PageSize: 2, IncludeTotalCount: true})
+	require.NoError(t, err)
+	assert.Equal(t, "2", result.NextPageToken)
+	require.NotNil(t, result.TotalCount)
+	assert.EqualValues(t, 6, *result.TotalCount)
+
+	_, err = svc.ListRecordsPage(ctx, "account", ListOptions{PageToken: "after:"})
+	assert.Error(t, err)
+
+	// Keyset tokens no longer apply once the list cannot be streamed.
+	handler := &grpcHandler{svc: svc}
+	_, err = svc.ListRecordsPage(ctx, "account", ListOptions{PageSize: 2, PageToken: "after:acc-3", IncludeTotalCount: true})
+	require.ErrorIs(t, err, ErrStalePageToken)
+	assert.ErrorContains(t, err, "sorted on id without a total count")
+	_, err = handler.ListRecords(ctx, &entityPb.ListRecordsRequest{Entity: "account", PageToken: "after:acc-3", SortField: "external_id"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	svc.SetStreamingList(false)
+	_, err = svc.ListRecordsPage(ctx, "account", ListOptions{PageToken: "after:acc-3"})
+	require.ErrorIs(t, err, ErrStalePageToken)
+	assert.ErrorContains(t, err, "only served by streaming lists")
+
+	// Starting over from the first page recovers.
+	result, err = svc.ListRecordsPage(ctx, "account", ListOptions{PageSize: 2, IncludeTotalCount: true})
+	require.NoError(t, err)
+	assert.Len(t, result.Records, 2)
+}
+
+func TestService_ListOrderMixedCaseIDs(t *testing.T) {
+	svc := newTestService(t)
+	ctx := testContext()
+	for _, id := range []string{"acc-b", "ACC-A", "acc-c", "acc-a"} {
+		_, err := svc.CreateRecord(ctx, "account", map[string]any{"id": id})
+		require.NoError(t, err)
+	}
+
+	list := func() []string {
+		var ids []string
+		token := ""
+		for {
+			result, err := svc.ListRecordsPage(ctx, "account", ListOptions{PageSize: 1, PageToken: token})
+			require.NoError(t, err)
+			for _, record := range result.Records {
+				ids = append(ids, record.ID)
+			}
+			if result.NextPageToken == "" {
+				return ids
+			}
+			token = result.NextPageToken
+		}
+	}
+
+	want := []string{"ACC-A", "acc-a", "acc-b", "acc-c"}
+	assert.Equal(t, want, list())
+	svc.SetStreamingList(true)
+	assert.Equal(t, want, list(), "streaming pages use the same order")
+}
+
+// snapshotStore adds snapshots to memoryStore and records the version record
+// streams were last loaded from.
+type snapshotStore struct {
+	*memoryStore
+	snapshots  map[string]eventstore.Snapshot
+	loadedFrom int64
+}
+
+func (s *snapshotStore) Load(ctx context.Context, streamID string, fromVersion int64) ([]eventstore.Event, error) {
+	if isRecordStream(streamID) {
+		s.loadedFrom = fromVersion
+	}
+	return s.memoryStore.Load(ctx, streamID, fromVersion)
+}
+
+func (s *snapshotStore) SaveSnapshot(_ context.Context, streamID string, version int64, state []byte) error {
+	if current, ok := s.snapshots[streamID]; !ok || current.Version < version {
+		s.snapshots[streamID] = eventstore.Snapshot{StreamID: streamID, Version: version, State: state}
+	}
+	return nil
+}
+
+func (s *snapshotStore) LoadSnapshot(_ context.Context, streamID string) (eventstore.Snapshot, error) {
+	snapshot, ok := s.snapshots[streamID]
+	if !ok {
+		return eventstore.Snapshot{}, eventstore.ErrSnapshotNotFound
+	}
+	return snapshot, nil
+}
+
+func TestService_Snapshots(t *testing.T) {
+	svc := newTestService(t)
+	store := &snapshotStore{memoryStore: newMemoryStore(), snapshots: make(map[string]eventstore.Snapshot)}
+	svc.store = store
+	svc.SetSnapshotFrequency(3)
+	ctx := testContext()
+
+	_, err := svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-1", "name": "v1"})
+	require.NoError(t, err)
+	for i := 2; i <= 4; i++ {
+		_, err = svc.UpdateRecord(ctx, "account", "acc-1", map[string]any{"name": fmt.Sprintf("v%d", i)})
+		require.NoError(t, err)
+	}
+	_, err = svc.UpdateRecord(ctx, "account", "acc-1", map[string]any{"external_id": json.Number("7")})
+	require.NoError(t, err)
+
+	streamID := recordStreamID("account", "acc-1")
+	require.Contains(t, store.snapshots, streamID)
+	assert.EqualValues(t, 3, store.snapshots[streamID].Version)
+
+	def, err := svc.Definition("account")
+	require.NoError(t, err)
+	record, err := svc.loadRecord(ctx, def, "acc-1")
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, store.loadedFrom, "only events after the snapshot are loaded")
+
+	_, events, err := svc.loadRecordEvents(ctx, def, "acc-1")
+	require.NoError(t, err)
+	empty := Record{Entity: def.ID, ID: "acc-1", Data: make(map[string]any)}
+	replayed, err := replayRecordFrom(svc.payloadCodec(), svc.metadataCodec(), def, empty, events)
+	require.NoError(t, err)
+	assert.Equal(t, replayed, record)
+	assert.EqualValues(t, 5, record.Version)
+	assert.Equal(t, "v4", record.Data["name"])
+
+	// Snapshots are ignored once disabled.
+	svc.SetSnapshotFrequency(0)
+	_, err = svc.GetRecord(ctx, "account", "acc-1")
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, store.loadedFrom)
+}
+
+func TestService_RecordHistoryWindow(t *testing.T) {
+	svc := newTestService(t)
+	store := &snapshotStore{memoryStore: newMemoryStore(), snapshots: make(map[string]eventstore.Snapshot)}
+	svc.store = store
+	ctx := testContext()
+
+	_, err := svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-1", "name": "v1"})
+	require.NoError(t, err)
+	for i := 2; i <= 7; i++ {
+		_, err = svc.UpdateRecord(ctx, "account", "acc-1", map[string]any{"name": fmt.Sprintf("v%d", i)})
+		require.NoError(t, err)
+	}
+
+	versions := func(entries []HistoryEntry) []int64 {
+		out := make([]int64, 0, len(entries))
+		for _, entry := range entries {
+			out = append(out, entry.Version)
+		}
+		return out
+	}
+
+	page, token, err := svc.RecordHistory(ctx, "account", "acc-1", HistoryOptions{PageSize: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{7, 6, 5}, versions(page))
+	assert.Equal(t, "before:5", token)
+	assert.Equal(t, "v6", page[1].Data["name"])
+
+	page, token, err = svc.RecordHistory(ctx, "account", "acc-1", HistoryOptions{PageSize: 3, PageToken: token})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{4, 3, 2}, versions(page))
+	page, token, err = svc.RecordHistory(ctx, "account", "acc-1", HistoryOptions{PageSize: 3, PageToken: token})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, versions(page))
+	assert.Empty(t, token)
+
+	page, token, err = svc.RecordHistory(ctx, "account", "acc-1", HistoryOptions{SinceVersion: 2, UntilVersion: 4})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{4, 3, 2}, versions(page))
+	assert.Equal(t, "v3", page[1].Data["name"])
+	assert.Empty(t, token)
+
+	// Pages after the latest snapshot only load the newer events.
+	svc.SetSnapshotFrequency(3)
+	require.NoError(t, svc.writeSnapshot(ctx, "account", "acc-1"))
+	_, err = svc.UpdateRecord(ctx, "account", "acc-1", map[string]any{"name": "v8"})
+	require.NoError(t, err)
+
+	full, _, err := svc.RecordHistory(ctx, "account", "acc-1", HistoryOptions{PageSize: 2})
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, store.loadedFrom, "pages reaching the snapshot fall back to a full replay")
+	page, token, err = svc.RecordHistory(ctx, "account", "acc-1", HistoryOptions{PageSize: 1})
+	require.NoError(t, err)
+	assert.EqualValues(t, 7, store.loadedFrom, "the event at the snapshot version is checked")
+	assert.Equal(t, full[:1], page)
+	assert.Equal(t, "before:8", token)
+
+	_, _, err = svc.RecordHistory(ctx, "account", "acc-1", HistoryOptions{PageToken: "before:1"})
+	assert.Error(t, err)
+}
+
+// uowProvider records the unit of work passed to provider writes.
+type uowProvider struct {
+	Provider
+	uow *UnitOfWork
+}
+
+func (p *uowProvider) Create(ctx context.Context, def Definition, data map[string]any) (Record, error) {
+	uow, ok := UnitOfWorkFromContext(ctx)
+	if !ok {
+		return Record{}, assert.AnError
+	}
+	p.uow = uow
+	err := uow.Append(ctx, "external:"+asString(data["id"]), -1, []eventstore.Event{{Type: "external.created"}})
+	return Record{Entity: def.ID, ID: asString(data["id"]), Data: data}, err
+}
+
+func TestService_ProviderUnitOfWork(t *testing.T) {
+	svc := newTestService(t)
+	provider := &uowProvider{}
+	svc.RegisterProvider("account", provider)
+
+	_, err := svc.CreateRecord(testContext(), "account", map[string]any{"id": "acc-1", "name": "first"})
+	require.NoError(t, err)
+	require.NotNil(t, provider.uow)
+	assert.Nil(t, provider.uow.Tx(), "memory store is not transactional")
+
+	events, err := svc.store.Load(context.Background(), "external:acc-1", 0)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
+
+func TestService_RelationshipPathLimits(t *testing.T) {
+	def, err := parseDefinition([]byte(`
+id: employee
+primary_key: id
+fields:
+  - id: id
+    type: string
+  - id: name
+    type: string
+  - id: manager_id
+    type: string
+    reference:
+      entity: employee
+      field: id
+`))
+	require.NoError(t, err)
+	svc := &Service{defs: map[string]Definition{def.ID: def}, store: newMemoryStore()}
+
+	_, err = svc.normalizeFilters(def, []Filter{{FieldID: "manager_id.name", Value: "ann"}})
+	require.NoError(t, err)
+
+	// Self-referencing paths are bounded by depth only.
+	_, err = svc.normalizeFilters(def, []Filter{{FieldID: "manager_id.manager_id.name", Value: "ann"}})
+	require.NoError(t, err)
+
+	_, err = svc.normalizeFilters(def, []Filter{{FieldID: "manager_id.manager_id.manager_id.manager_id.name", Value: "ann"}})
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+	assert.Contains(t, err.Error(), "max relationship depth")
+
+	svc.SetMaxRelationshipDepth(1)
+	_, err = svc.normalizeFilters(def, []Filter{{FieldID: "manager_id.manager_id.name", Value: "ann"}})
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+	assert.Contains(t, err.Error(), "max relationship depth")
+}
+
+// newReferenceTestServiceWithoutRecords returns a service with the account
+// entity and a contact entity referencing it.
+func newReferenceTestServiceWithoutRecords(t *testing.T) *Service {
+	t.Helper()
+
+	svc := newTestService(t)
+	contact, err := parseDefinition([]byte(`
+id: contact
+primary_key: id
+fields:
+  - id: id
+    type: string
+  - id: account_id
+    type: string
+    reference:
+      entity: account
+      field: id
+      label: name
+`))
+	require.NoError(t, err)
+	svc.defs[contact.ID] = contact
+	return svc
+}
+
+// newReferenceTestService adds a contact entity referencing account and seeds
+// both with records.
+func newReferenceTestService(t *testing.T) (*Service, context.Context) {
+	t.Helper()
+
+	svc := newReferenceTestServiceWithoutRecords(t)
+	ctx := testContext()
+
+	for _, data := range []map[string]any{
+		{"id": "acc-1", "name": "Acme"},
+		{"id": "acc-2", "name": "Globex"},
+	} {
+		_, err := svc.CreateRecord(ctx, "account", data)
+		require.NoError(t, err)
+	}
+	for _, data := range []map[string]any{
+		{"id": "c-1", "account_id": "acc-1"},
+		{"id": "c-2", "account_id": "acc-2"},
+		{"id": "c-3", "account_id": "acc-1"},
+		{"id": "c-4", "account_id": "acc-missing"},
+	} {
+		_, err := svc.CreateRecord(ctx, "contact", data)
+		require.NoError(t, err)
+	}
+	return svc, ctx
+}
+
+func TestService_OrGroups(t *testing.T) {
+	svc, ctx := newReferenceTestService(t)
+	eq := func(field, value string) Filter {
+		return Filter{FieldID: field, Operator: entityPb.FilterOperator_FILTER_OPERATOR_EQ, Value: value}
+	}
+	list := func(opts ListOptions) []string {
+		t.Helper()
+		records, _, err := svc.ListRecords(ctx, "contact", opts)
+		require.NoError(t, err)
+		ids := make([]string, 0, len(records))
+		for _, record := range records {
+			ids = append(ids, record.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"c-2", "c-4"}, list(ListOptions{
+		OrGroups: [][]Filter{{eq("account_id.name", "Globex"), eq("id", "c-4")}},
+	}), "nested filters work inside a group")
+	assert.Equal(t, []string{"c-3"}, list(ListOptions{
+		Filters:  []Filter{eq("account_id", "acc-1")},
+		OrGroups: [][]Filter{{eq("id", "c-3"), eq("id", "c-4")}},
+	}), "groups combine with AND filters")
+	assert.Equal(t, []string{"c-1"}, list(ListOptions{
+		OrGroups: [][]Filter{{eq("id", "c-1"), eq("id", "c-2")}, {eq("id", "c-1"), eq("id", "c-3")}},
+	}), "every group must match")
+	assert.Equal(t, []string{"c-4"}, list(ListOptions{
+		OrGroups: [][]Filter{{eq("account_id.name", "Nobody"), eq("id", "c-4")}},
+	}), "nested filters without matches drop out of their group")
+	assert.Empty(t, list(ListOptions{
+		OrGroups: [][]Filter{{eq("account_id.name", "Nobody")}},
+	}))
+
+	resp, err := (&grpcHandler{svc: svc}).ListRecords(ctx, &entityPb.ListRecordsRequest{
+		Entity: "contact",
+		OrGroups: []*entityPb.FilterGroup{{Filters: []*entityPb.FilterExpression{
+			{Field: "id", Value: structpb.NewStringValue("c-1")},
+			{Field: "account_id", Value: structpb.NewStringValue("acc-2")},
+		}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.GetRecords(), 2)
+	assert.Equal(t, "c-1", resp.GetRecords()[0].GetId())
+	assert.Equal(t, "c-2", resp.GetRecords()[1].GetId())
+}
+
+func TestService_ResolveReferenceLabels(t *testing.T) {
+	svc, ctx := newReferenceTestService(t)
+
+	records, _, err := svc.ListRecords(ctx, "contact", ListOptions{ResolveLabels: true})
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+
+	labels := make(map[string]map[string]string, len(records))
+	for _, record := range records {
+		labels[record.ID] = record.Labels
+	}
+	assert.Equal(t, map[string]map[string]string{
+		"c-1": {"account_id": "Acme"},
+		"c-2": {"account_id": "Globex"},
+		"c-3": {"account_id": "Acme"},
+		"c-4": nil,
+	}, labels)
+
+	records, _, err = svc.ListRecords(ctx, "contact", ListOptions{})
+	require.NoError(t, err)
+	for _, record := range records {
+		assert.Nil(t, record.Labels)
+	}
+}
+
+func TestService_ReferenceLabelsTransformed(t *testing.T) {
+	svc, ctx := newReferenceTestService(t)
+	svc.RegisterReadTransform("account", func(ctx context.Context, record *Record) error {
+		record.Data["name"] = "***"
+		return nil
+	})
+
+	records, _, err := svc.ListRecords(ctx, "contact", ListOptions{
+		Filters:       []Filter{{FieldID: "id", Value: "c-1"}},
+		ResolveLabels: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, map[string]string{"account_id": "***"}, records[0].Labels)
+
+	record, err := svc.GetRecordWith(ctx, "contact", "c-1", GetOptions{Expand: []string{"account_id"}})
+	require.NoError(t, err)
+	assert.Equal(t, "***", record.Data["account_id_label"])
+}
+
+func TestService_ExpandReferences(t *testing.T) {
+	svc, ctx := newReferenceTestService(t)
+
+	records, _, err := svc.ListRecords(ctx, "contact", ListOptions{
+		Filters: []Filter{{FieldID: "id", Value: "c-2"}},
+		Fields:  []string{"id"},
+		Expand:  ParseFieldList([]string{"account_id"}),
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, map[string]any{"id": "c-2", "account_id_label": "Globex"}, records[0].Data)
+
+	record, err := svc.GetRecordWith(ctx, "contact", "c-1", GetOptions{Expand: []string{"account_id"}})
+	require.NoError(t, err)
+	assert.Equal(t, "acc-1", record.Data["account_id"])
+	assert.Equal(t, "Acme", record.Data["account_id_label"])
+
+	record, err = svc.GetRecordWith(ctx, "contact", "c-4", GetOptions{Expand: []string{"account_id"}})
+	require.NoError(t, err)
+	assert.NotContains(t, record.Data, "account_id_label")
+
+	_, err = svc.GetRecordWith(ctx, "contact", "c-1", GetOptions{Expand: []string{"id"}})
+	assert.ErrorIs(t, err, ErrInvalidExpand)
+
+	_, _, err = svc.ListRecords(ctx, "contact", ListOptions{Expand: []string{"missing"}})
+	assert.ErrorIs(t, err, ErrInvalidExpand)
+}
+
+func TestService_AuditFieldNames(t *testing.T) {
+	svc := newTestService(t)
+	for _, src := range []string{`
+id: ticket
+primary_key: id
+fields:
+  - id: id
+    type: string
+  - id: created_by
+    type: string
+  - id: updated_by
+    type: string
+`, `
+id: note
+primary_key: id
+audit:
+  created_by: author
+  updated_by: editor
+fields:
+  - id: id
+    type: string
+  - id: author
+    type: string
+  - id: editor
+    type: string
+`} {
+		def, err := parseDefinition([]byte(src))
+		require.NoError(t, err)
+		svc.defs[def.ID] = def
+	}
+	ctx := testContext()
+
+	t.Run("should detect snake_case audit fields", func(t *testing.T) {
+		created, err := svc.CreateRecord(ctx, "ticket", map[string]any{
+			"id":         "t-1",
+			"created_by": "spoofed",
+			"createdBy":  "spoofed",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", created.Data["created_by"])
+		assert.Equal(t, "user-1", created.Data["updated_by"])
+		assert.NotContains(t, created.Data, "createdBy")
+
+		_, err = svc.UpdateRecord(ctx, "ticket", "t-1", map[string]any{"updated_by": "spoofed"})
+		require.NoError(t, err)
+		record, err := svc.GetRecord(ctx, "ticket", "t-1")
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", record.Data["created_by"])
+		assert.Equal(t, "user-1", record.Data["updated_by"])
+	})
+
+	t.Run("should use configured audit fields", func(t *testing.T) {
+		assert.Equal(t, AuditFields{CreatedBy: "author", UpdatedBy: "editor"}, svc.defs["note"].Audit)
+
+		_, err := svc.CreateRecord(ctx, "note", map[string]any{"id": "n-1", "author": "spoofed"})
+		require.NoError(t, err)
+		record, err := svc.GetRecord(ctx, "note", "n-1")
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", record.Data["author"])
+		assert.Equal(t, "user-1", record.Data["editor"])
+	})
+
+	t.Run("should keep camelCase defaults", func(t *testing.T) {
+		record, err := svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-1", "createdBy": "spoofed"})
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", record.Data["createdBy"])
+		assert.Equal(t, "user-1", record.Data["updatedBy"])
+	})
+}
+
+func TestCanonicalID(t *testing.T) {
+	for _, tc := range []struct {
+		value any
+		want  string
+	}{
+		{float64(42), "42"},
+		{float64(1234567), "1234567"},
+		{float32(7), "7"},
+		{json.Number("1234567"), "1234567"},
+		{json.Number("42.0"), "42"},
+		{"42.00", "42"},
+		{"1.234567e+06", "1234567"},
+		{"1.5e+00", "1.5e+00"},
+		{" 42 ", "42"},
+		{int64(9007199254740993), "9007199254740993"},
+		{1.5, "1.5"},
+		{"v1.0", "v1.0"},
+		{"acc-1", "acc-1"},
+		{nil, ""},
+	} {
+		assert.Equal(t, tc.want, canonicalID(tc.value), "%#v", tc.value)
+	}
+}
+
+func TestColumnValue(t *testing.T) {
+	number := FieldDefinition{ID: "amount", Type: entityPb.FieldType_FIELD_TYPE_NUMBER}
+	for _, tc := range []struct {
+		value any
+		want  any
+	}{
+		{json.Number("10"), int64(10)},
+		{"2.5", 2.5},
+		{float64(3), int64(3)},
+		{"12345678901234567890.123", "12345678901234567890.123"},
+		{"NaN", "NaN"},
+		{"0x10", "0x10"},
+		{"1 OR 1=1", "1 OR 1=1"},
+	} {
+		assert.Equal(t, tc.want, columnValue(number, tc.value), "%#v", tc.value)
+	}
+
+	text := FieldDefinition{ID: "code", Type: entityPb.FieldType_FIELD_TYPE_STRING}
+	assert.Equal(t, "1.0", columnValue(text, json.Number("1.0")))
+}
+
+func TestService_NumericIDMatching(t *testing.T) {
+	svc := newReferenceTestServiceWithoutRecords(t)
+	account := svc.defs["account"]
+	contact := svc.defs["contact"]
+	accountID, _ := contact.Field("account_id")
+	pk, _ := account.Field("id")
+
+	records := []Record{
+		{ID: "c-1", Data: map[string]any{"account_id": float64(1234567)}},
+		{ID: "c-2", Data: map[string]any{"account_id": "1234568"}},
+	}
+
+	t.Run("should match reference ids across types", func(t *testing.T) {
+		filter := normalizedFilter{
+			Steps:    []relationshipStep{{Entity: contact, Field: accountID}},
+			Operator: entityPb.FilterOperator_FILTER_OPERATOR_EQ,
+			Value:    "1234567",
+		}
+		matched := applyFilters(records, []normalizedFilter{filter})
+		require.Len(t, matched, 1)
+		assert.Equal(t, "c-1", matched[0].ID)
+
+		filter.Operator = entityPb.FilterOperator_FILTER_OPERATOR_IN
+		filter.Value = []any{json.Number("1234567.0"), float64(1234568)}
+		assert.Len(t, applyFilters(records, []normalizedFilter{filter}), 2)
+
+		filter.Operator = entityPb.FilterOperator_FILTER_OPERATOR_NE
+		filter.Value = float64(1234567)
+		matched = applyFilters(records, []normalizedFilter{filter})
+		require.Len(t, matched, 1)
+		assert.Equal(t, "c-2", matched[0].ID)
+	})
+
+	t.Run("should match primary keys across types", func(t *testing.T) {
+		filter := normalizedFilter{
+			Steps:    []relationshipStep{{Entity: account, Field: pk}},
+			Operator: entityPb.FilterOperator_FILTER_OPERATOR_EQ,
+			Value:    float64(7),
+		}
+		assert.True(t, matchesFilters(map[string]any{"id": "7"}, []normalizedFilter{filter}))
+	})
+
+	t.Run("should extract canonical values for nested filters", func(t *testing.T) {
+		values := extractFieldValues(records, "account_id", "id")
+		assert.ElementsMatch(t, []string{"1234567", "1234568"}, values.toSlice())
+	})
+}
+
+func TestService_NestedFilterNumericIDs(t *testing.T) {
+	svc := newReferenceTestServiceWithoutRecords(t)
+	ctx := testContext()
+
+	_, err := svc.CreateRecord(ctx, "account", map[string]any{"id": json.Number("1234567"), "name": "Acme"})
+	require.NoError(t, err)
+	_, err = svc.CreateRecord(ctx, "account", map[string]any{"id": "1234568", "name": "Globex"})
+	require.NoError(t, err)
+	_, err = svc.CreateRecord(ctx, "contact", map[string]any{"id": "c-1", "account_id": float64(1234567)})
+	require.NoError(t, err)
+	_, err = svc.CreateRecord(ctx, "contact", map[string]any{"id": "c-2", "account_id": "1234568.0"})
+	require.NoError(t, err)
+
+	record, err := svc.GetRecord(ctx, "account", "1234567")
+	require.NoError(t, err)
+	assert.Equal(t, "1234567", record.ID)
+
+	// Ids are stored as sent; only comparisons use the canonical form.
+	record, err = svc.CreateRecord(ctx, "account", map[string]any{"id": "1.0", "name": "Initech"})
+	require.NoError(t, err)
+	assert.Equal(t, "1.0", record.ID)
+	_, err = svc.GetRecord(ctx, "account", "1.0")
+	require.NoError(t, err)
+	record, err = svc.GetRecord(ctx, "contact", "c-2")
+	require.NoError(t, err)
+	assert.Equal(t, "1234568.0", record.Data["account_id"])
+
+	records, _, err := svc.ListRecords(ctx, "contact", ListOptions{
+		Filters: []Filter{{
+			FieldID:  "account_id.name",
+			Operator: entityPb.FilterOperator_FILTER_OPERATOR_EQ,
+			Value:    "Acme",
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "c-1", records[0].ID)
+}
+
+func TestTailEvents(t *testing.T) {
+	svc := newTestService(t)
+	ctx := testContext()
+
+	_, err := svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-1", "name": "first"})
+	require.NoError(t, err)
+	_, err = svc.UpdateRecord(ctx, "account", "acc-1", map[string]any{"name": "second"})
+	require.NoError(t, err)
+
+	t.Run("should follow a record from a version", func(t *testing.T) {
+		tailCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var tailed []TailedEvent
+		err := TailEvents(tailCtx, svc.store, TailOptions{
+			Entity:      "account",
+			Record:      "acc-1",
+			FromVersion: 2,
+			Interval:    time.Millisecond,
+		}, func(evt TailedEvent) error {
+			tailed = append(tailed, evt)
+			cancel()
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, tailed, 1)
+		assert.Equal(t, eventTypeRecordUpdated, tailed[0].Type)
+		assert.Equal(t, int64(2), tailed[0].Version)
+		assert.Equal(t, "user-1", tailed[0].ActorID)
+		assert.Equal(t, map[string]any{"data": map[string]any{"name": "second", "updatedBy": "user-1"}}, tailed[0].Payload)
+	})
+
+	t.Run("should require a feed for whole entities", func(t *testing.T) {
+		err := TailEvents(context.Background(), svc.store, TailOptions{Entity: "account"}, func(TailedEvent) error { return nil })
+		assert.ErrorIs(t, err, ErrFeedUnsupported)
+	})
+
+	t.Run("should follow a whole entity from a position", func(t *testing.T) {
+		svc := newTestService(t)
+		svc.store = &feedStore{memoryStore: newMemoryStore()}
+		for _, id := range []string{"acc-1", "acc-2"} {
+			_, err := svc.CreateRecord(ctx, "account", map[string]any{"id": id, "name": id})
+			require.NoError(t, err)
+		}
+
+		tailCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			_, _ = svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-3", "name": "acc-3"})
+		}()
+
+		// Positions 1 and 2 hold acc-1 and its legacy copy.
+		var streams []string
+		err := TailEvents(tailCtx, svc.store, TailOptions{
+			Entity:      "account",
+			FromVersion: 3,
+			Interval:    time.Millisecond,
+		}, func(evt TailedEvent) error {
+			if evt.Type == eventTypeRecordCreated && !strings.HasPrefix(evt.StreamID, "entity:") {
+				streams = append(streams, evt.StreamID)
+			}
+			if evt.StreamID == "acc-3" {
+				cancel()
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"acc-2", "acc-3"}, streams)
+	})
+}
+
+// prefixCodec wraps JSON in a marker so tests can tell it was used.
+type prefixCodec struct{}
+
+func (prefixCodec) Marshal(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	return append([]byte("meta:"), data...), err
+}
+
+func (prefixCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(bytes.TrimPrefix(data, []byte("meta:")), v)
+}
+
+func TestService_EventMetadata(t *testing.T) {
+	svc := newTestService(t)
+	svc.SetMetadataCodec(prefixCodec{})
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	ctx := trace.ContextWithSpanContext(testContext(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  trace.SpanID{1},
+	}))
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-request-id", "req-1"))
+	ctx = WithEventAnnotations(ctx, map[string]string{"source": "import"})
+	ctx = WithEventAnnotations(ctx, map[string]string{"batch": "7"})
+
+	_, err = svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-1", "name": "first"})
+	require.NoError(t, err)
+
+	events, err := svc.store.Load(ctx, recordStreamID("account", "acc-1"), 0)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.True(t, bytes.HasPrefix(events[0].Metadata, []byte("meta:")), "metadata codec should be used")
+
+	meta, err := decodeEventMetadata(svc.metadataCodec(), events[0].Metadata)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", meta.ActorID)
+	assert.Equal(t, traceID.String(), meta.TraceID)
+	assert.Equal(t, "req-1", meta.RequestID)
+	assert.Equal(t, "import", meta.Annotation("source"))
+	assert.Equal(t, "7", meta.Annotation("batch"))
+
+	// Records still replay through the configured codec.
+	record, err := svc.GetRecord(testContext(), "account", "acc-1")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", record.CreatedBy)
+}
+
+func TestService_BetweenFilter(t *testing.T) {
+	svc := newTestService(t)
+	def, err := parseDefinition([]byte(`
+id: order
+primary_key: id
+fields:
+  - id: id
+    type: string
+  - id: total
+    type: number
+    filter:
+      operators: [between]
+  - id: placed_at
+    type: datetime
+    filter:
+      operators: [".."]
+`))
+	require.NoError(t, err)
+	svc.defs[def.ID] = def
+	ctx := testContext()
+
+	for i, placedAt := range []string{"2024-01-01T00:00:00Z", "2024-02-01T00:00:00Z", "2024-03-01T00:00:00+02:00"} {
+		_, err := svc.CreateRecord(ctx, "order", map[string]any{
+			"id":        fmt.Sprintf("o-%d", i+1),
+			"total":     json.Number(strconv.Itoa((i + 1) * 10)),
+			"placed_at": placedAt,
+		})
+		require.NoError(t, err)
+	}
+
+	list := func(field string, value any) []string {
+		t.Helper()
+		records, _, err := svc.ListRecords(ctx, "order", ListOptions{Filters: []Filter{{
+			FieldID:  field,
+			Operator: entityPb.FilterOperator_FILTER_OPERATOR_BETWEEN,
+			Value:    value,
+		}}})
+		require.NoError(t, err)
+		ids := make([]string, 0, len(records))
+		for _, record := range records {
+			ids = append(ids, record.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"o-1", "o-2"}, list("total", "10,20"), "bounds are inclusive")
+	assert.Equal(t, []string{"o-2", "o-3"}, list("total", []any{15, 30.5}))
+	assert.Equal(t, []string{"o-2"}, list("placed_at", []any{"2024-01-15T00:00:00Z", "2024-02-29T21:00:00Z"}))
+	assert.Equal(t, []string{"o-3"}, list("placed_at", "2024-02-29T22:00:00Z,2024-02-29T22:00:00Z"), "datetimes compare across offsets")
+
+	for _, value := range []any{"10", "1,2,3", []any{1}} {
+		_, _, err := svc.ListRecords(ctx, "order", ListOptions{Filters: []Filter{{
+			FieldID:  "total",
+			Operator: entityPb.FilterOperator_FILTER_OPERATOR_BETWEEN,
+			Value:    value,
+		}}})
+		assert.ErrorIs(t, err, ErrInvalidFilter, "%v", value)
+	}
+	_, _, err = svc.ListRecords(ctx, "order", ListOptions{Filters: []Filter{{
+		FieldID:  "placed_at",
+		Operator: entityPb.FilterOperator_FILTER_OPERATOR_BETWEEN,
+		Value:    "yesterday,today",
+	}}})
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+
+	t.Run("sql provider", func(t *testing.T) {
+		sqldb, err := sql.Open(sqliteshim.ShimName, "file:between?mode=memory&cache=shared")
+		require.NoError(t, err)
+		sqldb.SetMaxOpenConns(1)
+		db := bun.NewDB(sqldb, sqlitedialect.New())
+		t.Cleanup(func() { _ = db.Close() })
+
+		provider := NewSQLProvider(db)
+		require.NoError(t, provider.CreateTable(ctx, def))
+		svc.RegisterProvider("order", provider)
+		for i, placedAt := range []string{"2024-01-01T00:00:00Z", "2024-02-01T00:00:00Z", "2024-03-01T00:00:00+02:00"} {
+			_, err := svc.CreateRecord(ctx, "order", map[string]any{
+				"id":        fmt.Sprintf("o-%d", i+1),
+				"placed_at": placedAt,
+			})
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, []string{"o-2"}, list("placed_at", []any{"2024-01-15T00:00:00Z", "2024-02-29T21:00:00Z"}))
+		assert.Equal(t, []string{"o-3"}, list("placed_at", "2024-02-29T22:00:00Z,2024-02-29T22:00:00Z"), "datetimes compare across offsets")
+		assert.Equal(t, []string{"o-3"}, list("placed_at", "2024-02-29T23:59:59.5+02:00,2024-03-01T01:00:00+02:00"), "fractions compare in order")
+
+		record, err := svc.GetRecord(ctx, "order", "o-3")
+		require.NoError(t, err)
+		assert.Equal(t, "2024-02-29T22:00:00Z", record.Data["placed_at"])
+
+		_, _, err = provider.List(ctx, def, ListOptions{Filters: []Filter{{
+			FieldID:  "placed_at",
+			Operator: entityPb.FilterOperator_FILTER_OPERATOR_BETWEEN,
+			Value:    []any{"yesterday", "today"},
+		}}, PageSize: 10})
+		assert.ErrorIs(t, err, ErrInvalidFilter)
+	})
+}
+
+func TestService_NullFilters(t *testing.T) {
+	svc := newTestService(t)
+	ctx := testContext()
+
+	for _, data := range []map[string]any{
+		{"id": "acc-1", "name": "first"},
+		{"id": "acc-2", "name": ""},
+		{"id": "acc-3"},
+		{"id": "acc-4", "name": nil},
+	} {
+		_, err := svc.CreateRecord(ctx, "account", data)
+		require.NoError(t, err)
+	}
+
+	list := func(operator entityPb.FilterOperator) []string {
+		t.Helper()
+		records, _, err := svc.ListRecords(ctx, "account", ListOptions{Filters: []Filter{{
+			FieldID:  "name",
+			Operator: operator,
+			Value:    "ignored",
+		}}})
+		require.NoError(t, err)
+		ids := make([]string, 0, len(records))
+		for _, record := range records {
+			ids = append(ids, record.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"acc-2", "acc-3", "acc-4"}, list(entityPb.FilterOperator_FILTER_OPERATOR_IS_NULL))
+	assert.Equal(t, []string{"acc-1"}, list(entityPb.FilterOperator_FILTER_OPERATOR_IS_NOT_NULL))
+
+	for alias, want := range map[string]entityPb.FilterOperator{
+		"is_null":  entityPb.FilterOperator_FILTER_OPERATOR_IS_NULL,
+		"null":     entityPb.FilterOperator_FILTER_OPERATOR_IS_NULL,
+		"not_null": entityPb.FilterOperator_FILTER_OPERATOR_IS_NOT_NULL,
+	} {
+		op, ok := parseFilterOperator(alias)
+		assert.True(t, ok, alias)
+		assert.Equal(t, want, op, alias)
+	}
+}
+
+func TestService_PivotAggregations(t *testing.T) {
+	svc := newTestService(t)
+	def, err := parseDefinition([]byte(`
+id: sale
+primary_key: id
+fields:
+  - id: id
+    type: string
+  - id: region
+    type: string
+  - id: month
+    type: string
+  - id: amount
+    type: number
+`))
+	require.NoError(t, err)
+	svc.defs[def.ID] = def
+	ctx := testContext()
+
+	for i, sale := range []map[string]any{
+		{"region": "eu", "month": "jan", "amount": json.Number("10")},
+		{"region": "eu", "month": "jan", "amount": json.Number("30")},
+		{"region": "eu", "month": "feb", "amount": json.Number("5")},
+		{"region": "us", "month": "jan", "amount": json.Number("7")},
+		{"region": "us", "month": "feb"},
+	} {
+		sale["id"] = fmt.Sprintf("s-%d", i)
+		_, err := svc.CreateRecord(ctx, "sale", sale)
+		require.NoError(t, err)
+	}
+
+	pivot := func(opts PivotOptions) PivotResult {
+		t.Helper()
+		opts.RowField = "region"
+		opts.ColumnField = "month"
+		result, err := svc.PivotRecords(ctx, "sale", opts)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := pivot(PivotOptions{})
+	assert.Equal(t, entityPb.PivotAggregation_PIVOT_AGGREGATION_COUNT, result.Aggregation)
+	assert.Equal(t, map[string]float64{"eu": 3, "us": 2}, result.RowTotals)
+	assert.Equal(t, 5.0, result.GrandTotal)
+
+	result = pivot(PivotOptions{ValueField: "amount"})
+	assert.Equal(t, entityPb.PivotAggregation_PIVOT_AGGREGATION_SUM, result.Aggregation)
+	assert.Equal(t, []PivotEntry{
+		{RowKey: "eu", ColumnKey: "feb", Value: 5},
+		{RowKey: "eu", ColumnKey: "jan", Value: 40},
+		{RowKey: "us", ColumnKey: "jan", Value: 7},
+	}, result.Entries, "records without a numeric value are skipped")
+	assert.Equal(t, map[string]float64{"eu": 45, "us": 7}, result.RowTotals)
+	assert.Equal(t, map[string]float64{"feb": 5, "jan": 47}, result.ColumnTotals)
+	assert.Equal(t, 52.0, result.GrandTotal)
+
+	result = pivot(PivotOptions{ValueField: "amount", Aggregation: entityPb.PivotAggregation_PIVOT_AGGREGATION_AVG})
+	assert.Equal(t, 20.0, result.Entries[1].Value)
+	assert.Equal(t, 15.0, result.RowTotals["eu"], "totals average the underlying values")
+	assert.Equal(t, 13.0, result.GrandTotal)
+
+	result = pivot(PivotOptions{ValueField: "amount", Aggregation: entityPb.PivotAggregation_PIVOT_AGGREGATION_MIN})
+	assert.Equal(t, 5.0, result.RowTotals["eu"])
+	assert.Equal(t, 5.0, result.GrandTotal)
+
+	result = pivot(PivotOptions{ValueField: "amount", Aggregation: entityPb.PivotAggregation_PIVOT_AGGREGATION_MAX})
+	assert.Equal(t, map[string]float64{"eu": 30, "us": 7}, result.RowTotals)
+	assert.Equal(t, 30.0, result.GrandTotal)
+
+	_, err = svc.PivotRecords(ctx, "sale", PivotOptions{RowField: "region", Aggregation: entityPb.PivotAggregation_PIVOT_AGGREGATION_SUM})
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+
+	_, err = (&grpcHandler{svc: svc}).PivotRecords(ctx, &entityPb.PivotRequest{Entity: "sale", RowField: "region", ValueField: "month"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.ErrorContains(t, err, "value field month is not a number")
+}
+
+func TestService_Stats(t *testing.T) {
+	svc := newTestService(t)
+	def, err := parseDefinition([]byte(`
+id: ticket
+primary_key: id
+fields:
+  - id: id
+    type: string
+  - id: status
+    type: string
+  - id: opened_at
+    type: datetime
+  - id: tags
+    type: string
+    repeated: true
+`))
+	require.NoError(t, err)
+	svc.defs[def.ID] = def
+	ctx := testContext()
+
+	for i, ticket := range []map[string]any{
+		{"status": "open", "opened_at": "2026-03-02T10:00:00Z"},
+		{"status": "open", "opened_at": "2026-03-08T23:30:00-02:00"},
+		{"status": "closed", "opened_at": "2026-03-09T08:00:00Z"},
+		{"status": "open"},
+	} {
+		ticket["id"] = fmt.Sprintf("t-%d", i)
+		_, err := svc.CreateRecord(ctx, "ticket", ticket)
+		require.NoError(t, err)
+	}
+
+	result, err := svc.Stats(ctx, "ticket", StatsOptions{
+		GroupField: "status",
+		TimeField:  "opened_at",
+		Interval:   entityPb.StatsInterval_STATS_INTERVAL_WEEK,
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, result.Total)
+	assert.Equal(t, []StatsCount{{Key: "open", Count: 3}, {Key: "closed", Count: 1}}, result.Groups)
+	// The second ticket opened on Monday March 9th in UTC.
+	assert.Equal(t, []StatsBucket{
+		{Start: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Count: 1},
+		{Start: time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), Count: 2},
+	}, result.Buckets)
+
+	result, err = svc.Stats(ctx, "ticket", StatsOptions{
+		Filters:  []Filter{{FieldID: "status", Operator: entityPb.FilterOperator_FILTER_OPERATOR_EQ, Value: "open"}},
+		Interval: entityPb.StatsInterval_STATS_INTERVAL_MONTH,
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, result.Total)
+	assert.Nil(t, result.Groups)
+	require.Len(t, result.Buckets, 1, "bucketed by creation time")
+	assert.EqualValues(t, 3, result.Buckets[0].Count)
+
+	_, err = svc.Stats(ctx, "ticket", StatsOptions{GroupField: "tags"})
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+	_, err = svc.Stats(ctx, "ticket", StatsOptions{TimeField: "status", Interval: entityPb.StatsInterval_STATS_INTERVAL_DAY})
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+}
+
+func TestService_StatsProviderRelationshipFilter(t *testing.T) {
+	sqldb, err := sql.Open(sqliteshim.ShimName, "file:stats?mode=memory&cache=shared")
+	require.NoError(t, err)
+	sqldb.SetMaxOpenConns(1)
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	t.Cleanup(func() { _ = db.Close() })
+
+	svc := newReferenceTestServiceWithoutRecords(t)
+	ctx := testContext()
+	contact, err := svc.Definition("contact")
+	require.NoError(t, err)
+	provider := NewSQLProvider(db)
+	require.NoError(t, provider.CreateTable(ctx, contact))
+	svc.RegisterProvider("contact", provider)
+
+	for _, data := range []map[string]any{
+		{"id": "acc-1", "name": "Acme"},
+		{"id": "acc-2", "name": "Globex"},
+	} {
+		_, err := svc.CreateRecord(ctx, "account", data)
+		require.NoError(t, err)
+	}
+	for _, data := range []map[string]any{
+		{"id": "c-1", "account_id": "acc-1"},
+		{"id": "c-2", "account_id": "acc-2"},
+		{"id": "c-3", "account_id": "acc-1"},
+	} {
+		_, err := svc.CreateRecord(ctx, "contact", data)
+		require.NoError(t, err)
+	}
+
+	stats, err := svc.Stats(ctx, "contact", StatsOptions{
+		Filters: []Filter{{FieldID: "account_id.name", Operator: entityPb.FilterOperator_FILTER_OPERATOR_EQ, Value: "Acme"}},
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, stats.Total)
+
+	stats, err = svc.Stats(ctx, "contact", StatsOptions{
+		Filters:    []Filter{{FieldID: "account_id.name", Operator: entityPb.FilterOperator_FILTER_OPERATOR_EQ, Value: "Initech"}},
+		GroupField: "account_id",
+	})
+	require.NoError(t, err)
+	assert.Zero(t, stats.Total)
+	assert.Empty(t, stats.Groups)
+}
+
+func TestService_BatchRecords(t *testing.T) {
+	svc := newTestService(t)
+	ctx := testContext()
+	h := &grpcHandler{svc: svc}
+
+	// The memory store cannot roll back a partial batch.
+	_, err := svc.CreateRecords(ctx, "account", []map[string]any{{"id": "acc-1"}}, BatchOptions{Atomic: true})
+	assert.ErrorIs(t, err, ErrAtomicUnsupported)
+	_, err = h.BatchCreateRecords(ctx, &entityPb.BatchCreateRecordsRequest{Entity: "account", Atomic: true})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	records, err := svc.CreateRecords(ctx, "account", []map[string]any{
+		{"id": "acc-1", "name": "first"},
+		{"id": "acc-1", "name": "again"},
+		{"name": "generated"},
+	}, BatchOptions{})
+	var batchErr BatchError
+	require.ErrorAs(t, err, &batchErr)
+	require.Len(t, records, 3)
+	assert.Equal(t, "acc-1", records[0].ID)
+	assert.Empty(t, records[1].ID)
+	assert.NotEmpty(t, records[2].ID)
+	assert.Equal(t, map[int]error{1: batchErr[0].Err}, batchItemErrors(err))
+
+	records, err = svc.UpdateRecords(ctx, "account", []RecordUpdate{
+		{ID: "acc-1", Data: map[string]any{"name": "renamed"}},
+		{ID: "missing", Data: map[string]any{"name": "x"}},
+	}, BatchOptions{})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+	assert.Equal(t, "renamed", records[0].Data["name"])
+
+	resp, err := h.BatchCreateRecords(ctx, &entityPb.BatchCreateRecordsRequest{
+		Entity: "account",
+		Records: []*structpb.Struct{
+			{Fields: map[string]*structpb.Value{"id": structpb.NewStringValue("acc-2")}},
+			{Fields: map[string]*structpb.Value{"id": structpb.NewStringValue("acc-1")}},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.GetResults(), 2)
+	assert.Equal(t, "acc-2", resp.GetResults()[0].GetRecord().GetId())
+	assert.Contains(t, resp.GetResults()[1].GetError(), "already exists")
+}
+
+func TestService_AtomicBatch(t *testing.T) {
+	store, err := eventstore.New(context.Background(), "sqlite", filepath.Join(t.TempDir(), "events.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close(context.Background()) })
+	svc := newTestService(t)
+	svc.store = store
+	ctx := testContext()
+	h := &grpcHandler{svc: svc}
+
+	// Atomic batches are validated before anything is written.
+	_, err = svc.CreateRecords(ctx, "account", []map[string]any{
+		{"id": "acc-1", "name": "first"},
+		{"id": "acc-1", "name": "again"},
+	}, BatchOptions{Atomic: true})
+	var batchErr BatchError
+	require.ErrorAs(t, err, &batchErr)
+	require.Len(t, batchErr, 1)
+	assert.Equal(t, 1, batchErr[0].Index)
+	assert.NotErrorIs(t, err, ErrBatchAborted)
+	_, err = svc.GetRecord(ctx, "account", "acc-1")
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+
+	_, err = h.BatchCreateRecords(ctx, &entityPb.BatchCreateRecordsRequest{
+		Entity: "account",
+		Atomic: true,
+		Records: []*structpb.Struct{
+			{Fields: map[string]*structpb.Value{"id": structpb.NewStringValue("acc-1")}},
+			{Fields: map[string]*structpb.Value{"id": structpb.NewStringValue("acc-1")}},
+		},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-1", "name": "first"})
+	require.NoError(t, err)
+
+	// A failed write rolls back the items written before it.
+	_, err = h.BatchCreateRecords(ctx, &entityPb.BatchCreateRecordsRequest{
+		Entity: "account",
+		Atomic: true,
+		Records: []*structpb.Struct{
+			{Fields: map[string]*structpb.Value{"id": structpb.NewStringValue("acc-2")}},
+			{Fields: map[string]*structpb.Value{"id": structpb.NewStringValue("acc-1")}},
+		},
+	})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	assert.ErrorContains(t, err, "item 1 (acc-1)")
+	_, err = svc.GetRecord(ctx, "account", "acc-2")
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+
+	t.Run("provider", func(t *testing.T) {
+		eventsDB := newSQLiteDB(t, "events.db")
+		store, err := eventstore.NewFromBun(ctx, eventsDB)
+		require.NoError(t, err)
+		svc := newTestService(t)
+		svc.store = store
+		def, err := svc.Definition("account")
+		require.NoError(t, err)
+		batch := []map[string]any{{"id": "acc-2"}, {"id": "acc-1"}}
+
+		// Writes of a provider outside the transaction cannot be rolled back.
+		separate := NewSQLProvider(newSQLiteDB(t, "records.db"))
+		require.NoError(t, separate.CreateTable(ctx, def))
+		svc.RegisterProvider("account", separate)
+		_, err = svc.CreateRecords(ctx, "account", batch, BatchOptions{Atomic: true})
+		assert.ErrorIs(t, err, ErrAtomicUnsupported)
+
+		shared := NewSQLProvider(eventsDB, WithEventStoreTx())
+		require.NoError(t, shared.CreateTable(ctx, def))
+		svc.RegisterProvider("account", shared)
+		_, err = svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-1"})
+		require.NoError(t, err)
+		_, err = svc.CreateRecords(ctx, "account", batch, BatchOptions{Atomic: true})
+		require.ErrorIs(t, err, ErrBatchAborted)
+		_, err = shared.Get(ctx, def, "acc-2")
+		assert.ErrorIs(t, err, ErrRecordNotFound, "the provider write is rolled back")
+	})
+}
+
+func TestService_FieldValidation(t *testing.T) {
+	svc := newTestService(t)
+	def, err := parseDefinition([]byte(`
+id: product
+primary_key: id
+fields:
+  - id: id
+    type: string
+  - id: sku
+    type: string
+    validation:
+      pattern: '[A-Z]{3}-\d+'
+      max_length: 8
+  - id: price
+    type: number
+    validation:
+      min: 0
+      max: 1000
+  - id: status
+    type: enum
+    validation:
+      enum: [draft, published]
+  - id: tags
+    type: string
+    repeated: true
+    validation:
+      enum: [new, sale]
+`))
+	require.NoError(t, err)
+	svc.defs[def.ID] = def
+	ctx := testContext()
+
+	_, err = svc.CreateRecord(ctx, "product", map[string]any{"sku": "ABC-12", "price": 10, "status": "draft", "tags": []any{"sale"}})
+	require.NoError(t, err)
+
+	_, err = svc.CreateRecord(ctx, "product", map[string]any{"sku": "abc", "price": -1, "status": "archived", "tags": []any{"new", "old"}})
+	var validationErrs ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
+	messages := make(map[string]string)
+	for _, e := range validationErrs {
+		messages[e.Field] = e.Message
+	}
+	assert.Equal(t, map[string]string{
+		"sku":    "does not match the required pattern",
+		"price":  "must be at least 0",
+		"status": "must be one of draft, published",
+		"tags":   "must be one of new, sale",
+	}, messages)
+
+	_, err = svc.CreateRecord(ctx, "product", map[string]any{"sku": "ABC-123456"})
+	assert.ErrorContains(t, err, "sku: must be at most 8 characters")
+
+	_, err = parseDefinition([]byte("id: bad\nprimary_key: id\nfields:\n  - id: id\n    type: string\n    validation:\n      min: 1\n"))
+	assert.ErrorContains(t, err, "min and max are only supported for number fields")
+}
+
+func TestService_FieldDefaults(t *testing.T) {
+	svc := newTestService(t)
+	def, err := parseDefinition([]byte(`
+id: task
+primary_key: id
+fields:
+  - id: id
+    type: string
+  - id: status
+    type: enum
+    required: true
+    default: active
+  - id: priority
+    type: number
+    default: 3
+  - id: due_at
+    type: datetime
+    default: now()
+`))
+	require.NoError(t, err)
+	svc.defs[def.ID] = def
+	ctx := testContext()
+
+	t.Run("should apply defaults on create", func(t *testing.T) {
+		before := time.Now().UTC().Add(-time.Second)
+		record, err := svc.CreateRecord(ctx, "task", map[string]any{"id": "t-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "active", record.Data["status"])
+		assert.Equal(t, json.Number("3"), record.Data["priority"])
+
+		dueAt, err := time.Parse(time.RFC3339Nano, record.Data["due_at"].(string))
+		require.NoError(t, err)
+		assert.True(t, dueAt.After(before))
+	})
+
+	t.Run("should keep explicit values", func(t *testing.T) {
+		record, err := svc.CreateRecord(ctx, "task", map[string]any{"id": "t-2", "status": "done", "priority": nil})
+		require.NoError(t, err)
+		assert.Equal(t, "done", record.Data["status"])
+		assert.Nil(t, record.Data["priority"])
+	})
+
+	t.Run("should not apply defaults on update", func(t *testing.T) {
+		_, err := svc.UpdateRecord(ctx, "task", "t-2", map[string]any{"status": "archived"})
+		require.NoError(t, err)
+		record, err := svc.GetRecord(ctx, "task", "t-2")
+		require.NoError(t, err)
+		assert.Equal(t, "archived", record.Data["status"])
+		assert.Nil(t, record.Data["priority"])
+	})
+
+	t.Run("should type-check defaults at load time", func(t *testing.T) {
+		for _, field := range []string{
+			"  - id: flag\n    type: boolean\n    default: maybe\n",
+			"  - id: count\n    type: number\n    default: many\n",
+			"  - id: name\n    type: string\n    default: now()\n",
+		} {
+			_, err := parseDefinition([]byte("id: bad\nprimary_key: id\nfields:\n  - id: id\n    type: string\n" + field))
+			assert.ErrorContains(t, err, "default", field)
+		}
+	})
+}
+
+func TestFilterValueFromProto(t *testing.T) {
+	list, err := structpb.NewList([]any{1234567, "8"})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name      string
+		fieldType entityPb.FieldType
+		value     *structpb.Value
+		want      any
+	}{
+		{"integral number", entityPb.FieldType_FIELD_TYPE_NUMBER, structpb.NewNumberValue(42), int64(42)},
+		{"fractional number", entityPb.FieldType_FIELD_TYPE_NUMBER, structpb.NewNumberValue(1.5), 1.5},
+		{"numeric string", entityPb.FieldType_FIELD_TYPE_NUMBER, structpb.NewStringValue("9007199254740993"), int64(9007199254740993)},
+		{"number as string id", entityPb.FieldType_FIELD_TYPE_STRING, structpb.NewNumberValue(1234567), "1234567"},
+		{"string", entityPb.FieldType_FIELD_TYPE_UUID, structpb.NewStringValue("abc"), "abc"},
+		{"bool", entityPb.FieldType_FIELD_TYPE_BOOLEAN, structpb.NewBoolValue(true), true},
+		{"bool as string", entityPb.FieldType_FIELD_TYPE_STRING, structpb.NewBoolValue(false), "false"},
+		{"unix seconds", entityPb.FieldType_FIELD_TYPE_DATETIME, structpb.NewNumberValue(1700000000), "2023-11-14T22:13:20Z"},
+		{"null", entityPb.FieldType_FIELD_TYPE_STRING, structpb.NewNullValue(), nil},
+		{"list", entityPb.FieldType_FIELD_TYPE_STRING, structpb.NewListValue(list), []any{"1234567", "8"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, filterValueFromProto(tc.fieldType, tc.value))
+		})
+	}
+}
+
+func TestGRPCHandler_TypedFilters(t *testing.T) {
+	svc, ctx := newReferenceTestService(t)
+	_, err := svc.UpdateRecord(ctx, "account", "acc-1", map[string]any{"external_id": json.Number("9007199254740993")})
+	require.NoError(t, err)
+	h := &grpcHandler{svc: svc}
+
+	resp, err := h.ListRecords(ctx, &entityPb.ListRecordsRequest{
+		Entity: "contact",
+		Filters: []*entityPb.FilterExpression{{
+			Field:    "account_id.external_id",
+			Operator: entityPb.FilterOperator_FILTER_OPERATOR_EQ,
+			Value:    structpb.NewStringValue("9007199254740993"),
+		}},
+	})
+	require.NoError(t, err)
+	ids := make([]string, 0, len(resp.GetRecords()))
+	for _, record := range resp.GetRecords() {
+		ids = append(ids, record.GetId())
+	}
+	assert.ElementsMatch(t, []string{"c-1", "c-3"}, ids)
+}
+
+func TestService_IDFormat(t *testing.T) {
+	svc := newTestService(t)
+	ctx := testContext()
+	for entity, format := range map[string]string{"uuid_ids": "uuid", "slug_ids": "slug", "pattern_ids": `acc-\d+`} {
+		def, err := parseDefinition([]byte("id: " + entity + "\nprimary_key: id\nid_format: '" + format + "'\nfields:\n  - id: id\n    type: string\n"))
+		require.NoError(t, err)
+		svc.defs[def.ID] = def
+	}
+
+	cases := []struct {
+		entity string
+		id     string
+		valid  bool
+	}{
+		{"uuid_ids", "0b8f3c1e-4c59-4d3a-9d0e-6a2f4f1b7c11", true},
+		{"uuid_ids", "not-a-uuid", false},
+		{"slug_ids", "summer-sale-2024", true},
+		{"slug_ids", "Summer Sale", false},
+		{"slug_ids", "a:b", false},
+		{"pattern_ids", "acc-42", true},
+		{"pattern_ids", "xacc-42", false},
+	}
+	for _, tc := range cases {
+		_, err := svc.CreateRecord(ctx, tc.entity, map[string]any{"id": tc.id})
+		if tc.valid {
+			assert.NoError(t, err, tc.id)
+		} else {
+			assert.ErrorIs(t, err, ErrInvalidID, tc.id)
+		}
+	}
+
+	// Generated ids are not checked.
+	record, err := svc.CreateRecord(ctx, "slug_ids", map[string]any{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, record.ID)
+
+	_, err = (&grpcHandler{svc: svc}).CreateRecord(ctx, &entityPb.CreateRecordRequest{
+		Entity: "slug_ids",
+		Data:   &structpb.Struct{Fields: map[string]*structpb.Value{"id": structpb.NewStringValue("Bad Id")}},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = parseDefinition([]byte("id: bad\nprimary_key: id\nid_format: '('\nfields:\n  - id: id\n    type: string\n"))
+	assert.ErrorContains(t, err, "id_format")
+}
+
+func TestService_SystemActor(t *testing.T) {
+	svc := newTestService(t)
+	payload := map[string]any{"id": "acc-1", "name": "first"}
+
+	_, err := svc.CreateRecord(context.Background(), "account", payload)
+	require.ErrorIs(t, err, ErrUnauthenticated)
+
+	record, err := svc.CreateRecord(WithSystemActor(context.Background(), "import"), "account", payload)
+	require.NoError(t, err)
+	assert.Equal(t, "system:import", record.CreatedBy)
+
+	// A real identity wins over the system actor.
+	ctx := WithSystemActor(testContext(), "system:migration")
+	record, err = svc.UpdateRecord(ctx, "account", "acc-1", map[string]any{"name": "renamed"})
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", record.UpdatedBy)
+
+	// Providers get the same actor on delete.
+	provider := &actorProvider{}
+	svc.RegisterProvider("account", provider)
+	require.ErrorIs(t, svc.DeleteRecord(context.Background(), "account", "acc-1"), ErrUnauthenticated)
+	require.NoError(t, svc.DeleteRecord(WithSystemActor(context.Background(), "import"), "account", "acc-1"))
+	assert.Equal(t, []string{"system:import"}, provider.actors)
+}
+
+// actorProvider records the actor providers see on delete.
+type actorProvider struct {
+	Provider
+	actors []string
+}
+
+func (p *actorProvider) Delete(ctx context.Context, _ Definition, _ string) error {
+	actorID, err := actorIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	p.actors = append(p.actors, actorID)
+	return nil
+}
+
+func TestService_AllowAnonymous(t *testing.T) {
+	svc := newTestService(t)
+	payload := map[string]any{"id": "acc-1", "name": "first"}
+
+	_, err := svc.CreateRecord(context.Background(), "account", payload)
+	require.ErrorIs(t, err, ErrUnauthenticated)
+
+	svc.SetAllowAnonymous(true)
+	record, err := svc.CreateRecord(context.Background(), "account", payload)
+	require.NoError(t, err)
+	assert.Equal(t, AnonymousActor, record.CreatedBy)
+
+	record, err = svc.UpdateRecord(testContext(), "account", "acc-1", map[string]any{"name": "renamed"})
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", record.UpdatedBy)
+
+	provider := &actorProvider{}
+	svc.RegisterProvider("account", provider)
+	require.NoError(t, svc.DeleteRecord(context.Background(), "account", "acc-1"))
+	assert.Equal(t, []string{AnonymousActor}, provider.actors)
+}
+
+func TestRecordLocation(t *testing.T) {
+	annotate := func(method string) context.Context {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/entities/account", nil)
+		ctx, err := runtime.AnnotateContext(context.Background(), runtime.NewServeMux(), req, method)
+		require.NoError(t, err)
+		return ctx
+	}
+	record := &entityPb.Record{Entity: "account", Id: "acc 1"}
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, RecordLocation(annotate(entityPb.EntityService_CreateRecord_FullMethodName), rec, record))
+	assert.Equal(t, "/api/v1/entities/account/acc%201", rec.Header().Get("Location"))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = httptest.NewRecorder()
+	require.NoError(t, RecordLocation(annotate(entityPb.EntityService_UpdateRecord_FullMethodName), rec, record))
+	assert.Empty(t, rec.Header().Get("Location"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestSQLProvider(t *testing.T) {
+	sqldb, err := sql.Open(sqliteshim.ShimName, "file::memory:?cache=shared")
+	require.NoError(t, err)
+	sqldb.SetMaxOpenConns(1)
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	t.Cleanup(func() { _ = db.Close() })
+
+	svc := newTestService(t)
+	def, err := svc.Definition("account")
+	require.NoError(t, err)
+
+	ctx := testContext()
+	provider := NewSQLProvider(db)
+	require.NoError(t, provider.CreateTable(ctx, def))
+	svc.RegisterProvider("account", provider)
+
+	for i, name := range []string{"bravo", "Alpha", "charlie"} {
+		_, err := svc.CreateRecord(ctx, "account", map[string]any{
+			"id":          "acc-" + name,
+			"external_id": json.Number([]string{"10", "2.5", "30"}[i]),
+			"name":        name,
+		})
+		require.NoError(t, err)
+	}
+
+	_, err = svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-bravo", "name": "again"})
+	assert.Error(t, err)
+	_, err = provider.Create(context.Background(), def, map[string]any{"id": "acc-anon", "name": "anon"})
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	record, err := svc.GetRecord(ctx, "account", "acc-Alpha")
+	require.NoError(t, err)
+	assert.Equal(t, json.Number("2.5"), record.Data["external_id"])
+	assert.Equal(t, "user-1", record.CreatedBy)
+	assert.EqualValues(t, 1, record.Version)
+
+	records, next, err := svc.ListRecords(ctx, "account", ListOptions{SortField: "name", PageSize: 2})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "Alpha", records[0].Data["name"])
+	assert.Equal(t, "bravo", records[1].Data["name"])
+	require.NotEmpty(t, next)
+
+	records, next, err = svc.ListRecords(ctx, "account", ListOptions{SortField: "name", PageSize: 2, PageToken: next})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "charlie", records[0].Data["name"])
+	assert.Empty(t, next)
+
+	records, _, err = svc.ListRecords(ctx, "account", ListOptions{
+		Filters: []Filter{{
+			FieldID:  "external_id",
+			Operator: entityPb.FilterOperator_FILTER_OPERATOR_GT,
+			Value:    json.Number("5"),
+		}},
+		PageSize: 10,
+	})
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	records, _, err = svc.ListRecords(ctx, "account", ListOptions{
+		OrGroups: [][]Filter{{
+			{FieldID: "name", Operator: entityPb.FilterOperator_FILTER_OPERATOR_EQ, Value: "Alpha"},
+			{FieldID: "external_id", Operator: entityPb.FilterOperator_FILTER_OPERATOR_GT, Value: json.Number("20")},
+		}},
+		PageSize: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "acc-Alpha", records[0].ID)
+	assert.Equal(t, "acc-charlie", records[1].ID)
+
+	result, err := svc.ListRecordsPage(ctx, "account", ListOptions{Search: "LIE", PageSize: 10, IncludeTotalCount: true})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 1)
+	assert.Equal(t, "acc-charlie", result.Records[0].ID)
+	require.NotNil(t, result.TotalCount)
+	assert.EqualValues(t, 1, *result.TotalCount)
+
+	stats, err := svc.Stats(ctx, "account", StatsOptions{
+		Filters:    []Filter{{FieldID: "external_id", Operator: entityPb.FilterOperator_FILTER_OPERATOR_GT, Value: json.Number("5")}},
+		GroupField: "name",
+		Interval:   entityPb.StatsInterval_STATS_INTERVAL_DAY,
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, stats.Total)
+	assert.Equal(t, []StatsCount{{Key: "bravo", Count: 1}, {Key: "charlie", Count: 1}}, stats.Groups)
+	require.Len(t, stats.Buckets, 1)
+	assert.EqualValues(t, 2, stats.Buckets[0].Count)
+	assert.Equal(t, statsBucketStart(time.Now(), entityPb.StatsInterval_STATS_INTERVAL_DAY), stats.Buckets[0].Start)
+
+	record, err = svc.UpdateRecord(ctx, "account", "acc-bravo", map[string]any{"name": "beta"})
+	require.NoError(t, err)
+	assert.Equal(t, "beta", record.Data["name"])
+	assert.Equal(t, json.Number("10"), record.Data["external_id"])
+	assert.EqualValues(t, 2, record.Version)
+
+	require.NoError(t, svc.DeleteRecord(ctx, "account", "acc-bravo"))
+	_, err = svc.GetRecord(ctx, "account", "acc-bravo")
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+// newSQLiteDB opens a sqlite database file in a temporary directory.
+func newSQLiteDB(t *testing.T, name string) *bun.DB {
+	t.Helper()
+	sqldb, err := sql.Open(sqliteshim.ShimName, filepath.Join(t.TempDir(), name))
+	require.NoError(t, err)
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestSQLProvider_EventStoreTx(t *testing.T) {
+	ctx := testContext()
+	eventsDB := newSQLiteDB(t, "events.db")
+	store, err := eventstore.NewFromBun(ctx, eventsDB)
+	require.NoError(t, err)
+	svc := newTestService(t)
+	svc.store = store
+	def, err := svc.Definition("account")
+	require.NoError(t, err)
+
+	// A provider on its own database writes there even inside a unit of work.
+	separate := NewSQLProvider(newSQLiteDB(t, "records.db"))
+	require.NoError(t, separate.CreateTable(ctx, def))
+	svc.RegisterProvider("account", separate)
+	_, err = svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-1", "name": "first"})
+	require.NoError(t, err)
+	_, err = separate.Get(ctx, def, "acc-1")
+	require.NoError(t, err)
+
+	// A provider sharing the event store database rolls back with it.
+	shared := NewSQLProvider(eventsDB, WithEventStoreTx())
+	require.NoError(t, shared.CreateTable(ctx, def))
+	svc.RegisterProvider("account", shared)
+	errRollback := errors.New("rollback")
+	err = svc.inUnitOfWork(ctx, func(ctx context.Context) error {
+		if _, err := svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-2"}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+	_, err = shared.Get(ctx, def, "acc-2")
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+
+	_, err = svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-2"})
+	require.NoError(t, err)
+	_, err = shared.Get(ctx, def, "acc-2")
+	require.NoError(t, err)
+}
+
+func TestService_DeepPagination(t *testing.T) {
+	svc := newTestService(t)
+	ctx := testContext()
+
+	for _, action := range []string{DeepOffsetReject, DeepOffsetWarn} {
+		def, err := parseDefinition([]byte(`
+id: note_` + action + `
+primary_key: id
+pagination:
+  max_offset: 1
+  on_deep_offset: ` + action + `
+fields:
+  - id: id
+    type: string
+`))
+		require.NoError(t, err)
+		svc.defs[def.ID] = def
+
+		// Record ids name their event streams, so they differ per entity.
+		for _, id := range []string{"n-1", "n-2", "n-3"} {
+			_, err := svc.CreateRecord(ctx, def.ID, map[string]any{"id": action + "-" + id})
+			require.NoError(t, err)
+		}
+
+		_, err = svc.ListRecordsPage(ctx, def.ID, ListOptions{PageSize: 1, PageToken: "1"})
+		require.NoError(t, err)
+
+		result, err := svc.ListRecordsPage(ctx, def.ID, ListOptions{PageSize: 1, PageToken: "2"})
+		if action == DeepOffsetReject {
+			require.ErrorIs(t, err, ErrDeepPagination)
+			assert.ErrorContains(t, err, "narrow the filters")
+			assert.NotContains(t, err.Error(), "next_page_token", "keyset tokens need streaming lists")
+
+			svc.SetStreamingList(true)
+			_, err = svc.ListRecordsPage(ctx, def.ID, ListOptions{PageSize: 1, PageToken: "2"})
+			assert.ErrorContains(t, err, "sort on id without a total count")
+			svc.SetStreamingList(false)
+
+			// Internal aggregation scans page past the limit.
+			_, err = svc.ListRecordsPage(withFullScan(ctx), def.ID, ListOptions{PageSize: 1, PageToken: "2"})
+			require.NoError(t, err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Len(t, result.Records, 1)
+	}
+
+	_, err := parseDefinition([]byte(`
+id: note
+primary_key: id
+pagination:
+  max_offset: 1
+  on_deep_offset: ignore
+fields:
+  - id: id
+    type: string
+`))
+	require.Error(t, err)
+}
+
+func TestService_ReadTransform(t *testing.T) {
+	svc := newTestService(t)
+	ctx := testContext()
+
+	svc.RegisterReadTransform("account", func(ctx context.Context, record *Record) error {
+		if identity.FromContext(ctx).GetID() != "admin" {
+			record.Data["name"] = "***"
+		}
+		return nil
+	})
+
+	created, err := svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-1", "name": "Acme"})
+	require.NoError(t, err)
+	assert.Equal(t, "Acme", created.Data["name"])
+
+	record, err := svc.GetRecord(ctx, "account", "acc-1")
+	require.NoError(t, err)
+	assert.Equal(t, "***", record.Data["name"])
+
+	adminCtx := identity.NewContext(context.Background(), identity.NewIdentity("admin"))
+	record, err = svc.GetRecord(adminCtx, "account", "acc-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Acme", record.Data["name"])
+
+	records, _, err := svc.ListRecords(ctx, "account", ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "***", records[0].Data["name"])
+
+	history, _, err := svc.RecordHistory(ctx, "account", "acc-1", HistoryOptions{})
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "***", history[0].Data["name"])
+	assert.Nil(t, history[0].RawPayload)
+
+	svc.RegisterReadTransform("account", func(ctx context.Context, record *Record) error {
+		return fmt.Errorf("denied")
+	})
+	_, err = svc.GetRecord(ctx, "account", "acc-1")
+	assert.ErrorContains(t, err, "denied")
+}
+
+func TestService_RestoreRecord(t *testing.T) {
+	svc := newTestService(t)
+	ctx := testContext()
+
+	_, err := svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-1", "name": "Acme"})
+	require.NoError(t, err)
+
+	_, err = svc.RestoreRecord(ctx, "account", "acc-1")
+	require.ErrorIs(t, err, ErrNotDeleted)
+
+	require.NoError(t, svc.DeleteRecord(ctx, "account", "acc-1"))
+	_, err = svc.GetRecord(ctx, "account", "acc-1")
+	require.ErrorIs(t, err, ErrRecordDeleted)
+
+	restored, err := svc.RestoreRecord(ctx, "account", "acc-1")
+	require.NoError(t, err)
+	assert.False(t, restored.Deleted)
+	assert.EqualValues(t, 3, restored.Version)
+	assert.Equal(t, "Acme", restored.Data["name"])
+
+	record, err := svc.GetRecord(ctx, "account", "acc-1")
+	require.NoError(t, err)
+	assert.Equal(t, restored.Version, record.Version)
+
+	records, _, err := svc.ListRecords(ctx, "account", ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	history, _, err := svc.RecordHistory(ctx, "account", "acc-1", HistoryOptions{})
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, eventTypeRecordRestored, history[0].EventType)
+	assert.False(t, history[0].Deleted)
+
+	_, err = svc.RestoreRecord(ctx, "account", "acc-1")
+	require.ErrorIs(t, err, ErrNotDeleted)
+}
+
+func TestService_WriteHook(t *testing.T) {
+	svc := newTestService(t)
+	ctx := testContext()
+
+	var actions []WriteAction
+	svc.RegisterWriteHook("account", func(ctx context.Context, action WriteAction, data map[string]any) error {
+		actions = append(actions, action)
+		name, ok := data["name"].(string)
+		if !ok {
+			return nil
+		}
+		if name == "" {
+			return ValidationErrors{{Field: "name", Message: "must not be blank"}}
+		}
+		data["name"] = strings.ToUpper(name)
+		return nil
+	})
+
+	record, err := svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-1", "name": "acme"})
+	require.NoError(t, err)
+	assert.Equal(t, "ACME", record.Data["name"])
+
+	record, err = svc.UpdateRecord(ctx, "account", "acc-1", map[string]any{"name": "globex"})
+	require.NoError(t, err)
+	assert.Equal(t, "GLOBEX", record.Data["name"])
+
+	_, err = svc.UpdateRecord(ctx, "account", "acc-1", map[string]any{"name": ""})
+	require.ErrorIs(t, err, ErrValidation)
+
+	record, err = svc.GetRecord(ctx, "account", "acc-1")
+	require.NoError(t, err)
+	assert.Equal(t, "GLOBEX", record.Data["name"])
+	assert.Equal(t, []WriteAction{WriteActionCreate, WriteActionUpdate, WriteActionUpdate}, actions)
+}
+
+// feedStore adds the aggregate-type feed to memoryStore, numbering events in
+// append order.
+type feedStore struct {
+	*memoryStore
+	feedMu sync.Mutex
+	feed   []eventstore.Event
+}
+
+func (f *feedStore) Append(ctx context.Context, streamID string, expectedVersion int64, events []eventstore.Event) error {
+	if err := f.memoryStore.Append(ctx, streamID, expectedVersion, events); err != nil {
+		return err
+	}
+	stored, _ := f.memoryStore.Load(ctx, streamID, 0)
+
+	f.feedMu.Lock()
+	defer f.feedMu.Unlock()
+	for _, evt := range stored[len(stored)-len(events):] {
+		evt.ID = int64(len(f.feed) + 1)
+		f.feed = append(f.feed, evt)
+	}
+	return nil
+}
+
+func (f *feedStore) LoadByType(_ context.Context, aggregateType string, fromID int64, limit int) ([]eventstore.Event, error) {
+	f.feedMu.Lock()
+	defer f.feedMu.Unlock()
+
+	var out []eventstore.Event
+	for _, evt := range f.feed {
+		if evt.ID >= fromID && evt.AggregateType == aggregateType && len(out) < limit {
+			out = append(out, evt)
+		}
+	}
+	return out, nil
+}
+
+func TestService_StreamChanges(t *testing.T) {
+	svc := newTestService(t)
+	ctx := testContext()
+
+	require.ErrorIs(t, svc.StreamChanges(ctx, "account", ChangeStreamOptions{}, nil), ErrFeedUnsupported)
+	svc.store = &feedStore{memoryStore: newMemoryStore()}
+
+	svc.RegisterReadTransform("account", func(ctx context.Context, record *Record) error {
+		if record.Data["name"] == "Secret" {
+			record.Data["name"] = "***"
+		}
+		return nil
+	})
+
+	_, err := svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-1", "name": "Acme"})
+	require.NoError(t, err)
+	_, err = svc.UpdateRecord(ctx, "account", "acc-1", map[string]any{"name": "Secret"})
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteRecord(ctx, "account", "acc-1"))
+	_, err = svc.RestoreRecord(ctx, "account", "acc-1")
+	require.NoError(t, err)
+
+	errStop := errors.New("stop")
+	collect := func(from int64, n int) []Change {
+		var changes []Change
+		err := svc.StreamChanges(ctx, "account", ChangeStreamOptions{FromPosition: from, Interval: 10 * time.Millisecond}, func(c Change) error {
+			changes = append(changes, c)
+			if len(changes) == n {
+				return errStop
+			}
+			return nil
+		})
+		require.ErrorIs(t, err, errStop)
+		return changes
+	}
+
+	changes := collect(0, 4)
+	types := make([]ChangeType, 0, len(changes))
+	for _, c := range changes {
+		types = append(types, c.Type)
+		assert.Equal(t, "acc-1", c.RecordID)
+		assert.Equal(t, "user-1", c.Actor)
+	}
+	assert.Equal(t, []ChangeType{ChangeCreated, ChangeUpdated, ChangeDeleted, ChangeRestored}, types)
+	assert.Nil(t, changes[0].Before)
+	assert.Equal(t, "Acme", changes[0].After["name"])
+	assert.Equal(t, "Acme", changes[1].Before["name"])
+	assert.Equal(t, "***", changes[1].After["name"], "read transforms should apply")
+	assert.Nil(t, changes[2].After)
+	assert.EqualValues(t, 3, changes[2].Version)
+
+	// Resuming rebuilds the before image from the record stream.
+	resumed := collect(changes[0].Position+1, 1)
+	assert.Equal(t, changes[1], resumed[0])
+
+	// New changes are followed after the replay.
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_, _ = svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-2", "name": "Globex"})
+	}()
+	live := collect(changes[3].Position+1, 1)
+	assert.Equal(t, "acc-2", live[0].RecordID)
+	assert.Equal(t, ChangeCreated, live[0].Type)
+}
+
+func TestService_StreamChangesLegacyRecords(t *testing.T) {
+	svc := newTestService(t)
+	ctx := testContext()
+	svc.store = &feedStore{memoryStore: newMemoryStore()}
+
+	legacyEvent := func(eventType string, data map[string]any) eventstore.Event {
+		payload, err := svc.payloadCodec().Marshal(recordPayload{Data: data})
+		require.NoError(t, err)
+		meta, err := svc.metadataCodec().Marshal(EventMetadata{Entity: "account", RecordID: "acc-0", ActorID: "user-0", Timestamp: time.Now()})
+		require.NoError(t, err)
+		return eventstore.Event{AggregateType: aggregateType("account"), Type: eventType, Payload: payload, Metadata: meta}
+	}
+	require.NoError(t, svc.store.Append(ctx, legacyRecordStreamID("account", "acc-0"), 0, []eventstore.Event{
+		legacyEvent(eventTypeRecordCreated, map[string]any{"id": "acc-0", "name": "Initech"}),
+		legacyEvent(eventTypeRecordUpdated, map[string]any{"name": "Initrode"}),
+	}))
+	_, err := svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-1", "name": "Acme"})
+	require.NoError(t, err)
+
+	errStop := errors.New("stop")
+	collect := func(from int64, n int) []Change {
+		var changes []Change
+		err := svc.StreamChanges(ctx, "account", ChangeStreamOptions{FromPosition: from, Interval: 10 * time.Millisecond}, func(c Change) error {
+			changes = append(changes, c)
+			if len(changes) == n {
+				return errStop
+			}
+			return nil
+		})
+		require.ErrorIs(t, err, errStop)
+		return changes
+	}
+
+	changes := collect(0, 3)
+	assert.Equal(t, "acc-0", changes[0].RecordID, "legacy stream ids should map to the record id")
+	assert.Equal(t, ChangeCreated, changes[0].Type)
+	assert.Equal(t, "acc-0", changes[1].RecordID)
+	assert.Equal(t, "Initech", changes[1].Before["name"])
+	assert.Equal(t, "Initrode", changes[1].After["name"])
+	assert.Equal(t, "acc-1", changes[2].RecordID)
+
+	// Resuming rebuilds the before image of legacy records from their stream.
+	resumed := collect(changes[1].Position, 1)
+	assert.Equal(t, changes[1], resumed[0])
+
+	// The legacy copies of the events of acc-1 are not reported again.
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_, _ = svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-2", "name": "Globex"})
+	}()
+	live := collect(changes[2].Position+1, 1)
+	assert.Equal(t, "acc-2", live[0].RecordID)
+}
+
+func TestService_Compaction(t *testing.T) {
+	svc := newTestService(t)
+	ctx := testContext()
+	handler := &grpcHandler{svc: svc}
+
+	_, err := svc.CompactRecords(ctx, "account")
+	require.ErrorIs(t, err, ErrNoRetention)
+	_, err = handler.CompactRecords(ctx, &entityPb.CompactRecordsRequest{Entity: "account"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	def := svc.defs["account"]
+	def.Retention = RetentionPolicy{KeepVersions: 2}
+	svc.defs["account"] = def
+
+	// Compaction folds events into a snapshot the store has to keep.
+	_, err = svc.CompactRecords(ctx, "account")
+	require.ErrorIs(t, err, ErrCompactionUnsupported)
+	store := &snapshotStore{memoryStore: newMemoryStore(), snapshots: make(map[string]eventstore.Snapshot)}
+	svc.store = store
+
+	_, err = svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-1", "name": "v1"})
+	require.NoError(t, err)
+	for _, name := range []string{"v2", "v3", "v4", "v5"} {
+		_, err = svc.UpdateRecord(ctx, "account", "acc-1", map[string]any{"name": name})
+		require.NoError(t, err)
+	}
+	before, err := svc.GetRecord(ctx, "account", "acc-1")
+	require.NoError(t, err)
+
+	resp, err := handler.CompactRecords(ctx, &entityPb.CompactRecordsRequest{Entity: "account"})
+	require.NoError(t, err)
+	assert.Equal(t, &entityPb.CompactRecordsResponse{Entity: "account", Records: 1, RemovedEvents: 2}, resp)
+
+	events, err := svc.store.Load(ctx, "acc-1", 0)
+	require.NoError(t, err)
+	require.Len(t, events, 3, "the folded version and the two newest events should be kept")
+	assert.EqualValues(t, 3, events[0].Version)
+	assert.Equal(t, eventTypeRecordUpdated, events[0].Type)
+	snapshot := store.snapshots[compactedSnapshotID("acc-1")]
+	assert.EqualValues(t, 3, snapshot.Version)
+	assert.NotContains(t, store.snapshots, "acc-1", "periodic snapshots are left alone")
+
+	record, err := svc.GetRecord(ctx, "account", "acc-1")
+	require.NoError(t, err)
+	assert.Equal(t, "v5", record.Data["name"])
+	assert.EqualValues(t, 5, record.Version)
+	assert.True(t, before.CreatedAt.Equal(record.CreatedAt), "create time should survive compaction")
+	assert.Equal(t, before.CreatedBy, record.CreatedBy)
+
+	// History starts at the folded version.
+	history, _, err := svc.RecordHistory(ctx, "account", "acc-1", HistoryOptions{})
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.EqualValues(t, 3, history[2].Version)
+	assert.Equal(t, "v3", history[2].Data["name"])
+	assert.Equal(t, "v4", history[1].Data["name"])
+
+	// Nothing is left to fold on a second run.
+	removed, err := svc.CompactRecord(ctx, "account", "acc-1")
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+
+	// The stream carries on after its folded events.
+	record, err = svc.UpdateRecord(ctx, "account", "acc-1", map[string]any{"name": "v6"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 6, record.Version)
+	assert.True(t, before.CreatedAt.Equal(record.CreatedAt))
+
+	// Compacting again moves the folded state forward.
+	removed, err = svc.CompactRecord(ctx, "account", "acc-1")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, removed)
+	record, err = svc.GetRecord(ctx, "account", "acc-1")
+	require.NoError(t, err)
+	assert.Equal(t, "v6", record.Data["name"])
+
+	// Stores without compaction support are rejected up front.
+	svc.store = struct {
+		eventstore.Store
+		eventstore.Snapshotter
+	}{store, store}
+	_, err = svc.CompactRecords(ctx, "account")
+	require.ErrorIs(t, err, ErrCompactionUnsupported)
+	_, err = handler.CompactRecords(ctx, &entityPb.CompactRecordsRequest{Entity: "account", RecordId: "acc-1"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestService_CompactionWithSnapshots(t *testing.T) {
+	svc := newTestService(t)
+	ctx := testContext()
+	store := &snapshotStore{memoryStore: newMemoryStore(), snapshots: make(map[string]eventstore.Snapshot)}
+	svc.store = store
+	svc.SetSnapshotFrequency(5)
+	def := svc.defs["account"]
+	def.Retention = RetentionPolicy{KeepVersions: 1}
+	svc.defs["account"] = def
+
+	_, err := svc.CreateRecord(ctx, "account", map[string]any{"id": "acc-1", "name": "v1", "external_id": json.Number("1")})
+	require.NoError(t, err)
+	for i := 2; i <= 9; i++ {
+		patch := map[string]any{"name": fmt.Sprintf("v%d", i)}
+		if i == 7 {
+			patch["external_id"] = json.Number("7")
+		}
+		_, err = svc.UpdateRecord(ctx, "account", "acc-1", patch)
+		require.NoError(t, err)
+	}
+	require.EqualValues(t, 5, store.snapshots["acc-1"].Version)
+	before, err := svc.GetRecord(ctx, "account", "acc-1")
+	require.NoError(t, err)
+
+	// The folded version 8 lies past the periodic snapshot at version 5.
+	removed, err := svc.CompactRecord(ctx, "account", "acc-1")
+	require.NoError(t, err)
+	assert.EqualValues(t, 7, removed)
+
+	record, err := svc.GetRecord(ctx, "account", "acc-1")
+	require.NoError(t, err)
+	assert.Equal(t, before.Data, record.Data)
+	assert.EqualValues(t, 9, record.Version)
+
+	history, _, err := svc.RecordHistory(ctx, "account", "acc-1", HistoryOptions{})
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.EqualValues(t, 8, history[1].Version)
+	assert.Equal(t, json.Number("7"), history[1].Data["external_id"])
+	assert.Equal(t, json.Number("7"), history[0].Data["external_id"])
+
+	// The next periodic snapshot starts from the compacted state.
+	_, err = svc.UpdateRecord(ctx, "account", "acc-1", map[string]any{"name": "v10"})
+	require.NoError(t, err)
+	require.EqualValues(t, 10, store.snapshots["acc-1"].Version)
+	record, err = svc.GetRecord(ctx, "account", "acc-1")
+	require.NoError(t, err)
+	assert.Equal(t, "v10", record.Data["name"])
+	assert.Equal(t, json.Number("7"), record.Data["external_id"])
+}
+
+func TestService_RunCompactionStops(t *testing.T) {
+	svc := newTestService(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() { done <- svc.RunCompaction(ctx, time.Hour) }()
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("compaction should stop once its context is done")
+	}
+	assert.Error(t, svc.RunCompaction(context.Background(), 0))
+}
+
+func TestConfigureFromEnv(t *testing.T) {
+	svc := newTestService(t)
+	require.NoError(t, configureFromEnv(svc))
+	assert.Zero(t, svc.snapshotEvery, "snapshots are off by default")
+	assert.False(t, svc.streamingList)
+	assert.Equal(t, JSONCodec{}, svc.metadataCodec())
+
+	t.Setenv("ENTITIES_SNAPSHOT_FREQUENCY", "50")
+	t.Setenv("ENTITIES_STREAMING_LIST", "true")
+	t.Setenv("ENTITIES_METADATA_CODEC", "number")
+	require.NoError(t, configureFromEnv(svc))
+	assert.EqualValues(t, 50, svc.snapshotEvery)
+	assert.True(t, svc.streamingList)
+	assert.Equal(t, NumberJSONCodec{}, svc.metadataCodec())
+
+	t.Setenv("ENTITIES_METADATA_CODEC", "gob")
+	assert.ErrorContains(t, configureFromEnv(svc), "ENTITIES_METADATA_CODEC")
+
+	t.Setenv("ENTITIES_PAYLOAD_CODEC", "gob")
+	assert.ErrorContains(t, configureFromEnv(svc), "ENTITIES_PAYLOAD_CODEC")
+}
@@ -0,0 +1,187 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/tonica-go/tonica/pkg/tonica/modules/eventstore"
+)
+
+// SetSnapshotFrequency makes the service snapshot a record every n versions
+// when the event store implements eventstore.Snapshotter. Records are then
+// loaded from their latest snapshot plus the events appended after it
+// instead of replaying the whole stream. Zero or a negative n disables
+// snapshots, which is the default.
+func (s *Service) SetSnapshotFrequency(n int) {
+	s.snapshotEvery = int64(max(n, 0))
+}
+
+func (s *Service) snapshotter() (eventstore.Snapshotter, bool) {
+	if s.snapshotEvery <= 0 {
+		return nil, false
+	}
+	snapshotter, ok := s.store.(eventstore.Snapshotter)
+	return snapshotter, ok
+}
+
+// loadRecordFromSnapshot restores a record from its latest snapshot and
+// replays the newer events. It reports false when there is no snapshot to
+// start from or compaction cut past it, in which case the caller falls back
+// to a full replay.
+func (s *Service) loadRecordFromSnapshot(ctx context.Context, def Definition, recordID string) (Record, bool, error) {
+	snapshotter, ok := s.snapshotter()
+	if !ok {
+		return Record{}, false, nil
+	}
+
+	streamID := recordStreamID(def.ID, recordID)
+	snapshot, err := snapshotter.LoadSnapshot(ctx, streamID)
+	if errors.Is(err, eventstore.ErrSnapshotNotFound) {
+		return Record{}, false, nil
+	}
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	codec := s.payloadCodec()
+	state, err := recordFromSnapshot(codec, def, recordID, snapshot)
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	events, err := s.store.Load(ctx, streamID, snapshot.Version+1)
+	if err != nil {
+		return Record{}, false, err
+	}
+	if len(events) > 0 && events[0].Version > snapshot.Version+1 {
+		// Compaction removed events newer than the snapshot; they only
+		// survive in the compacted state the full replay starts from.
+		return Record{}, false, nil
+	}
+	record, err := replayRecordFrom(codec, s.metadataCodec(), def, state, events)
+	if err != nil {
+		return Record{}, false, err
+	}
+	return record, true, nil
+}
+
+// scheduleSnapshot snapshots a record whose stream just reached version when
+// the version is due. Inside a unit of work the snapshot is taken after
+// commit so it never captures events that may still roll back.
+func (s *Service) scheduleSnapshot(ctx context.Context, entityID, recordID string, version int64) {
+	if _, ok := s.snapshotter(); !ok || version%s.snapshotEvery != 0 {
+		return
+	}
+	if uow, ok := UnitOfWorkFromContext(ctx); ok {
+		uow.AfterCommit(func(ctx context.Context) {
+			s.saveSnapshot(ctx, entityID, recordID)
+		})
+		return
+	}
+	s.saveSnapshot(ctx, entityID, recordID)
+}
+
+// saveSnapshot stores the current state of a record. Failures only cost
+// read performance, so they are logged rather than returned.
+func (s *Service) saveSnapshot(ctx context.Context, entityID, recordID string) {
+	if err := s.writeSnapshot(ctx, entityID, recordID); err != nil {
+		slog.Warn("entity snapshot failed", "entity", entityID, "record", recordID, "error", err.Error())
+	}
+}
+
+func (s *Service) writeSnapshot(ctx context.Context, entityID, recordID string) error {
+	snapshotter, ok := s.snapshotter()
+	if !ok {
+		return nil
+	}
+	def, err := s.Definition(entityID)
+	if err != nil {
+		return err
+	}
+	record, err := s.loadRecord(ctx, def, recordID)
+	if err != nil {
+		return err
+	}
+
+	state, err := encodeSnapshot(s.payloadCodec(), record)
+	if err != nil {
+		return err
+	}
+	return snapshotter.SaveSnapshot(ctx, recordStreamID(entityID, recordID), record.Version, state)
+}
+
+// compactedSnapshotID names the snapshot that compaction folds the removed
+// events of streamID into. It is kept apart from the snapshots taken every
+// few versions, which move on and would lose the versions in between.
+func compactedSnapshotID(streamID string) string {
+	return streamID + ":compacted"
+}
+
+// replayBase returns the state a replay of the events of streamID starts
+// from. A stream whose first event is past version 1 has been compacted and
+// starts from its compacted snapshot; events up to the version of that
+// snapshot are already folded into it. Other streams start empty.
+func (s *Service) replayBase(ctx context.Context, def Definition, recordID, streamID string, events []eventstore.Event) (Record, error) {
+	if len(events) == 0 || events[0].Version <= 1 {
+		return Record{Entity: def.ID, ID: recordID, Data: make(map[string]any)}, nil
+	}
+
+	snapshotter, ok := s.store.(eventstore.Snapshotter)
+	if !ok {
+		return Record{}, fmt.Errorf("stream %s is compacted but the event store keeps no snapshots", streamID)
+	}
+	snapshot, err := snapshotter.LoadSnapshot(ctx, compactedSnapshotID(streamID))
+	if err != nil {
+		return Record{}, fmt.Errorf("load compacted state of %s: %w", streamID, err)
+	}
+	if snapshot.Version+1 < events[0].Version {
+		return Record{}, fmt.Errorf("compacted state of %s ends at version %d but its events start at %d",
+			streamID, snapshot.Version, events[0].Version)
+	}
+	return recordFromSnapshot(s.payloadCodec(), def, recordID, snapshot)
+}
+
+// eventsAfter returns the events newer than version.
+func eventsAfter(events []eventstore.Event, version int64) []eventstore.Event {
+	for i, evt := range events {
+		if evt.Version > version {
+			return events[i:]
+		}
+	}
+	return nil
+}
+
+func recordFromSnapshot(codec PayloadCodec, def Definition, recordID string, snapshot eventstore.Snapshot) (Record, error) {
+	payload, err := decodeSnapshotPayload(codec, snapshot.State)
+	if err != nil {
+		return Record{}, err
+	}
+	return Record{
+		Entity:    def.ID,
+		ID:        recordID,
+		Data:      cloneMap(payload.Data),
+		CreatedAt: payload.CreatedAt,
+		UpdatedAt: payload.UpdatedAt,
+		CreatedBy: payload.CreatedBy,
+		UpdatedBy: payload.UpdatedBy,
+		Version:   snapshot.Version,
+		Deleted:   payload.Deleted,
+	}, nil
+}
+
+func encodeSnapshot(codec PayloadCodec, record Record) ([]byte, error) {
+	state, err := codec.Marshal(snapshotPayload{
+		Data:      record.Data,
+		CreatedAt: record.CreatedAt,
+		CreatedBy: record.CreatedBy,
+		UpdatedAt: record.UpdatedAt,
+		UpdatedBy: record.UpdatedBy,
+		Deleted:   record.Deleted,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal snapshot: %w", err)
+	}
+	return state, nil
+}
@@ -0,0 +1,738 @@
+package entities
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	entityPb "github.com/tonica-go/tonica/pkg/tonica/proto/entities"
+)
+
+// Bookkeeping columns of SQL provider tables, next to one column per field.
+const (
+	sqlColVersion   = "_version"
+	sqlColCreatedAt = "_created_at"
+	sqlColUpdatedAt = "_updated_at"
+	sqlColCreatedBy = "_created_by"
+	sqlColUpdatedBy = "_updated_by"
+)
+
+// SQLProvider stores the records of an entity as rows of a SQL table with one
+// column per definition field, instead of as an event stream. Filters,
+// search, sorting and pagination run in the database. Register it with
+// Service.RegisterProvider and create its table with CreateTable.
+//
+// Object, array and repeated fields are stored as JSON text and cannot be
+// filtered on. Deletes remove the row; records have no history.
+type SQLProvider struct {
+	db     *bun.DB
+	table  string
+	joinTx bool
+}
+
+// SQLProviderOption configures a SQLProvider.
+type SQLProviderOption func(*SQLProvider)
+
+// WithSQLTable sets the table name. Defaults to "entities_<entity id>".
+func WithSQLTable(name string) SQLProviderOption {
+	return func(p *SQLProvider) {
+		p.table = name
+	}
+}
+
+// WithEventStoreTx makes writes join the transaction of the active unit of
+// work, so they commit or roll back together with the record events. Only use
+// it when db is the database of the event store; otherwise writes go to db
+// directly.
+func WithEventStoreTx() SQLProviderOption {
+	return func(p *SQLProvider) {
+		p.joinTx = true
+	}
+}
+
+// NewSQLProvider creates a provider storing records through db.
+func NewSQLProvider(db *bun.DB, opts ...SQLProviderOption) *SQLProvider {
+	p := &SQLProvider{db: db}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+var (
+	_ Provider      = (*SQLProvider)(nil)
+	_ RecordCounter = (*SQLProvider)(nil)
+	_ RecordStatter = (*SQLProvider)(nil)
+	_ TxProvider    = (*SQLProvider)(nil)
+)
+
+// JoinsUnitOfWork reports whether writes join the unit of work transaction,
+// see WithEventStoreTx.
+func (p *SQLProvider) JoinsUnitOfWork() bool {
+	return p.joinTx
+}
+
+// CreateTable creates the table of def if it does not exist yet. Columns of
+// fields added to def later are not added to an existing table.
+func (p *SQLProvider) CreateTable(ctx context.Context, def Definition) error {
+	columns := make([]string, 0, len(def.Fields)+5)
+	if _, ok := def.Field(def.PrimaryKey); !ok {
+		columns = append(columns, p.columnDDL(def.PrimaryKey, "VARCHAR(255) NOT NULL PRIMARY KEY"))
+	}
+	for _, field := range def.Fields {
+		if field.ID == def.PrimaryKey {
+			columns = append(columns, p.columnDDL(field.ID, "VARCHAR(255) NOT NULL PRIMARY KEY"))
+			continue
+		}
+		columns = append(columns, p.columnDDL(field.ID, p.columnType(field)))
+	}
+	columns = append(columns,
+		p.columnDDL(sqlColVersion, "BIGINT NOT NULL"),
+		p.columnDDL(sqlColCreatedAt, "VARCHAR(64) NOT NULL"),
+		p.columnDDL(sqlColUpdatedAt, "VARCHAR(64) NOT NULL"),
+		p.columnDDL(sqlColCreatedBy, "VARCHAR(255)"),
+		p.columnDDL(sqlColUpdatedBy, "VARCHAR(255)"),
+	)
+
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", p.ident(p.tableName(def)), strings.Join(columns, ", "))
+	_, err := p.db.ExecContext(ctx, query)
+	return err
+}
+
+// List returns a page of records matching opts.
+func (p *SQLProvider) List(ctx context.Context, def Definition, opts ListOptions) ([]Record, string, error) {
+	offset, err := parsePageToken(opts.PageToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	q := p.db.NewSelect().TableExpr("?", bun.Ident(p.tableName(def)))
+	if err := p.applyQuery(q, def, opts); err != nil {
+		return nil, "", err
+	}
+
+	sortField := opts.SortField
+	if sortField == "" {
+		sortField = def.PrimaryKey
+	}
+	dir := "ASC"
+	if opts.SortDir == entityPb.SortDirection_SORT_DIRECTION_DESC {
+		dir = "DESC"
+	}
+	if field, ok := def.Field(sortField); ok && isTextField(field) {
+		q.OrderExpr("LOWER(?) "+dir, bun.Ident(sortField))
+	} else {
+		q.OrderExpr("? "+dir, bun.Ident(sortField))
+	}
+	if sortField != def.PrimaryKey {
+		q.OrderExpr("? ASC", bun.Ident(def.PrimaryKey))
+	}
+
+	// One extra row tells whether there is a next page.
+	q.Limit(opts.PageSize + 1).Offset(offset)
+
+	var rows []map[string]any
+	if err := q.Scan(ctx, &rows); err != nil {
+		return nil, "", err
+	}
+
+	var nextToken string
+	if len(rows) > opts.PageSize {
+		rows = rows[:opts.PageSize]
+		nextToken = strconv.Itoa(offset + opts.PageSize)
+	}
+
+	records := make([]Record, 0, len(rows))
+	for _, row := range rows {
+		record, err := p.recordFromRow(def, row)
+		if err != nil {
+			return nil, "", err
+		}
+		records = append(records, record)
+	}
+	return records, nextToken, nil
+}
+
+// Count returns the number of records matching the filters and search of opts.
+func (p *SQLProvider) Count(ctx context.Context, def Definition, opts ListOptions) (int64, error) {
+	q := p.db.NewSelect().TableExpr("?", bun.Ident(p.tableName(def)))
+	if err := p.applyQuery(q, def, opts); err != nil {
+		return 0, err
+	}
+	count, err := q.Count(ctx)
+	return int64(count), err
+}
+
+// Stats counts the matching records in the database. Group counts are
+// grouped by the database; for time buckets only the time column is read.
+func (p *SQLProvider) Stats(ctx context.Context, def Definition, opts StatsOptions) (StatsResult, error) {
+	listOpts := ListOptions{Filters: opts.Filters}
+	total, err := p.Count(ctx, def, listOpts)
+	if err != nil {
+		return StatsResult{}, err
+	}
+	counter := newStatsCounter(opts)
+	counter.total = total
+
+	if opts.GroupField != "" {
+		field, _ := def.Field(opts.GroupField)
+		q := p.db.NewSelect().TableExpr("?", bun.Ident(p.tableName(def))).
+			ColumnExpr("? AS stats_key", bun.Ident(field.ID)).
+			ColumnExpr("COUNT(*) AS stats_count").
+			GroupExpr("?", bun.Ident(field.ID))
+		if err := p.applyQuery(q, def, listOpts); err != nil {
+			return StatsResult{}, err
+		}
+		var rows []map[string]any
+		if err := q.Scan(ctx, &rows); err != nil {
+			return StatsResult{}, err
+		}
+		for _, row := range rows {
+			value, err := scannedFieldValue(field, row["stats_key"])
+			if err != nil {
+				return StatsResult{}, err
+			}
+			count, _ := toInt64(scannedNumber(row["stats_count"]))
+			counter.addGroup(asString(value), count)
+		}
+	}
+
+	if opts.Interval != entityPb.StatsInterval_STATS_INTERVAL_UNSPECIFIED {
+		column := sqlColCreatedAt
+		if opts.TimeField != "" {
+			column = opts.TimeField
+		}
+		q := p.db.NewSelect().TableExpr("?", bun.Ident(p.tableName(def))).
+			ColumnExpr("? AS stats_time", bun.Ident(column))
+		if err := p.applyQuery(q, def, listOpts); err != nil {
+			return StatsResult{}, err
+		}
+		var rows []map[string]any
+		if err := q.Scan(ctx, &rows); err != nil {
+			return StatsResult{}, err
+		}
+		for _, row := range rows {
+			counter.addTimeString(scannedString(row["stats_time"]))
+		}
+	}
+	return counter.result(), nil
+}
+
+// Get returns the record with the given id.
+func (p *SQLProvider) Get(ctx context.Context, def Definition, id string) (Record, error) {
+	return p.get(ctx, p.db, def, id)
+}
+
+// Create inserts a record. A missing primary key is generated.
+func (p *SQLProvider) Create(ctx context.Context, def Definition, data map[string]any) (Record, error) {
+	actorID, err := actorIDFromContext(ctx)
+	if err != nil {
+		return Record{}, err
+	}
+	conn := p.conn(ctx)
+
+	id := asString(data[def.PrimaryKey])
+	if id == "" {
+		id = uuid.NewString()
+	}
+	data[def.PrimaryKey] = id
+
+	if _, err := p.get(ctx, conn, def, id); err == nil {
+		return Record{}, fmt.Errorf("record %s already exists", id)
+	} else if !errors.Is(err, ErrRecordNotFound) {
+		return Record{}, err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	audit := def.auditFields()
+	if _, ok := def.Field(audit.CreatedBy); ok && actorID != "" {
+		data[audit.CreatedBy] = actorID
+	}
+	if _, ok := def.Field(audit.UpdatedBy); ok && actorID != "" {
+		data[audit.UpdatedBy] = actorID
+	}
+
+	row, err := p.rowFromData(def, data)
+	if err != nil {
+		return Record{}, err
+	}
+	row[sqlColVersion] = int64(1)
+	row[sqlColCreatedAt] = now
+	row[sqlColUpdatedAt] = now
+	row[sqlColCreatedBy] = actorID
+	row[sqlColUpdatedBy] = actorID
+
+	if _, err := p.db.NewInsert().Conn(conn).Model(&row).TableExpr("?", bun.Ident(p.tableName(def))).Exec(ctx); err != nil {
+		return Record{}, err
+	}
+	return p.get(ctx, conn, def, id)
+}
+
+// Update merges data into the record with the given id.
+func (p *SQLProvider) Update(ctx context.Context, def Definition, id string, data map[string]any) (Record, error) {
+	conn := p.conn(ctx)
+
+	current, err := p.get(ctx, conn, def, id)
+	if err != nil {
+		return Record{}, err
+	}
+
+	actorID, err := actorIDFromContext(ctx)
+	if err != nil {
+		return Record{}, err
+	}
+	audit := def.auditFields()
+	delete(data, audit.CreatedBy)
+	if _, ok := def.Field(audit.UpdatedBy); ok && actorID != "" {
+		data[audit.UpdatedBy] = actorID
+	}
+	delete(data, def.PrimaryKey)
+
+	row, err := p.rowFromData(def, data)
+	if err != nil {
+		return Record{}, err
+	}
+	row[sqlColVersion] = current.Version + 1
+	row[sqlColUpdatedAt] = time.Now().UTC().Format(time.RFC3339Nano)
+	row[sqlColUpdatedBy] = actorID
+
+	res, err := p.db.NewUpdate().Conn(conn).Model(&row).TableExpr("?", bun.Ident(p.tableName(def))).
+		Where("? = ?", bun.Ident(def.PrimaryKey), id).
+		Where("? = ?", bun.Ident(sqlColVersion), current.Version).
+		Exec(ctx)
+	if err != nil {
+		return Record{}, err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return Record{}, fmt.Errorf("record %s was modified concurrently", id)
+	}
+	return p.get(ctx, conn, def, id)
+}
+
+// Delete removes the record with the given id.
+func (p *SQLProvider) Delete(ctx context.Context, def Definition, id string) error {
+	res, err := p.db.NewDelete().Conn(p.conn(ctx)).TableExpr("?", bun.Ident(p.tableName(def))).
+		Where("? = ?", bun.Ident(def.PrimaryKey), id).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: %s", ErrRecordNotFound, id)
+	}
+	return nil
+}
+
+func (p *SQLProvider) get(ctx context.Context, conn bun.IConn, def Definition, id string) (Record, error) {
+	var rows []map[string]any
+	err := p.db.NewSelect().Conn(conn).TableExpr("?", bun.Ident(p.tableName(def))).
+		Where("? = ?", bun.Ident(def.PrimaryKey), id).
+		Limit(1).
+		Scan(ctx, &rows)
+	if err != nil {
+		return Record{}, err
+	}
+	if len(rows) == 0 {
+		return Record{}, fmt.Errorf("%w: %s", ErrRecordNotFound, id)
+	}
+	return p.recordFromRow(def, rows[0])
+}
+
+// conn returns the transaction of the active unit of work when the provider
+// shares the event store database, so writes commit together with it, or the
+// database.
+func (p *SQLProvider) conn(ctx context.Context) bun.IConn {
+	if !p.joinTx {
+		return p.db
+	}
+	if uow, ok := UnitOfWorkFromContext(ctx); ok {
+		if tx := uow.Tx(); tx != nil {
+			return tx
+		}
+	}
+	return p.db
+}
+
+// applyQuery adds the filters and search of opts to q.
+func (p *SQLProvider) applyQuery(q *bun.SelectQuery, def Definition, opts ListOptions) error {
+	for _, filter := range opts.Filters {
+		if err := p.applyFilter(q, def, filter); err != nil {
+			return err
+		}
+	}
+	for _, group := range opts.OrGroups {
+		var err error
+		q.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			for _, filter := range group {
+				q.WhereGroup(" OR ", func(q *bun.SelectQuery) *bun.SelectQuery {
+					if filterErr := p.applyFilter(q, def, filter); filterErr != nil && err == nil {
+						err = filterErr
+					}
+					return q
+				})
+			}
+			return q
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	search := strings.ToLower(strings.TrimSpace(opts.Search))
+	if search == "" {
+		return nil
+	}
+	fields := searchableFieldIDs(def)
+	if len(fields) == 0 {
+		return nil
+	}
+	pattern := "%" + escapeLike(search) + "%"
+	q.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+		for _, id := range fields {
+			q.WhereOr(`LOWER(?) LIKE ? ESCAPE '\'`, bun.Ident(id), pattern)
+		}
+		return q
+	})
+	return nil
+}
+
+func (p *SQLProvider) applyFilter(q *bun.SelectQuery, def Definition, filter Filter) error {
+	field, ok := def.Field(filter.FieldID)
+	if !ok && filter.FieldID == def.PrimaryKey {
+		field, ok = FieldDefinition{ID: def.PrimaryKey, Type: entityPb.FieldType_FIELD_TYPE_STRING}, true
+	}
+	if !ok {
+		return fmt.Errorf("%w: unknown field %s", ErrInvalidFilter, filter.FieldID)
+	}
+	col := bun.Ident(field.ID)
+
+	// Presence checks work on every column, JSON ones included.
+	switch filter.Operator {
+	case entityPb.FilterOperator_FILTER_OPERATOR_IS_NULL:
+		if isTextField(field) {
+			q.Where("(? IS NULL OR ? = '')", col, col)
+		} else {
+			q.Where("? IS NULL", col)
+		}
+		return nil
+	case entityPb.FilterOperator_FILTER_OPERATOR_IS_NOT_NULL:
+		if isTextField(field) {
+			q.Where("(? IS NOT NULL AND ? <> '')", col, col)
+		} else {
+			q.Where("? IS NOT NULL", col)
+		}
+		return nil
+	}
+
+	if isJSONField(field) {
+		return fmt.Errorf("%w: field %s cannot be filtered by the SQL provider", ErrInvalidFilter, field.ID)
+	}
+
+	switch filter.Operator {
+	case entityPb.FilterOperator_FILTER_OPERATOR_EQ:
+		if filter.Value == nil {
+			q.Where("? IS NULL", col)
+			return nil
+		}
+		q.Where("? = ?", col, columnValue(field, filter.Value))
+	case entityPb.FilterOperator_FILTER_OPERATOR_NE:
+		if filter.Value == nil {
+			q.Where("? IS NOT NULL", col)
+			return nil
+		}
+		q.Where("(? <> ? OR ? IS NULL)", col, columnValue(field, filter.Value), col)
+	case entityPb.FilterOperator_FILTER_OPERATOR_GT:
+		q.Where("? > ?", col, columnValue(field, filter.Value))
+	case entityPb.FilterOperator_FILTER_OPERATOR_GTE:
+		q.Where("? >= ?", col, columnValue(field, filter.Value))
+	case entityPb.FilterOperator_FILTER_OPERATOR_LT:
+		q.Where("? < ?", col, columnValue(field, filter.Value))
+	case entityPb.FilterOperator_FILTER_OPERATOR_LTE:
+		q.Where("? <= ?", col, columnValue(field, filter.Value))
+	case entityPb.FilterOperator_FILTER_OPERATOR_BETWEEN:
+		bounds, ok := filter.Value.([]any)
+		if !ok || len(bounds) != 2 {
+			return fmt.Errorf("%w: between expects two bounds", ErrInvalidFilter)
+		}
+		if field.Type == entityPb.FieldType_FIELD_TYPE_DATETIME {
+			for _, bound := range bounds {
+				if _, ok := sqlDatetime(bound); !ok {
+					return fmt.Errorf("%w: between expects RFC 3339 bounds for %s", ErrInvalidFilter, field.ID)
+				}
+			}
+		}
+		q.Where("? BETWEEN ? AND ?", col, columnValue(field, bounds[0]), columnValue(field, bounds[1]))
+	case entityPb.FilterOperator_FILTER_OPERATOR_CONTAINS:
+		pattern := "%" + escapeLike(strings.ToLower(asString(filter.Value))) + "%"
+		q.Where(`LOWER(?) LIKE ? ESCAPE '\'`, col, pattern)
+	case entityPb.FilterOperator_FILTER_OPERATOR_IN:
+		values, ok := filter.Value.([]any)
+		if !ok {
+			values = []any{filter.Value}
+		}
+		if len(values) == 0 {
+			q.Where("1 = 0")
+			return nil
+		}
+		converted := make([]any, 0, len(values))
+		for _, value := range values {
+			converted = append(converted, columnValue(field, value))
+		}
+		q.Where("? IN (?)", col, bun.In(converted))
+	default:
+		return fmt.Errorf("%w: unsupported operator %s", ErrInvalidFilter, filter.Operator)
+	}
+	return nil
+}
+
+// rowFromData converts record data to column values.
+func (p *SQLProvider) rowFromData(def Definition, data map[string]any) (map[string]any, error) {
+	row := make(map[string]any, len(data)+5)
+	for key, value := range data {
+		field, ok := def.Field(key)
+		if !ok {
+			if key == def.PrimaryKey {
+				row[key] = asString(value)
+			}
+			continue
+		}
+		if value == nil {
+			row[key] = nil
+			continue
+		}
+		if isJSONField(field) {
+			raw, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPayload, key, err)
+			}
+			row[key] = string(raw)
+			continue
+		}
+		row[key] = columnValue(field, value)
+	}
+	return row, nil
+}
+
+// recordFromRow converts a scanned row back to a record.
+func (p *SQLProvider) recordFromRow(def Definition, row map[string]any) (Record, error) {
+	record := Record{
+		Entity:    def.ID,
+		ID:        scannedString(row[def.PrimaryKey]),
+		Data:      make(map[string]any, len(def.Fields)),
+		CreatedBy: scannedString(row[sqlColCreatedBy]),
+		UpdatedBy: scannedString(row[sqlColUpdatedBy]),
+	}
+	record.Version, _ = toInt64(scannedNumber(row[sqlColVersion]))
+	record.CreatedAt, _ = time.Parse(time.RFC3339Nano, scannedString(row[sqlColCreatedAt]))
+	record.UpdatedAt, _ = time.Parse(time.RFC3339Nano, scannedString(row[sqlColUpdatedAt]))
+	record.Data[def.PrimaryKey] = record.ID
+
+	for _, field := range def.Fields {
+		value, ok := row[field.ID]
+		if !ok || value == nil || field.ID == def.PrimaryKey {
+			continue
+		}
+		decoded, err := scannedFieldValue(field, value)
+		if err != nil {
+			return Record{}, err
+		}
+		record.Data[field.ID] = decoded
+	}
+	return record, nil
+}
+
+// scannedFieldValue converts a scanned column of field to its record value.
+func scannedFieldValue(field FieldDefinition, value any) (any, error) {
+	switch {
+	case value == nil:
+		return nil, nil
+	case isJSONField(field):
+		var decoded any
+		dec := json.NewDecoder(strings.NewReader(scannedString(value)))
+		dec.UseNumber()
+		if err := dec.Decode(&decoded); err != nil {
+			return nil, fmt.Errorf("decode column %s: %w", field.ID, err)
+		}
+		return decoded, nil
+	case field.Type == entityPb.FieldType_FIELD_TYPE_NUMBER:
+		return scannedNumber(value), nil
+	case field.Type == entityPb.FieldType_FIELD_TYPE_BOOLEAN:
+		return scannedBool(value), nil
+	case field.Type == entityPb.FieldType_FIELD_TYPE_DATETIME:
+		s := scannedString(value)
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return t.UTC().Format(time.RFC3339Nano), nil
+		}
+		return s, nil
+	default:
+		return scannedString(value), nil
+	}
+}
+
+func (p *SQLProvider) tableName(def Definition) string {
+	if p.table != "" {
+		return p.table
+	}
+	return "entities_" + strings.ToLower(def.ID)
+}
+
+func (p *SQLProvider) ident(name string) string {
+	return string(p.db.Formatter().AppendIdent(nil, name))
+}
+
+func (p *SQLProvider) columnDDL(name, typ string) string {
+	return p.ident(name) + " " + typ
+}
+
+// columnType returns the SQL type of a field column.
+func (p *SQLProvider) columnType(field FieldDefinition) string {
+	if isJSONField(field) {
+		return "TEXT"
+	}
+	switch field.Type {
+	case entityPb.FieldType_FIELD_TYPE_NUMBER:
+		if p.db.Dialect().Name().String() == "mysql" {
+			return "DECIMAL(65,20)"
+		}
+		return "NUMERIC"
+	case entityPb.FieldType_FIELD_TYPE_BOOLEAN:
+		return "BOOLEAN"
+	case entityPb.FieldType_FIELD_TYPE_UUID, entityPb.FieldType_FIELD_TYPE_ENUM, entityPb.FieldType_FIELD_TYPE_DATETIME:
+		return "VARCHAR(255)"
+	default:
+		if field.Reference != nil {
+			return "VARCHAR(255)"
+		}
+		return "TEXT"
+	}
+}
+
+// isJSONField reports whether a field is stored as JSON text.
+func isJSONField(field FieldDefinition) bool {
+	return field.Repeated ||
+		field.Type == entityPb.FieldType_FIELD_TYPE_OBJECT ||
+		field.Type == entityPb.FieldType_FIELD_TYPE_ARRAY
+}
+
+// isTextField reports whether a field sorts case-insensitively.
+func isTextField(field FieldDefinition) bool {
+	switch field.Type {
+	case entityPb.FieldType_FIELD_TYPE_NUMBER, entityPb.FieldType_FIELD_TYPE_BOOLEAN:
+		return false
+	}
+	return !isJSONField(field)
+}
+
+// columnValue converts a scalar value to a typed query parameter. Numbers
+// are bound as int64, as float64 when that keeps their exact value, and as
+// their decimal spelling otherwise, which the database converts to the column
+// type. Datetimes are stored in UTC, see sqlDatetimeLayout.
+func columnValue(field FieldDefinition, value any) any {
+	switch field.Type {
+	case entityPb.FieldType_FIELD_TYPE_NUMBER:
+		if number, ok := numericValue(value); ok {
+			return number
+		}
+		return value
+	case entityPb.FieldType_FIELD_TYPE_BOOLEAN:
+		return value
+	case entityPb.FieldType_FIELD_TYPE_DATETIME:
+		if datetime, ok := sqlDatetime(value); ok {
+			return datetime
+		}
+		return asString(value)
+	default:
+		return asString(value)
+	}
+}
+
+// sqlDatetimeLayout stores datetimes in UTC with a fixed width fraction, so
+// that the VARCHAR columns compare in chronological order.
+const sqlDatetimeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// sqlDatetime returns an RFC 3339 value in its stored form.
+func sqlDatetime(value any) (string, bool) {
+	t, err := time.Parse(time.RFC3339Nano, asString(value))
+	if err != nil {
+		return "", false
+	}
+	return t.UTC().Format(sqlDatetimeLayout), true
+}
+
+// decimalPattern matches the plain decimal numbers a NUMBER column holds.
+// NaN, infinities and hex forms are not among them.
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// numericValue returns a number or numeric string as a query parameter, see
+// columnValue. Other values are reported as not numeric.
+func numericValue(value any) (any, bool) {
+	if _, ok := value.(string); !ok && !isNumber(value) {
+		return nil, false
+	}
+	s := canonicalID(value)
+	if !decimalPattern.MatchString(s) {
+		return nil, false
+	}
+	if number, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return number, true
+	}
+	if number, err := strconv.ParseFloat(s, 64); err == nil && strconv.FormatFloat(number, 'f', -1, 64) == s {
+		return number, true
+	}
+	return s, true
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func scannedString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return canonicalID(v)
+	}
+}
+
+// scannedNumber returns a numeric column as json.Number, keeping its exact
+// value. Trailing fraction zeros added by DECIMAL columns are dropped.
+func scannedNumber(value any) any {
+	s := scannedString(value)
+	if strings.Contains(s, ".") && !strings.ContainsAny(s, "eE") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "" {
+		return nil
+	}
+	return json.Number(s)
+}
+
+func scannedBool(value any) any {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case int64:
+		return v != 0
+	default:
+		b, err := strconv.ParseBool(scannedString(v))
+		if err != nil {
+			return scannedString(v)
+		}
+		return b
+	}
+}
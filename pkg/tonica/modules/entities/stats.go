@@ -0,0 +1,232 @@
+package entities
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	entityPb "github.com/tonica-go/tonica/pkg/tonica/proto/entities"
+)
+
+// StatsOptions selects the headline metrics returned by Stats.
+type StatsOptions struct {
+	Filters []Filter
+	// GroupField counts records per value of this field when set.
+	GroupField string
+	// TimeField is the datetime field records are bucketed by. Records are
+	// bucketed by their creation time when it is empty.
+	TimeField string
+	// Interval counts records per time bucket when set.
+	Interval entityPb.StatsInterval
+}
+
+// StatsCount is the number of records sharing a group field value.
+type StatsCount struct {
+	Key   string
+	Count int64
+}
+
+// StatsBucket is the number of records whose time falls in the bucket
+// starting at Start.
+type StatsBucket struct {
+	Start time.Time
+	Count int64
+}
+
+// StatsResult contains the counts computed by Stats. Groups are ordered by
+// count, largest first; buckets are ordered by time and empty ones are
+// omitted.
+type StatsResult struct {
+	Total      int64
+	GroupField string
+	Groups     []StatsCount
+	TimeField  string
+	Interval   entityPb.StatsInterval
+	Buckets    []StatsBucket
+}
+
+// RecordStatter is implemented by providers that can compute Stats without
+// loading every record.
+type RecordStatter interface {
+	Stats(ctx context.Context, def Definition, opts StatsOptions) (StatsResult, error)
+}
+
+// Stats counts the records matching opts.Filters, in total and optionally per
+// group field value and per time bucket. Providers implementing RecordStatter
+// compute the counts themselves; other entities are scanned page by page.
+func (s *Service) Stats(ctx context.Context, entityID string, opts StatsOptions) (StatsResult, error) {
+	def, err := s.Definition(entityID)
+	if err != nil {
+		return StatsResult{}, err
+	}
+	opts.GroupField = strings.TrimSpace(opts.GroupField)
+	opts.TimeField = strings.TrimSpace(opts.TimeField)
+	if err := validateStatsOptions(def, opts); err != nil {
+		return StatsResult{}, err
+	}
+
+	if provider, ok := s.providerFor(def.ID); ok {
+		if statter, ok := provider.(RecordStatter); ok {
+			filters, _, emptyResult, err := s.resolveFilters(ctx, def, opts.Filters)
+			if err != nil {
+				return StatsResult{}, err
+			}
+			if emptyResult {
+				return newStatsCounter(opts).result(), nil
+			}
+			opts.Filters = filters
+			return statter.Stats(ctx, def, opts)
+		}
+	}
+
+	listOpts := ListOptions{
+		Filters:   opts.Filters,
+		SortField: def.PrimaryKey,
+		SortDir:   entityPb.SortDirection_SORT_DIRECTION_ASC,
+		PageSize:  200,
+	}
+	counter := newStatsCounter(opts)
+	pageToken := ""
+	scanCtx := withFullScan(ctx)
+	for {
+		listOpts.PageToken = pageToken
+		records, next, err := s.ListRecords(scanCtx, entityID, listOpts)
+		if err != nil {
+			return StatsResult{}, err
+		}
+		for _, record := range records {
+			counter.total++
+			if opts.GroupField != "" {
+				counter.addGroup(asString(record.Data[opts.GroupField]), 1)
+			}
+			if opts.Interval != entityPb.StatsInterval_STATS_INTERVAL_UNSPECIFIED {
+				if opts.TimeField == "" {
+					counter.addTime(record.CreatedAt)
+				} else {
+					counter.addTimeString(asString(record.Data[opts.TimeField]))
+				}
+			}
+		}
+
+		if next == "" || next == pageToken {
+			break
+		}
+		pageToken = next
+	}
+	return counter.result(), nil
+}
+
+func validateStatsOptions(def Definition, opts StatsOptions) error {
+	if opts.GroupField != "" {
+		field, ok := def.Field(opts.GroupField)
+		if !ok {
+			return fmt.Errorf("%w: group field %s", ErrInvalidFilter, opts.GroupField)
+		}
+		if isJSONField(field) {
+			return fmt.Errorf("%w: cannot group by %s field %s", ErrInvalidFilter, field.Type, field.ID)
+		}
+	}
+	if opts.TimeField != "" {
+		field, ok := def.Field(opts.TimeField)
+		if !ok {
+			return fmt.Errorf("%w: time field %s", ErrInvalidFilter, opts.TimeField)
+		}
+		if field.Type != entityPb.FieldType_FIELD_TYPE_DATETIME || field.Repeated {
+			return fmt.Errorf("%w: time field %s is not a datetime", ErrInvalidFilter, field.ID)
+		}
+	}
+	if _, ok := entityPb.StatsInterval_name[int32(opts.Interval)]; !ok {
+		return fmt.Errorf("%w: unknown interval %d", ErrInvalidFilter, opts.Interval)
+	}
+	return nil
+}
+
+// statsCounter accumulates counts for a StatsResult.
+type statsCounter struct {
+	opts    StatsOptions
+	total   int64
+	groups  map[string]int64
+	buckets map[time.Time]int64
+}
+
+func newStatsCounter(opts StatsOptions) *statsCounter {
+	return &statsCounter{
+		opts:    opts,
+		groups:  make(map[string]int64),
+		buckets: make(map[time.Time]int64),
+	}
+}
+
+func (c *statsCounter) addGroup(key string, count int64) {
+	c.groups[normalizePivotKey(key)] += count
+}
+
+// addTime counts t in its bucket. Zero times are not bucketed.
+func (c *statsCounter) addTime(t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	c.buckets[statsBucketStart(t, c.opts.Interval)]++
+}
+
+// addTimeString counts an RFC 3339 value in its bucket. Empty and
+// unparseable values are not bucketed.
+func (c *statsCounter) addTimeString(value string) {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return
+	}
+	c.addTime(t)
+}
+
+func (c *statsCounter) result() StatsResult {
+	result := StatsResult{
+		Total:      c.total,
+		GroupField: c.opts.GroupField,
+		TimeField:  c.opts.TimeField,
+		Interval:   c.opts.Interval,
+	}
+	if c.opts.GroupField != "" {
+		result.Groups = make([]StatsCount, 0, len(c.groups))
+		for key, count := range c.groups {
+			result.Groups = append(result.Groups, StatsCount{Key: key, Count: count})
+		}
+		sort.Slice(result.Groups, func(i, j int) bool {
+			a, b := result.Groups[i], result.Groups[j]
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			return a.Key < b.Key
+		})
+	}
+	if c.opts.Interval != entityPb.StatsInterval_STATS_INTERVAL_UNSPECIFIED {
+		result.Buckets = make([]StatsBucket, 0, len(c.buckets))
+		for start, count := range c.buckets {
+			result.Buckets = append(result.Buckets, StatsBucket{Start: start, Count: count})
+		}
+		sort.Slice(result.Buckets, func(i, j int) bool {
+			return result.Buckets[i].Start.Before(result.Buckets[j].Start)
+		})
+	}
+	return result
+}
+
+// statsBucketStart returns the start of the UTC bucket containing t. Weeks
+// start on Monday.
+func statsBucketStart(t time.Time, interval entityPb.StatsInterval) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case entityPb.StatsInterval_STATS_INTERVAL_HOUR:
+		return t.Truncate(time.Hour)
+	case entityPb.StatsInterval_STATS_INTERVAL_WEEK:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case entityPb.StatsInterval_STATS_INTERVAL_MONTH:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
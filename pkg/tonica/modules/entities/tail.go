@@ -0,0 +1,124 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tonica-go/tonica/pkg/tonica/modules/eventstore"
+)
+
+// ErrFeedUnsupported is returned when tailing a whole entity against a store
+// without an aggregate-type feed.
+var ErrFeedUnsupported = errors.New("event store does not support the aggregate-type feed")
+
+const defaultTailInterval = time.Second
+
+// TailOptions selects the events followed by TailEvents.
+type TailOptions struct {
+	// Entity is the entity id. Required.
+	Entity string
+	// Record narrows the tail to one record stream.
+	Record string
+	// FromVersion is the first record version to print with Record set, or
+	// the first feed position (event id) when following the whole entity.
+	FromVersion int64
+	// Interval is the poll interval. Defaults to one second.
+	Interval time.Duration
+	// Codec decodes payloads. Defaults to NumberJSONCodec.
+	Codec PayloadCodec
+	// MetadataCodec decodes event metadata. Defaults to JSONCodec.
+	MetadataCodec PayloadCodec
+}
+
+// TailedEvent is a decoded event as printed by `tonica events tail`.
+type TailedEvent struct {
+	ID        int64     `json:"id"`
+	StreamID  string    `json:"stream_id"`
+	Type      string    `json:"type"`
+	Version   int64     `json:"version"`
+	ActorID   string    `json:"actor_id,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	TraceID   string    `json:"trace_id,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	Payload   any       `json:"payload,omitempty"`
+}
+
+// TailEvents prints past events starting at opts.FromVersion and then
+// follows new ones with eventstore.Follow, calling fn for each until ctx is
+// done or fn fails.
+func TailEvents(ctx context.Context, store eventstore.Store, opts TailOptions, fn func(TailedEvent) error) error {
+	if opts.Entity == "" {
+		return errors.New("entity is required")
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultTailInterval
+	}
+	if opts.Codec == nil {
+		opts.Codec = NumberJSONCodec{}
+	}
+	if opts.MetadataCodec == nil {
+		opts.MetadataCodec = JSONCodec{}
+	}
+
+	// Versions of a single stream are appended one after the other, so the
+	// record load resumes after the last printed version and ignores the id
+	// cursor of Follow.
+	nextVersion := opts.FromVersion
+	load := func(ctx context.Context, _ int64, limit int) ([]eventstore.Event, error) {
+		events, err := store.Load(ctx, recordStreamID(opts.Entity, opts.Record), nextVersion)
+		if err != nil {
+			return nil, err
+		}
+		return events[:min(len(events), limit)], nil
+	}
+	var fromID int64
+	if opts.Record == "" {
+		feed, ok := store.(eventstore.TypeFeed)
+		if !ok {
+			return ErrFeedUnsupported
+		}
+		load = func(ctx context.Context, fromID int64, limit int) ([]eventstore.Event, error) {
+			return feed.LoadByType(ctx, aggregateType(opts.Entity), fromID, limit)
+		}
+		fromID = opts.FromVersion
+	}
+
+	return eventstore.Follow(ctx, load, fromID, opts.Interval, func(evt eventstore.Event) error {
+		tailed, err := decodeTailedEvent(opts.Codec, opts.MetadataCodec, evt)
+		if err != nil {
+			return fmt.Errorf("event %d: %w", evt.ID, err)
+		}
+		if err := fn(tailed); err != nil {
+			return err
+		}
+		nextVersion = evt.Version + 1
+		return nil
+	})
+}
+
+func decodeTailedEvent(codec, metaCodec PayloadCodec, evt eventstore.Event) (TailedEvent, error) {
+	tailed := TailedEvent{
+		ID:       evt.ID,
+		StreamID: evt.AggregateID,
+		Type:     evt.Type,
+		Version:  evt.Version,
+	}
+	if len(evt.Metadata) > 0 {
+		meta, err := decodeEventMetadata(metaCodec, evt.Metadata)
+		if err != nil {
+			return TailedEvent{}, err
+		}
+		tailed.ActorID = meta.ActorID
+		tailed.Timestamp = meta.Timestamp
+		tailed.TraceID = meta.TraceID
+		tailed.RequestID = meta.RequestID
+	}
+	if len(evt.Payload) > 0 {
+		if err := codec.Unmarshal(evt.Payload, &tailed.Payload); err != nil {
+			return TailedEvent{}, fmt.Errorf("decode payload: %w", err)
+		}
+	}
+	return tailed, nil
+}
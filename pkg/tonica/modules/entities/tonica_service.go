@@ -2,10 +2,23 @@ package entities
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/tonica-go/tonica/pkg/tonica/config"
 	"github.com/tonica-go/tonica/pkg/tonica/service"
+	"google.golang.org/genproto/googleapis/api/annotations"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	pb "github.com/tonica-go/tonica/pkg/tonica/proto/entities"
@@ -13,19 +26,41 @@ import (
 	"github.com/tonica-go/tonica/pkg/tonica/modules/eventstore"
 )
 
+// TonicaOption configures the service created by NewTonicaService.
+type TonicaOption func(*tonicaOptions)
+
+type tonicaOptions struct {
+	jobCtx context.Context
+}
+
+// WithJobContext runs the background jobs of the service, such as scheduled
+// compaction, until ctx is done. Without it they run until the process exits.
+func WithJobContext(ctx context.Context) TonicaOption {
+	return func(o *tonicaOptions) {
+		o.jobCtx = ctx
+	}
+}
+
 // NewTonicaService creates a new tonica service for entities module.
-func NewTonicaService(dsn, driver string) *service.Service {
+func NewTonicaService(dsn, driver string, opts ...TonicaOption) *service.Service {
+	o := tonicaOptions{jobCtx: context.Background()}
+	for _, opt := range opts {
+		opt(&o)
+	}
 	return service.NewService(
 		service.WithName("entities"),
 		service.WithGRPCAddr(":19002"),
 		service.WithDB(dsn, driver),
-		service.WithGRPC(registerGRPC),
+		service.WithGRPC(func(grpcServer *grpc.Server, svc *service.Service) {
+			registerGRPC(o.jobCtx, grpcServer, svc)
+		}),
 		service.WithGateway(registerGateway),
 	)
 }
 
-// registerGRPC registers the entities gRPC service.
-func registerGRPC(grpcServer *grpc.Server, svc *service.Service) {
+// registerGRPC registers the entities gRPC service. Its background jobs stop
+// when jobCtx is done.
+func registerGRPC(jobCtx context.Context, grpcServer *grpc.Server, svc *service.Service) {
 	bunDB := svc.GetDBClient()
 	if bunDB == nil {
 		panic("database not configured for entities service")
@@ -43,11 +78,51 @@ func registerGRPC(grpcServer *grpc.Server, svc *service.Service) {
 		panic(err)
 	}
 
+	if err := configureFromEnv(entitySvc); err != nil {
+		panic(err)
+	}
+
+	// Run scheduled compaction for entities with a retention policy
+	if interval := config.GetEnv("ENTITIES_COMPACTION_INTERVAL", ""); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			panic(fmt.Errorf("invalid ENTITIES_COMPACTION_INTERVAL: %w", err))
+		}
+		go func() {
+			if err := entitySvc.RunCompaction(jobCtx, d); err != nil && !errors.Is(err, context.Canceled) {
+				slog.Error("entity compaction stopped", "error", err.Error())
+			}
+		}()
+	}
+
 	// Register gRPC server
 	handler := &grpcHandler{svc: entitySvc}
 	pb.RegisterEntityServiceServer(grpcServer, handler)
 }
 
+// configureFromEnv applies the ENTITIES_* environment settings to svc.
+func configureFromEnv(svc *Service) error {
+	// Select the record payload codec
+	codec, err := CodecByName(config.GetEnv("ENTITIES_PAYLOAD_CODEC", "number"))
+	if err != nil {
+		return fmt.Errorf("invalid ENTITIES_PAYLOAD_CODEC: %w", err)
+	}
+	svc.SetPayloadCodec(codec)
+
+	// Select the event metadata codec
+	metaCodec, err := CodecByName(config.GetEnv("ENTITIES_METADATA_CODEC", "json"))
+	if err != nil {
+		return fmt.Errorf("invalid ENTITIES_METADATA_CODEC: %w", err)
+	}
+	svc.SetMetadataCodec(metaCodec)
+
+	svc.SetMaxRelationshipDepth(config.GetEnvInt("ENTITIES_MAX_RELATIONSHIP_DEPTH", defaultMaxRelationshipDepth))
+	svc.SetAllowAnonymous(config.GetEnvBool("ENTITIES_ALLOW_ANONYMOUS", false))
+	svc.SetSnapshotFrequency(config.GetEnvInt("ENTITIES_SNAPSHOT_FREQUENCY", 0))
+	svc.SetStreamingList(config.GetEnvBool("ENTITIES_STREAMING_LIST", false))
+	return nil
+}
+
 // registerGateway registers the entities gateway.
 func registerGateway(ctx context.Context, mux *runtime.ServeMux, target string, opts []grpc.DialOption) error {
 	return pb.RegisterEntityServiceHandlerFromEndpoint(ctx, mux, target, opts)
@@ -76,43 +151,79 @@ func (h *grpcHandler) GetEntity(ctx context.Context, req *pb.GetEntityRequest) (
 	return def.ToProto(), nil
 }
 
-func (h *grpcHandler) ListRecords(ctx context.Context, req *pb.ListRecordsRequest) (*pb.ListRecordsResponse, error) {
-	filters := make([]Filter, 0, len(req.GetFilters()))
-	for _, f := range req.GetFilters() {
+// filtersFromProto converts request filters, typing each value by the declared
+// type of the field it targets. Unknown fields keep the untyped value and are
+// rejected by filter validation.
+func (h *grpcHandler) filtersFromProto(entityID string, in []*pb.FilterExpression) []Filter {
+	filters := make([]Filter, 0, len(in))
+	for _, f := range in {
+		value := f.GetValue().AsInterface()
+		if fieldType, ok := h.svc.filterFieldType(entityID, f.GetField()); ok {
+			value = filterValueFromProto(fieldType, f.GetValue())
+		}
 		filters = append(filters, Filter{
 			FieldID:  f.GetField(),
 			Operator: f.GetOperator(),
-			Value:    f.GetValue().AsInterface(),
+			Value:    value,
 		})
 	}
+	return filters
+}
+
+func (h *grpcHandler) filterGroupsFromProto(entityID string, in []*pb.FilterGroup) [][]Filter {
+	if len(in) == 0 {
+		return nil
+	}
+	groups := make([][]Filter, 0, len(in))
+	for _, group := range in {
+		groups = append(groups, h.filtersFromProto(entityID, group.GetFilters()))
+	}
+	return groups
+}
 
+func (h *grpcHandler) ListRecords(ctx context.Context, req *pb.ListRecordsRequest) (*pb.ListRecordsResponse, error) {
 	opts := ListOptions{
-		Filters:   filters,
-		SortField: req.GetSortField(),
-		SortDir:   req.GetSortDirection(),
-		PageSize:  int(req.GetPageSize()),
-		PageToken: req.GetPageToken(),
-		Search:    req.GetSearch(),
+		Filters:           h.filtersFromProto(req.GetEntity(), req.GetFilters()),
+		OrGroups:          h.filterGroupsFromProto(req.GetEntity(), req.GetOrGroups()),
+		SortField:         req.GetSortField(),
+		SortDir:           req.GetSortDirection(),
+		PageSize:          int(req.GetPageSize()),
+		PageToken:         req.GetPageToken(),
+		Search:            req.GetSearch(),
+		Fields:            ParseFields(req.GetFields()),
+		IncludeTotalCount: req.GetIncludeTotalCount(),
+		ResolveLabels:     req.GetResolveLabels(),
+		Expand:            ParseFieldList(req.GetExpand()),
 	}
 
-	records, nextToken, err := h.svc.ListRecords(ctx, req.GetEntity(), opts)
+	result, err := h.svc.ListRecordsPage(ctx, req.GetEntity(), opts)
+	if errors.Is(err, ErrDeepPagination) || errors.Is(err, ErrInvalidFields) || errors.Is(err, ErrStalePageToken) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	pbRecords := make([]*pb.Record, 0, len(records))
-	for _, r := range records {
+	pbRecords := make([]*pb.Record, 0, len(result.Records))
+	for _, r := range result.Records {
 		pbRecords = append(pbRecords, recordToProto(r))
 	}
 
 	return &pb.ListRecordsResponse{
 		Records:       pbRecords,
-		NextPageToken: nextToken,
+		NextPageToken: result.NextPageToken,
+		TotalCount:    result.TotalCount,
 	}, nil
 }
 
 func (h *grpcHandler) GetRecord(ctx context.Context, req *pb.GetRecordRequest) (*pb.Record, error) {
-	record, err := h.svc.GetRecord(ctx, req.GetEntity(), req.GetId())
+	record, err := h.svc.GetRecordWith(ctx, req.GetEntity(), req.GetId(), GetOptions{
+		Fields: ParseFields(req.GetFields()),
+		Expand: ParseFieldList(req.GetExpand()),
+	})
+	if errors.Is(err, ErrInvalidFields) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -122,12 +233,52 @@ func (h *grpcHandler) GetRecord(ctx context.Context, req *pb.GetRecordRequest) (
 func (h *grpcHandler) CreateRecord(ctx context.Context, req *pb.CreateRecordRequest) (*pb.Record, error) {
 	data := req.GetData().AsMap()
 	record, err := h.svc.CreateRecord(ctx, req.GetEntity(), data)
+	if errors.Is(err, ErrInvalidID) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
 	return recordToProto(record), nil
 }
 
+// BatchCreateRecords creates the requested records. Atomic batches fail as a
+// whole; otherwise each result carries either the record or its error.
+func (h *grpcHandler) BatchCreateRecords(ctx context.Context, req *pb.BatchCreateRecordsRequest) (*pb.BatchCreateRecordsResponse, error) {
+	payloads := make([]map[string]any, 0, len(req.GetRecords()))
+	for _, data := range req.GetRecords() {
+		payloads = append(payloads, data.AsMap())
+	}
+
+	records, err := h.svc.CreateRecords(ctx, req.GetEntity(), payloads, BatchOptions{Atomic: req.GetAtomic()})
+	itemErrs := batchItemErrors(err)
+	if req.GetAtomic() || (err != nil && itemErrs == nil) {
+		if errors.Is(err, ErrAtomicUnsupported) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		if errors.Is(err, ErrBatchAborted) {
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+		// Atomic batches are validated up front.
+		if itemErrs != nil || errors.Is(err, ErrInvalidID) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]*pb.BatchRecordResult, 0, len(records))
+	for i, record := range records {
+		if itemErr, ok := itemErrs[i]; ok {
+			results = append(results, &pb.BatchRecordResult{Error: itemErr.Error()})
+			continue
+		}
+		results = append(results, &pb.BatchRecordResult{Record: recordToProto(record)})
+	}
+	return &pb.BatchCreateRecordsResponse{Results: results}, nil
+}
+
 func (h *grpcHandler) UpdateRecord(ctx context.Context, req *pb.UpdateRecordRequest) (*pb.Record, error) {
 	data := req.GetData().AsMap()
 	record, err := h.svc.UpdateRecord(ctx, req.GetEntity(), req.GetId(), data)
@@ -145,10 +296,23 @@ func (h *grpcHandler) DeleteRecord(ctx context.Context, req *pb.DeleteRecordRequ
 	return &emptypb.Empty{}, nil
 }
 
+func (h *grpcHandler) RestoreRecord(ctx context.Context, req *pb.RestoreRecordRequest) (*pb.Record, error) {
+	record, err := h.svc.RestoreRecord(ctx, req.GetEntity(), req.GetId())
+	if errors.Is(err, ErrNotDeleted) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return recordToProto(record), nil
+}
+
 func (h *grpcHandler) ListRecordHistory(ctx context.Context, req *pb.ListRecordHistoryRequest) (*pb.ListRecordHistoryResponse, error) {
 	opts := HistoryOptions{
-		PageSize:  int(req.GetPageSize()),
-		PageToken: req.GetPageToken(),
+		PageSize:     int(req.GetPageSize()),
+		PageToken:    req.GetPageToken(),
+		SinceVersion: req.GetSinceVersion(),
+		UntilVersion: req.GetUntilVersion(),
 	}
 
 	history, nextToken, err := h.svc.RecordHistory(ctx, req.GetEntity(), req.GetId(), opts)
@@ -167,26 +331,108 @@ func (h *grpcHandler) ListRecordHistory(ctx context.Context, req *pb.ListRecordH
 	}, nil
 }
 
-func (h *grpcHandler) PivotRecords(ctx context.Context, req *pb.PivotRequest) (*pb.PivotResponse, error) {
-	filters := make([]Filter, 0, len(req.GetFilters()))
-	for _, f := range req.GetFilters() {
-		filters = append(filters, Filter{
-			FieldID:  f.GetField(),
-			Operator: f.GetOperator(),
-			Value:    f.GetValue().AsInterface(),
-		})
+func (h *grpcHandler) StreamEntityChanges(req *pb.StreamEntityChangesRequest, stream pb.EntityService_StreamEntityChangesServer) error {
+	opts := ChangeStreamOptions{FromPosition: req.GetFromPosition()}
+
+	err := h.svc.StreamChanges(stream.Context(), req.GetEntity(), opts, func(c Change) error {
+		return stream.Send(changeToProto(c))
+	})
+	if errors.Is(err, ErrFeedUnsupported) {
+		return status.Error(codes.FailedPrecondition, err.Error())
 	}
+	return err
+}
 
+func (h *grpcHandler) PivotRecords(ctx context.Context, req *pb.PivotRequest) (*pb.PivotResponse, error) {
 	opts := PivotOptions{
 		RowField:    req.GetRowField(),
 		ColumnField: req.GetColumnField(),
-		Filters:     filters,
+		Filters:     h.filtersFromProto(req.GetEntity(), req.GetFilters()),
+		ValueField:  req.GetValueField(),
+		Aggregation: req.GetAggregation(),
 	}
 
 	result, err := h.svc.PivotRecords(ctx, req.GetEntity(), opts)
+	if errors.Is(err, ErrInvalidFilter) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	return pivotToProto(result), nil
 }
+
+func (h *grpcHandler) RecordStats(ctx context.Context, req *pb.StatsRequest) (*pb.StatsResponse, error) {
+	opts := StatsOptions{
+		Filters:    h.filtersFromProto(req.GetEntity(), req.GetFilters()),
+		GroupField: req.GetGroupField(),
+		TimeField:  req.GetTimeField(),
+		Interval:   req.GetInterval(),
+	}
+
+	result, err := h.svc.Stats(ctx, req.GetEntity(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return statsToProto(result), nil
+}
+
+func (h *grpcHandler) CompactRecords(ctx context.Context, req *pb.CompactRecordsRequest) (*pb.CompactRecordsResponse, error) {
+	var (
+		result CompactionResult
+		err    error
+	)
+	if recordID := req.GetRecordId(); recordID != "" {
+		var removed int64
+		removed, err = h.svc.CompactRecord(ctx, req.GetEntity(), recordID)
+		result = CompactionResult{Entity: req.GetEntity(), RemovedEvents: removed}
+		if removed > 0 {
+			result.Records = 1
+		}
+	} else {
+		result, err = h.svc.CompactRecords(ctx, req.GetEntity())
+	}
+	if errors.Is(err, ErrNoRetention) || errors.Is(err, ErrCompactionUnsupported) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CompactRecordsResponse{
+		Entity:        result.Entity,
+		Records:       int32(result.Records),
+		RemovedEvents: result.RemovedEvents,
+	}, nil
+}
+
+// recordPath is the GetRecord route template, e.g. /api/v1/entities/{entity}/{id}.
+var recordPath = sync.OnceValue(func() string {
+	method := pb.File_entities_entities_proto.Services().ByName("EntityService").Methods().ByName("GetRecord")
+	rule, _ := proto.GetExtension(method.Options(), annotations.E_Http).(*annotations.HttpRule)
+	return rule.GetGet()
+})
+
+// RecordLocation is a gateway forward-response option that answers
+// CreateRecord with 201 Created and a Location header set to the GetRecord URL
+// of the new record.
+func RecordLocation(ctx context.Context, w http.ResponseWriter, msg proto.Message) error {
+	if method, ok := runtime.RPCMethod(ctx); !ok || method != pb.EntityService_CreateRecord_FullMethodName {
+		return nil
+	}
+	record, ok := msg.(*pb.Record)
+	if !ok {
+		return nil
+	}
+	if record.GetId() != "" && recordPath() != "" {
+		location := strings.NewReplacer(
+			"{entity}", url.PathEscape(record.GetEntity()),
+			"{id}", url.PathEscape(record.GetId()),
+		).Replace(recordPath())
+		w.Header().Set("Location", location)
+	}
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
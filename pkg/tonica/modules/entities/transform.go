@@ -0,0 +1,110 @@
+package entities
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ReadTransform post-processes a record before it is returned, e.g. to mask
+// fields or add display values. It runs after the record is loaded and before
+// field projection, so it sees the full record data, and may read the caller
+// identity from ctx. Returning an error fails the read.
+type ReadTransform func(ctx context.Context, record *Record) error
+
+// RegisterReadTransform adds a transform applied to every record of entityID
+// returned by GetRecord, ListRecords and RecordHistory. Transforms run in
+// registration order. Filters, search and sorting are evaluated on the stored
+// data, before any transform.
+func (s *Service) RegisterReadTransform(entityID string, fn ReadTransform) {
+	if entityID == "" || fn == nil {
+		return
+	}
+	if s.readTransforms == nil {
+		s.readTransforms = make(map[string][]ReadTransform)
+	}
+	key := strings.ToLower(entityID)
+	s.readTransforms[key] = append(s.readTransforms[key], fn)
+}
+
+// transformRecords applies the read transforms of entityID to records in
+// place.
+func (s *Service) transformRecords(ctx context.Context, entityID string, records []Record) error {
+	transforms := s.readTransforms[strings.ToLower(entityID)]
+	if len(transforms) == 0 {
+		return nil
+	}
+	for i := range records {
+		for _, fn := range transforms {
+			if err := fn(ctx, &records[i]); err != nil {
+				return fmt.Errorf("transform %s record %s: %w", entityID, records[i].ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// transformHistory applies the read transforms of def to the data of each
+// history entry. Entries are presented to transforms as the record state at
+// that version. The raw event payload would bypass the transforms, so it is
+// dropped from transformed entries.
+func (s *Service) transformHistory(ctx context.Context, def Definition, recordID string, entries []HistoryEntry) error {
+	if len(s.readTransforms[strings.ToLower(def.ID)]) == 0 {
+		return nil
+	}
+	for i, entry := range entries {
+		records := []Record{{
+			Entity:    def.ID,
+			ID:        recordID,
+			Data:      entry.Data,
+			UpdatedAt: entry.Timestamp,
+			UpdatedBy: entry.Actor,
+			Version:   entry.Version,
+			Deleted:   entry.Deleted,
+		}}
+		if err := s.transformRecords(ctx, def.ID, records); err != nil {
+			return err
+		}
+		entries[i].Data = records[0].Data
+		entries[i].RawPayload = nil
+	}
+	return nil
+}
+
+// WriteAction is the kind of write a WriteHook is called for.
+type WriteAction string
+
+const (
+	WriteActionCreate WriteAction = "create"
+	WriteActionUpdate WriteAction = "update"
+)
+
+// WriteHook normalizes or validates the data of a write, e.g. to lowercase
+// emails, set derived fields or enforce cross-field rules. It runs after the
+// payload is sanitized and before any event is appended, and may mutate data
+// in place. For updates data holds only the changed fields. Returning an
+// error, typically ValidationErrors, rejects the write with that error.
+type WriteHook func(ctx context.Context, action WriteAction, data map[string]any) error
+
+// RegisterWriteHook adds a hook called for every create and update of
+// entityID, including batch writes. Hooks run in registration order. Data set
+// by a hook is written as is, without another sanitization pass.
+func (s *Service) RegisterWriteHook(entityID string, fn WriteHook) {
+	if entityID == "" || fn == nil {
+		return
+	}
+	if s.writeHooks == nil {
+		s.writeHooks = make(map[string][]WriteHook)
+	}
+	key := strings.ToLower(entityID)
+	s.writeHooks[key] = append(s.writeHooks[key], fn)
+}
+
+func (s *Service) runWriteHooks(ctx context.Context, entityID string, action WriteAction, data map[string]any) error {
+	for _, fn := range s.writeHooks[strings.ToLower(entityID)] {
+		if err := fn(ctx, action, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package entities
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/tonica-go/tonica/pkg/tonica/modules/eventstore"
+)
+
+type unitOfWorkKey struct{}
+
+// UnitOfWork groups the writes of a single service call. Record and index
+// events are appended through it, and providers can enlist their own database
+// statements via Tx, so everything commits or rolls back together.
+type UnitOfWork struct {
+	store       eventstore.Store
+	tx          eventstore.Tx
+	afterCommit []func(ctx context.Context)
+}
+
+// UnitOfWorkFromContext returns the unit of work of the current write, if any.
+// Providers receive it in the context passed to Create, Update and Delete.
+func UnitOfWorkFromContext(ctx context.Context) (*UnitOfWork, bool) {
+	uow, ok := ctx.Value(unitOfWorkKey{}).(*UnitOfWork)
+	return uow, ok && uow != nil
+}
+
+// Tx returns the database transaction used for event appends. It is nil when
+// the event store does not support transactions; writes are then applied
+// immediately and are not atomic.
+func (u *UnitOfWork) Tx() *sql.Tx {
+	if u.tx == nil {
+		return nil
+	}
+	return u.tx.SQLTx()
+}
+
+// Append appends events to a stream as part of the unit of work.
+func (u *UnitOfWork) Append(ctx context.Context, streamID string, expectedVersion int64, events []eventstore.Event) error {
+	if u.tx == nil {
+		return u.store.Append(ctx, streamID, expectedVersion, events)
+	}
+	return u.tx.Append(ctx, streamID, expectedVersion, events)
+}
+
+// AfterCommit registers fn to run once the unit of work has committed. It is
+// skipped on rollback.
+func (u *UnitOfWork) AfterCommit(fn func(ctx context.Context)) {
+	if fn != nil {
+		u.afterCommit = append(u.afterCommit, fn)
+	}
+}
+
+// inUnitOfWork runs fn inside a unit of work and commits it when fn succeeds.
+// Calls made while a unit of work is already active join it.
+func (s *Service) inUnitOfWork(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := UnitOfWorkFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	uow := &UnitOfWork{store: s.store}
+	if txStore, ok := s.store.(eventstore.Transactional); ok {
+		tx, err := txStore.BeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		uow.tx = tx
+	}
+
+	if err := fn(context.WithValue(ctx, unitOfWorkKey{}, uow)); err != nil {
+		if uow.tx != nil {
+			if rbErr := uow.tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+				return errors.Join(err, rbErr)
+			}
+		}
+		return err
+	}
+
+	if uow.tx != nil {
+		if err := uow.tx.Commit(); err != nil {
+			return err
+		}
+	}
+	for _, fn := range uow.afterCommit {
+		fn(ctx)
+	}
+	return nil
+}
+
+// appendEvents appends through the active unit of work, or directly to the
+// store when there is none.
+func (s *Service) appendEvents(ctx context.Context, streamID string, expectedVersion int64, events []eventstore.Event) error {
+	if uow, ok := UnitOfWorkFromContext(ctx); ok {
+		return uow.Append(ctx, streamID, expectedVersion, events)
+	}
+	return s.store.Append(ctx, streamID, expectedVersion, events)
+}
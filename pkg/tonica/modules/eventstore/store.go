@@ -16,10 +16,16 @@ import (
 	_ "modernc.org/sqlite"
 )
 
+// ErrVersionNotFound indicates the requested stream version does not exist.
+var ErrVersionNotFound = errors.New("stream version not found")
+
 // ErrConcurrencyConflict indicates an optimistic concurrency check failed.
 // This error should be retried by the caller.
 var ErrConcurrencyConflict = errors.New("concurrency conflict: aggregate was modified concurrently")
 
+// ErrSnapshotNotFound indicates no snapshot has been saved for a stream.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
 // Event represents a domain event persisted in the store.
 type Event struct {
 	ID            int64
@@ -38,6 +44,73 @@ type Store interface {
 	Close(ctx context.Context) error
 }
 
+// Compactor is implemented by stores that can remove the old events of a
+// stream once their state has been saved elsewhere, usually as a snapshot,
+// see Snapshotter.
+type Compactor interface {
+	// Compact removes the events of the stream before version and returns
+	// how many were removed. The event at version is kept so the stream
+	// version and everything appended after it stay untouched.
+	Compact(ctx context.Context, streamID string, version int64) (int64, error)
+}
+
+// TypeFeed is implemented by stores that can read the events of one
+// aggregate type across all of its streams.
+type TypeFeed interface {
+	// LoadByType returns up to limit events of aggregateType whose id is at
+	// least fromID, ordered by id.
+	LoadByType(ctx context.Context, aggregateType string, fromID int64, limit int) ([]Event, error)
+}
+
+// Feed is implemented by stores that can read the events of every stream
+// in the order they were appended, so projections can follow the store and
+// checkpoint the highest id they processed.
+type Feed interface {
+	// LoadAll returns up to limit events whose id is at least fromID,
+	// ordered by id.
+	LoadAll(ctx context.Context, fromID int64, limit int) ([]Event, error)
+	// Subscribe delivers every event whose id is at least fromID, first the
+	// stored ones and then new ones as they are appended, including events
+	// that commit after ones with higher ids, see Follow. The channel is
+	// closed when ctx is done.
+	Subscribe(ctx context.Context, fromID int64) (<-chan Event, error)
+}
+
+// Tx groups appends into a single database transaction. Appended events
+// become visible only after Commit.
+type Tx interface {
+	Append(ctx context.Context, streamID string, expectedVersion int64, events []Event) error
+	// SQLTx exposes the underlying transaction so callers can enlist their own
+	// statements against the same database.
+	SQLTx() *sql.Tx
+	Commit() error
+	Rollback() error
+}
+
+// Transactional is implemented by stores that support Tx.
+type Transactional interface {
+	BeginTx(ctx context.Context) (Tx, error)
+}
+
+// Snapshot is the serialized state of a stream as of Version.
+type Snapshot struct {
+	StreamID string
+	Version  int64
+	State    []byte
+}
+
+// Snapshotter is implemented by stores that keep the latest state snapshot
+// of a stream next to its events, so readers only replay newer events.
+// Snapshots are a cache: the events stay the source of truth.
+type Snapshotter interface {
+	// SaveSnapshot stores state as the snapshot of streamID at version. An
+	// existing snapshot is only replaced by a newer version.
+	SaveSnapshot(ctx context.Context, streamID string, version int64, state []byte) error
+	// LoadSnapshot returns the latest snapshot of streamID or
+	// ErrSnapshotNotFound.
+	LoadSnapshot(ctx context.Context, streamID string) (Snapshot, error)
+}
+
 type sqlStore struct {
 	db      *sql.DB
 	dialect string
@@ -165,7 +238,7 @@ func newMySQLStore(ctx context.Context, dsn string) (Store, error) {
 }
 
 func (s *sqlStore) ensureSchema(ctx context.Context) error {
-	var schema string
+	var schema, snapshots string
 	switch s.dialect {
 	case "mysql":
 		schema = `
@@ -181,6 +254,14 @@ CREATE TABLE IF NOT EXISTS events (
 	UNIQUE KEY idx_events_stream_version (aggregate_id, version),
 	KEY idx_events_created_at (created_at)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+`
+		snapshots = `
+CREATE TABLE IF NOT EXISTS snapshots (
+	stream_id VARCHAR(255) NOT NULL PRIMARY KEY,
+	version BIGINT NOT NULL,
+	state LONGBLOB NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 `
 	case "postgres":
 		schema = `
@@ -197,6 +278,14 @@ CREATE TABLE IF NOT EXISTS events (
 );
 
 CREATE INDEX IF NOT EXISTS idx_events_created_at ON events (created_at);
+`
+		snapshots = `
+CREATE TABLE IF NOT EXISTS snapshots (
+    stream_id text PRIMARY KEY,
+    version BIGINT NOT NULL,
+    state BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
 `
 	case "sqlite":
 		schema = `
@@ -211,12 +300,24 @@ CREATE TABLE IF NOT EXISTS events (
 	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 );
 CREATE UNIQUE INDEX IF NOT EXISTS idx_events_stream_version ON events(aggregate_id, version);
+`
+		snapshots = `
+CREATE TABLE IF NOT EXISTS snapshots (
+	stream_id TEXT PRIMARY KEY,
+	version INTEGER NOT NULL,
+	state BLOB NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
 `
 	default:
 		return fmt.Errorf("unsupported dialect: %s", s.dialect)
 	}
 
-	_, err := s.db.ExecContext(ctx, schema)
+	if _, err := s.db.ExecContext(ctx, schema); err != nil {
+		return err
+	}
+	// Created separately because MySQL runs one statement per Exec.
+	_, err := s.db.ExecContext(ctx, snapshots)
 	return err
 }
 
@@ -231,8 +332,34 @@ func (s *sqlStore) Append(ctx context.Context, streamID string, expectedVersion
 	}
 	defer tx.Rollback() //nolint:errcheck // best effort
 
+	if err := appendTx(ctx, tx, streamID, expectedVersion, events); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		// Check if commit fails due to unique constraint violation
+		if isUniqueConstraintViolation(err) {
+			return ErrConcurrencyConflict
+		}
+		return err
+	}
+
+	//logging.FromContext(ctx).Debug("appended events", zap.String("stream_id", streamID), zap.Int("count", len(events)))
+	return nil
+}
+
+// BeginTx starts a transaction that appends are grouped into.
+func (s *sqlStore) BeginTx(ctx context.Context) (Tx, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlTx{tx: tx}, nil
+}
+
+func appendTx(ctx context.Context, tx *sql.Tx, streamID string, expectedVersion int64, events []Event) error {
 	var currentVersion sql.NullInt64
-	err = tx.QueryRowContext(ctx, "SELECT MAX(version) FROM events WHERE aggregate_id = $1", streamID).Scan(&currentVersion)
+	err := tx.QueryRowContext(ctx, "SELECT MAX(version) FROM events WHERE aggregate_id = $1", streamID).Scan(&currentVersion)
 	if err != nil {
 		return err
 	}
@@ -262,19 +389,38 @@ VALUES ($1, $2, $3, $4, $5, $6)
 			return err
 		}
 	}
+	return nil
+}
 
-	if err := tx.Commit(); err != nil {
-		// Check if commit fails due to unique constraint violation
+type sqlTx struct {
+	tx *sql.Tx
+}
+
+func (t *sqlTx) Append(ctx context.Context, streamID string, expectedVersion int64, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return appendTx(ctx, t.tx, streamID, expectedVersion, events)
+}
+
+func (t *sqlTx) SQLTx() *sql.Tx {
+	return t.tx
+}
+
+func (t *sqlTx) Commit() error {
+	if err := t.tx.Commit(); err != nil {
 		if isUniqueConstraintViolation(err) {
 			return ErrConcurrencyConflict
 		}
 		return err
 	}
-
-	//logging.FromContext(ctx).Debug("appended events", zap.String("stream_id", streamID), zap.Int("count", len(events)))
 	return nil
 }
 
+func (t *sqlTx) Rollback() error {
+	return t.tx.Rollback()
+}
+
 func (s *sqlStore) Load(ctx context.Context, streamID string, fromVersion int64) ([]Event, error) {
 	rows, err := s.db.QueryContext(ctx, `
 SELECT id, aggregate_id, aggregate_type, version, type, payload, metadata
@@ -299,6 +445,128 @@ ORDER BY version
 	return events, rows.Err()
 }
 
+func (s *sqlStore) LoadByType(ctx context.Context, aggregateType string, fromID int64, limit int) ([]Event, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	return s.queryEvents(ctx, `
+SELECT id, aggregate_id, aggregate_type, version, type, payload, metadata
+FROM events
+WHERE aggregate_type = $1 AND id >= $2
+ORDER BY id
+LIMIT $3
+`, aggregateType, fromID, limit)
+}
+
+func (s *sqlStore) LoadAll(ctx context.Context, fromID int64, limit int) ([]Event, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	return s.queryEvents(ctx, `
+SELECT id, aggregate_id, aggregate_type, version, type, payload, metadata
+FROM events
+WHERE id >= $1
+ORDER BY id
+LIMIT $2
+`, fromID, limit)
+}
+
+// queryEvents runs query and scans the events it selects.
+func (s *sqlStore) queryEvents(ctx context.Context, query string, args ...any) ([]Event, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var evt Event
+		if err := rows.Scan(&evt.ID, &evt.AggregateID, &evt.AggregateType, &evt.Version, &evt.Type, &evt.Payload, &evt.Metadata); err != nil {
+			return nil, err
+		}
+		events = append(events, evt)
+	}
+
+	return events, rows.Err()
+}
+
+func (s *sqlStore) Subscribe(ctx context.Context, fromID int64) (<-chan Event, error) {
+	return Poll(ctx, s.LoadAll, fromID, DefaultPollInterval), nil
+}
+
+func (s *sqlStore) Compact(ctx context.Context, streamID string, version int64) (int64, error) {
+	if version <= 0 {
+		return 0, fmt.Errorf("%w: %s@%d", ErrVersionNotFound, streamID, version)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback() //nolint:errcheck // best effort
+
+	var exists int
+	err = tx.QueryRowContext(ctx, "SELECT 1 FROM events WHERE aggregate_id = $1 AND version = $2", streamID, version).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: %s@%d", ErrVersionNotFound, streamID, version)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM events WHERE aggregate_id = $1 AND version < $2", streamID, version)
+	if err != nil {
+		return 0, err
+	}
+	removed, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+func (s *sqlStore) SaveSnapshot(ctx context.Context, streamID string, version int64, state []byte) error {
+	if version <= 0 {
+		return fmt.Errorf("%w: %s@%d", ErrVersionNotFound, streamID, version)
+	}
+
+	query := `
+INSERT INTO snapshots (stream_id, version, state) VALUES ($1, $2, $3)
+ON CONFLICT (stream_id) DO UPDATE SET version = excluded.version, state = excluded.state, created_at = CURRENT_TIMESTAMP
+WHERE snapshots.version < excluded.version
+`
+	if s.dialect == "mysql" {
+		// state is assigned first so it still compares against the old version.
+		query = `
+INSERT INTO snapshots (stream_id, version, state) VALUES ($1, $2, $3)
+ON DUPLICATE KEY UPDATE
+	state = IF(VALUES(version) > version, VALUES(state), state),
+	created_at = IF(VALUES(version) > version, CURRENT_TIMESTAMP, created_at),
+	version = GREATEST(version, VALUES(version))
+`
+	}
+	_, err := s.db.ExecContext(ctx, query, streamID, version, state)
+	return err
+}
+
+func (s *sqlStore) LoadSnapshot(ctx context.Context, streamID string) (Snapshot, error) {
+	snapshot := Snapshot{StreamID: streamID}
+	err := s.db.QueryRowContext(ctx, "SELECT version, state FROM snapshots WHERE stream_id = $1", streamID).
+		Scan(&snapshot.Version, &snapshot.State)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Snapshot{}, fmt.Errorf("%w: %s", ErrSnapshotNotFound, streamID)
+	}
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return snapshot, nil
+}
+
 func (s *sqlStore) Close(ctx context.Context) error {
 	return s.db.Close()
 }
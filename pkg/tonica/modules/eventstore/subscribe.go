@@ -0,0 +1,187 @@
+package eventstore
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	// DefaultPollInterval is how often Subscribe checks for new events.
+	DefaultPollInterval = time.Second
+	pollBatchSize       = 100
+
+	// gapLookback bounds how many ids behind the newest delivered event
+	// skipped ids are re-read.
+	gapLookback = 1000
+	// gapTimeout is how long skipped ids are re-read before they are taken
+	// as rolled back or compacted.
+	gapTimeout = time.Minute
+)
+
+// LoadFunc returns up to limit events whose id is at least fromID, ordered
+// by id, like Feed.LoadAll and TypeFeed.LoadByType.
+type LoadFunc func(ctx context.Context, fromID int64, limit int) ([]Event, error)
+
+// Poll implements Feed.Subscribe on top of a LoadAll function, see Follow.
+// Load errors are logged and retried on the next tick.
+func Poll(ctx context.Context, loadAll LoadFunc, fromID int64, interval time.Duration) <-chan Event {
+	load := func(ctx context.Context, fromID int64, limit int) ([]Event, error) {
+		events, err := loadAll(ctx, fromID, limit)
+		if err != nil && ctx.Err() == nil {
+			slog.Warn("event feed poll failed", "from_id", fromID, "error", err.Error())
+			return nil, nil
+		}
+		return events, err
+	}
+
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		_ = Follow(ctx, load, fromID, interval, func(evt Event) error {
+			select {
+			case out <- evt:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return out
+}
+
+// Follow calls fn for every event load returns from fromID on, first the
+// stored ones and then new ones every interval, until ctx is done or load or
+// fn fail. It returns nil once ctx is done.
+//
+// Ids are assigned on insert, so an event whose transaction commits after a
+// later one appears behind events that were already delivered. Follow
+// re-reads the ids it skipped for gapTimeout, up to gapLookback ids behind
+// the newest event, and delivers events found there late and out of id
+// order. Consumers checkpoint the highest id they processed.
+func Follow(ctx context.Context, load LoadFunc, fromID int64, interval time.Duration, fn func(Event) error) error {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	c := &cursor{next: fromID}
+	for {
+		events, err := load(ctx, c.next, pollBatchSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		for _, evt := range events {
+			if err := fn(evt); err != nil {
+				return err
+			}
+			c.advance(evt.ID, time.Now())
+		}
+		// A full page means more is waiting; fetch it without sleeping.
+		if len(events) == pollBatchSize {
+			continue
+		}
+		if err := c.rescan(ctx, load, fn); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// idGap is a range of ids, from lo up to excluding hi, that were skipped
+// when a later event was read.
+type idGap struct {
+	lo, hi int64
+	seen   time.Time
+}
+
+// cursor is the read position of Follow and the gaps behind it.
+type cursor struct {
+	next int64
+	gaps []idGap
+}
+
+// advance moves the cursor past id and records the ids it skipped.
+func (c *cursor) advance(id int64, now time.Time) {
+	if id < c.next {
+		return
+	}
+	if lo := max(c.next, 1, id-gapLookback); lo < id {
+		c.gaps = append(c.gaps, idGap{lo: lo, hi: id, seen: now})
+	}
+	c.next = id + 1
+}
+
+// fill removes id from the gaps and reports whether it was missing.
+func (c *cursor) fill(id int64) bool {
+	for i, gap := range c.gaps {
+		if id < gap.lo || id >= gap.hi {
+			continue
+		}
+		var split []idGap
+		if gap.lo < id {
+			split = append(split, idGap{lo: gap.lo, hi: id, seen: gap.seen})
+		}
+		if id+1 < gap.hi {
+			split = append(split, idGap{lo: id + 1, hi: gap.hi, seen: gap.seen})
+		}
+		c.gaps = append(c.gaps[:i], append(split, c.gaps[i+1:]...)...)
+		return true
+	}
+	return false
+}
+
+// prune drops the gaps that timed out or fell out of the lookback window.
+func (c *cursor) prune(now time.Time) {
+	kept := c.gaps[:0]
+	for _, gap := range c.gaps {
+		if now.Sub(gap.seen) >= gapTimeout || gap.hi <= c.next-1-gapLookback {
+			continue
+		}
+		gap.lo = max(gap.lo, c.next-1-gapLookback)
+		kept = append(kept, gap)
+	}
+	c.gaps = kept
+}
+
+// rescan re-reads the gaps and calls fn for the events that committed late.
+func (c *cursor) rescan(ctx context.Context, load LoadFunc, fn func(Event) error) error {
+	c.prune(time.Now())
+	if len(c.gaps) == 0 {
+		return nil
+	}
+	from := c.gaps[0].lo
+	for from < c.next {
+		events, err := load(ctx, from, pollBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, evt := range events {
+			if evt.ID >= c.next {
+				return nil
+			}
+			if c.fill(evt.ID) {
+				if err := fn(evt); err != nil {
+					return err
+				}
+			}
+			from = evt.ID + 1
+		}
+		if len(events) < pollBatchSize {
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package eventstore
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoll(t *testing.T) {
+	var mu sync.Mutex
+	var stored []Event
+	appendEvent := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		stored = append(stored, Event{ID: int64(len(stored) + 1)})
+	}
+	loadAll := func(_ context.Context, fromID int64, limit int) ([]Event, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		var out []Event
+		for _, evt := range stored {
+			if evt.ID >= fromID && len(out) < limit {
+				out = append(out, evt)
+			}
+		}
+		return out, nil
+	}
+
+	for i := 0; i < pollBatchSize+5; i++ {
+		appendEvent()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := Poll(ctx, loadAll, 3, 10*time.Millisecond)
+
+	next := int64(3)
+	receive := func(until int64) {
+		for ; next <= until; next++ {
+			select {
+			case evt := <-events:
+				require.Equal(t, next, evt.ID)
+			case <-time.After(time.Second):
+				t.Fatalf("event %d not delivered", next)
+			}
+		}
+	}
+	receive(pollBatchSize + 5)
+
+	appendEvent()
+	receive(pollBatchSize + 6)
+
+	cancel()
+	_, open := <-events
+	assert.False(t, open, "channel is closed once ctx is done")
+}
+
+func TestPoll_LateCommit(t *testing.T) {
+	var mu sync.Mutex
+	stored := []Event{{ID: 1}, {ID: 2}, {ID: 4}}
+	commit := func(id int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		stored = append(stored, Event{ID: id})
+		sort.Slice(stored, func(i, j int) bool { return stored[i].ID < stored[j].ID })
+	}
+	loadAll := func(_ context.Context, fromID int64, limit int) ([]Event, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		var out []Event
+		for _, evt := range stored {
+			if evt.ID >= fromID && len(out) < limit {
+				out = append(out, evt)
+			}
+		}
+		return out, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := Poll(ctx, loadAll, 1, 10*time.Millisecond)
+
+	receive := func(want int64) {
+		t.Helper()
+		select {
+		case evt := <-events:
+			require.Equal(t, want, evt.ID)
+		case <-time.After(time.Second):
+			t.Fatalf("event %d not delivered", want)
+		}
+	}
+	receive(1)
+	receive(2)
+	receive(4)
+
+	// Event 3 was still uncommitted when 4 was read.
+	commit(3)
+	receive(3)
+	commit(5)
+	receive(5)
+}
+
+func TestCursor_Gaps(t *testing.T) {
+	now := time.Now()
+	c := &cursor{next: 1}
+
+	c.advance(1, now)
+	c.advance(5, now)
+	assert.Equal(t, []idGap{{lo: 2, hi: 5, seen: now}}, c.gaps)
+
+	assert.True(t, c.fill(3))
+	assert.False(t, c.fill(3), "ids are delivered once")
+	assert.Equal(t, []idGap{{lo: 2, hi: 3, seen: now}, {lo: 4, hi: 5, seen: now}}, c.gaps)
+
+	c.advance(gapLookback+10, now)
+	c.prune(now)
+	assert.Equal(t, []idGap{{lo: 10, hi: gapLookback + 10, seen: now}}, c.gaps, "gaps are kept within the lookback window")
+
+	c.prune(now.Add(gapTimeout))
+	assert.Empty(t, c.gaps, "gaps time out")
+}
@@ -0,0 +1,110 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/operatorservice/v1"
+	"go.temporal.io/sdk/converter"
+)
+
+// ErrUnknownSearchAttribute is returned when a workflow is started with a
+// search attribute that is not registered in its namespace.
+var ErrUnknownSearchAttribute = errors.New("unknown search attribute")
+
+// defaultNamespace is the Temporal namespace used when none is configured.
+const defaultNamespace = "default"
+
+// SetNamespace sets the Temporal namespace Trigger starts workflows in. It
+// must match the namespace of the client and is used to look up registered
+// search attributes. Defaults to "default".
+func (s *Service) SetNamespace(namespace string) {
+	s.namespace = strings.TrimSpace(namespace)
+}
+
+func (s *Service) triggerNamespace() string {
+	if s.namespace != "" {
+		return s.namespace
+	}
+	return defaultNamespace
+}
+
+// validateSearchAttributes rejects search attribute keys that are not
+// registered in namespace, so typos fail the start instead of being dropped
+// or failing inside Temporal.
+func (s *Service) validateSearchAttributes(ctx context.Context, namespace string, attributes map[string]any) error {
+	if len(attributes) == 0 {
+		return nil
+	}
+	resp, err := s.client.OperatorService().ListSearchAttributes(ctx, &operatorservice.ListSearchAttributesRequest{
+		Namespace: namespace,
+	})
+	if err != nil {
+		return fmt.Errorf("list search attributes: %w", err)
+	}
+	return checkSearchAttributes(attributes, resp.GetCustomAttributes(), resp.GetSystemAttributes())
+}
+
+// checkSearchAttributes reports every key of attributes that is missing from
+// all of the registered attribute sets.
+func checkSearchAttributes(attributes map[string]any, registered ...map[string]enums.IndexedValueType) error {
+	var unknown []string
+	for key := range attributes {
+		found := false
+		for _, set := range registered {
+			if _, ok := set[key]; ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("%w: %s", ErrUnknownSearchAttribute, strings.Join(unknown, ", "))
+}
+
+// encodeMemo converts memo values to Temporal payloads. An empty memo yields
+// nil.
+func encodeMemo(memo map[string]any) (*common.Memo, error) {
+	fields, err := encodeFields(memo)
+	if err != nil || fields == nil {
+		return nil, err
+	}
+	return &common.Memo{Fields: fields}, nil
+}
+
+// encodeSearchAttributes converts search attribute values to Temporal
+// payloads. The server types them by their registered attribute type.
+func encodeSearchAttributes(attributes map[string]any) (*common.SearchAttributes, error) {
+	fields, err := encodeFields(attributes)
+	if err != nil || fields == nil {
+		return nil, err
+	}
+	return &common.SearchAttributes{IndexedFields: fields}, nil
+}
+
+func encodeFields(values map[string]any) (map[string]*common.Payload, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	dc := converter.GetDefaultDataConverter()
+	fields := make(map[string]*common.Payload, len(values))
+	for key, value := range values {
+		payload, err := dc.ToPayload(value)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s: %w", key, err)
+		}
+		fields[key] = payload
+	}
+	return fields, nil
+}
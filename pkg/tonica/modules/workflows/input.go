@@ -0,0 +1,127 @@
+package workflows
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tonica-go/tonica/pkg/tonica/worker"
+)
+
+// ErrInvalidInput is returned by Trigger when the input does not match the
+// schema declared for the workflow.
+var ErrInvalidInput = errors.New("invalid workflow input")
+
+// InputSchema declares the trigger input a workflow expects, so mismatches
+// are rejected before the workflow starts instead of failing inside it.
+type InputSchema struct {
+	// RequireEntity and RequireRecordID reject triggers without an entity
+	// or record id.
+	RequireEntity   bool
+	RequireRecordID bool
+	// Required lists payload keys that must be present and non-empty.
+	Required []string
+	// Optional lists payload keys that may be present.
+	Optional []string
+	// AllowUnknown accepts payload keys not listed in Required or Optional.
+	AllowUnknown bool
+}
+
+// Validate checks a trigger against the schema and reports every problem
+// at once.
+func (s InputSchema) Validate(entity, recordID string, payload map[string]string) error {
+	var problems []string
+	if s.RequireEntity && strings.TrimSpace(entity) == "" {
+		problems = append(problems, "entity is required")
+	}
+	if s.RequireRecordID && strings.TrimSpace(recordID) == "" {
+		problems = append(problems, "record_id is required")
+	}
+
+	known := make(map[string]bool, len(s.Required)+len(s.Optional))
+	for _, key := range s.Required {
+		known[key] = true
+		if strings.TrimSpace(payload[key]) == "" {
+			problems = append(problems, fmt.Sprintf("payload.%s is required", key))
+		}
+	}
+	for _, key := range s.Optional {
+		known[key] = true
+	}
+	if !s.AllowUnknown {
+		var unknown []string
+		for key := range payload {
+			if !known[key] {
+				unknown = append(unknown, key)
+			}
+		}
+		sort.Strings(unknown)
+		for _, key := range unknown {
+			problems = append(problems, fmt.Sprintf("payload.%s is not accepted", key))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidInput, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// InputSchemas holds the input schemas of workflows by name. Share one
+// between the workers declaring the schemas and the workflows service that
+// validates triggers against them, see WithInputSchemas.
+type InputSchemas struct {
+	mu         sync.RWMutex
+	byWorkflow map[string]InputSchema
+}
+
+// NewInputSchemas returns an empty set of input schemas.
+func NewInputSchemas() *InputSchemas {
+	return &InputSchemas{byWorkflow: make(map[string]InputSchema)}
+}
+
+// Register declares the input expected by the named workflow. Trigger
+// validates against it before starting the workflow; workflows without a
+// schema accept any input.
+func (s *InputSchemas) Register(workflow string, schema InputSchema) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byWorkflow[workflow] = schema
+}
+
+// Lookup returns the schema registered for workflow. A nil set has none.
+func (s *InputSchemas) Lookup(workflow string) (InputSchema, bool) {
+	if s == nil {
+		return InputSchema{}, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	schema, ok := s.byWorkflow[workflow]
+	return schema, ok
+}
+
+// WithValidatedWorkflow is WithWorkflow that also registers schema in
+// schemas for the workflow, under name when given and under the function
+// name otherwise.
+func WithValidatedWorkflow(schemas *InputSchemas, fn interface{}, schema InputSchema, name ...string) worker.Option {
+	workflowName := functionName(fn)
+	if len(name) > 0 {
+		workflowName = name[0]
+	}
+	schemas.Register(workflowName, schema)
+	return worker.WithWorkflow(fn, name...)
+}
+
+// functionName returns the name Temporal registers fn under: the function
+// name without its package path and, for methods, without the receiver.
+func functionName(fn interface{}) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.TrimSuffix(name, "-fm")
+}
@@ -2,7 +2,9 @@ package workflows
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"strings"
 
 	pacev1 "github.com/tonica-go/tonica/pkg/tonica/proto/workflows"
 
@@ -38,15 +40,19 @@ func (s *Service) ListNamespaces(ctx context.Context) ([]*pacev1.Namespace, erro
 	return namespaces, nil
 }
 
+// ListWorkflows returns a page of workflow executions. The type, status and
+// workflow id prefix filters are part of the visibility query, so pages are
+// filled by the server. The page token is the base64 encoded Temporal token.
 func (s *Service) ListWorkflows(ctx context.Context, namespace string, requestedWorkflowType string, status pacev1.WorkflowStatus, pageSize int32, pageToken string, searchQuery string) ([]*pacev1.WorkflowExecution, string, error) {
-	if searchQuery != "" {
-		searchQuery = fmt.Sprintf("`WorkflowId` STARTS_WITH \"%s\"", searchQuery)
+	token, err := base64.RawURLEncoding.DecodeString(pageToken)
+	if err != nil {
+		return nil, "", fmt.Errorf("list workflows: %w", ErrInvalidPageToken)
 	}
 	res, err := s.client.WorkflowService().ListWorkflowExecutions(ctx, &workflowservice.ListWorkflowExecutionsRequest{
 		Namespace:     namespace,
 		PageSize:      pageSize,
-		Query:         searchQuery,
-		NextPageToken: []byte(pageToken),
+		Query:         visibilityQuery(requestedWorkflowType, status, searchQuery),
+		NextPageToken: token,
 	})
 	if err != nil {
 		return nil, "", fmt.Errorf("list workflows: %w", err)
@@ -77,6 +83,8 @@ func (s *Service) ListWorkflows(ctx context.Context, namespace string, requested
 		if workflowType == "" {
 			workflowType = "unknown"
 		}
+		// The query already filters; this guards against visibility stores
+		// that ignore part of it.
 		if requestedWorkflowType != "" && workflowType != requestedWorkflowType {
 			continue
 		}
@@ -98,7 +106,40 @@ func (s *Service) ListWorkflows(ctx context.Context, namespace string, requested
 		})
 	}
 
-	return workflows, "", nil
+	return workflows, base64.RawURLEncoding.EncodeToString(res.NextPageToken), nil
+}
+
+// visibilityStatuses names workflow statuses as the ExecutionStatus search
+// attribute spells them.
+var visibilityStatuses = map[pacev1.WorkflowStatus]string{
+	pacev1.WorkflowStatus_WORKFLOW_STATUS_RUNNING:          "Running",
+	pacev1.WorkflowStatus_WORKFLOW_STATUS_COMPLETED:        "Completed",
+	pacev1.WorkflowStatus_WORKFLOW_STATUS_FAILED:           "Failed",
+	pacev1.WorkflowStatus_WORKFLOW_STATUS_CANCELED:         "Canceled",
+	pacev1.WorkflowStatus_WORKFLOW_STATUS_TERMINATED:       "Terminated",
+	pacev1.WorkflowStatus_WORKFLOW_STATUS_CONTINUED_AS_NEW: "ContinuedAsNew",
+	pacev1.WorkflowStatus_WORKFLOW_STATUS_TIMED_OUT:        "TimedOut",
+}
+
+// visibilityQuery builds the list filter for ListWorkflowExecutions from the
+// optional workflow type, status and workflow id prefix.
+func visibilityQuery(workflowType string, status pacev1.WorkflowStatus, workflowIDPrefix string) string {
+	var predicates []string
+	if workflowType != "" {
+		predicates = append(predicates, "`WorkflowType` = "+quoteQueryValue(workflowType))
+	}
+	if name, ok := visibilityStatuses[status]; ok {
+		predicates = append(predicates, "`ExecutionStatus` = "+quoteQueryValue(name))
+	}
+	if workflowIDPrefix != "" {
+		predicates = append(predicates, "`WorkflowId` STARTS_WITH "+quoteQueryValue(workflowIDPrefix))
+	}
+	return strings.Join(predicates, " AND ")
+}
+
+// quoteQueryValue quotes a string literal for a visibility query.
+func quoteQueryValue(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
 }
 
 func (s *Service) GetWorkflow(ctx context.Context, namespace string, workflowID string, runID string) (*pacev1.WorkflowDetails, error) {
@@ -338,10 +379,43 @@ func payloadToStruct(payload *common.Payload) (*structpb.Struct, error) {
 	return &s, nil
 }
 
-func (s *Service) GetWorkflowHistory(ctx context.Context, namespace string, workflowID string, runID string, pageSize int32, pageToken string) ([]*pacev1.HistoryEvent, string, error) {
+// structToPayloads encodes input as a single json/plain payload, the inverse
+// of payloadToStruct. A nil input yields no payloads.
+func structToPayloads(input *structpb.Struct) (*common.Payloads, error) {
+	if input == nil {
+		return nil, nil
+	}
+
+	data, err := input.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("encode payload: %w", err)
+	}
+
+	return &common.Payloads{
+		Payloads: []*common.Payload{{
+			Metadata: map[string][]byte{"encoding": []byte("json/plain")},
+			Data:     data,
+		}},
+	}, nil
+}
+
+// GetWorkflowHistory returns a page of workflow history events. When
+// categories is not empty only events of those categories are returned.
+// Temporal can only narrow history to the close event, so the filter is
+// applied to each page before events are mapped and a filtered page may be
+// shorter than pageSize. The page token is the base64 encoded Temporal token.
+func (s *Service) GetWorkflowHistory(ctx context.Context, namespace string, workflowID string, runID string, pageSize int32, pageToken string, categories []pacev1.HistoryEventCategory) ([]*pacev1.HistoryEvent, string, error) {
 	if pageSize == 0 {
 		pageSize = 100
 	}
+	token, err := base64.RawURLEncoding.DecodeString(pageToken)
+	if err != nil {
+		return nil, "", fmt.Errorf("get workflow history: %w", ErrInvalidPageToken)
+	}
+	wanted := make(map[pacev1.HistoryEventCategory]bool, len(categories))
+	for _, category := range categories {
+		wanted[category] = true
+	}
 
 	req := &workflowservice.GetWorkflowExecutionHistoryRequest{
 		Namespace: namespace,
@@ -350,7 +424,7 @@ func (s *Service) GetWorkflowHistory(ctx context.Context, namespace string, work
 			RunId:      runID,
 		},
 		MaximumPageSize: pageSize,
-		NextPageToken:   []byte(pageToken),
+		NextPageToken:   token,
 	}
 
 	resp, err := s.client.WorkflowService().GetWorkflowExecutionHistory(ctx, req)
@@ -360,6 +434,10 @@ func (s *Service) GetWorkflowHistory(ctx context.Context, namespace string, work
 
 	var events []*pacev1.HistoryEvent
 	for _, he := range resp.History.Events {
+		if len(wanted) > 0 && !wanted[historyEventCategory(he.EventType)] {
+			continue
+		}
+
 		// Map event type
 		eventType := pacev1.HistoryEventType_HISTORY_EVENT_TYPE_UNSPECIFIED
 		eventName := ""
@@ -545,8 +623,59 @@ func (s *Service) GetWorkflowHistory(ctx context.Context, namespace string, work
 		})
 	}
 
-	nextToken := string(resp.NextPageToken)
-	return events, nextToken, nil
+	return events, base64.RawURLEncoding.EncodeToString(resp.NextPageToken), nil
+}
+
+// historyEventCategory returns the category of a Temporal event type, or
+// UNSPECIFIED for types outside every category.
+func historyEventCategory(eventType enums.EventType) pacev1.HistoryEventCategory {
+	switch eventType {
+	case enums.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED,
+		enums.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED,
+		enums.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED,
+		enums.EVENT_TYPE_WORKFLOW_EXECUTION_TIMED_OUT,
+		enums.EVENT_TYPE_WORKFLOW_EXECUTION_CANCEL_REQUESTED,
+		enums.EVENT_TYPE_WORKFLOW_EXECUTION_CANCELED,
+		enums.EVENT_TYPE_WORKFLOW_EXECUTION_TERMINATED,
+		enums.EVENT_TYPE_WORKFLOW_EXECUTION_CONTINUED_AS_NEW:
+		return pacev1.HistoryEventCategory_HISTORY_EVENT_CATEGORY_WORKFLOW
+	case enums.EVENT_TYPE_WORKFLOW_TASK_SCHEDULED,
+		enums.EVENT_TYPE_WORKFLOW_TASK_STARTED,
+		enums.EVENT_TYPE_WORKFLOW_TASK_COMPLETED,
+		enums.EVENT_TYPE_WORKFLOW_TASK_TIMED_OUT,
+		enums.EVENT_TYPE_WORKFLOW_TASK_FAILED:
+		return pacev1.HistoryEventCategory_HISTORY_EVENT_CATEGORY_WORKFLOW_TASK
+	case enums.EVENT_TYPE_ACTIVITY_TASK_SCHEDULED,
+		enums.EVENT_TYPE_ACTIVITY_TASK_STARTED,
+		enums.EVENT_TYPE_ACTIVITY_TASK_COMPLETED,
+		enums.EVENT_TYPE_ACTIVITY_TASK_FAILED,
+		enums.EVENT_TYPE_ACTIVITY_TASK_TIMED_OUT,
+		enums.EVENT_TYPE_ACTIVITY_TASK_CANCEL_REQUESTED,
+		enums.EVENT_TYPE_ACTIVITY_TASK_CANCELED:
+		return pacev1.HistoryEventCategory_HISTORY_EVENT_CATEGORY_ACTIVITY
+	case enums.EVENT_TYPE_TIMER_STARTED,
+		enums.EVENT_TYPE_TIMER_FIRED,
+		enums.EVENT_TYPE_TIMER_CANCELED:
+		return pacev1.HistoryEventCategory_HISTORY_EVENT_CATEGORY_TIMER
+	case enums.EVENT_TYPE_WORKFLOW_EXECUTION_SIGNALED,
+		enums.EVENT_TYPE_SIGNAL_EXTERNAL_WORKFLOW_EXECUTION_INITIATED,
+		enums.EVENT_TYPE_SIGNAL_EXTERNAL_WORKFLOW_EXECUTION_FAILED,
+		enums.EVENT_TYPE_EXTERNAL_WORKFLOW_EXECUTION_SIGNALED:
+		return pacev1.HistoryEventCategory_HISTORY_EVENT_CATEGORY_SIGNAL
+	case enums.EVENT_TYPE_MARKER_RECORDED:
+		return pacev1.HistoryEventCategory_HISTORY_EVENT_CATEGORY_MARKER
+	case enums.EVENT_TYPE_START_CHILD_WORKFLOW_EXECUTION_INITIATED,
+		enums.EVENT_TYPE_START_CHILD_WORKFLOW_EXECUTION_FAILED,
+		enums.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_STARTED,
+		enums.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_COMPLETED,
+		enums.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_FAILED,
+		enums.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_CANCELED,
+		enums.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_TIMED_OUT,
+		enums.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_TERMINATED:
+		return pacev1.HistoryEventCategory_HISTORY_EVENT_CATEGORY_CHILD_WORKFLOW
+	default:
+		return pacev1.HistoryEventCategory_HISTORY_EVENT_CATEGORY_UNSPECIFIED
+	}
 }
 
 func (s *Service) TerminateWorkflow(ctx context.Context, namespace string, workflowID string, runID string, reason string) error {
@@ -584,7 +713,12 @@ func (s *Service) CancelWorkflow(ctx context.Context, namespace string, workflow
 	return nil
 }
 
-func (s *Service) SignalWorkflow(ctx context.Context, namespace string, workflowID string, runID string, signalName string, _ *structpb.Struct) error {
+func (s *Service) SignalWorkflow(ctx context.Context, namespace string, workflowID string, runID string, signalName string, input *structpb.Struct) error {
+	payloads, err := structToPayloads(input)
+	if err != nil {
+		return fmt.Errorf("signal workflow: %w", err)
+	}
+
 	req := &workflowservice.SignalWorkflowExecutionRequest{
 		Namespace: namespace,
 		WorkflowExecution: &common.WorkflowExecution{
@@ -592,10 +726,10 @@ func (s *Service) SignalWorkflow(ctx context.Context, namespace string, workflow
 			RunId:      runID,
 		},
 		SignalName: signalName,
-		Input:      nil, // Convert structpb.Struct to payloads if needed
+		Input:      payloads,
 	}
 
-	_, err := s.client.WorkflowService().SignalWorkflowExecution(ctx, req)
+	_, err = s.client.WorkflowService().SignalWorkflowExecution(ctx, req)
 	if err != nil {
 		return fmt.Errorf("signal workflow: %w", err)
 	}
@@ -642,10 +776,10 @@ func (s *Service) RestartWorkflow(ctx context.Context, namespace string, workflo
 		}
 	}
 
-	// Extract task queue from workflow execution info
-	taskQueue := "default"
-	if descResp.WorkflowExecutionInfo.TaskQueue != "" {
-		taskQueue = descResp.WorkflowExecutionInfo.TaskQueue
+	// Restart on the task queue of the original execution
+	taskQueue, err := s.resolveTaskQueue(descResp.WorkflowExecutionInfo.TaskQueue)
+	if err != nil {
+		return "", "", fmt.Errorf("restart workflow: %w", err)
 	}
 
 	// Start a new workflow with the same type and input
@@ -0,0 +1,74 @@
+package workflows
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	pacev1 "github.com/tonica-go/tonica/pkg/tonica/proto/workflows"
+)
+
+func TestStructToPayloads(t *testing.T) {
+	t.Run("round trips through payloadToStruct", func(t *testing.T) {
+		input, err := structpb.NewStruct(map[string]any{
+			"approved": true,
+			"amount":   42.5,
+			"items":    []any{"a", "b"},
+			"customer": map[string]any{"id": "c-1"},
+		})
+		require.NoError(t, err)
+
+		payloads, err := structToPayloads(input)
+		require.NoError(t, err)
+		require.Len(t, payloads.GetPayloads(), 1)
+		assert.Equal(t, "json/plain", string(payloads.GetPayloads()[0].GetMetadata()["encoding"]))
+
+		decoded, err := payloadToStruct(payloads.GetPayloads()[0])
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(input, decoded))
+	})
+
+	t.Run("nil input sends no payloads", func(t *testing.T) {
+		payloads, err := structToPayloads(nil)
+		require.NoError(t, err)
+		assert.Nil(t, payloads)
+	})
+}
+
+func TestVisibilityQuery(t *testing.T) {
+	assert.Empty(t, visibilityQuery("", pacev1.WorkflowStatus_WORKFLOW_STATUS_UNSPECIFIED, ""))
+	assert.Equal(t,
+		"`WorkflowType` = \"OrderWorkflow\" AND `ExecutionStatus` = \"ContinuedAsNew\" AND `WorkflowId` STARTS_WITH \"order-\"",
+		visibilityQuery("OrderWorkflow", pacev1.WorkflowStatus_WORKFLOW_STATUS_CONTINUED_AS_NEW, "order-"),
+	)
+	assert.Equal(t,
+		"`ExecutionStatus` = \"Failed\"",
+		visibilityQuery("", pacev1.WorkflowStatus_WORKFLOW_STATUS_FAILED, ""),
+	)
+	assert.Equal(t,
+		"`WorkflowId` STARTS_WITH \"a\\\" OR \\\\\"",
+		visibilityQuery("", pacev1.WorkflowStatus_WORKFLOW_STATUS_UNSPECIFIED, `a" OR \`),
+		"values are escaped",
+	)
+}
+
+func TestHistoryEventCategory(t *testing.T) {
+	cases := map[enums.EventType]pacev1.HistoryEventCategory{
+		enums.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED:                   pacev1.HistoryEventCategory_HISTORY_EVENT_CATEGORY_WORKFLOW,
+		enums.EVENT_TYPE_WORKFLOW_TASK_COMPLETED:                      pacev1.HistoryEventCategory_HISTORY_EVENT_CATEGORY_WORKFLOW_TASK,
+		enums.EVENT_TYPE_ACTIVITY_TASK_SCHEDULED:                      pacev1.HistoryEventCategory_HISTORY_EVENT_CATEGORY_ACTIVITY,
+		enums.EVENT_TYPE_TIMER_FIRED:                                  pacev1.HistoryEventCategory_HISTORY_EVENT_CATEGORY_TIMER,
+		enums.EVENT_TYPE_WORKFLOW_EXECUTION_SIGNALED:                  pacev1.HistoryEventCategory_HISTORY_EVENT_CATEGORY_SIGNAL,
+		enums.EVENT_TYPE_SIGNAL_EXTERNAL_WORKFLOW_EXECUTION_INITIATED: pacev1.HistoryEventCategory_HISTORY_EVENT_CATEGORY_SIGNAL,
+		enums.EVENT_TYPE_MARKER_RECORDED:                              pacev1.HistoryEventCategory_HISTORY_EVENT_CATEGORY_MARKER,
+		enums.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_COMPLETED:           pacev1.HistoryEventCategory_HISTORY_EVENT_CATEGORY_CHILD_WORKFLOW,
+		enums.EVENT_TYPE_UPSERT_WORKFLOW_SEARCH_ATTRIBUTES:            pacev1.HistoryEventCategory_HISTORY_EVENT_CATEGORY_UNSPECIFIED,
+	}
+	for eventType, want := range cases {
+		assert.Equal(t, want, historyEventCategory(eventType), eventType.String())
+	}
+}
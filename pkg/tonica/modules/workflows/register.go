@@ -0,0 +1,36 @@
+package workflows
+
+import (
+	"go.temporal.io/sdk/client"
+
+	"github.com/tonica-go/tonica/pkg/tonica/worker"
+)
+
+// NewWorker builds a worker that polls taskQueue with c. It is named after
+// the queue unless worker.WithName overrides it, so workers built by several
+// services for the same queue merge when registered with
+// Registry.MustRegisterWorker.
+//
+//	reg.MustRegisterWorker(workflows.NewWorker(c, "orders",
+//		workflows.WithWorkflow(OrderWorkflow),
+//		workflows.WithActivity(&OrderActivities{}),
+//	))
+func NewWorker(c client.Client, taskQueue string, options ...worker.Option) *worker.Worker {
+	return worker.NewWorker(append([]worker.Option{
+		worker.WithName(taskQueue),
+		worker.WithQueue(taskQueue),
+		worker.WithClient(c),
+	}, options...)...)
+}
+
+// WithWorkflow adds a workflow function to the worker, registered under name
+// when given and under the function name otherwise.
+func WithWorkflow(fn interface{}, name ...string) worker.Option {
+	return worker.WithWorkflow(fn, name...)
+}
+
+// WithActivity adds an activity function, or a struct whose exported methods
+// are activities, to the worker.
+func WithActivity(activity interface{}) worker.Option {
+	return worker.WithActivity(activity)
+}
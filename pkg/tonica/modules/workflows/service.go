@@ -2,20 +2,46 @@ package workflows
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/api/workflowservice/v1"
 	"go.temporal.io/sdk/client"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // TaskQueue is the Temporal task queue used by Pace workflows.
 const TaskQueue = "default"
 
+// ErrTaskQueueRequired is returned when a workflow is started without a task
+// queue and the service has no default task queue.
+var ErrTaskQueueRequired = errors.New("task queue is required")
+
+// ErrWorkflowTypeRequired is returned when a workflow is triggered or started
+// without naming its type.
+var ErrWorkflowTypeRequired = errors.New("workflow type is required")
+
+// ErrInvalidPageToken is returned when a page token was not issued by a
+// previous page.
+var ErrInvalidPageToken = errors.New("invalid page token")
+
 // Service coordinates workflow triggers via Temporal.
 type Service struct {
 	client        client.Client
 	workerExample *WorkerExample
+	// defaultTaskQueue is used when a start request names no task queue.
+	defaultTaskQueue string
+	// namespace is the namespace of client, see SetNamespace.
+	namespace string
+	// inputSchemas validates trigger inputs, see SetInputSchemas.
+	inputSchemas *InputSchemas
 }
 
 // NewService constructs Service.
@@ -26,16 +52,55 @@ func NewService(client client.Client) *Service {
 	}
 }
 
-// Trigger schedules a workflow execution in Temporal.
+// SetDefaultTaskQueue sets the task queue used when a trigger or start
+// request names none. Without a default such requests are rejected rather
+// than routed to a queue no worker may be polling.
+func (s *Service) SetDefaultTaskQueue(queue string) {
+	s.defaultTaskQueue = strings.TrimSpace(queue)
+}
+
+// SetInputSchemas sets the schemas Trigger validates inputs against.
+// Workflows without a schema accept any input.
+func (s *Service) SetInputSchemas(schemas *InputSchemas) {
+	s.inputSchemas = schemas
+}
+
+// resolveTaskQueue returns the requested task queue or the default one.
+func (s *Service) resolveTaskQueue(requested string) (string, error) {
+	if queue := strings.TrimSpace(requested); queue != "" {
+		return queue, nil
+	}
+	if s.defaultTaskQueue != "" {
+		return s.defaultTaskQueue, nil
+	}
+	return "", ErrTaskQueueRequired
+}
+
+// Trigger schedules a workflow execution in Temporal on taskQueue, or on the
+// default task queue when it is empty. Input is validated against the schema
+// registered for workflow, if any. Memo and search attributes are attached to
+// the execution; search attribute keys must be registered in the namespace.
 // If waitForCompletion is true, blocks until workflow completes and returns final status.
 // If waitForCompletion is false, returns immediately after starting workflow with status "started".
-func (s *Service) Trigger(ctx context.Context, workflow string, entity string, recordID string, input map[string]string, waitForCompletion bool) (string, string, error) {
+func (s *Service) Trigger(ctx context.Context, workflow string, taskQueue string, entity string, recordID string, input map[string]string, memo map[string]any, searchAttributes map[string]any, waitForCompletion bool) (string, string, error) {
+	if strings.TrimSpace(workflow) == "" {
+		return "", "", ErrWorkflowTypeRequired
+	}
+	taskQueue, err := s.resolveTaskQueue(taskQueue)
+	if err != nil {
+		return "", "", err
+	}
+	if schema, ok := s.inputSchemas.Lookup(workflow); ok {
+		if err := schema.Validate(entity, recordID, input); err != nil {
+			return "", "", err
+		}
+	}
+
 	if s.client == nil {
 		return "", "", fmt.Errorf("temporal client unavailable")
 	}
-
-	if strings.TrimSpace(workflow) == "" {
-		return "", "", fmt.Errorf("workflow name is required")
+	if err := s.validateSearchAttributes(ctx, s.triggerNamespace(), searchAttributes); err != nil {
+		return "", "", err
 	}
 
 	wfInput := WorkflowInput{
@@ -47,8 +112,10 @@ func (s *Service) Trigger(ctx context.Context, workflow string, entity string, r
 
 	workflowID := fmt.Sprintf("%s-%s-%s-%s", entity, recordID, workflow, uuid.NewString())
 	options := client.StartWorkflowOptions{
-		ID:        workflowID,
-		TaskQueue: TaskQueue,
+		ID:               workflowID,
+		TaskQueue:        taskQueue,
+		Memo:             memo,
+		SearchAttributes: searchAttributes,
 	}
 
 	run, err := s.client.ExecuteWorkflow(ctx, options, workflow, wfInput)
@@ -74,3 +141,90 @@ func (s *Service) Trigger(ctx context.Context, workflow string, entity string, r
 
 	return run.GetID(), result, nil
 }
+
+// StartOptions controls a workflow execution started with StartWorkflow.
+// Zero values leave the Temporal server defaults in place.
+type StartOptions struct {
+	ExecutionTimeout time.Duration
+	RunTimeout       time.Duration
+	TaskTimeout      time.Duration
+	IDReusePolicy    enums.WorkflowIdReusePolicy
+	RetryPolicy      *common.RetryPolicy
+	// Memo is attached to the execution as non-indexed metadata.
+	Memo map[string]any
+	// SearchAttributes makes the execution findable in visibility queries.
+	// Keys must be registered in the namespace.
+	SearchAttributes map[string]any
+}
+
+// StartWorkflow starts workflowType on taskQueue, or on the default task queue
+// when it is empty, with input encoded as a json/plain payload and returns the
+// run id. A random workflow id is used when workflowID is empty.
+func (s *Service) StartWorkflow(ctx context.Context, namespace string, workflowType string, taskQueue string, workflowID string, input *structpb.Struct, opts StartOptions) (string, error) {
+	if strings.TrimSpace(workflowType) == "" {
+		return "", ErrWorkflowTypeRequired
+	}
+	taskQueue, err := s.resolveTaskQueue(taskQueue)
+	if err != nil {
+		return "", err
+	}
+	if s.client == nil {
+		return "", fmt.Errorf("temporal client unavailable")
+	}
+	if workflowID == "" {
+		workflowID = newWorkflowID(workflowType)
+	}
+
+	if err := s.validateSearchAttributes(ctx, namespace, opts.SearchAttributes); err != nil {
+		return "", err
+	}
+
+	payloads, err := structToPayloads(input)
+	if err != nil {
+		return "", fmt.Errorf("start workflow: %w", err)
+	}
+	memo, err := encodeMemo(opts.Memo)
+	if err != nil {
+		return "", fmt.Errorf("start workflow: memo: %w", err)
+	}
+	searchAttributes, err := encodeSearchAttributes(opts.SearchAttributes)
+	if err != nil {
+		return "", fmt.Errorf("start workflow: search attributes: %w", err)
+	}
+
+	req := &workflowservice.StartWorkflowExecutionRequest{
+		Namespace:    namespace,
+		WorkflowId:   workflowID,
+		WorkflowType: &common.WorkflowType{Name: workflowType},
+		TaskQueue: &taskqueue.TaskQueue{
+			Name: taskQueue,
+		},
+		Input:                    payloads,
+		WorkflowExecutionTimeout: optionalDuration(opts.ExecutionTimeout),
+		WorkflowRunTimeout:       optionalDuration(opts.RunTimeout),
+		WorkflowTaskTimeout:      optionalDuration(opts.TaskTimeout),
+		WorkflowIdReusePolicy:    opts.IDReusePolicy,
+		RetryPolicy:              opts.RetryPolicy,
+		Memo:                     memo,
+		SearchAttributes:         searchAttributes,
+		RequestId:                uuid.NewString(),
+	}
+
+	resp, err := s.client.WorkflowService().StartWorkflowExecution(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("start workflow: %w", err)
+	}
+
+	return resp.GetRunId(), nil
+}
+
+func newWorkflowID(workflowType string) string {
+	return fmt.Sprintf("%s-%s", workflowType, uuid.NewString())
+}
+
+func optionalDuration(d time.Duration) *durationpb.Duration {
+	if d <= 0 {
+		return nil
+	}
+	return durationpb.New(d)
+}
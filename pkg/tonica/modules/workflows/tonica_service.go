@@ -2,22 +2,32 @@ package workflows
 
 import (
 	"context"
+	"errors"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/tonica-go/tonica/pkg/tonica/config"
 	"github.com/tonica-go/tonica/pkg/tonica/service"
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
 	"go.temporal.io/sdk/client"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	pb "github.com/tonica-go/tonica/pkg/tonica/proto/workflows"
 )
 
 // NewTonicaService creates a new tonica service for workflows module.
-// temporalClient must be initialized before calling this function.
-func NewTonicaService(temporalClient client.Client) *service.Service {
+// temporalClient must be initialized before calling this function. Workflows
+// started without a task queue run on TEMPORAL_TASK_QUEUE; when it is unset
+// requests must name their task queue. Triggers are validated against
+// schemas, which may be nil.
+func NewTonicaService(temporalClient client.Client, schemas *InputSchemas) *service.Service {
 	// Create closure that captures temporal client
 	registerGRPCFunc := func(grpcServer *grpc.Server, svc *service.Service) {
-		registerGRPCWithClient(grpcServer, svc, temporalClient)
+		registerGRPCWithClient(grpcServer, svc, temporalClient, schemas)
 	}
 
 	return service.NewService(
@@ -29,13 +39,16 @@ func NewTonicaService(temporalClient client.Client) *service.Service {
 }
 
 // registerGRPCWithClient registers the workflows gRPC service with temporal client.
-func registerGRPCWithClient(grpcServer *grpc.Server, svc *service.Service, temporalClient client.Client) {
+func registerGRPCWithClient(grpcServer *grpc.Server, svc *service.Service, temporalClient client.Client, schemas *InputSchemas) {
 	if temporalClient == nil {
 		panic("temporal client not configured for workflows service")
 	}
 
 	// Create workflows service
 	workflowSvc := NewService(temporalClient)
+	workflowSvc.SetDefaultTaskQueue(config.GetEnv("TEMPORAL_TASK_QUEUE", ""))
+	workflowSvc.SetNamespace(config.GetEnv("TEMPORAL_NAMESPACE", defaultNamespace))
+	workflowSvc.SetInputSchemas(schemas)
 
 	// Register gRPC server
 	handler := &grpcHandler{svc: workflowSvc}
@@ -60,24 +73,89 @@ func (h *grpcHandler) TriggerWorkflow(ctx context.Context, req *pb.TriggerWorkfl
 		waitForCompletion = false
 	}
 
-	executionID, status, err := h.svc.Trigger(
+	executionID, runStatus, err := h.svc.Trigger(
 		ctx,
 		req.GetWorkflow(),
+		req.GetTaskQueue(),
 		req.GetEntity(),
 		req.GetRecordId(),
 		req.GetInput(),
+		structMap(req.GetMemo()),
+		structMap(req.GetSearchAttributes()),
 		waitForCompletion,
 	)
+	if errors.Is(err, ErrInvalidInput) || errors.Is(err, ErrWorkflowTypeRequired) || errors.Is(err, ErrTaskQueueRequired) || errors.Is(err, ErrUnknownSearchAttribute) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	return &pb.TriggerWorkflowResponse{
 		ExecutionId: executionID,
-		Status:      status,
+		Status:      runStatus,
+	}, nil
+}
+
+func (h *grpcHandler) StartWorkflow(ctx context.Context, req *pb.StartWorkflowRequest) (*pb.StartWorkflowResponse, error) {
+	workflowID := req.GetWorkflowId()
+	if workflowID == "" {
+		workflowID = newWorkflowID(req.GetWorkflowType())
+	}
+
+	runID, err := h.svc.StartWorkflow(
+		ctx,
+		req.GetNamespace(),
+		req.GetWorkflowType(),
+		req.GetTaskQueue(),
+		workflowID,
+		req.GetInput(),
+		startOptions(req),
+	)
+	if errors.Is(err, ErrWorkflowTypeRequired) || errors.Is(err, ErrTaskQueueRequired) || errors.Is(err, ErrUnknownSearchAttribute) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.StartWorkflowResponse{
+		WorkflowId: workflowID,
+		RunId:      runID,
 	}, nil
 }
 
+// startOptions maps the request's execution options onto StartOptions. The
+// proto reuse policy mirrors Temporal's enum value for value.
+func startOptions(req *pb.StartWorkflowRequest) StartOptions {
+	opts := StartOptions{
+		ExecutionTimeout: req.GetExecutionTimeout().AsDuration(),
+		RunTimeout:       req.GetRunTimeout().AsDuration(),
+		TaskTimeout:      req.GetTaskTimeout().AsDuration(),
+		IDReusePolicy:    enums.WorkflowIdReusePolicy(req.GetIdReusePolicy()),
+		Memo:             structMap(req.GetMemo()),
+		SearchAttributes: structMap(req.GetSearchAttributes()),
+	}
+	if rp := req.GetRetryPolicy(); rp != nil {
+		opts.RetryPolicy = &common.RetryPolicy{
+			InitialInterval:        rp.GetInitialInterval(),
+			BackoffCoefficient:     rp.GetBackoffCoefficient(),
+			MaximumInterval:        rp.GetMaximumInterval(),
+			MaximumAttempts:        rp.GetMaximumAttempts(),
+			NonRetryableErrorTypes: rp.GetNonRetryableErrorTypes(),
+		}
+	}
+	return opts
+}
+
+// structMap converts s to a map, keeping nil for unset fields.
+func structMap(s *structpb.Struct) map[string]any {
+	if s == nil {
+		return nil
+	}
+	return s.AsMap()
+}
+
 func (h *grpcHandler) ListNamespaces(ctx context.Context, req *pb.ListNamespacesRequest) (*pb.ListNamespacesResponse, error) {
 	res, err := h.svc.ListNamespaces(ctx)
 	if err != nil {
@@ -91,11 +169,14 @@ func (h *grpcHandler) ListNamespaces(ctx context.Context, req *pb.ListNamespaces
 }
 
 func (h *grpcHandler) ListWorkflows(ctx context.Context, req *pb.ListWorkflowsRequest) (*pb.ListWorkflowsResponse, error) {
-	res, _, err := h.svc.ListWorkflows(ctx, req.GetNamespace(), req.GetWorkflowType(), req.GetStatus(), req.GetPageSize(), req.GetPageToken(), req.GetSearchQuery())
+	res, nextPageToken, err := h.svc.ListWorkflows(ctx, req.GetNamespace(), req.GetWorkflowType(), req.GetStatus(), req.GetPageSize(), req.GetPageToken(), req.GetSearchQuery())
+	if errors.Is(err, ErrInvalidPageToken) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
-	response := &pb.ListWorkflowsResponse{}
+	response := &pb.ListWorkflowsResponse{NextPageToken: nextPageToken}
 	for _, workflow := range res {
 		response.Executions = append(response.Executions, workflow)
 	}
@@ -119,11 +200,14 @@ func (h *grpcHandler) GetWorkflow(ctx context.Context, req *pb.GetWorkflowReques
 }
 
 func (h *grpcHandler) GetWorkflowHistory(ctx context.Context, req *pb.GetWorkflowHistoryRequest) (*pb.GetWorkflowHistoryResponse, error) {
-	res, _, err := h.svc.GetWorkflowHistory(ctx, req.GetNamespace(), req.GetWorkflowId(), req.GetRunId(), req.GetPageSize(), req.GetPageToken())
+	res, nextPageToken, err := h.svc.GetWorkflowHistory(ctx, req.GetNamespace(), req.GetWorkflowId(), req.GetRunId(), req.GetPageSize(), req.GetPageToken(), req.GetCategories())
+	if errors.Is(err, ErrInvalidPageToken) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
-	response := &pb.GetWorkflowHistoryResponse{}
+	response := &pb.GetWorkflowHistoryResponse{NextPageToken: nextPageToken}
 	response.History = append(response.History, res...)
 
 	return response, nil
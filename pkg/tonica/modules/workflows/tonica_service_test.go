@@ -0,0 +1,174 @@
+package workflows
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	pb "github.com/tonica-go/tonica/pkg/tonica/proto/workflows"
+)
+
+func TestStartOptions(t *testing.T) {
+	t.Run("maps execution options", func(t *testing.T) {
+		opts := startOptions(&pb.StartWorkflowRequest{
+			ExecutionTimeout: durationpb.New(time.Hour),
+			RunTimeout:       durationpb.New(10 * time.Minute),
+			IdReusePolicy:    pb.WorkflowIdReusePolicy_WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE,
+			RetryPolicy: &pb.RetryPolicy{
+				InitialInterval:        durationpb.New(time.Second),
+				BackoffCoefficient:     2,
+				MaximumAttempts:        5,
+				NonRetryableErrorTypes: []string{"ValidationError"},
+			},
+		})
+
+		assert.Equal(t, time.Hour, opts.ExecutionTimeout)
+		assert.Equal(t, 10*time.Minute, opts.RunTimeout)
+		assert.Zero(t, opts.TaskTimeout)
+		assert.Equal(t, enums.WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE, opts.IDReusePolicy)
+		require.NotNil(t, opts.RetryPolicy)
+		assert.Equal(t, time.Second, opts.RetryPolicy.GetInitialInterval().AsDuration())
+		assert.Equal(t, int32(5), opts.RetryPolicy.GetMaximumAttempts())
+		assert.Equal(t, []string{"ValidationError"}, opts.RetryPolicy.GetNonRetryableErrorTypes())
+	})
+
+	t.Run("leaves server defaults when unset", func(t *testing.T) {
+		opts := startOptions(&pb.StartWorkflowRequest{})
+
+		assert.Equal(t, StartOptions{}, opts)
+		assert.Nil(t, optionalDuration(opts.ExecutionTimeout))
+	})
+}
+
+func TestTriggerWorkflow_InputSchema(t *testing.T) {
+	schemas := NewInputSchemas()
+	schemas.Register("approve_order", InputSchema{
+		RequireRecordID: true,
+		Required:        []string{"approver"},
+		Optional:        []string{"note"},
+	})
+	svc := NewService(nil)
+	svc.SetDefaultTaskQueue("orders")
+	svc.SetInputSchemas(schemas)
+	h := &grpcHandler{svc: svc}
+
+	_, err := h.TriggerWorkflow(context.Background(), &pb.TriggerWorkflowRequest{
+		Workflow: "approve_order",
+		Input:    map[string]string{"approver": " ", "color": "red"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.ErrorContains(t, err, "record_id is required, payload.approver is required, payload.color is not accepted")
+
+	// Valid input gets past validation to the missing client.
+	_, err = h.TriggerWorkflow(context.Background(), &pb.TriggerWorkflowRequest{
+		Workflow: "approve_order",
+		RecordId: "o-1",
+		Input:    map[string]string{"approver": "ann"},
+	})
+	assert.ErrorContains(t, err, "temporal client unavailable")
+
+	// Workflows without a schema accept any input.
+	_, err = h.TriggerWorkflow(context.Background(), &pb.TriggerWorkflowRequest{
+		Workflow: "other",
+		Input:    map[string]string{"anything": "goes"},
+	})
+	assert.ErrorContains(t, err, "temporal client unavailable")
+}
+
+func TestWithValidatedWorkflow(t *testing.T) {
+	schema := InputSchema{RequireEntity: true}
+
+	schemas := NewInputSchemas()
+
+	WithValidatedWorkflow(schemas, (&WorkerExample{}).PaceWorkflowDummy, schema)
+	registered, ok := schemas.Lookup("PaceWorkflowDummy")
+	require.True(t, ok)
+	assert.True(t, registered.RequireEntity)
+
+	WithValidatedWorkflow(schemas, (&WorkerExample{}).PaceWorkflowDummy, schema, "pace")
+	_, ok = schemas.Lookup("pace")
+	assert.True(t, ok)
+
+	var none *InputSchemas
+	_, ok = none.Lookup("pace")
+	assert.False(t, ok)
+}
+
+func TestTaskQueue(t *testing.T) {
+	svc := NewService(nil)
+	h := &grpcHandler{svc: svc}
+
+	_, err := h.TriggerWorkflow(context.Background(), &pb.TriggerWorkflowRequest{Workflow: "report"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "no queue and no default")
+	_, err = h.StartWorkflow(context.Background(), &pb.StartWorkflowRequest{WorkflowType: "report"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = h.TriggerWorkflow(context.Background(), &pb.TriggerWorkflowRequest{TaskQueue: "reports"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "no workflow type")
+	_, err = h.StartWorkflow(context.Background(), &pb.StartWorkflowRequest{TaskQueue: "reports"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "no workflow type")
+
+	_, err = h.TriggerWorkflow(context.Background(), &pb.TriggerWorkflowRequest{Workflow: "report", TaskQueue: "reports"})
+	assert.ErrorContains(t, err, "temporal client unavailable")
+
+	queue, err := svc.resolveTaskQueue(" ")
+	assert.ErrorIs(t, err, ErrTaskQueueRequired)
+	assert.Empty(t, queue)
+
+	svc.SetDefaultTaskQueue(" billing ")
+	queue, err = svc.resolveTaskQueue("")
+	require.NoError(t, err)
+	assert.Equal(t, "billing", queue)
+	queue, err = svc.resolveTaskQueue("reports")
+	require.NoError(t, err)
+	assert.Equal(t, "reports", queue)
+}
+
+func TestWorkflowAttributes(t *testing.T) {
+	opts := startOptions(&pb.StartWorkflowRequest{
+		Memo: &structpb.Struct{Fields: map[string]*structpb.Value{
+			"note": structpb.NewStringValue("rush order"),
+		}},
+		SearchAttributes: &structpb.Struct{Fields: map[string]*structpb.Value{
+			"TenantId": structpb.NewStringValue("acme"),
+		}},
+	})
+	assert.Equal(t, map[string]any{"note": "rush order"}, opts.Memo)
+	assert.Equal(t, map[string]any{"TenantId": "acme"}, opts.SearchAttributes)
+
+	memo, err := encodeMemo(opts.Memo)
+	require.NoError(t, err)
+	require.Contains(t, memo.GetFields(), "note")
+	assert.JSONEq(t, `"rush order"`, string(memo.GetFields()["note"].GetData()))
+
+	attributes, err := encodeSearchAttributes(nil)
+	require.NoError(t, err)
+	assert.Nil(t, attributes)
+
+	custom := map[string]enums.IndexedValueType{"TenantId": enums.INDEXED_VALUE_TYPE_KEYWORD}
+	system := map[string]enums.IndexedValueType{"WorkflowType": enums.INDEXED_VALUE_TYPE_KEYWORD}
+	assert.NoError(t, checkSearchAttributes(map[string]any{"TenantId": "acme", "WorkflowType": "x"}, custom, system))
+
+	err = checkSearchAttributes(map[string]any{"TenantID": "acme", "OrderId": "1", "TenantId": "acme"}, custom, system)
+	assert.ErrorIs(t, err, ErrUnknownSearchAttribute)
+	assert.ErrorContains(t, err, "OrderId, TenantID")
+}
+
+func TestInvalidPageToken(t *testing.T) {
+	h := &grpcHandler{svc: NewService(nil)}
+
+	_, err := h.ListWorkflows(context.Background(), &pb.ListWorkflowsRequest{PageToken: "not a token"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = h.GetWorkflowHistory(context.Background(), &pb.GetWorkflowHistoryRequest{WorkflowId: "wf-1", PageToken: "not a token"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.ErrorContains(t, err, "get workflow history: invalid page token")
+}
@@ -0,0 +1,249 @@
+package obs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Default messages of the request log lines.
+const (
+	DefaultHTTPLogMessage = "http request"
+	DefaultGRPCLogMessage = "grpc call"
+)
+
+// RedactedValue replaces the values of redacted headers in log lines.
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactedHeaders are always redacted, whatever LogConfig says.
+var DefaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Api-Key"}
+
+// DefaultRedactedFields are always redacted in logged payloads, whatever
+// LogConfig says.
+var DefaultRedactedFields = []string{"password", "secret", "token", "access_token", "refresh_token", "api_key"}
+
+// DefaultPayloadLimit is the default size in bytes of a logged payload.
+const DefaultPayloadLimit = 4096
+
+// DefaultGRPCPayloadMessage is the message of gRPC payload lines.
+const DefaultGRPCPayloadMessage = "grpc payload"
+
+// LogConfig controls the request log lines written by HTTPLogger and the gRPC
+// logging interceptors.
+type LogConfig struct {
+	// FieldNames renames fields, keyed by their default name
+	// (e.g. "trace_id": "traceId"). Unlisted fields keep their name.
+	FieldNames map[string]string
+	// Headers lists request headers (gRPC metadata keys on the gRPC side)
+	// logged under the "headers" field. None are logged by default.
+	Headers []string
+	// RedactHeaders adds headers to DefaultRedactedHeaders.
+	RedactHeaders []string
+	// HTTPMessage is the message of HTTP request lines. Defaults to
+	// DefaultHTTPLogMessage.
+	HTTPMessage string
+	// GRPCMessage is the message of gRPC call lines. Defaults to
+	// DefaultGRPCLogMessage.
+	GRPCMessage string
+	// GRPCPayloads logs the request and response messages of unary gRPC
+	// calls as protojson in a DefaultGRPCPayloadMessage line at debug level.
+	// Payloads may hold personal data, so it is off by default and the line
+	// is only written when the logger is at debug level.
+	GRPCPayloads bool
+	// RedactFields adds message fields to DefaultRedactedFields. Fields match
+	// at any depth by their proto or JSON name, e.g. "card_number" or
+	// "cardNumber".
+	RedactFields []string
+	// PayloadLimit truncates logged payloads to that many bytes. Defaults to
+	// DefaultPayloadLimit.
+	PayloadLimit int
+}
+
+// logSchema is the normalized form of LogConfig.
+type logSchema struct {
+	names   map[string]string
+	headers []string
+	redact  map[string]struct{}
+	httpMsg string
+	grpcMsg string
+
+	payloads     bool
+	redactFields map[string]struct{}
+	payloadLimit int
+}
+
+func newLogSchema(cfg LogConfig) *logSchema {
+	s := &logSchema{
+		names:   make(map[string]string, len(cfg.FieldNames)),
+		redact:  make(map[string]struct{}),
+		httpMsg: DefaultHTTPLogMessage,
+		grpcMsg: DefaultGRPCLogMessage,
+
+		payloads:     cfg.GRPCPayloads,
+		redactFields: make(map[string]struct{}),
+		payloadLimit: DefaultPayloadLimit,
+	}
+	if cfg.PayloadLimit > 0 {
+		s.payloadLimit = cfg.PayloadLimit
+	}
+	for _, f := range append(append([]string(nil), DefaultRedactedFields...), cfg.RedactFields...) {
+		if f = fieldKey(f); f != "" {
+			s.redactFields[f] = struct{}{}
+		}
+	}
+	if msg := strings.TrimSpace(cfg.HTTPMessage); msg != "" {
+		s.httpMsg = msg
+	}
+	if msg := strings.TrimSpace(cfg.GRPCMessage); msg != "" {
+		s.grpcMsg = msg
+	}
+	for from, to := range cfg.FieldNames {
+		if to = strings.TrimSpace(to); to != "" {
+			s.names[from] = to
+		}
+	}
+	for _, h := range cfg.Headers {
+		if h = strings.TrimSpace(h); h != "" {
+			s.headers = append(s.headers, h)
+		}
+	}
+	for _, h := range append(append([]string(nil), DefaultRedactedHeaders...), cfg.RedactHeaders...) {
+		s.redact[strings.ToLower(strings.TrimSpace(h))] = struct{}{}
+	}
+	return s
+}
+
+// RequestLogger writes the HTTP and gRPC request log lines of one app with
+// its LogConfig.
+type RequestLogger struct {
+	schema *logSchema
+}
+
+var defaultRequestLogger = NewRequestLogger(LogConfig{})
+
+// NewRequestLogger returns a request logger using the messages, field names,
+// logged headers and payloads and the redactions of cfg.
+func NewRequestLogger(cfg LogConfig) *RequestLogger {
+	return &RequestLogger{schema: newLogSchema(cfg)}
+}
+
+// logFields turns key/value pairs into slog arguments, applying the
+// configured field names.
+func (s *logSchema) logFields(kv ...any) []any {
+	args := make([]any, 0, len(kv))
+	for i := 0; i+1 < len(kv); i += 2 {
+		args = append(args, s.name(kv[i].(string)), kv[i+1])
+	}
+	return args
+}
+
+func (s *logSchema) name(field string) string {
+	if name, ok := s.names[field]; ok {
+		return name
+	}
+	return field
+}
+
+func (s *logSchema) redacted(header string) bool {
+	_, ok := s.redact[strings.ToLower(header)]
+	return ok
+}
+
+// httpHeaders returns the configured headers present on h, redacted as needed.
+func (s *logSchema) httpHeaders(h http.Header) map[string]string {
+	var out map[string]string
+	for _, name := range s.headers {
+		value := h.Get(name)
+		if value == "" {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		if s.redacted(name) {
+			value = RedactedValue
+		}
+		out[name] = value
+	}
+	return out
+}
+
+// grpcHeaders returns the configured metadata keys present on the incoming
+// context, redacted as needed.
+func (s *logSchema) grpcHeaders(ctx context.Context) map[string]string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+	var out map[string]string
+	for _, name := range s.headers {
+		values := md.Get(name)
+		if len(values) == 0 {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		value := strings.Join(values, ",")
+		if s.redacted(name) {
+			value = RedactedValue
+		}
+		out[strings.ToLower(name)] = value
+	}
+	return out
+}
+
+// fieldKey normalizes a message field name so proto and JSON names match.
+func fieldKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", ""))
+}
+
+// payload renders msg as protojson for a log line, with the configured fields
+// redacted and truncated to the payload limit. Values that are not proto
+// messages are left out.
+func (s *logSchema) payload(msg any) (string, bool) {
+	m, ok := msg.(proto.Message)
+	if !ok || !m.ProtoReflect().IsValid() {
+		return "", false
+	}
+	raw, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	if err != nil {
+		return "", false
+	}
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", false
+	}
+	out, err := json.Marshal(s.redactValue(value))
+	if err != nil {
+		return "", false
+	}
+	if len(out) > s.payloadLimit {
+		return string(out[:s.payloadLimit]) + "...(truncated)", true
+	}
+	return string(out), true
+}
+
+// redactValue replaces the values of redacted fields in a decoded JSON value.
+func (s *logSchema) redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if _, ok := s.redactFields[fieldKey(key)]; ok {
+				v[key] = RedactedValue
+				continue
+			}
+			v[key] = s.redactValue(field)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = s.redactValue(item)
+		}
+	}
+	return value
+}
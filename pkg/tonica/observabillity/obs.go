@@ -4,9 +4,13 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"runtime/debug"
 	"strconv"
 	"time"
 
@@ -31,6 +35,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/stats"
 	"google.golang.org/grpc/status"
 )
 
@@ -39,8 +44,18 @@ type Config struct {
 	ServiceVersion string
 	OTLPEndpoint   string // e.g., "localhost:4317" (gRPC)
 	LogLevel       string // debug, info, warn, error
+	// OTLPStrict makes Init fail when the OTLP endpoint is unreachable
+	// instead of starting with a warning.
+	OTLPStrict bool
+	// MetricExporters are pushed to periodically in addition to Prometheus.
+	MetricExporters []sdkmetric.Exporter
+	// DisablePrometheus turns off the Prometheus reader; MetricsHandler is nil.
+	DisablePrometheus bool
 }
 
+// otlpDialTimeout bounds the connectivity probe against the OTLP endpoint.
+const otlpDialTimeout = 3 * time.Second
+
 type Observability struct {
 	MetricsHandler http.Handler
 	Shutdown       func(ctx context.Context) error
@@ -65,11 +80,11 @@ func Init(ctx context.Context, cfg Config) (*Observability, error) {
 
 	// Traces: use OTLP gRPC exporter if endpoint set; otherwise, a noop provider.
 	var tp *sdktrace.TracerProvider
-	if cfg.OTLPEndpoint != "" {
-		exp, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint), otlptracegrpc.WithInsecure())
-		if err != nil {
-			return nil, err
-		}
+	exp, err := newTraceExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if exp != nil {
 		tp = sdktrace.NewTracerProvider(
 			sdktrace.WithBatcher(exp),
 			sdktrace.WithResource(res),
@@ -80,17 +95,6 @@ func Init(ctx context.Context, cfg Config) (*Observability, error) {
 	otel.SetTracerProvider(tp)
 	otel.SetTextMapPropagator(propagation.TraceContext{})
 
-	// Metrics: Prometheus exporter mounted at /metrics
-	reg := promclient.NewRegistry()
-	// Add Go and process collectors for baseline metrics
-	reg.MustRegister(collectors.NewGoCollector())
-	reg.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
-
-	prom, err := promexp.New(promexp.WithRegisterer(reg))
-	if err != nil {
-		return nil, err
-	}
-
 	// Get histogram buckets from env or use defaults
 	// In milliseconds: 10ms, 50ms, 100ms, 250ms, 500ms, 1s, 2.5s, 5s, 10s, 30s, 60s
 	buckets := getHistogramBuckets()
@@ -105,21 +109,174 @@ func Init(ctx context.Context, cfg Config) (*Observability, error) {
 		},
 	)
 
-	// Wire the exporter into the OTel metrics SDK with custom views
-	mp := sdkmetric.NewMeterProvider(
-		sdkmetric.WithReader(prom),
+	mpOpts := []sdkmetric.Option{
 		sdkmetric.WithResource(res),
 		sdkmetric.WithView(customView),
-	)
+	}
+
+	// Metrics: Prometheus exporter mounted at /metrics
+	var handler http.Handler
+	if !cfg.DisablePrometheus {
+		reg := promclient.NewRegistry()
+		// Add Go and process collectors for baseline metrics
+		reg.MustRegister(collectors.NewGoCollector())
+		reg.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+		prom, err := promexp.New(promexp.WithRegisterer(reg))
+		if err != nil {
+			return nil, err
+		}
+		mpOpts = append(mpOpts, sdkmetric.WithReader(prom))
+		// OpenMetrics is required to expose exemplars linking metrics to
+		// traces. The SDK records them for measurements made in sampled spans,
+		// its default OTEL_METRICS_EXEMPLAR_FILTER.
+		handler = promhttp.HandlerFor(reg, promhttp.HandlerOpts{EnableOpenMetrics: true})
+	}
+
+	// Push based exporters (OTLP, vendor specific) are read periodically
+	for _, exp := range cfg.MetricExporters {
+		mpOpts = append(mpOpts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exp)))
+	}
+
+	// Wire the readers into the OTel metrics SDK with custom views
+	mp := sdkmetric.NewMeterProvider(mpOpts...)
 	otel.SetMeterProvider(mp)
-	handler := promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
 
 	return &Observability{
 		MetricsHandler: handler,
-		Shutdown:       func(ctx context.Context) error { return tp.Shutdown(ctx) },
+		Shutdown: func(ctx context.Context) error {
+			return errors.Join(tp.Shutdown(ctx), mp.Shutdown(ctx))
+		},
 	}, nil
 }
 
+// newTraceExporter creates the OTLP exporter. The gRPC connection is lazy and
+// failed exports are retried with backoff by the exporter, so an unreachable
+// collector never blocks startup. In strict mode the endpoint is probed and
+// any failure is returned; otherwise a single warning is logged.
+func newTraceExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	if cfg.OTLPEndpoint == "" {
+		return nil, nil
+	}
+
+	if cfg.OTLPStrict {
+		if err := probeEndpoint(ctx, cfg.OTLPEndpoint); err != nil {
+			return nil, fmt.Errorf("otlp endpoint %s unreachable: %w", cfg.OTLPEndpoint, err)
+		}
+	}
+
+	exp, err := otlptracegrpc.New(ctx,
+		otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint),
+		otlptracegrpc.WithInsecure(),
+		otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig{
+			Enabled:         true,
+			InitialInterval: time.Second,
+			MaxInterval:     30 * time.Second,
+			MaxElapsedTime:  time.Minute,
+		}),
+	)
+	if err != nil {
+		if cfg.OTLPStrict {
+			return nil, err
+		}
+		slog.Warn("otlp exporter disabled", "endpoint", cfg.OTLPEndpoint, "error", err.Error())
+		return nil, nil
+	}
+
+	if !cfg.OTLPStrict {
+		installExportErrorHandler(cfg.OTLPEndpoint)
+		go func() {
+			if err := probeEndpoint(context.Background(), cfg.OTLPEndpoint); err != nil {
+				warnExportFailure(cfg.OTLPEndpoint, err)
+			}
+		}()
+	}
+
+	return exp, nil
+}
+
+// exportWarnInterval is the minimum time between two telemetry export
+// warnings, so a collector outage does not flood the service logs.
+const exportWarnInterval = time.Minute
+
+var (
+	exportWarn = exportWarner{interval: exportWarnInterval}
+	// defaultErrorHandler is the handler otel starts with. It forwards to the
+	// first handler that is set, so wrapping it would call back into us.
+	defaultErrorHandler = otel.GetErrorHandler()
+)
+
+// exportWarner lets one warning through per interval and counts the ones
+// it holds back.
+type exportWarner struct {
+	interval time.Duration
+
+	mu         sync.Mutex
+	last       time.Time
+	suppressed int
+}
+
+// allow reports whether a warning may be logged at now and how many were
+// suppressed since the last one.
+func (w *exportWarner) allow(now time.Time) (int, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.last.IsZero() && now.Sub(w.last) < w.interval {
+		w.suppressed++
+		return 0, false
+	}
+	suppressed := w.suppressed
+	w.last, w.suppressed = now, 0
+	return suppressed, true
+}
+
+func warnExportFailure(endpoint string, err error) {
+	suppressed, ok := exportWarn.allow(time.Now())
+	if !ok {
+		return
+	}
+	slog.Warn("otlp endpoint unreachable, traces will be retried in background",
+		"endpoint", endpoint,
+		"error", err.Error(),
+		"suppressed", suppressed,
+	)
+}
+
+// exportErrorHandler logs otel errors at a limited rate and passes them on
+// to the handler that was installed before it.
+type exportErrorHandler struct {
+	endpoint string
+	next     otel.ErrorHandler
+}
+
+func (h *exportErrorHandler) Handle(err error) {
+	warnExportFailure(h.endpoint, err)
+	if h.next != nil {
+		h.next.Handle(err)
+	}
+}
+
+func installExportErrorHandler(endpoint string) {
+	next := otel.GetErrorHandler()
+	if installed, ok := next.(*exportErrorHandler); ok {
+		next = installed.next
+	}
+	if next == defaultErrorHandler {
+		next = nil
+	}
+	otel.SetErrorHandler(&exportErrorHandler{endpoint: endpoint, next: next})
+}
+
+func probeEndpoint(ctx context.Context, endpoint string) error {
+	dialer := net.Dialer{Timeout: otlpDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", endpoint)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
 // getHistogramBuckets returns histogram buckets from env or defaults
 func getHistogramBuckets() []float64 {
 	// Default buckets in milliseconds: 10ms, 50ms, 100ms, 250ms, 500ms, 1s, 2.5s, 5s, 10s, 30s, 60s
@@ -199,21 +356,21 @@ func HTTPTracing(serviceName string) gin.HandlerFunc {
 	}
 }
 
-// RequestID adds/propagates X-Request-ID.
+// RequestID adds/propagates the X-Request-ID header. Apps configure the
+// header and format through NewRequestIDs instead.
 func RequestID() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		rid := c.GetHeader("X-Request-ID")
-		if rid == "" {
-			rid = randomID()
-			c.Writer.Header().Set("X-Request-ID", rid)
-		}
-		c.Set("request_id", rid)
-		c.Next()
-	}
+	return defaultRequestIDs.Middleware()
 }
 
-// HTTPLogger logs request/response with slog and trace id.
+// HTTPLogger logs request/response with slog and trace id, using the default
+// LogConfig. Apps log through their own RequestLogger instead.
 func HTTPLogger() gin.HandlerFunc {
+	return defaultRequestLogger.HTTP()
+}
+
+// HTTP logs request/response with slog and trace id.
+func (l *RequestLogger) HTTP() gin.HandlerFunc {
+	schema := l.schema
 	return func(c *gin.Context) {
 		// Skip noisy endpoints and OPTIONS requests from logging/metrics
 		if isObsPath(c.Request.URL.Path) || c.Request.Method == http.MethodOptions {
@@ -221,9 +378,10 @@ func HTTPLogger() gin.HandlerFunc {
 			return
 		}
 		start := time.Now()
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), metricServiceKey{}, &metricService{}))
 		c.Next()
 		latency := time.Since(start)
-		rid, _ := c.Get("request_id")
+		rid := c.GetString("request_id")
 		sc := trace.SpanContextFromContext(c.Request.Context())
 		// Record OTel metrics for HTTP
 		recordHTTPMetrics(c, latency)
@@ -232,27 +390,48 @@ func HTTPLogger() gin.HandlerFunc {
 		if len(c.Errors) > 0 {
 			errMsg = c.Errors.String()
 		}
-		// Choose level by logStatus and errors
-		logStatus := c.Writer.Status()
-		logFn := slog.Info
-		if logStatus >= 500 || errMsg != "" {
-			logFn = slog.Error
-		} else if logStatus >= 400 {
-			logFn = slog.Warn
+		// Choose level by status and errors
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= 500 || errMsg != "" {
+			level = slog.LevelError
+		} else if status >= 400 {
+			level = slog.LevelWarn
 		}
-		logFn(
+		args := schema.logFields(
+			"protocol", "http",
 			"trace_id", sc.TraceID().String(),
 			"span_id", sc.SpanID().String(),
-			"http",
+			"request_id", rid,
 			"method", c.Request.Method,
 			"path", c.Request.URL.Path,
-			"logStatus", logStatus,
+			"status", status,
 			"duration_ms", latency.Milliseconds(),
-			"request_id", rid,
 			"ip", c.ClientIP(),
 			"user_agent", c.Request.UserAgent(),
 			"error", errMsg,
 		)
+		if headers := schema.httpHeaders(c.Request.Header); headers != nil {
+			args = append(args, schema.logFields("headers", headers)...)
+		}
+		slog.Log(c.Request.Context(), level, schema.httpMsg, args...)
+	}
+}
+
+type metricServiceKey struct{}
+
+// metricService is filled while the request is handled, once the service
+// serving it is known.
+type metricService struct {
+	name string
+}
+
+// SetMetricService attributes the HTTP request carried by ctx to service in
+// the request metrics, as the "service" attribute. It does nothing for
+// contexts not created by HTTPLogger.
+func SetMetricService(ctx context.Context, service string) {
+	if holder, ok := ctx.Value(metricServiceKey{}).(*metricService); ok {
+		holder.name = service
 	}
 }
 
@@ -290,6 +469,11 @@ func recordHTTPMetrics(c *gin.Context, d time.Duration) {
 		attribute.String("route", c.Request.URL.Path),
 		attribute.Int("status", c.Writer.Status()),
 	}
+	if holder, ok := c.Request.Context().Value(metricServiceKey{}).(*metricService); ok && holder.name != "" {
+		attrs = append(attrs, attribute.String("service", holder.name))
+	}
+	// The request context carries the span started by HTTPTracing, so sampled
+	// requests attach their trace id as an exemplar to the latency histogram.
 	ctx := c.Request.Context()
 	if httpReqCounter != nil {
 		httpReqCounter.Add(ctx, 1, metric.WithAttributes(attrs...))
@@ -309,16 +493,72 @@ func isObsPath(p string) bool {
 	return false
 }
 
-// GRPCServerStats gRPC OTel stats handlers (preferred over deprecated interceptors)
-func GRPCServerStats() grpc.ServerOption { return grpc.StatsHandler(otelgrpcpkg.NewServerHandler()) }
-func GRPCClientStats() grpc.DialOption   { return grpc.WithStatsHandler(otelgrpcpkg.NewClientHandler()) }
+// GRPCServerStats gRPC OTel stats handlers (preferred over deprecated interceptors).
+// The attributes are added to every metric the server records.
+func GRPCServerStats(attrs ...attribute.KeyValue) grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpcpkg.NewServerHandler(otelgrpcpkg.WithMetricAttributes(attrs...)))
+}
+
+// GRPCServerStatsByMethod is GRPCServerStats with the attributes chosen for
+// each call from its full method name, e.g. to label the calls of the services
+// sharing a server. Calls resolving to the same attributes share a handler.
+func GRPCServerStatsByMethod(attrs func(fullMethod string) []attribute.KeyValue) grpc.ServerOption {
+	return grpc.StatsHandler(&methodStatsHandler{
+		attrs:    attrs,
+		handlers: make(map[attribute.Distinct]stats.Handler),
+	})
+}
+
+type methodStatsKey struct{}
+
+// methodStatsHandler forwards the stats of each call to an otelgrpc handler
+// recording the attributes of its method.
+type methodStatsHandler struct {
+	attrs func(fullMethod string) []attribute.KeyValue
+
+	mu       sync.Mutex
+	handlers map[attribute.Distinct]stats.Handler
+}
+
+func (h *methodStatsHandler) handler(fullMethod string) stats.Handler {
+	attrs := h.attrs(fullMethod)
+	set := attribute.NewSet(attrs...)
+	key := set.Equivalent()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	handler, ok := h.handlers[key]
+	if !ok {
+		handler = otelgrpcpkg.NewServerHandler(otelgrpcpkg.WithMetricAttributes(attrs...))
+		h.handlers[key] = handler
+	}
+	return handler
+}
+
+func (h *methodStatsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	handler := h.handler(info.FullMethodName)
+	return handler.TagRPC(context.WithValue(ctx, methodStatsKey{}, handler), info)
+}
+
+func (h *methodStatsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	if handler, ok := ctx.Value(methodStatsKey{}).(stats.Handler); ok {
+		handler.HandleRPC(ctx, s)
+	}
+}
+
+func (h *methodStatsHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *methodStatsHandler) HandleConn(context.Context, stats.ConnStats) {}
+
+func GRPCClientStats() grpc.DialOption { return grpc.WithStatsHandler(otelgrpcpkg.NewClientHandler()) }
 
 func GRPCRecoverUnary() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
 		defer func() {
 			if r := recover(); r != nil {
-				slog.Error("grpc panic", "method", info.FullMethod, "panic", r)
-				err = status.Error(codes.Internal, "internal")
+				err = grpcPanicError(ctx, info.FullMethod, r)
 			}
 		}()
 		return handler(ctx, req)
@@ -328,50 +568,138 @@ func GRPCRecoverStream() grpc.StreamServerInterceptor {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
 		defer func() {
 			if r := recover(); r != nil {
-				slog.Error("grpc panic", "method", info.FullMethod, "panic", r)
-				err = status.Error(codes.Internal, "internal")
+				err = grpcPanicError(ss.Context(), info.FullMethod, r)
 			}
 		}()
 		return handler(srv, ss)
 	}
 }
 
+// grpcPanicError logs a recovered panic and returns an Internal status that
+// carries the request and trace id, so clients can report the failure.
+func grpcPanicError(ctx context.Context, method string, r interface{}) error {
+	rid := RequestIDFromContext(ctx)
+	traceID := trace.SpanContextFromContext(ctx).TraceID().String()
+	slog.Error("grpc panic",
+		"method", method,
+		"panic", r,
+		"request_id", rid,
+		"trace_id", traceID,
+		"stack", string(debug.Stack()),
+	)
+
+	msg := fmt.Sprintf("internal error (request_id=%s, trace_id=%s)", rid, traceID)
+	if panicDetailsEnabled() {
+		msg += fmt.Sprintf(": %v", r)
+	}
+	return status.Error(codes.Internal, msg)
+}
+
+// HTTPRecovery recovers from panics in HTTP handlers and responds with a 500
+// that carries the request and trace id. The panic value is only included
+// when APP_ENV=local.
+func HTTPRecovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, r any) {
+		rid := c.GetString("request_id")
+		traceID := trace.SpanContextFromContext(c.Request.Context()).TraceID().String()
+		slog.Error("http panic",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"panic", r,
+			"request_id", rid,
+			"trace_id", traceID,
+			"stack", string(debug.Stack()),
+		)
+
+		body := gin.H{
+			"error":      "internal server error",
+			"request_id": rid,
+			"trace_id":   traceID,
+		}
+		if panicDetailsEnabled() {
+			body["panic"] = fmt.Sprint(r)
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, body)
+	})
+}
+
+// panicDetailsEnabled reports whether panic values may be returned to
+// clients, which is only allowed for local development.
+func panicDetailsEnabled() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv("APP_ENV")), "local")
+}
+
 func GRPCLoggingUnary() grpc.UnaryServerInterceptor {
+	return defaultRequestLogger.GRPCUnary()
+}
+func GRPCLoggingStream() grpc.StreamServerInterceptor {
+	return defaultRequestLogger.GRPCStream()
+}
+
+// GRPCUnary logs unary calls and, when enabled, their payloads.
+func (l *RequestLogger) GRPCUnary() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		start := time.Now()
 		resp, err := handler(ctx, req)
-		grpcLog(ctx, info.FullMethod, status.Code(err), start, err)
+		grpcLog(ctx, l.schema, info.FullMethod, status.Code(err), start, err)
+		grpcPayloadLog(ctx, l.schema, info.FullMethod, req, resp)
 		return resp, err
 	}
 }
-func GRPCLoggingStream() grpc.StreamServerInterceptor {
+
+// GRPCStream logs streaming calls.
+func (l *RequestLogger) GRPCStream() grpc.StreamServerInterceptor {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		start := time.Now()
 		err := handler(srv, ss)
-		grpcLog(context.Background(), info.FullMethod, status.Code(err), start, err)
+		grpcLog(ss.Context(), l.schema, info.FullMethod, status.Code(err), start, err)
 		return err
 	}
 }
 
-func grpcLog(ctx context.Context, method string, code codes.Code, start time.Time, err error) {
+func grpcLog(ctx context.Context, schema *logSchema, method string, code codes.Code, start time.Time, err error) {
 	// Level by status code
 	sc := trace.SpanContextFromContext(ctx)
-	logFn := slog.Info
+	level := slog.LevelInfo
 	if code == codes.Unknown || code == codes.DeadlineExceeded || code == codes.Unimplemented || code == codes.Internal || code == codes.Unavailable || code == codes.DataLoss {
-		logFn = slog.Error
+		level = slog.LevelError
 	} else if code != codes.OK {
-		logFn = slog.Warn
+		level = slog.LevelWarn
 	}
-	logFn(
-
+	args := schema.logFields(
+		"protocol", "grpc",
 		"trace_id", sc.TraceID().String(),
 		"span_id", sc.SpanID().String(),
-		"grpc",
+		"request_id", RequestIDFromContext(ctx),
 		"method", method,
 		"code", code.String(),
 		"duration_ms", time.Since(start).Milliseconds(),
 		"error", errString(err),
 	)
+	if headers := schema.grpcHeaders(ctx); headers != nil {
+		args = append(args, schema.logFields("headers", headers)...)
+	}
+	slog.Log(ctx, level, schema.grpcMsg, args...)
+}
+
+// grpcPayloadLog writes the request and response of a unary call at debug
+// level when LogConfig.GRPCPayloads is set.
+func grpcPayloadLog(ctx context.Context, schema *logSchema, method string, req, resp any) {
+	if !schema.payloads || !slog.Default().Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	args := schema.logFields(
+		"protocol", "grpc",
+		"request_id", RequestIDFromContext(ctx),
+		"method", method,
+	)
+	if payload, ok := schema.payload(req); ok {
+		args = append(args, schema.logFields("request", payload)...)
+	}
+	if payload, ok := schema.payload(resp); ok {
+		args = append(args, schema.logFields("response", payload)...)
+	}
+	slog.DebugContext(ctx, DefaultGRPCPayloadMessage, args...)
 }
 
 func randomID() string {
@@ -0,0 +1,150 @@
+package obs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type recordingErrorHandler struct {
+	errs []error
+}
+
+func (h *recordingErrorHandler) Handle(err error) {
+	h.errs = append(h.errs, err)
+}
+
+func TestExportWarner(t *testing.T) {
+	w := exportWarner{interval: time.Minute}
+	start := time.Now()
+
+	suppressed, ok := w.allow(start)
+	assert.True(t, ok)
+	assert.Zero(t, suppressed)
+
+	for i := 1; i <= 3; i++ {
+		_, ok = w.allow(start.Add(time.Duration(i) * time.Second))
+		assert.False(t, ok)
+	}
+
+	suppressed, ok = w.allow(start.Add(time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, 3, suppressed)
+}
+
+func TestInstallExportErrorHandler(t *testing.T) {
+	previous := otel.GetErrorHandler()
+	t.Cleanup(func() { otel.SetErrorHandler(previous) })
+
+	recorder := &recordingErrorHandler{}
+	otel.SetErrorHandler(recorder)
+
+	installExportErrorHandler("localhost:4317")
+	// Installing again must not wrap the handler twice.
+	installExportErrorHandler("localhost:4317")
+
+	first, second := errors.New("export failed"), errors.New("export failed again")
+	otel.Handle(first)
+	otel.Handle(second)
+
+	assert.Equal(t, []error{first, second}, recorder.errs, "every error reaches the previous handler")
+}
+
+func TestHTTPRecovery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("request_id", "req-1") }, HTTPRecovery())
+	router.GET("/panic", func(*gin.Context) { panic("boom") })
+
+	serve := func() map[string]any {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return body
+	}
+
+	t.Run("should hide the panic outside local development", func(t *testing.T) {
+		t.Setenv("APP_ENV", "production")
+
+		body := serve()
+		assert.Equal(t, "internal server error", body["error"])
+		assert.Equal(t, "req-1", body["request_id"])
+		assert.Contains(t, body, "trace_id")
+		assert.NotContains(t, body, "panic")
+	})
+
+	t.Run("should include the panic locally", func(t *testing.T) {
+		t.Setenv("APP_ENV", "local")
+
+		body := serve()
+		assert.Equal(t, "boom", body["panic"])
+	})
+}
+
+func TestGRPCRecover(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "req-1"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Panic"}
+	call := func() error {
+		_, err := GRPCRecoverUnary()(ctx, nil, info, func(context.Context, interface{}) (interface{}, error) {
+			panic("boom")
+		})
+		return err
+	}
+
+	t.Run("should hide the panic outside local development", func(t *testing.T) {
+		t.Setenv("APP_ENV", "")
+
+		st, ok := status.FromError(call())
+		require.True(t, ok)
+		assert.Equal(t, codes.Internal, st.Code())
+		assert.Contains(t, st.Message(), "request_id=req-1")
+		assert.NotContains(t, st.Message(), "boom")
+	})
+
+	t.Run("should include the panic locally", func(t *testing.T) {
+		t.Setenv("APP_ENV", "local")
+
+		st, ok := status.FromError(call())
+		require.True(t, ok)
+		assert.Equal(t, codes.Internal, st.Code())
+		assert.Contains(t, st.Message(), ": boom")
+	})
+}
+
+func TestMetricsHandlerExemplars(t *testing.T) {
+	o, err := Init(context.Background(), Config{ServiceName: "exemplars"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = o.Shutdown(context.Background()) })
+
+	hist, err := otel.Meter("test").Float64Histogram("exemplar_latency")
+	require.NoError(t, err)
+	ctx, span := otel.Tracer("test").Start(context.Background(), "op")
+	require.True(t, span.SpanContext().IsSampled())
+	hist.Record(ctx, 5)
+	span.End()
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	rec := httptest.NewRecorder()
+	o.MetricsHandler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "application/openmetrics-text")
+	assert.Contains(t, rec.Body.String(), `trace_id="`+span.SpanContext().TraceID().String()+`"`)
+}
@@ -0,0 +1,128 @@
+package obs
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// DefaultRequestIDHeader is the header request ids are read from and written
+// to unless NewRequestIDs is given another one.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// RequestIDFormat selects how generated request ids look.
+type RequestIDFormat int
+
+const (
+	// RequestIDHex generates 16 hex characters.
+	RequestIDHex RequestIDFormat = iota
+	// RequestIDUUID generates random (v4) UUIDs.
+	RequestIDUUID
+)
+
+// RequestIDs reads, generates and forwards request ids with one header and
+// format. Each app builds its own, so apps in the same process do not share
+// the configuration.
+type RequestIDs struct {
+	header string
+	format RequestIDFormat
+}
+
+// NewRequestIDs returns request ids carried in header (DefaultRequestIDHeader
+// when empty) and generated in format.
+func NewRequestIDs(header string, format RequestIDFormat) *RequestIDs {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+	return &RequestIDs{header: header, format: format}
+}
+
+var defaultRequestIDs = NewRequestIDs(DefaultRequestIDHeader, RequestIDHex)
+
+// Header returns the header that carries request ids.
+func (r *RequestIDs) Header() string {
+	return r.header
+}
+
+func (r *RequestIDs) newID() string {
+	if r.format == RequestIDUUID {
+		return uuid.NewString()
+	}
+	return randomID()
+}
+
+// Middleware adds/propagates the request id header. An inbound id is kept as
+// is; a generated one is returned to the client and forwarded to gRPC through
+// the gateway.
+func (r *RequestIDs) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rid := c.GetHeader(r.header)
+		if rid == "" {
+			rid = r.newID()
+			c.Writer.Header().Set(r.header, rid)
+			c.Request.Header.Set(r.header, rid)
+		}
+		c.Set("request_id", rid)
+		c.Next()
+	}
+}
+
+// GRPCUnary makes the request id sent as metadata under the lowercase header
+// name available to RequestIDFromContext.
+func (r *RequestIDs) GRPCUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(r.withRequestID(ctx), req)
+	}
+}
+
+// GRPCStream is the streaming counterpart of GRPCUnary.
+func (r *RequestIDs) GRPCStream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: r.withRequestID(ss.Context())})
+	}
+}
+
+func (r *RequestIDs) withRequestID(ctx context.Context) context.Context {
+	if rid := r.fromMetadata(ctx); rid != "" {
+		return context.WithValue(ctx, requestIDKey{}, rid)
+	}
+	return ctx
+}
+
+func (r *RequestIDs) fromMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if v := md.Get(strings.ToLower(r.header)); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+type requestIDKey struct{}
+
+// contextStream overrides the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// RequestIDFromContext returns the request id the gateway forwarded to a
+// gRPC handler, or "" outside of one. Servers without the RequestIDs
+// interceptors are read under DefaultRequestIDHeader.
+func RequestIDFromContext(ctx context.Context) string {
+	if rid, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return rid
+	}
+	return defaultRequestIDs.fromMetadata(ctx)
+}
@@ -2,10 +2,17 @@ package tonica
 
 import (
 	"log"
+	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/tonica-go/tonica/pkg/tonica/config"
+	"github.com/tonica-go/tonica/pkg/tonica/grpc/serviceconfig"
+	"github.com/tonica-go/tonica/pkg/tonica/modules/workflows"
+	obs "github.com/tonica-go/tonica/pkg/tonica/observabillity"
 	"github.com/tonica-go/tonica/pkg/tonica/registry"
+	"github.com/tonica-go/tonica/pkg/tonica/storage/blob"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 )
 
 type AppOption func(*App)
@@ -46,12 +53,39 @@ func WithSpecUrl(spec string) AppOption {
 	}
 }
 
+// WithSecurityScheme defines the security scheme name in the served OpenAPI
+// spec, so security requirements such as RouteBuilder.Security(name) resolve
+// and the docs page can authorize requests. A scheme of the same name in the
+// spec file is replaced.
+// Example:
+//
+//	WithSecurityScheme("bearer", BearerSecurityScheme("JWT"))
+//	WithSecurityScheme("apiKey", APIKeySecurityScheme("X-Api-Key"))
+func WithSecurityScheme(name string, scheme SecurityScheme) AppOption {
+	return func(a *App) {
+		if a.securitySchemes == nil {
+			a.securitySchemes = make(map[string]SecurityScheme)
+		}
+		a.securitySchemes[name] = scheme
+	}
+}
+
 func WithAPIPrefix(prefix string) AppOption {
 	return func(a *App) {
 		a.apiPrefix = prefix
 	}
 }
 
+// WithPublicURL sets the externally visible base URL of the API (e.g.
+// "https://api.example.com/billing"). It is written into the served OpenAPI
+// spec so the docs page sends requests to the right place behind proxies.
+// Defaults to the APP_PUBLIC_URL env var.
+func WithPublicURL(publicURL string) AppOption {
+	return func(a *App) {
+		a.publicURL = publicURL
+	}
+}
+
 func WithWorkflowService(namespace string) AppOption {
 	return func(a *App) {
 		a.isWorkflowService = true
@@ -59,6 +93,14 @@ func WithWorkflowService(namespace string) AppOption {
 	}
 }
 
+// WithWorkflowInputSchemas validates workflow triggers against schemas. Pass
+// the same set to workflows.WithValidatedWorkflow when registering workers.
+func WithWorkflowInputSchemas(schemas *workflows.InputSchemas) AppOption {
+	return func(a *App) {
+		a.workflowInputSchemas = schemas
+	}
+}
+
 func WithEntityService(definitionsPath, dbDriver, dsn string) AppOption {
 	return func(a *App) {
 		a.isEntityService = true
@@ -75,6 +117,19 @@ func WithGatewayProtoMessages() AppOption {
 	}
 }
 
+// WithGatewayMount serves the gateway routes of mount.Services below
+// mount.Prefix instead of at the root, so several API versions can be live at
+// once.
+// Example:
+//
+//	WithGatewayMount(GatewayMount{Prefix: "/v1", Services: []string{"orders-v1"}})
+//	WithGatewayMount(GatewayMount{Prefix: "/v2", Services: []string{"orders-v2"}, Spec: "openapi/v2.json"})
+func WithGatewayMount(mount GatewayMount) AppOption {
+	return func(a *App) {
+		a.gatewayMounts = append(a.gatewayMounts, mount)
+	}
+}
+
 // WithRouteMiddleware adds middleware for specific route patterns
 // Example:
 //
@@ -89,6 +144,109 @@ func WithRouteMiddleware(pathPrefixes []string, middlewares ...gin.HandlerFunc)
 	}
 }
 
+// WithMethodMiddleware guards gateway calls to the given gRPC methods,
+// whatever HTTP path they are mapped to. Methods are full names
+// ("/pkg.UserService/DeleteUser") or a whole service ("/pkg.UserService/*").
+// Example:
+//
+//	WithMethodMiddleware([]string{"/pkg.UserService/DeleteUser"}, requireAdmin)
+func WithMethodMiddleware(methods []string, middlewares ...MethodMiddleware) AppOption {
+	return func(a *App) {
+		a.methodMiddlewares = append(a.methodMiddlewares, methodMiddlewareRule{
+			methods:     methods,
+			middlewares: middlewares,
+		})
+	}
+}
+
+// WithGatewayLimits sets the body size and timeout of gateway calls without
+// a WithMethodLimits rule of their own. No limits apply by default.
+func WithGatewayLimits(limits GatewayLimits) AppOption {
+	return func(a *App) {
+		a.defaultGatewayLimits = limits
+	}
+}
+
+// WithMethodLimits sets the body size and timeout of gateway calls to the
+// given gRPC methods, named as in WithMethodMiddleware. Unset fields fall back
+// to WithGatewayLimits; the last matching rule wins.
+// Example:
+//
+//	WithMethodLimits([]string{"/files.v1.FileService/Upload"}, GatewayLimits{MaxBodyBytes: 100 << 20, Timeout: 5 * time.Minute})
+func WithMethodLimits(methods []string, limits GatewayLimits) AppOption {
+	return func(a *App) {
+		a.methodLimits = append(a.methodLimits, methodLimitsRule{methods: methods, limits: limits})
+	}
+}
+
+// WithHTTPServer adjusts the API http.Server before it listens, e.g. its
+// timeouts, MaxHeaderBytes or TLSConfig. The server is served over TLS when
+// its TLSConfig has certificates.
+func WithHTTPServer(fn func(*http.Server)) AppOption {
+	return func(a *App) {
+		a.httpServerHooks = append(a.httpServerHooks, fn)
+	}
+}
+
+// WithGatewayTLS makes the gateway dial gRPC services over TLS, verifying
+// them against caFile (the system roots when empty). certFile and keyFile
+// are the client certificate presented to services requiring mTLS and may
+// be empty. Unreadable files stop the app at startup.
+func WithGatewayTLS(caFile, certFile, keyFile string) AppOption {
+	return func(a *App) {
+		a.gatewayTLS = &serviceconfig.TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile}
+	}
+}
+
+// WithHTTPAddr sets the listen address of the API server. Port 0 picks a free
+// port, reported by App.HTTPAddr. Defaults to the APP_HTTP_ADDR env var or
+// ":8080".
+func WithHTTPAddr(addr string) AppOption {
+	return func(a *App) {
+		a.httpAddr = addr
+	}
+}
+
+// WithMetricsAddr sets the listen address of the metrics server, see
+// WithHTTPAddr. Defaults to the APP_METRIC_ADDR env var or ":2121".
+func WithMetricsAddr(addr string) AppOption {
+	return func(a *App) {
+		a.metricsAddr = addr
+	}
+}
+
+// WithGRPCAddr sets the listen address of the shared gRPC server. It serves
+// App.RegisterRawGRPC registrations and every service without a gRPC address
+// of its own (or with this same address). Defaults to DefaultGRPCAddr.
+func WithGRPCAddr(addr string) AppOption {
+	return func(a *App) {
+		a.grpcAddr = addr
+	}
+}
+
+// WithDefaultGRPCTimeout cancels unary gRPC calls that arrive without a
+// deadline once d has passed, so a slow dependency cannot keep a handler
+// running forever. Calls with a deadline of their own are left alone, and
+// streams are never cut off. Off by default, recommended in production. Calls
+// hitting it are counted in app_grpc_default_timeout_count.
+func WithDefaultGRPCTimeout(d time.Duration) AppOption {
+	return func(a *App) {
+		a.grpcTimeout = d
+	}
+}
+
+// WithGRPCReflection registers the gRPC reflection service on every gRPC
+// server of the app, so tools like grpcurl can list and call its services.
+// Defaults to the debug mode of the config. Pass the flag per run mode to keep
+// it off in production, e.g.
+//
+//	WithGRPCReflection(cfg.GetRunMode() == config.ModeAIO)
+func WithGRPCReflection(enabled bool) AppOption {
+	return func(a *App) {
+		a.grpcReflection = &enabled
+	}
+}
+
 func WithCustomGrpcHeaders(headers []string) AppOption {
 	return func(a *App) {
 		for _, v := range headers {
@@ -96,3 +254,140 @@ func WithCustomGrpcHeaders(headers []string) AppOption {
 		}
 	}
 }
+
+// WithMetricsExporter adds push based metric exporters (e.g. otlpmetricgrpc)
+// next to the default Prometheus exporter.
+func WithMetricsExporter(exporters ...sdkmetric.Exporter) AppOption {
+	return func(a *App) {
+		a.metricExporters = append(a.metricExporters, exporters...)
+	}
+}
+
+// WithoutPrometheus disables the Prometheus exporter and the /metrics endpoint.
+// Use it together with WithMetricsExporter.
+func WithoutPrometheus() AppOption {
+	return func(a *App) {
+		a.disablePrometheus = true
+	}
+}
+
+// WithMetricsOnAPI serves the metrics and health endpoints, and the consumer
+// admin endpoints of WithConsumerAdmin, on the API listener below prefix
+// instead of on their own port. An empty prefix mounts them at the root.
+// guards run before these endpoints, e.g. to restrict them to internal
+// callers. Only the aio and gateway modes serve the API; the other modes keep
+// the separate metrics port.
+// Example:
+//
+//	WithMetricsOnAPI("/internal", allowPrivateNetworks)
+func WithMetricsOnAPI(prefix string, guards ...gin.HandlerFunc) AppOption {
+	return func(a *App) {
+		a.metricsOnAPI = true
+		a.metricsPrefix = prefix
+		a.metricsGuards = append(a.metricsGuards, guards...)
+	}
+}
+
+// WithConsumerAdmin serves POST /admin/consumers/:name/pause and
+// /admin/consumers/:name/resume next to /metrics. They change the state of
+// running consumers, so they are off by default and guard, which must
+// authenticate the caller, runs before them with the other guards.
+// Example:
+//
+//	WithConsumerAdmin(requireAdminToken)
+func WithConsumerAdmin(guard gin.HandlerFunc, guards ...gin.HandlerFunc) AppOption {
+	return func(a *App) {
+		if guard == nil {
+			panic("tonica: WithConsumerAdmin requires a guard")
+		}
+		a.consumerAdminGuards = append(append(a.consumerAdminGuards, guard), guards...)
+	}
+}
+
+// WithRequestValidation validates gateway requests against the OpenAPI spec
+// before they are proxied to gRPC. Without prefixes every gateway route is
+// validated; otherwise only paths starting with one of the prefixes.
+// Custom routes opt in with RouteBuilder.ValidateRequest.
+func WithRequestValidation(pathPrefixes ...string) AppOption {
+	return func(a *App) {
+		a.validateGateway = true
+		a.validationPrefixes = append(a.validationPrefixes, pathPrefixes...)
+	}
+}
+
+// WithAutoGateway registers the generated gateway handler for services that
+// have HTTP annotations but were registered without service.WithGateway.
+// Handlers are recorded by the init functions of `tonica wrap` output. The
+// proto services are taken from the gRPC servers of the app, so it only works
+// in aio mode; Validate rejects it in gateway mode.
+func WithAutoGateway() AppOption {
+	return func(a *App) {
+		a.autoGateway = true
+	}
+}
+
+// WithServiceMetricLabels adds a "service" attribute to framework metrics so
+// co-hosted services can be told apart: HTTP request metrics of gateway calls
+// carry the service that owns the called RPC, and gRPC server metrics,
+// including those of the shared server, carry it too.
+func WithServiceMetricLabels() AppOption {
+	return func(a *App) {
+		a.serviceMetricLabels = true
+	}
+}
+
+// WithUploadStore sets the store SaveFormFile streams uploads to.
+func WithUploadStore(store UploadStore) AppOption {
+	return func(a *App) {
+		a.uploadStore = store
+	}
+}
+
+// WithBlobStore sets the app's blob store. It also receives SaveFormFile
+// uploads unless WithUploadStore sets a different store.
+func WithBlobStore(store blob.Store) AppOption {
+	return func(a *App) {
+		a.blobStore = store
+		if a.uploadStore == nil {
+			a.uploadStore = store
+		}
+	}
+}
+
+// RequestIDFormat selects how generated request ids look.
+type RequestIDFormat = obs.RequestIDFormat
+
+// Request id formats for WithRequestIDFormat.
+const (
+	RequestIDHex  = obs.RequestIDHex
+	RequestIDUUID = obs.RequestIDUUID
+)
+
+// WithRequestIDHeader sets the header request ids are read from, returned in
+// and forwarded to gRPC with (lowercased as metadata). Defaults to
+// X-Request-ID.
+func WithRequestIDHeader(name string) AppOption {
+	return func(a *App) {
+		a.requestIDHeader = name
+	}
+}
+
+// WithRequestIDFormat sets the format of generated request ids. Inbound ids
+// are kept regardless of format. Defaults to RequestIDHex.
+func WithRequestIDFormat(format RequestIDFormat) AppOption {
+	return func(a *App) {
+		a.requestIDFormat = format
+	}
+}
+
+// LogConfig controls the field names, logged headers and payloads and the
+// redaction of request log lines.
+type LogConfig = obs.LogConfig
+
+// WithLogConfig configures the HTTP and gRPC request log lines. Authorization,
+// Proxy-Authorization, Cookie and X-Api-Key are always redacted.
+func WithLogConfig(cfg LogConfig) AppOption {
+	return func(a *App) {
+		a.logConfig = cfg
+	}
+}
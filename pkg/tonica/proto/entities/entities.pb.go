@@ -104,20 +104,28 @@ const (
 	FilterOperator_FILTER_OPERATOR_GTE         FilterOperator = 6
 	FilterOperator_FILTER_OPERATOR_LT          FilterOperator = 7
 	FilterOperator_FILTER_OPERATOR_LTE         FilterOperator = 8
+	// Inclusive range; the value holds the lower and upper bound.
+	FilterOperator_FILTER_OPERATOR_BETWEEN FilterOperator = 9
+	// Match fields that are missing, null or empty; the value is ignored.
+	FilterOperator_FILTER_OPERATOR_IS_NULL     FilterOperator = 10
+	FilterOperator_FILTER_OPERATOR_IS_NOT_NULL FilterOperator = 11
 )
 
 // Enum value maps for FilterOperator.
 var (
 	FilterOperator_name = map[int32]string{
-		0: "FILTER_OPERATOR_UNSPECIFIED",
-		1: "FILTER_OPERATOR_EQ",
-		2: "FILTER_OPERATOR_NE",
-		3: "FILTER_OPERATOR_CONTAINS",
-		4: "FILTER_OPERATOR_IN",
-		5: "FILTER_OPERATOR_GT",
-		6: "FILTER_OPERATOR_GTE",
-		7: "FILTER_OPERATOR_LT",
-		8: "FILTER_OPERATOR_LTE",
+		0:  "FILTER_OPERATOR_UNSPECIFIED",
+		1:  "FILTER_OPERATOR_EQ",
+		2:  "FILTER_OPERATOR_NE",
+		3:  "FILTER_OPERATOR_CONTAINS",
+		4:  "FILTER_OPERATOR_IN",
+		5:  "FILTER_OPERATOR_GT",
+		6:  "FILTER_OPERATOR_GTE",
+		7:  "FILTER_OPERATOR_LT",
+		8:  "FILTER_OPERATOR_LTE",
+		9:  "FILTER_OPERATOR_BETWEEN",
+		10: "FILTER_OPERATOR_IS_NULL",
+		11: "FILTER_OPERATOR_IS_NOT_NULL",
 	}
 	FilterOperator_value = map[string]int32{
 		"FILTER_OPERATOR_UNSPECIFIED": 0,
@@ -129,6 +137,9 @@ var (
 		"FILTER_OPERATOR_GTE":         6,
 		"FILTER_OPERATOR_LT":          7,
 		"FILTER_OPERATOR_LTE":         8,
+		"FILTER_OPERATOR_BETWEEN":     9,
+		"FILTER_OPERATOR_IS_NULL":     10,
+		"FILTER_OPERATOR_IS_NOT_NULL": 11,
 	}
 )
 
@@ -208,6 +219,121 @@ func (SortDirection) EnumDescriptor() ([]byte, []int) {
 	return file_entities_entities_proto_rawDescGZIP(), []int{2}
 }
 
+type PivotAggregation int32
+
+const (
+	PivotAggregation_PIVOT_AGGREGATION_UNSPECIFIED PivotAggregation = 0
+	PivotAggregation_PIVOT_AGGREGATION_COUNT       PivotAggregation = 1
+	PivotAggregation_PIVOT_AGGREGATION_SUM         PivotAggregation = 2
+	PivotAggregation_PIVOT_AGGREGATION_AVG         PivotAggregation = 3
+	PivotAggregation_PIVOT_AGGREGATION_MIN         PivotAggregation = 4
+	PivotAggregation_PIVOT_AGGREGATION_MAX         PivotAggregation = 5
+)
+
+// Enum value maps for PivotAggregation.
+var (
+	PivotAggregation_name = map[int32]string{
+		0: "PIVOT_AGGREGATION_UNSPECIFIED",
+		1: "PIVOT_AGGREGATION_COUNT",
+		2: "PIVOT_AGGREGATION_SUM",
+		3: "PIVOT_AGGREGATION_AVG",
+		4: "PIVOT_AGGREGATION_MIN",
+		5: "PIVOT_AGGREGATION_MAX",
+	}
+	PivotAggregation_value = map[string]int32{
+		"PIVOT_AGGREGATION_UNSPECIFIED": 0,
+		"PIVOT_AGGREGATION_COUNT":       1,
+		"PIVOT_AGGREGATION_SUM":         2,
+		"PIVOT_AGGREGATION_AVG":         3,
+		"PIVOT_AGGREGATION_MIN":         4,
+		"PIVOT_AGGREGATION_MAX":         5,
+	}
+)
+
+func (x PivotAggregation) Enum() *PivotAggregation {
+	p := new(PivotAggregation)
+	*p = x
+	return p
+}
+
+func (x PivotAggregation) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PivotAggregation) Descriptor() protoreflect.EnumDescriptor {
+	return file_entities_entities_proto_enumTypes[3].Descriptor()
+}
+
+func (PivotAggregation) Type() protoreflect.EnumType {
+	return &file_entities_entities_proto_enumTypes[3]
+}
+
+func (x PivotAggregation) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PivotAggregation.Descriptor instead.
+func (PivotAggregation) EnumDescriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{3}
+}
+
+// Width of the time buckets returned by RecordStats.
+type StatsInterval int32
+
+const (
+	StatsInterval_STATS_INTERVAL_UNSPECIFIED StatsInterval = 0
+	StatsInterval_STATS_INTERVAL_HOUR        StatsInterval = 1
+	StatsInterval_STATS_INTERVAL_DAY         StatsInterval = 2
+	// Weeks start on Monday.
+	StatsInterval_STATS_INTERVAL_WEEK  StatsInterval = 3
+	StatsInterval_STATS_INTERVAL_MONTH StatsInterval = 4
+)
+
+// Enum value maps for StatsInterval.
+var (
+	StatsInterval_name = map[int32]string{
+		0: "STATS_INTERVAL_UNSPECIFIED",
+		1: "STATS_INTERVAL_HOUR",
+		2: "STATS_INTERVAL_DAY",
+		3: "STATS_INTERVAL_WEEK",
+		4: "STATS_INTERVAL_MONTH",
+	}
+	StatsInterval_value = map[string]int32{
+		"STATS_INTERVAL_UNSPECIFIED": 0,
+		"STATS_INTERVAL_HOUR":        1,
+		"STATS_INTERVAL_DAY":         2,
+		"STATS_INTERVAL_WEEK":        3,
+		"STATS_INTERVAL_MONTH":       4,
+	}
+)
+
+func (x StatsInterval) Enum() *StatsInterval {
+	p := new(StatsInterval)
+	*p = x
+	return p
+}
+
+func (x StatsInterval) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StatsInterval) Descriptor() protoreflect.EnumDescriptor {
+	return file_entities_entities_proto_enumTypes[4].Descriptor()
+}
+
+func (StatsInterval) Type() protoreflect.EnumType {
+	return &file_entities_entities_proto_enumTypes[4]
+}
+
+func (x StatsInterval) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StatsInterval.Descriptor instead.
+func (StatsInterval) EnumDescriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{4}
+}
+
 type FilterDefinition struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Operators     []FilterOperator       `protobuf:"varint,1,rep,packed,name=operators,proto3,enum=entities.v1.FilterOperator" json:"operators,omitempty"`
@@ -601,11 +727,14 @@ func (x *RecordMetadata) GetUpdatedBy() string {
 }
 
 type Record struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Entity        string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
-	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
-	Data          *structpb.Struct       `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
-	Metadata      *RecordMetadata        `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Entity   string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
+	Id       string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Data     *structpb.Struct       `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	Metadata *RecordMetadata        `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// Labels of referenced records keyed by reference field id. Only set when
+	// resolve_labels is requested.
+	Labels        map[string]string `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -668,6 +797,13 @@ func (x *Record) GetMetadata() *RecordMetadata {
 	return nil
 }
 
+func (x *Record) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
 type FilterExpression struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
@@ -728,6 +864,51 @@ func (x *FilterExpression) GetValue() *structpb.Value {
 	return nil
 }
 
+// FilterGroup matches when any of its filters matches.
+type FilterGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filters       []*FilterExpression    `protobuf:"bytes,1,rep,name=filters,proto3" json:"filters,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FilterGroup) Reset() {
+	*x = FilterGroup{}
+	mi := &file_entities_entities_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FilterGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilterGroup) ProtoMessage() {}
+
+func (x *FilterGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilterGroup.ProtoReflect.Descriptor instead.
+func (*FilterGroup) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *FilterGroup) GetFilters() []*FilterExpression {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
 type ListRecordsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Entity        string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
@@ -737,13 +918,23 @@ type ListRecordsRequest struct {
 	SortField     string                 `protobuf:"bytes,5,opt,name=sort_field,json=sortField,proto3" json:"sort_field,omitempty"`
 	SortDirection SortDirection          `protobuf:"varint,6,opt,name=sort_direction,json=sortDirection,proto3,enum=entities.v1.SortDirection" json:"sort_direction,omitempty"`
 	Search        string                 `protobuf:"bytes,7,opt,name=search,proto3" json:"search,omitempty"`
+	// Comma-separated field ids to return. The primary key is always included.
+	Fields string `protobuf:"bytes,8,opt,name=fields,proto3" json:"fields,omitempty"`
+	// Count all matching records and return the result as total_count.
+	IncludeTotalCount bool `protobuf:"varint,9,opt,name=include_total_count,json=includeTotalCount,proto3" json:"include_total_count,omitempty"`
+	// Resolve the labels of referenced records into Record.labels.
+	ResolveLabels bool `protobuf:"varint,10,opt,name=resolve_labels,json=resolveLabels,proto3" json:"resolve_labels,omitempty"`
+	// Reference fields whose label is inlined into the data as <field>_label.
+	Expand []string `protobuf:"bytes,11,rep,name=expand,proto3" json:"expand,omitempty"`
+	// Records must match every filter and at least one filter of each group.
+	OrGroups      []*FilterGroup `protobuf:"bytes,12,rep,name=or_groups,json=orGroups,proto3" json:"or_groups,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ListRecordsRequest) Reset() {
 	*x = ListRecordsRequest{}
-	mi := &file_entities_entities_proto_msgTypes[8]
+	mi := &file_entities_entities_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -755,7 +946,7 @@ func (x *ListRecordsRequest) String() string {
 func (*ListRecordsRequest) ProtoMessage() {}
 
 func (x *ListRecordsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_entities_entities_proto_msgTypes[8]
+	mi := &file_entities_entities_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -768,7 +959,7 @@ func (x *ListRecordsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListRecordsRequest.ProtoReflect.Descriptor instead.
 func (*ListRecordsRequest) Descriptor() ([]byte, []int) {
-	return file_entities_entities_proto_rawDescGZIP(), []int{8}
+	return file_entities_entities_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *ListRecordsRequest) GetEntity() string {
@@ -820,17 +1011,55 @@ func (x *ListRecordsRequest) GetSearch() string {
 	return ""
 }
 
+func (x *ListRecordsRequest) GetFields() string {
+	if x != nil {
+		return x.Fields
+	}
+	return ""
+}
+
+func (x *ListRecordsRequest) GetIncludeTotalCount() bool {
+	if x != nil {
+		return x.IncludeTotalCount
+	}
+	return false
+}
+
+func (x *ListRecordsRequest) GetResolveLabels() bool {
+	if x != nil {
+		return x.ResolveLabels
+	}
+	return false
+}
+
+func (x *ListRecordsRequest) GetExpand() []string {
+	if x != nil {
+		return x.Expand
+	}
+	return nil
+}
+
+func (x *ListRecordsRequest) GetOrGroups() []*FilterGroup {
+	if x != nil {
+		return x.OrGroups
+	}
+	return nil
+}
+
 type ListRecordsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Records       []*Record              `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
 	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	// Number of records matching the filters across all pages. Only set when
+	// include_total_count is requested and the entity can count its records.
+	TotalCount    *int64 `protobuf:"varint,3,opt,name=total_count,json=totalCount,proto3,oneof" json:"total_count,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ListRecordsResponse) Reset() {
 	*x = ListRecordsResponse{}
-	mi := &file_entities_entities_proto_msgTypes[9]
+	mi := &file_entities_entities_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -842,7 +1071,7 @@ func (x *ListRecordsResponse) String() string {
 func (*ListRecordsResponse) ProtoMessage() {}
 
 func (x *ListRecordsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_entities_entities_proto_msgTypes[9]
+	mi := &file_entities_entities_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -855,7 +1084,7 @@ func (x *ListRecordsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListRecordsResponse.ProtoReflect.Descriptor instead.
 func (*ListRecordsResponse) Descriptor() ([]byte, []int) {
-	return file_entities_entities_proto_rawDescGZIP(), []int{9}
+	return file_entities_entities_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *ListRecordsResponse) GetRecords() []*Record {
@@ -872,17 +1101,28 @@ func (x *ListRecordsResponse) GetNextPageToken() string {
 	return ""
 }
 
+func (x *ListRecordsResponse) GetTotalCount() int64 {
+	if x != nil && x.TotalCount != nil {
+		return *x.TotalCount
+	}
+	return 0
+}
+
 type GetRecordRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Entity        string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
-	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Entity string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
+	Id     string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	// Comma-separated field ids to return. The primary key is always included.
+	Fields string `protobuf:"bytes,3,opt,name=fields,proto3" json:"fields,omitempty"`
+	// Reference fields whose label is inlined into the data as <field>_label.
+	Expand        []string `protobuf:"bytes,4,rep,name=expand,proto3" json:"expand,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetRecordRequest) Reset() {
 	*x = GetRecordRequest{}
-	mi := &file_entities_entities_proto_msgTypes[10]
+	mi := &file_entities_entities_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -894,7 +1134,7 @@ func (x *GetRecordRequest) String() string {
 func (*GetRecordRequest) ProtoMessage() {}
 
 func (x *GetRecordRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_entities_entities_proto_msgTypes[10]
+	mi := &file_entities_entities_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -907,7 +1147,7 @@ func (x *GetRecordRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetRecordRequest.ProtoReflect.Descriptor instead.
 func (*GetRecordRequest) Descriptor() ([]byte, []int) {
-	return file_entities_entities_proto_rawDescGZIP(), []int{10}
+	return file_entities_entities_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *GetRecordRequest) GetEntity() string {
@@ -924,6 +1164,20 @@ func (x *GetRecordRequest) GetId() string {
 	return ""
 }
 
+func (x *GetRecordRequest) GetFields() string {
+	if x != nil {
+		return x.Fields
+	}
+	return ""
+}
+
+func (x *GetRecordRequest) GetExpand() []string {
+	if x != nil {
+		return x.Expand
+	}
+	return nil
+}
+
 type CreateRecordRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Entity        string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
@@ -934,7 +1188,7 @@ type CreateRecordRequest struct {
 
 func (x *CreateRecordRequest) Reset() {
 	*x = CreateRecordRequest{}
-	mi := &file_entities_entities_proto_msgTypes[11]
+	mi := &file_entities_entities_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -946,7 +1200,7 @@ func (x *CreateRecordRequest) String() string {
 func (*CreateRecordRequest) ProtoMessage() {}
 
 func (x *CreateRecordRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_entities_entities_proto_msgTypes[11]
+	mi := &file_entities_entities_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -959,7 +1213,7 @@ func (x *CreateRecordRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateRecordRequest.ProtoReflect.Descriptor instead.
 func (*CreateRecordRequest) Descriptor() ([]byte, []int) {
-	return file_entities_entities_proto_rawDescGZIP(), []int{11}
+	return file_entities_entities_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *CreateRecordRequest) GetEntity() string {
@@ -976,30 +1230,32 @@ func (x *CreateRecordRequest) GetData() *structpb.Struct {
 	return nil
 }
 
-type UpdateRecordRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Entity        string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
-	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
-	Data          *structpb.Struct       `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+type BatchCreateRecordsRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Entity  string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
+	Records []*structpb.Struct     `protobuf:"bytes,2,rep,name=records,proto3" json:"records,omitempty"`
+	// Create every record or none. Otherwise records are created independently
+	// and failures are reported per item.
+	Atomic        bool `protobuf:"varint,3,opt,name=atomic,proto3" json:"atomic,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateRecordRequest) Reset() {
-	*x = UpdateRecordRequest{}
-	mi := &file_entities_entities_proto_msgTypes[12]
+func (x *BatchCreateRecordsRequest) Reset() {
+	*x = BatchCreateRecordsRequest{}
+	mi := &file_entities_entities_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateRecordRequest) String() string {
+func (x *BatchCreateRecordsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateRecordRequest) ProtoMessage() {}
+func (*BatchCreateRecordsRequest) ProtoMessage() {}
 
-func (x *UpdateRecordRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_entities_entities_proto_msgTypes[12]
+func (x *BatchCreateRecordsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1010,55 +1266,57 @@ func (x *UpdateRecordRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateRecordRequest.ProtoReflect.Descriptor instead.
-func (*UpdateRecordRequest) Descriptor() ([]byte, []int) {
-	return file_entities_entities_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use BatchCreateRecordsRequest.ProtoReflect.Descriptor instead.
+func (*BatchCreateRecordsRequest) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *UpdateRecordRequest) GetEntity() string {
+func (x *BatchCreateRecordsRequest) GetEntity() string {
 	if x != nil {
 		return x.Entity
 	}
 	return ""
 }
 
-func (x *UpdateRecordRequest) GetId() string {
+func (x *BatchCreateRecordsRequest) GetRecords() []*structpb.Struct {
 	if x != nil {
-		return x.Id
+		return x.Records
 	}
-	return ""
+	return nil
 }
 
-func (x *UpdateRecordRequest) GetData() *structpb.Struct {
+func (x *BatchCreateRecordsRequest) GetAtomic() bool {
 	if x != nil {
-		return x.Data
+		return x.Atomic
 	}
-	return nil
+	return false
 }
 
-type DeleteRecordRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Entity        string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
-	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+type BatchRecordResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Set when the item was written.
+	Record *Record `protobuf:"bytes,1,opt,name=record,proto3" json:"record,omitempty"`
+	// Set when the item failed.
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteRecordRequest) Reset() {
-	*x = DeleteRecordRequest{}
-	mi := &file_entities_entities_proto_msgTypes[13]
+func (x *BatchRecordResult) Reset() {
+	*x = BatchRecordResult{}
+	mi := &file_entities_entities_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteRecordRequest) String() string {
+func (x *BatchRecordResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteRecordRequest) ProtoMessage() {}
+func (*BatchRecordResult) ProtoMessage() {}
 
-func (x *DeleteRecordRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_entities_entities_proto_msgTypes[13]
+func (x *BatchRecordResult) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1069,50 +1327,48 @@ func (x *DeleteRecordRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteRecordRequest.ProtoReflect.Descriptor instead.
-func (*DeleteRecordRequest) Descriptor() ([]byte, []int) {
-	return file_entities_entities_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use BatchRecordResult.ProtoReflect.Descriptor instead.
+func (*BatchRecordResult) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *DeleteRecordRequest) GetEntity() string {
+func (x *BatchRecordResult) GetRecord() *Record {
 	if x != nil {
-		return x.Entity
+		return x.Record
 	}
-	return ""
+	return nil
 }
 
-func (x *DeleteRecordRequest) GetId() string {
+func (x *BatchRecordResult) GetError() string {
 	if x != nil {
-		return x.Id
+		return x.Error
 	}
 	return ""
 }
 
-type ListRecordHistoryRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Entity        string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
-	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
-	PageSize      int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
-	PageToken     string                 `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+type BatchCreateRecordsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One result per requested record, in request order.
+	Results       []*BatchRecordResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListRecordHistoryRequest) Reset() {
-	*x = ListRecordHistoryRequest{}
-	mi := &file_entities_entities_proto_msgTypes[14]
+func (x *BatchCreateRecordsResponse) Reset() {
+	*x = BatchCreateRecordsResponse{}
+	mi := &file_entities_entities_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListRecordHistoryRequest) String() string {
+func (x *BatchCreateRecordsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListRecordHistoryRequest) ProtoMessage() {}
+func (*BatchCreateRecordsResponse) ProtoMessage() {}
 
-func (x *ListRecordHistoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_entities_entities_proto_msgTypes[14]
+func (x *BatchCreateRecordsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1123,67 +1379,812 @@ func (x *ListRecordHistoryRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListRecordHistoryRequest.ProtoReflect.Descriptor instead.
-func (*ListRecordHistoryRequest) Descriptor() ([]byte, []int) {
-	return file_entities_entities_proto_rawDescGZIP(), []int{14}
-}
-
-func (x *ListRecordHistoryRequest) GetEntity() string {
-	if x != nil {
-		return x.Entity
-	}
-	return ""
+// Deprecated: Use BatchCreateRecordsResponse.ProtoReflect.Descriptor instead.
+func (*BatchCreateRecordsResponse) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *ListRecordHistoryRequest) GetId() string {
+func (x *BatchCreateRecordsResponse) GetResults() []*BatchRecordResult {
 	if x != nil {
-		return x.Id
+		return x.Results
 	}
-	return ""
+	return nil
 }
 
-func (x *ListRecordHistoryRequest) GetPageSize() int32 {
-	if x != nil {
-		return x.PageSize
-	}
-	return 0
+type UpdateRecordRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entity        string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Data          *structpb.Struct       `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListRecordHistoryRequest) GetPageToken() string {
-	if x != nil {
+func (x *UpdateRecordRequest) Reset() {
+	*x = UpdateRecordRequest{}
+	mi := &file_entities_entities_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateRecordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateRecordRequest) ProtoMessage() {}
+
+func (x *UpdateRecordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateRecordRequest.ProtoReflect.Descriptor instead.
+func (*UpdateRecordRequest) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *UpdateRecordRequest) GetEntity() string {
+	if x != nil {
+		return x.Entity
+	}
+	return ""
+}
+
+func (x *UpdateRecordRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateRecordRequest) GetData() *structpb.Struct {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type DeleteRecordRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entity        string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRecordRequest) Reset() {
+	*x = DeleteRecordRequest{}
+	mi := &file_entities_entities_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRecordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRecordRequest) ProtoMessage() {}
+
+func (x *DeleteRecordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRecordRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRecordRequest) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *DeleteRecordRequest) GetEntity() string {
+	if x != nil {
+		return x.Entity
+	}
+	return ""
+}
+
+func (x *DeleteRecordRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RestoreRecordRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entity        string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreRecordRequest) Reset() {
+	*x = RestoreRecordRequest{}
+	mi := &file_entities_entities_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreRecordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreRecordRequest) ProtoMessage() {}
+
+func (x *RestoreRecordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreRecordRequest.ProtoReflect.Descriptor instead.
+func (*RestoreRecordRequest) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RestoreRecordRequest) GetEntity() string {
+	if x != nil {
+		return x.Entity
+	}
+	return ""
+}
+
+func (x *RestoreRecordRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListRecordHistoryRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Entity    string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
+	Id        string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	PageSize  int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken string                 `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Oldest and newest version to return, both inclusive. Zero leaves the
+	// bound open.
+	SinceVersion  int64 `protobuf:"varint,5,opt,name=since_version,json=sinceVersion,proto3" json:"since_version,omitempty"`
+	UntilVersion  int64 `protobuf:"varint,6,opt,name=until_version,json=untilVersion,proto3" json:"until_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRecordHistoryRequest) Reset() {
+	*x = ListRecordHistoryRequest{}
+	mi := &file_entities_entities_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRecordHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRecordHistoryRequest) ProtoMessage() {}
+
+func (x *ListRecordHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRecordHistoryRequest.ProtoReflect.Descriptor instead.
+func (*ListRecordHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListRecordHistoryRequest) GetEntity() string {
+	if x != nil {
+		return x.Entity
+	}
+	return ""
+}
+
+func (x *ListRecordHistoryRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ListRecordHistoryRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListRecordHistoryRequest) GetPageToken() string {
+	if x != nil {
 		return x.PageToken
 	}
-	return ""
+	return ""
+}
+
+func (x *ListRecordHistoryRequest) GetSinceVersion() int64 {
+	if x != nil {
+		return x.SinceVersion
+	}
+	return 0
+}
+
+func (x *ListRecordHistoryRequest) GetUntilVersion() int64 {
+	if x != nil {
+		return x.UntilVersion
+	}
+	return 0
+}
+
+type RecordHistoryEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       int64                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	EventType     string                 `protobuf:"bytes,2,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	Actor         string                 `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor,omitempty"`
+	Data          *structpb.Struct       `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
+	RawPayload    string                 `protobuf:"bytes,6,opt,name=raw_payload,json=rawPayload,proto3" json:"raw_payload,omitempty"`
+	Deleted       bool                   `protobuf:"varint,7,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecordHistoryEntry) Reset() {
+	*x = RecordHistoryEntry{}
+	mi := &file_entities_entities_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordHistoryEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordHistoryEntry) ProtoMessage() {}
+
+func (x *RecordHistoryEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordHistoryEntry.ProtoReflect.Descriptor instead.
+func (*RecordHistoryEntry) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RecordHistoryEntry) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *RecordHistoryEntry) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *RecordHistoryEntry) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+func (x *RecordHistoryEntry) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *RecordHistoryEntry) GetData() *structpb.Struct {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *RecordHistoryEntry) GetRawPayload() string {
+	if x != nil {
+		return x.RawPayload
+	}
+	return ""
+}
+
+func (x *RecordHistoryEntry) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+type ListRecordHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	History       []*RecordHistoryEntry  `protobuf:"bytes,1,rep,name=history,proto3" json:"history,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRecordHistoryResponse) Reset() {
+	*x = ListRecordHistoryResponse{}
+	mi := &file_entities_entities_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRecordHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRecordHistoryResponse) ProtoMessage() {}
+
+func (x *ListRecordHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRecordHistoryResponse.ProtoReflect.Descriptor instead.
+func (*ListRecordHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListRecordHistoryResponse) GetHistory() []*RecordHistoryEntry {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+func (x *ListRecordHistoryResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type StreamEntityChangesRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Entity string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
+	// Feed position to start at. Resume with the position of the last received
+	// change plus one; zero replays every change.
+	FromPosition  int64 `protobuf:"varint,2,opt,name=from_position,json=fromPosition,proto3" json:"from_position,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamEntityChangesRequest) Reset() {
+	*x = StreamEntityChangesRequest{}
+	mi := &file_entities_entities_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamEntityChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEntityChangesRequest) ProtoMessage() {}
+
+func (x *StreamEntityChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamEntityChangesRequest.ProtoReflect.Descriptor instead.
+func (*StreamEntityChangesRequest) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *StreamEntityChangesRequest) GetEntity() string {
+	if x != nil {
+		return x.Entity
+	}
+	return ""
+}
+
+func (x *StreamEntityChangesRequest) GetFromPosition() int64 {
+	if x != nil {
+		return x.FromPosition
+	}
+	return 0
+}
+
+type EntityChange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Feed position of the change, increasing across the records of the entity.
+	Position int64  `protobuf:"varint,1,opt,name=position,proto3" json:"position,omitempty"`
+	Entity   string `protobuf:"bytes,2,opt,name=entity,proto3" json:"entity,omitempty"`
+	RecordId string `protobuf:"bytes,3,opt,name=record_id,json=recordId,proto3" json:"record_id,omitempty"`
+	// One of created, updated, deleted or restored.
+	ChangeType string                 `protobuf:"bytes,4,opt,name=change_type,json=changeType,proto3" json:"change_type,omitempty"`
+	Version    int64                  `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	OccurredAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	Actor      string                 `protobuf:"bytes,7,opt,name=actor,proto3" json:"actor,omitempty"`
+	// Record data before and after the change. Before is unset for created
+	// and restored records, after is unset for deleted records.
+	Before        *structpb.Struct `protobuf:"bytes,8,opt,name=before,proto3" json:"before,omitempty"`
+	After         *structpb.Struct `protobuf:"bytes,9,opt,name=after,proto3" json:"after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EntityChange) Reset() {
+	*x = EntityChange{}
+	mi := &file_entities_entities_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EntityChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntityChange) ProtoMessage() {}
+
+func (x *EntityChange) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntityChange.ProtoReflect.Descriptor instead.
+func (*EntityChange) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *EntityChange) GetPosition() int64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *EntityChange) GetEntity() string {
+	if x != nil {
+		return x.Entity
+	}
+	return ""
+}
+
+func (x *EntityChange) GetRecordId() string {
+	if x != nil {
+		return x.RecordId
+	}
+	return ""
+}
+
+func (x *EntityChange) GetChangeType() string {
+	if x != nil {
+		return x.ChangeType
+	}
+	return ""
+}
+
+func (x *EntityChange) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *EntityChange) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+func (x *EntityChange) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *EntityChange) GetBefore() *structpb.Struct {
+	if x != nil {
+		return x.Before
+	}
+	return nil
+}
+
+func (x *EntityChange) GetAfter() *structpb.Struct {
+	if x != nil {
+		return x.After
+	}
+	return nil
+}
+
+type PivotRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Entity      string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
+	RowField    string                 `protobuf:"bytes,2,opt,name=row_field,json=rowField,proto3" json:"row_field,omitempty"`
+	ColumnField string                 `protobuf:"bytes,3,opt,name=column_field,json=columnField,proto3" json:"column_field,omitempty"`
+	Filters     []*FilterExpression    `protobuf:"bytes,4,rep,name=filters,proto3" json:"filters,omitempty"`
+	// Numeric field aggregated per cell. Records are counted when empty.
+	ValueField string `protobuf:"bytes,5,opt,name=value_field,json=valueField,proto3" json:"value_field,omitempty"`
+	// Aggregate applied to value_field. Defaults to SUM when value_field is set
+	// and to COUNT otherwise.
+	Aggregation   PivotAggregation `protobuf:"varint,6,opt,name=aggregation,proto3,enum=entities.v1.PivotAggregation" json:"aggregation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PivotRequest) Reset() {
+	*x = PivotRequest{}
+	mi := &file_entities_entities_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PivotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PivotRequest) ProtoMessage() {}
+
+func (x *PivotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PivotRequest.ProtoReflect.Descriptor instead.
+func (*PivotRequest) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *PivotRequest) GetEntity() string {
+	if x != nil {
+		return x.Entity
+	}
+	return ""
+}
+
+func (x *PivotRequest) GetRowField() string {
+	if x != nil {
+		return x.RowField
+	}
+	return ""
+}
+
+func (x *PivotRequest) GetColumnField() string {
+	if x != nil {
+		return x.ColumnField
+	}
+	return ""
+}
+
+func (x *PivotRequest) GetFilters() []*FilterExpression {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *PivotRequest) GetValueField() string {
+	if x != nil {
+		return x.ValueField
+	}
+	return ""
+}
+
+func (x *PivotRequest) GetAggregation() PivotAggregation {
+	if x != nil {
+		return x.Aggregation
+	}
+	return PivotAggregation_PIVOT_AGGREGATION_UNSPECIFIED
+}
+
+type PivotEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RowKey        string                 `protobuf:"bytes,1,opt,name=row_key,json=rowKey,proto3" json:"row_key,omitempty"`
+	ColumnKey     string                 `protobuf:"bytes,2,opt,name=column_key,json=columnKey,proto3" json:"column_key,omitempty"`
+	Value         float64                `protobuf:"fixed64,3,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PivotEntry) Reset() {
+	*x = PivotEntry{}
+	mi := &file_entities_entities_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PivotEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PivotEntry) ProtoMessage() {}
+
+func (x *PivotEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PivotEntry.ProtoReflect.Descriptor instead.
+func (*PivotEntry) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *PivotEntry) GetRowKey() string {
+	if x != nil {
+		return x.RowKey
+	}
+	return ""
+}
+
+func (x *PivotEntry) GetColumnKey() string {
+	if x != nil {
+		return x.ColumnKey
+	}
+	return ""
+}
+
+func (x *PivotEntry) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+type PivotTotals struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Row           map[string]float64     `protobuf:"bytes,1,rep,name=row,proto3" json:"row,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	Column        map[string]float64     `protobuf:"bytes,2,rep,name=column,proto3" json:"column,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	Grand         float64                `protobuf:"fixed64,3,opt,name=grand,proto3" json:"grand,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PivotTotals) Reset() {
+	*x = PivotTotals{}
+	mi := &file_entities_entities_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PivotTotals) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PivotTotals) ProtoMessage() {}
+
+func (x *PivotTotals) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PivotTotals.ProtoReflect.Descriptor instead.
+func (*PivotTotals) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *PivotTotals) GetRow() map[string]float64 {
+	if x != nil {
+		return x.Row
+	}
+	return nil
+}
+
+func (x *PivotTotals) GetColumn() map[string]float64 {
+	if x != nil {
+		return x.Column
+	}
+	return nil
+}
+
+func (x *PivotTotals) GetGrand() float64 {
+	if x != nil {
+		return x.Grand
+	}
+	return 0
 }
 
-type RecordHistoryEntry struct {
+type PivotResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Version       int64                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
-	EventType     string                 `protobuf:"bytes,2,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
-	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
-	Actor         string                 `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor,omitempty"`
-	Data          *structpb.Struct       `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
-	RawPayload    string                 `protobuf:"bytes,6,opt,name=raw_payload,json=rawPayload,proto3" json:"raw_payload,omitempty"`
-	Deleted       bool                   `protobuf:"varint,7,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	RowField      string                 `protobuf:"bytes,1,opt,name=row_field,json=rowField,proto3" json:"row_field,omitempty"`
+	ColumnField   string                 `protobuf:"bytes,2,opt,name=column_field,json=columnField,proto3" json:"column_field,omitempty"`
+	Entries       []*PivotEntry          `protobuf:"bytes,3,rep,name=entries,proto3" json:"entries,omitempty"`
+	Totals        *PivotTotals           `protobuf:"bytes,4,opt,name=totals,proto3" json:"totals,omitempty"`
+	ValueField    string                 `protobuf:"bytes,5,opt,name=value_field,json=valueField,proto3" json:"value_field,omitempty"`
+	Aggregation   PivotAggregation       `protobuf:"varint,6,opt,name=aggregation,proto3,enum=entities.v1.PivotAggregation" json:"aggregation,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RecordHistoryEntry) Reset() {
-	*x = RecordHistoryEntry{}
-	mi := &file_entities_entities_proto_msgTypes[15]
+func (x *PivotResponse) Reset() {
+	*x = PivotResponse{}
+	mi := &file_entities_entities_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RecordHistoryEntry) String() string {
+func (x *PivotResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RecordHistoryEntry) ProtoMessage() {}
+func (*PivotResponse) ProtoMessage() {}
 
-func (x *RecordHistoryEntry) ProtoReflect() protoreflect.Message {
-	mi := &file_entities_entities_proto_msgTypes[15]
+func (x *PivotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1194,83 +2195,82 @@ func (x *RecordHistoryEntry) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RecordHistoryEntry.ProtoReflect.Descriptor instead.
-func (*RecordHistoryEntry) Descriptor() ([]byte, []int) {
-	return file_entities_entities_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use PivotResponse.ProtoReflect.Descriptor instead.
+func (*PivotResponse) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{27}
 }
 
-func (x *RecordHistoryEntry) GetVersion() int64 {
+func (x *PivotResponse) GetRowField() string {
 	if x != nil {
-		return x.Version
+		return x.RowField
 	}
-	return 0
+	return ""
 }
 
-func (x *RecordHistoryEntry) GetEventType() string {
+func (x *PivotResponse) GetColumnField() string {
 	if x != nil {
-		return x.EventType
+		return x.ColumnField
 	}
 	return ""
 }
 
-func (x *RecordHistoryEntry) GetOccurredAt() *timestamppb.Timestamp {
+func (x *PivotResponse) GetEntries() []*PivotEntry {
 	if x != nil {
-		return x.OccurredAt
+		return x.Entries
 	}
 	return nil
 }
 
-func (x *RecordHistoryEntry) GetActor() string {
-	if x != nil {
-		return x.Actor
-	}
-	return ""
-}
-
-func (x *RecordHistoryEntry) GetData() *structpb.Struct {
+func (x *PivotResponse) GetTotals() *PivotTotals {
 	if x != nil {
-		return x.Data
+		return x.Totals
 	}
 	return nil
 }
 
-func (x *RecordHistoryEntry) GetRawPayload() string {
+func (x *PivotResponse) GetValueField() string {
 	if x != nil {
-		return x.RawPayload
+		return x.ValueField
 	}
 	return ""
 }
 
-func (x *RecordHistoryEntry) GetDeleted() bool {
+func (x *PivotResponse) GetAggregation() PivotAggregation {
 	if x != nil {
-		return x.Deleted
+		return x.Aggregation
 	}
-	return false
+	return PivotAggregation_PIVOT_AGGREGATION_UNSPECIFIED
 }
 
-type ListRecordHistoryResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	History       []*RecordHistoryEntry  `protobuf:"bytes,1,rep,name=history,proto3" json:"history,omitempty"`
-	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+type StatsRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Entity  string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
+	Filters []*FilterExpression    `protobuf:"bytes,2,rep,name=filters,proto3" json:"filters,omitempty"`
+	// Counts records per value of this field when set.
+	GroupField string `protobuf:"bytes,3,opt,name=group_field,json=groupField,proto3" json:"group_field,omitempty"`
+	// Datetime field records are bucketed by. Defaults to the creation time.
+	TimeField string `protobuf:"bytes,4,opt,name=time_field,json=timeField,proto3" json:"time_field,omitempty"`
+	// Counts records per time bucket when set.
+	Interval      StatsInterval `protobuf:"varint,5,opt,name=interval,proto3,enum=entities.v1.StatsInterval" json:"interval,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListRecordHistoryResponse) Reset() {
-	*x = ListRecordHistoryResponse{}
-	mi := &file_entities_entities_proto_msgTypes[16]
+func (x *StatsRequest) Reset() {
+	*x = StatsRequest{}
+	mi := &file_entities_entities_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListRecordHistoryResponse) String() string {
+func (x *StatsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListRecordHistoryResponse) ProtoMessage() {}
+func (*StatsRequest) ProtoMessage() {}
 
-func (x *ListRecordHistoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_entities_entities_proto_msgTypes[16]
+func (x *StatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1281,50 +2281,69 @@ func (x *ListRecordHistoryResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListRecordHistoryResponse.ProtoReflect.Descriptor instead.
-func (*ListRecordHistoryResponse) Descriptor() ([]byte, []int) {
-	return file_entities_entities_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use StatsRequest.ProtoReflect.Descriptor instead.
+func (*StatsRequest) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{28}
 }
 
-func (x *ListRecordHistoryResponse) GetHistory() []*RecordHistoryEntry {
+func (x *StatsRequest) GetEntity() string {
 	if x != nil {
-		return x.History
+		return x.Entity
+	}
+	return ""
+}
+
+func (x *StatsRequest) GetFilters() []*FilterExpression {
+	if x != nil {
+		return x.Filters
 	}
 	return nil
 }
 
-func (x *ListRecordHistoryResponse) GetNextPageToken() string {
+func (x *StatsRequest) GetGroupField() string {
 	if x != nil {
-		return x.NextPageToken
+		return x.GroupField
 	}
 	return ""
 }
 
-type PivotRequest struct {
+func (x *StatsRequest) GetTimeField() string {
+	if x != nil {
+		return x.TimeField
+	}
+	return ""
+}
+
+func (x *StatsRequest) GetInterval() StatsInterval {
+	if x != nil {
+		return x.Interval
+	}
+	return StatsInterval_STATS_INTERVAL_UNSPECIFIED
+}
+
+type StatsCount struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Entity        string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
-	RowField      string                 `protobuf:"bytes,2,opt,name=row_field,json=rowField,proto3" json:"row_field,omitempty"`
-	ColumnField   string                 `protobuf:"bytes,3,opt,name=column_field,json=columnField,proto3" json:"column_field,omitempty"`
-	Filters       []*FilterExpression    `protobuf:"bytes,4,rep,name=filters,proto3" json:"filters,omitempty"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PivotRequest) Reset() {
-	*x = PivotRequest{}
-	mi := &file_entities_entities_proto_msgTypes[17]
+func (x *StatsCount) Reset() {
+	*x = StatsCount{}
+	mi := &file_entities_entities_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PivotRequest) String() string {
+func (x *StatsCount) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PivotRequest) ProtoMessage() {}
+func (*StatsCount) ProtoMessage() {}
 
-func (x *PivotRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_entities_entities_proto_msgTypes[17]
+func (x *StatsCount) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1335,63 +2354,105 @@ func (x *PivotRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PivotRequest.ProtoReflect.Descriptor instead.
-func (*PivotRequest) Descriptor() ([]byte, []int) {
-	return file_entities_entities_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use StatsCount.ProtoReflect.Descriptor instead.
+func (*StatsCount) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *PivotRequest) GetEntity() string {
+func (x *StatsCount) GetKey() string {
 	if x != nil {
-		return x.Entity
+		return x.Key
 	}
 	return ""
 }
 
-func (x *PivotRequest) GetRowField() string {
+func (x *StatsCount) GetCount() int64 {
 	if x != nil {
-		return x.RowField
+		return x.Count
 	}
-	return ""
+	return 0
 }
 
-func (x *PivotRequest) GetColumnField() string {
+type StatsBucket struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Start of the bucket in UTC.
+	Start         *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsBucket) Reset() {
+	*x = StatsBucket{}
+	mi := &file_entities_entities_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsBucket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsBucket) ProtoMessage() {}
+
+func (x *StatsBucket) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[30]
 	if x != nil {
-		return x.ColumnField
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *PivotRequest) GetFilters() []*FilterExpression {
+// Deprecated: Use StatsBucket.ProtoReflect.Descriptor instead.
+func (*StatsBucket) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *StatsBucket) GetStart() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Filters
+		return x.Start
 	}
 	return nil
 }
 
-type PivotEntry struct {
+func (x *StatsBucket) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type StatsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	RowKey        string                 `protobuf:"bytes,1,opt,name=row_key,json=rowKey,proto3" json:"row_key,omitempty"`
-	ColumnKey     string                 `protobuf:"bytes,2,opt,name=column_key,json=columnKey,proto3" json:"column_key,omitempty"`
-	Value         float64                `protobuf:"fixed64,3,opt,name=value,proto3" json:"value,omitempty"`
+	Total         int64                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	GroupField    string                 `protobuf:"bytes,2,opt,name=group_field,json=groupField,proto3" json:"group_field,omitempty"`
+	Groups        []*StatsCount          `protobuf:"bytes,3,rep,name=groups,proto3" json:"groups,omitempty"`
+	TimeField     string                 `protobuf:"bytes,4,opt,name=time_field,json=timeField,proto3" json:"time_field,omitempty"`
+	Interval      StatsInterval          `protobuf:"varint,5,opt,name=interval,proto3,enum=entities.v1.StatsInterval" json:"interval,omitempty"`
+	Buckets       []*StatsBucket         `protobuf:"bytes,6,rep,name=buckets,proto3" json:"buckets,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PivotEntry) Reset() {
-	*x = PivotEntry{}
-	mi := &file_entities_entities_proto_msgTypes[18]
+func (x *StatsResponse) Reset() {
+	*x = StatsResponse{}
+	mi := &file_entities_entities_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PivotEntry) String() string {
+func (x *StatsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PivotEntry) ProtoMessage() {}
+func (*StatsResponse) ProtoMessage() {}
 
-func (x *PivotEntry) ProtoReflect() protoreflect.Message {
-	mi := &file_entities_entities_proto_msgTypes[18]
+func (x *StatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1402,56 +2463,77 @@ func (x *PivotEntry) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PivotEntry.ProtoReflect.Descriptor instead.
-func (*PivotEntry) Descriptor() ([]byte, []int) {
-	return file_entities_entities_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use StatsResponse.ProtoReflect.Descriptor instead.
+func (*StatsResponse) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{31}
 }
 
-func (x *PivotEntry) GetRowKey() string {
+func (x *StatsResponse) GetTotal() int64 {
 	if x != nil {
-		return x.RowKey
+		return x.Total
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetGroupField() string {
+	if x != nil {
+		return x.GroupField
 	}
 	return ""
 }
 
-func (x *PivotEntry) GetColumnKey() string {
+func (x *StatsResponse) GetGroups() []*StatsCount {
 	if x != nil {
-		return x.ColumnKey
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *StatsResponse) GetTimeField() string {
+	if x != nil {
+		return x.TimeField
 	}
 	return ""
 }
 
-func (x *PivotEntry) GetValue() float64 {
+func (x *StatsResponse) GetInterval() StatsInterval {
 	if x != nil {
-		return x.Value
+		return x.Interval
 	}
-	return 0
+	return StatsInterval_STATS_INTERVAL_UNSPECIFIED
 }
 
-type PivotTotals struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Row           map[string]float64     `protobuf:"bytes,1,rep,name=row,proto3" json:"row,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
-	Column        map[string]float64     `protobuf:"bytes,2,rep,name=column,proto3" json:"column,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
-	Grand         float64                `protobuf:"fixed64,3,opt,name=grand,proto3" json:"grand,omitempty"`
+func (x *StatsResponse) GetBuckets() []*StatsBucket {
+	if x != nil {
+		return x.Buckets
+	}
+	return nil
+}
+
+type CompactRecordsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Entity string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
+	// Compacts only this record when set, otherwise every record of the entity.
+	RecordId      string `protobuf:"bytes,2,opt,name=record_id,json=recordId,proto3" json:"record_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PivotTotals) Reset() {
-	*x = PivotTotals{}
-	mi := &file_entities_entities_proto_msgTypes[19]
+func (x *CompactRecordsRequest) Reset() {
+	*x = CompactRecordsRequest{}
+	mi := &file_entities_entities_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PivotTotals) String() string {
+func (x *CompactRecordsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PivotTotals) ProtoMessage() {}
+func (*CompactRecordsRequest) ProtoMessage() {}
 
-func (x *PivotTotals) ProtoReflect() protoreflect.Message {
-	mi := &file_entities_entities_proto_msgTypes[19]
+func (x *CompactRecordsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1462,57 +2544,50 @@ func (x *PivotTotals) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PivotTotals.ProtoReflect.Descriptor instead.
-func (*PivotTotals) Descriptor() ([]byte, []int) {
-	return file_entities_entities_proto_rawDescGZIP(), []int{19}
-}
-
-func (x *PivotTotals) GetRow() map[string]float64 {
-	if x != nil {
-		return x.Row
-	}
-	return nil
+// Deprecated: Use CompactRecordsRequest.ProtoReflect.Descriptor instead.
+func (*CompactRecordsRequest) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{32}
 }
 
-func (x *PivotTotals) GetColumn() map[string]float64 {
+func (x *CompactRecordsRequest) GetEntity() string {
 	if x != nil {
-		return x.Column
+		return x.Entity
 	}
-	return nil
+	return ""
 }
 
-func (x *PivotTotals) GetGrand() float64 {
+func (x *CompactRecordsRequest) GetRecordId() string {
 	if x != nil {
-		return x.Grand
+		return x.RecordId
 	}
-	return 0
+	return ""
 }
 
-type PivotResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	RowField      string                 `protobuf:"bytes,1,opt,name=row_field,json=rowField,proto3" json:"row_field,omitempty"`
-	ColumnField   string                 `protobuf:"bytes,2,opt,name=column_field,json=columnField,proto3" json:"column_field,omitempty"`
-	Entries       []*PivotEntry          `protobuf:"bytes,3,rep,name=entries,proto3" json:"entries,omitempty"`
-	Totals        *PivotTotals           `protobuf:"bytes,4,opt,name=totals,proto3" json:"totals,omitempty"`
+type CompactRecordsResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Entity string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
+	// Number of records that had events removed.
+	Records       int32 `protobuf:"varint,2,opt,name=records,proto3" json:"records,omitempty"`
+	RemovedEvents int64 `protobuf:"varint,3,opt,name=removed_events,json=removedEvents,proto3" json:"removed_events,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PivotResponse) Reset() {
-	*x = PivotResponse{}
-	mi := &file_entities_entities_proto_msgTypes[20]
+func (x *CompactRecordsResponse) Reset() {
+	*x = CompactRecordsResponse{}
+	mi := &file_entities_entities_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PivotResponse) String() string {
+func (x *CompactRecordsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PivotResponse) ProtoMessage() {}
+func (*CompactRecordsResponse) ProtoMessage() {}
 
-func (x *PivotResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_entities_entities_proto_msgTypes[20]
+func (x *CompactRecordsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_entities_entities_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1523,37 +2598,30 @@ func (x *PivotResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PivotResponse.ProtoReflect.Descriptor instead.
-func (*PivotResponse) Descriptor() ([]byte, []int) {
-	return file_entities_entities_proto_rawDescGZIP(), []int{20}
-}
-
-func (x *PivotResponse) GetRowField() string {
-	if x != nil {
-		return x.RowField
-	}
-	return ""
+// Deprecated: Use CompactRecordsResponse.ProtoReflect.Descriptor instead.
+func (*CompactRecordsResponse) Descriptor() ([]byte, []int) {
+	return file_entities_entities_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *PivotResponse) GetColumnField() string {
+func (x *CompactRecordsResponse) GetEntity() string {
 	if x != nil {
-		return x.ColumnField
+		return x.Entity
 	}
 	return ""
 }
 
-func (x *PivotResponse) GetEntries() []*PivotEntry {
+func (x *CompactRecordsResponse) GetRecords() int32 {
 	if x != nil {
-		return x.Entries
+		return x.Records
 	}
-	return nil
+	return 0
 }
 
-func (x *PivotResponse) GetTotals() *PivotTotals {
+func (x *CompactRecordsResponse) GetRemovedEvents() int64 {
 	if x != nil {
-		return x.Totals
+		return x.RemovedEvents
 	}
-	return nil
+	return 0
 }
 
 var File_entities_entities_proto protoreflect.FileDescriptor
@@ -1599,16 +2667,22 @@ const file_entities_entities_proto_rawDesc = "" +
 	"\n" +
 	"created_by\x18\x04 \x01(\tR\tcreatedBy\x12\x1d\n" +
 	"\n" +
-	"updated_by\x18\x05 \x01(\tR\tupdatedBy\"\x96\x01\n" +
+	"updated_by\x18\x05 \x01(\tR\tupdatedBy\"\x8a\x02\n" +
 	"\x06Record\x12\x16\n" +
 	"\x06entity\x18\x01 \x01(\tR\x06entity\x12\x0e\n" +
 	"\x02id\x18\x02 \x01(\tR\x02id\x12+\n" +
 	"\x04data\x18\x03 \x01(\v2\x17.google.protobuf.StructR\x04data\x127\n" +
-	"\bmetadata\x18\x04 \x01(\v2\x1b.entities.v1.RecordMetadataR\bmetadata\"\x8f\x01\n" +
+	"\bmetadata\x18\x04 \x01(\v2\x1b.entities.v1.RecordMetadataR\bmetadata\x127\n" +
+	"\x06labels\x18\x05 \x03(\v2\x1f.entities.v1.Record.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8f\x01\n" +
 	"\x10FilterExpression\x12\x14\n" +
 	"\x05field\x18\x01 \x01(\tR\x05field\x127\n" +
 	"\boperator\x18\x02 \x01(\x0e2\x1b.entities.v1.FilterOperatorR\boperator\x12,\n" +
-	"\x05value\x18\x03 \x01(\v2\x16.google.protobuf.ValueR\x05value\"\x9b\x02\n" +
+	"\x05value\x18\x03 \x01(\v2\x16.google.protobuf.ValueR\x05value\"F\n" +
+	"\vFilterGroup\x127\n" +
+	"\afilters\x18\x01 \x03(\v2\x1d.entities.v1.FilterExpressionR\afilters\"\xd9\x03\n" +
 	"\x12ListRecordsRequest\x12\x16\n" +
 	"\x06entity\x18\x01 \x01(\tR\x06entity\x127\n" +
 	"\afilters\x18\x02 \x03(\v2\x1d.entities.v1.FilterExpressionR\afilters\x12\x1b\n" +
@@ -1618,29 +2692,54 @@ const file_entities_entities_proto_rawDesc = "" +
 	"\n" +
 	"sort_field\x18\x05 \x01(\tR\tsortField\x12A\n" +
 	"\x0esort_direction\x18\x06 \x01(\x0e2\x1a.entities.v1.SortDirectionR\rsortDirection\x12\x16\n" +
-	"\x06search\x18\a \x01(\tR\x06search\"l\n" +
+	"\x06search\x18\a \x01(\tR\x06search\x12\x16\n" +
+	"\x06fields\x18\b \x01(\tR\x06fields\x12.\n" +
+	"\x13include_total_count\x18\t \x01(\bR\x11includeTotalCount\x12%\n" +
+	"\x0eresolve_labels\x18\n" +
+	" \x01(\bR\rresolveLabels\x12\x16\n" +
+	"\x06expand\x18\v \x03(\tR\x06expand\x125\n" +
+	"\tor_groups\x18\f \x03(\v2\x18.entities.v1.FilterGroupR\borGroups\"\xa2\x01\n" +
 	"\x13ListRecordsResponse\x12-\n" +
 	"\arecords\x18\x01 \x03(\v2\x13.entities.v1.RecordR\arecords\x12&\n" +
-	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\":\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12$\n" +
+	"\vtotal_count\x18\x03 \x01(\x03H\x00R\n" +
+	"totalCount\x88\x01\x01B\x0e\n" +
+	"\f_total_count\"j\n" +
 	"\x10GetRecordRequest\x12\x16\n" +
 	"\x06entity\x18\x01 \x01(\tR\x06entity\x12\x0e\n" +
-	"\x02id\x18\x02 \x01(\tR\x02id\"Z\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x16\n" +
+	"\x06fields\x18\x03 \x01(\tR\x06fields\x12\x16\n" +
+	"\x06expand\x18\x04 \x03(\tR\x06expand\"Z\n" +
 	"\x13CreateRecordRequest\x12\x16\n" +
 	"\x06entity\x18\x01 \x01(\tR\x06entity\x12+\n" +
-	"\x04data\x18\x02 \x01(\v2\x17.google.protobuf.StructR\x04data\"j\n" +
+	"\x04data\x18\x02 \x01(\v2\x17.google.protobuf.StructR\x04data\"~\n" +
+	"\x19BatchCreateRecordsRequest\x12\x16\n" +
+	"\x06entity\x18\x01 \x01(\tR\x06entity\x121\n" +
+	"\arecords\x18\x02 \x03(\v2\x17.google.protobuf.StructR\arecords\x12\x16\n" +
+	"\x06atomic\x18\x03 \x01(\bR\x06atomic\"V\n" +
+	"\x11BatchRecordResult\x12+\n" +
+	"\x06record\x18\x01 \x01(\v2\x13.entities.v1.RecordR\x06record\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"V\n" +
+	"\x1aBatchCreateRecordsResponse\x128\n" +
+	"\aresults\x18\x01 \x03(\v2\x1e.entities.v1.BatchRecordResultR\aresults\"j\n" +
 	"\x13UpdateRecordRequest\x12\x16\n" +
 	"\x06entity\x18\x01 \x01(\tR\x06entity\x12\x0e\n" +
 	"\x02id\x18\x02 \x01(\tR\x02id\x12+\n" +
 	"\x04data\x18\x03 \x01(\v2\x17.google.protobuf.StructR\x04data\"=\n" +
 	"\x13DeleteRecordRequest\x12\x16\n" +
 	"\x06entity\x18\x01 \x01(\tR\x06entity\x12\x0e\n" +
-	"\x02id\x18\x02 \x01(\tR\x02id\"~\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\">\n" +
+	"\x14RestoreRecordRequest\x12\x16\n" +
+	"\x06entity\x18\x01 \x01(\tR\x06entity\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"\xc8\x01\n" +
 	"\x18ListRecordHistoryRequest\x12\x16\n" +
 	"\x06entity\x18\x01 \x01(\tR\x06entity\x12\x0e\n" +
 	"\x02id\x18\x02 \x01(\tR\x02id\x12\x1b\n" +
 	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
 	"\n" +
-	"page_token\x18\x04 \x01(\tR\tpageToken\"\x88\x02\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\x12#\n" +
+	"\rsince_version\x18\x05 \x01(\x03R\fsinceVersion\x12#\n" +
+	"\runtil_version\x18\x06 \x01(\x03R\funtilVersion\"\x88\x02\n" +
 	"\x12RecordHistoryEntry\x12\x18\n" +
 	"\aversion\x18\x01 \x01(\x03R\aversion\x12\x1d\n" +
 	"\n" +
@@ -1654,12 +2753,30 @@ const file_entities_entities_proto_rawDesc = "" +
 	"\adeleted\x18\a \x01(\bR\adeleted\"~\n" +
 	"\x19ListRecordHistoryResponse\x129\n" +
 	"\ahistory\x18\x01 \x03(\v2\x1f.entities.v1.RecordHistoryEntryR\ahistory\x12&\n" +
-	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\x9f\x01\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"Y\n" +
+	"\x1aStreamEntityChangesRequest\x12\x16\n" +
+	"\x06entity\x18\x01 \x01(\tR\x06entity\x12#\n" +
+	"\rfrom_position\x18\x02 \x01(\x03R\ffromPosition\"\xcd\x02\n" +
+	"\fEntityChange\x12\x1a\n" +
+	"\bposition\x18\x01 \x01(\x03R\bposition\x12\x16\n" +
+	"\x06entity\x18\x02 \x01(\tR\x06entity\x12\x1b\n" +
+	"\trecord_id\x18\x03 \x01(\tR\brecordId\x12\x1f\n" +
+	"\vchange_type\x18\x04 \x01(\tR\n" +
+	"changeType\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x03R\aversion\x12;\n" +
+	"\voccurred_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\x12\x14\n" +
+	"\x05actor\x18\a \x01(\tR\x05actor\x12/\n" +
+	"\x06before\x18\b \x01(\v2\x17.google.protobuf.StructR\x06before\x12-\n" +
+	"\x05after\x18\t \x01(\v2\x17.google.protobuf.StructR\x05after\"\x81\x02\n" +
 	"\fPivotRequest\x12\x16\n" +
 	"\x06entity\x18\x01 \x01(\tR\x06entity\x12\x1b\n" +
 	"\trow_field\x18\x02 \x01(\tR\browField\x12!\n" +
 	"\fcolumn_field\x18\x03 \x01(\tR\vcolumnField\x127\n" +
-	"\afilters\x18\x04 \x03(\v2\x1d.entities.v1.FilterExpressionR\afilters\"Z\n" +
+	"\afilters\x18\x04 \x03(\v2\x1d.entities.v1.FilterExpressionR\afilters\x12\x1f\n" +
+	"\vvalue_field\x18\x05 \x01(\tR\n" +
+	"valueField\x12?\n" +
+	"\vaggregation\x18\x06 \x01(\x0e2\x1d.entities.v1.PivotAggregationR\vaggregation\"Z\n" +
 	"\n" +
 	"PivotEntry\x12\x17\n" +
 	"\arow_key\x18\x01 \x01(\tR\x06rowKey\x12\x1d\n" +
@@ -1675,12 +2792,46 @@ const file_entities_entities_proto_rawDesc = "" +
 	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\x1a9\n" +
 	"\vColumnEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\xb4\x01\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\x96\x02\n" +
 	"\rPivotResponse\x12\x1b\n" +
 	"\trow_field\x18\x01 \x01(\tR\browField\x12!\n" +
 	"\fcolumn_field\x18\x02 \x01(\tR\vcolumnField\x121\n" +
 	"\aentries\x18\x03 \x03(\v2\x17.entities.v1.PivotEntryR\aentries\x120\n" +
-	"\x06totals\x18\x04 \x01(\v2\x18.entities.v1.PivotTotalsR\x06totals*\xdd\x01\n" +
+	"\x06totals\x18\x04 \x01(\v2\x18.entities.v1.PivotTotalsR\x06totals\x12\x1f\n" +
+	"\vvalue_field\x18\x05 \x01(\tR\n" +
+	"valueField\x12?\n" +
+	"\vaggregation\x18\x06 \x01(\x0e2\x1d.entities.v1.PivotAggregationR\vaggregation\"\xd7\x01\n" +
+	"\fStatsRequest\x12\x16\n" +
+	"\x06entity\x18\x01 \x01(\tR\x06entity\x127\n" +
+	"\afilters\x18\x02 \x03(\v2\x1d.entities.v1.FilterExpressionR\afilters\x12\x1f\n" +
+	"\vgroup_field\x18\x03 \x01(\tR\n" +
+	"groupField\x12\x1d\n" +
+	"\n" +
+	"time_field\x18\x04 \x01(\tR\ttimeField\x126\n" +
+	"\binterval\x18\x05 \x01(\x0e2\x1a.entities.v1.StatsIntervalR\binterval\"4\n" +
+	"\n" +
+	"StatsCount\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\"U\n" +
+	"\vStatsBucket\x120\n" +
+	"\x05start\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x05start\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\"\x82\x02\n" +
+	"\rStatsResponse\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x03R\x05total\x12\x1f\n" +
+	"\vgroup_field\x18\x02 \x01(\tR\n" +
+	"groupField\x12/\n" +
+	"\x06groups\x18\x03 \x03(\v2\x17.entities.v1.StatsCountR\x06groups\x12\x1d\n" +
+	"\n" +
+	"time_field\x18\x04 \x01(\tR\ttimeField\x126\n" +
+	"\binterval\x18\x05 \x01(\x0e2\x1a.entities.v1.StatsIntervalR\binterval\x122\n" +
+	"\abuckets\x18\x06 \x03(\v2\x18.entities.v1.StatsBucketR\abuckets\"L\n" +
+	"\x15CompactRecordsRequest\x12\x16\n" +
+	"\x06entity\x18\x01 \x01(\tR\x06entity\x12\x1b\n" +
+	"\trecord_id\x18\x02 \x01(\tR\brecordId\"q\n" +
+	"\x16CompactRecordsResponse\x12\x16\n" +
+	"\x06entity\x18\x01 \x01(\tR\x06entity\x12\x18\n" +
+	"\arecords\x18\x02 \x01(\x05R\arecords\x12%\n" +
+	"\x0eremoved_events\x18\x03 \x01(\x03R\rremovedEvents*\xdd\x01\n" +
 	"\tFieldType\x12\x1a\n" +
 	"\x16FIELD_TYPE_UNSPECIFIED\x10\x00\x12\x15\n" +
 	"\x11FIELD_TYPE_STRING\x10\x01\x12\x15\n" +
@@ -1690,7 +2841,7 @@ const file_entities_entities_proto_rawDesc = "" +
 	"\x0fFIELD_TYPE_UUID\x10\x05\x12\x13\n" +
 	"\x0fFIELD_TYPE_ENUM\x10\x06\x12\x15\n" +
 	"\x11FIELD_TYPE_OBJECT\x10\a\x12\x14\n" +
-	"\x10FIELD_TYPE_ARRAY\x10\b*\xf9\x01\n" +
+	"\x10FIELD_TYPE_ARRAY\x10\b*\xd4\x02\n" +
 	"\x0eFilterOperator\x12\x1f\n" +
 	"\x1bFILTER_OPERATOR_UNSPECIFIED\x10\x00\x12\x16\n" +
 	"\x12FILTER_OPERATOR_EQ\x10\x01\x12\x16\n" +
@@ -1700,21 +2851,43 @@ const file_entities_entities_proto_rawDesc = "" +
 	"\x12FILTER_OPERATOR_GT\x10\x05\x12\x17\n" +
 	"\x13FILTER_OPERATOR_GTE\x10\x06\x12\x16\n" +
 	"\x12FILTER_OPERATOR_LT\x10\a\x12\x17\n" +
-	"\x13FILTER_OPERATOR_LTE\x10\b*`\n" +
+	"\x13FILTER_OPERATOR_LTE\x10\b\x12\x1b\n" +
+	"\x17FILTER_OPERATOR_BETWEEN\x10\t\x12\x1b\n" +
+	"\x17FILTER_OPERATOR_IS_NULL\x10\n" +
+	"\x12\x1f\n" +
+	"\x1bFILTER_OPERATOR_IS_NOT_NULL\x10\v*`\n" +
 	"\rSortDirection\x12\x1e\n" +
 	"\x1aSORT_DIRECTION_UNSPECIFIED\x10\x00\x12\x16\n" +
 	"\x12SORT_DIRECTION_ASC\x10\x01\x12\x17\n" +
-	"\x13SORT_DIRECTION_DESC\x10\x022\xbd\b\n" +
+	"\x13SORT_DIRECTION_DESC\x10\x02*\xbe\x01\n" +
+	"\x10PivotAggregation\x12!\n" +
+	"\x1dPIVOT_AGGREGATION_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17PIVOT_AGGREGATION_COUNT\x10\x01\x12\x19\n" +
+	"\x15PIVOT_AGGREGATION_SUM\x10\x02\x12\x19\n" +
+	"\x15PIVOT_AGGREGATION_AVG\x10\x03\x12\x19\n" +
+	"\x15PIVOT_AGGREGATION_MIN\x10\x04\x12\x19\n" +
+	"\x15PIVOT_AGGREGATION_MAX\x10\x05*\x93\x01\n" +
+	"\rStatsInterval\x12\x1e\n" +
+	"\x1aSTATS_INTERVAL_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13STATS_INTERVAL_HOUR\x10\x01\x12\x16\n" +
+	"\x12STATS_INTERVAL_DAY\x10\x02\x12\x17\n" +
+	"\x13STATS_INTERVAL_WEEK\x10\x03\x12\x18\n" +
+	"\x14STATS_INTERVAL_MONTH\x10\x042\xd6\r\n" +
 	"\rEntityService\x12c\n" +
 	"\fListEntities\x12\x16.google.protobuf.Empty\x1a!.entities.v1.ListEntitiesResponse\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/api/v1/entities\x12h\n" +
 	"\tGetEntity\x12\x1d.entities.v1.GetEntityRequest\x1a\x1d.entities.v1.EntityDefinition\"\x1d\x82\xd3\xe4\x93\x02\x17\x12\x15/api/v1/entities/{id}\x12\x9a\x01\n" +
 	"\vListRecords\x12\x1f.entities.v1.ListRecordsRequest\x1a .entities.v1.ListRecordsResponse\"H\x82\xd3\xe4\x93\x02B:\x01*Z\x1b\x12\x19/api/v1/entities/{entity}\" /api/v1/entities/{entity}/search\x12g\n" +
 	"\tGetRecord\x12\x1d.entities.v1.GetRecordRequest\x1a\x13.entities.v1.Record\"&\x82\xd3\xe4\x93\x02 \x12\x1e/api/v1/entities/{entity}/{id}\x12k\n" +
-	"\fCreateRecord\x12 .entities.v1.CreateRecordRequest\x1a\x13.entities.v1.Record\"$\x82\xd3\xe4\x93\x02\x1e:\x01*\"\x19/api/v1/entities/{entity}\x12p\n" +
+	"\fCreateRecord\x12 .entities.v1.CreateRecordRequest\x1a\x13.entities.v1.Record\"$\x82\xd3\xe4\x93\x02\x1e:\x01*\"\x19/api/v1/entities/{entity}\x12\x91\x01\n" +
+	"\x12BatchCreateRecords\x12&.entities.v1.BatchCreateRecordsRequest\x1a'.entities.v1.BatchCreateRecordsResponse\"*\x82\xd3\xe4\x93\x02$:\x01*\"\x1f/api/v1/entities/{entity}/batch\x12p\n" +
 	"\fUpdateRecord\x12 .entities.v1.UpdateRecordRequest\x1a\x13.entities.v1.Record\")\x82\xd3\xe4\x93\x02#:\x01*\x1a\x1e/api/v1/entities/{entity}/{id}\x12p\n" +
-	"\fDeleteRecord\x12 .entities.v1.DeleteRecordRequest\x1a\x16.google.protobuf.Empty\"&\x82\xd3\xe4\x93\x02 *\x1e/api/v1/entities/{entity}/{id}\x12\x92\x01\n" +
-	"\x11ListRecordHistory\x12%.entities.v1.ListRecordHistoryRequest\x1a&.entities.v1.ListRecordHistoryResponse\".\x82\xd3\xe4\x93\x02(\x12&/api/v1/entities/{entity}/{id}/history\x12q\n" +
-	"\fPivotRecords\x12\x19.entities.v1.PivotRequest\x1a\x1a.entities.v1.PivotResponse\"*\x82\xd3\xe4\x93\x02$:\x01*\"\x1f/api/v1/entities/{entity}/pivotB\x1dZ\x1bproto/gen/entities;entitiesb\x06proto3"
+	"\fDeleteRecord\x12 .entities.v1.DeleteRecordRequest\x1a\x16.google.protobuf.Empty\"&\x82\xd3\xe4\x93\x02 *\x1e/api/v1/entities/{entity}/{id}\x12w\n" +
+	"\rRestoreRecord\x12!.entities.v1.RestoreRecordRequest\x1a\x13.entities.v1.Record\".\x82\xd3\xe4\x93\x02(\"&/api/v1/entities/{entity}/{id}/restore\x12\x92\x01\n" +
+	"\x11ListRecordHistory\x12%.entities.v1.ListRecordHistoryRequest\x1a&.entities.v1.ListRecordHistoryResponse\".\x82\xd3\xe4\x93\x02(\x12&/api/v1/entities/{entity}/{id}/history\x12\x8d\x01\n" +
+	"\x13StreamEntityChanges\x12'.entities.v1.StreamEntityChangesRequest\x1a\x19.entities.v1.EntityChange\"0\x82\xd3\xe4\x93\x02*\x12(/api/v1/entities/{entity}/changes/stream0\x01\x12q\n" +
+	"\fPivotRecords\x12\x19.entities.v1.PivotRequest\x1a\x1a.entities.v1.PivotResponse\"*\x82\xd3\xe4\x93\x02$:\x01*\"\x1f/api/v1/entities/{entity}/pivot\x12p\n" +
+	"\vRecordStats\x12\x19.entities.v1.StatsRequest\x1a\x1a.entities.v1.StatsResponse\"*\x82\xd3\xe4\x93\x02$:\x01*\"\x1f/api/v1/entities/{entity}/stats\x12\x87\x01\n" +
+	"\x0eCompactRecords\x12\".entities.v1.CompactRecordsRequest\x1a#.entities.v1.CompactRecordsResponse\",\x82\xd3\xe4\x93\x02&:\x01*\"!/api/v1/entities/{entity}/compactB\x1dZ\x1bproto/gen/entities;entitiesb\x06proto3"
 
 var (
 	file_entities_entities_proto_rawDescOnce sync.Once
@@ -1728,92 +2901,135 @@ func file_entities_entities_proto_rawDescGZIP() []byte {
 	return file_entities_entities_proto_rawDescData
 }
 
-var file_entities_entities_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_entities_entities_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
+var file_entities_entities_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_entities_entities_proto_msgTypes = make([]protoimpl.MessageInfo, 39)
 var file_entities_entities_proto_goTypes = []any{
-	(FieldType)(0),                    // 0: entities.v1.FieldType
-	(FilterOperator)(0),               // 1: entities.v1.FilterOperator
-	(SortDirection)(0),                // 2: entities.v1.SortDirection
-	(*FilterDefinition)(nil),          // 3: entities.v1.FilterDefinition
-	(*FieldDefinition)(nil),           // 4: entities.v1.FieldDefinition
-	(*EntityDefinition)(nil),          // 5: entities.v1.EntityDefinition
-	(*ListEntitiesResponse)(nil),      // 6: entities.v1.ListEntitiesResponse
-	(*GetEntityRequest)(nil),          // 7: entities.v1.GetEntityRequest
-	(*RecordMetadata)(nil),            // 8: entities.v1.RecordMetadata
-	(*Record)(nil),                    // 9: entities.v1.Record
-	(*FilterExpression)(nil),          // 10: entities.v1.FilterExpression
-	(*ListRecordsRequest)(nil),        // 11: entities.v1.ListRecordsRequest
-	(*ListRecordsResponse)(nil),       // 12: entities.v1.ListRecordsResponse
-	(*GetRecordRequest)(nil),          // 13: entities.v1.GetRecordRequest
-	(*CreateRecordRequest)(nil),       // 14: entities.v1.CreateRecordRequest
-	(*UpdateRecordRequest)(nil),       // 15: entities.v1.UpdateRecordRequest
-	(*DeleteRecordRequest)(nil),       // 16: entities.v1.DeleteRecordRequest
-	(*ListRecordHistoryRequest)(nil),  // 17: entities.v1.ListRecordHistoryRequest
-	(*RecordHistoryEntry)(nil),        // 18: entities.v1.RecordHistoryEntry
-	(*ListRecordHistoryResponse)(nil), // 19: entities.v1.ListRecordHistoryResponse
-	(*PivotRequest)(nil),              // 20: entities.v1.PivotRequest
-	(*PivotEntry)(nil),                // 21: entities.v1.PivotEntry
-	(*PivotTotals)(nil),               // 22: entities.v1.PivotTotals
-	(*PivotResponse)(nil),             // 23: entities.v1.PivotResponse
-	nil,                               // 24: entities.v1.FieldDefinition.MetadataEntry
-	nil,                               // 25: entities.v1.EntityDefinition.MetadataEntry
-	nil,                               // 26: entities.v1.PivotTotals.RowEntry
-	nil,                               // 27: entities.v1.PivotTotals.ColumnEntry
-	(*timestamppb.Timestamp)(nil),     // 28: google.protobuf.Timestamp
-	(*structpb.Struct)(nil),           // 29: google.protobuf.Struct
-	(*structpb.Value)(nil),            // 30: google.protobuf.Value
-	(*emptypb.Empty)(nil),             // 31: google.protobuf.Empty
+	(FieldType)(0),                     // 0: entities.v1.FieldType
+	(FilterOperator)(0),                // 1: entities.v1.FilterOperator
+	(SortDirection)(0),                 // 2: entities.v1.SortDirection
+	(PivotAggregation)(0),              // 3: entities.v1.PivotAggregation
+	(StatsInterval)(0),                 // 4: entities.v1.StatsInterval
+	(*FilterDefinition)(nil),           // 5: entities.v1.FilterDefinition
+	(*FieldDefinition)(nil),            // 6: entities.v1.FieldDefinition
+	(*EntityDefinition)(nil),           // 7: entities.v1.EntityDefinition
+	(*ListEntitiesResponse)(nil),       // 8: entities.v1.ListEntitiesResponse
+	(*GetEntityRequest)(nil),           // 9: entities.v1.GetEntityRequest
+	(*RecordMetadata)(nil),             // 10: entities.v1.RecordMetadata
+	(*Record)(nil),                     // 11: entities.v1.Record
+	(*FilterExpression)(nil),           // 12: entities.v1.FilterExpression
+	(*FilterGroup)(nil),                // 13: entities.v1.FilterGroup
+	(*ListRecordsRequest)(nil),         // 14: entities.v1.ListRecordsRequest
+	(*ListRecordsResponse)(nil),        // 15: entities.v1.ListRecordsResponse
+	(*GetRecordRequest)(nil),           // 16: entities.v1.GetRecordRequest
+	(*CreateRecordRequest)(nil),        // 17: entities.v1.CreateRecordRequest
+	(*BatchCreateRecordsRequest)(nil),  // 18: entities.v1.BatchCreateRecordsRequest
+	(*BatchRecordResult)(nil),          // 19: entities.v1.BatchRecordResult
+	(*BatchCreateRecordsResponse)(nil), // 20: entities.v1.BatchCreateRecordsResponse
+	(*UpdateRecordRequest)(nil),        // 21: entities.v1.UpdateRecordRequest
+	(*DeleteRecordRequest)(nil),        // 22: entities.v1.DeleteRecordRequest
+	(*RestoreRecordRequest)(nil),       // 23: entities.v1.RestoreRecordRequest
+	(*ListRecordHistoryRequest)(nil),   // 24: entities.v1.ListRecordHistoryRequest
+	(*RecordHistoryEntry)(nil),         // 25: entities.v1.RecordHistoryEntry
+	(*ListRecordHistoryResponse)(nil),  // 26: entities.v1.ListRecordHistoryResponse
+	(*StreamEntityChangesRequest)(nil), // 27: entities.v1.StreamEntityChangesRequest
+	(*EntityChange)(nil),               // 28: entities.v1.EntityChange
+	(*PivotRequest)(nil),               // 29: entities.v1.PivotRequest
+	(*PivotEntry)(nil),                 // 30: entities.v1.PivotEntry
+	(*PivotTotals)(nil),                // 31: entities.v1.PivotTotals
+	(*PivotResponse)(nil),              // 32: entities.v1.PivotResponse
+	(*StatsRequest)(nil),               // 33: entities.v1.StatsRequest
+	(*StatsCount)(nil),                 // 34: entities.v1.StatsCount
+	(*StatsBucket)(nil),                // 35: entities.v1.StatsBucket
+	(*StatsResponse)(nil),              // 36: entities.v1.StatsResponse
+	(*CompactRecordsRequest)(nil),      // 37: entities.v1.CompactRecordsRequest
+	(*CompactRecordsResponse)(nil),     // 38: entities.v1.CompactRecordsResponse
+	nil,                                // 39: entities.v1.FieldDefinition.MetadataEntry
+	nil,                                // 40: entities.v1.EntityDefinition.MetadataEntry
+	nil,                                // 41: entities.v1.Record.LabelsEntry
+	nil,                                // 42: entities.v1.PivotTotals.RowEntry
+	nil,                                // 43: entities.v1.PivotTotals.ColumnEntry
+	(*timestamppb.Timestamp)(nil),      // 44: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),            // 45: google.protobuf.Struct
+	(*structpb.Value)(nil),             // 46: google.protobuf.Value
+	(*emptypb.Empty)(nil),              // 47: google.protobuf.Empty
 }
 var file_entities_entities_proto_depIdxs = []int32{
 	1,  // 0: entities.v1.FilterDefinition.operators:type_name -> entities.v1.FilterOperator
 	0,  // 1: entities.v1.FieldDefinition.type:type_name -> entities.v1.FieldType
-	3,  // 2: entities.v1.FieldDefinition.filter:type_name -> entities.v1.FilterDefinition
-	24, // 3: entities.v1.FieldDefinition.metadata:type_name -> entities.v1.FieldDefinition.MetadataEntry
-	4,  // 4: entities.v1.EntityDefinition.fields:type_name -> entities.v1.FieldDefinition
-	25, // 5: entities.v1.EntityDefinition.metadata:type_name -> entities.v1.EntityDefinition.MetadataEntry
-	5,  // 6: entities.v1.ListEntitiesResponse.entities:type_name -> entities.v1.EntityDefinition
-	28, // 7: entities.v1.RecordMetadata.created_at:type_name -> google.protobuf.Timestamp
-	28, // 8: entities.v1.RecordMetadata.updated_at:type_name -> google.protobuf.Timestamp
-	29, // 9: entities.v1.Record.data:type_name -> google.protobuf.Struct
-	8,  // 10: entities.v1.Record.metadata:type_name -> entities.v1.RecordMetadata
-	1,  // 11: entities.v1.FilterExpression.operator:type_name -> entities.v1.FilterOperator
-	30, // 12: entities.v1.FilterExpression.value:type_name -> google.protobuf.Value
-	10, // 13: entities.v1.ListRecordsRequest.filters:type_name -> entities.v1.FilterExpression
-	2,  // 14: entities.v1.ListRecordsRequest.sort_direction:type_name -> entities.v1.SortDirection
-	9,  // 15: entities.v1.ListRecordsResponse.records:type_name -> entities.v1.Record
-	29, // 16: entities.v1.CreateRecordRequest.data:type_name -> google.protobuf.Struct
-	29, // 17: entities.v1.UpdateRecordRequest.data:type_name -> google.protobuf.Struct
-	28, // 18: entities.v1.RecordHistoryEntry.occurred_at:type_name -> google.protobuf.Timestamp
-	29, // 19: entities.v1.RecordHistoryEntry.data:type_name -> google.protobuf.Struct
-	18, // 20: entities.v1.ListRecordHistoryResponse.history:type_name -> entities.v1.RecordHistoryEntry
-	10, // 21: entities.v1.PivotRequest.filters:type_name -> entities.v1.FilterExpression
-	26, // 22: entities.v1.PivotTotals.row:type_name -> entities.v1.PivotTotals.RowEntry
-	27, // 23: entities.v1.PivotTotals.column:type_name -> entities.v1.PivotTotals.ColumnEntry
-	21, // 24: entities.v1.PivotResponse.entries:type_name -> entities.v1.PivotEntry
-	22, // 25: entities.v1.PivotResponse.totals:type_name -> entities.v1.PivotTotals
-	31, // 26: entities.v1.EntityService.ListEntities:input_type -> google.protobuf.Empty
-	7,  // 27: entities.v1.EntityService.GetEntity:input_type -> entities.v1.GetEntityRequest
-	11, // 28: entities.v1.EntityService.ListRecords:input_type -> entities.v1.ListRecordsRequest
-	13, // 29: entities.v1.EntityService.GetRecord:input_type -> entities.v1.GetRecordRequest
-	14, // 30: entities.v1.EntityService.CreateRecord:input_type -> entities.v1.CreateRecordRequest
-	15, // 31: entities.v1.EntityService.UpdateRecord:input_type -> entities.v1.UpdateRecordRequest
-	16, // 32: entities.v1.EntityService.DeleteRecord:input_type -> entities.v1.DeleteRecordRequest
-	17, // 33: entities.v1.EntityService.ListRecordHistory:input_type -> entities.v1.ListRecordHistoryRequest
-	20, // 34: entities.v1.EntityService.PivotRecords:input_type -> entities.v1.PivotRequest
-	6,  // 35: entities.v1.EntityService.ListEntities:output_type -> entities.v1.ListEntitiesResponse
-	5,  // 36: entities.v1.EntityService.GetEntity:output_type -> entities.v1.EntityDefinition
-	12, // 37: entities.v1.EntityService.ListRecords:output_type -> entities.v1.ListRecordsResponse
-	9,  // 38: entities.v1.EntityService.GetRecord:output_type -> entities.v1.Record
-	9,  // 39: entities.v1.EntityService.CreateRecord:output_type -> entities.v1.Record
-	9,  // 40: entities.v1.EntityService.UpdateRecord:output_type -> entities.v1.Record
-	31, // 41: entities.v1.EntityService.DeleteRecord:output_type -> google.protobuf.Empty
-	19, // 42: entities.v1.EntityService.ListRecordHistory:output_type -> entities.v1.ListRecordHistoryResponse
-	23, // 43: entities.v1.EntityService.PivotRecords:output_type -> entities.v1.PivotResponse
-	35, // [35:44] is the sub-list for method output_type
-	26, // [26:35] is the sub-list for method input_type
-	26, // [26:26] is the sub-list for extension type_name
-	26, // [26:26] is the sub-list for extension extendee
-	0,  // [0:26] is the sub-list for field type_name
+	5,  // 2: entities.v1.FieldDefinition.filter:type_name -> entities.v1.FilterDefinition
+	39, // 3: entities.v1.FieldDefinition.metadata:type_name -> entities.v1.FieldDefinition.MetadataEntry
+	6,  // 4: entities.v1.EntityDefinition.fields:type_name -> entities.v1.FieldDefinition
+	40, // 5: entities.v1.EntityDefinition.metadata:type_name -> entities.v1.EntityDefinition.MetadataEntry
+	7,  // 6: entities.v1.ListEntitiesResponse.entities:type_name -> entities.v1.EntityDefinition
+	44, // 7: entities.v1.RecordMetadata.created_at:type_name -> google.protobuf.Timestamp
+	44, // 8: entities.v1.RecordMetadata.updated_at:type_name -> google.protobuf.Timestamp
+	45, // 9: entities.v1.Record.data:type_name -> google.protobuf.Struct
+	10, // 10: entities.v1.Record.metadata:type_name -> entities.v1.RecordMetadata
+	41, // 11: entities.v1.Record.labels:type_name -> entities.v1.Record.LabelsEntry
+	1,  // 12: entities.v1.FilterExpression.operator:type_name -> entities.v1.FilterOperator
+	46, // 13: entities.v1.FilterExpression.value:type_name -> google.protobuf.Value
+	12, // 14: entities.v1.FilterGroup.filters:type_name -> entities.v1.FilterExpression
+	12, // 15: entities.v1.ListRecordsRequest.filters:type_name -> entities.v1.FilterExpression
+	2,  // 16: entities.v1.ListRecordsRequest.sort_direction:type_name -> entities.v1.SortDirection
+	13, // 17: entities.v1.ListRecordsRequest.or_groups:type_name -> entities.v1.FilterGroup
+	11, // 18: entities.v1.ListRecordsResponse.records:type_name -> entities.v1.Record
+	45, // 19: entities.v1.CreateRecordRequest.data:type_name -> google.protobuf.Struct
+	45, // 20: entities.v1.BatchCreateRecordsRequest.records:type_name -> google.protobuf.Struct
+	11, // 21: entities.v1.BatchRecordResult.record:type_name -> entities.v1.Record
+	19, // 22: entities.v1.BatchCreateRecordsResponse.results:type_name -> entities.v1.BatchRecordResult
+	45, // 23: entities.v1.UpdateRecordRequest.data:type_name -> google.protobuf.Struct
+	44, // 24: entities.v1.RecordHistoryEntry.occurred_at:type_name -> google.protobuf.Timestamp
+	45, // 25: entities.v1.RecordHistoryEntry.data:type_name -> google.protobuf.Struct
+	25, // 26: entities.v1.ListRecordHistoryResponse.history:type_name -> entities.v1.RecordHistoryEntry
+	44, // 27: entities.v1.EntityChange.occurred_at:type_name -> google.protobuf.Timestamp
+	45, // 28: entities.v1.EntityChange.before:type_name -> google.protobuf.Struct
+	45, // 29: entities.v1.EntityChange.after:type_name -> google.protobuf.Struct
+	12, // 30: entities.v1.PivotRequest.filters:type_name -> entities.v1.FilterExpression
+	3,  // 31: entities.v1.PivotRequest.aggregation:type_name -> entities.v1.PivotAggregation
+	42, // 32: entities.v1.PivotTotals.row:type_name -> entities.v1.PivotTotals.RowEntry
+	43, // 33: entities.v1.PivotTotals.column:type_name -> entities.v1.PivotTotals.ColumnEntry
+	30, // 34: entities.v1.PivotResponse.entries:type_name -> entities.v1.PivotEntry
+	31, // 35: entities.v1.PivotResponse.totals:type_name -> entities.v1.PivotTotals
+	3,  // 36: entities.v1.PivotResponse.aggregation:type_name -> entities.v1.PivotAggregation
+	12, // 37: entities.v1.StatsRequest.filters:type_name -> entities.v1.FilterExpression
+	4,  // 38: entities.v1.StatsRequest.interval:type_name -> entities.v1.StatsInterval
+	44, // 39: entities.v1.StatsBucket.start:type_name -> google.protobuf.Timestamp
+	34, // 40: entities.v1.StatsResponse.groups:type_name -> entities.v1.StatsCount
+	4,  // 41: entities.v1.StatsResponse.interval:type_name -> entities.v1.StatsInterval
+	35, // 42: entities.v1.StatsResponse.buckets:type_name -> entities.v1.StatsBucket
+	47, // 43: entities.v1.EntityService.ListEntities:input_type -> google.protobuf.Empty
+	9,  // 44: entities.v1.EntityService.GetEntity:input_type -> entities.v1.GetEntityRequest
+	14, // 45: entities.v1.EntityService.ListRecords:input_type -> entities.v1.ListRecordsRequest
+	16, // 46: entities.v1.EntityService.GetRecord:input_type -> entities.v1.GetRecordRequest
+	17, // 47: entities.v1.EntityService.CreateRecord:input_type -> entities.v1.CreateRecordRequest
+	18, // 48: entities.v1.EntityService.BatchCreateRecords:input_type -> entities.v1.BatchCreateRecordsRequest
+	21, // 49: entities.v1.EntityService.UpdateRecord:input_type -> entities.v1.UpdateRecordRequest
+	22, // 50: entities.v1.EntityService.DeleteRecord:input_type -> entities.v1.DeleteRecordRequest
+	23, // 51: entities.v1.EntityService.RestoreRecord:input_type -> entities.v1.RestoreRecordRequest
+	24, // 52: entities.v1.EntityService.ListRecordHistory:input_type -> entities.v1.ListRecordHistoryRequest
+	27, // 53: entities.v1.EntityService.StreamEntityChanges:input_type -> entities.v1.StreamEntityChangesRequest
+	29, // 54: entities.v1.EntityService.PivotRecords:input_type -> entities.v1.PivotRequest
+	33, // 55: entities.v1.EntityService.RecordStats:input_type -> entities.v1.StatsRequest
+	37, // 56: entities.v1.EntityService.CompactRecords:input_type -> entities.v1.CompactRecordsRequest
+	8,  // 57: entities.v1.EntityService.ListEntities:output_type -> entities.v1.ListEntitiesResponse
+	7,  // 58: entities.v1.EntityService.GetEntity:output_type -> entities.v1.EntityDefinition
+	15, // 59: entities.v1.EntityService.ListRecords:output_type -> entities.v1.ListRecordsResponse
+	11, // 60: entities.v1.EntityService.GetRecord:output_type -> entities.v1.Record
+	11, // 61: entities.v1.EntityService.CreateRecord:output_type -> entities.v1.Record
+	20, // 62: entities.v1.EntityService.BatchCreateRecords:output_type -> entities.v1.BatchCreateRecordsResponse
+	11, // 63: entities.v1.EntityService.UpdateRecord:output_type -> entities.v1.Record
+	47, // 64: entities.v1.EntityService.DeleteRecord:output_type -> google.protobuf.Empty
+	11, // 65: entities.v1.EntityService.RestoreRecord:output_type -> entities.v1.Record
+	26, // 66: entities.v1.EntityService.ListRecordHistory:output_type -> entities.v1.ListRecordHistoryResponse
+	28, // 67: entities.v1.EntityService.StreamEntityChanges:output_type -> entities.v1.EntityChange
+	32, // 68: entities.v1.EntityService.PivotRecords:output_type -> entities.v1.PivotResponse
+	36, // 69: entities.v1.EntityService.RecordStats:output_type -> entities.v1.StatsResponse
+	38, // 70: entities.v1.EntityService.CompactRecords:output_type -> entities.v1.CompactRecordsResponse
+	57, // [57:71] is the sub-list for method output_type
+	43, // [43:57] is the sub-list for method input_type
+	43, // [43:43] is the sub-list for extension type_name
+	43, // [43:43] is the sub-list for extension extendee
+	0,  // [0:43] is the sub-list for field type_name
 }
 
 func init() { file_entities_entities_proto_init() }
@@ -1821,13 +3037,14 @@ func file_entities_entities_proto_init() {
 	if File_entities_entities_proto != nil {
 		return
 	}
+	file_entities_entities_proto_msgTypes[10].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_entities_entities_proto_rawDesc), len(file_entities_entities_proto_rawDesc)),
-			NumEnums:      3,
-			NumMessages:   25,
+			NumEnums:      5,
+			NumMessages:   39,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
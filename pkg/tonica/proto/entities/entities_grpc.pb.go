@@ -20,15 +20,20 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	EntityService_ListEntities_FullMethodName      = "/entities.v1.EntityService/ListEntities"
-	EntityService_GetEntity_FullMethodName         = "/entities.v1.EntityService/GetEntity"
-	EntityService_ListRecords_FullMethodName       = "/entities.v1.EntityService/ListRecords"
-	EntityService_GetRecord_FullMethodName         = "/entities.v1.EntityService/GetRecord"
-	EntityService_CreateRecord_FullMethodName      = "/entities.v1.EntityService/CreateRecord"
-	EntityService_UpdateRecord_FullMethodName      = "/entities.v1.EntityService/UpdateRecord"
-	EntityService_DeleteRecord_FullMethodName      = "/entities.v1.EntityService/DeleteRecord"
-	EntityService_ListRecordHistory_FullMethodName = "/entities.v1.EntityService/ListRecordHistory"
-	EntityService_PivotRecords_FullMethodName      = "/entities.v1.EntityService/PivotRecords"
+	EntityService_ListEntities_FullMethodName        = "/entities.v1.EntityService/ListEntities"
+	EntityService_GetEntity_FullMethodName           = "/entities.v1.EntityService/GetEntity"
+	EntityService_ListRecords_FullMethodName         = "/entities.v1.EntityService/ListRecords"
+	EntityService_GetRecord_FullMethodName           = "/entities.v1.EntityService/GetRecord"
+	EntityService_CreateRecord_FullMethodName        = "/entities.v1.EntityService/CreateRecord"
+	EntityService_BatchCreateRecords_FullMethodName  = "/entities.v1.EntityService/BatchCreateRecords"
+	EntityService_UpdateRecord_FullMethodName        = "/entities.v1.EntityService/UpdateRecord"
+	EntityService_DeleteRecord_FullMethodName        = "/entities.v1.EntityService/DeleteRecord"
+	EntityService_RestoreRecord_FullMethodName       = "/entities.v1.EntityService/RestoreRecord"
+	EntityService_ListRecordHistory_FullMethodName   = "/entities.v1.EntityService/ListRecordHistory"
+	EntityService_StreamEntityChanges_FullMethodName = "/entities.v1.EntityService/StreamEntityChanges"
+	EntityService_PivotRecords_FullMethodName        = "/entities.v1.EntityService/PivotRecords"
+	EntityService_RecordStats_FullMethodName         = "/entities.v1.EntityService/RecordStats"
+	EntityService_CompactRecords_FullMethodName      = "/entities.v1.EntityService/CompactRecords"
 )
 
 // EntityServiceClient is the client API for EntityService service.
@@ -40,10 +45,17 @@ type EntityServiceClient interface {
 	ListRecords(ctx context.Context, in *ListRecordsRequest, opts ...grpc.CallOption) (*ListRecordsResponse, error)
 	GetRecord(ctx context.Context, in *GetRecordRequest, opts ...grpc.CallOption) (*Record, error)
 	CreateRecord(ctx context.Context, in *CreateRecordRequest, opts ...grpc.CallOption) (*Record, error)
+	BatchCreateRecords(ctx context.Context, in *BatchCreateRecordsRequest, opts ...grpc.CallOption) (*BatchCreateRecordsResponse, error)
 	UpdateRecord(ctx context.Context, in *UpdateRecordRequest, opts ...grpc.CallOption) (*Record, error)
 	DeleteRecord(ctx context.Context, in *DeleteRecordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RestoreRecord(ctx context.Context, in *RestoreRecordRequest, opts ...grpc.CallOption) (*Record, error)
 	ListRecordHistory(ctx context.Context, in *ListRecordHistoryRequest, opts ...grpc.CallOption) (*ListRecordHistoryResponse, error)
+	StreamEntityChanges(ctx context.Context, in *StreamEntityChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[EntityChange], error)
 	PivotRecords(ctx context.Context, in *PivotRequest, opts ...grpc.CallOption) (*PivotResponse, error)
+	RecordStats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	// Applies the retention policy of the entity now. Meant for operators;
+	// guard it, e.g. with tonica.WithMethodMiddleware.
+	CompactRecords(ctx context.Context, in *CompactRecordsRequest, opts ...grpc.CallOption) (*CompactRecordsResponse, error)
 }
 
 type entityServiceClient struct {
@@ -104,6 +116,16 @@ func (c *entityServiceClient) CreateRecord(ctx context.Context, in *CreateRecord
 	return out, nil
 }
 
+func (c *entityServiceClient) BatchCreateRecords(ctx context.Context, in *BatchCreateRecordsRequest, opts ...grpc.CallOption) (*BatchCreateRecordsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchCreateRecordsResponse)
+	err := c.cc.Invoke(ctx, EntityService_BatchCreateRecords_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *entityServiceClient) UpdateRecord(ctx context.Context, in *UpdateRecordRequest, opts ...grpc.CallOption) (*Record, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Record)
@@ -124,6 +146,16 @@ func (c *entityServiceClient) DeleteRecord(ctx context.Context, in *DeleteRecord
 	return out, nil
 }
 
+func (c *entityServiceClient) RestoreRecord(ctx context.Context, in *RestoreRecordRequest, opts ...grpc.CallOption) (*Record, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Record)
+	err := c.cc.Invoke(ctx, EntityService_RestoreRecord_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *entityServiceClient) ListRecordHistory(ctx context.Context, in *ListRecordHistoryRequest, opts ...grpc.CallOption) (*ListRecordHistoryResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ListRecordHistoryResponse)
@@ -134,6 +166,25 @@ func (c *entityServiceClient) ListRecordHistory(ctx context.Context, in *ListRec
 	return out, nil
 }
 
+func (c *entityServiceClient) StreamEntityChanges(ctx context.Context, in *StreamEntityChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[EntityChange], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &EntityService_ServiceDesc.Streams[0], EntityService_StreamEntityChanges_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamEntityChangesRequest, EntityChange]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EntityService_StreamEntityChangesClient = grpc.ServerStreamingClient[EntityChange]
+
 func (c *entityServiceClient) PivotRecords(ctx context.Context, in *PivotRequest, opts ...grpc.CallOption) (*PivotResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PivotResponse)
@@ -144,6 +195,26 @@ func (c *entityServiceClient) PivotRecords(ctx context.Context, in *PivotRequest
 	return out, nil
 }
 
+func (c *entityServiceClient) RecordStats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, EntityService_RecordStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *entityServiceClient) CompactRecords(ctx context.Context, in *CompactRecordsRequest, opts ...grpc.CallOption) (*CompactRecordsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompactRecordsResponse)
+	err := c.cc.Invoke(ctx, EntityService_CompactRecords_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // EntityServiceServer is the server API for EntityService service.
 // All implementations must embed UnimplementedEntityServiceServer
 // for forward compatibility.
@@ -153,10 +224,17 @@ type EntityServiceServer interface {
 	ListRecords(context.Context, *ListRecordsRequest) (*ListRecordsResponse, error)
 	GetRecord(context.Context, *GetRecordRequest) (*Record, error)
 	CreateRecord(context.Context, *CreateRecordRequest) (*Record, error)
+	BatchCreateRecords(context.Context, *BatchCreateRecordsRequest) (*BatchCreateRecordsResponse, error)
 	UpdateRecord(context.Context, *UpdateRecordRequest) (*Record, error)
 	DeleteRecord(context.Context, *DeleteRecordRequest) (*emptypb.Empty, error)
+	RestoreRecord(context.Context, *RestoreRecordRequest) (*Record, error)
 	ListRecordHistory(context.Context, *ListRecordHistoryRequest) (*ListRecordHistoryResponse, error)
+	StreamEntityChanges(*StreamEntityChangesRequest, grpc.ServerStreamingServer[EntityChange]) error
 	PivotRecords(context.Context, *PivotRequest) (*PivotResponse, error)
+	RecordStats(context.Context, *StatsRequest) (*StatsResponse, error)
+	// Applies the retention policy of the entity now. Meant for operators;
+	// guard it, e.g. with tonica.WithMethodMiddleware.
+	CompactRecords(context.Context, *CompactRecordsRequest) (*CompactRecordsResponse, error)
 	mustEmbedUnimplementedEntityServiceServer()
 }
 
@@ -182,18 +260,33 @@ func (UnimplementedEntityServiceServer) GetRecord(context.Context, *GetRecordReq
 func (UnimplementedEntityServiceServer) CreateRecord(context.Context, *CreateRecordRequest) (*Record, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateRecord not implemented")
 }
+func (UnimplementedEntityServiceServer) BatchCreateRecords(context.Context, *BatchCreateRecordsRequest) (*BatchCreateRecordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCreateRecords not implemented")
+}
 func (UnimplementedEntityServiceServer) UpdateRecord(context.Context, *UpdateRecordRequest) (*Record, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateRecord not implemented")
 }
 func (UnimplementedEntityServiceServer) DeleteRecord(context.Context, *DeleteRecordRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteRecord not implemented")
 }
+func (UnimplementedEntityServiceServer) RestoreRecord(context.Context, *RestoreRecordRequest) (*Record, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreRecord not implemented")
+}
 func (UnimplementedEntityServiceServer) ListRecordHistory(context.Context, *ListRecordHistoryRequest) (*ListRecordHistoryResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListRecordHistory not implemented")
 }
+func (UnimplementedEntityServiceServer) StreamEntityChanges(*StreamEntityChangesRequest, grpc.ServerStreamingServer[EntityChange]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEntityChanges not implemented")
+}
 func (UnimplementedEntityServiceServer) PivotRecords(context.Context, *PivotRequest) (*PivotResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PivotRecords not implemented")
 }
+func (UnimplementedEntityServiceServer) RecordStats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecordStats not implemented")
+}
+func (UnimplementedEntityServiceServer) CompactRecords(context.Context, *CompactRecordsRequest) (*CompactRecordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompactRecords not implemented")
+}
 func (UnimplementedEntityServiceServer) mustEmbedUnimplementedEntityServiceServer() {}
 func (UnimplementedEntityServiceServer) testEmbeddedByValue()                       {}
 
@@ -305,6 +398,24 @@ func _EntityService_CreateRecord_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EntityService_BatchCreateRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCreateRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EntityServiceServer).BatchCreateRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EntityService_BatchCreateRecords_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EntityServiceServer).BatchCreateRecords(ctx, req.(*BatchCreateRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _EntityService_UpdateRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UpdateRecordRequest)
 	if err := dec(in); err != nil {
@@ -341,6 +452,24 @@ func _EntityService_DeleteRecord_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EntityService_RestoreRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EntityServiceServer).RestoreRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EntityService_RestoreRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EntityServiceServer).RestoreRecord(ctx, req.(*RestoreRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _EntityService_ListRecordHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListRecordHistoryRequest)
 	if err := dec(in); err != nil {
@@ -359,6 +488,17 @@ func _EntityService_ListRecordHistory_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EntityService_StreamEntityChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEntityChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EntityServiceServer).StreamEntityChanges(m, &grpc.GenericServerStream[StreamEntityChangesRequest, EntityChange]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EntityService_StreamEntityChangesServer = grpc.ServerStreamingServer[EntityChange]
+
 func _EntityService_PivotRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PivotRequest)
 	if err := dec(in); err != nil {
@@ -377,6 +517,42 @@ func _EntityService_PivotRecords_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EntityService_RecordStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EntityServiceServer).RecordStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EntityService_RecordStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EntityServiceServer).RecordStats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EntityService_CompactRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompactRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EntityServiceServer).CompactRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EntityService_CompactRecords_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EntityServiceServer).CompactRecords(ctx, req.(*CompactRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // EntityService_ServiceDesc is the grpc.ServiceDesc for EntityService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -404,6 +580,10 @@ var EntityService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateRecord",
 			Handler:    _EntityService_CreateRecord_Handler,
 		},
+		{
+			MethodName: "BatchCreateRecords",
+			Handler:    _EntityService_BatchCreateRecords_Handler,
+		},
 		{
 			MethodName: "UpdateRecord",
 			Handler:    _EntityService_UpdateRecord_Handler,
@@ -412,6 +592,10 @@ var EntityService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteRecord",
 			Handler:    _EntityService_DeleteRecord_Handler,
 		},
+		{
+			MethodName: "RestoreRecord",
+			Handler:    _EntityService_RestoreRecord_Handler,
+		},
 		{
 			MethodName: "ListRecordHistory",
 			Handler:    _EntityService_ListRecordHistory_Handler,
@@ -420,7 +604,21 @@ var EntityService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "PivotRecords",
 			Handler:    _EntityService_PivotRecords_Handler,
 		},
+		{
+			MethodName: "RecordStats",
+			Handler:    _EntityService_RecordStats_Handler,
+		},
+		{
+			MethodName: "CompactRecords",
+			Handler:    _EntityService_CompactRecords_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamEntityChanges",
+			Handler:       _EntityService_StreamEntityChanges_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "entities/entities.proto",
 }
@@ -10,6 +10,7 @@ import (
 	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
 	structpb "google.golang.org/protobuf/types/known/structpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
@@ -25,6 +26,62 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Mirrors Temporal's WorkflowIdReusePolicy.
+type WorkflowIdReusePolicy int32
+
+const (
+	WorkflowIdReusePolicy_WORKFLOW_ID_REUSE_POLICY_UNSPECIFIED                 WorkflowIdReusePolicy = 0
+	WorkflowIdReusePolicy_WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE             WorkflowIdReusePolicy = 1
+	WorkflowIdReusePolicy_WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE_FAILED_ONLY WorkflowIdReusePolicy = 2
+	WorkflowIdReusePolicy_WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE            WorkflowIdReusePolicy = 3
+	WorkflowIdReusePolicy_WORKFLOW_ID_REUSE_POLICY_TERMINATE_IF_RUNNING        WorkflowIdReusePolicy = 4
+)
+
+// Enum value maps for WorkflowIdReusePolicy.
+var (
+	WorkflowIdReusePolicy_name = map[int32]string{
+		0: "WORKFLOW_ID_REUSE_POLICY_UNSPECIFIED",
+		1: "WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE",
+		2: "WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE_FAILED_ONLY",
+		3: "WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE",
+		4: "WORKFLOW_ID_REUSE_POLICY_TERMINATE_IF_RUNNING",
+	}
+	WorkflowIdReusePolicy_value = map[string]int32{
+		"WORKFLOW_ID_REUSE_POLICY_UNSPECIFIED":                 0,
+		"WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE":             1,
+		"WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE_FAILED_ONLY": 2,
+		"WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE":            3,
+		"WORKFLOW_ID_REUSE_POLICY_TERMINATE_IF_RUNNING":        4,
+	}
+)
+
+func (x WorkflowIdReusePolicy) Enum() *WorkflowIdReusePolicy {
+	p := new(WorkflowIdReusePolicy)
+	*p = x
+	return p
+}
+
+func (x WorkflowIdReusePolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WorkflowIdReusePolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_workflows_service_proto_enumTypes[0].Descriptor()
+}
+
+func (WorkflowIdReusePolicy) Type() protoreflect.EnumType {
+	return &file_workflows_service_proto_enumTypes[0]
+}
+
+func (x WorkflowIdReusePolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WorkflowIdReusePolicy.Descriptor instead.
+func (WorkflowIdReusePolicy) EnumDescriptor() ([]byte, []int) {
+	return file_workflows_service_proto_rawDescGZIP(), []int{0}
+}
+
 type WorkflowStatus int32
 
 const (
@@ -73,11 +130,11 @@ func (x WorkflowStatus) String() string {
 }
 
 func (WorkflowStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_workflows_service_proto_enumTypes[0].Descriptor()
+	return file_workflows_service_proto_enumTypes[1].Descriptor()
 }
 
 func (WorkflowStatus) Type() protoreflect.EnumType {
-	return &file_workflows_service_proto_enumTypes[0]
+	return &file_workflows_service_proto_enumTypes[1]
 }
 
 func (x WorkflowStatus) Number() protoreflect.EnumNumber {
@@ -86,7 +143,74 @@ func (x WorkflowStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use WorkflowStatus.Descriptor instead.
 func (WorkflowStatus) EnumDescriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{0}
+	return file_workflows_service_proto_rawDescGZIP(), []int{1}
+}
+
+// Groups of history event types that can be requested together.
+type HistoryEventCategory int32
+
+const (
+	HistoryEventCategory_HISTORY_EVENT_CATEGORY_UNSPECIFIED HistoryEventCategory = 0
+	// Workflow execution lifecycle events other than signals.
+	HistoryEventCategory_HISTORY_EVENT_CATEGORY_WORKFLOW      HistoryEventCategory = 1
+	HistoryEventCategory_HISTORY_EVENT_CATEGORY_WORKFLOW_TASK HistoryEventCategory = 2
+	HistoryEventCategory_HISTORY_EVENT_CATEGORY_ACTIVITY      HistoryEventCategory = 3
+	HistoryEventCategory_HISTORY_EVENT_CATEGORY_TIMER         HistoryEventCategory = 4
+	// Received and sent signals.
+	HistoryEventCategory_HISTORY_EVENT_CATEGORY_SIGNAL         HistoryEventCategory = 5
+	HistoryEventCategory_HISTORY_EVENT_CATEGORY_MARKER         HistoryEventCategory = 6
+	HistoryEventCategory_HISTORY_EVENT_CATEGORY_CHILD_WORKFLOW HistoryEventCategory = 7
+)
+
+// Enum value maps for HistoryEventCategory.
+var (
+	HistoryEventCategory_name = map[int32]string{
+		0: "HISTORY_EVENT_CATEGORY_UNSPECIFIED",
+		1: "HISTORY_EVENT_CATEGORY_WORKFLOW",
+		2: "HISTORY_EVENT_CATEGORY_WORKFLOW_TASK",
+		3: "HISTORY_EVENT_CATEGORY_ACTIVITY",
+		4: "HISTORY_EVENT_CATEGORY_TIMER",
+		5: "HISTORY_EVENT_CATEGORY_SIGNAL",
+		6: "HISTORY_EVENT_CATEGORY_MARKER",
+		7: "HISTORY_EVENT_CATEGORY_CHILD_WORKFLOW",
+	}
+	HistoryEventCategory_value = map[string]int32{
+		"HISTORY_EVENT_CATEGORY_UNSPECIFIED":    0,
+		"HISTORY_EVENT_CATEGORY_WORKFLOW":       1,
+		"HISTORY_EVENT_CATEGORY_WORKFLOW_TASK":  2,
+		"HISTORY_EVENT_CATEGORY_ACTIVITY":       3,
+		"HISTORY_EVENT_CATEGORY_TIMER":          4,
+		"HISTORY_EVENT_CATEGORY_SIGNAL":         5,
+		"HISTORY_EVENT_CATEGORY_MARKER":         6,
+		"HISTORY_EVENT_CATEGORY_CHILD_WORKFLOW": 7,
+	}
+)
+
+func (x HistoryEventCategory) Enum() *HistoryEventCategory {
+	p := new(HistoryEventCategory)
+	*p = x
+	return p
+}
+
+func (x HistoryEventCategory) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HistoryEventCategory) Descriptor() protoreflect.EnumDescriptor {
+	return file_workflows_service_proto_enumTypes[2].Descriptor()
+}
+
+func (HistoryEventCategory) Type() protoreflect.EnumType {
+	return &file_workflows_service_proto_enumTypes[2]
+}
+
+func (x HistoryEventCategory) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HistoryEventCategory.Descriptor instead.
+func (HistoryEventCategory) EnumDescriptor() ([]byte, []int) {
+	return file_workflows_service_proto_rawDescGZIP(), []int{2}
 }
 
 type HistoryEventType int32
@@ -185,11 +309,11 @@ func (x HistoryEventType) String() string {
 }
 
 func (HistoryEventType) Descriptor() protoreflect.EnumDescriptor {
-	return file_workflows_service_proto_enumTypes[1].Descriptor()
+	return file_workflows_service_proto_enumTypes[3].Descriptor()
 }
 
 func (HistoryEventType) Type() protoreflect.EnumType {
-	return &file_workflows_service_proto_enumTypes[1]
+	return &file_workflows_service_proto_enumTypes[3]
 }
 
 func (x HistoryEventType) Number() protoreflect.EnumNumber {
@@ -198,18 +322,25 @@ func (x HistoryEventType) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use HistoryEventType.Descriptor instead.
 func (HistoryEventType) EnumDescriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{1}
+	return file_workflows_service_proto_rawDescGZIP(), []int{3}
 }
 
 type TriggerWorkflowRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Workflow      string                 `protobuf:"bytes,1,opt,name=workflow,proto3" json:"workflow,omitempty"`
-	Entity        string                 `protobuf:"bytes,2,opt,name=entity,proto3" json:"entity,omitempty"`
-	RecordId      string                 `protobuf:"bytes,3,opt,name=record_id,json=recordId,proto3" json:"record_id,omitempty"`
-	Input         map[string]string      `protobuf:"bytes,4,rep,name=input,proto3" json:"input,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Async         *bool                  `protobuf:"varint,5,opt,name=async,proto3,oneof" json:"async,omitempty"` // If true, returns immediately without waiting for completion. Default: false (waits for completion)
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Workflow string                 `protobuf:"bytes,1,opt,name=workflow,proto3" json:"workflow,omitempty"`
+	Entity   string                 `protobuf:"bytes,2,opt,name=entity,proto3" json:"entity,omitempty"`
+	RecordId string                 `protobuf:"bytes,3,opt,name=record_id,json=recordId,proto3" json:"record_id,omitempty"`
+	Input    map[string]string      `protobuf:"bytes,4,rep,name=input,proto3" json:"input,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Async    *bool                  `protobuf:"varint,5,opt,name=async,proto3,oneof" json:"async,omitempty"` // If true, returns immediately without waiting for completion. Default: false (waits for completion)
+	// Task queue to run the workflow on. Defaults to the service's default queue.
+	TaskQueue string `protobuf:"bytes,6,opt,name=task_queue,json=taskQueue,proto3" json:"task_queue,omitempty"`
+	// Non-indexed metadata attached to the execution.
+	Memo *structpb.Struct `protobuf:"bytes,7,opt,name=memo,proto3" json:"memo,omitempty"`
+	// Search attributes attached to the execution. Keys must be registered in
+	// the namespace.
+	SearchAttributes *structpb.Struct `protobuf:"bytes,8,opt,name=search_attributes,json=searchAttributes,proto3" json:"search_attributes,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *TriggerWorkflowRequest) Reset() {
@@ -277,6 +408,27 @@ func (x *TriggerWorkflowRequest) GetAsync() bool {
 	return false
 }
 
+func (x *TriggerWorkflowRequest) GetTaskQueue() string {
+	if x != nil {
+		return x.TaskQueue
+	}
+	return ""
+}
+
+func (x *TriggerWorkflowRequest) GetMemo() *structpb.Struct {
+	if x != nil {
+		return x.Memo
+	}
+	return nil
+}
+
+func (x *TriggerWorkflowRequest) GetSearchAttributes() *structpb.Struct {
+	if x != nil {
+		return x.SearchAttributes
+	}
+	return nil
+}
+
 type TriggerWorkflowResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ExecutionId   string                 `protobuf:"bytes,1,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`
@@ -329,6 +481,268 @@ func (x *TriggerWorkflowResponse) GetStatus() string {
 	return ""
 }
 
+type RetryPolicy struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	InitialInterval        *durationpb.Duration   `protobuf:"bytes,1,opt,name=initial_interval,json=initialInterval,proto3" json:"initial_interval,omitempty"`
+	BackoffCoefficient     float64                `protobuf:"fixed64,2,opt,name=backoff_coefficient,json=backoffCoefficient,proto3" json:"backoff_coefficient,omitempty"`
+	MaximumInterval        *durationpb.Duration   `protobuf:"bytes,3,opt,name=maximum_interval,json=maximumInterval,proto3" json:"maximum_interval,omitempty"`
+	MaximumAttempts        int32                  `protobuf:"varint,4,opt,name=maximum_attempts,json=maximumAttempts,proto3" json:"maximum_attempts,omitempty"` // 0 means unlimited
+	NonRetryableErrorTypes []string               `protobuf:"bytes,5,rep,name=non_retryable_error_types,json=nonRetryableErrorTypes,proto3" json:"non_retryable_error_types,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *RetryPolicy) Reset() {
+	*x = RetryPolicy{}
+	mi := &file_workflows_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryPolicy) ProtoMessage() {}
+
+func (x *RetryPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_workflows_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryPolicy.ProtoReflect.Descriptor instead.
+func (*RetryPolicy) Descriptor() ([]byte, []int) {
+	return file_workflows_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RetryPolicy) GetInitialInterval() *durationpb.Duration {
+	if x != nil {
+		return x.InitialInterval
+	}
+	return nil
+}
+
+func (x *RetryPolicy) GetBackoffCoefficient() float64 {
+	if x != nil {
+		return x.BackoffCoefficient
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetMaximumInterval() *durationpb.Duration {
+	if x != nil {
+		return x.MaximumInterval
+	}
+	return nil
+}
+
+func (x *RetryPolicy) GetMaximumAttempts() int32 {
+	if x != nil {
+		return x.MaximumAttempts
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetNonRetryableErrorTypes() []string {
+	if x != nil {
+		return x.NonRetryableErrorTypes
+	}
+	return nil
+}
+
+type StartWorkflowRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Namespace    string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	WorkflowType string                 `protobuf:"bytes,2,opt,name=workflow_type,json=workflowType,proto3" json:"workflow_type,omitempty"`
+	// Defaults to the service's default queue.
+	TaskQueue        string                `protobuf:"bytes,3,opt,name=task_queue,json=taskQueue,proto3" json:"task_queue,omitempty"`
+	WorkflowId       string                `protobuf:"bytes,4,opt,name=workflow_id,json=workflowId,proto3" json:"workflow_id,omitempty"` // Generated when empty
+	Input            *structpb.Struct      `protobuf:"bytes,5,opt,name=input,proto3" json:"input,omitempty"`
+	ExecutionTimeout *durationpb.Duration  `protobuf:"bytes,6,opt,name=execution_timeout,json=executionTimeout,proto3" json:"execution_timeout,omitempty"`
+	RunTimeout       *durationpb.Duration  `protobuf:"bytes,7,opt,name=run_timeout,json=runTimeout,proto3" json:"run_timeout,omitempty"`
+	TaskTimeout      *durationpb.Duration  `protobuf:"bytes,8,opt,name=task_timeout,json=taskTimeout,proto3" json:"task_timeout,omitempty"`
+	IdReusePolicy    WorkflowIdReusePolicy `protobuf:"varint,9,opt,name=id_reuse_policy,json=idReusePolicy,proto3,enum=workflow.v1.WorkflowIdReusePolicy" json:"id_reuse_policy,omitempty"`
+	RetryPolicy      *RetryPolicy          `protobuf:"bytes,10,opt,name=retry_policy,json=retryPolicy,proto3" json:"retry_policy,omitempty"`
+	Memo             *structpb.Struct      `protobuf:"bytes,11,opt,name=memo,proto3" json:"memo,omitempty"`
+	// Keys must be registered in the namespace.
+	SearchAttributes *structpb.Struct `protobuf:"bytes,12,opt,name=search_attributes,json=searchAttributes,proto3" json:"search_attributes,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *StartWorkflowRequest) Reset() {
+	*x = StartWorkflowRequest{}
+	mi := &file_workflows_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartWorkflowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartWorkflowRequest) ProtoMessage() {}
+
+func (x *StartWorkflowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workflows_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartWorkflowRequest.ProtoReflect.Descriptor instead.
+func (*StartWorkflowRequest) Descriptor() ([]byte, []int) {
+	return file_workflows_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StartWorkflowRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *StartWorkflowRequest) GetWorkflowType() string {
+	if x != nil {
+		return x.WorkflowType
+	}
+	return ""
+}
+
+func (x *StartWorkflowRequest) GetTaskQueue() string {
+	if x != nil {
+		return x.TaskQueue
+	}
+	return ""
+}
+
+func (x *StartWorkflowRequest) GetWorkflowId() string {
+	if x != nil {
+		return x.WorkflowId
+	}
+	return ""
+}
+
+func (x *StartWorkflowRequest) GetInput() *structpb.Struct {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+func (x *StartWorkflowRequest) GetExecutionTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.ExecutionTimeout
+	}
+	return nil
+}
+
+func (x *StartWorkflowRequest) GetRunTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.RunTimeout
+	}
+	return nil
+}
+
+func (x *StartWorkflowRequest) GetTaskTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.TaskTimeout
+	}
+	return nil
+}
+
+func (x *StartWorkflowRequest) GetIdReusePolicy() WorkflowIdReusePolicy {
+	if x != nil {
+		return x.IdReusePolicy
+	}
+	return WorkflowIdReusePolicy_WORKFLOW_ID_REUSE_POLICY_UNSPECIFIED
+}
+
+func (x *StartWorkflowRequest) GetRetryPolicy() *RetryPolicy {
+	if x != nil {
+		return x.RetryPolicy
+	}
+	return nil
+}
+
+func (x *StartWorkflowRequest) GetMemo() *structpb.Struct {
+	if x != nil {
+		return x.Memo
+	}
+	return nil
+}
+
+func (x *StartWorkflowRequest) GetSearchAttributes() *structpb.Struct {
+	if x != nil {
+		return x.SearchAttributes
+	}
+	return nil
+}
+
+type StartWorkflowResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WorkflowId    string                 `protobuf:"bytes,1,opt,name=workflow_id,json=workflowId,proto3" json:"workflow_id,omitempty"`
+	RunId         string                 `protobuf:"bytes,2,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartWorkflowResponse) Reset() {
+	*x = StartWorkflowResponse{}
+	mi := &file_workflows_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartWorkflowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartWorkflowResponse) ProtoMessage() {}
+
+func (x *StartWorkflowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_workflows_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartWorkflowResponse.ProtoReflect.Descriptor instead.
+func (*StartWorkflowResponse) Descriptor() ([]byte, []int) {
+	return file_workflows_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StartWorkflowResponse) GetWorkflowId() string {
+	if x != nil {
+		return x.WorkflowId
+	}
+	return ""
+}
+
+func (x *StartWorkflowResponse) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
 type ListNamespacesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -337,7 +751,7 @@ type ListNamespacesRequest struct {
 
 func (x *ListNamespacesRequest) Reset() {
 	*x = ListNamespacesRequest{}
-	mi := &file_workflows_service_proto_msgTypes[2]
+	mi := &file_workflows_service_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -349,7 +763,7 @@ func (x *ListNamespacesRequest) String() string {
 func (*ListNamespacesRequest) ProtoMessage() {}
 
 func (x *ListNamespacesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[2]
+	mi := &file_workflows_service_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -362,7 +776,7 @@ func (x *ListNamespacesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListNamespacesRequest.ProtoReflect.Descriptor instead.
 func (*ListNamespacesRequest) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{2}
+	return file_workflows_service_proto_rawDescGZIP(), []int{5}
 }
 
 type Namespace struct {
@@ -375,7 +789,7 @@ type Namespace struct {
 
 func (x *Namespace) Reset() {
 	*x = Namespace{}
-	mi := &file_workflows_service_proto_msgTypes[3]
+	mi := &file_workflows_service_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -387,7 +801,7 @@ func (x *Namespace) String() string {
 func (*Namespace) ProtoMessage() {}
 
 func (x *Namespace) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[3]
+	mi := &file_workflows_service_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -400,7 +814,7 @@ func (x *Namespace) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Namespace.ProtoReflect.Descriptor instead.
 func (*Namespace) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{3}
+	return file_workflows_service_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *Namespace) GetName() string {
@@ -426,7 +840,7 @@ type ListNamespacesResponse struct {
 
 func (x *ListNamespacesResponse) Reset() {
 	*x = ListNamespacesResponse{}
-	mi := &file_workflows_service_proto_msgTypes[4]
+	mi := &file_workflows_service_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -438,7 +852,7 @@ func (x *ListNamespacesResponse) String() string {
 func (*ListNamespacesResponse) ProtoMessage() {}
 
 func (x *ListNamespacesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[4]
+	mi := &file_workflows_service_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -451,7 +865,7 @@ func (x *ListNamespacesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListNamespacesResponse.ProtoReflect.Descriptor instead.
 func (*ListNamespacesResponse) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{4}
+	return file_workflows_service_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ListNamespacesResponse) GetNamespaces() []*Namespace {
@@ -475,7 +889,7 @@ type ListWorkflowsRequest struct {
 
 func (x *ListWorkflowsRequest) Reset() {
 	*x = ListWorkflowsRequest{}
-	mi := &file_workflows_service_proto_msgTypes[5]
+	mi := &file_workflows_service_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -487,7 +901,7 @@ func (x *ListWorkflowsRequest) String() string {
 func (*ListWorkflowsRequest) ProtoMessage() {}
 
 func (x *ListWorkflowsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[5]
+	mi := &file_workflows_service_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -500,7 +914,7 @@ func (x *ListWorkflowsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListWorkflowsRequest.ProtoReflect.Descriptor instead.
 func (*ListWorkflowsRequest) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{5}
+	return file_workflows_service_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ListWorkflowsRequest) GetNamespace() string {
@@ -564,7 +978,7 @@ type WorkflowExecution struct {
 
 func (x *WorkflowExecution) Reset() {
 	*x = WorkflowExecution{}
-	mi := &file_workflows_service_proto_msgTypes[6]
+	mi := &file_workflows_service_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -576,7 +990,7 @@ func (x *WorkflowExecution) String() string {
 func (*WorkflowExecution) ProtoMessage() {}
 
 func (x *WorkflowExecution) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[6]
+	mi := &file_workflows_service_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -589,7 +1003,7 @@ func (x *WorkflowExecution) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WorkflowExecution.ProtoReflect.Descriptor instead.
 func (*WorkflowExecution) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{6}
+	return file_workflows_service_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *WorkflowExecution) GetWorkflowId() string {
@@ -679,7 +1093,7 @@ type ListWorkflowsResponse struct {
 
 func (x *ListWorkflowsResponse) Reset() {
 	*x = ListWorkflowsResponse{}
-	mi := &file_workflows_service_proto_msgTypes[7]
+	mi := &file_workflows_service_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -691,7 +1105,7 @@ func (x *ListWorkflowsResponse) String() string {
 func (*ListWorkflowsResponse) ProtoMessage() {}
 
 func (x *ListWorkflowsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[7]
+	mi := &file_workflows_service_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -704,7 +1118,7 @@ func (x *ListWorkflowsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListWorkflowsResponse.ProtoReflect.Descriptor instead.
 func (*ListWorkflowsResponse) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{7}
+	return file_workflows_service_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *ListWorkflowsResponse) GetExecutions() []*WorkflowExecution {
@@ -732,7 +1146,7 @@ type GetWorkflowRequest struct {
 
 func (x *GetWorkflowRequest) Reset() {
 	*x = GetWorkflowRequest{}
-	mi := &file_workflows_service_proto_msgTypes[8]
+	mi := &file_workflows_service_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -744,7 +1158,7 @@ func (x *GetWorkflowRequest) String() string {
 func (*GetWorkflowRequest) ProtoMessage() {}
 
 func (x *GetWorkflowRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[8]
+	mi := &file_workflows_service_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -757,7 +1171,7 @@ func (x *GetWorkflowRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetWorkflowRequest.ProtoReflect.Descriptor instead.
 func (*GetWorkflowRequest) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{8}
+	return file_workflows_service_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *GetWorkflowRequest) GetNamespace() string {
@@ -795,7 +1209,7 @@ type WorkflowDetails struct {
 
 func (x *WorkflowDetails) Reset() {
 	*x = WorkflowDetails{}
-	mi := &file_workflows_service_proto_msgTypes[9]
+	mi := &file_workflows_service_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -807,7 +1221,7 @@ func (x *WorkflowDetails) String() string {
 func (*WorkflowDetails) ProtoMessage() {}
 
 func (x *WorkflowDetails) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[9]
+	mi := &file_workflows_service_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -820,7 +1234,7 @@ func (x *WorkflowDetails) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WorkflowDetails.ProtoReflect.Descriptor instead.
 func (*WorkflowDetails) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{9}
+	return file_workflows_service_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *WorkflowDetails) GetExecution() *WorkflowExecution {
@@ -878,7 +1292,7 @@ type PendingActivity struct {
 
 func (x *PendingActivity) Reset() {
 	*x = PendingActivity{}
-	mi := &file_workflows_service_proto_msgTypes[10]
+	mi := &file_workflows_service_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -890,7 +1304,7 @@ func (x *PendingActivity) String() string {
 func (*PendingActivity) ProtoMessage() {}
 
 func (x *PendingActivity) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[10]
+	mi := &file_workflows_service_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -903,7 +1317,7 @@ func (x *PendingActivity) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PendingActivity.ProtoReflect.Descriptor instead.
 func (*PendingActivity) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{10}
+	return file_workflows_service_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *PendingActivity) GetActivityId() string {
@@ -942,19 +1356,22 @@ func (x *PendingActivity) GetLastHeartbeatTime() *timestamppb.Timestamp {
 }
 
 type GetWorkflowHistoryRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
-	WorkflowId    string                 `protobuf:"bytes,2,opt,name=workflow_id,json=workflowId,proto3" json:"workflow_id,omitempty"`
-	RunId         string                 `protobuf:"bytes,3,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"` // Optional
-	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
-	PageToken     string                 `protobuf:"bytes,5,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Namespace  string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	WorkflowId string                 `protobuf:"bytes,2,opt,name=workflow_id,json=workflowId,proto3" json:"workflow_id,omitempty"`
+	RunId      string                 `protobuf:"bytes,3,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"` // Optional
+	PageSize   int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken  string                 `protobuf:"bytes,5,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Only return events of these categories. Empty returns every event.
+	// Filtered pages may hold fewer than page_size events.
+	Categories    []HistoryEventCategory `protobuf:"varint,6,rep,packed,name=categories,proto3,enum=workflow.v1.HistoryEventCategory" json:"categories,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetWorkflowHistoryRequest) Reset() {
 	*x = GetWorkflowHistoryRequest{}
-	mi := &file_workflows_service_proto_msgTypes[11]
+	mi := &file_workflows_service_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -966,7 +1383,7 @@ func (x *GetWorkflowHistoryRequest) String() string {
 func (*GetWorkflowHistoryRequest) ProtoMessage() {}
 
 func (x *GetWorkflowHistoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[11]
+	mi := &file_workflows_service_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -979,7 +1396,7 @@ func (x *GetWorkflowHistoryRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetWorkflowHistoryRequest.ProtoReflect.Descriptor instead.
 func (*GetWorkflowHistoryRequest) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{11}
+	return file_workflows_service_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *GetWorkflowHistoryRequest) GetNamespace() string {
@@ -1017,6 +1434,13 @@ func (x *GetWorkflowHistoryRequest) GetPageToken() string {
 	return ""
 }
 
+func (x *GetWorkflowHistoryRequest) GetCategories() []HistoryEventCategory {
+	if x != nil {
+		return x.Categories
+	}
+	return nil
+}
+
 type HistoryEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	EventId       int64                  `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
@@ -1031,7 +1455,7 @@ type HistoryEvent struct {
 
 func (x *HistoryEvent) Reset() {
 	*x = HistoryEvent{}
-	mi := &file_workflows_service_proto_msgTypes[12]
+	mi := &file_workflows_service_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1043,7 +1467,7 @@ func (x *HistoryEvent) String() string {
 func (*HistoryEvent) ProtoMessage() {}
 
 func (x *HistoryEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[12]
+	mi := &file_workflows_service_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1056,7 +1480,7 @@ func (x *HistoryEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HistoryEvent.ProtoReflect.Descriptor instead.
 func (*HistoryEvent) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{12}
+	return file_workflows_service_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *HistoryEvent) GetEventId() int64 {
@@ -1111,7 +1535,7 @@ type GetWorkflowHistoryResponse struct {
 
 func (x *GetWorkflowHistoryResponse) Reset() {
 	*x = GetWorkflowHistoryResponse{}
-	mi := &file_workflows_service_proto_msgTypes[13]
+	mi := &file_workflows_service_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1123,7 +1547,7 @@ func (x *GetWorkflowHistoryResponse) String() string {
 func (*GetWorkflowHistoryResponse) ProtoMessage() {}
 
 func (x *GetWorkflowHistoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[13]
+	mi := &file_workflows_service_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1136,7 +1560,7 @@ func (x *GetWorkflowHistoryResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetWorkflowHistoryResponse.ProtoReflect.Descriptor instead.
 func (*GetWorkflowHistoryResponse) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{13}
+	return file_workflows_service_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *GetWorkflowHistoryResponse) GetHistory() []*HistoryEvent {
@@ -1165,7 +1589,7 @@ type TerminateWorkflowRequest struct {
 
 func (x *TerminateWorkflowRequest) Reset() {
 	*x = TerminateWorkflowRequest{}
-	mi := &file_workflows_service_proto_msgTypes[14]
+	mi := &file_workflows_service_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1177,7 +1601,7 @@ func (x *TerminateWorkflowRequest) String() string {
 func (*TerminateWorkflowRequest) ProtoMessage() {}
 
 func (x *TerminateWorkflowRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[14]
+	mi := &file_workflows_service_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1190,7 +1614,7 @@ func (x *TerminateWorkflowRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TerminateWorkflowRequest.ProtoReflect.Descriptor instead.
 func (*TerminateWorkflowRequest) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{14}
+	return file_workflows_service_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *TerminateWorkflowRequest) GetNamespace() string {
@@ -1232,7 +1656,7 @@ type CancelWorkflowRequest struct {
 
 func (x *CancelWorkflowRequest) Reset() {
 	*x = CancelWorkflowRequest{}
-	mi := &file_workflows_service_proto_msgTypes[15]
+	mi := &file_workflows_service_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1244,7 +1668,7 @@ func (x *CancelWorkflowRequest) String() string {
 func (*CancelWorkflowRequest) ProtoMessage() {}
 
 func (x *CancelWorkflowRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[15]
+	mi := &file_workflows_service_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1257,7 +1681,7 @@ func (x *CancelWorkflowRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CancelWorkflowRequest.ProtoReflect.Descriptor instead.
 func (*CancelWorkflowRequest) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{15}
+	return file_workflows_service_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *CancelWorkflowRequest) GetNamespace() string {
@@ -1294,7 +1718,7 @@ type SignalWorkflowRequest struct {
 
 func (x *SignalWorkflowRequest) Reset() {
 	*x = SignalWorkflowRequest{}
-	mi := &file_workflows_service_proto_msgTypes[16]
+	mi := &file_workflows_service_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1306,7 +1730,7 @@ func (x *SignalWorkflowRequest) String() string {
 func (*SignalWorkflowRequest) ProtoMessage() {}
 
 func (x *SignalWorkflowRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[16]
+	mi := &file_workflows_service_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1319,7 +1743,7 @@ func (x *SignalWorkflowRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SignalWorkflowRequest.ProtoReflect.Descriptor instead.
 func (*SignalWorkflowRequest) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{16}
+	return file_workflows_service_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *SignalWorkflowRequest) GetNamespace() string {
@@ -1368,7 +1792,7 @@ type RestartWorkflowRequest struct {
 
 func (x *RestartWorkflowRequest) Reset() {
 	*x = RestartWorkflowRequest{}
-	mi := &file_workflows_service_proto_msgTypes[17]
+	mi := &file_workflows_service_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1380,7 +1804,7 @@ func (x *RestartWorkflowRequest) String() string {
 func (*RestartWorkflowRequest) ProtoMessage() {}
 
 func (x *RestartWorkflowRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[17]
+	mi := &file_workflows_service_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1393,7 +1817,7 @@ func (x *RestartWorkflowRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RestartWorkflowRequest.ProtoReflect.Descriptor instead.
 func (*RestartWorkflowRequest) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{17}
+	return file_workflows_service_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *RestartWorkflowRequest) GetNamespace() string {
@@ -1427,7 +1851,7 @@ type RestartWorkflowResponse struct {
 
 func (x *RestartWorkflowResponse) Reset() {
 	*x = RestartWorkflowResponse{}
-	mi := &file_workflows_service_proto_msgTypes[18]
+	mi := &file_workflows_service_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1439,7 +1863,7 @@ func (x *RestartWorkflowResponse) String() string {
 func (*RestartWorkflowResponse) ProtoMessage() {}
 
 func (x *RestartWorkflowResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[18]
+	mi := &file_workflows_service_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1452,7 +1876,7 @@ func (x *RestartWorkflowResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RestartWorkflowResponse.ProtoReflect.Descriptor instead.
 func (*RestartWorkflowResponse) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{18}
+	return file_workflows_service_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *RestartWorkflowResponse) GetWorkflowId() string {
@@ -1480,7 +1904,7 @@ type ListSchedulesRequest struct {
 
 func (x *ListSchedulesRequest) Reset() {
 	*x = ListSchedulesRequest{}
-	mi := &file_workflows_service_proto_msgTypes[19]
+	mi := &file_workflows_service_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1492,7 +1916,7 @@ func (x *ListSchedulesRequest) String() string {
 func (*ListSchedulesRequest) ProtoMessage() {}
 
 func (x *ListSchedulesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[19]
+	mi := &file_workflows_service_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1505,7 +1929,7 @@ func (x *ListSchedulesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListSchedulesRequest.ProtoReflect.Descriptor instead.
 func (*ListSchedulesRequest) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{19}
+	return file_workflows_service_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *ListSchedulesRequest) GetNamespace() string {
@@ -1545,7 +1969,7 @@ type Schedule struct {
 
 func (x *Schedule) Reset() {
 	*x = Schedule{}
-	mi := &file_workflows_service_proto_msgTypes[20]
+	mi := &file_workflows_service_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1557,7 +1981,7 @@ func (x *Schedule) String() string {
 func (*Schedule) ProtoMessage() {}
 
 func (x *Schedule) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[20]
+	mi := &file_workflows_service_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1570,7 +1994,7 @@ func (x *Schedule) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Schedule.ProtoReflect.Descriptor instead.
 func (*Schedule) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{20}
+	return file_workflows_service_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *Schedule) GetScheduleId() string {
@@ -1639,7 +2063,7 @@ type ListSchedulesResponse struct {
 
 func (x *ListSchedulesResponse) Reset() {
 	*x = ListSchedulesResponse{}
-	mi := &file_workflows_service_proto_msgTypes[21]
+	mi := &file_workflows_service_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1651,7 +2075,7 @@ func (x *ListSchedulesResponse) String() string {
 func (*ListSchedulesResponse) ProtoMessage() {}
 
 func (x *ListSchedulesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[21]
+	mi := &file_workflows_service_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1664,7 +2088,7 @@ func (x *ListSchedulesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListSchedulesResponse.ProtoReflect.Descriptor instead.
 func (*ListSchedulesResponse) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{21}
+	return file_workflows_service_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *ListSchedulesResponse) GetSchedules() []*Schedule {
@@ -1691,7 +2115,7 @@ type GetScheduleRequest struct {
 
 func (x *GetScheduleRequest) Reset() {
 	*x = GetScheduleRequest{}
-	mi := &file_workflows_service_proto_msgTypes[22]
+	mi := &file_workflows_service_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1703,7 +2127,7 @@ func (x *GetScheduleRequest) String() string {
 func (*GetScheduleRequest) ProtoMessage() {}
 
 func (x *GetScheduleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[22]
+	mi := &file_workflows_service_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1716,7 +2140,7 @@ func (x *GetScheduleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetScheduleRequest.ProtoReflect.Descriptor instead.
 func (*GetScheduleRequest) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{22}
+	return file_workflows_service_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *GetScheduleRequest) GetNamespace() string {
@@ -1744,7 +2168,7 @@ type PauseScheduleRequest struct {
 
 func (x *PauseScheduleRequest) Reset() {
 	*x = PauseScheduleRequest{}
-	mi := &file_workflows_service_proto_msgTypes[23]
+	mi := &file_workflows_service_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1756,7 +2180,7 @@ func (x *PauseScheduleRequest) String() string {
 func (*PauseScheduleRequest) ProtoMessage() {}
 
 func (x *PauseScheduleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[23]
+	mi := &file_workflows_service_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1769,7 +2193,7 @@ func (x *PauseScheduleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PauseScheduleRequest.ProtoReflect.Descriptor instead.
 func (*PauseScheduleRequest) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{23}
+	return file_workflows_service_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *PauseScheduleRequest) GetNamespace() string {
@@ -1804,7 +2228,7 @@ type UnpauseScheduleRequest struct {
 
 func (x *UnpauseScheduleRequest) Reset() {
 	*x = UnpauseScheduleRequest{}
-	mi := &file_workflows_service_proto_msgTypes[24]
+	mi := &file_workflows_service_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1816,7 +2240,7 @@ func (x *UnpauseScheduleRequest) String() string {
 func (*UnpauseScheduleRequest) ProtoMessage() {}
 
 func (x *UnpauseScheduleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[24]
+	mi := &file_workflows_service_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1829,7 +2253,7 @@ func (x *UnpauseScheduleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UnpauseScheduleRequest.ProtoReflect.Descriptor instead.
 func (*UnpauseScheduleRequest) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{24}
+	return file_workflows_service_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *UnpauseScheduleRequest) GetNamespace() string {
@@ -1863,7 +2287,7 @@ type TriggerScheduleRequest struct {
 
 func (x *TriggerScheduleRequest) Reset() {
 	*x = TriggerScheduleRequest{}
-	mi := &file_workflows_service_proto_msgTypes[25]
+	mi := &file_workflows_service_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1875,7 +2299,7 @@ func (x *TriggerScheduleRequest) String() string {
 func (*TriggerScheduleRequest) ProtoMessage() {}
 
 func (x *TriggerScheduleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_workflows_service_proto_msgTypes[25]
+	mi := &file_workflows_service_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1888,7 +2312,7 @@ func (x *TriggerScheduleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TriggerScheduleRequest.ProtoReflect.Descriptor instead.
 func (*TriggerScheduleRequest) Descriptor() ([]byte, []int) {
-	return file_workflows_service_proto_rawDescGZIP(), []int{25}
+	return file_workflows_service_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *TriggerScheduleRequest) GetNamespace() string {
@@ -1909,13 +2333,17 @@ var File_workflows_service_proto protoreflect.FileDescriptor
 
 const file_workflows_service_proto_rawDesc = "" +
 	"\n" +
-	"\x17workflows/service.proto\x12\vworkflow.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1bgoogle/protobuf/empty.proto\"\x8e\x02\n" +
+	"\x17workflows/service.proto\x12\vworkflow.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1egoogle/protobuf/duration.proto\"\xa0\x03\n" +
 	"\x16TriggerWorkflowRequest\x12\x1a\n" +
 	"\bworkflow\x18\x01 \x01(\tR\bworkflow\x12\x16\n" +
 	"\x06entity\x18\x02 \x01(\tR\x06entity\x12\x1b\n" +
 	"\trecord_id\x18\x03 \x01(\tR\brecordId\x12D\n" +
 	"\x05input\x18\x04 \x03(\v2..workflow.v1.TriggerWorkflowRequest.InputEntryR\x05input\x12\x19\n" +
-	"\x05async\x18\x05 \x01(\bH\x00R\x05async\x88\x01\x01\x1a8\n" +
+	"\x05async\x18\x05 \x01(\bH\x00R\x05async\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"task_queue\x18\x06 \x01(\tR\ttaskQueue\x12+\n" +
+	"\x04memo\x18\a \x01(\v2\x17.google.protobuf.StructR\x04memo\x12D\n" +
+	"\x11search_attributes\x18\b \x01(\v2\x17.google.protobuf.StructR\x10searchAttributes\x1a8\n" +
 	"\n" +
 	"InputEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
@@ -1923,7 +2351,34 @@ const file_workflows_service_proto_rawDesc = "" +
 	"\x06_async\"T\n" +
 	"\x17TriggerWorkflowResponse\x12!\n" +
 	"\fexecution_id\x18\x01 \x01(\tR\vexecutionId\x12\x16\n" +
-	"\x06status\x18\x02 \x01(\tR\x06status\"\x17\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"\xb0\x02\n" +
+	"\vRetryPolicy\x12D\n" +
+	"\x10initial_interval\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\x0finitialInterval\x12/\n" +
+	"\x13backoff_coefficient\x18\x02 \x01(\x01R\x12backoffCoefficient\x12D\n" +
+	"\x10maximum_interval\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x0fmaximumInterval\x12)\n" +
+	"\x10maximum_attempts\x18\x04 \x01(\x05R\x0fmaximumAttempts\x129\n" +
+	"\x19non_retryable_error_types\x18\x05 \x03(\tR\x16nonRetryableErrorTypes\"\x86\x05\n" +
+	"\x14StartWorkflowRequest\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12#\n" +
+	"\rworkflow_type\x18\x02 \x01(\tR\fworkflowType\x12\x1d\n" +
+	"\n" +
+	"task_queue\x18\x03 \x01(\tR\ttaskQueue\x12\x1f\n" +
+	"\vworkflow_id\x18\x04 \x01(\tR\n" +
+	"workflowId\x12-\n" +
+	"\x05input\x18\x05 \x01(\v2\x17.google.protobuf.StructR\x05input\x12F\n" +
+	"\x11execution_timeout\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\x10executionTimeout\x12:\n" +
+	"\vrun_timeout\x18\a \x01(\v2\x19.google.protobuf.DurationR\n" +
+	"runTimeout\x12<\n" +
+	"\ftask_timeout\x18\b \x01(\v2\x19.google.protobuf.DurationR\vtaskTimeout\x12J\n" +
+	"\x0fid_reuse_policy\x18\t \x01(\x0e2\".workflow.v1.WorkflowIdReusePolicyR\ridReusePolicy\x12;\n" +
+	"\fretry_policy\x18\n" +
+	" \x01(\v2\x18.workflow.v1.RetryPolicyR\vretryPolicy\x12+\n" +
+	"\x04memo\x18\v \x01(\v2\x17.google.protobuf.StructR\x04memo\x12D\n" +
+	"\x11search_attributes\x18\f \x01(\v2\x17.google.protobuf.StructR\x10searchAttributes\"O\n" +
+	"\x15StartWorkflowResponse\x12\x1f\n" +
+	"\vworkflow_id\x18\x01 \x01(\tR\n" +
+	"workflowId\x12\x15\n" +
+	"\x06run_id\x18\x02 \x01(\tR\x05runId\"\x17\n" +
 	"\x15ListNamespacesRequest\"A\n" +
 	"\tNamespace\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
@@ -1979,7 +2434,7 @@ const file_workflows_service_proto_rawDesc = "" +
 	"\ractivity_type\x18\x02 \x01(\tR\factivityType\x12A\n" +
 	"\x0escheduled_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\rscheduledTime\x12\x18\n" +
 	"\aattempt\x18\x04 \x01(\x05R\aattempt\x12J\n" +
-	"\x13last_heartbeat_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x11lastHeartbeatTime\"\xad\x01\n" +
+	"\x13last_heartbeat_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x11lastHeartbeatTime\"\xf0\x01\n" +
 	"\x19GetWorkflowHistoryRequest\x12\x1c\n" +
 	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x1f\n" +
 	"\vworkflow_id\x18\x02 \x01(\tR\n" +
@@ -1987,7 +2442,10 @@ const file_workflows_service_proto_rawDesc = "" +
 	"\x06run_id\x18\x03 \x01(\tR\x05runId\x12\x1b\n" +
 	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\x12\x1d\n" +
 	"\n" +
-	"page_token\x18\x05 \x01(\tR\tpageToken\"\x93\x02\n" +
+	"page_token\x18\x05 \x01(\tR\tpageToken\x12A\n" +
+	"\n" +
+	"categories\x18\x06 \x03(\x0e2!.workflow.v1.HistoryEventCategoryR\n" +
+	"categories\"\x93\x02\n" +
 	"\fHistoryEvent\x12\x19\n" +
 	"\bevent_id\x18\x01 \x01(\x03R\aeventId\x129\n" +
 	"\n" +
@@ -2066,7 +2524,13 @@ const file_workflows_service_proto_rawDesc = "" +
 	"\x16TriggerScheduleRequest\x12\x1c\n" +
 	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x1f\n" +
 	"\vschedule_id\x18\x02 \x01(\tR\n" +
-	"scheduleId*\x8c\x02\n" +
+	"scheduleId*\x8b\x02\n" +
+	"\x15WorkflowIdReusePolicy\x12(\n" +
+	"$WORKFLOW_ID_REUSE_POLICY_UNSPECIFIED\x10\x00\x12,\n" +
+	"(WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE\x10\x01\x128\n" +
+	"4WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE_FAILED_ONLY\x10\x02\x12-\n" +
+	")WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE\x10\x03\x121\n" +
+	"-WORKFLOW_ID_REUSE_POLICY_TERMINATE_IF_RUNNING\x10\x04*\x8c\x02\n" +
 	"\x0eWorkflowStatus\x12\x1f\n" +
 	"\x1bWORKFLOW_STATUS_UNSPECIFIED\x10\x00\x12\x1b\n" +
 	"\x17WORKFLOW_STATUS_RUNNING\x10\x01\x12\x1d\n" +
@@ -2075,7 +2539,16 @@ const file_workflows_service_proto_rawDesc = "" +
 	"\x18WORKFLOW_STATUS_CANCELED\x10\x04\x12\x1e\n" +
 	"\x1aWORKFLOW_STATUS_TERMINATED\x10\x05\x12$\n" +
 	" WORKFLOW_STATUS_CONTINUED_AS_NEW\x10\x06\x12\x1d\n" +
-	"\x19WORKFLOW_STATUS_TIMED_OUT\x10\a*\x8d\t\n" +
+	"\x19WORKFLOW_STATUS_TIMED_OUT\x10\a*\xc5\x02\n" +
+	"\x14HistoryEventCategory\x12&\n" +
+	"\"HISTORY_EVENT_CATEGORY_UNSPECIFIED\x10\x00\x12#\n" +
+	"\x1fHISTORY_EVENT_CATEGORY_WORKFLOW\x10\x01\x12(\n" +
+	"$HISTORY_EVENT_CATEGORY_WORKFLOW_TASK\x10\x02\x12#\n" +
+	"\x1fHISTORY_EVENT_CATEGORY_ACTIVITY\x10\x03\x12 \n" +
+	"\x1cHISTORY_EVENT_CATEGORY_TIMER\x10\x04\x12!\n" +
+	"\x1dHISTORY_EVENT_CATEGORY_SIGNAL\x10\x05\x12!\n" +
+	"\x1dHISTORY_EVENT_CATEGORY_MARKER\x10\x06\x12)\n" +
+	"%HISTORY_EVENT_CATEGORY_CHILD_WORKFLOW\x10\a*\x8d\t\n" +
 	"\x10HistoryEventType\x12\"\n" +
 	"\x1eHISTORY_EVENT_TYPE_UNSPECIFIED\x10\x00\x121\n" +
 	"-HISTORY_EVENT_TYPE_WORKFLOW_EXECUTION_STARTED\x10\x01\x123\n" +
@@ -2101,9 +2574,10 @@ const file_workflows_service_proto_rawDesc = "" +
 	"!HISTORY_EVENT_TYPE_TIMER_CANCELED\x10\x14\x12&\n" +
 	"\"HISTORY_EVENT_TYPE_MARKER_RECORDED\x10\x15\x12C\n" +
 	"?HISTORY_EVENT_TYPE_SIGNAL_EXTERNAL_WORKFLOW_EXECUTION_INITIATED\x10\x16\x122\n" +
-	".HISTORY_EVENT_TYPE_WORKFLOW_EXECUTION_SIGNALED\x10\x172\xf9\x0f\n" +
+	".HISTORY_EVENT_TYPE_WORKFLOW_EXECUTION_SIGNALED\x10\x172\x82\x11\n" +
 	"\x0fWorkflowService\x12\x82\x01\n" +
-	"\x0fTriggerWorkflow\x12#.workflow.v1.TriggerWorkflowRequest\x1a$.workflow.v1.TriggerWorkflowResponse\"$\x82\xd3\xe4\x93\x02\x1e:\x01*\"\x19/api/v1/workflows/trigger\x12\x7f\n" +
+	"\x0fTriggerWorkflow\x12#.workflow.v1.TriggerWorkflowRequest\x1a$.workflow.v1.TriggerWorkflowResponse\"$\x82\xd3\xe4\x93\x02\x1e:\x01*\"\x19/api/v1/workflows/trigger\x12\x86\x01\n" +
+	"\rStartWorkflow\x12!.workflow.v1.StartWorkflowRequest\x1a\".workflow.v1.StartWorkflowResponse\".\x82\xd3\xe4\x93\x02(:\x01*\"#/api/v1/workflows/{namespace}/start\x12\x7f\n" +
 	"\x0eListNamespaces\x12\".workflow.v1.ListNamespacesRequest\x1a#.workflow.v1.ListNamespacesResponse\"$\x82\xd3\xe4\x93\x02\x1e\x12\x1c/api/v1/workflows/namespaces\x12{\n" +
 	"\rListWorkflows\x12!.workflow.v1.ListWorkflowsRequest\x1a\".workflow.v1.ListWorkflowsResponse\"#\x82\xd3\xe4\x93\x02\x1d:\x01*\"\x18/api/v1/workflows/search\x12\x81\x01\n" +
 	"\vGetWorkflow\x12\x1f.workflow.v1.GetWorkflowRequest\x1a\x1c.workflow.v1.WorkflowDetails\"3\x82\xd3\xe4\x93\x02-\x12+/api/v1/workflows/{namespace}/{workflow_id}\x12\xa2\x01\n" +
@@ -2130,101 +2604,122 @@ func file_workflows_service_proto_rawDescGZIP() []byte {
 	return file_workflows_service_proto_rawDescData
 }
 
-var file_workflows_service_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_workflows_service_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
+var file_workflows_service_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_workflows_service_proto_msgTypes = make([]protoimpl.MessageInfo, 30)
 var file_workflows_service_proto_goTypes = []any{
-	(WorkflowStatus)(0),                // 0: workflow.v1.WorkflowStatus
-	(HistoryEventType)(0),              // 1: workflow.v1.HistoryEventType
-	(*TriggerWorkflowRequest)(nil),     // 2: workflow.v1.TriggerWorkflowRequest
-	(*TriggerWorkflowResponse)(nil),    // 3: workflow.v1.TriggerWorkflowResponse
-	(*ListNamespacesRequest)(nil),      // 4: workflow.v1.ListNamespacesRequest
-	(*Namespace)(nil),                  // 5: workflow.v1.Namespace
-	(*ListNamespacesResponse)(nil),     // 6: workflow.v1.ListNamespacesResponse
-	(*ListWorkflowsRequest)(nil),       // 7: workflow.v1.ListWorkflowsRequest
-	(*WorkflowExecution)(nil),          // 8: workflow.v1.WorkflowExecution
-	(*ListWorkflowsResponse)(nil),      // 9: workflow.v1.ListWorkflowsResponse
-	(*GetWorkflowRequest)(nil),         // 10: workflow.v1.GetWorkflowRequest
-	(*WorkflowDetails)(nil),            // 11: workflow.v1.WorkflowDetails
-	(*PendingActivity)(nil),            // 12: workflow.v1.PendingActivity
-	(*GetWorkflowHistoryRequest)(nil),  // 13: workflow.v1.GetWorkflowHistoryRequest
-	(*HistoryEvent)(nil),               // 14: workflow.v1.HistoryEvent
-	(*GetWorkflowHistoryResponse)(nil), // 15: workflow.v1.GetWorkflowHistoryResponse
-	(*TerminateWorkflowRequest)(nil),   // 16: workflow.v1.TerminateWorkflowRequest
-	(*CancelWorkflowRequest)(nil),      // 17: workflow.v1.CancelWorkflowRequest
-	(*SignalWorkflowRequest)(nil),      // 18: workflow.v1.SignalWorkflowRequest
-	(*RestartWorkflowRequest)(nil),     // 19: workflow.v1.RestartWorkflowRequest
-	(*RestartWorkflowResponse)(nil),    // 20: workflow.v1.RestartWorkflowResponse
-	(*ListSchedulesRequest)(nil),       // 21: workflow.v1.ListSchedulesRequest
-	(*Schedule)(nil),                   // 22: workflow.v1.Schedule
-	(*ListSchedulesResponse)(nil),      // 23: workflow.v1.ListSchedulesResponse
-	(*GetScheduleRequest)(nil),         // 24: workflow.v1.GetScheduleRequest
-	(*PauseScheduleRequest)(nil),       // 25: workflow.v1.PauseScheduleRequest
-	(*UnpauseScheduleRequest)(nil),     // 26: workflow.v1.UnpauseScheduleRequest
-	(*TriggerScheduleRequest)(nil),     // 27: workflow.v1.TriggerScheduleRequest
-	nil,                                // 28: workflow.v1.TriggerWorkflowRequest.InputEntry
-	(*timestamppb.Timestamp)(nil),      // 29: google.protobuf.Timestamp
-	(*structpb.Struct)(nil),            // 30: google.protobuf.Struct
-	(*emptypb.Empty)(nil),              // 31: google.protobuf.Empty
+	(WorkflowIdReusePolicy)(0),         // 0: workflow.v1.WorkflowIdReusePolicy
+	(WorkflowStatus)(0),                // 1: workflow.v1.WorkflowStatus
+	(HistoryEventCategory)(0),          // 2: workflow.v1.HistoryEventCategory
+	(HistoryEventType)(0),              // 3: workflow.v1.HistoryEventType
+	(*TriggerWorkflowRequest)(nil),     // 4: workflow.v1.TriggerWorkflowRequest
+	(*TriggerWorkflowResponse)(nil),    // 5: workflow.v1.TriggerWorkflowResponse
+	(*RetryPolicy)(nil),                // 6: workflow.v1.RetryPolicy
+	(*StartWorkflowRequest)(nil),       // 7: workflow.v1.StartWorkflowRequest
+	(*StartWorkflowResponse)(nil),      // 8: workflow.v1.StartWorkflowResponse
+	(*ListNamespacesRequest)(nil),      // 9: workflow.v1.ListNamespacesRequest
+	(*Namespace)(nil),                  // 10: workflow.v1.Namespace
+	(*ListNamespacesResponse)(nil),     // 11: workflow.v1.ListNamespacesResponse
+	(*ListWorkflowsRequest)(nil),       // 12: workflow.v1.ListWorkflowsRequest
+	(*WorkflowExecution)(nil),          // 13: workflow.v1.WorkflowExecution
+	(*ListWorkflowsResponse)(nil),      // 14: workflow.v1.ListWorkflowsResponse
+	(*GetWorkflowRequest)(nil),         // 15: workflow.v1.GetWorkflowRequest
+	(*WorkflowDetails)(nil),            // 16: workflow.v1.WorkflowDetails
+	(*PendingActivity)(nil),            // 17: workflow.v1.PendingActivity
+	(*GetWorkflowHistoryRequest)(nil),  // 18: workflow.v1.GetWorkflowHistoryRequest
+	(*HistoryEvent)(nil),               // 19: workflow.v1.HistoryEvent
+	(*GetWorkflowHistoryResponse)(nil), // 20: workflow.v1.GetWorkflowHistoryResponse
+	(*TerminateWorkflowRequest)(nil),   // 21: workflow.v1.TerminateWorkflowRequest
+	(*CancelWorkflowRequest)(nil),      // 22: workflow.v1.CancelWorkflowRequest
+	(*SignalWorkflowRequest)(nil),      // 23: workflow.v1.SignalWorkflowRequest
+	(*RestartWorkflowRequest)(nil),     // 24: workflow.v1.RestartWorkflowRequest
+	(*RestartWorkflowResponse)(nil),    // 25: workflow.v1.RestartWorkflowResponse
+	(*ListSchedulesRequest)(nil),       // 26: workflow.v1.ListSchedulesRequest
+	(*Schedule)(nil),                   // 27: workflow.v1.Schedule
+	(*ListSchedulesResponse)(nil),      // 28: workflow.v1.ListSchedulesResponse
+	(*GetScheduleRequest)(nil),         // 29: workflow.v1.GetScheduleRequest
+	(*PauseScheduleRequest)(nil),       // 30: workflow.v1.PauseScheduleRequest
+	(*UnpauseScheduleRequest)(nil),     // 31: workflow.v1.UnpauseScheduleRequest
+	(*TriggerScheduleRequest)(nil),     // 32: workflow.v1.TriggerScheduleRequest
+	nil,                                // 33: workflow.v1.TriggerWorkflowRequest.InputEntry
+	(*structpb.Struct)(nil),            // 34: google.protobuf.Struct
+	(*durationpb.Duration)(nil),        // 35: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),      // 36: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),              // 37: google.protobuf.Empty
 }
 var file_workflows_service_proto_depIdxs = []int32{
-	28, // 0: workflow.v1.TriggerWorkflowRequest.input:type_name -> workflow.v1.TriggerWorkflowRequest.InputEntry
-	5,  // 1: workflow.v1.ListNamespacesResponse.namespaces:type_name -> workflow.v1.Namespace
-	0,  // 2: workflow.v1.ListWorkflowsRequest.status:type_name -> workflow.v1.WorkflowStatus
-	0,  // 3: workflow.v1.WorkflowExecution.status:type_name -> workflow.v1.WorkflowStatus
-	29, // 4: workflow.v1.WorkflowExecution.start_time:type_name -> google.protobuf.Timestamp
-	29, // 5: workflow.v1.WorkflowExecution.close_time:type_name -> google.protobuf.Timestamp
-	30, // 6: workflow.v1.WorkflowExecution.search_attributes:type_name -> google.protobuf.Struct
-	8,  // 7: workflow.v1.ListWorkflowsResponse.executions:type_name -> workflow.v1.WorkflowExecution
-	8,  // 8: workflow.v1.WorkflowDetails.execution:type_name -> workflow.v1.WorkflowExecution
-	30, // 9: workflow.v1.WorkflowDetails.input:type_name -> google.protobuf.Struct
-	30, // 10: workflow.v1.WorkflowDetails.result:type_name -> google.protobuf.Struct
-	30, // 11: workflow.v1.WorkflowDetails.failure_message:type_name -> google.protobuf.Struct
-	29, // 12: workflow.v1.WorkflowDetails.execution_time:type_name -> google.protobuf.Timestamp
-	12, // 13: workflow.v1.WorkflowDetails.pending_activities:type_name -> workflow.v1.PendingActivity
-	29, // 14: workflow.v1.PendingActivity.scheduled_time:type_name -> google.protobuf.Timestamp
-	29, // 15: workflow.v1.PendingActivity.last_heartbeat_time:type_name -> google.protobuf.Timestamp
-	29, // 16: workflow.v1.HistoryEvent.event_time:type_name -> google.protobuf.Timestamp
-	1,  // 17: workflow.v1.HistoryEvent.event_type:type_name -> workflow.v1.HistoryEventType
-	30, // 18: workflow.v1.HistoryEvent.attributes:type_name -> google.protobuf.Struct
-	14, // 19: workflow.v1.GetWorkflowHistoryResponse.history:type_name -> workflow.v1.HistoryEvent
-	30, // 20: workflow.v1.SignalWorkflowRequest.input:type_name -> google.protobuf.Struct
-	29, // 21: workflow.v1.Schedule.next_run_time:type_name -> google.protobuf.Timestamp
-	29, // 22: workflow.v1.Schedule.last_run_time:type_name -> google.protobuf.Timestamp
-	30, // 23: workflow.v1.Schedule.workflow_input:type_name -> google.protobuf.Struct
-	22, // 24: workflow.v1.ListSchedulesResponse.schedules:type_name -> workflow.v1.Schedule
-	2,  // 25: workflow.v1.WorkflowService.TriggerWorkflow:input_type -> workflow.v1.TriggerWorkflowRequest
-	4,  // 26: workflow.v1.WorkflowService.ListNamespaces:input_type -> workflow.v1.ListNamespacesRequest
-	7,  // 27: workflow.v1.WorkflowService.ListWorkflows:input_type -> workflow.v1.ListWorkflowsRequest
-	10, // 28: workflow.v1.WorkflowService.GetWorkflow:input_type -> workflow.v1.GetWorkflowRequest
-	13, // 29: workflow.v1.WorkflowService.GetWorkflowHistory:input_type -> workflow.v1.GetWorkflowHistoryRequest
-	16, // 30: workflow.v1.WorkflowService.TerminateWorkflow:input_type -> workflow.v1.TerminateWorkflowRequest
-	17, // 31: workflow.v1.WorkflowService.CancelWorkflow:input_type -> workflow.v1.CancelWorkflowRequest
-	18, // 32: workflow.v1.WorkflowService.SignalWorkflow:input_type -> workflow.v1.SignalWorkflowRequest
-	19, // 33: workflow.v1.WorkflowService.RestartWorkflow:input_type -> workflow.v1.RestartWorkflowRequest
-	21, // 34: workflow.v1.WorkflowService.ListSchedules:input_type -> workflow.v1.ListSchedulesRequest
-	24, // 35: workflow.v1.WorkflowService.GetSchedule:input_type -> workflow.v1.GetScheduleRequest
-	25, // 36: workflow.v1.WorkflowService.PauseSchedule:input_type -> workflow.v1.PauseScheduleRequest
-	26, // 37: workflow.v1.WorkflowService.UnpauseSchedule:input_type -> workflow.v1.UnpauseScheduleRequest
-	27, // 38: workflow.v1.WorkflowService.TriggerSchedule:input_type -> workflow.v1.TriggerScheduleRequest
-	3,  // 39: workflow.v1.WorkflowService.TriggerWorkflow:output_type -> workflow.v1.TriggerWorkflowResponse
-	6,  // 40: workflow.v1.WorkflowService.ListNamespaces:output_type -> workflow.v1.ListNamespacesResponse
-	9,  // 41: workflow.v1.WorkflowService.ListWorkflows:output_type -> workflow.v1.ListWorkflowsResponse
-	11, // 42: workflow.v1.WorkflowService.GetWorkflow:output_type -> workflow.v1.WorkflowDetails
-	15, // 43: workflow.v1.WorkflowService.GetWorkflowHistory:output_type -> workflow.v1.GetWorkflowHistoryResponse
-	31, // 44: workflow.v1.WorkflowService.TerminateWorkflow:output_type -> google.protobuf.Empty
-	31, // 45: workflow.v1.WorkflowService.CancelWorkflow:output_type -> google.protobuf.Empty
-	31, // 46: workflow.v1.WorkflowService.SignalWorkflow:output_type -> google.protobuf.Empty
-	20, // 47: workflow.v1.WorkflowService.RestartWorkflow:output_type -> workflow.v1.RestartWorkflowResponse
-	23, // 48: workflow.v1.WorkflowService.ListSchedules:output_type -> workflow.v1.ListSchedulesResponse
-	22, // 49: workflow.v1.WorkflowService.GetSchedule:output_type -> workflow.v1.Schedule
-	31, // 50: workflow.v1.WorkflowService.PauseSchedule:output_type -> google.protobuf.Empty
-	31, // 51: workflow.v1.WorkflowService.UnpauseSchedule:output_type -> google.protobuf.Empty
-	3,  // 52: workflow.v1.WorkflowService.TriggerSchedule:output_type -> workflow.v1.TriggerWorkflowResponse
-	39, // [39:53] is the sub-list for method output_type
-	25, // [25:39] is the sub-list for method input_type
-	25, // [25:25] is the sub-list for extension type_name
-	25, // [25:25] is the sub-list for extension extendee
-	0,  // [0:25] is the sub-list for field type_name
+	33, // 0: workflow.v1.TriggerWorkflowRequest.input:type_name -> workflow.v1.TriggerWorkflowRequest.InputEntry
+	34, // 1: workflow.v1.TriggerWorkflowRequest.memo:type_name -> google.protobuf.Struct
+	34, // 2: workflow.v1.TriggerWorkflowRequest.search_attributes:type_name -> google.protobuf.Struct
+	35, // 3: workflow.v1.RetryPolicy.initial_interval:type_name -> google.protobuf.Duration
+	35, // 4: workflow.v1.RetryPolicy.maximum_interval:type_name -> google.protobuf.Duration
+	34, // 5: workflow.v1.StartWorkflowRequest.input:type_name -> google.protobuf.Struct
+	35, // 6: workflow.v1.StartWorkflowRequest.execution_timeout:type_name -> google.protobuf.Duration
+	35, // 7: workflow.v1.StartWorkflowRequest.run_timeout:type_name -> google.protobuf.Duration
+	35, // 8: workflow.v1.StartWorkflowRequest.task_timeout:type_name -> google.protobuf.Duration
+	0,  // 9: workflow.v1.StartWorkflowRequest.id_reuse_policy:type_name -> workflow.v1.WorkflowIdReusePolicy
+	6,  // 10: workflow.v1.StartWorkflowRequest.retry_policy:type_name -> workflow.v1.RetryPolicy
+	34, // 11: workflow.v1.StartWorkflowRequest.memo:type_name -> google.protobuf.Struct
+	34, // 12: workflow.v1.StartWorkflowRequest.search_attributes:type_name -> google.protobuf.Struct
+	10, // 13: workflow.v1.ListNamespacesResponse.namespaces:type_name -> workflow.v1.Namespace
+	1,  // 14: workflow.v1.ListWorkflowsRequest.status:type_name -> workflow.v1.WorkflowStatus
+	1,  // 15: workflow.v1.WorkflowExecution.status:type_name -> workflow.v1.WorkflowStatus
+	36, // 16: workflow.v1.WorkflowExecution.start_time:type_name -> google.protobuf.Timestamp
+	36, // 17: workflow.v1.WorkflowExecution.close_time:type_name -> google.protobuf.Timestamp
+	34, // 18: workflow.v1.WorkflowExecution.search_attributes:type_name -> google.protobuf.Struct
+	13, // 19: workflow.v1.ListWorkflowsResponse.executions:type_name -> workflow.v1.WorkflowExecution
+	13, // 20: workflow.v1.WorkflowDetails.execution:type_name -> workflow.v1.WorkflowExecution
+	34, // 21: workflow.v1.WorkflowDetails.input:type_name -> google.protobuf.Struct
+	34, // 22: workflow.v1.WorkflowDetails.result:type_name -> google.protobuf.Struct
+	34, // 23: workflow.v1.WorkflowDetails.failure_message:type_name -> google.protobuf.Struct
+	36, // 24: workflow.v1.WorkflowDetails.execution_time:type_name -> google.protobuf.Timestamp
+	17, // 25: workflow.v1.WorkflowDetails.pending_activities:type_name -> workflow.v1.PendingActivity
+	36, // 26: workflow.v1.PendingActivity.scheduled_time:type_name -> google.protobuf.Timestamp
+	36, // 27: workflow.v1.PendingActivity.last_heartbeat_time:type_name -> google.protobuf.Timestamp
+	2,  // 28: workflow.v1.GetWorkflowHistoryRequest.categories:type_name -> workflow.v1.HistoryEventCategory
+	36, // 29: workflow.v1.HistoryEvent.event_time:type_name -> google.protobuf.Timestamp
+	3,  // 30: workflow.v1.HistoryEvent.event_type:type_name -> workflow.v1.HistoryEventType
+	34, // 31: workflow.v1.HistoryEvent.attributes:type_name -> google.protobuf.Struct
+	19, // 32: workflow.v1.GetWorkflowHistoryResponse.history:type_name -> workflow.v1.HistoryEvent
+	34, // 33: workflow.v1.SignalWorkflowRequest.input:type_name -> google.protobuf.Struct
+	36, // 34: workflow.v1.Schedule.next_run_time:type_name -> google.protobuf.Timestamp
+	36, // 35: workflow.v1.Schedule.last_run_time:type_name -> google.protobuf.Timestamp
+	34, // 36: workflow.v1.Schedule.workflow_input:type_name -> google.protobuf.Struct
+	27, // 37: workflow.v1.ListSchedulesResponse.schedules:type_name -> workflow.v1.Schedule
+	4,  // 38: workflow.v1.WorkflowService.TriggerWorkflow:input_type -> workflow.v1.TriggerWorkflowRequest
+	7,  // 39: workflow.v1.WorkflowService.StartWorkflow:input_type -> workflow.v1.StartWorkflowRequest
+	9,  // 40: workflow.v1.WorkflowService.ListNamespaces:input_type -> workflow.v1.ListNamespacesRequest
+	12, // 41: workflow.v1.WorkflowService.ListWorkflows:input_type -> workflow.v1.ListWorkflowsRequest
+	15, // 42: workflow.v1.WorkflowService.GetWorkflow:input_type -> workflow.v1.GetWorkflowRequest
+	18, // 43: workflow.v1.WorkflowService.GetWorkflowHistory:input_type -> workflow.v1.GetWorkflowHistoryRequest
+	21, // 44: workflow.v1.WorkflowService.TerminateWorkflow:input_type -> workflow.v1.TerminateWorkflowRequest
+	22, // 45: workflow.v1.WorkflowService.CancelWorkflow:input_type -> workflow.v1.CancelWorkflowRequest
+	23, // 46: workflow.v1.WorkflowService.SignalWorkflow:input_type -> workflow.v1.SignalWorkflowRequest
+	24, // 47: workflow.v1.WorkflowService.RestartWorkflow:input_type -> workflow.v1.RestartWorkflowRequest
+	26, // 48: workflow.v1.WorkflowService.ListSchedules:input_type -> workflow.v1.ListSchedulesRequest
+	29, // 49: workflow.v1.WorkflowService.GetSchedule:input_type -> workflow.v1.GetScheduleRequest
+	30, // 50: workflow.v1.WorkflowService.PauseSchedule:input_type -> workflow.v1.PauseScheduleRequest
+	31, // 51: workflow.v1.WorkflowService.UnpauseSchedule:input_type -> workflow.v1.UnpauseScheduleRequest
+	32, // 52: workflow.v1.WorkflowService.TriggerSchedule:input_type -> workflow.v1.TriggerScheduleRequest
+	5,  // 53: workflow.v1.WorkflowService.TriggerWorkflow:output_type -> workflow.v1.TriggerWorkflowResponse
+	8,  // 54: workflow.v1.WorkflowService.StartWorkflow:output_type -> workflow.v1.StartWorkflowResponse
+	11, // 55: workflow.v1.WorkflowService.ListNamespaces:output_type -> workflow.v1.ListNamespacesResponse
+	14, // 56: workflow.v1.WorkflowService.ListWorkflows:output_type -> workflow.v1.ListWorkflowsResponse
+	16, // 57: workflow.v1.WorkflowService.GetWorkflow:output_type -> workflow.v1.WorkflowDetails
+	20, // 58: workflow.v1.WorkflowService.GetWorkflowHistory:output_type -> workflow.v1.GetWorkflowHistoryResponse
+	37, // 59: workflow.v1.WorkflowService.TerminateWorkflow:output_type -> google.protobuf.Empty
+	37, // 60: workflow.v1.WorkflowService.CancelWorkflow:output_type -> google.protobuf.Empty
+	37, // 61: workflow.v1.WorkflowService.SignalWorkflow:output_type -> google.protobuf.Empty
+	25, // 62: workflow.v1.WorkflowService.RestartWorkflow:output_type -> workflow.v1.RestartWorkflowResponse
+	28, // 63: workflow.v1.WorkflowService.ListSchedules:output_type -> workflow.v1.ListSchedulesResponse
+	27, // 64: workflow.v1.WorkflowService.GetSchedule:output_type -> workflow.v1.Schedule
+	37, // 65: workflow.v1.WorkflowService.PauseSchedule:output_type -> google.protobuf.Empty
+	37, // 66: workflow.v1.WorkflowService.UnpauseSchedule:output_type -> google.protobuf.Empty
+	5,  // 67: workflow.v1.WorkflowService.TriggerSchedule:output_type -> workflow.v1.TriggerWorkflowResponse
+	53, // [53:68] is the sub-list for method output_type
+	38, // [38:53] is the sub-list for method input_type
+	38, // [38:38] is the sub-list for extension type_name
+	38, // [38:38] is the sub-list for extension extendee
+	0,  // [0:38] is the sub-list for field type_name
 }
 
 func init() { file_workflows_service_proto_init() }
@@ -2238,8 +2733,8 @@ func file_workflows_service_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_workflows_service_proto_rawDesc), len(file_workflows_service_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   27,
+			NumEnums:      4,
+			NumMessages:   30,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
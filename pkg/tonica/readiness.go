@@ -0,0 +1,164 @@
+package tonica
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tonica-go/tonica/pkg/tonica/consumer"
+	"github.com/tonica-go/tonica/pkg/tonica/storage"
+)
+
+// Statuses of readiness checks in the /readyz response.
+const (
+	ReadinessOK   = "ok"
+	ReadinessDown = "down"
+)
+
+// ReadinessResult is the outcome of one readiness check in the /readyz
+// response.
+type ReadinessResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// readiness holds the checks reported by /readyz.
+type readiness struct {
+	mu     sync.RWMutex
+	checks map[string]func(ctx context.Context) error
+}
+
+func newReadiness() *readiness {
+	return &readiness{checks: make(map[string]func(ctx context.Context) error)}
+}
+
+func (r *readiness) register(name string, fn func(ctx context.Context) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = fn
+}
+
+// run runs every check concurrently and reports whether all passed.
+func (r *readiness) run(ctx context.Context, timeout time.Duration) (map[string]ReadinessResult, bool) {
+	r.mu.RLock()
+	checks := make(map[string]func(ctx context.Context) error, len(r.checks))
+	for name, fn := range r.checks {
+		checks[name] = fn
+	}
+	r.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]ReadinessResult, len(checks))
+		ready   = true
+	)
+	for name, fn := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := ReadinessResult{Status: ReadinessOK}
+			if err := fn(ctx); err != nil {
+				result = ReadinessResult{Status: ReadinessDown, Error: err.Error()}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			results[name] = result
+			if result.Status != ReadinessOK {
+				ready = false
+			}
+		}()
+	}
+	wg.Wait()
+	return results, ready
+}
+
+// RegisterReadinessCheck adds a check to /readyz. The app reports 503 while
+// fn returns an error; fn gets a context bounded by DefaultReadinessTimeout.
+// Registering a name again replaces its check.
+func (a *App) RegisterReadinessCheck(name string, fn func(ctx context.Context) error) {
+	a.readiness.register(name, fn)
+}
+
+// readyz reports the result of every readiness check, with 503 when one fails.
+func (a *App) readyz(c *gin.Context) {
+	results, ready := a.readiness.run(c.Request.Context(), DefaultReadinessTimeout)
+	code, status := http.StatusOK, ReadinessOK
+	if !ready {
+		code, status = http.StatusServiceUnavailable, "unavailable"
+	}
+	c.JSON(code, gin.H{
+		"status": status,
+		"now":    time.Now().UTC().Format(time.RFC3339),
+		"checks": results,
+	})
+}
+
+// runState tracks whether a component (a server, consumer or worker) is
+// running. Its check fails before the component starts and after it stops.
+type runState struct {
+	running atomic.Bool
+}
+
+// trackRunning registers a readiness check for a component and returns its
+// state, initially not running.
+func (a *App) trackRunning(name string) *runState {
+	state := &runState{}
+	a.RegisterReadinessCheck(name, state.check)
+	return state
+}
+
+func (s *runState) set(running bool) {
+	s.running.Store(running)
+}
+
+func (s *runState) check(context.Context) error {
+	if !s.running.Load() {
+		return errors.New("not running")
+	}
+	return nil
+}
+
+// consumerCheck reports a consumer as ready while it runs and its pubsub
+// client is healthy.
+func consumerCheck(state *runState, c *consumer.Consumer) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if err := state.check(ctx); err != nil {
+			return err
+		}
+		if c.GetClient() == nil {
+			return nil
+		}
+		health := c.GetClient().Health()
+		if health.Status == storage.StatusDown {
+			return fmt.Errorf("pubsub is %s: %v", health.Status, sortedDetails(health.Details))
+		}
+		return nil
+	}
+}
+
+// sortedDetails formats health details in a stable order.
+func sortedDetails(details map[string]any) string {
+	keys := make([]string, 0, len(details))
+	for k := range details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := ""
+	for i, k := range keys {
+		if i > 0 {
+			out += ", "
+		}
+		out += fmt.Sprintf("%s=%v", k, details[k])
+	}
+	return out
+}
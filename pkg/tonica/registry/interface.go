@@ -16,6 +16,7 @@ type Registry interface {
 	GetCountServices() int
 
 	RegisterWorker(name string, item *worker.Worker) error
+	MustRegisterWorker(item *worker.Worker)
 	GetWorker(name string) (*worker.Worker, error)
 	GetAllWorkers() ([]*worker.Worker, error)
 	GetCountWorkers() int
@@ -93,6 +94,22 @@ func (r *AppRegistry) RegisterWorker(name string, item *worker.Worker) error {
 	return nil
 }
 
+// MustRegisterWorker registers item under its name. A worker already
+// registered under that name on the same task queue absorbs the workflows and
+// activities of item instead, so services can share a queue.
+func (r *AppRegistry) MustRegisterWorker(item *worker.Worker) {
+	name := item.Name()
+	existing, ok := r.workers[name]
+	if !ok {
+		r.workers[name] = item
+		return
+	}
+	if existing.GetQueue() != item.GetQueue() {
+		panic(fmt.Errorf("worker %s already exists on queue %s", name, existing.GetQueue()))
+	}
+	existing.Merge(item)
+}
+
 func (r *AppRegistry) GetWorker(name string) (*worker.Worker, error) {
 	if item, ok := r.workers[name]; ok {
 		return item, nil
@@ -134,6 +134,31 @@ func TestRegistry_Workers(t *testing.T) {
 		assert.Contains(t, err.Error(), "already exists")
 	})
 
+	t.Run("MustRegisterWorker merges on shared queue", func(t *testing.T) {
+		reg := NewRegistry()
+		orders := func() {}
+		billing := func() {}
+
+		reg.MustRegisterWorker(worker.NewWorker(worker.WithName("shared"), worker.WithQueue("shared"), worker.WithWorkflow(orders), worker.WithActivity(orders)))
+		reg.MustRegisterWorker(worker.NewWorker(worker.WithName("shared"), worker.WithQueue("shared"), worker.WithWorkflow(billing, "billing")))
+
+		assert.Equal(t, 1, reg.GetCountWorkers())
+		wrk, err := reg.GetWorker("shared")
+		assert.NoError(t, err)
+		assert.Len(t, wrk.Workflows(), 2)
+		assert.Equal(t, "billing", wrk.Workflows()[1].Name)
+		assert.Len(t, wrk.Activities(), 1)
+	})
+
+	t.Run("MustRegisterWorker queue conflict", func(t *testing.T) {
+		reg := NewRegistry()
+		reg.MustRegisterWorker(worker.NewWorker(worker.WithName("conflict"), worker.WithQueue("a")))
+
+		assert.Panics(t, func() {
+			reg.MustRegisterWorker(worker.NewWorker(worker.WithName("conflict"), worker.WithQueue("b")))
+		})
+	})
+
 	t.Run("GetWorker not found", func(t *testing.T) {
 		_, err := reg.GetWorker("non-existent")
 		assert.Error(t, err)
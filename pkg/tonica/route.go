@@ -3,22 +3,29 @@ package tonica
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 // RouteBuilder provides fluent API for creating documented custom routes
 type RouteBuilder struct {
-	app         *App
-	method      string
-	path        string
-	summary     string
-	description string
-	tags        []string
-	parameters  []RouteParameter
-	responses   map[string]RouteResponse
-	security    []map[string][]string
-	handler     gin.HandlerFunc
+	app          *App
+	method       string
+	path         string
+	summary      string
+	description  string
+	tags         []string
+	parameters   []RouteParameter
+	responses    map[string]RouteResponse
+	security     []map[string][]string
+	deprecated   bool
+	validate     bool
+	validateBody bool
+	files        map[string]fileConstraints
+	middlewares  []gin.HandlerFunc
+	handler      gin.HandlerFunc
 }
 
 // RouteParameter represents an OpenAPI parameter
@@ -31,6 +38,16 @@ type RouteParameter struct {
 	Schema      interface{} `json:"schema,omitempty"` // for body parameters
 	Format      string      `json:"format,omitempty"` // int32, int64, float, double, etc.
 	Default     interface{} `json:"default,omitempty"`
+	Enum        []string    `json:"enum,omitempty"`
+	Items       *RouteItems `json:"items,omitempty"` // for array parameters
+	// CollectionFormat is how array values are sent: multi (?a=1&a=2), csv, ...
+	CollectionFormat string `json:"collectionFormat,omitempty"`
+}
+
+// RouteItems describes the items of an array parameter
+type RouteItems struct {
+	Type string   `json:"type"`
+	Enum []string `json:"enum,omitempty"`
 }
 
 // RouteResponse represents an OpenAPI response
@@ -50,6 +67,7 @@ type RouteMetadata struct {
 	Parameters  []RouteParameter         `json:"parameters,omitempty"`
 	Responses   map[string]RouteResponse `json:"responses"`
 	Security    []map[string][]string    `json:"security,omitempty"`
+	Deprecated  bool                     `json:"deprecated,omitempty"`
 }
 
 // NewRoute creates a new route builder
@@ -131,6 +149,34 @@ func (rb *RouteBuilder) QueryParam(name, paramType, description string, required
 	return rb
 }
 
+// QueryParamEnum adds a string query parameter limited to values
+func (rb *RouteBuilder) QueryParamEnum(name, description string, values []string, required bool) *RouteBuilder {
+	rb.parameters = append(rb.parameters, RouteParameter{
+		Name:        name,
+		In:          "query",
+		Type:        "string",
+		Description: description,
+		Required:    required,
+		Enum:        values,
+	})
+	return rb
+}
+
+// QueryParamArray adds a repeated query parameter, e.g.
+// ?status=open&status=closed
+func (rb *RouteBuilder) QueryParamArray(name, itemType, description string, required bool) *RouteBuilder {
+	rb.parameters = append(rb.parameters, RouteParameter{
+		Name:             name,
+		In:               "query",
+		Type:             "array",
+		Description:      description,
+		Required:         required,
+		Items:            &RouteItems{Type: itemType},
+		CollectionFormat: "multi",
+	})
+	return rb
+}
+
 // PathParam adds a path parameter
 func (rb *RouteBuilder) PathParam(name, paramType, description string) *RouteBuilder {
 	rb.parameters = append(rb.parameters, RouteParameter{
@@ -179,8 +225,9 @@ func (rb *RouteBuilder) FormParam(name, description, typ string) *RouteBuilder {
 	return rb
 }
 
-// FormFileParam adds a form data file parameter
-func (rb *RouteBuilder) FormFileParam(name, description string) *RouteBuilder {
+// FormFileParam adds a form data file parameter. Options constrain the files
+// accepted by FormFile and SaveFormFile in the route handler.
+func (rb *RouteBuilder) FormFileParam(name, description string, opts ...FileOption) *RouteBuilder {
 	rb.parameters = append(rb.parameters, RouteParameter{
 		Name:        name,
 		In:          "formData",
@@ -188,6 +235,15 @@ func (rb *RouteBuilder) FormFileParam(name, description string) *RouteBuilder {
 		Required:    true,
 		Type:        "file",
 	})
+
+	var constraints fileConstraints
+	for _, opt := range opts {
+		opt(&constraints)
+	}
+	if rb.files == nil {
+		rb.files = make(map[string]fileConstraints)
+	}
+	rb.files[name] = constraints
 	return rb
 }
 
@@ -209,6 +265,37 @@ func (rb *RouteBuilder) Security(name string, scopes ...string) *RouteBuilder {
 	return rb
 }
 
+// Deprecated marks the operation as deprecated in the OpenAPI spec. The route
+// keeps serving requests.
+func (rb *RouteBuilder) Deprecated() *RouteBuilder {
+	rb.deprecated = true
+	return rb
+}
+
+// ValidateRequest validates parameters and body against the route's OpenAPI
+// metadata and responds with 400 before the handler runs
+func (rb *RouteBuilder) ValidateRequest() *RouteBuilder {
+	rb.validate = true
+	return rb
+}
+
+// ValidateBody validates the JSON body against the schema of the BodyParam
+// before the handler runs and responds with 400 and the field errors
+// otherwise. Unlike ValidateRequest it does not need the served spec, so the
+// route is checked against exactly what it documents.
+func (rb *RouteBuilder) ValidateBody(enabled bool) *RouteBuilder {
+	rb.validateBody = enabled
+	return rb
+}
+
+// Use adds middleware that runs before the handler of this route only, in the
+// order added. It runs ahead of request validation and upload checks, so auth
+// middleware rejects requests before their body is looked at.
+func (rb *RouteBuilder) Use(middlewares ...gin.HandlerFunc) *RouteBuilder {
+	rb.middlewares = append(rb.middlewares, middlewares...)
+	return rb
+}
+
 // Handle registers the handler and metadata
 func (rb *RouteBuilder) Handle(handler gin.HandlerFunc) {
 	if rb.method == "" || rb.path == "" {
@@ -217,18 +304,32 @@ func (rb *RouteBuilder) Handle(handler gin.HandlerFunc) {
 
 	rb.handler = handler
 
+	handlers := []gin.HandlerFunc{handler}
+	if len(rb.files) > 0 {
+		handlers = append([]gin.HandlerFunc{rb.uploadMiddleware()}, handlers...)
+	}
+	if rb.validateBody {
+		if schema, required := rb.bodySchema(); schema != nil {
+			handlers = append([]gin.HandlerFunc{rb.app.bodyValidationMiddleware(schema, required)}, handlers...)
+		}
+	}
+	if rb.validate {
+		handlers = append([]gin.HandlerFunc{rb.app.validationMiddleware(nil)}, handlers...)
+	}
+	handlers = append(append([]gin.HandlerFunc(nil), rb.middlewares...), handlers...)
+
 	// Register the route with Gin
 	switch rb.method {
 	case "GET":
-		rb.app.router.GET(rb.path, handler)
+		rb.app.router.GET(rb.path, handlers...)
 	case "POST":
-		rb.app.router.POST(rb.path, handler)
+		rb.app.router.POST(rb.path, handlers...)
 	case "PUT":
-		rb.app.router.PUT(rb.path, handler)
+		rb.app.router.PUT(rb.path, handlers...)
 	case "PATCH":
-		rb.app.router.PATCH(rb.path, handler)
+		rb.app.router.PATCH(rb.path, handlers...)
 	case "DELETE":
-		rb.app.router.DELETE(rb.path, handler)
+		rb.app.router.DELETE(rb.path, handlers...)
 	default:
 		panic(fmt.Sprintf("unsupported HTTP method: %s", rb.method))
 	}
@@ -244,11 +345,31 @@ func (rb *RouteBuilder) Handle(handler gin.HandlerFunc) {
 		Parameters:  rb.parameters,
 		Responses:   rb.responses,
 		Security:    rb.security,
+		Deprecated:  rb.deprecated,
 	}
 
 	rb.app.customRoutes = append(rb.app.customRoutes, metadata)
 }
 
+// bodySchema returns the schema of the body parameter of the route and
+// whether the body is required. The schema goes through JSON so typed maps,
+// such as those of InlineObjectSchema, validate like the served spec.
+func (rb *RouteBuilder) bodySchema() (map[string]interface{}, bool) {
+	for _, param := range rb.parameters {
+		if param.In != "body" {
+			continue
+		}
+		raw, err := json.Marshal(param.Schema)
+		if err != nil {
+			panic(fmt.Sprintf("invalid body schema of %s %s: %v", rb.method, rb.path, err))
+		}
+		var schema map[string]interface{}
+		_ = json.Unmarshal(raw, &schema)
+		return schema, param.Required
+	}
+	return nil, false
+}
+
 // generateOperationID creates an operation ID from method and path
 func (rb *RouteBuilder) generateOperationID() string {
 	// Simple operation ID generation: Method + sanitized path
@@ -306,9 +427,17 @@ func InlineObjectSchema(props map[string]string) map[string]interface{} {
 	return ObjectSchema(properties)
 }
 
-// mergeCustomRoutesIntoSpec merges custom route metadata into the OpenAPI spec
-func mergeCustomRoutesIntoSpec(specBytes []byte, customRoutes []RouteMetadata) ([]byte, error) {
-	if len(customRoutes) == 0 {
+// RequiredFields marks fields of an object schema as required and returns
+// the schema, e.g. RequiredFields(InlineObjectSchema(props), "name").
+func RequiredFields(schema map[string]interface{}, fields ...string) map[string]interface{} {
+	schema["required"] = fields
+	return schema
+}
+
+// mergeCustomRoutesIntoSpec merges custom route metadata and security schemes
+// into the OpenAPI spec
+func mergeCustomRoutesIntoSpec(specBytes []byte, customRoutes []RouteMetadata, schemes map[string]SecurityScheme) ([]byte, error) {
+	if len(customRoutes) == 0 && len(schemes) == 0 {
 		return specBytes, nil
 	}
 
@@ -323,6 +452,7 @@ func mergeCustomRoutesIntoSpec(specBytes []byte, customRoutes []RouteMetadata) (
 		paths = make(map[string]interface{})
 		spec["paths"] = paths
 	}
+	mergeSecuritySchemes(spec, schemes)
 
 	// Add custom routes
 	for _, route := range customRoutes {
@@ -356,6 +486,9 @@ func mergeCustomRoutesIntoSpec(specBytes []byte, customRoutes []RouteMetadata) (
 		if len(route.Security) > 0 {
 			operation["security"] = route.Security
 		}
+		if route.Deprecated {
+			operation["deprecated"] = true
+		}
 
 		// Add operation to path with lowercase method
 		methodKey := ""
@@ -377,3 +510,41 @@ func mergeCustomRoutesIntoSpec(specBytes []byte, customRoutes []RouteMetadata) (
 	// Marshal back to JSON
 	return json.MarshalIndent(spec, "", "  ")
 }
+
+// applySpecServer rewrites the server block of the OpenAPI spec so the docs
+// page targets the URL the API is actually served from. Swagger 2.0 specs get
+// host/basePath/schemes, OpenAPI 3.x specs get servers. An empty publicURL
+// leaves the spec as written.
+func applySpecServer(specBytes []byte, publicURL string) ([]byte, error) {
+	if publicURL == "" {
+		return specBytes, nil
+	}
+
+	u, err := url.Parse(publicURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public url %q: %w", publicURL, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid public url %q: scheme and host are required", publicURL)
+	}
+
+	var spec map[string]interface{}
+	if err := json.Unmarshal(specBytes, &spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal spec: %w", err)
+	}
+
+	if _, ok := spec["openapi"]; ok {
+		spec["servers"] = []map[string]string{{"url": strings.TrimSuffix(u.String(), "/")}}
+		return json.MarshalIndent(spec, "", "  ")
+	}
+
+	basePath := "/"
+	if p := strings.TrimSuffix(u.Path, "/"); p != "" {
+		basePath = p
+	}
+	spec["host"] = u.Host
+	spec["schemes"] = []string{u.Scheme}
+	spec["basePath"] = basePath
+
+	return json.MarshalIndent(spec, "", "  ")
+}
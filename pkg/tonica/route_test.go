@@ -90,6 +90,25 @@ func TestRouteBuilder_Parameters(t *testing.T) {
 		assert.False(t, rb.parameters[1].Required)
 	})
 
+	t.Run("QueryParamEnum", func(t *testing.T) {
+		rb := NewRoute(app).QueryParamEnum("sort", "Sort order", []string{"asc", "desc"}, false)
+
+		require.Len(t, rb.parameters, 1)
+		raw, err := json.Marshal(rb.parameters[0])
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"sort","in":"query","description":"Sort order","type":"string","enum":["asc","desc"]}`, string(raw))
+	})
+
+	t.Run("QueryParamArray", func(t *testing.T) {
+		rb := NewRoute(app).QueryParamArray("status", "string", "Statuses", true)
+
+		require.Len(t, rb.parameters, 1)
+		raw, err := json.Marshal(rb.parameters[0])
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"status","in":"query","description":"Statuses","required":true,
+			"type":"array","items":{"type":"string"},"collectionFormat":"multi"}`, string(raw))
+	})
+
 	t.Run("PathParam", func(t *testing.T) {
 		rb := NewRoute(app).PathParam("id", "string", "User ID")
 
@@ -211,6 +230,53 @@ func TestRouteBuilder_Handle(t *testing.T) {
 		assert.Len(t, metadata.Responses, 1)
 	})
 
+	t.Run("should run route middleware in order", func(t *testing.T) {
+		var calls []string
+		tag := func(name string) gin.HandlerFunc {
+			return func(c *gin.Context) {
+				calls = append(calls, name)
+				c.Next()
+			}
+		}
+		NewRoute(app).
+			GET("/ordered").
+			Use(tag("first"), tag("second")).
+			Use(tag("third")).
+			Handle(func(c *gin.Context) {
+				calls = append(calls, "handler")
+				c.Status(http.StatusNoContent)
+			})
+
+		w := httptest.NewRecorder()
+		app.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ordered", nil))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, []string{"first", "second", "third", "handler"}, calls)
+
+		calls = nil
+		w = httptest.NewRecorder()
+		app.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+		assert.Empty(t, calls, "middleware should not leak to other routes")
+	})
+
+	t.Run("should run route middleware before validation", func(t *testing.T) {
+		called := false
+		NewRoute(app).
+			GET("/guarded").
+			QueryParam("q", "string", "Query", true).
+			ValidateRequest().
+			Use(func(c *gin.Context) {
+				c.AbortWithStatus(http.StatusUnauthorized)
+			}).
+			Handle(func(c *gin.Context) {
+				called = true
+			})
+
+		w := httptest.NewRecorder()
+		app.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/guarded", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.False(t, called)
+	})
+
 	t.Run("should panic without method", func(t *testing.T) {
 		assert.Panics(t, func() {
 			NewRoute(app).Handle(func(c *gin.Context) {})
@@ -321,7 +387,7 @@ func TestMergeCustomRoutesIntoSpec(t *testing.T) {
 			},
 		}
 
-		result, err := mergeCustomRoutesIntoSpec(specBytes, routes)
+		result, err := mergeCustomRoutesIntoSpec(specBytes, routes, nil)
 		require.NoError(t, err)
 
 		var spec map[string]interface{}
@@ -354,7 +420,7 @@ func TestMergeCustomRoutesIntoSpec(t *testing.T) {
 			},
 		}
 
-		result, err := mergeCustomRoutesIntoSpec(specBytes, routes)
+		result, err := mergeCustomRoutesIntoSpec(specBytes, routes, nil)
 		require.NoError(t, err)
 
 		var spec map[string]interface{}
@@ -369,7 +435,7 @@ func TestMergeCustomRoutesIntoSpec(t *testing.T) {
 	t.Run("should handle empty custom routes", func(t *testing.T) {
 		specBytes := []byte(`{"swagger":"2.0","paths":{}}`)
 
-		result, err := mergeCustomRoutesIntoSpec(specBytes, []RouteMetadata{})
+		result, err := mergeCustomRoutesIntoSpec(specBytes, []RouteMetadata{}, nil)
 		require.NoError(t, err)
 		assert.Equal(t, specBytes, result)
 	})
@@ -390,7 +456,7 @@ func TestMergeCustomRoutesIntoSpec(t *testing.T) {
 			},
 		}
 
-		result, err := mergeCustomRoutesIntoSpec(specBytes, routes)
+		result, err := mergeCustomRoutesIntoSpec(specBytes, routes, nil)
 		require.NoError(t, err)
 
 		var spec map[string]interface{}
@@ -403,6 +469,23 @@ func TestMergeCustomRoutesIntoSpec(t *testing.T) {
 		assert.Contains(t, usersPath, "get")
 		assert.Contains(t, usersPath, "post")
 	})
+	t.Run("should mark deprecated operations", func(t *testing.T) {
+		app := NewApp()
+		NewRoute(app).GET("/v1/legacy").Deprecated().Handle(func(c *gin.Context) {})
+		NewRoute(app).GET("/v2/current").Handle(func(c *gin.Context) {})
+
+		result, err := mergeCustomRoutesIntoSpec([]byte(`{"swagger":"2.0","paths":{}}`), app.customRoutes, nil)
+		require.NoError(t, err)
+
+		var spec map[string]interface{}
+		require.NoError(t, json.Unmarshal(result, &spec))
+
+		paths := spec["paths"].(map[string]interface{})
+		legacy := paths["/v1/legacy"].(map[string]interface{})["get"].(map[string]interface{})
+		current := paths["/v2/current"].(map[string]interface{})["get"].(map[string]interface{})
+		assert.Equal(t, true, legacy["deprecated"])
+		assert.NotContains(t, current, "deprecated")
+	})
 }
 
 func TestRouteBuilder_generateOperationID(t *testing.T) {
@@ -434,3 +517,92 @@ func TestRouteBuilder_generateOperationID(t *testing.T) {
 		})
 	}
 }
+
+func TestApplySpecServer(t *testing.T) {
+	t.Run("should set host and basePath for swagger 2.0", func(t *testing.T) {
+		specBytes := []byte(`{"swagger":"2.0","host":"internal:8080","basePath":"/old","paths":{}}`)
+
+		result, err := applySpecServer(specBytes, "https://api.example.com/billing/")
+		require.NoError(t, err)
+
+		var spec map[string]interface{}
+		require.NoError(t, json.Unmarshal(result, &spec))
+		assert.Equal(t, "api.example.com", spec["host"])
+		assert.Equal(t, "/billing", spec["basePath"])
+		assert.Equal(t, []interface{}{"https"}, spec["schemes"])
+	})
+
+	t.Run("should leave the spec untouched without public url", func(t *testing.T) {
+		for _, specBytes := range [][]byte{
+			[]byte(`{"swagger":"2.0","host":"internal:8080","basePath":"/old","schemes":["http"],"paths":{}}`),
+			[]byte(`{"openapi":"3.0.0","servers":[{"url":"http://internal"}],"paths":{}}`),
+		} {
+			result, err := applySpecServer(specBytes, "")
+			require.NoError(t, err)
+			assert.Equal(t, specBytes, result)
+		}
+	})
+
+	t.Run("should set servers for openapi 3", func(t *testing.T) {
+		specBytes := []byte(`{"openapi":"3.0.0","servers":[{"url":"http://internal"}],"paths":{}}`)
+
+		result, err := applySpecServer(specBytes, "https://api.example.com/billing")
+		require.NoError(t, err)
+
+		var spec map[string]interface{}
+		require.NoError(t, json.Unmarshal(result, &spec))
+		servers := spec["servers"].([]interface{})
+		require.Len(t, servers, 1)
+		assert.Equal(t, "https://api.example.com/billing", servers[0].(map[string]interface{})["url"])
+	})
+
+	t.Run("should reject public url without host", func(t *testing.T) {
+		_, err := applySpecServer([]byte(`{"swagger":"2.0"}`), "/billing")
+		assert.Error(t, err)
+	})
+}
+
+func TestMergeSecuritySchemes(t *testing.T) {
+	schemes := map[string]SecurityScheme{
+		"bearer": BearerSecurityScheme("JWT"),
+		"apiKey": APIKeySecurityScheme("X-Api-Key"),
+		"oauth": OAuth2SecurityScheme(OAuth2AuthorizationCode, "https://auth.example.com/authorize",
+			"https://auth.example.com/token", map[string]string{"orders:read": "Read orders"}),
+	}
+	routes := []RouteMetadata{{Method: "GET", Path: "/orders", Security: []map[string][]string{{"bearer": nil}}}}
+
+	decode := func(t *testing.T, spec []byte) map[string]any {
+		t.Helper()
+		var out map[string]any
+		require.NoError(t, json.Unmarshal(spec, &out))
+		return out
+	}
+
+	t.Run("should add components for OpenAPI 3", func(t *testing.T) {
+		result, err := mergeCustomRoutesIntoSpec([]byte(`{"openapi":"3.0.0","paths":{}}`), routes, schemes)
+		require.NoError(t, err)
+
+		defs := decode(t, result)["components"].(map[string]any)["securitySchemes"].(map[string]any)
+		assert.Equal(t, map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"}, defs["bearer"])
+		assert.Equal(t, map[string]any{"type": "apiKey", "name": "X-Api-Key", "in": "header"}, defs["apiKey"])
+		assert.Equal(t, map[string]any{
+			"type": "oauth2",
+			"flows": map[string]any{"authorizationCode": map[string]any{
+				"authorizationUrl": "https://auth.example.com/authorize",
+				"tokenUrl":         "https://auth.example.com/token",
+				"scopes":           map[string]any{"orders:read": "Read orders"},
+			}},
+		}, defs["oauth"])
+	})
+
+	t.Run("should add security definitions for Swagger 2", func(t *testing.T) {
+		spec := []byte(`{"swagger":"2.0","paths":{},"securityDefinitions":{"basic":{"type":"basic"}}}`)
+		result, err := mergeCustomRoutesIntoSpec(spec, nil, schemes)
+		require.NoError(t, err)
+
+		defs := decode(t, result)["securityDefinitions"].(map[string]any)
+		assert.Equal(t, map[string]any{"type": "basic"}, defs["basic"])
+		assert.Equal(t, map[string]any{"type": "apiKey", "name": "Authorization", "in": "header"}, defs["bearer"])
+		assert.Equal(t, "accessCode", defs["oauth"].(map[string]any)["flow"])
+	})
+}
@@ -2,19 +2,30 @@ package tonica
 
 import (
 	"context"
+	"errors"
 	"os/signal"
 	"syscall"
 
 	"github.com/tonica-go/tonica/pkg/tonica/config"
+	"github.com/tonica-go/tonica/pkg/tonica/service"
 )
 
 func (a *App) Run() error {
+	// Fail before anything listens when the setup is inconsistent.
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
 	// Implementation of the Run method goes here.
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
+	// Cancelled when a server fails too, so consumers and workers stop
+	// before the shutdown drains them.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	// Observability
-	o, err := initObs(ctx, a.cfg.AppName())
+	o, err := a.initObs(ctx, a.cfg.AppName())
 	if err != nil {
 		a.logger.Fatal(err)
 	}
@@ -22,17 +33,82 @@ func (a *App) Run() error {
 
 	switch a.cfg.GetRunMode() {
 	case config.ModeAIO:
-		a.runAio(ctx, o)
+		return a.runAio(ctx, cancel, o)
 	case config.ModeService:
-		a.runService(ctx, o)
+		return a.runService(ctx, cancel, o)
 	case config.ModeWorker:
-		a.runWorker(ctx, o)
+		return a.runWorker(ctx, cancel, o)
 	case config.ModeConsumer:
-		a.runConsumer(ctx, o)
+		return a.runConsumer(ctx, cancel, o)
 	case config.ModeGateway:
-		a.runGateway(ctx, o)
+		return a.runGateway(ctx, cancel, o)
 	default:
 		return nil
 	}
+}
+
+// Validate checks the app setup for conflicting listen addresses and for
+// settings required by the enabled modules, see config.Validate. Run calls it
+// before starting anything.
+func (a *App) Validate() error {
+	runMode := config.ModeAIO
+	if a.cfg != nil {
+		runMode = a.cfg.GetRunMode()
+	}
+	settings := config.Settings{
+		RunMode:           runMode,
+		HTTPAddr:          a.getHTTPAddr(),
+		MetricsAddr:       a.getMetricsAddr(),
+		MetricsOnAPI:      a.metricsOnAPI,
+		GRPCAddrs:         make(map[string]string),
+		AutoGateway:       a.autoGateway,
+		Workflows:         a.isWorkflowService,
+		TemporalAddr:      config.GetEnv("TEMPORAL_ADDR", "localhost:7233"),
+		TemporalNamespace: config.GetEnv("TEMPORAL_NAMESPACE", "default"),
+		Entities:          a.isEntityService,
+		EntityDriver:      a.entityDriver,
+		EntityDSN:         a.entityDSN,
+	}
+
+	services, err := a.GetRegistry().GetAllServices()
+	if err != nil {
+		return err
+	}
+	var sharedServices []*service.Service
+	for _, svc := range services {
+		if a.usesSharedGRPC(svc) {
+			sharedServices = append(sharedServices, svc)
+			continue
+		}
+		settings.GRPCAddrs["service "+svc.GetName()] = svc.GetGRPCAddr()
+	}
+	if len(sharedServices) > 0 || len(a.rawGRPCRegs) > 0 {
+		settings.GRPCAddrs["shared grpc"] = a.grpcAddr
+	}
+
+	var problems []string
+	if err := config.Validate(settings); err != nil {
+		var validationErr *config.ValidationError
+		if !errors.As(err, &validationErr) {
+			return err
+		}
+		problems = append(problems, validationErr.Problems...)
+	}
+	// gRPC services are only served in aio and service mode.
+	if runMode == config.ModeAIO || runMode == config.ModeService {
+		if _, err := sharedTLS(sharedServices); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	// The gateway is only served in aio and gateway mode.
+	if runMode == config.ModeAIO || runMode == config.ModeGateway {
+		var mountErr *config.ValidationError
+		if _, _, err := a.splitGatewayMounts(services); errors.As(err, &mountErr) {
+			problems = append(problems, mountErr.Problems...)
+		}
+	}
+	if len(problems) > 0 {
+		return &config.ValidationError{Problems: problems}
+	}
 	return nil
 }
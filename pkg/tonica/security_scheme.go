@@ -0,0 +1,165 @@
+package tonica
+
+// Security scheme types, see SecurityScheme.
+const (
+	SecuritySchemeHTTP   = "http"
+	SecuritySchemeAPIKey = "apiKey"
+	SecuritySchemeOAuth2 = "oauth2"
+)
+
+// OAuth2 flows, named as in OpenAPI 3.x.
+const (
+	OAuth2AuthorizationCode = "authorizationCode"
+	OAuth2ClientCredentials = "clientCredentials"
+	OAuth2Implicit          = "implicit"
+	OAuth2Password          = "password"
+)
+
+// SecurityScheme defines a security scheme referenced by RouteBuilder.Security
+// and by the security requirements of the proto routes. It is written to
+// components.securitySchemes (OpenAPI 3.x) or securityDefinitions
+// (Swagger 2.0) of the served spec, see WithSecurityScheme.
+type SecurityScheme struct {
+	// Type is SecuritySchemeHTTP, SecuritySchemeAPIKey or SecuritySchemeOAuth2.
+	Type        string
+	Description string
+
+	// Scheme and BearerFormat describe http schemes, e.g. "bearer" and "JWT".
+	Scheme       string
+	BearerFormat string
+
+	// Name and In describe apiKey schemes, e.g. "X-Api-Key" in "header".
+	Name string
+	In   string
+
+	// Flow, the URLs and Scopes describe oauth2 schemes. Scopes map scope
+	// names to their description.
+	Flow             string
+	AuthorizationURL string
+	TokenURL         string
+	Scopes           map[string]string
+}
+
+// BearerSecurityScheme creates a bearer token scheme, e.g. with format "JWT".
+func BearerSecurityScheme(format string) SecurityScheme {
+	return SecurityScheme{Type: SecuritySchemeHTTP, Scheme: "bearer", BearerFormat: format}
+}
+
+// APIKeySecurityScheme creates a scheme for an API key sent in header.
+func APIKeySecurityScheme(header string) SecurityScheme {
+	return SecurityScheme{Type: SecuritySchemeAPIKey, Name: header, In: "header"}
+}
+
+// OAuth2SecurityScheme creates an oauth2 scheme with a single flow. Flows
+// without an authorization or token step ignore the matching URL.
+func OAuth2SecurityScheme(flow, authorizationURL, tokenURL string, scopes map[string]string) SecurityScheme {
+	return SecurityScheme{
+		Type:             SecuritySchemeOAuth2,
+		Flow:             flow,
+		AuthorizationURL: authorizationURL,
+		TokenURL:         tokenURL,
+		Scopes:           scopes,
+	}
+}
+
+// openAPI3 renders the scheme for components.securitySchemes.
+func (s SecurityScheme) openAPI3() map[string]interface{} {
+	out := map[string]interface{}{"type": s.Type}
+	if s.Description != "" {
+		out["description"] = s.Description
+	}
+	switch s.Type {
+	case SecuritySchemeHTTP:
+		out["scheme"] = s.Scheme
+		if s.BearerFormat != "" {
+			out["bearerFormat"] = s.BearerFormat
+		}
+	case SecuritySchemeAPIKey:
+		out["name"] = s.Name
+		out["in"] = s.In
+	case SecuritySchemeOAuth2:
+		out["flows"] = map[string]interface{}{s.Flow: s.oauth2Flow()}
+	}
+	return out
+}
+
+// swagger2 renders the scheme for securityDefinitions. Swagger 2.0 has no
+// http schemes, so bearer schemes become an Authorization header API key.
+func (s SecurityScheme) swagger2() map[string]interface{} {
+	out := map[string]interface{}{"type": s.Type}
+	if s.Description != "" {
+		out["description"] = s.Description
+	}
+	switch s.Type {
+	case SecuritySchemeHTTP:
+		out["type"] = SecuritySchemeAPIKey
+		out["name"] = "Authorization"
+		out["in"] = "header"
+	case SecuritySchemeAPIKey:
+		out["name"] = s.Name
+		out["in"] = s.In
+	case SecuritySchemeOAuth2:
+		for key, value := range s.oauth2Flow() {
+			out[key] = value
+		}
+		out["flow"] = swagger2Flows[s.Flow]
+	}
+	return out
+}
+
+// swagger2Flows maps OpenAPI 3.x flow names to Swagger 2.0 ones.
+var swagger2Flows = map[string]string{
+	OAuth2AuthorizationCode: "accessCode",
+	OAuth2ClientCredentials: "application",
+	OAuth2Implicit:          "implicit",
+	OAuth2Password:          "password",
+}
+
+func (s SecurityScheme) oauth2Flow() map[string]interface{} {
+	scopes := s.Scopes
+	if scopes == nil {
+		scopes = map[string]string{}
+	}
+	flow := map[string]interface{}{"scopes": scopes}
+	if s.AuthorizationURL != "" && (s.Flow == OAuth2AuthorizationCode || s.Flow == OAuth2Implicit) {
+		flow["authorizationUrl"] = s.AuthorizationURL
+	}
+	if s.TokenURL != "" && s.Flow != OAuth2Implicit {
+		flow["tokenUrl"] = s.TokenURL
+	}
+	return flow
+}
+
+// mergeSecuritySchemes adds schemes to the security definitions of spec,
+// replacing definitions of the same name.
+func mergeSecuritySchemes(spec map[string]interface{}, schemes map[string]SecurityScheme) {
+	if len(schemes) == 0 {
+		return
+	}
+
+	if _, ok := spec["openapi"]; ok {
+		components, ok := spec["components"].(map[string]interface{})
+		if !ok {
+			components = make(map[string]interface{})
+			spec["components"] = components
+		}
+		defs, ok := components["securitySchemes"].(map[string]interface{})
+		if !ok {
+			defs = make(map[string]interface{})
+			components["securitySchemes"] = defs
+		}
+		for name, scheme := range schemes {
+			defs[name] = scheme.openAPI3()
+		}
+		return
+	}
+
+	defs, ok := spec["securityDefinitions"].(map[string]interface{})
+	if !ok {
+		defs = make(map[string]interface{})
+		spec["securityDefinitions"] = defs
+	}
+	for name, scheme := range schemes {
+		defs[name] = scheme.swagger2()
+	}
+}
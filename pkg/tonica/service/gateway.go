@@ -0,0 +1,26 @@
+package service
+
+import "sync"
+
+var (
+	gatewayHandlersMu sync.RWMutex
+	gatewayHandlers   = map[string]GatewayRegistrar{}
+)
+
+// RegisterGatewayHandler records the generated gateway handler for a fully
+// qualified proto service name (e.g. "reports.v1.ReportsService"). Code
+// generated by `tonica wrap` calls it from init so the app can wire the
+// gateway for services registered without WithGateway.
+func RegisterGatewayHandler(protoService string, registrar GatewayRegistrar) {
+	gatewayHandlersMu.Lock()
+	defer gatewayHandlersMu.Unlock()
+	gatewayHandlers[protoService] = registrar
+}
+
+// GatewayHandler returns the handler recorded for protoService.
+func GatewayHandler(protoService string) (GatewayRegistrar, bool) {
+	gatewayHandlersMu.RLock()
+	defer gatewayHandlersMu.RUnlock()
+	registrar, ok := gatewayHandlers[protoService]
+	return registrar, ok
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"github.com/redis/go-redis/v9"
 	"github.com/tonica-go/tonica/pkg/tonica/grpc/serviceconfig"
+	"github.com/tonica-go/tonica/pkg/tonica/storage/blob"
 	"github.com/uptrace/bun"
 	"google.golang.org/grpc"
 )
@@ -30,6 +31,23 @@ func (s *Service) GetClientConn() *grpc.ClientConn {
 	})
 }
 
+// GetTLS returns the TLS configuration of the gRPC server, or nil.
+func (s *Service) GetTLS() *TLSConfig {
+	return s.config.TLS
+}
+
+// GetUnaryInterceptors returns the unary interceptors of the service's
+// methods, the concurrency limit first.
+func (s *Service) GetUnaryInterceptors() []grpc.UnaryServerInterceptor {
+	return s.unaryInterceptors
+}
+
+// GetStreamInterceptors returns the stream interceptors of the service's
+// methods, the concurrency limit first.
+func (s *Service) GetStreamInterceptors() []grpc.StreamServerInterceptor {
+	return s.streamInterceptors
+}
+
 func (s *Service) GetGateway() GatewayRegistrar {
 	return s.gatewayRegistrar
 }
@@ -67,3 +85,10 @@ func (s *Service) GetRedisClient() *redis.Client {
 	}
 	return rdb.GetClient()
 }
+
+func (s *Service) GetBlobStore() blob.Store {
+	if s.storage == nil {
+		return nil
+	}
+	return s.storage.blob
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	limitMetricsOnce  sync.Once
+	inflightRequests  metric.Int64UpDownCounter
+	rejectedRequests  metric.Int64Counter
+	limitWaitDuration metric.Float64Histogram
+)
+
+func initLimitInstruments() {
+	meter := otel.Meter("tonica/grpc")
+	inflightRequests, _ = meter.Int64UpDownCounter(
+		"grpc_server_inflight_requests",
+		metric.WithDescription("gRPC requests currently handled by services with a concurrency limit"),
+	)
+	rejectedRequests, _ = meter.Int64Counter(
+		"grpc_server_rejected_requests_total",
+		metric.WithDescription("gRPC requests rejected by a service concurrency limit"),
+	)
+	limitWaitDuration, _ = meter.Float64Histogram(
+		"grpc_server_limit_wait_ms",
+		metric.WithUnit("ms"),
+		metric.WithDescription("Time gRPC requests waited for a concurrency slot"),
+	)
+}
+
+// concurrencyLimiter bounds the requests a service handles at once.
+type concurrencyLimiter struct {
+	slots chan struct{}
+	wait  time.Duration
+	attrs metric.MeasurementOption
+}
+
+func newConcurrencyLimiter(service string, max int, wait time.Duration) *concurrencyLimiter {
+	limitMetricsOnce.Do(initLimitInstruments)
+	return &concurrencyLimiter{
+		slots: make(chan struct{}, max),
+		wait:  wait,
+		attrs: metric.WithAttributes(attribute.String("service", service)),
+	}
+}
+
+// acquire takes a slot, waiting at most l.wait for one. It fails with
+// ResourceExhausted when none frees up in time.
+func (l *concurrencyLimiter) acquire(ctx context.Context) (func(), error) {
+	release := func() {
+		<-l.slots
+		inflightRequests.Add(context.Background(), -1, l.attrs)
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		inflightRequests.Add(ctx, 1, l.attrs)
+		return release, nil
+	default:
+	}
+
+	if l.wait > 0 {
+		start := time.Now()
+		timer := time.NewTimer(l.wait)
+		defer timer.Stop()
+		select {
+		case l.slots <- struct{}{}:
+			limitWaitDuration.Record(ctx, float64(time.Since(start).Milliseconds()), l.attrs)
+			inflightRequests.Add(ctx, 1, l.attrs)
+			return release, nil
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		case <-timer.C:
+		}
+	}
+
+	rejectedRequests.Add(ctx, 1, l.attrs)
+	return nil, status.Error(codes.ResourceExhausted, "too many concurrent requests")
+}
+
+func (l *concurrencyLimiter) unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		release, err := l.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return handler(ctx, req)
+	}
+}
+
+func (l *concurrencyLimiter) stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		release, err := l.acquire(ss.Context())
+		if err != nil {
+			return err
+		}
+		defer release()
+		return handler(srv, ss)
+	}
+}
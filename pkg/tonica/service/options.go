@@ -3,6 +3,10 @@ package service
 import (
 	"log"
 	"slices"
+	"time"
+
+	"github.com/tonica-go/tonica/pkg/tonica/storage/blob"
+	"google.golang.org/grpc"
 )
 
 type Option func(*Service)
@@ -50,6 +54,13 @@ func WithPubSub(dsn string) Option {
 	}
 }
 
+// WithBlobStore attaches a blob store, available through GetBlobStore.
+func WithBlobStore(store blob.Store) Option {
+	return func(a *Service) {
+		a.storage.blob = store
+	}
+}
+
 func WithLogger(logger *log.Logger) Option {
 	return func(a *Service) {
 		a.logger = logger
@@ -78,6 +89,42 @@ func WithGRPCAddr(grpcAddr string) Option {
 	}
 }
 
+// WithTLS serves the service's gRPC server over TLS with the given key pair.
+// When caFile is set, clients must present a certificate signed by that CA.
+// Unreadable files stop the app at startup.
+func WithTLS(certFile, keyFile, caFile string) Option {
+	return func(a *Service) {
+		a.config.TLS = &TLSConfig{CertFile: certFile, KeyFile: keyFile, CAFile: caFile}
+	}
+}
+
+// WithMaxConcurrentRequests bounds the gRPC requests the service handles at
+// once. Excess requests wait up to wait for a free slot and are then rejected
+// with ResourceExhausted; a zero wait rejects them right away. Streams hold a
+// slot until they end.
+func WithMaxConcurrentRequests(n int, wait time.Duration) Option {
+	return func(a *Service) {
+		a.config.MaxConcurrentRequests = n
+		a.config.MaxConcurrentWait = wait
+	}
+}
+
+// WithUnaryInterceptors adds interceptors run for the unary methods of this
+// service only, after the framework interceptors.
+func WithUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) Option {
+	return func(a *Service) {
+		a.unaryInterceptors = append(a.unaryInterceptors, interceptors...)
+	}
+}
+
+// WithStreamInterceptors adds interceptors run for the streaming methods of
+// this service only, after the framework interceptors.
+func WithStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) Option {
+	return func(a *Service) {
+		a.streamInterceptors = append(a.streamInterceptors, interceptors...)
+	}
+}
+
 func WithGRPClient(client GRPCClient) Option {
 	return func(a *Service) {
 		a.grpcClient = client
@@ -3,10 +3,12 @@ package service
 import (
 	"context"
 	"log"
+	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/redis/go-redis/v9"
 	"github.com/tonica-go/tonica/pkg/tonica/grpc/serviceconfig"
+	"github.com/tonica-go/tonica/pkg/tonica/storage/blob"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/pgdialect"
 	"github.com/uptrace/bun/driver/pgdriver"
@@ -44,17 +46,29 @@ type Service struct {
 
 	gatewayRegistrar GatewayRegistrar
 	isGatewayEnabled bool
+
+	unaryInterceptors  []grpc.UnaryServerInterceptor
+	streamInterceptors []grpc.StreamServerInterceptor
 }
 
 type Config struct {
 	Name     string
 	GrpcAddr string
+	// TLS, when set, makes the service's gRPC server serve TLS.
+	TLS *TLSConfig
+	// MaxConcurrentRequests bounds the requests handled at once. Zero means
+	// unlimited.
+	MaxConcurrentRequests int
+	// MaxConcurrentWait is how long an excess request waits for a free slot
+	// before it is rejected. Zero rejects it right away.
+	MaxConcurrentWait time.Duration
 }
 
 type Storage struct {
 	db     *DB
 	rdb    *Redis
 	pubsub *PubSub
+	blob   blob.Store
 }
 
 type DB struct {
@@ -156,5 +170,11 @@ func NewService(options ...Option) *Service {
 		option(app)
 	}
 
+	if app.config.MaxConcurrentRequests > 0 {
+		limiter := newConcurrencyLimiter(app.config.Name, app.config.MaxConcurrentRequests, app.config.MaxConcurrentWait)
+		app.unaryInterceptors = append([]grpc.UnaryServerInterceptor{limiter.unary()}, app.unaryInterceptors...)
+		app.streamInterceptors = append([]grpc.StreamServerInterceptor{limiter.stream()}, app.streamInterceptors...)
+	}
+
 	return app
 }
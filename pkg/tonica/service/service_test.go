@@ -1,10 +1,17 @@
 package service
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestRedis_GetClient(t *testing.T) {
@@ -154,3 +161,74 @@ func TestNewService(t *testing.T) {
 		assert.Equal(t, ":9999", svc.config.GrpcAddr)
 	})
 }
+
+func TestTLSConfig_ServerCredentials(t *testing.T) {
+	t.Run("should fail on missing files", func(t *testing.T) {
+		cfg := &TLSConfig{CertFile: "missing.crt", KeyFile: "missing.key"}
+		_, err := cfg.ServerCredentials()
+		assert.ErrorContains(t, err, "load TLS key pair")
+	})
+
+	t.Run("should fail on a CA file without certificates", func(t *testing.T) {
+		ca := filepath.Join(t.TempDir(), "ca.pem")
+		assert.NoError(t, os.WriteFile(ca, []byte("not a certificate"), 0o600))
+		_, err := LoadCertPool(ca)
+		assert.ErrorContains(t, err, "no certificates found")
+	})
+
+	t.Run("should be set by WithTLS", func(t *testing.T) {
+		svc := NewService(WithTLS("server.crt", "server.key", "ca.crt"))
+		assert.Equal(t, &TLSConfig{CertFile: "server.crt", KeyFile: "server.key", CAFile: "ca.crt"}, svc.GetTLS())
+		assert.Nil(t, NewService().GetTLS())
+	})
+}
+
+func TestWithMaxConcurrentRequests(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Svc/Call"}
+
+	// hold starts a call that keeps its slot until the returned func is called.
+	hold := func(interceptor grpc.UnaryServerInterceptor) func() {
+		started, done := make(chan struct{}), make(chan struct{})
+		go func() {
+			_, _ = interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+				close(started)
+				<-done
+				return nil, nil
+			})
+		}()
+		<-started
+		return func() { close(done) }
+	}
+	ok := func(context.Context, any) (any, error) { return "ok", nil }
+
+	t.Run("should be unlimited by default", func(t *testing.T) {
+		assert.Empty(t, NewService(WithName("plain")).GetUnaryInterceptors())
+	})
+
+	t.Run("should reject excess requests", func(t *testing.T) {
+		svc := NewService(WithName("limited"), WithMaxConcurrentRequests(1, 0))
+		require.Len(t, svc.GetUnaryInterceptors(), 1)
+		interceptor := svc.GetUnaryInterceptors()[0]
+
+		release := hold(interceptor)
+		_, err := interceptor(context.Background(), nil, info, ok)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+		release()
+		assert.Eventually(t, func() bool {
+			resp, err := interceptor(context.Background(), nil, info, ok)
+			return err == nil && resp == "ok"
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("should wait for a free slot", func(t *testing.T) {
+		svc := NewService(WithName("queued"), WithMaxConcurrentRequests(1, time.Second))
+		interceptor := svc.GetUnaryInterceptors()[0]
+
+		release := hold(interceptor)
+		time.AfterFunc(50*time.Millisecond, release)
+		resp, err := interceptor(context.Background(), nil, info, ok)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+	})
+}
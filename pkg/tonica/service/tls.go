@@ -0,0 +1,45 @@
+package service
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/tonica-go/tonica/pkg/tonica/grpc/serviceconfig"
+	"google.golang.org/grpc/credentials"
+)
+
+// TLSConfig holds the files a gRPC server serves TLS with.
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// CAFile, when set, makes the server require client certificates
+	// signed by this CA (mTLS).
+	CAFile string
+}
+
+// ServerCredentials loads the certificate files into gRPC server credentials.
+func (c *TLSConfig) ServerCredentials() (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load TLS key pair: %w", err)
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if c.CAFile != "" {
+		pool, err := LoadCertPool(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
+// LoadCertPool reads a PEM encoded CA bundle.
+func LoadCertPool(caFile string) (*x509.CertPool, error) {
+	return serviceconfig.LoadCertPool(caFile)
+}
@@ -2,18 +2,52 @@ package tonica
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"slices"
 	"sync"
 	"time"
 
 	"google.golang.org/grpc"
 )
 
+// ShutdownPhase orders the shutdown of app components. Phases run one after
+// another in ascending order; components within a phase stop in parallel.
+type ShutdownPhase int
+
+const (
+	// PhaseStopIntake stops accepting new work: servers, consumers and workers.
+	PhaseStopIntake ShutdownPhase = iota
+	// PhaseDrain waits for in-flight handlers and writes to finish.
+	PhaseDrain
+	// PhaseCloseResources closes stores, connections and pools.
+	PhaseCloseResources
+)
+
+// String returns the phase name used in logs.
+func (p ShutdownPhase) String() string {
+	switch p {
+	case PhaseStopIntake:
+		return "stop-intake"
+	case PhaseDrain:
+		return "drain"
+	case PhaseCloseResources:
+		return "close-resources"
+	default:
+		return fmt.Sprintf("phase-%d", int(p))
+	}
+}
+
+type cleanupFn struct {
+	phase ShutdownPhase
+	fn    func(context.Context) error
+}
+
 // Shutdown coordinates graceful shutdown of all app components
 type Shutdown struct {
 	httpServers []*http.Server
 	grpcServers []*grpc.Server
-	cleanupFns  []func(context.Context) error
+	cleanupFns  []cleanupFn
 	mu          sync.Mutex
 }
 
@@ -22,32 +56,41 @@ func NewShutdown() *Shutdown {
 	return &Shutdown{
 		httpServers: make([]*http.Server, 0),
 		grpcServers: make([]*grpc.Server, 0),
-		cleanupFns:  make([]func(context.Context) error, 0),
+		cleanupFns:  make([]cleanupFn, 0),
 	}
 }
 
-// RegisterHTTPServer adds an HTTP server to be gracefully stopped
+// RegisterHTTPServer adds an HTTP server to be gracefully stopped.
+// Servers stop in PhaseStopIntake.
 func (s *Shutdown) RegisterHTTPServer(srv *http.Server) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.httpServers = append(s.httpServers, srv)
 }
 
-// RegisterGRPCServer adds a gRPC server to be gracefully stopped
+// RegisterGRPCServer adds a gRPC server to be gracefully stopped.
+// Servers stop in PhaseStopIntake.
 func (s *Shutdown) RegisterGRPCServer(srv *grpc.Server) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.grpcServers = append(s.grpcServers, srv)
 }
 
-// RegisterCleanup adds a cleanup function to be called during shutdown
+// RegisterCleanup adds a cleanup function to be called during shutdown.
+// It runs in PhaseCloseResources, after servers and consumers have stopped.
 func (s *Shutdown) RegisterCleanup(fn func(context.Context) error) {
+	s.RegisterCleanupInPhase(PhaseCloseResources, fn)
+}
+
+// RegisterCleanupInPhase adds a cleanup function that runs in the given phase.
+func (s *Shutdown) RegisterCleanupInPhase(phase ShutdownPhase, fn func(context.Context) error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.cleanupFns = append(s.cleanupFns, fn)
+	s.cleanupFns = append(s.cleanupFns, cleanupFn{phase: phase, fn: fn})
 }
 
-// Execute performs graceful shutdown of all registered components
+// Execute performs graceful shutdown of all registered components, phase by
+// phase. The timeout covers the whole shutdown, not each phase.
 func (s *Shutdown) Execute(timeout time.Duration) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -55,51 +98,90 @@ func (s *Shutdown) Execute(timeout time.Duration) error {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
+	var firstErr error
+	for _, phase := range s.phases() {
+		err := s.executePhase(ctx, phase)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// phases returns the distinct phases with registered components in order.
+func (s *Shutdown) phases() []ShutdownPhase {
+	seen := make(map[ShutdownPhase]struct{})
+	if len(s.httpServers) > 0 || len(s.grpcServers) > 0 {
+		seen[PhaseStopIntake] = struct{}{}
+	}
+	for _, c := range s.cleanupFns {
+		seen[c.phase] = struct{}{}
+	}
+
+	phases := make([]ShutdownPhase, 0, len(seen))
+	for phase := range seen {
+		phases = append(phases, phase)
+	}
+	slices.Sort(phases)
+	return phases
+}
+
+// executePhase stops every component of a phase in parallel and returns the
+// first error.
+func (s *Shutdown) executePhase(ctx context.Context, phase ShutdownPhase) error {
 	var wg sync.WaitGroup
 	errCh := make(chan error, len(s.httpServers)+len(s.grpcServers)+len(s.cleanupFns))
 
-	// Shutdown HTTP servers
-	for _, srv := range s.httpServers {
-		wg.Add(1)
-		go func(server *http.Server) {
-			defer wg.Done()
-			if err := server.Shutdown(ctx); err != nil {
-				errCh <- err
-			}
-		}(srv)
-	}
+	if phase == PhaseStopIntake {
+		// Shutdown HTTP servers
+		for _, srv := range s.httpServers {
+			wg.Add(1)
+			go func(server *http.Server) {
+				defer wg.Done()
+				if err := server.Shutdown(ctx); err != nil {
+					errCh <- err
+				}
+			}(srv)
+		}
 
-	// Shutdown gRPC servers
-	for _, srv := range s.grpcServers {
-		wg.Add(1)
-		go func(server *grpc.Server) {
-			defer wg.Done()
-			// GracefulStop stops accepting new requests and waits for existing to complete
-			stopped := make(chan struct{})
-			go func() {
-				server.GracefulStop()
-				close(stopped)
-			}()
-
-			select {
-			case <-stopped:
-				// Graceful stop completed
-			case <-ctx.Done():
-				// Timeout reached, force stop
-				server.Stop()
-			}
-		}(srv)
+		// Shutdown gRPC servers
+		for _, srv := range s.grpcServers {
+			wg.Add(1)
+			go func(server *grpc.Server) {
+				defer wg.Done()
+				// GracefulStop stops accepting new requests and waits for existing to complete
+				stopped := make(chan struct{})
+				go func() {
+					server.GracefulStop()
+					close(stopped)
+				}()
+
+				select {
+				case <-stopped:
+					// Graceful stop completed
+				case <-ctx.Done():
+					// Timeout reached, force stop
+					server.Stop()
+				}
+			}(srv)
+		}
 	}
 
-	// Run cleanup functions
-	for _, fn := range s.cleanupFns {
+	// Run cleanup functions of this phase
+	for _, c := range s.cleanupFns {
+		if c.phase != phase {
+			continue
+		}
 		wg.Add(1)
 		go func(cleanup func(context.Context) error) {
 			defer wg.Done()
 			if err := cleanup(ctx); err != nil {
 				errCh <- err
 			}
-		}(fn)
+		}(c.fn)
 	}
 
 	// Wait for all shutdowns to complete
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net"
 	"net/http"
+	"sync"
 	"testing"
 	"time"
 
@@ -225,6 +226,52 @@ func TestShutdown_Execute_Multiple(t *testing.T) {
 	})
 }
 
+func TestShutdown_Execute_Phases(t *testing.T) {
+	t.Run("should run phases in order", func(t *testing.T) {
+		s := NewShutdown()
+
+		var mu sync.Mutex
+		var order []ShutdownPhase
+		record := func(phase ShutdownPhase) func(context.Context) error {
+			return func(ctx context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				order = append(order, phase)
+				return nil
+			}
+		}
+
+		// Register out of order to make sure phases, not registration, decide
+		s.RegisterCleanup(record(PhaseCloseResources))
+		s.RegisterCleanupInPhase(PhaseDrain, record(PhaseDrain))
+		s.RegisterCleanupInPhase(PhaseStopIntake, record(PhaseStopIntake))
+		s.RegisterCleanupInPhase(PhaseDrain, record(PhaseDrain))
+
+		err := s.Execute(1 * time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, []ShutdownPhase{PhaseStopIntake, PhaseDrain, PhaseDrain, PhaseCloseResources}, order)
+	})
+
+	t.Run("should continue with later phases after an error", func(t *testing.T) {
+		s := NewShutdown()
+
+		testErr := errors.New("drain error")
+		closed := false
+
+		s.RegisterCleanupInPhase(PhaseDrain, func(ctx context.Context) error {
+			return testErr
+		})
+		s.RegisterCleanup(func(ctx context.Context) error {
+			closed = true
+			return nil
+		})
+
+		err := s.Execute(1 * time.Second)
+		assert.Equal(t, testErr, err)
+		assert.True(t, closed)
+	})
+}
+
 func TestShutdown_Execute_Empty(t *testing.T) {
 	t.Run("should complete immediately with no registered components", func(t *testing.T) {
 		s := NewShutdown()
@@ -0,0 +1,43 @@
+// Package blob provides object storage for binary data such as uploads. An
+// S3-compatible implementation is provided for production and a local
+// filesystem implementation for development.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned when a key does not exist.
+var ErrNotFound = errors.New("blob not found")
+
+// Metadata keys set by Put callers and returned on Get.
+const (
+	MetadataContentType = "content-type"
+	MetadataFilename    = "filename"
+)
+
+// Store reads and writes blobs by key. Entity fields can hold blob keys
+// instead of inline data.
+type Store interface {
+	// Put streams r to key. The content-type metadata entry, if any, is
+	// stored as the object's content type.
+	Put(ctx context.Context, key string, r io.Reader, metadata map[string]string) error
+	// Get opens the blob at key. The caller must close the returned object.
+	Get(ctx context.Context, key string) (*Object, error)
+	// Delete removes the blob at key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that grants read access to key until expiry.
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// Object is an open blob.
+type Object struct {
+	io.ReadCloser
+	Key         string
+	Size        int64
+	ContentType string
+	Metadata    map[string]string
+}
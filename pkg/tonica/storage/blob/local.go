@@ -0,0 +1,234 @@
+package blob
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	backendLocal = "local"
+	metaDir      = ".meta"
+)
+
+// LocalConfig configures a filesystem store.
+type LocalConfig struct {
+	// Root is the directory blobs are written to. It is created if missing.
+	Root string
+	// BaseURL is where Handler is mounted, e.g. "http://localhost:8080/blobs".
+	// It is used to build signed URLs.
+	BaseURL string
+	// Secret signs URLs. A random secret is generated when empty, so signed
+	// URLs stop working after a restart.
+	Secret []byte
+}
+
+// Local stores blobs on the local filesystem. It is meant for development;
+// metadata is kept in JSON files next to the data.
+type Local struct {
+	root    string
+	baseURL string
+	secret  []byte
+}
+
+// NewLocal creates a filesystem store rooted at cfg.Root.
+func NewLocal(cfg LocalConfig) (*Local, error) {
+	if cfg.Root == "" {
+		return nil, errors.New("blob: local root is required")
+	}
+	if err := os.MkdirAll(cfg.Root, 0o755); err != nil {
+		return nil, fmt.Errorf("blob: create root: %w", err)
+	}
+
+	secret := cfg.Secret
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("blob: generate secret: %w", err)
+		}
+	}
+
+	return &Local{
+		root:    cfg.Root,
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
+		secret:  secret,
+	}, nil
+}
+
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, metadata map[string]string) (err error) {
+	ctx, op := startOperation(ctx, backendLocal, "put", key)
+	defer func() { op.end(ctx, err) }()
+
+	dataPath, metaPath, err := l.paths(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dataPath), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dataPath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), dataPath); err != nil {
+		return err
+	}
+	recordWritten(ctx, backendLocal, n)
+
+	if err := os.MkdirAll(filepath.Dir(metaPath), 0o755); err != nil {
+		return err
+	}
+	meta, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(metaPath, meta, 0o644)
+}
+
+func (l *Local) Get(ctx context.Context, key string) (_ *Object, err error) {
+	ctx, op := startOperation(ctx, backendLocal, "get", key)
+	defer func() { op.end(ctx, err) }()
+
+	dataPath, metaPath, err := l.paths(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(dataPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	metadata := make(map[string]string)
+	if raw, err := os.ReadFile(metaPath); err == nil {
+		_ = json.Unmarshal(raw, &metadata)
+	}
+
+	return &Object{
+		ReadCloser:  file,
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: metadata[MetadataContentType],
+		Metadata:    metadata,
+	}, nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) (err error) {
+	ctx, op := startOperation(ctx, backendLocal, "delete", key)
+	defer func() { op.end(ctx, err) }()
+
+	dataPath, metaPath, err := l.paths(key)
+	if err != nil {
+		return err
+	}
+	for _, p := range []string{dataPath, metaPath} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// SignedURL returns a URL served by Handler that is valid until expiry.
+func (l *Local) SignedURL(ctx context.Context, key string, expiry time.Duration) (_ string, err error) {
+	ctx, op := startOperation(ctx, backendLocal, "signed_url", key)
+	defer func() { op.end(ctx, err) }()
+
+	if _, _, err := l.paths(key); err != nil {
+		return "", err
+	}
+	if l.baseURL == "" {
+		return "", errors.New("blob: local base url is not configured")
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+	query := url.Values{
+		"expires":   {expires},
+		"signature": {l.sign(key, expires)},
+	}
+	return l.baseURL + "/" + (&url.URL{Path: key}).EscapedPath() + "?" + query.Encode(), nil
+}
+
+// Handler serves blobs addressed by signed URLs. Mount it at BaseURL with the
+// prefix stripped, e.g. http.StripPrefix("/blobs", store.Handler()).
+func (l *Local) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		expires := r.URL.Query().Get("expires")
+		signature := r.URL.Query().Get("signature")
+
+		unix, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil || time.Now().Unix() > unix ||
+			!hmac.Equal([]byte(signature), []byte(l.sign(key, expires))) {
+			http.Error(w, "invalid or expired signature", http.StatusForbidden)
+			return
+		}
+
+		obj, err := l.Get(r.Context(), key)
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, "failed to read blob", http.StatusInternalServerError)
+			return
+		}
+		defer obj.Close()
+
+		if obj.ContentType != "" {
+			w.Header().Set("Content-Type", obj.ContentType)
+		}
+		w.Header().Set("Content-Length", strconv.FormatInt(obj.Size, 10))
+		_, _ = io.Copy(w, obj)
+	})
+}
+
+func (l *Local) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// paths maps a key to its data and metadata files, rejecting keys that would
+// escape the root.
+func (l *Local) paths(key string) (string, string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || clean == "/" || strings.HasPrefix(clean, "/"+metaDir+"/") || clean != "/"+key {
+		return "", "", fmt.Errorf("blob: invalid key %q", key)
+	}
+	rel := filepath.FromSlash(strings.TrimPrefix(clean, "/"))
+	return filepath.Join(l.root, rel), filepath.Join(l.root, metaDir, rel+".json"), nil
+}
@@ -0,0 +1,129 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLocal(t *testing.T) *Local {
+	t.Helper()
+	store, err := NewLocal(LocalConfig{
+		Root:    t.TempDir(),
+		BaseURL: "http://localhost/blobs",
+		Secret:  []byte("secret"),
+	})
+	require.NoError(t, err)
+	return store
+}
+
+func TestLocal_PutGetDelete(t *testing.T) {
+	store := newTestLocal(t)
+	ctx := context.Background()
+
+	err := store.Put(ctx, "docs/a.txt", strings.NewReader("hello"), map[string]string{MetadataContentType: "text/plain"})
+	require.NoError(t, err)
+
+	obj, err := store.Get(ctx, "docs/a.txt")
+	require.NoError(t, err)
+	data, err := io.ReadAll(obj)
+	require.NoError(t, err)
+	require.NoError(t, obj.Close())
+	assert.Equal(t, "hello", string(data))
+	assert.Equal(t, int64(5), obj.Size)
+	assert.Equal(t, "text/plain", obj.ContentType)
+
+	require.NoError(t, store.Delete(ctx, "docs/a.txt"))
+	require.NoError(t, store.Delete(ctx, "docs/a.txt"))
+	_, err = store.Get(ctx, "docs/a.txt")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLocal_RejectsEscapingKeys(t *testing.T) {
+	store := newTestLocal(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"", "/", "../outside", "docs/../../outside", "/abs", "docs//a", ".meta/docs/a.json"} {
+		err := store.Put(ctx, key, strings.NewReader("x"), nil)
+		assert.ErrorContains(t, err, "invalid key", key)
+
+		_, err = store.Get(ctx, key)
+		assert.ErrorContains(t, err, "invalid key", key)
+
+		_, err = store.SignedURL(ctx, key, time.Minute)
+		assert.ErrorContains(t, err, "invalid key", key)
+	}
+}
+
+func TestLocal_SignedURL(t *testing.T) {
+	store := newTestLocal(t)
+	ctx := context.Background()
+	require.NoError(t, store.Put(ctx, "docs/a b.txt", strings.NewReader("hello"), map[string]string{MetadataContentType: "text/plain"}))
+
+	signed, err := store.SignedURL(ctx, "docs/a b.txt", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(signed, "http://localhost/blobs/docs/a%20b.txt?"), signed)
+
+	handler := http.StripPrefix("/blobs", store.Handler())
+	serve := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+
+	t.Run("valid", func(t *testing.T) {
+		rec := serve(u.RequestURI())
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "hello", rec.Body.String())
+		assert.Equal(t, "text/plain", rec.Header().Get("Content-Type"))
+	})
+
+	t.Run("tampered signature", func(t *testing.T) {
+		query := u.Query()
+		query.Set("signature", strings.Repeat("0", 64))
+		rec := serve(u.EscapedPath() + "?" + query.Encode())
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("other key", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "docs/b.txt", strings.NewReader("secret"), nil))
+		rec := serve("/blobs/docs/b.txt?" + u.RawQuery)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("extended expiry", func(t *testing.T) {
+		query := u.Query()
+		query.Set("expires", "99999999999")
+		rec := serve(u.EscapedPath() + "?" + query.Encode())
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		expired, err := store.SignedURL(ctx, "docs/a b.txt", -time.Minute)
+		require.NoError(t, err)
+		u, err := url.Parse(expired)
+		require.NoError(t, err)
+		rec := serve(u.RequestURI())
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("missing blob", func(t *testing.T) {
+		missing, err := store.SignedURL(ctx, "docs/missing.txt", time.Minute)
+		require.NoError(t, err)
+		u, err := url.Parse(missing)
+		require.NoError(t, err)
+		rec := serve(u.RequestURI())
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
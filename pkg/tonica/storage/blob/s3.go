@@ -0,0 +1,146 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const backendS3 = "s3"
+
+// S3Config configures an S3-compatible store (AWS S3, MinIO, GCS interop,
+// R2 and similar).
+type S3Config struct {
+	// Endpoint is the host of the service, e.g. "s3.amazonaws.com" or
+	// "localhost:9000".
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// UseSSL connects over HTTPS.
+	UseSSL bool
+	// CreateBucket creates the bucket on startup when it does not exist.
+	CreateBucket bool
+}
+
+// S3 stores blobs in a bucket of an S3-compatible service.
+type S3 struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3 connects to the bucket described by cfg.
+func NewS3(ctx context.Context, cfg S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("blob: s3 endpoint and bucket are required")
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("blob: create s3 client: %w", err)
+	}
+
+	if cfg.CreateBucket {
+		exists, err := client.BucketExists(ctx, cfg.Bucket)
+		if err != nil {
+			return nil, fmt.Errorf("blob: check bucket %s: %w", cfg.Bucket, err)
+		}
+		if !exists {
+			if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+				return nil, fmt.Errorf("blob: create bucket %s: %w", cfg.Bucket, err)
+			}
+		}
+	}
+
+	return &S3{client: client, bucket: cfg.Bucket}, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, metadata map[string]string) (err error) {
+	ctx, op := startOperation(ctx, backendS3, "put", key)
+	defer func() { op.end(ctx, err) }()
+
+	opts := minio.PutObjectOptions{UserMetadata: make(map[string]string, len(metadata))}
+	for k, v := range metadata {
+		if k == MetadataContentType {
+			opts.ContentType = v
+			continue
+		}
+		opts.UserMetadata[k] = v
+	}
+
+	info, err := s.client.PutObject(ctx, s.bucket, key, r, -1, opts)
+	if err != nil {
+		return err
+	}
+	recordWritten(ctx, backendS3, info.Size)
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (_ *Object, err error) {
+	ctx, op := startOperation(ctx, backendS3, "get", key)
+	defer func() { op.end(ctx, err) }()
+
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s.mapError(key, err)
+	}
+	info, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, s.mapError(key, err)
+	}
+
+	metadata := make(map[string]string, len(info.UserMetadata)+1)
+	for k, v := range info.UserMetadata {
+		metadata[strings.ToLower(k)] = v
+	}
+	if info.ContentType != "" {
+		metadata[MetadataContentType] = info.ContentType
+	}
+
+	return &Object{
+		ReadCloser:  obj,
+		Key:         key,
+		Size:        info.Size,
+		ContentType: info.ContentType,
+		Metadata:    metadata,
+	}, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) (err error) {
+	ctx, op := startOperation(ctx, backendS3, "delete", key)
+	defer func() { op.end(ctx, err) }()
+
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// SignedURL returns a presigned GET URL.
+func (s *S3) SignedURL(ctx context.Context, key string, expiry time.Duration) (_ string, err error) {
+	ctx, op := startOperation(ctx, backendS3, "signed_url", key)
+	defer func() { op.end(ctx, err) }()
+
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, key, expiry, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func (s *S3) mapError(key string, err error) error {
+	if resp := minio.ToErrorResponse(err); resp.Code == "NoSuchKey" || resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return err
+}
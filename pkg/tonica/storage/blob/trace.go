@@ -0,0 +1,81 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	metricsOnce  sync.Once
+	opDuration   metric.Float64Histogram
+	bytesWritten metric.Int64Counter
+)
+
+// initInstruments registers the blob instruments on first use so the global
+// meter provider configured at startup is picked up.
+func initInstruments() {
+	meter := otel.Meter("tonica/blob")
+	opDuration, _ = meter.Float64Histogram(
+		"app_blob_operation_duration_seconds",
+		metric.WithDescription("Duration of blob store operations"),
+		metric.WithUnit("s"),
+	)
+	bytesWritten, _ = meter.Int64Counter(
+		"app_blob_written_bytes_total",
+		metric.WithDescription("Bytes written to blob stores"),
+		metric.WithUnit("By"),
+	)
+}
+
+// operation tracks a single store call as a span plus a duration sample.
+type operation struct {
+	span    trace.Span
+	start   time.Time
+	backend string
+	name    string
+}
+
+func startOperation(ctx context.Context, backend, name, key string) (context.Context, *operation) {
+	metricsOnce.Do(initInstruments)
+	ctx, span := otel.Tracer("tonica/blob").Start(ctx, "blob "+name, trace.WithAttributes(
+		attribute.String("blob.backend", backend),
+		attribute.String("blob.key", key),
+	))
+	return ctx, &operation{span: span, start: time.Now(), backend: backend, name: name}
+}
+
+// end finishes the operation. A missing key is not recorded as a span error.
+func (o *operation) end(ctx context.Context, err error) {
+	result := "ok"
+	switch {
+	case errors.Is(err, ErrNotFound):
+		result = "not_found"
+	case err != nil:
+		result = "error"
+		o.span.RecordError(err)
+		o.span.SetStatus(codes.Error, err.Error())
+	}
+	o.span.End()
+
+	if opDuration != nil {
+		opDuration.Record(ctx, time.Since(o.start).Seconds(), metric.WithAttributes(
+			attribute.String("backend", o.backend),
+			attribute.String("operation", o.name),
+			attribute.String("result", result),
+		))
+	}
+}
+
+func recordWritten(ctx context.Context, backend string, n int64) {
+	if bytesWritten != nil && n > 0 {
+		bytesWritten.Add(ctx, n, metric.WithAttributes(attribute.String("backend", backend)))
+	}
+}
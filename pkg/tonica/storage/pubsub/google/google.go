@@ -110,9 +110,17 @@ func connect(conf Config) (*gcPubSub.Client, error) {
 }
 
 func (g *googleClient) Publish(ctx context.Context, topic string, message []byte) error {
+	return g.PublishMessage(ctx, topic, &pubsub.Message{Value: message})
+}
+
+// PublishMessage publishes the message value with its headers as attributes.
+// A non-empty key is used as the ordering key.
+func (g *googleClient) PublishMessage(ctx context.Context, topic string, msg *pubsub.Message) error {
 	ctx, span := otel.GetTracerProvider().Tracer("gocrux").Start(ctx, "publish-gcp")
 	defer span.End()
 
+	pubsub.InjectTraceContext(ctx, msg)
+
 	g.metrics.IncrementCounter(ctx, "app_pubsub_publish_total_count", "topic", topic)
 
 	t, err := g.getTopic(ctx, topic)
@@ -122,9 +130,13 @@ func (g *googleClient) Publish(ctx context.Context, topic string, message []byte
 		return err
 	}
 
+	t.EnableMessageOrdering = len(msg.Key) > 0
+
 	//start := time.Now()
 	result := t.Publish(ctx, &gcPubSub.Message{
-		Data:        message,
+		Data:        msg.Value,
+		Attributes:  toAttributes(msg.Headers),
+		OrderingKey: string(msg.Key),
 		PublishTime: time.Now(),
 	})
 	//end := time.Since(start)
@@ -188,7 +200,11 @@ func (g *googleClient) Subscribe(ctx context.Context, topic string) (*pubsub.Mes
 
 			m.Topic = topic
 			m.Value = msg.Data
+			m.Headers = fromAttributes(msg.Attributes)
 			m.MetaData = msg.Attributes
+			if msg.OrderingKey != "" {
+				m.Key = []byte(msg.OrderingKey)
+			}
 			m.Committer = newGoogleMessage(msg)
 
 			g.mu.Lock()
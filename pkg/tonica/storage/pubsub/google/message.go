@@ -15,3 +15,29 @@ func newGoogleMessage(msg *gcPubSub.Message) *googleMessage {
 func (gm *googleMessage) Commit() {
 	gm.msg.Ack()
 }
+
+func toAttributes(headers map[string][]byte) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	attributes := make(map[string]string, len(headers))
+	for key, value := range headers {
+		attributes[key] = string(value)
+	}
+
+	return attributes
+}
+
+func fromAttributes(attributes map[string]string) map[string][]byte {
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	headers := make(map[string][]byte, len(attributes))
+	for key, value := range attributes {
+		headers[key] = []byte(value)
+	}
+
+	return headers
+}
@@ -10,6 +10,12 @@ type Publisher interface {
 	Publish(ctx context.Context, topic string, message []byte) error
 }
 
+// MessagePublisher publishes a message together with its key and headers.
+// Clients implement it next to Publisher, which only sends the value.
+type MessagePublisher interface {
+	PublishMessage(ctx context.Context, topic string, msg *Message) error
+}
+
 type Subscriber interface {
 	Subscribe(ctx context.Context, topic string) (*Message, error)
 }
@@ -97,9 +97,16 @@ func New(conf *Config, metrics Metrics) *kafkaClient { //nolint:revive // New al
 }
 
 func (k *kafkaClient) Publish(ctx context.Context, topic string, message []byte) error {
+	return k.PublishMessage(ctx, topic, &pubsub.Message{Value: message})
+}
+
+// PublishMessage writes the message value along with its key and headers.
+func (k *kafkaClient) PublishMessage(ctx context.Context, topic string, msg *pubsub.Message) error {
 	ctx, span := otel.GetTracerProvider().Tracer("gocrux").Start(ctx, "kafka-publish")
 	defer span.End()
 
+	pubsub.InjectTraceContext(ctx, msg)
+
 	k.metrics.IncrementCounter(ctx, "app_pubsub_publish_total_count", "topic", topic)
 
 	if k.writer == nil || topic == "" {
@@ -109,9 +116,11 @@ func (k *kafkaClient) Publish(ctx context.Context, topic string, message []byte)
 	//start := time.Now()
 	err := k.writer.WriteMessages(ctx,
 		kafka.Message{
-			Topic: topic,
-			Value: message,
-			Time:  time.Now(),
+			Topic:   topic,
+			Key:     msg.Key,
+			Value:   msg.Value,
+			Headers: toKafkaHeaders(msg.Headers),
+			Time:    time.Now(),
 		},
 	)
 	//end := time.Since(start)
@@ -212,7 +221,9 @@ func (k *kafkaClient) Subscribe(ctx context.Context, topic string) (*pubsub.Mess
 	}
 
 	m := pubsub.NewMessage(ctx)
+	m.Key = msg.Key
 	m.Value = msg.Value
+	m.Headers = fromKafkaHeaders(msg.Headers)
 	m.Topic = topic
 	m.Committer = newKafkaMessage(&msg, k.reader[topic])
 
@@ -27,3 +27,29 @@ func (kmsg *kafkaMessage) Commit() {
 		}
 	}
 }
+
+func toKafkaHeaders(headers map[string][]byte) []kafka.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	result := make([]kafka.Header, 0, len(headers))
+	for key, value := range headers {
+		result = append(result, kafka.Header{Key: key, Value: value})
+	}
+
+	return result
+}
+
+func fromKafkaHeaders(headers []kafka.Header) map[string][]byte {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	result := make(map[string][]byte, len(headers))
+	for _, h := range headers {
+		result[h.Key] = h.Value
+	}
+
+	return result
+}
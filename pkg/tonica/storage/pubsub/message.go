@@ -13,8 +13,16 @@ var errNotPointer = errors.New("input should be a pointer to a variable")
 type Message struct {
 	ctx context.Context
 
-	Topic    string
-	Value    []byte
+	Topic string
+	// Key identifies the message for partitioning and idempotency.
+	Key   []byte
+	Value []byte
+	// Headers carry transport level attributes such as trace context or
+	// schema version.
+	Headers map[string][]byte
+	// Attempt is the 1-based number of the current handling attempt when the
+	// message is handled by a consumer with retries.
+	Attempt  int
 	MetaData any
 
 	Committer
@@ -32,6 +40,19 @@ func (m *Message) Context() context.Context {
 	return m.ctx
 }
 
+// Header returns the value of the named header or an empty string.
+func (m *Message) Header(name string) string {
+	return string(m.Headers[name])
+}
+
+// SetHeader sets the named header, allocating the header map if needed.
+func (m *Message) SetHeader(name, value string) {
+	if m.Headers == nil {
+		m.Headers = make(map[string][]byte)
+	}
+	m.Headers[name] = []byte(value)
+}
+
 func (m *Message) Param(p string) string {
 	if p == "topic" {
 		return m.Topic
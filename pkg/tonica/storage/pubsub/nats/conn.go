@@ -0,0 +1,220 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// initialize connects to the servers and ensures the stream exists.
+func (n *natsClient) initialize(ctx context.Context) error {
+	opts, err := connectOptions(&n.config)
+	if err != nil {
+		return err
+	}
+
+	conn, err := natsgo.Connect(strings.Join(n.config.Servers, ","), opts...)
+	if err != nil {
+		return err
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	stream, err := n.ensureStream(ctx, js)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("create stream %s: %w", n.config.Stream, err)
+	}
+
+	slog.Info(fmt.Sprintf("connected to NATS at '%s'", conn.ConnectedUrlRedacted()))
+
+	n.mu.Lock()
+	n.conn = conn
+	n.js = js
+	n.stream = stream
+	n.consumers = make(map[string]jetstream.Consumer)
+	n.mu.Unlock()
+
+	return nil
+}
+
+// ensureStream looks the stream up and creates it when it is missing. An
+// existing stream keeps its configuration, including subjects added by
+// CreateTopic; configured subjects it does not capture yet are added to it.
+func (n *natsClient) ensureStream(ctx context.Context, js jetstream.JetStream) (jetstream.Stream, error) {
+	stream, err := js.Stream(ctx, n.config.Stream)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		return js.CreateStream(ctx, jetstream.StreamConfig{
+			Name:     n.config.Stream,
+			Subjects: n.config.Subjects,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := stream.CachedInfo().Config
+	updated := false
+	for _, subject := range n.config.Subjects {
+		if !capturesSubject(cfg.Subjects, subject) {
+			cfg.Subjects = append(cfg.Subjects, subject)
+			updated = true
+		}
+	}
+	if !updated {
+		return stream, nil
+	}
+
+	return js.UpdateStream(ctx, cfg)
+}
+
+// capturesSubject reports whether one of subjects matches topic.
+func capturesSubject(subjects []string, topic string) bool {
+	for _, subject := range subjects {
+		if subjectMatches(subject, topic) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// connectOptions builds the connection options. Once connected the client
+// reconnects on its own, without limit.
+func connectOptions(conf *Config) ([]natsgo.Option, error) {
+	opts := []natsgo.Option{
+		natsgo.MaxReconnects(-1),
+		natsgo.ReconnectWait(conf.RetryTimeout),
+		natsgo.DisconnectErrHandler(func(_ *natsgo.Conn, err error) {
+			if err != nil {
+				slog.Error(fmt.Sprintf("disconnected from nats, error: %v", err))
+			}
+		}),
+		natsgo.ReconnectHandler(func(conn *natsgo.Conn) {
+			slog.Info(fmt.Sprintf("reconnected to NATS at '%s'", conn.ConnectedUrlRedacted()))
+		}),
+	}
+
+	switch {
+	case conf.CredentialsFile != "":
+		opts = append(opts, natsgo.UserCredentials(conf.CredentialsFile))
+	case conf.Token != "":
+		opts = append(opts, natsgo.Token(conf.Token))
+	case conf.Username != "":
+		opts = append(opts, natsgo.UserInfo(conf.Username, conf.Password))
+	}
+
+	if tlsEnabled(conf) {
+		tlsConfig, err := createTLSConfig(&conf.TLS)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, natsgo.Secure(tlsConfig))
+	}
+
+	return opts, nil
+}
+
+func tlsEnabled(conf *Config) bool {
+	if conf.TLS != (TLSConfig{}) {
+		return true
+	}
+
+	for _, server := range conf.Servers {
+		if strings.HasPrefix(server, "tls://") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// retryConnect handles the retry mechanism for the initial connection to the NATS servers.
+func (n *natsClient) retryConnect() {
+	for {
+		time.Sleep(n.retryTimeout())
+
+		err := n.initialize(context.Background())
+		if err != nil {
+			slog.Error(fmt.Sprintf("could not connect to NATS at '%v', error: %v", n.config.Servers, err))
+
+			continue
+		}
+
+		return
+	}
+}
+
+func (n *natsClient) isConnected() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.conn != nil && n.conn.IsConnected()
+}
+
+func (n *natsClient) jetStream() jetstream.JetStream {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.js
+}
+
+func (n *natsClient) getStream() jetstream.Stream {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.stream
+}
+
+// getConsumer returns the durable consumer of the consumer group for a topic,
+// creating it on first use. New consumers start with the oldest stored
+// message.
+func (n *natsClient) getConsumer(ctx context.Context, topic string) (jetstream.Consumer, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if cons, ok := n.consumers[topic]; ok {
+		return cons, nil
+	}
+
+	cons, err := n.stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       durableName(n.config.ConsumerGroupID, topic),
+		FilterSubject: topic,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       n.config.AckWait,
+		MaxDeliver:    n.config.MaxDeliver,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	n.consumers[topic] = cons
+
+	return cons, nil
+}
+
+// durableName derives the durable consumer name of a group and topic. Durable
+// names may not contain subject separators or wildcards.
+func durableName(group, topic string) string {
+	return strings.NewReplacer(".", "_", "*", "_", ">", "_", " ", "_").Replace(group + "_" + topic)
+}
+
+func (n *natsClient) retryTimeout() time.Duration {
+	if n.config.RetryTimeout > 0 {
+		return n.config.RetryTimeout
+	}
+
+	return defaultRetryTimeout
+}
@@ -0,0 +1,15 @@
+package nats
+
+import "errors"
+
+var (
+	ErrConsumerGroupNotProvided = errors.New("consumer group id not provided")
+	errServersNotProvided       = errors.New("nats server address not provided")
+	errStreamNotProvided        = errors.New("nats stream name not provided")
+	errPublisherNotConfigured   = errors.New("can't publish message. Publisher not configured or topic is empty")
+	errClientNotConnected       = errors.New("nats client not connected")
+	errEmptyTopicName           = errors.New("topic name cannot be empty")
+	errConflictingCredentials   = errors.New("only one of username/password, token or credentials file can be set")
+	errCACertFileRead           = errors.New("failed to read CA certificate file")
+	errClientCertLoad           = errors.New("failed to load client certificate")
+)
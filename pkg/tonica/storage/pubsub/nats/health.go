@@ -0,0 +1,59 @@
+package nats
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/tonica-go/tonica/pkg/tonica/storage"
+)
+
+const healthTimeout = 5 * time.Second
+
+func (n *natsClient) Health() storage.Health {
+	health := storage.Health{
+		Status:  storage.StatusDown,
+		Details: make(map[string]any),
+	}
+
+	health.Details["backend"] = "NATS"
+	health.Details["stream"] = n.config.Stream
+
+	if !n.isConnected() {
+		health.Details["error"] = errClientNotConnected.Error()
+		return health
+	}
+
+	n.mu.RLock()
+	health.Details["server"] = n.conn.ConnectedUrlRedacted()
+	n.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthTimeout)
+	defer cancel()
+
+	info, err := n.getStream().Info(ctx)
+	if err != nil {
+		health.Details["error"] = err.Error()
+		return health
+	}
+
+	health.Status = storage.StatusUp
+	health.Details["messages"] = info.State.Msgs
+	health.Details["subscriptions"] = n.subscribedTopics()
+
+	return health
+}
+
+func (n *natsClient) subscribedTopics() []string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	topics := make([]string, 0, len(n.consumers))
+	for topic := range n.consumers {
+		topics = append(topics, topic)
+	}
+
+	sort.Strings(topics)
+
+	return topics
+}
@@ -0,0 +1,37 @@
+package nats
+
+import (
+	"fmt"
+)
+
+func validateConfigs(conf *Config) error {
+	if len(conf.Servers) == 0 {
+		return errServersNotProvided
+	}
+
+	if conf.Stream == "" {
+		return errStreamNotProvided
+	}
+
+	credentials := 0
+
+	for _, set := range []bool{conf.Username != "", conf.Token != "", conf.CredentialsFile != ""} {
+		if set {
+			credentials++
+		}
+	}
+
+	if credentials > 1 {
+		return fmt.Errorf("invalid credentials: %w", errConflictingCredentials)
+	}
+
+	if len(conf.Subjects) == 0 {
+		conf.Subjects = []string{conf.Stream + ".>"}
+	}
+
+	if conf.RetryTimeout <= 0 {
+		conf.RetryTimeout = defaultRetryTimeout
+	}
+
+	return nil
+}
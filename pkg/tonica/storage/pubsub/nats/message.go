@@ -0,0 +1,71 @@
+package nats
+
+import (
+	"log/slog"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// keyHeader carries the message key. The JetStream message id is not used as
+// it deduplicates messages sharing a key.
+const keyHeader = "Tonica-Message-Key"
+
+type natsMessage struct {
+	msg jetstream.Msg
+}
+
+func newNATSMessage(msg jetstream.Msg) *natsMessage {
+	return &natsMessage{
+		msg: msg,
+	}
+}
+
+// Commit acknowledges the message. Messages that are not acknowledged within
+// the consumer ack wait are redelivered.
+func (nmsg *natsMessage) Commit() {
+	if nmsg.msg != nil {
+		err := nmsg.msg.Ack()
+		if err != nil {
+			slog.Error("unable to acknowledge message on nats")
+		}
+	}
+}
+
+func toNATSHeaders(key []byte, headers map[string][]byte) natsgo.Header {
+	if len(key) == 0 && len(headers) == 0 {
+		return nil
+	}
+
+	result := make(natsgo.Header, len(headers)+1)
+	for name, value := range headers {
+		result.Set(name, string(value))
+	}
+
+	if len(key) > 0 {
+		result.Set(keyHeader, string(key))
+	}
+
+	return result
+}
+
+// fromNATSHeaders returns the message key and the remaining headers.
+func fromNATSHeaders(headers natsgo.Header) ([]byte, map[string][]byte) {
+	if len(headers) == 0 {
+		return nil, nil
+	}
+
+	var key []byte
+
+	result := make(map[string][]byte, len(headers))
+	for name := range headers {
+		if name == keyHeader {
+			key = []byte(headers.Get(name))
+			continue
+		}
+
+		result[name] = []byte(headers.Get(name))
+	}
+
+	return key, result
+}
@@ -0,0 +1,340 @@
+// Package nats provides a client for interacting with NATS JetStream. This package facilitates interaction with
+// JetStream, allowing publishing to subject based topics, consuming them through durable consumers per consumer
+// group, and acknowledging messages.
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/tonica-go/tonica/pkg/tonica/storage/pubsub"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	defaultRetryTimeout = 10 * time.Second
+	defaultFetchWait    = 5 * time.Second
+	defaultQueryWait    = 2 * time.Second
+	defaultMessageLimit = 10
+)
+
+// Config configures the NATS JetStream client.
+//
+// Topics are subjects captured by Stream. Every consumer group reads a topic
+// through its own durable consumer, so each group receives every message once
+// and resumes where it left off after a restart.
+type Config struct {
+	Servers []string
+	Stream  string
+	// Subjects captured by the stream when it is created. Defaults to
+	// "<Stream>.>", so topics are subjects below the stream name.
+	Subjects        []string
+	ConsumerGroupID string
+	// AckWait is how long a delivered message may stay unacknowledged before
+	// it is redelivered. Zero uses the server default.
+	AckWait time.Duration
+	// MaxDeliver limits the deliveries of a message. Zero is unlimited.
+	MaxDeliver int
+
+	Username        string
+	Password        string
+	Token           string
+	CredentialsFile string
+
+	RetryTimeout time.Duration
+	// TLS is used when any of its fields is set or a server uses the tls://
+	// scheme.
+	TLS TLSConfig
+}
+
+type natsClient struct {
+	conn      *natsgo.Conn
+	js        jetstream.JetStream
+	stream    jetstream.Stream
+	consumers map[string]jetstream.Consumer
+
+	mu *sync.RWMutex
+
+	config  Config
+	metrics Metrics
+}
+
+func New(conf *Config, metrics Metrics) *natsClient { //nolint:revive // New allows
+	// returning unexported types as intended.
+	err := validateConfigs(conf)
+	if err != nil {
+		slog.Error(fmt.Sprintf("could not initialize nats, error: %v", err))
+
+		return nil
+	}
+
+	slog.Info(fmt.Sprintf("connecting to NATS servers: %v", conf.Servers))
+
+	client := &natsClient{
+		config:    *conf,
+		metrics:   metrics,
+		consumers: make(map[string]jetstream.Consumer),
+		mu:        &sync.RWMutex{},
+	}
+
+	err = client.initialize(context.Background())
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to connect to nats at %v, error: %v", conf.Servers, err))
+
+		go client.retryConnect()
+
+		return client
+	}
+
+	return client
+}
+
+func (n *natsClient) Publish(ctx context.Context, topic string, message []byte) error {
+	return n.PublishMessage(ctx, topic, &pubsub.Message{Value: message})
+}
+
+// PublishMessage publishes the message value along with its key and headers
+// and waits for the stream to store it.
+func (n *natsClient) PublishMessage(ctx context.Context, topic string, msg *pubsub.Message) error {
+	ctx, span := otel.GetTracerProvider().Tracer("gocrux").Start(ctx, "nats-publish")
+	defer span.End()
+
+	pubsub.InjectTraceContext(ctx, msg)
+
+	n.metrics.IncrementCounter(ctx, "app_pubsub_publish_total_count", "topic", topic)
+
+	js := n.jetStream()
+	if js == nil || topic == "" {
+		return errPublisherNotConfigured
+	}
+
+	_, err := js.PublishMsg(ctx, &natsgo.Msg{
+		Subject: topic,
+		Header:  toNATSHeaders(msg.Key, msg.Headers),
+		Data:    msg.Value,
+	})
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to publish message to nats, error: %v", err))
+		return err
+	}
+
+	n.metrics.IncrementCounter(ctx, "app_pubsub_publish_success_count", "topic", topic)
+
+	return nil
+}
+
+func (n *natsClient) Subscribe(ctx context.Context, topic string) (*pubsub.Message, error) {
+	if !n.isConnected() {
+		time.Sleep(n.retryTimeout())
+
+		return nil, errClientNotConnected
+	}
+
+	if n.config.ConsumerGroupID == "" {
+		slog.Error("cannot subscribe as consumer_id is not provided in configs")
+
+		return &pubsub.Message{}, ErrConsumerGroupNotProvided
+	}
+
+	ctx, span := otel.GetTracerProvider().Tracer("gocrux").Start(ctx, "nats-subscribe")
+	defer span.End()
+
+	n.metrics.IncrementCounter(ctx, "app_pubsub_subscribe_total_count", "topic", topic, "consumer_group", n.config.ConsumerGroupID)
+
+	cons, err := n.getConsumer(ctx, topic)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to subscribe to nats topic %s: %v", topic, err))
+
+		return nil, err
+	}
+
+	var msg jetstream.Msg
+
+	// Pull one message at a time, checking ctx between idle pulls.
+	for msg == nil {
+		msg, err = cons.Next(jetstream.FetchMaxWait(defaultFetchWait))
+		if err == nil {
+			break
+		}
+
+		if !errors.Is(err, natsgo.ErrTimeout) && !errors.Is(err, jetstream.ErrNoMessages) {
+			slog.Error(fmt.Sprintf("failed to read message from nats topic %s: %v", topic, err))
+
+			return nil, err
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+
+	m := pubsub.NewMessage(ctx)
+	m.Key, m.Headers = fromNATSHeaders(msg.Headers())
+	m.Value = msg.Data()
+	m.Topic = topic
+	m.Committer = newNATSMessage(msg)
+
+	n.metrics.IncrementCounter(ctx, "app_pubsub_subscribe_success_count", "topic", topic, "consumer_group", n.config.ConsumerGroupID)
+
+	return m, nil
+}
+
+// Query returns up to limit messages stored for a topic, oldest first and
+// newline separated, without affecting any consumer. The optional first
+// argument is the limit.
+func (n *natsClient) Query(ctx context.Context, query string, args ...any) ([]byte, error) {
+	if !n.isConnected() {
+		return nil, errClientNotConnected
+	}
+
+	if query == "" {
+		return nil, errEmptyTopicName
+	}
+
+	limit := defaultMessageLimit
+	if len(args) > 0 {
+		if val, ok := args[0].(int); ok {
+			limit = val
+		}
+	}
+
+	cons, err := n.getStream().OrderedConsumer(ctx, jetstream.OrderedConsumerConfig{
+		FilterSubjects: []string{query},
+		DeliverPolicy:  jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	batch, err := cons.Fetch(limit, jetstream.FetchMaxWait(defaultQueryWait))
+	if err != nil {
+		return nil, err
+	}
+
+	var result []byte
+
+	for msg := range batch.Messages() {
+		if len(result) > 0 {
+			result = append(result, '\n')
+		}
+
+		result = append(result, msg.Data()...)
+	}
+
+	if err := batch.Error(); err != nil && !errors.Is(err, natsgo.ErrTimeout) {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+
+	return result, nil
+}
+
+// CreateTopic adds the topic subject to the stream unless the stream already
+// captures it.
+func (n *natsClient) CreateTopic(ctx context.Context, name string) error {
+	if name == "" {
+		return errEmptyTopicName
+	}
+
+	if !n.isConnected() {
+		return errClientNotConnected
+	}
+
+	info, err := n.getStream().Info(ctx)
+	if err != nil {
+		return err
+	}
+
+	if capturesSubject(info.Config.Subjects, name) {
+		return nil
+	}
+
+	cfg := info.Config
+	cfg.Subjects = append(cfg.Subjects, name)
+
+	stream, err := n.jetStream().UpdateStream(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	n.stream = stream
+	n.mu.Unlock()
+
+	return nil
+}
+
+// DeleteTopic purges the messages of the topic and deletes the durable
+// consumer of the consumer group, if any.
+func (n *natsClient) DeleteTopic(ctx context.Context, name string) error {
+	if name == "" {
+		return errEmptyTopicName
+	}
+
+	if !n.isConnected() {
+		return errClientNotConnected
+	}
+
+	stream := n.getStream()
+
+	if err := stream.Purge(ctx, jetstream.WithPurgeSubject(name)); err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	delete(n.consumers, name)
+	n.mu.Unlock()
+
+	if n.config.ConsumerGroupID == "" {
+		return nil
+	}
+
+	err := stream.DeleteConsumer(ctx, durableName(n.config.ConsumerGroupID, name))
+	if errors.Is(err, jetstream.ErrConsumerNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+func (n *natsClient) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.conn == nil || n.conn.IsClosed() {
+		return nil
+	}
+
+	// Drain lets in-flight acknowledgements complete before closing.
+	err := n.conn.Drain()
+	if err != nil {
+		n.conn.Close()
+	}
+
+	return err
+}
+
+// subjectMatches reports whether subject, which may contain the * and >
+// wildcards, matches the literal subject topic.
+func subjectMatches(subject, topic string) bool {
+	pattern := strings.Split(subject, ".")
+	tokens := strings.Split(topic, ".")
+
+	for i, token := range pattern {
+		if token == ">" {
+			return len(tokens) > i
+		}
+
+		if i >= len(tokens) || (token != "*" && token != tokens[i]) {
+			return false
+		}
+	}
+
+	return len(pattern) == len(tokens)
+}
@@ -0,0 +1,186 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const defaultHeartbeat = 10 * time.Second
+
+// initialize connects to the broker, opens the publishing channel in confirm
+// mode and declares the exchange, if any.
+func (r *rabbitClient) initialize() error {
+	amqpConfig := amqp.Config{
+		Heartbeat: defaultHeartbeat,
+		Locale:    "en_US",
+	}
+
+	if strings.HasPrefix(r.config.URL, "amqps://") {
+		tlsConfig, err := createTLSConfig(&r.config.TLS)
+		if err != nil {
+			return err
+		}
+
+		amqpConfig.TLSClientConfig = tlsConfig
+	}
+
+	conn, err := amqp.DialConfig(r.config.URL, amqpConfig)
+	if err != nil {
+		return err
+	}
+
+	publisher, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	if err := publisher.Confirm(false); err != nil {
+		conn.Close()
+		return fmt.Errorf("enable publisher confirms: %w", err)
+	}
+
+	if r.config.Exchange != "" {
+		err = publisher.ExchangeDeclare(r.config.Exchange, r.config.ExchangeType, true, false, false, false, nil)
+		if err != nil {
+			conn.Close()
+			return fmt.Errorf("declare exchange %s: %w", r.config.Exchange, err)
+		}
+	}
+
+	slog.Info(fmt.Sprintf("connected to RabbitMQ at '%s'", redactURL(r.config.URL)))
+
+	r.mu.Lock()
+	r.conn = amqpConnection{Connection: conn}
+	r.publisher = publisher
+	r.consumers = make(map[string]*consumer)
+	r.mu.Unlock()
+
+	go r.watchConnection(r.conn)
+
+	return nil
+}
+
+// watchConnection reconnects when the broker closes the connection. A
+// connection closed by Close is not reopened.
+func (r *rabbitClient) watchConnection(conn Connection) {
+	closed := conn.NotifyClose(make(chan *amqp.Error, 1))
+
+	if err, ok := <-closed; ok && err != nil {
+		slog.Error(fmt.Sprintf("rabbitmq connection closed, error: %v", err))
+
+		r.retryConnect()
+	}
+}
+
+// retryConnect handles the retry mechanism for connecting to the RabbitMQ broker.
+func (r *rabbitClient) retryConnect() {
+	for {
+		time.Sleep(r.retryTimeout())
+
+		err := r.initialize()
+		if err != nil {
+			slog.Error(fmt.Sprintf("could not connect to RabbitMQ at '%s', error: %v", redactURL(r.config.URL), err))
+
+			continue
+		}
+
+		return
+	}
+}
+
+func (r *rabbitClient) isConnected() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.conn != nil && !r.conn.IsClosed()
+}
+
+// getConsumer returns the consumer of a topic, opening a channel and
+// declaring its queue on first use.
+func (r *rabbitClient) getConsumer(_ context.Context, topic string) (*consumer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if sub, ok := r.consumers[topic]; ok {
+		return sub, nil
+	}
+
+	ch, err := r.conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ch.Qos(r.config.PrefetchCount, 0, false); err != nil {
+		ch.Close()
+		return nil, err
+	}
+
+	if err := r.declareQueue(ch, topic); err != nil {
+		ch.Close()
+		return nil, err
+	}
+
+	// The consumer outlives the Subscribe call it was opened by, so it is not
+	// bound to that call's context.
+	deliveries, err := ch.ConsumeWithContext(context.Background(), r.queueName(topic), "", false, false, false, false, nil)
+	if err != nil {
+		ch.Close()
+		return nil, err
+	}
+
+	sub := &consumer{channel: ch, deliveries: deliveries}
+	r.consumers[topic] = sub
+
+	return sub, nil
+}
+
+func (r *rabbitClient) dropConsumer(topic string, sub *consumer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.consumers[topic] == sub {
+		delete(r.consumers, topic)
+		sub.channel.Close()
+	}
+}
+
+func (r *rabbitClient) declareQueue(ch Channel, topic string) error {
+	queue := r.queueName(topic)
+
+	if _, err := ch.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("declare queue %s: %w", queue, err)
+	}
+
+	if r.config.Exchange == "" {
+		return nil
+	}
+
+	if err := ch.QueueBind(queue, topic, r.config.Exchange, false, nil); err != nil {
+		return fmt.Errorf("bind queue %s to exchange %s: %w", queue, r.config.Exchange, err)
+	}
+
+	return nil
+}
+
+// queueName returns the queue a topic is consumed from, see Config.
+func (r *rabbitClient) queueName(topic string) string {
+	if r.config.Exchange == "" || r.config.ConsumerGroupID == "" {
+		return topic
+	}
+
+	return r.config.ConsumerGroupID + "." + topic
+}
+
+func (r *rabbitClient) retryTimeout() time.Duration {
+	if r.config.RetryTimeout > 0 {
+		return r.config.RetryTimeout
+	}
+
+	return defaultRetryTimeout
+}
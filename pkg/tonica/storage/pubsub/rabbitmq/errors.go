@@ -0,0 +1,16 @@
+package rabbitmq
+
+import "errors"
+
+var (
+	ErrConsumerGroupNotProvided = errors.New("consumer group id not provided")
+	errURLNotProvided           = errors.New("rabbitmq url not provided")
+	errInvalidURL               = errors.New("invalid rabbitmq url")
+	errPrefetchCount            = errors.New("RABBITMQ_PREFETCH_COUNT must not be negative")
+	errPublisherNotConfigured   = errors.New("can't publish message. Publisher not configured or topic is empty")
+	errClientNotConnected       = errors.New("rabbitmq client not connected")
+	errPublishNotConfirmed      = errors.New("rabbitmq broker did not confirm the message")
+	errEmptyTopicName           = errors.New("topic name cannot be empty")
+	errCACertFileRead           = errors.New("failed to read CA certificate file")
+	errClientCertLoad           = errors.New("failed to load client certificate")
+)
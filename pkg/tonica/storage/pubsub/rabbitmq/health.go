@@ -0,0 +1,42 @@
+package rabbitmq
+
+import (
+	"sort"
+
+	"github.com/tonica-go/tonica/pkg/tonica/storage"
+)
+
+func (r *rabbitClient) Health() storage.Health {
+	health := storage.Health{
+		Status:  storage.StatusDown,
+		Details: make(map[string]any),
+	}
+
+	health.Details["backend"] = "RABBITMQ"
+	health.Details["host"] = redactURL(r.config.URL)
+	health.Details["exchange"] = r.config.Exchange
+
+	if !r.isConnected() {
+		health.Details["error"] = errClientNotConnected.Error()
+		return health
+	}
+
+	health.Status = storage.StatusUp
+	health.Details["subscriptions"] = r.subscribedTopics()
+
+	return health
+}
+
+func (r *rabbitClient) subscribedTopics() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	topics := make([]string, 0, len(r.consumers))
+	for topic := range r.consumers {
+		topics = append(topics, topic)
+	}
+
+	sort.Strings(topics)
+
+	return topics
+}
@@ -0,0 +1,42 @@
+package rabbitmq
+
+import (
+	"fmt"
+	"net/url"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func validateConfigs(conf *Config) error {
+	if conf.URL == "" {
+		return errURLNotProvided
+	}
+
+	if _, err := amqp.ParseURI(conf.URL); err != nil {
+		return fmt.Errorf("%w: %w", errInvalidURL, err)
+	}
+
+	if conf.PrefetchCount < 0 {
+		return fmt.Errorf("prefetch count must not be negative: %w", errPrefetchCount)
+	}
+
+	if conf.PrefetchCount == 0 {
+		conf.PrefetchCount = DefaultPrefetchCount
+	}
+
+	if conf.ExchangeType == "" {
+		conf.ExchangeType = DefaultExchangeType
+	}
+
+	return nil
+}
+
+// redactURL returns the URL with its password masked, for logging.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "invalid url"
+	}
+
+	return u.Redacted()
+}
@@ -0,0 +1,61 @@
+package rabbitmq
+
+import (
+	"log/slog"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type rabbitMessage struct {
+	delivery *amqp.Delivery
+}
+
+func newRabbitMessage(delivery *amqp.Delivery) *rabbitMessage {
+	return &rabbitMessage{
+		delivery: delivery,
+	}
+}
+
+// Commit acknowledges the message. Messages that are never committed are
+// redelivered once their channel closes.
+func (rmsg *rabbitMessage) Commit() {
+	if rmsg.delivery != nil {
+		err := rmsg.delivery.Ack(false)
+		if err != nil {
+			slog.Error("unable to acknowledge message on rabbitmq")
+		}
+	}
+}
+
+func toAMQPHeaders(headers map[string][]byte) amqp.Table {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	result := make(amqp.Table, len(headers))
+	for key, value := range headers {
+		result[key] = value
+	}
+
+	return result
+}
+
+// fromAMQPHeaders keeps the byte and string valued headers, which covers the
+// headers set by publishers of this package and most other clients.
+func fromAMQPHeaders(headers amqp.Table) map[string][]byte {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	result := make(map[string][]byte, len(headers))
+	for key, value := range headers {
+		switch v := value.(type) {
+		case []byte:
+			result[key] = v
+		case string:
+			result[key] = []byte(v)
+		}
+	}
+
+	return result
+}
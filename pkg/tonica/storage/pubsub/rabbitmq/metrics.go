@@ -0,0 +1,7 @@
+package rabbitmq
+
+import "context"
+
+type Metrics interface {
+	IncrementCounter(ctx context.Context, name string, labels ...string)
+}
@@ -0,0 +1,57 @@
+package pubsub
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// headerCarrier adapts message headers to the OpenTelemetry propagation API.
+type headerCarrier map[string][]byte
+
+func (h headerCarrier) Get(key string) string {
+	return string(h[key])
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h[key] = []byte(value)
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+var _ propagation.TextMapCarrier = headerCarrier(nil)
+
+// InjectTraceContext writes the W3C trace context of ctx into the message
+// headers. msg.Headers is replaced with a copy carrying the trace context, so
+// a header map shared with other messages is never modified.
+func InjectTraceContext(ctx context.Context, msg *Message) {
+	if msg == nil {
+		return
+	}
+
+	headers := make(headerCarrier, len(msg.Headers)+2)
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	otel.GetTextMapPropagator().Inject(ctx, headers)
+
+	if len(headers) > 0 {
+		msg.Headers = headers
+	}
+}
+
+// ExtractTraceContext returns ctx enriched with the remote span context found
+// in the message headers, if any.
+func ExtractTraceContext(ctx context.Context, msg *Message) context.Context {
+	if msg == nil || len(msg.Headers) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, headerCarrier(msg.Headers))
+}
@@ -0,0 +1,124 @@
+package tonica
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tonica-go/tonica/pkg/tonica/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// writeCert signs a certificate for localhost with parent (self-signed when
+// nil) and writes it and its key as PEM files to dir.
+func writeCert(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+func TestApp_GRPCTLS(t *testing.T) {
+	dir := t.TempDir()
+	file := func(name string) string { return filepath.Join(dir, name) }
+	ca, caKey := writeCert(t, dir, "ca", nil, nil)
+	writeCert(t, dir, "server", ca, caKey)
+	writeCert(t, dir, "client", ca, caKey)
+
+	serve := func(t *testing.T, tlsCfg *service.TLSConfig) string {
+		srv, err := NewApp().newGRPCServer(tlsCfg, nil)
+		require.NoError(t, err)
+		healthpb.RegisterHealthServer(srv, health.NewServer())
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		go func() { _ = srv.Serve(lis) }()
+		t.Cleanup(srv.Stop)
+		return lis.Addr().String()
+	}
+	check := func(app *App, addr string) error {
+		creds, err := app.gatewayCredentials()
+		require.NoError(t, err)
+		conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+		require.NoError(t, err)
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		return err
+	}
+
+	t.Run("should serve TLS", func(t *testing.T) {
+		addr := serve(t, &service.TLSConfig{CertFile: file("server.crt"), KeyFile: file("server.key")})
+		assert.NoError(t, check(NewApp(WithGatewayTLS(file("ca.crt"), "", "")), addr))
+	})
+
+	t.Run("should require client certificates with a CA", func(t *testing.T) {
+		addr := serve(t, &service.TLSConfig{CertFile: file("server.crt"), KeyFile: file("server.key"), CAFile: file("ca.crt")})
+		assert.Error(t, check(NewApp(WithGatewayTLS(file("ca.crt"), "", "")), addr))
+		assert.NoError(t, check(NewApp(WithGatewayTLS(file("ca.crt"), file("client.crt"), file("client.key"))), addr))
+	})
+
+	t.Run("should fail on unreadable files", func(t *testing.T) {
+		_, err := NewApp().newGRPCServer(&service.TLSConfig{CertFile: file("missing.crt"), KeyFile: file("missing.key")}, nil)
+		assert.Error(t, err)
+		_, err = NewApp(WithGatewayTLS(file("missing.crt"), "", "")).gatewayCredentials()
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject conflicting shared TLS", func(t *testing.T) {
+		a := service.NewService(service.WithName("a"), service.WithTLS("a.crt", "a.key", ""))
+		same := service.NewService(service.WithName("same"), service.WithTLS("a.crt", "a.key", ""))
+		b := service.NewService(service.WithName("b"), service.WithTLS("b.crt", "b.key", ""))
+		plain := service.NewService(service.WithName("plain"))
+
+		cfg, err := sharedTLS([]*service.Service{a, same})
+		assert.NoError(t, err)
+		assert.Equal(t, a.GetTLS(), cfg)
+		_, err = sharedTLS([]*service.Service{a, b})
+		assert.Error(t, err)
+		_, err = sharedTLS([]*service.Service{plain, a})
+		assert.Error(t, err)
+		_, err = sharedTLS([]*service.Service{a, plain})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,219 @@
+package tonica
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Upload errors returned by FormFile and SaveFormFile.
+var (
+	ErrUploadMissing     = errors.New("upload missing")
+	ErrUploadTooLarge    = errors.New("upload too large")
+	ErrUploadContentType = errors.New("upload content type not allowed")
+	ErrNoUploadStore     = errors.New("no upload store configured")
+)
+
+const (
+	uploadConstraintsKey = "tonica.upload_constraints"
+	uploadStoreKey       = "tonica.upload_store"
+)
+
+// sniffLen is how much of a file is read to detect its content type.
+const sniffLen = 512
+
+// multipartOverhead is the room left in the request body limit for part
+// headers and non-file form fields.
+const multipartOverhead = 1 << 20
+
+// UploadStore receives uploaded files streamed by SaveFormFile.
+type UploadStore interface {
+	Put(ctx context.Context, key string, r io.Reader, metadata map[string]string) error
+}
+
+// FileOption constrains a file declared with FormFileParam.
+type FileOption func(*fileConstraints)
+
+type fileConstraints struct {
+	maxSize      int64
+	contentTypes []string
+}
+
+// MaxFileSize rejects files larger than bytes.
+func MaxFileSize(bytes int64) FileOption {
+	return func(c *fileConstraints) {
+		c.maxSize = bytes
+	}
+}
+
+// AllowedContentTypes restricts files to the given media types. Wildcards
+// such as "image/*" match any subtype.
+func AllowedContentTypes(types ...string) FileOption {
+	return func(c *fileConstraints) {
+		c.contentTypes = append(c.contentTypes, types...)
+	}
+}
+
+// UploadedFile is a multipart file received by a route. Read it like any
+// io.Reader and Close it when done.
+type UploadedFile struct {
+	Field       string
+	Filename    string
+	ContentType string
+	Size        int64
+
+	r    io.Reader
+	file multipart.File
+}
+
+func (f *UploadedFile) Read(p []byte) (int, error) {
+	return f.r.Read(p)
+}
+
+// Close releases the underlying multipart file.
+func (f *UploadedFile) Close() error {
+	return f.file.Close()
+}
+
+// FormFile reads the multipart file name from the request and enforces the
+// constraints declared with FormFileParam. The content type is taken from the
+// part header and sniffed from the data when the header is missing.
+func FormFile(c *gin.Context, name string) (*UploadedFile, error) {
+	header, err := c.FormFile(name)
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) {
+			return nil, fmt.Errorf("%w: %s", ErrUploadMissing, name)
+		}
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, fmt.Errorf("%w: request body exceeds %d bytes", ErrUploadTooLarge, maxErr.Limit)
+		}
+		return nil, err
+	}
+
+	var constraints fileConstraints
+	if all, ok := c.Get(uploadConstraintsKey); ok {
+		constraints = all.(map[string]fileConstraints)[name]
+	}
+
+	if constraints.maxSize > 0 && header.Size > constraints.maxSize {
+		return nil, fmt.Errorf("%w: %s is %d bytes, max %d", ErrUploadTooLarge, name, header.Size, constraints.maxSize)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		file.Close()
+		return nil, err
+	}
+	head = head[:n]
+
+	contentType := header.Header.Get("Content-Type")
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = http.DetectContentType(head)
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+	if len(constraints.contentTypes) > 0 && !matchContentType(constraints.contentTypes, contentType) {
+		file.Close()
+		return nil, fmt.Errorf("%w: %s has type %s", ErrUploadContentType, name, contentType)
+	}
+
+	return &UploadedFile{
+		Field:       name,
+		Filename:    header.Filename,
+		ContentType: contentType,
+		Size:        header.Size,
+		r:           io.MultiReader(bytes.NewReader(head), file),
+		file:        file,
+	}, nil
+}
+
+// SaveFormFile reads the multipart file name like FormFile and streams it to
+// the store configured with WithUploadStore under key. The filename and
+// content type are passed along as metadata. The returned file is already
+// consumed and closed; use it for its metadata.
+func SaveFormFile(c *gin.Context, name, key string) (*UploadedFile, error) {
+	store, _ := c.Get(uploadStoreKey)
+	uploadStore, ok := store.(UploadStore)
+	if !ok || uploadStore == nil {
+		return nil, ErrNoUploadStore
+	}
+
+	file, err := FormFile(c, name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	err = uploadStore.Put(c.Request.Context(), key, file, map[string]string{
+		"filename":     file.Filename,
+		"content-type": file.ContentType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("store upload %s: %w", name, err)
+	}
+	return file, nil
+}
+
+// uploadMiddleware exposes the route's file constraints and the app's upload
+// store to FormFile and SaveFormFile. When every file declares a MaxFileSize
+// the request body is capped before the multipart form is parsed.
+func (rb *RouteBuilder) uploadMiddleware() gin.HandlerFunc {
+	constraints := rb.files
+	store := rb.app.uploadStore
+	limit := bodyLimit(constraints)
+	return func(c *gin.Context) {
+		if limit > 0 {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		}
+		c.Set(uploadConstraintsKey, constraints)
+		if store != nil {
+			c.Set(uploadStoreKey, store)
+		}
+		c.Next()
+	}
+}
+
+// bodyLimit returns the largest request body the declared files can make up,
+// or 0 when any file is unbounded.
+func bodyLimit(constraints map[string]fileConstraints) int64 {
+	limit := int64(multipartOverhead)
+	for _, c := range constraints {
+		if c.maxSize <= 0 {
+			return 0
+		}
+		limit += c.maxSize
+	}
+	return limit
+}
+
+func matchContentType(allowed []string, contentType string) bool {
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(contentType, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if pattern == contentType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package tonica
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryUploadStore struct {
+	key      string
+	data     []byte
+	metadata map[string]string
+}
+
+func (m *memoryUploadStore) Put(_ context.Context, key string, r io.Reader, metadata map[string]string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m.key, m.data, m.metadata = key, data, metadata
+	return nil
+}
+
+func multipartRequest(t *testing.T, path, field, filename, contentType string, content []byte) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="`+field+`"; filename="`+filename+`"`)
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	part, err := w.CreatePart(header)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestFormFile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	app := NewApp()
+
+	NewRoute(app).
+		POST("/avatar").
+		FormFileParam("file", "Avatar", MaxFileSize(16), AllowedContentTypes("image/*")).
+		Handle(func(c *gin.Context) {
+			file, err := FormFile(c, "file")
+			if err != nil {
+				c.String(http.StatusBadRequest, err.Error())
+				return
+			}
+			defer file.Close()
+			data, _ := io.ReadAll(file)
+			c.String(http.StatusOK, "%s %s %s", file.Filename, file.ContentType, data)
+		})
+
+	t.Run("accepted", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		app.router.ServeHTTP(rec, multipartRequest(t, "/avatar", "file", "a.png", "image/png", []byte("png-data")))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "a.png image/png png-data", rec.Body.String())
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		app.router.ServeHTTP(rec, multipartRequest(t, "/avatar", "file", "a.png", "image/png", bytes.Repeat([]byte("x"), 17)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), ErrUploadTooLarge.Error())
+	})
+
+	t.Run("body over limit", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		app.router.ServeHTTP(rec, multipartRequest(t, "/avatar", "file", "a.png", "image/png", bytes.Repeat([]byte("x"), multipartOverhead+32)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "request body exceeds")
+	})
+
+	t.Run("sniffed content type", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		app.router.ServeHTTP(rec, multipartRequest(t, "/avatar", "file", "a.txt", "", []byte("plain text")))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "text/plain")
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		app.router.ServeHTTP(rec, multipartRequest(t, "/avatar", "other", "a.png", "image/png", []byte("png")))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), ErrUploadMissing.Error())
+	})
+}
+
+func TestSaveFormFile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &memoryUploadStore{}
+	app := NewApp(WithUploadStore(store))
+
+	NewRoute(app).
+		POST("/files").
+		FormFileParam("file", "Document").
+		Handle(func(c *gin.Context) {
+			file, err := SaveFormFile(c, "file", "docs/1")
+			if err != nil {
+				c.String(http.StatusInternalServerError, err.Error())
+				return
+			}
+			c.String(http.StatusCreated, "%d", file.Size)
+		})
+
+	rec := httptest.NewRecorder()
+	app.router.ServeHTTP(rec, multipartRequest(t, "/files", "file", "doc.pdf", "application/pdf", []byte("%PDF-1.4")))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, "8", rec.Body.String())
+	assert.Equal(t, "docs/1", store.key)
+	assert.Equal(t, []byte("%PDF-1.4"), store.data)
+	assert.Equal(t, map[string]string{"filename": "doc.pdf", "content-type": "application/pdf"}, store.metadata)
+}
@@ -0,0 +1,567 @@
+package tonica
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSchemaDepth guards against self-referencing schemas.
+const maxSchemaDepth = 32
+
+// defaultValidationBodyBytes caps the bodies buffered for validation when no
+// gateway body limit is configured. It matches the default gRPC message size.
+const defaultValidationBodyBytes = 4 << 20
+
+// FieldError describes a single request validation failure.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// requestValidator validates requests against the merged OpenAPI spec.
+// Both Swagger 2.0 (grpc-gateway output) and OpenAPI 3 documents are supported.
+type requestValidator struct {
+	operations []specOperation
+	spec       map[string]interface{}
+}
+
+type specOperation struct {
+	method       string
+	segments     []string
+	parameters   []map[string]interface{}
+	bodySchema   map[string]interface{}
+	bodyRequired bool
+}
+
+func newRequestValidator(specBytes []byte) (*requestValidator, error) {
+	var spec map[string]interface{}
+	if err := json.Unmarshal(specBytes, &spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal spec: %w", err)
+	}
+
+	v := &requestValidator{spec: spec}
+	paths, _ := spec["paths"].(map[string]interface{})
+	for path, item := range paths {
+		pathItem, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		shared := asObjectSlice(pathItem["parameters"])
+		for method, raw := range pathItem {
+			operation, ok := raw.(map[string]interface{})
+			if !ok || method == "parameters" {
+				continue
+			}
+			op := specOperation{
+				method:   strings.ToUpper(method),
+				segments: splitPath(path),
+			}
+			for _, param := range slices.Concat(shared, asObjectSlice(operation["parameters"])) {
+				param = v.resolve(param, 0)
+				if param["in"] == "body" {
+					op.bodySchema, _ = param["schema"].(map[string]interface{})
+					op.bodyRequired, _ = param["required"].(bool)
+					continue
+				}
+				op.parameters = append(op.parameters, param)
+			}
+			if body, ok := operation["requestBody"].(map[string]interface{}); ok {
+				body = v.resolve(body, 0)
+				op.bodyRequired, _ = body["required"].(bool)
+				if content, ok := body["content"].(map[string]interface{}); ok {
+					if media, ok := content["application/json"].(map[string]interface{}); ok {
+						op.bodySchema, _ = media["schema"].(map[string]interface{})
+					}
+				}
+			}
+			v.operations = append(v.operations, op)
+		}
+	}
+	slices.SortFunc(v.operations, compareOperations)
+
+	return v, nil
+}
+
+// compareOperations orders operations so that find prefers the most specific
+// template: at the first differing segment a literal beats a variable and a
+// variable beats a catch-all. Remaining ties fall back to the template text.
+func compareOperations(a, b specOperation) int {
+	for i := range min(len(a.segments), len(b.segments)) {
+		if c := cmp.Compare(segmentRank(a.segments[i]), segmentRank(b.segments[i])); c != 0 {
+			return c
+		}
+	}
+	if c := cmp.Compare(len(b.segments), len(a.segments)); c != 0 {
+		return c
+	}
+	return cmp.Or(
+		slices.Compare(a.segments, b.segments),
+		strings.Compare(a.method, b.method),
+	)
+}
+
+// segmentRank returns 0 for literal segments, 1 for single segment variables
+// and 2 for catch-all segments.
+func segmentRank(segment string) int {
+	switch {
+	case strings.HasPrefix(segment, "*"), strings.Contains(segment, "**"):
+		return 2
+	case strings.HasPrefix(segment, ":"), strings.HasPrefix(segment, "{"):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Validate checks path, query, header and body parameters of the request.
+// Requests that do not match any documented operation pass unchanged.
+func (v *requestValidator) Validate(r *http.Request) []FieldError {
+	op, pathValues := v.find(r.Method, r.URL.Path)
+	if op == nil {
+		return nil
+	}
+
+	var errs []FieldError
+	query := r.URL.Query()
+	for _, param := range op.parameters {
+		name, _ := param["name"].(string)
+		required, _ := param["required"].(bool)
+
+		var values []string
+		switch param["in"] {
+		case "path":
+			if value, ok := pathValues[name]; ok {
+				values = []string{value}
+			}
+		case "query":
+			values = query[name]
+		case "header":
+			if value := r.Header.Get(name); value != "" {
+				values = []string{value}
+			}
+		default:
+			continue
+		}
+
+		if len(values) == 0 {
+			if required {
+				errs = append(errs, FieldError{Field: name, Message: fmt.Sprintf("%s parameter is required", param["in"])})
+			}
+			continue
+		}
+
+		schema := param
+		if nested, ok := param["schema"].(map[string]interface{}); ok {
+			schema = v.resolve(nested, 0)
+		}
+		paramType, _ := schema["type"].(string)
+		if paramType == "array" {
+			items, _ := schema["items"].(map[string]interface{})
+			for _, value := range values {
+				if msg := checkParamValue(items, value); msg != "" {
+					errs = append(errs, FieldError{Field: name, Message: msg})
+				}
+			}
+			continue
+		}
+		if msg := checkParamValue(schema, values[0]); msg != "" {
+			errs = append(errs, FieldError{Field: name, Message: msg})
+		}
+	}
+
+	if op.bodySchema == nil {
+		return errs
+	}
+	return v.validateBody(r, op.bodySchema, op.bodyRequired, errs)
+}
+
+// validateBody checks the JSON body of r against schema. The body is restored
+// for the handler.
+func (v *requestValidator) validateBody(r *http.Request, schema map[string]interface{}, required bool, errs []FieldError) []FieldError {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return append(errs, FieldError{Field: "body", Message: "failed to read request body"})
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if required {
+			errs = append(errs, FieldError{Field: "body", Message: "request body is required"})
+		}
+		return errs
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var payload interface{}
+	if err := decoder.Decode(&payload); err != nil {
+		return append(errs, FieldError{Field: "body", Message: fmt.Sprintf("invalid JSON: %v", err)})
+	}
+
+	return v.validateValue("", payload, schema, 0, errs)
+}
+
+func (v *requestValidator) find(method, path string) (*specOperation, map[string]string) {
+	segments := splitPath(path)
+	for i := range v.operations {
+		op := &v.operations[i]
+		if op.method != method {
+			continue
+		}
+		if values, ok := matchSegments(op.segments, segments); ok {
+			return op, values
+		}
+	}
+	return nil, nil
+}
+
+func (v *requestValidator) validateValue(field string, value interface{}, schema map[string]interface{}, depth int, errs []FieldError) []FieldError {
+	if depth > maxSchemaDepth {
+		return errs
+	}
+	schema = v.resolve(schema, depth)
+	if value == nil {
+		return errs
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		if str, isString := value.(string); isString && !containsValue(enum, str) {
+			return append(errs, FieldError{Field: fieldName(field), Message: fmt.Sprintf("must be one of %v", enum)})
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return append(errs, FieldError{Field: fieldName(field), Message: "must be an object"})
+		}
+		for _, name := range asStringSlice(schema["required"]) {
+			if _, exists := obj[name]; !exists {
+				errs = append(errs, FieldError{Field: joinField(field, name), Message: "is required"})
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, propValue := range obj {
+			propSchema, ok := properties[name].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			errs = v.validateValue(joinField(field, name), propValue, propSchema, depth+1, errs)
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return append(errs, FieldError{Field: fieldName(field), Message: "must be an array"})
+		}
+		itemSchema, _ := schema["items"].(map[string]interface{})
+		if itemSchema == nil {
+			return errs
+		}
+		for i, item := range items {
+			errs = v.validateValue(fmt.Sprintf("%s[%d]", field, i), item, itemSchema, depth+1, errs)
+		}
+	case "string":
+		if _, ok := value.(string); ok {
+			return errs
+		}
+		// protojson accepts numbers for 64-bit integers encoded as strings
+		if _, ok := value.(json.Number); ok && isIntegerFormat(schema["format"]) {
+			return errs
+		}
+		errs = append(errs, FieldError{Field: fieldName(field), Message: "must be a string"})
+	case "integer":
+		if !isInteger(value) {
+			errs = append(errs, FieldError{Field: fieldName(field), Message: "must be an integer"})
+		}
+	case "number":
+		if !isNumber(value) {
+			errs = append(errs, FieldError{Field: fieldName(field), Message: "must be a number"})
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			errs = append(errs, FieldError{Field: fieldName(field), Message: "must be a boolean"})
+		}
+	}
+
+	return errs
+}
+
+// resolve follows local $ref pointers such as "#/definitions/Foo" or
+// "#/components/schemas/Foo".
+func (v *requestValidator) resolve(schema map[string]interface{}, depth int) map[string]interface{} {
+	for i := depth; i <= maxSchemaDepth; i++ {
+		ref, ok := schema["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return schema
+		}
+		var node interface{} = v.spec
+		for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			obj, ok := node.(map[string]interface{})
+			if !ok {
+				return schema
+			}
+			node = obj[part]
+		}
+		resolved, ok := node.(map[string]interface{})
+		if !ok {
+			return schema
+		}
+		schema = resolved
+	}
+	return schema
+}
+
+func (a *App) validationMiddleware(match func(path string) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v := a.requestValidator
+		if v == nil || (match != nil && !match(c.Request.URL.Path)) {
+			c.Next()
+			return
+		}
+
+		if !a.bufferBody(c) {
+			return
+		}
+		if errs := v.Validate(c.Request); len(errs) > 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":   "request validation failed",
+				"details": errs,
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// bodyValidationMiddleware validates the request body against schema, the
+// body parameter of a custom route. References are resolved against the
+// served spec once it is built.
+func (a *App) bodyValidationMiddleware(schema map[string]interface{}, required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v := &requestValidator{spec: map[string]interface{}{}}
+		if a.requestValidator != nil {
+			v.spec = a.requestValidator.spec
+		}
+
+		if !a.bufferBody(c) {
+			return
+		}
+		if errs := v.validateBody(c.Request, schema, required, nil); len(errs) > 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":   "request validation failed",
+				"details": errs,
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// bufferBody reads the request body up to the validation body limit so it can
+// be validated and still reach the handler. Larger bodies are answered with
+// 413 Request Entity Too Large. It reports whether the request may continue.
+func (a *App) bufferBody(c *gin.Context) bool {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return true
+	}
+	limit := a.validationBodyLimit()
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, limit))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+			return false
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+		return false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return true
+}
+
+// validationBodyLimit returns the largest body limit of WithGatewayLimits and
+// WithMethodLimits, so validation never rejects a body the gateway accepts.
+// Without a default gateway limit defaultValidationBodyBytes stands in for it.
+func (a *App) validationBodyLimit() int64 {
+	limit := a.defaultGatewayLimits.MaxBodyBytes
+	if limit <= 0 {
+		limit = defaultValidationBodyBytes
+	}
+	for _, rule := range a.methodLimits {
+		limit = max(limit, rule.limits.MaxBodyBytes)
+	}
+	return limit
+}
+
+// gatewayValidation returns the validation middleware for gateway routes, or
+// nil when validation is not enabled via WithRequestValidation.
+func (a *App) gatewayValidation() gin.HandlerFunc {
+	if !a.validateGateway {
+		return nil
+	}
+	prefixes := a.validationPrefixes
+	return a.validationMiddleware(func(path string) bool {
+		if len(prefixes) == 0 {
+			return true
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+// matchSegments matches a request path against a spec path template. Both
+// OpenAPI ("{id}") and gin (":id", "*any") placeholders are understood.
+func matchSegments(template, path []string) (map[string]string, bool) {
+	values := make(map[string]string)
+	for i, segment := range template {
+		if strings.HasPrefix(segment, "*") {
+			values[segment[1:]] = strings.Join(path[min(i, len(path)):], "/")
+			return values, true
+		}
+		if i >= len(path) {
+			return nil, false
+		}
+		switch {
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			name := strings.Trim(segment, "{}")
+			if before, pattern, ok := strings.Cut(name, "="); ok {
+				// Multi segment pattern like {name=projects/*/items/**}
+				if strings.Contains(pattern, "**") {
+					values[before] = strings.Join(path[i:], "/")
+					return values, true
+				}
+				name = before
+			}
+			values[name] = path[i]
+		case strings.HasPrefix(segment, ":"):
+			values[segment[1:]] = path[i]
+		case segment != path[i]:
+			return nil, false
+		}
+	}
+	if len(template) != len(path) {
+		return nil, false
+	}
+	return values, true
+}
+
+// checkParamValue checks a parameter value against the type and enum of
+// schema.
+func checkParamValue(schema map[string]interface{}, value string) string {
+	paramType, _ := schema["type"].(string)
+	if msg := checkParamType(paramType, value); msg != "" {
+		return msg
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && !containsValue(enum, value) {
+		return fmt.Sprintf("must be one of %v", enum)
+	}
+	return ""
+}
+
+func checkParamType(paramType, value string) string {
+	switch paramType {
+	case "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return "must be an integer"
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "must be a number"
+		}
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return "must be a boolean"
+		}
+	}
+	return ""
+}
+
+func isInteger(value interface{}) bool {
+	n, ok := value.(json.Number)
+	if !ok {
+		return false
+	}
+	_, err := n.Int64()
+	return err == nil
+}
+
+func isNumber(value interface{}) bool {
+	n, ok := value.(json.Number)
+	if !ok {
+		return false
+	}
+	_, err := n.Float64()
+	return err == nil
+}
+
+func isIntegerFormat(format interface{}) bool {
+	switch format {
+	case "int64", "uint64", "int32", "uint32":
+		return true
+	}
+	return false
+}
+
+func containsValue(values []interface{}, value string) bool {
+	for _, candidate := range values {
+		if fmt.Sprint(candidate) == value {
+			return true
+		}
+	}
+	return false
+}
+
+func asObjectSlice(value interface{}) []map[string]interface{} {
+	items, _ := value.([]interface{})
+	result := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if obj, ok := item.(map[string]interface{}); ok {
+			result = append(result, obj)
+		}
+	}
+	return result
+}
+
+func asStringSlice(value interface{}) []string {
+	items, _ := value.([]interface{})
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if str, ok := item.(string); ok {
+			result = append(result, str)
+		}
+	}
+	return result
+}
+
+func joinField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func fieldName(field string) string {
+	if field == "" {
+		return "body"
+	}
+	return field
+}
@@ -0,0 +1,232 @@
+package tonica
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSpec = `{
+  "swagger": "2.0",
+  "paths": {
+    "/v1/users/{id}": {
+      "put": {
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "type": "integer"},
+          {"name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/User"}}
+        ]
+      }
+    },
+    "/v1/users": {
+      "get": {
+        "parameters": [
+          {"name": "limit", "in": "query", "required": true, "type": "integer"}
+        ]
+      }
+    }
+  },
+  "definitions": {
+    "User": {
+      "type": "object",
+      "required": ["name"],
+      "properties": {
+        "name": {"type": "string"},
+        "age": {"type": "integer"},
+        "role": {"type": "string", "enum": ["ADMIN", "USER"]},
+        "balance": {"type": "string", "format": "int64"}
+      }
+    }
+  }
+}`
+
+func TestRequestValidator_Validate(t *testing.T) {
+	v, err := newRequestValidator([]byte(testSpec))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		fields []string
+	}{
+		{"valid body", http.MethodPut, "/v1/users/1", `{"name":"john","age":30,"role":"ADMIN","balance":10}`, nil},
+		{"missing required field", http.MethodPut, "/v1/users/1", `{"age":30}`, []string{"name"}},
+		{"wrong type", http.MethodPut, "/v1/users/1", `{"name":"john","age":"old"}`, []string{"age"}},
+		{"invalid enum", http.MethodPut, "/v1/users/1", `{"name":"john","role":"ROOT"}`, []string{"role"}},
+		{"invalid path param", http.MethodPut, "/v1/users/abc", `{"name":"john"}`, []string{"id"}},
+		{"missing body", http.MethodPut, "/v1/users/1", ``, []string{"body"}},
+		{"malformed json", http.MethodPut, "/v1/users/1", `{"name":`, []string{"body"}},
+		{"missing query param", http.MethodGet, "/v1/users", ``, []string{"limit"}},
+		{"unknown route", http.MethodGet, "/v1/unknown", ``, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			errs := v.Validate(req)
+
+			fields := make([]string, 0, len(errs))
+			for _, e := range errs {
+				fields = append(fields, e.Field)
+			}
+			assert.ElementsMatch(t, tt.fields, fields)
+		})
+	}
+}
+
+func TestRequestValidator_PrefersLiteralSegments(t *testing.T) {
+	spec := `{
+  "paths": {
+    "/v1/users/{id}": {"get": {"parameters": [{"name": "id", "in": "path", "required": true, "type": "integer"}]}},
+    "/v1/users/me": {"get": {"parameters": [{"name": "fields", "in": "query", "required": true, "type": "string"}]}},
+    "/v1/users/{rest=**}": {"get": {}}
+  }
+}`
+	// Map iteration order differs between runs, so build the validator a few
+	// times to make sure the match does not depend on it.
+	for range 10 {
+		v, err := newRequestValidator([]byte(spec))
+		require.NoError(t, err)
+
+		errs := v.Validate(httptest.NewRequest(http.MethodGet, "/v1/users/me", nil))
+		require.Len(t, errs, 1)
+		assert.Equal(t, "fields", errs[0].Field)
+
+		errs = v.Validate(httptest.NewRequest(http.MethodGet, "/v1/users/abc", nil))
+		require.Len(t, errs, 1)
+		assert.Equal(t, "id", errs[0].Field)
+	}
+}
+
+func TestRouteBuilder_ValidateRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	app := NewApp()
+
+	called := false
+	NewRoute(app).
+		POST("/items/:id").
+		PathParam("id", "integer", "Item ID").
+		BodyParam("Item", InlineObjectSchema(map[string]string{"name": "string"})).
+		ValidateRequest().
+		Handle(func(c *gin.Context) {
+			called = true
+			c.Status(http.StatusNoContent)
+		})
+
+	spec, err := mergeCustomRoutesIntoSpec([]byte("{}"), app.customRoutes, nil)
+	require.NoError(t, err)
+	app.requestValidator, err = newRequestValidator(spec)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	app.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/items/1", strings.NewReader(`{"name":1}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "must be a string")
+	assert.False(t, called)
+
+	w = httptest.NewRecorder()
+	app.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/items/1", strings.NewReader(`{"name":"box"}`)))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.True(t, called)
+}
+
+func TestRouteBuilder_ValidateBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	app := NewApp()
+
+	var received map[string]any
+	NewRoute(app).
+		POST("/orders").
+		BodyParam("Order", RequiredFields(ObjectSchema(map[string]interface{}{
+			"sku":      StringSchema(),
+			"quantity": map[string]string{"type": "integer"},
+			"tags":     ArraySchema(StringSchema()),
+		}), "sku", "quantity")).
+		ValidateBody(true).
+		Handle(func(c *gin.Context) {
+			require.NoError(t, c.ShouldBindJSON(&received))
+			c.Status(http.StatusCreated)
+		})
+
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body)))
+		return w
+	}
+
+	w := post(`{"quantity":"two","tags":[1]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var resp struct {
+		Details []FieldError `json:"details"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.ElementsMatch(t, []FieldError{
+		{Field: "sku", Message: "is required"},
+		{Field: "quantity", Message: "must be an integer"},
+		{Field: "tags[0]", Message: "must be a string"},
+	}, resp.Details)
+	assert.Nil(t, received)
+
+	assert.Equal(t, http.StatusBadRequest, post(``).Code)
+
+	w = post(`{"sku":"box","quantity":2}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "box", received["sku"], "the body should reach the handler")
+
+	tooLarge := `{"sku":"` + strings.Repeat("x", defaultValidationBodyBytes) + `","quantity":2}`
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post(tooLarge).Code)
+}
+
+func TestApp_ValidationBodyLimit(t *testing.T) {
+	assert.EqualValues(t, defaultValidationBodyBytes, NewApp().validationBodyLimit())
+
+	app := NewApp(WithGatewayLimits(GatewayLimits{MaxBodyBytes: 1 << 10}))
+	assert.EqualValues(t, 1<<10, app.validationBodyLimit())
+
+	app = NewApp(WithMethodLimits([]string{"/files.v1.FileService/Upload"}, GatewayLimits{MaxBodyBytes: 100 << 20}))
+	assert.EqualValues(t, 100<<20, app.validationBodyLimit(), "validation should accept what the gateway accepts")
+}
+
+func TestRouteBuilder_ValidateQueryArrays(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	app := NewApp()
+
+	NewRoute(app).
+		GET("/tickets").
+		QueryParamArray("id", "integer", "Ticket IDs", false).
+		QueryParamEnum("sort", "Sort order", []string{"asc", "desc"}, false).
+		ValidateRequest().
+		Handle(func(c *gin.Context) {
+			c.JSON(http.StatusOK, c.QueryArray("id"))
+		})
+
+	spec, err := mergeCustomRoutesIntoSpec([]byte("{}"), app.customRoutes, nil)
+	require.NoError(t, err)
+	app.requestValidator, err = newRequestValidator(spec)
+	require.NoError(t, err)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tickets?"+query, nil))
+		return w
+	}
+
+	w := get("id=1&id=2&sort=asc")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `["1","2"]`, w.Body.String())
+
+	w = get("id=1&id=two")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "must be an integer")
+
+	w = get("sort=random")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "must be one of [asc desc]")
+}
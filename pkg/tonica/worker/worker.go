@@ -1,6 +1,8 @@
 package worker
 
 import (
+	"sync"
+
 	"go.opentelemetry.io/otel"
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/interceptor"
@@ -21,12 +23,16 @@ type Worker struct {
 	queue      string
 	name       string
 	client     client.Client
+
+	stop     chan interface{}
+	stopOnce sync.Once
 }
 
 func NewWorker(options ...Option) *Worker {
 	app := &Worker{
 		activities: []interface{}{},
 		workflows:  []*WF{},
+		stop:       make(chan interface{}),
 	}
 
 	for _, option := range options {
@@ -70,7 +76,21 @@ func (app *Worker) Start() error {
 		w.RegisterWorkflowWithOptions(wf.Function, workflow.RegisterOptions{Name: wf.Name})
 	}
 
-	return w.Run(worker.InterruptCh())
+	return w.Run(app.stop)
+}
+
+// Stop makes Start return once in-flight activities have finished.
+func (app *Worker) Stop() {
+	app.stopOnce.Do(func() {
+		close(app.stop)
+	})
+}
+
+// Merge adds the workflows and activities of other to the worker, so that
+// several services can share one task queue.
+func (app *Worker) Merge(other *Worker) {
+	app.activities = append(app.activities, other.activities...)
+	app.workflows = append(app.workflows, other.workflows...)
 }
 
 type Option func(worker *Worker)
@@ -103,3 +123,23 @@ func WithWorkflows(workflows []*WF) Option {
 		a.workflows = workflows
 	}
 }
+
+// WithWorkflow adds a workflow function. It is registered under name when
+// given, otherwise under the function name.
+func WithWorkflow(fn interface{}, name ...string) Option {
+	return func(a *Worker) {
+		wf := &WF{Function: fn}
+		if len(name) > 0 {
+			wf.Name = name[0]
+		}
+		a.workflows = append(a.workflows, wf)
+	}
+}
+
+// WithActivity adds an activity function, or a struct whose methods are
+// activities.
+func WithActivity(activity interface{}) Option {
+	return func(a *Worker) {
+		a.activities = append(a.activities, activity)
+	}
+}